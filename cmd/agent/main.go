@@ -6,9 +6,11 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -16,6 +18,8 @@ import (
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/personas"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
@@ -26,8 +30,13 @@ func main() {
 	// Parse command-line flags
 	agentName := flag.String("name", "", "Agent name (required)")
 	agentRole := flag.String("role", "", "Agent role (required)")
-	capabilities := flag.String("capabilities", "", "Comma-separated capabilities")
+	capabilities := flag.String("capabilities", "", "Comma-separated capabilities (name or name@version)")
 	metadata := flag.String("metadata", "", "Comma-separated key:value pairs (e.g., framework:openai,model:gpt-4)")
+	personaParams := flag.String("persona", "", "Persona overrides (e.g., activity=2,targets=sales|support,failure_rate=0.1)")
+	agentID := flag.String("id", "", "Agent ID, needed for attestations (default: generated)")
+	namespace := flag.String("namespace", "", "Namespace of the team's sub-mesh (default: default)")
+	dependsOn := flag.String("depends", "", "Comma-separated roles or agent IDs this agent sends work to (seeds their edges)")
+	attestations := flag.String("attestations", "", "JSON file with operator-signed attestations for this agent")
 	flag.Parse()
 
 	if *agentName == "" || *agentRole == "" {
@@ -49,26 +58,65 @@ func main() {
 	)
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+
+	// Simulated behavior for the role; roles without a persona only answer tasks
+	persona, ok := personas.Get(*agentRole)
+	if ok {
+		if err := personas.ParseParams(&persona.Params, *personaParams); err != nil {
+			logger.Fatal("Invalid persona parameters", zap.Error(err))
+		}
+		if *capabilities == "" {
+			*capabilities = strings.Join(persona.Capabilities, ",")
+		}
+	} else {
+		logger.Warn("No persona for role, agent will not send messages", zap.String("role", *agentRole))
+	}
 
 	// Create agent instance
 	agent := &types.Agent{
-		ID:           types.NewAgentID(),
+		ID:           types.AgentID(*agentID),
 		Name:         *agentName,
 		Role:         *agentRole,
+		Namespace:    *namespace,
+		DependsOn:    parseDependencies(*dependsOn),
 		Status:       types.AgentStatusActive,
 		Capabilities: parseCapabilities(*capabilities),
 		Metadata:     parseMetadata(*metadata),
 		CreatedAt:    time.Now(),
 		LastSeenAt:   time.Now(),
 	}
+	if agent.ID == "" {
+		agent.ID = types.NewAgentID()
+	}
+	if *attestations != "" {
+		if agent.Attestations, err = loadAttestations(*attestations); err != nil {
+			logger.Fatal("Invalid attestations", zap.Error(err))
+		}
+	}
+	agent.PromoteMetadata()
+	if agent.Region == "" {
+		agent.Region = cfg.Region // Agents run in the region of their mesh
+	}
+	if err := agent.Validate(); err != nil {
+		logger.Fatal("Invalid agent definition", zap.Error(err))
+	}
 
 	// Initialize Kafka messaging
 	messaging := messaging.NewKafkaMessaging(cfg, logger)
 	defer messaging.Close()
+	if cfg.AgentDryRun {
+		messaging.EnableDryRun()
+	}
 
 	// Create distributed agent runtime
-	runtime := NewDistributedAgent(agent, messaging, cfg, logger)
+	runtime := NewDistributedAgent(agent, persona, messaging, cfg, logger)
 
 	// Start agent
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,11 +141,38 @@ func main() {
 	logger.Info("Agent shutting down gracefully...")
 }
 
-func parseCapabilities(capStr string) []string {
+// loadAttestations reads an attestation as printed by agentmeshctl attest, or a JSON list of them
+func loadAttestations(path string) ([]types.Attestation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestations: %w", err)
+	}
+	var attestations []types.Attestation
+	if err := json.Unmarshal(data, &attestations); err != nil {
+		var attestation types.Attestation
+		if err := json.Unmarshal(data, &attestation); err != nil {
+			return nil, fmt.Errorf("failed to parse attestations: %w", err)
+		}
+		attestations = []types.Attestation{attestation}
+	}
+	return attestations, nil
+}
+
+func parseCapabilities(capStr string) []types.Capability {
 	if capStr == "" {
-		return []string{}
+		return []types.Capability{}
+	}
+	return types.ParseCapabilities(strings.Split(capStr, ","))
+}
+
+func parseDependencies(depStr string) []string {
+	dependencies := []string{}
+	for _, dependency := range strings.Split(depStr, ",") {
+		if dependency = strings.TrimSpace(dependency); dependency != "" {
+			dependencies = append(dependencies, dependency)
+		}
 	}
-	return strings.Split(capStr, ",")
+	return dependencies
 }
 
 func parseMetadata(metaStr string) map[string]string {
@@ -121,15 +196,19 @@ func parseMetadata(metaStr string) map[string]string {
 // DistributedAgent is an agent that communicates only via Kafka/Redis (no shared memory)
 type DistributedAgent struct {
 	agent     *types.Agent
+	persona   *personas.Persona // nil when the role has no persona
 	messaging *messaging.KafkaMessaging
 	config    *types.Config
 	logger    *zap.Logger
 	ctx       context.Context
 	cancel    context.CancelFunc
+
+	tasksReceived atomic.Int64
 }
 
 func NewDistributedAgent(
 	agent *types.Agent,
+	persona *personas.Persona,
 	msg *messaging.KafkaMessaging,
 	cfg *types.Config,
 	logger *zap.Logger,
@@ -137,6 +216,7 @@ func NewDistributedAgent(
 	ctx, cancel := context.WithCancel(context.Background())
 	return &DistributedAgent{
 		agent:     agent,
+		persona:   persona,
 		messaging: msg,
 		config:    cfg,
 		logger:    logger.With(zap.String("agent_id", string(agent.ID))),
@@ -162,6 +242,9 @@ func (da *DistributedAgent) Start(ctx context.Context) error {
 	// Start message consumer
 	go da.consumeMessages()
 
+	// Start consumer for insights the knowledge manager pushes
+	go da.consumePushedInsights()
+
 	// Start heartbeat sender
 	go da.sendHeartbeats()
 
@@ -213,7 +296,7 @@ func (da *DistributedAgent) SendMessage(toAgentID types.AgentID, msgType types.M
 
 func (da *DistributedAgent) consumeMessages() {
 	groupID := fmt.Sprintf("agent-%s", da.agent.ID)
-	err := da.messaging.ConsumeMessages(da.ctx, "messages", groupID, func(msg *types.Message) error {
+	err := da.messaging.ConsumeMessages(da.ctx, "messages", groupID, func(ctx context.Context, msg *types.Message) error {
 		// Only process messages addressed to this agent
 		if msg.ToAgentID != da.agent.ID {
 			return nil
@@ -227,6 +310,11 @@ func (da *DistributedAgent) consumeMessages() {
 		// Process message and learn insights
 		da.processMessageAndLearn(msg)
 
+		// Answer tasks so the sender's route gets scored
+		if msg.Type == types.MessageTypeTask && msg.FromAgentID != da.agent.ID {
+			da.respondToTask(msg)
+		}
+
 		return nil
 	})
 
@@ -235,79 +323,52 @@ func (da *DistributedAgent) consumeMessages() {
 	}
 }
 
-// processMessageAndLearn handles a message and extracts insights
-func (da *DistributedAgent) processMessageAndLearn(msg *types.Message) {
-	// Simple rule-based insight generation
-	// In production, this would use LLM to analyze and learn
-
-	var insight *types.Insight
-
-	// Example: Sales agent learns from pricing-related messages
-	if da.agent.Role == "sales" {
-		if action, ok := msg.Payload["action"].(string); ok {
-			if action == "check_price" || action == "negotiate_price" {
-				insight = types.NewInsight(
-					da.agent.ID,
-					da.agent.Role,
-					types.InsightTypePricingIssue,
-					"pricing",
-					fmt.Sprintf("Customer interested in pricing for %v", msg.Payload["product"]),
-					0.7,
-				)
-			}
+// consumePushedInsights receives high-importance insights from other agents
+func (da *DistributedAgent) consumePushedInsights() {
+	groupID := fmt.Sprintf("agent-%s-push", da.agent.ID)
+	err := da.messaging.ConsumeMessages(da.ctx, "insights-push", groupID, func(ctx context.Context, msg *types.Message) error {
+		if msg.FromAgentID == da.agent.ID {
+			return nil
 		}
-	}
 
-	// Example: Support agent learns from customer complaints
-	if da.agent.Role == "support" {
-		if msgType := string(msg.Type); msgType == "task" {
-			if action, ok := msg.Payload["action"].(string); ok {
-				if action == "report_issue" {
-					insight = types.NewInsight(
-						da.agent.ID,
-						da.agent.Role,
-						types.InsightTypeProductIssue,
-						"product_quality",
-						fmt.Sprintf("Customer reported issue: %v", msg.Payload["issue"]),
-						0.85,
-					)
-				}
-			}
+		fields := []zap.Field{zap.String("from", string(msg.FromAgentID))}
+		if insight, ok := msg.Payload["insight"].(map[string]any); ok {
+			fields = append(fields, zap.Any("topic", insight["topic"]), zap.Any("content", insight["content"]))
 		}
+		da.logger.Info("Received pushed insight", fields...)
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		da.logger.Error("Pushed insight consumption stopped", zap.Error(err))
 	}
+}
 
-	// Example: Fraud agent learns from verification requests
-	if da.agent.Role == "fraud" {
-		if action, ok := msg.Payload["action"].(string); ok {
-			if action == "verify_user" || action == "check_transaction" {
-				insight = types.NewInsight(
-					da.agent.ID,
-					da.agent.Role,
-					types.InsightTypeFraudPattern,
-					"fraud_detection",
-					fmt.Sprintf("Verification requested for %v", msg.Payload["user_id"]),
-					0.6,
-				)
-			}
-		}
+// respondToTask sends a response to a task; tasks without an action fail,
+// as does the persona's FailureRate share of the rest
+func (da *DistributedAgent) respondToTask(task *types.Message) {
+	action := task.ActionName()
+	n := int(da.tasksReceived.Add(1)) - 1
+	success := action != "" && (da.persona == nil || !da.persona.Fails(n))
+	response := types.NewResponse(task, success, map[string]any{
+		"action":      action,
+		"description": fmt.Sprintf("%s handled %s", da.agent.Name, action),
+	})
+	response.Metadata["agent_role"] = da.agent.Role
+
+	if err := da.messaging.PublishMessage(da.ctx, "messages", response); err != nil {
+		da.logger.Error("Failed to send response", zap.Error(err))
 	}
+}
 
-	// Example: Inventory agent learns from stock patterns
-	if da.agent.Role == "inventory" {
-		if action, ok := msg.Payload["action"].(string); ok {
-			if action == "check_stock" {
-				// Track stock check frequency as inventory trend
-				insight = types.NewInsight(
-					da.agent.ID,
-					da.agent.Role,
-					types.InsightTypeInventoryTrend,
-					"inventory",
-					fmt.Sprintf("Stock check for SKU: %v", msg.Payload["sku"]),
-					0.5,
-				)
-			}
-		}
+// processMessageAndLearn handles a message and extracts insights
+func (da *DistributedAgent) processMessageAndLearn(msg *types.Message) {
+	// Simple rule-based insight generation from the persona
+	// In production, this would use LLM to analyze and learn
+	if da.persona == nil {
+		return
 	}
+	insight := da.persona.Learn(da.agent, msg)
 
 	// Publish insight to knowledge mesh
 	if insight != nil {
@@ -323,8 +384,10 @@ func (da *DistributedAgent) processMessageAndLearn(msg *types.Message) {
 	}
 }
 
+// sendHeartbeats publishes a heartbeat every HeartbeatInterval; agents silent
+// for AgentOfflineTimeout are marked offline by the topology manager
 func (da *DistributedAgent) sendHeartbeats() {
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(da.config.HeartbeatInterval)
 	defer ticker.Stop()
 
 	for {
@@ -333,6 +396,10 @@ func (da *DistributedAgent) sendHeartbeats() {
 			return
 		case <-ticker.C:
 			da.agent.LastSeenAt = time.Now()
+			if err := da.messaging.PublishHeartbeat(da.ctx, da.agent.ID); err != nil {
+				da.logger.Warn("Failed to send heartbeat", zap.Error(err))
+				continue
+			}
 			da.logger.Debug("Heartbeat")
 		}
 	}
@@ -346,166 +413,50 @@ func (da *DistributedAgent) simulateBusinessLogic() {
 	// Then wait for periodic messaging
 	time.Sleep(5 * time.Second)
 
-	// Define agent interactions based on role
-	ticker := time.NewTicker(10 * time.Second)
+	if da.persona == nil {
+		return
+	}
+
+	ticker := time.NewTicker(da.persona.Params.Interval)
 	defer ticker.Stop()
 
 	counter := 0
+	var throttle types.IncidentThrottle
 	for {
 		select {
 		case <-da.ctx.Done():
 			return
 		case <-ticker.C:
-			counter++
-
-			// Sales agent creates orders and checks inventory
-			if da.agent.Role == "sales" {
-				if counter%2 == 0 {
-					// Send to Inventory agent (role-based, will find any inventory agent)
-					productName := fmt.Sprintf("Product-%d", counter)
-					da.sendToRole("inventory", types.MessageTypeTask, map[string]any{
-						"action":      "check_stock",
-						"product":     productName,
-						"qty":         counter % 10,
-						"description": fmt.Sprintf("Check stock availability for %s (qty: %d)", productName, counter%10),
-					})
-				}
-				if counter%3 == 0 {
-					// Send to Fraud agent
-					orderID := fmt.Sprintf("ORD-%d", counter)
-					amount := float64(counter * 100)
-					da.sendToRole("fraud", types.MessageTypeTask, map[string]any{
-						"action":      "verify_transaction",
-						"order_id":    orderID,
-						"amount":      amount,
-						"description": fmt.Sprintf("Verify transaction %s ($%.2f)", orderID, amount),
-					})
-				}
-			}
-
-			// Support agent creates tickets and escalates to multiple teams
-			if da.agent.Role == "support" {
-				targets := []string{"sales", "inventory", "fraud"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%2 == 0 {
-					ticketID := fmt.Sprintf("TKT-%d", counter)
-					action := "escalate"
-					issueType := "pricing_complaint"
-
-					if targetRole == "inventory" {
-						action = "check_delivery"
-						issueType = "shipping_delay"
-					} else if targetRole == "fraud" {
-						action = "verify_account"
-						issueType = "suspicious_activity"
-					}
-
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      action,
-						"ticket_id":   ticketID,
-						"issue_type":  issueType,
-						"description": fmt.Sprintf("Support %s for ticket %s - %s", action, ticketID, issueType),
-					})
-				}
-			}
-
-			// Inventory agent notifies Sales and Support
-			if da.agent.Role == "inventory" {
-				targets := []string{"sales", "support"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%2 == 0 {
-					productName := fmt.Sprintf("Product-%d", counter)
-					action := "stock_alert"
-					level := "low"
-
-					if targetRole == "support" {
-						action = "delivery_update"
-						level = "delayed"
-					}
-
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      action,
-						"product":     productName,
-						"level":       level,
-						"description": fmt.Sprintf("%s for %s - status: %s", action, productName, level),
-					})
-				}
-			}
-
-			// Fraud agent reports to Sales and Support
-			if da.agent.Role == "fraud" {
-				targets := []string{"sales", "support"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%3 == 0 {
-					txnID := fmt.Sprintf("TXN-%d", counter)
-					action := "fraud_alert"
-					riskLevel := "medium"
-
-					if targetRole == "support" {
-						action = "account_suspension"
-						riskLevel = "high"
-					}
-
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      action,
-						"transaction": txnID,
-						"risk_level":  riskLevel,
-						"description": fmt.Sprintf("%s for transaction %s - risk: %s", action, txnID, riskLevel),
-					})
-				}
-			}
-
-			// Research agent (OpenAI) sends research requests and findings
-			if da.agent.Role == "research" {
-				targets := []string{"sales", "support", "inventory"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%2 == 0 {
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      "research_request",
-						"topic":       fmt.Sprintf("market_trend_%d", counter),
-						"priority":    "high",
-						"description": fmt.Sprintf("OpenAI Research: Requesting %s data for market analysis #%d", targetRole, counter),
-					})
-				}
+			// Simulated traffic slows down during an incident
+			if !throttle.Allow(da.incidentActive(), da.config.IncidentSlowdown) {
+				continue
 			}
+			counter++
 
-			// Market Analyst (LangChain) sends analysis reports and forecasts
-			if da.agent.Role == "analyst" {
-				targets := []string{"sales", "inventory", "fraud"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%2 == 0 {
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      "analysis_report",
-						"metric":      fmt.Sprintf("kpi_%d", counter),
-						"trend":       "increasing",
-						"description": fmt.Sprintf("LangChain Analyst: Market analysis report #%d for %s", counter, targetRole),
-					})
-				}
+			actions, reports := da.persona.Tick(counter)
+			for _, action := range actions {
+				da.sendToRole(action.TargetRole, action.Type, action.Payload)
 			}
-
-			// Coordinator (Anthropic) sends coordination updates and health checks
-			if da.agent.Role == "coordinator" {
-				targets := []string{"sales", "support", "inventory", "fraud", "research", "analyst"}
-				targetRole := targets[counter%len(targets)]
-
-				if counter%2 == 0 {
-					da.sendToRole(targetRole, types.MessageTypeTask, map[string]any{
-						"action":      "coordination_update",
-						"status":      "all_systems_operational",
-						"check_id":    fmt.Sprintf("health_check_%d", counter),
-						"description": fmt.Sprintf("Anthropic Coordinator: System health check #%d - %s status OK", counter, targetRole),
-					})
-				}
+			for _, report := range reports {
+				da.reportGoalProgress(report.GoalID, report.Value)
 			}
 		}
 	}
 }
 
+// reportGoalProgress publishes a goal measurement as a typed insight
+func (da *DistributedAgent) reportGoalProgress(goalID types.GoalID, value float64) {
+	insight := types.NewGoalProgressInsight(da.agent.ID, da.agent.Role, goalID, value)
+	if err := da.messaging.PublishInsight(da.ctx, insight); err != nil {
+		da.logger.Error("Failed to publish goal progress", zap.Error(err))
+		return
+	}
+	da.logger.Debug("Reported goal progress",
+		zap.String("goal_id", string(goalID)),
+		zap.Float64("value", value),
+	)
+}
+
 // sendInitialMessage sends an initial self-message to create the edge immediately
 func (da *DistributedAgent) sendInitialMessage() {
 	message := &types.Message{
@@ -563,8 +514,14 @@ func (da *DistributedAgent) sendToRole(role string, msgType types.MessageType, p
 	}
 }
 
-// findAgentByRole queries the topology API to find an agent with the given role
+// findAgentByRole asks the API which agent with the given role should get the task,
+// falling back to one of the agents with the role in the topology, drawn by the
+// strength of the edge to each so that tasks spread over all of them
 func (da *DistributedAgent) findAgentByRole(role string) types.AgentID {
+	if targetID := da.selectRoute(role); targetID != "" {
+		return targetID
+	}
+
 	resp, err := http.Get("http://localhost:8080/api/topology")
 	if err != nil {
 		return ""
@@ -573,20 +530,65 @@ func (da *DistributedAgent) findAgentByRole(role string) types.AgentID {
 
 	var topologyData struct {
 		Agents map[types.AgentID]*types.Agent `json:"agents"`
+		Edges  map[types.EdgeID]*types.Edge   `json:"edges"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&topologyData); err != nil {
 		return ""
 	}
 
-	// Find first agent with matching role (excluding self)
+	// Agents with matching role in our namespace (excluding self)
+	candidates := []types.RouteCandidate{}
 	for id, agent := range topologyData.Agents {
-		if agent.Role == role && id != da.agent.ID {
-			return id
+		if agent.Role != role || id == da.agent.ID || !agent.Routable() || !agent.SameNamespace(da.agent) {
+			continue
 		}
+		candidate := types.RouteCandidate{AgentID: id, Role: agent.Role}
+		if edge, ok := topologyData.Edges[da.config.EdgeMode.EdgeID(da.agent.ID, id)]; ok {
+			candidate.Weight, candidate.Score, candidate.LatencyMs = edge.Weight, edge.Weight, edge.LatencyP50Ms
+		}
+		candidates = append(candidates, candidate)
+	}
+	if selected, ok := routing.Balance(routing.PreferLowLatency(da.config, candidates)); ok {
+		return selected.AgentID
 	}
 	return ""
 }
 
+// selectRoute returns the agent the routing API selects for a task to the given role
+func (da *DistributedAgent) selectRoute(role string) types.AgentID {
+	query := url.Values{"from": {string(da.agent.ID)}, "role": {role}}
+	resp, err := http.Get("http://localhost:8080/api/routing/candidates?" + query.Encode())
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	var candidates struct {
+		Selected types.AgentID `json:"selected"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&candidates) != nil {
+		return ""
+	}
+	return candidates.Selected
+}
+
+// incidentActive asks the API whether the mesh is in incident mode
+func (da *DistributedAgent) incidentActive() bool {
+	resp, err := http.Get("http://localhost:8080/api/incident")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var incident struct {
+		Active bool `json:"active"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&incident) != nil {
+		return false
+	}
+	return incident.Active
+}
+
 // findRandomAgent returns a random agent ID from the topology (excluding self)
 func (da *DistributedAgent) findRandomAgent() types.AgentID {
 	resp, err := http.Get("http://localhost:8080/api/topology")
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// runAttest signs a capability attestation for an agent with an operator CA key,
+// or generates a new CA key pair with -keygen
+func runAttest(args []string) error {
+	fs := flag.NewFlagSet("attest", flag.ExitOnError)
+	keygen := fs.Bool("keygen", false, "Generate an operator CA key pair instead of signing")
+	keyFile := fs.String("key", "", "File holding the base64 ed25519 private key of the CA")
+	issuer := fs.String("issuer", "", "CA name, as listed in ATTESTATION_CA_KEYS")
+	agentID := fs.String("agent", "", "ID of the agent the attestation is about")
+	capabilities := fs.String("capabilities", "", "Comma-separated attested capability names")
+	dataAccess := fs.String("data-access", "", "Comma-separated attested data access scopes")
+	ttl := fs.Duration("ttl", 0, "Validity of the attestation, 0 = no expiry")
+	fs.Parse(args)
+
+	if *keygen {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		fmt.Printf("private key: %s\n", base64.StdEncoding.EncodeToString(private))
+		fmt.Printf("public key:  %s\n", base64.StdEncoding.EncodeToString(public))
+		return nil
+	}
+
+	if *keyFile == "" || *issuer == "" || *agentID == "" {
+		return fmt.Errorf("-key, -issuer and -agent are required")
+	}
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("%s does not hold a base64 ed25519 private key", *keyFile)
+	}
+
+	attestation := types.Attestation{
+		Subject:      types.AgentID(*agentID),
+		Issuer:       *issuer,
+		Capabilities: splitList(*capabilities),
+		DataAccess:   splitList(*dataAccess),
+		IssuedAt:     time.Now().UTC(),
+	}
+	if *ttl > 0 {
+		attestation.ExpiresAt = attestation.IssuedAt.Add(*ttl)
+	}
+	attestation.Sign(ed25519.PrivateKey(key))
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(attestation)
+}
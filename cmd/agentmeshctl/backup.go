@@ -0,0 +1,287 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+)
+
+// backupFormatVersion is bumped whenever the archive layout changes
+const backupFormatVersion = 1
+
+// Archive members
+const (
+	manifestFile = "manifest.json"
+	redisFile    = "redis.json"
+	offsetsFile  = "kafka_offsets.json"
+)
+
+// backupManifest describes the contents of a backup archive
+type backupManifest struct {
+	Version          int       `json:"version"`
+	CreatedAt        time.Time `json:"created_at"`
+	RedisAddr        string    `json:"redis_addr"`
+	RedisDB          int       `json:"redis_db"`
+	KafkaTopicPrefix string    `json:"kafka_topic_prefix"`
+	KeyCount         int       `json:"key_count"`
+	GroupCount       int       `json:"group_count"`
+}
+
+// runBackup dumps all Redis keys plus Kafka consumer offsets into a tar.gz archive
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", fmt.Sprintf("agentmesh-backup-%s.tar.gz", time.Now().Format("20060102-150405")), "Output archive path")
+	pattern := fs.String("match", "*", "Redis key pattern to include")
+	skipKafka := fs.Bool("skip-kafka", false, "Do not record Kafka consumer offsets")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	logger := newLogger(*verbose)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	store, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	dumps, err := store.DumpKeys(ctx, *pattern)
+	if err != nil {
+		return err
+	}
+
+	offsets := []messaging.GroupOffsets{}
+	if !*skipKafka {
+		km := messaging.NewKafkaMessaging(cfg, logger)
+		defer km.Close()
+
+		offsets, err = km.ConsumerGroupOffsets(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read Kafka offsets (use -skip-kafka to ignore): %w", err)
+		}
+	}
+
+	manifest := backupManifest{
+		Version:          backupFormatVersion,
+		CreatedAt:        time.Now(),
+		RedisAddr:        cfg.RedisAddr,
+		RedisDB:          cfg.RedisDB,
+		KafkaTopicPrefix: cfg.KafkaTopicPrefix,
+		KeyCount:         len(dumps),
+		GroupCount:       len(offsets),
+	}
+
+	if err := writeBackup(*output, manifest, dumps, offsets); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backup written to %s (%d keys, %d consumer groups)\n", *output, len(dumps), len(offsets))
+	return nil
+}
+
+// runRestore reloads Redis keys and optionally consumer offsets from an archive
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := fs.String("i", "", "Input archive path (required)")
+	overwrite := fs.Bool("overwrite", false, "Overwrite keys that already exist")
+	restoreOffsets := fs.Bool("restore-offsets", false, "Commit recorded consumer group offsets (groups must be stopped)")
+	dryRun := fs.Bool("dry-run", false, "Print archive contents without writing anything")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	if *input == "" {
+		return fmt.Errorf("-i <archive> is required")
+	}
+
+	manifest, dumps, offsets, err := readBackup(*input)
+	if err != nil {
+		return err
+	}
+	if manifest.Version > backupFormatVersion {
+		return fmt.Errorf("archive version %d is newer than supported version %d", manifest.Version, backupFormatVersion)
+	}
+
+	fmt.Printf("Archive created %s from %s (db %d): %d keys, %d consumer groups\n",
+		manifest.CreatedAt.Format(time.RFC3339), manifest.RedisAddr, manifest.RedisDB, len(dumps), len(offsets))
+	if *dryRun {
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	logger := newLogger(*verbose)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	store, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	restored, skipped, err := store.RestoreKeys(ctx, dumps, *overwrite)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d keys (%d skipped)\n", restored, skipped)
+
+	if *restoreOffsets {
+		if manifest.KafkaTopicPrefix != cfg.KafkaTopicPrefix {
+			return fmt.Errorf("archive topic prefix %q does not match configured prefix %q", manifest.KafkaTopicPrefix, cfg.KafkaTopicPrefix)
+		}
+
+		km := messaging.NewKafkaMessaging(cfg, logger)
+		defer km.Close()
+
+		for _, group := range offsets {
+			if err := km.CommitGroupOffsets(ctx, group); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Committed offsets for %d consumer groups\n", len(offsets))
+	}
+
+	return nil
+}
+
+// readBackup extracts the manifest, Redis dumps and offsets from an archive
+func readBackup(path string) (*backupManifest, []state.KeyDump, []messaging.GroupOffsets, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *backupManifest
+	dumps := []state.KeyDump{}
+	offsets := []messaging.GroupOffsets{}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		decoder := json.NewDecoder(tr)
+		switch header.Name {
+		case manifestFile:
+			manifest = &backupManifest{}
+			err = decoder.Decode(manifest)
+		case redisFile:
+			err = decoder.Decode(&dumps)
+		case offsetsFile:
+			err = decoder.Decode(&offsets)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode %s: %w", header.Name, err)
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, nil, fmt.Errorf("archive has no %s", manifestFile)
+	}
+
+	return manifest, dumps, offsets, nil
+}
+
+// writeBackup writes an archive to a temporary file next to path and renames
+// it into place once complete, so a failed backup leaves no truncated archive
+func writeBackup(path string, manifest backupManifest, dumps []state.KeyDump, offsets []messaging.GroupOffsets) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	if err := writeArchive(file, manifest, dumps, offsets); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// writeArchive writes the gzipped tar members of a backup
+func writeArchive(w io.Writer, manifest backupManifest, dumps []state.KeyDump, offsets []messaging.GroupOffsets) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	members := []struct {
+		name  string
+		value interface{}
+	}{
+		{manifestFile, manifest},
+		{redisFile, dumps},
+		{offsetsFile, offsets},
+	}
+	for _, m := range members {
+		if err := writeTarJSON(tw, m.name, m.value); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+// writeTarJSON adds a JSON-encoded member to a tar archive
+func writeTarJSON(tw *tar.Writer, name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+)
+
+// maxClockSkew is how far apart service clocks may drift before doctor warns;
+// insight ranking, proposal timeouts and snapshot ages all compare timestamps across services
+const maxClockSkew = time.Second
+
+// requiredTopics are consumed by the managers; the other mesh topics are only published to
+var requiredTopics = []string{"topology", "messages", "proposals", "votes", "insights", "lifecycle"}
+
+// diagnosis collects doctor's findings
+type diagnosis struct {
+	failures int
+	warnings int
+}
+
+func (d *diagnosis) ok(format string, args ...any) {
+	fmt.Printf("  [ok]   %s\n", fmt.Sprintf(format, args...))
+}
+
+func (d *diagnosis) warn(format string, args ...any) {
+	d.warnings++
+	fmt.Printf("  [warn] %s\n", fmt.Sprintf(format, args...))
+}
+
+func (d *diagnosis) fail(format string, args ...any) {
+	d.failures++
+	fmt.Printf("  [FAIL] %s\n", fmt.Sprintf(format, args...))
+}
+
+// runDoctor checks configuration, Redis, Kafka and service clocks before a deployment goes live
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	timeout := fs.Duration("timeout", time.Minute, "Time allowed for all checks")
+	strict := fs.Bool("strict", false, "Fail on warnings too")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	effective, err := config.LoadEffective() // Invalid settings are reported below
+	if effective == nil {
+		return err
+	}
+	cfg := effective.Config
+	logger := newLogger(*verbose)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	d := &diagnosis{}
+
+	fmt.Println("Configuration")
+	if effective.File != "" {
+		d.ok("read %s (profile %q)", effective.File, effective.Profile)
+	}
+	problems := config.Problems(cfg)
+	for _, problem := range problems {
+		d.fail("%s", problem)
+	}
+	warnings := append(config.Warnings(cfg), effective.Ignored...)
+	for _, warning := range warnings {
+		d.warn("%s", warning)
+	}
+	if len(problems) == 0 && len(warnings) == 0 {
+		d.ok("settings are consistent")
+	}
+
+	fmt.Println("Redis")
+	store, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		d.fail("cannot reach Redis at %s (db %d): %v; check REDIS_ADDR and REDIS_DB", cfg.RedisAddr, cfg.RedisDB, err)
+	} else {
+		defer store.Close()
+		d.ok("reachable at %s (db %d)", cfg.RedisAddr, cfg.RedisDB)
+		checkSchemas(ctx, d, store)
+	}
+
+	fmt.Println("Kafka")
+	km := messaging.NewKafkaMessaging(cfg, logger)
+	defer km.Close()
+	if err := km.Ping(ctx); err != nil {
+		d.fail("cannot reach brokers %s: %v; check KAFKA_BROKERS", strings.Join(cfg.KafkaBrokers, ","), err)
+	} else {
+		d.ok("brokers %s reachable", strings.Join(cfg.KafkaBrokers, ","))
+		checkTopics(ctx, d, km, cfg.KafkaTopicPrefix)
+	}
+
+	fmt.Println("Clocks")
+	if store == nil {
+		d.warn("not checked without Redis")
+	} else {
+		checkClocks(ctx, d, store)
+	}
+
+	fmt.Println()
+	fmt.Printf("%d failure(s), %d warning(s)\n", d.failures, d.warnings)
+	if d.failures > 0 || (*strict && d.warnings > 0) {
+		return fmt.Errorf("deployment is not ready")
+	}
+	return nil
+}
+
+// checkSchemas reports persisted records older than the current schema versions
+func checkSchemas(ctx context.Context, d *diagnosis, store *state.RedisStore) {
+	reports, err := store.MigrateAll(ctx, true)
+	if err != nil {
+		d.warn("could not check record schemas: %v", err)
+		return
+	}
+	stale := 0
+	for _, r := range reports {
+		stale += r.Migrated
+	}
+	if stale > 0 {
+		d.warn("%d record(s) use an old schema version; run agentmeshctl migrate", stale)
+		return
+	}
+	d.ok("persisted records are on current schema versions")
+}
+
+// checkTopics reports missing mesh topics and partitions without a leader
+func checkTopics(ctx context.Context, d *diagnosis, km *messaging.KafkaMessaging, prefix string) {
+	topics, err := km.DescribeTopics(ctx, requiredTopics...)
+	if err != nil {
+		d.fail("cannot read topic metadata: %v", err)
+		return
+	}
+	healthy := true
+	for _, name := range requiredTopics {
+		topic, ok := topics[name]
+		switch {
+		case !ok:
+			healthy = false
+			d.warn("topic %s.%s does not exist; create it, or the brokers must auto-create topics on first publish", prefix, name)
+		case topic.Unavailable > 0:
+			healthy = false
+			d.fail("topic %s.%s has %d of %d partition(s) without a leader; check broker health", prefix, name, topic.Unavailable, topic.Partitions)
+		}
+	}
+	if healthy {
+		d.ok("topics %s.{%s} exist and have leaders", prefix, strings.Join(requiredTopics, ","))
+	}
+}
+
+// checkClocks compares this host's clock and those of the running services against Redis's
+func checkClocks(ctx context.Context, d *diagnosis, store *state.RedisStore) {
+	offset, err := store.ClockOffset(ctx)
+	if err != nil {
+		d.warn("could not read the Redis clock: %v", err)
+		return
+	}
+	if offset > maxClockSkew || offset < -maxClockSkew {
+		d.warn("this host's clock is %s off Redis's; check NTP", offset.Round(time.Millisecond))
+	}
+
+	reports, err := store.ListServiceHealth(ctx)
+	if err != nil {
+		d.warn("could not read service health reports: %v", err)
+		return
+	}
+	if len(reports) == 0 {
+		d.warn("no services are reporting health yet, so skew between services was not checked")
+		return
+	}
+
+	earliest, latest := reports[0], reports[0]
+	for _, r := range reports[1:] {
+		if r.ClockOffset < earliest.ClockOffset {
+			earliest = r
+		}
+		if r.ClockOffset > latest.ClockOffset {
+			latest = r
+		}
+	}
+	if skew := latest.ClockOffset - earliest.ClockOffset; skew > maxClockSkew {
+		d.warn("clocks of %s@%s and %s@%s are %s apart; check NTP on their hosts",
+			latest.Service, latest.Instance, earliest.Service, earliest.Instance, skew.Round(time.Millisecond))
+		return
+	}
+	d.ok("%d service instance(s) within %s of each other", len(reports), maxClockSkew)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// agentmeshctl: operator CLI for administering a running mesh
+// Talks directly to Kafka and Redis using the same environment configuration as the services
+
+// command is a single agentmeshctl subcommand
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"attest":   {summary: "Sign an agent capability attestation with an operator CA key", run: runAttest},
+	"backup":   {summary: "Dump Redis state and Kafka consumer offsets to an archive", run: runBackup},
+	"doctor":   {summary: "Check config, Redis, Kafka and service clocks before going live", run: runDoctor},
+	"migrate":  {summary: "Upgrade persisted records to the current schema versions", run: runMigrate},
+	"query":    {summary: "Run an AMQL query over insights or the topology", run: runQuery},
+	"record":   {summary: "Capture live mesh traffic to a recording file", run: runRecord},
+	"replay":   {summary: "Publish a recording to a test mesh at original or accelerated speed", run: runReplay},
+	"restore":  {summary: "Reload Redis state (and optionally offsets) from an archive", run: runRestore},
+	"simulate": {summary: "Replay a recording offline against other topology parameters", run: runSimulate},
+	"topology": {summary: "Compare the topology at two points in time (topology diff)", run: runTopology},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(1)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: agentmeshctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// newLogger returns a quiet logger unless verbose output was requested
+func newLogger(verbose bool) *zap.Logger {
+	if !verbose {
+		return zap.NewNop()
+	}
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+)
+
+// runMigrate upgrades all persisted records to the current schema versions
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Report stale records without rewriting them")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	logger := newLogger(*verbose)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	store, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	reports, err := store.MigrateAll(ctx, *dryRun)
+	for _, r := range reports {
+		verb := "migrated"
+		if *dryRun {
+			verb = "stale"
+		}
+		fmt.Printf("%-15s v%d  scanned %d, %s %d, failed %d\n", r.Schema, r.Version, r.Scanned, verb, r.Migrated, r.Failed)
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// runQuery runs an AMQL query against the API server, which parses and
+// evaluates it, and prints the matching records
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	api := fs.String("api", "http://localhost:8080", "Base URL of the AgentMesh API server")
+	agentID := fs.String("agent", "", "Query insights as this agent (applies its visibility rules)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: agentmeshctl query [-api url] [-agent id] \"SELECT insights WHERE ...\"")
+	}
+	body, err := json.Marshal(map[string]string{"query": strings.Join(fs.Args(), " ")})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(*api, "/") + "/api/amql"
+	if *agentID != "" {
+		endpoint += "?agent_id=" + url.QueryEscape(*agentID)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to reach API server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("query failed (%s): %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Results []map[string]any `json:"results"`
+		Count   int              `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result.Results); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d result(s)\n", result.Count)
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/replay"
+)
+
+// runRecord captures live mesh traffic into a recording file
+func runRecord(args []string) error {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	output := fs.String("o", fmt.Sprintf("agentmesh-traffic-%s.jsonl", time.Now().Format("20060102-150405")), "Output recording path")
+	topics := fs.String("topics", strings.Join(replay.DefaultTopics, ","), "Comma-separated topics to capture")
+	duration := fs.Duration("duration", 0, "Stop after this long (0 records until interrupted)")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	logger := newLogger(*verbose)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+	defer file.Close()
+
+	names := splitList(*topics)
+	recorder, err := replay.NewRecorder(file, cfg.KafkaTopicPrefix, names)
+	if err != nil {
+		return err
+	}
+
+	km := messaging.NewKafkaMessaging(cfg, logger)
+	defer km.Close()
+
+	// A capture-scoped group starts at the head of each topic and leaves the mesh's groups untouched
+	groupID := fmt.Sprintf("agentmeshctl-record-%d", time.Now().UnixNano())
+	var wg sync.WaitGroup
+	for _, topic := range names {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			km.TailRaw(ctx, topic, groupID, func(key, value []byte, at time.Time) error {
+				if at.IsZero() {
+					at = time.Now()
+				}
+				return recorder.Record(topic, key, value, at)
+			})
+		}(topic)
+	}
+
+	fmt.Printf("Recording %s to %s (Ctrl+C to stop)\n", strings.Join(names, ", "), *output)
+	wg.Wait()
+
+	if err := recorder.Flush(); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	fmt.Printf("Recorded %d records\n", recorder.Count())
+	return nil
+}
+
+// runReplay publishes a recording to the configured (test) mesh
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	input := fs.String("i", "", "Input recording path (required)")
+	speed := fs.Float64("speed", 1, "Playback speed multiplier (0 replays as fast as possible)")
+	prefix := fs.String("prefix", "", "Kafka topic prefix to replay into (default: configured prefix)")
+	topics := fs.String("topics", "", "Comma-separated topics to replay (default: all recorded)")
+	retime := fs.Bool("retime", true, "Stamp replayed records with the current time")
+	force := fs.Bool("force", false, "Allow replaying into the topic prefix the traffic was recorded from")
+	verbose := fs.Bool("v", false, "Verbose logging")
+	fs.Parse(args)
+
+	if *input == "" {
+		return fmt.Errorf("-i <recording> is required")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := replay.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if *prefix != "" {
+		cfg.KafkaTopicPrefix = *prefix
+	}
+	if cfg.KafkaTopicPrefix == reader.Header.TopicPrefix && !*force {
+		return fmt.Errorf("recording is from topic prefix %q, the replay target; use -prefix for a test mesh or -force", cfg.KafkaTopicPrefix)
+	}
+
+	only := make(map[string]bool)
+	for _, topic := range splitList(*topics) {
+		only[topic] = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	km := messaging.NewKafkaMessaging(cfg, newLogger(*verbose))
+	defer km.Close()
+
+	fmt.Printf("Replaying traffic recorded %s into %q at %gx\n",
+		reader.Header.StartedAt.Format(time.RFC3339), cfg.KafkaTopicPrefix, *speed)
+
+	skipped := 0
+	played, err := replay.Play(ctx, reader, *speed, func(entry replay.Entry) error {
+		if len(only) > 0 && !only[entry.Topic] {
+			skipped++
+			return nil
+		}
+		value := entry.Value
+		if *retime {
+			var err error
+			if value, err = replay.Retime(value, time.Now()); err != nil {
+				return err
+			}
+		}
+		return km.PublishRaw(ctx, entry.Topic, []byte(entry.Key), value)
+	})
+	fmt.Printf("Replayed %d records (%d skipped)\n", played-skipped, skipped)
+	return err
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// runTopology runs a topology subcommand against the API server
+func runTopology(args []string) error {
+	if len(args) == 0 || args[0] != "diff" {
+		return fmt.Errorf("usage: agentmeshctl topology diff [flags] <from> [<to>]")
+	}
+	return runTopologyDiff(args[1:])
+}
+
+// runTopologyDiff prints how the topology changed between two points in
+// time, e.g. before and after a deployment
+func runTopologyDiff(args []string) error {
+	fs := flag.NewFlagSet("topology diff", flag.ExitOnError)
+	api := fs.String("api", "http://localhost:8080", "Base URL of the AgentMesh API server")
+	top := fs.Int("top", 10, "Show this many of the largest weight changes (0 for all)")
+	minChange := fs.Float64("min-change", 0, "Leave out weight changes smaller than this")
+	namespace := fs.String("namespace", "", "Compare only this namespace")
+	region := fs.String("region", "", "Compare only this region")
+	asJSON := fs.Bool("json", false, "Print the diff as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("usage: agentmeshctl topology diff [flags] <from> [<to>], each an RFC 3339 time, a duration ago (e.g. 2h) or now (default to)")
+	}
+	now := time.Now()
+	from, err := parseDiffTime(fs.Arg(0), now)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("from", from.Format(time.RFC3339))
+	if fs.NArg() == 2 && fs.Arg(1) != "now" {
+		to, err := parseDiffTime(fs.Arg(1), now)
+		if err != nil {
+			return err
+		}
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	query.Set("top", strconv.Itoa(*top))
+	if *minChange > 0 {
+		query.Set("min_change", strconv.FormatFloat(*minChange, 'g', -1, 64))
+	}
+	if *namespace != "" {
+		query.Set("namespace", *namespace)
+	}
+	if *region != "" {
+		query.Set("region", *region)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(strings.TrimRight(*api, "/") + "/api/topology/diff?" + query.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to reach API server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("diff failed (%s): %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var diff types.TopologyDiff
+	if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	printTopologyDiff(&diff)
+	return nil
+}
+
+// parseDiffTime parses an RFC 3339 time, a duration before now or "now"
+func parseDiffTime(value string, now time.Time) (time.Time, error) {
+	if value == "now" {
+		return now, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil || ago < 0 {
+		return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time, a duration ago or now", value)
+	}
+	return now.Add(-ago), nil
+}
+
+// printTopologyDiff prints a diff for review
+func printTopologyDiff(diff *types.TopologyDiff) {
+	fmt.Printf("Topology %s → %s (%s)\n\n", diff.From.Format(time.RFC3339), diff.To.Format(time.RFC3339), diff.To.Sub(diff.From).Round(time.Second))
+
+	before, after, delta := diff.StatsBefore, diff.StatsAfter, diff.StatsDelta
+	fmt.Printf("%-12s %10s %10s %10s\n", "", "BEFORE", "AFTER", "CHANGE")
+	fmt.Printf("%-12s %10d %10d %+10d\n", "agents", before.TotalAgents, after.TotalAgents, delta.Agents)
+	fmt.Printf("%-12s %10d %10d %+10d\n", "edges", before.TotalEdges, after.TotalEdges, delta.Edges)
+	fmt.Printf("%-12s %10d %10d %+10d\n", "active", before.ActiveEdges, after.ActiveEdges, delta.ActiveEdges)
+	fmt.Printf("%-12s %10.3f %10.3f %+10.3f\n", "avg weight", before.AverageWeight, after.AverageWeight, delta.AverageWeight)
+	fmt.Printf("%-12s %10.3f %10.3f %+10.3f\n", "density", before.Density, after.Density, delta.Density)
+	fmt.Printf("%-12s %9.1f%% %9.1f%% %+9.1f%%\n", "reduction", before.ReductionPercent, after.ReductionPercent, delta.ReductionPercent)
+	fmt.Printf("%-12s %10d %10d %+10d\n", "communities", before.Communities, after.Communities, delta.Communities)
+	fmt.Printf("%-12s %10d %10d %+10d\n", "components", before.Components, after.Components, delta.Components)
+
+	fmt.Printf("\nAgents: %d joined, %d left\n", len(diff.AddedAgents), len(diff.RemovedAgents))
+	for _, agent := range diff.AddedAgents {
+		fmt.Printf("  + %s (%s)\n", agent.ID, agent.Role)
+	}
+	for _, agent := range diff.RemovedAgents {
+		fmt.Printf("  - %s (%s)\n", agent.ID, agent.Role)
+	}
+
+	fmt.Printf("\nEdges: %d formed, %d pruned, %d strengthened, %d weakened\n",
+		len(diff.AddedEdges), len(diff.PrunedEdges), diff.Strengthened, diff.Weakened)
+	for _, edge := range diff.AddedEdges {
+		fmt.Printf("  + %s (%.3f)\n", edge.ID, edge.Weight)
+	}
+	for _, edge := range diff.PrunedEdges {
+		fmt.Printf("  - %s (%.3f)\n", edge.ID, edge.Weight)
+	}
+
+	if len(diff.WeightChanges) > 0 {
+		fmt.Printf("\nLargest weight changes:\n")
+		for _, change := range diff.WeightChanges {
+			fmt.Printf("  %s  %.3f → %.3f (%+.3f)\n", change.EdgeID, change.Before, change.After, change.Delta)
+		}
+	}
+}
@@ -3,22 +3,39 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/avinashshinde/agentmesh-cortex/internal/amql"
+	"github.com/avinashshinde/agentmesh-cortex/internal/analytics"
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/digest"
+	"github.com/avinashshinde/agentmesh-cortex/internal/health"
+	"github.com/avinashshinde/agentmesh-cortex/internal/manager"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
 	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/internal/views"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
+// incidentCheckInterval is how often the health is checked for automatic incidents
+const incidentCheckInterval = 15 * time.Second
+
 // APIServer provides REST API access to AgentMesh collective knowledge
 
 func main() {
@@ -33,7 +50,14 @@ func main() {
 	logger.Info("Starting AgentMesh API Server")
 
 	// Load configuration
-	cfg := config.Load()
+	effective, err := config.LoadEffective()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	cfg := effective.Config
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
 
 	// Initialize Kafka messaging
 	messaging := messaging.NewKafkaMessaging(cfg, logger)
@@ -48,6 +72,15 @@ func main() {
 
 	// Create API server
 	server := NewAPIServer(messaging, stateStore, cfg, logger)
+	server.effective = effective
+
+	// Report our health for the system health rollup
+	reportCtx, stopReporting := context.WithCancel(context.Background())
+	defer stopReporting()
+	go health.NewReporter("api-server", stateStore, messaging, logger).Run(reportCtx)
+
+	// Declare and clear incidents on health changes, with INCIDENT_AUTO_STATUS
+	go server.watchIncidents(reportCtx)
 
 	// Start HTTP server
 	port := 8080
@@ -85,6 +118,7 @@ type APIServer struct {
 	messaging  *messaging.KafkaMessaging
 	stateStore *state.RedisStore
 	config     *types.Config
+	effective  *config.Effective // Where each setting came from
 	logger     *zap.Logger
 }
 
@@ -107,10 +141,15 @@ func (api *APIServer) setupRoutes() http.Handler {
 
 	// Health check
 	mux.HandleFunc("/health", api.handleHealth)
+	mux.HandleFunc("/api/system/health", api.handleSystemHealth)
+	mux.HandleFunc("/api/config/effective", api.handleEffectiveConfig)
+	mux.HandleFunc("/api/incident", api.handleIncident)
 
 	// Insights endpoints
 	mux.HandleFunc("/api/insights", api.handleQueryInsights)
 	mux.HandleFunc("/api/insights/search", api.handleSearchInsights)
+	mux.HandleFunc("/api/insights/batch", api.handleInsightBatch)
+	mux.HandleFunc("/api/insights/embeddings", api.handleEmbeddingStatus)
 
 	// Agent endpoints
 	mux.HandleFunc("/api/agents", api.handleListAgents)
@@ -119,6 +158,82 @@ func (api *APIServer) setupRoutes() http.Handler {
 	// Topology endpoints
 	mux.HandleFunc("/api/topology", api.handleGetTopology)
 	mux.HandleFunc("/api/topology/stats", api.handleTopologyStats)
+	mux.HandleFunc("/api/topology/history", api.handleTopologyHistory)
+	mux.HandleFunc("/api/topology/at", api.handleTopologyAt)
+	mux.HandleFunc("/api/topology/diff", api.handleTopologyDiff)
+	mux.HandleFunc("/api/topology/export", api.handleTopologyExport)
+	mux.HandleFunc("/api/topology/guardrails", api.handleTopologyGuardrails)
+	mux.HandleFunc("/api/topology/freeze", api.handleTopologyFreeze)
+	mux.HandleFunc("/api/topology/path", api.handleTopologyPath)
+	mux.HandleFunc("/api/topology/shadow", api.handleTopologyShadow)
+	mux.HandleFunc("/api/topology/algorithm", api.handleTopologyAlgorithm)
+	mux.HandleFunc("/api/topology/health", api.handleTopologyHealth)
+	mux.HandleFunc("/api/topology/priors", api.handleTopologyPriors)
+
+	// Goal endpoints
+	mux.HandleFunc("/api/goals", api.handleGoals)
+	mux.HandleFunc("/api/goals/", api.handleGoal)
+
+	// Digests and webhook subscriptions
+	mux.HandleFunc("/api/digests/", api.handleDigest)
+	mux.HandleFunc("/api/webhooks", api.handleWebhooks)
+	mux.HandleFunc("/api/webhooks/", api.handleWebhook)
+
+	// Saved queries called back on new matching insights
+	mux.HandleFunc("/api/saved-queries", api.handleSavedQueries)
+	mux.HandleFunc("/api/saved-queries/", api.handleSavedQuery)
+
+	// Insight sharing agreements between teams
+	mux.HandleFunc("/api/sharing/agreements", api.handleSharingAgreements)
+	mux.HandleFunc("/api/sharing/agreements/", api.handleSharingAgreement)
+	mux.HandleFunc("/api/sharing/audit", api.handleSharingAudit)
+
+	// Proposal templates
+	mux.HandleFunc("/api/proposal-templates", api.handleProposalTemplates)
+	mux.HandleFunc("/api/proposal-templates/", api.handleProposalTemplate)
+
+	// Proposals and their votes
+	mux.HandleFunc("/api/proposals", api.handleProposals)
+	mux.HandleFunc("/api/proposals/", api.handleProposal)
+	mux.HandleFunc("/api/proposals/dependencies", api.handleProposalDependencies)
+
+	// Standing policies in force
+	mux.HandleFunc("/api/policies", api.handlePolicies)
+	mux.HandleFunc("/api/policies/", api.handlePolicy)
+
+	// Proposal bundles voted on as a slate
+	mux.HandleFunc("/api/bundles", api.handleBundles)
+	mux.HandleFunc("/api/bundles/", api.handleBundle)
+
+	// Decision impact
+	mux.HandleFunc("/api/decisions", api.handleDecisions)
+	mux.HandleFunc("/api/decisions/", api.handleDecision)
+	mux.HandleFunc("/api/stakes", api.handleStakes)
+	mux.HandleFunc("/api/stakes/", api.handleStakeAccount)
+
+	// Routing feedback
+	mux.HandleFunc("/api/routing", api.handleRouting)
+	mux.HandleFunc("/api/routing/candidates", api.handleRoutingCandidates)
+
+	// Protocol compatibility matrix
+	mux.HandleFunc("/api/compatibility", api.handleCompatibility)
+
+	// Manager state rebuilds
+	mux.HandleFunc("/api/backfill", api.handleBackfill)
+
+	// AMQL structured queries
+	mux.HandleFunc("/api/amql", api.handleAMQL)
+
+	// Materialized views over insights
+	mux.HandleFunc("/api/views", api.handleViews)
+	mux.HandleFunc("/api/views/", api.handleView)
+
+	// Insight analytics, noised for regulated teams
+	mux.HandleFunc("/api/analytics/insights", api.handleInsightAnalytics)
+	mux.HandleFunc("/api/analytics/communication-matrix", api.handleCommunicationMatrix)
+
+	// Debugging
+	mux.HandleFunc("/api/debug/replay", api.handleDebugReplay)
 
 	// Query endpoint (natural language)
 	mux.HandleFunc("/api/query", api.handleNaturalLanguageQuery)
@@ -136,6 +251,182 @@ func (api *APIServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleSystemHealth handles GET /api/system/health, the rollup of every service's health
+func (api *APIServer) handleSystemHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	health, err := api.systemHealth(ctx)
+	if err != nil {
+		api.logger.Error("Failed to list service health", zap.Error(err))
+		http.Error(w, "Failed to list service health", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if health.Status == types.HealthDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
+
+// systemHealth probes Redis and Kafka and rolls them up with the service reports
+func (api *APIServer) systemHealth(ctx context.Context) (types.SystemHealth, error) {
+	probes := types.HealthProbes{
+		RedisError: api.stateStore.Ping(ctx),
+		KafkaError: api.messaging.Ping(ctx),
+	}
+	services := []types.ServiceHealth{}
+	var incident *types.Incident
+	if probes.RedisError == nil {
+		reports, err := api.stateStore.ListServiceHealth(ctx)
+		if err != nil {
+			return types.SystemHealth{}, err
+		}
+		services = reports
+		if snapshot, err := api.stateStore.LoadGraphSnapshot(ctx); err == nil {
+			probes.LastSnapshotAt = &snapshot.Timestamp
+		}
+		if incident, err = api.stateStore.LoadIncident(ctx); err != nil {
+			api.logger.Warn("Failed to read incident", zap.Error(err))
+		}
+	}
+
+	health := types.RollupHealth(api.config, probes, services, time.Now())
+	health.Incident = incident
+	return health, nil
+}
+
+// handleIncident handles GET (the active incident), POST (declare one) and
+// DELETE (clear it) on /api/incident
+func (api *APIServer) handleIncident(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		incident, err := api.stateStore.LoadIncident(r.Context())
+		if err != nil {
+			api.logger.Error("Failed to load incident", zap.Error(err))
+			http.Error(w, "Failed to load incident", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"active":   incident != nil,
+			"incident": incident,
+		})
+
+	case http.MethodPost:
+		incident := &types.Incident{}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(incident); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+		if incident.Reason == "" {
+			incident.Reason = "declared via API"
+		}
+		incident.Automatic = false // Only cleared through the API
+		incident.TriggeredBy = ""
+		incident.DeclaredAt = time.Now()
+		if err := api.stateStore.SetIncident(r.Context(), incident); err != nil {
+			api.logger.Error("Failed to declare incident", zap.Error(err))
+			http.Error(w, "Failed to declare incident", http.StatusInternalServerError)
+			return
+		}
+		api.logger.Warn("Incident declared", zap.String("reason", incident.Reason), zap.String("declared_by", incident.DeclaredBy))
+
+		// The managers apply incident mode on their next cycle
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{
+			"active":   true,
+			"incident": incident,
+		})
+
+	case http.MethodDelete:
+		if err := api.stateStore.ClearIncident(r.Context()); err != nil {
+			api.logger.Error("Failed to clear incident", zap.Error(err))
+			http.Error(w, "Failed to clear incident", http.StatusInternalServerError)
+			return
+		}
+		api.logger.Info("Incident cleared via API")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{"active": false})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// watchIncidents rolls up the system health every incidentCheckInterval and,
+// with INCIDENT_AUTO_STATUS set, declares an incident when it drops to that
+// status and clears it once health recovers
+func (api *APIServer) watchIncidents(ctx context.Context) {
+	if api.config.IncidentAutoStatus == "" {
+		return
+	}
+	ticker := time.NewTicker(incidentCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			api.checkIncident(checkCtx, now)
+			cancel()
+		}
+	}
+}
+
+// checkIncident declares or clears an automatic incident from the current health
+func (api *APIServer) checkIncident(ctx context.Context, now time.Time) {
+	health, err := api.systemHealth(ctx)
+	if err != nil {
+		api.logger.Warn("Failed to check health for incidents", zap.Error(err))
+		return
+	}
+	declare, clear := types.AutoIncident(health.Incident, health, api.config.IncidentAutoStatus, now)
+	switch {
+	case declare != nil:
+		if err := api.stateStore.SetIncident(ctx, declare); err != nil {
+			api.logger.Warn("Failed to declare incident", zap.Error(err))
+			return
+		}
+		api.logger.Warn("Incident declared on health drop",
+			zap.String("status", string(health.Status)),
+			zap.String("checks", declare.TriggeredBy))
+	case clear:
+		if err := api.stateStore.ClearIncident(ctx); err != nil {
+			api.logger.Warn("Failed to clear incident", zap.Error(err))
+			return
+		}
+		api.logger.Info("Incident cleared, health recovered", zap.String("status", string(health.Status)))
+	}
+}
+
+// handleEffectiveConfig handles GET /api/config/effective, the resolved
+// configuration with the source of every setting
+func (api *APIServer) handleEffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if api.effective == nil {
+		http.Error(w, "Configuration sources not available", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.effective)
+}
+
 // handleQueryInsights handles GET /api/insights with filters
 func (api *APIServer) handleQueryInsights(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -168,6 +459,9 @@ func (api *APIServer) handleQueryInsights(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	query.Actionable = r.URL.Query().Get("actionable") == "true"
+	query.AgentID = types.AgentID(r.URL.Query().Get("agent_id"))
+
 	// Query insights from Redis
 	insights, err := api.queryInsightsFromRedis(r.Context(), query)
 	if err != nil {
@@ -183,6 +477,20 @@ func (api *APIServer) handleQueryInsights(w http.ResponseWriter, r *http.Request
 		Timestamp: time.Now(),
 	}
 
+	// Low-confidence insights tiering did not store individually are only counted
+	summaries, err := api.stateStore.LoadInsightSummaries(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to load insight summaries", zap.Error(err))
+	}
+	for topic, summary := range summaries {
+		if len(query.Topics) == 0 || slices.Contains(query.Topics, topic) {
+			result.LowConfidence = append(result.LowConfidence, *summary)
+		}
+	}
+	sort.Slice(result.LowConfidence, func(i, j int) bool {
+		return result.LowConfidence[i].Topic < result.LowConfidence[j].Topic
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -219,6 +527,96 @@ func (api *APIServer) handleSearchInsights(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(result)
 }
 
+// handleInsightBatch handles POST /api/insights/batch, publishing a batch of
+// insights (e.g. backfilled from another system) to the insights topic
+func (api *APIServer) handleInsightBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var batch types.InsightBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(batch.Insights) == 0 {
+		http.Error(w, "No insights given", http.StatusBadRequest)
+		return
+	}
+	if len(batch.Insights) > api.config.InsightBatchLimit {
+		http.Error(w, fmt.Sprintf("At most %d insights per batch", api.config.InsightBatchLimit), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	insights, report := types.PrepareInsightBatch(batch.Insights, time.Now())
+
+	ids := make([]types.InsightID, len(insights))
+	for i, insight := range insights {
+		ids[i] = insight.ID
+	}
+	existing, err := api.stateStore.ExistingInsights(r.Context(), ids)
+	if err != nil {
+		api.logger.Error("Failed to check existing insights", zap.Error(err))
+		http.Error(w, "Failed to check existing insights", http.StatusInternalServerError)
+		return
+	}
+
+	// Indexes of the insights to publish, to report outcomes by batch position
+	index := make(map[*types.Insight]int, len(batch.Insights))
+	for i := range batch.Insights {
+		index[&batch.Insights[i]] = i
+	}
+	for _, insight := range insights {
+		result := types.IngestionResult{Index: index[insight], ID: insight.ID, Status: types.IngestAccepted}
+		if existing[insight.ID] {
+			result.Status, result.Error = types.IngestDuplicate, "already stored"
+		} else if err := api.messaging.PublishInsight(r.Context(), insight); err != nil {
+			api.logger.Warn("Failed to publish ingested insight", zap.String("insight_id", string(insight.ID)), zap.Error(err))
+			result.Status, result.Error = types.IngestFailed, "failed to publish"
+		}
+		report.Record(result)
+	}
+	sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Index < report.Results[j].Index })
+
+	api.logger.Info("Ingested insight batch",
+		zap.Int("received", report.Received),
+		zap.Int("accepted", report.Accepted),
+		zap.Int("duplicates", report.Duplicates),
+		zap.Int("rejected", report.Rejected),
+		zap.Int("failed", report.Failed),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleEmbeddingStatus handles GET /api/insights/embeddings, reporting the
+// model insights are embedded with and the progress of re-embedding them
+func (api *APIServer) handleEmbeddingStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if api.config.EmbeddingURL == "" {
+		http.Error(w, "Embeddings are disabled (EMBEDDING_URL is unset)", http.StatusNotFound)
+		return
+	}
+
+	status, err := api.stateStore.LoadEmbeddingStatus(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to load embedding status", zap.Error(err))
+		http.Error(w, "Failed to load embedding status", http.StatusInternalServerError)
+		return
+	}
+	if status == nil {
+		status = &types.EmbeddingStatus{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
 // handleNaturalLanguageQuery handles POST /api/query (natural language)
 func (api *APIServer) handleNaturalLanguageQuery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -266,27 +664,18 @@ func (api *APIServer) handleNaturalLanguageQuery(w http.ResponseWriter, r *http.
 	json.NewEncoder(w).Encode(result)
 }
 
-// handleListAgents returns all active agents
+// handleListAgents returns all active agents, of one namespace with namespace=
 func (api *APIServer) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Query agents from Redis (simplified)
-	agents := []map[string]any{
-		{
-			"id":     "agent-sales-1",
-			"name":   "Sales",
-			"role":   "sales",
-			"status": "active",
-		},
-		{
-			"id":     "agent-support-1",
-			"name":   "Support",
-			"role":   "support",
-			"status": "active",
-		},
+	// Agents of the latest topology snapshot, shared by every API replica
+	agents, err := api.stateStore.SnapshotAgents(r.Context(), r.URL.Query().Get("namespace"))
+	if err != nil {
+		api.logger.Debug("No topology snapshot for agent list", zap.Error(err))
+		agents = []*types.Agent{}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -296,10 +685,20 @@ func (api *APIServer) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleGetAgent returns details for a specific agent
+// handleGetAgent returns details for a specific agent and routes
+// POST /api/agents/{id}/approve and GET /api/agents/{id}/neighborhood
 func (api *APIServer) handleGetAgent(w http.ResponseWriter, r *http.Request) {
 	// Extract agent ID from path
-	agentID := r.URL.Path[len("/api/agents/"):]
+	agentID, action, _ := strings.Cut(r.URL.Path[len("/api/agents/"):], "/")
+
+	switch action {
+	case "approve":
+		api.handleApproveAgent(w, r, types.AgentID(agentID))
+		return
+	case "neighborhood":
+		api.handleAgentNeighborhood(w, r, types.AgentID(agentID))
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
@@ -309,118 +708,1931 @@ func (api *APIServer) handleGetAgent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleGetTopology returns the current network topology
+// handleApproveAgent handles POST /api/agents/{id}/approve, which releases an
+// agent from the sandbox before its probation ends
+func (api *APIServer) handleApproveAgent(w http.ResponseWriter, r *http.Request, agentID types.AgentID) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := api.stateStore.ApproveAgent(r.Context(), agentID); err != nil {
+		api.logger.Error("Failed to approve agent", zap.Error(err))
+		http.Error(w, "Failed to approve agent", http.StatusInternalServerError)
+		return
+	}
+
+	// The managers apply the approval asynchronously
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{
+		"agent_id": agentID,
+		"approved": true,
+	})
+}
+
+// handleAgentNeighborhood handles GET /api/agents/{id}/neighborhood?k=2&min_weight=0.2,
+// the agents within k hops (default 1) over edges at or above min_weight
+// (default 0) and the edges among them
+func (api *APIServer) handleAgentNeighborhood(w http.ResponseWriter, r *http.Request, agentID types.AgentID) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	k, minWeight := 1, 0.0
+	if value := r.URL.Query().Get("k"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			http.Error(w, "k must be a whole number of hops, 0 or more", http.StatusBadRequest)
+			return
+		}
+		k = parsed
+	}
+	if value := r.URL.Query().Get("min_weight"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			http.Error(w, "min_weight must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		minWeight = parsed
+	}
+
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get topology snapshot", zap.Error(err))
+		http.Error(w, "Failed to get topology", http.StatusInternalServerError)
+		return
+	}
+
+	// The parameters are valid, so the agent is unknown
+	neighborhood, err := topology.SnapshotNeighborhood(snapshot, agentID, k, minWeight)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(neighborhood)
+}
+
+// handleGetTopology returns the current network topology, or with region= or
+// namespace= only the agents of that region or namespace and the edges between them
 func (api *APIServer) handleGetTopology(w http.ResponseWriter, r *http.Request) {
 	// Query topology snapshot from Redis
-	ctx := r.Context()
-	var snapshot types.GraphSnapshot
-
-	err := api.stateStore.Get(ctx, "graph:snapshot:latest", &snapshot)
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
 	if err != nil {
 		api.logger.Warn("Failed to get topology snapshot", zap.Error(err))
 		// Return empty snapshot
-		snapshot = types.GraphSnapshot{
+		snapshot = &types.GraphSnapshot{
 			Agents:    make(map[types.AgentID]*types.Agent),
 			Edges:     make(map[types.EdgeID]*types.Edge),
 			Timestamp: time.Now(),
 		}
 	}
+	snapshot = api.scopeSnapshot(snapshot, r.URL.Query())
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(snapshot)
 }
 
-// handleTopologyStats returns topology statistics
-func (api *APIServer) handleTopologyStats(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	var snapshot types.GraphSnapshot
+// scopeSnapshot narrows a snapshot to one region with region= and to one
+// namespace with namespace=
+func (api *APIServer) scopeSnapshot(snapshot *types.GraphSnapshot, query url.Values) *types.GraphSnapshot {
+	if region := query.Get("region"); region != "" {
+		snapshot = topology.RegionView(api.config, snapshot, region)
+	}
+	if namespace := query.Get("namespace"); namespace != "" {
+		snapshot = topology.NamespaceView(api.config, snapshot, namespace)
+	}
+	return snapshot
+}
 
-	err := api.stateStore.Get(ctx, "graph:snapshot:latest", &snapshot)
+// handleTopologyStats returns topology statistics, of one region or namespace
+// with region= or namespace=
+func (api *APIServer) handleTopologyStats(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
 	if err != nil {
 		api.logger.Warn("Failed to get topology stats", zap.Error(err))
 		http.Error(w, "Failed to get stats", http.StatusInternalServerError)
 		return
 	}
+	snapshot = api.scopeSnapshot(snapshot, r.URL.Query())
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(snapshot.Stats)
 }
 
-// queryInsightsFromRedis queries insights from Redis with filters
-func (api *APIServer) queryInsightsFromRedis(ctx context.Context, query types.KnowledgeQuery) ([]types.Insight, error) {
-	// Simplified implementation - in production, use Redis indexes or search
-	// For now, return sample insights
-
-	insights := []types.Insight{
-		{
-			ID:         "insight-1",
-			AgentID:    "agent-sales-1",
-			AgentRole:  "sales",
-			Type:       types.InsightTypePricingIssue,
-			Topic:      "pricing",
-			Content:    "Customer complained that price is too high for basic features",
-			Confidence: 0.85,
-			CreatedAt:  time.Now().Add(-1 * time.Hour),
-			Privacy:    types.InsightPrivacyPublic,
-		},
-		{
-			ID:         "insight-2",
-			AgentID:    "agent-support-1",
-			AgentRole:  "support",
-			Type:       types.InsightTypeProductIssue,
-			Topic:      "product_quality",
-			Content:    "Multiple customers reporting slow mobile app performance",
-			Confidence: 0.92,
-			CreatedAt:  time.Now().Add(-30 * time.Minute),
-			Privacy:    types.InsightPrivacyPublic,
-		},
-	}
-
-	// Apply filters
-	var filtered []types.Insight
-	for _, insight := range insights {
-		// Filter by confidence
-		if insight.Confidence < query.MinConfidence {
-			continue
-		}
+// handleTopologyHistory handles GET /api/topology/history?from=&to=: the
+// timestamped snapshots taken between two RFC 3339 times (default: the last
+// 24h, all that is kept), oldest first, with their stats; of one region or
+// namespace with region= or namespace=
+func (api *APIServer) handleTopologyHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		// Filter by topics
-		if len(query.Topics) > 0 {
-			found := false
-			for _, topic := range query.Topics {
-				if insight.Topic == topic {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	for name, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s must be an RFC 3339 time", name), http.StatusBadRequest)
+				return
 			}
+			*target = parsed
 		}
+	}
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
 
-		// Filter by agent types
-		if len(query.AgentTypes) > 0 {
-			found := false
-			for _, agentType := range query.AgentTypes {
-				if insight.AgentRole == agentType {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
+	snapshots, err := api.stateStore.ListSnapshots(r.Context(), from, to)
+	if err != nil {
+		api.logger.Error("Failed to list snapshots", zap.Error(err))
+		http.Error(w, "Failed to list topology history", http.StatusInternalServerError)
+		return
+	}
+
+	points := make([]types.TopologyHistoryPoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, api.scopeSnapshot(snapshot, r.URL.Query()).HistoryPoint())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"from":      from,
+		"to":        to,
+		"snapshots": points,
+		"count":     len(points),
+	})
+}
+
+// handleTopologyAt handles GET /api/topology/at?ts=: the topology as of an
+// RFC 3339 time, from the last timestamped snapshot taken at or before it; of
+// one region or namespace with region= or namespace=
+func (api *APIServer) handleTopologyAt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, r.URL.Query().Get("ts"))
+	if err != nil {
+		http.Error(w, "ts must be an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := api.stateStore.SnapshotAt(r.Context(), at)
+	if errors.Is(err, state.ErrNoSnapshot) {
+		http.Error(w, "No topology snapshot at or before ts", http.StatusNotFound)
+		return
+	} else if err != nil {
+		api.logger.Error("Failed to load topology snapshot", zap.Time("ts", at), zap.Error(err))
+		http.Error(w, "Failed to load topology snapshot", http.StatusInternalServerError)
+		return
+	}
+	snapshot = api.scopeSnapshot(snapshot, r.URL.Query())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// handleTopologyDiff handles GET /api/topology/diff?from=&to=: how the
+// topology evolved between the snapshots as of two RFC 3339 times (to defaults
+// to the current topology), with the top= largest weight changes; of one
+// region or namespace with region= or namespace=
+func (api *APIServer) handleTopologyDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		http.Error(w, "from must be an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+	var to *time.Time
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "to must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		if parsed.Before(from) {
+			http.Error(w, "to must not be before from", http.StatusBadRequest)
+			return
 		}
+		to = &parsed
+	}
+	minChange := 0.0
+	if value := query.Get("min_change"); value != "" {
+		if minChange, err = strconv.ParseFloat(value, 64); err != nil || minChange < 0 || minChange > 1 {
+			http.Error(w, "min_change must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+	top := 0
+	if value := query.Get("top"); value != "" {
+		if top, err = strconv.Atoi(value); err != nil || top < 0 {
+			http.Error(w, "top must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
 
-		filtered = append(filtered, insight)
+	before, err := api.stateStore.SnapshotAt(r.Context(), from)
+	if errors.Is(err, state.ErrNoSnapshot) {
+		http.Error(w, "No topology snapshot at or before from", http.StatusNotFound)
+		return
+	} else if err != nil {
+		api.logger.Error("Failed to load topology snapshot", zap.Time("ts", from), zap.Error(err))
+		http.Error(w, "Failed to load topology snapshot", http.StatusInternalServerError)
+		return
+	}
 
-		// Apply limit
-		if query.Limit > 0 && len(filtered) >= query.Limit {
-			break
+	var after *types.GraphSnapshot
+	if to == nil {
+		if after, err = api.stateStore.LoadGraphSnapshot(r.Context()); err != nil {
+			api.logger.Warn("Failed to get topology snapshot", zap.Error(err))
+			http.Error(w, "No topology snapshot available", http.StatusNotFound)
+			return
 		}
+	} else if after, err = api.stateStore.SnapshotAt(r.Context(), *to); errors.Is(err, state.ErrNoSnapshot) {
+		http.Error(w, "No topology snapshot at or before to", http.StatusNotFound)
+		return
+	} else if err != nil {
+		api.logger.Error("Failed to load topology snapshot", zap.Time("ts", *to), zap.Error(err))
+		http.Error(w, "Failed to load topology snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	before, after = api.scopeSnapshot(before, query), api.scopeSnapshot(after, query)
+
+	diff := topology.Diff(before, after, minChange)
+	diff.Top(top)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// handleTopologyExport handles GET /api/topology/export?format=graphml|dot|gexf,
+// of one namespace with namespace=
+func (api *APIServer) handleTopologyExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := topology.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = topology.ExportFormatGraphML
+	}
+	if format != topology.ExportFormatGraphML && format != topology.ExportFormatDOT && format != topology.ExportFormatGEXF {
+		http.Error(w, "Unsupported format (use graphml, dot or gexf)", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get topology snapshot for export", zap.Error(err))
+		http.Error(w, "No topology snapshot available", http.StatusNotFound)
+		return
+	}
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		snapshot = topology.NamespaceView(api.config, snapshot, namespace)
 	}
 
-	return filtered, nil
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"agentmesh-%d.%s\"", snapshot.Timestamp.Unix(), format))
+	if err := topology.Export(w, snapshot, format); err != nil {
+		api.logger.Error("Failed to export topology", zap.Error(err))
+	}
+}
+
+// handleTopologyGuardrails handles GET /api/topology/guardrails
+func (api *APIServer) handleTopologyGuardrails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := api.stateStore.LoadGuardrailStatus(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get guardrail status", zap.Error(err))
+		http.Error(w, "No guardrail status reported (is the topology manager running?)", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleTopologyHealth handles GET /api/topology/health, the pathological
+// states the topology is in and what to do about them
+func (api *APIServer) handleTopologyHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	health, err := api.stateStore.LoadTopologyHealth(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get topology health", zap.Error(err))
+		http.Error(w, "No topology health reported (is the topology manager running?)", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(health)
+}
+
+// handleTopologyPriors handles GET /api/topology/priors, the edge weights
+// learned before the topology manager last shut down
+func (api *APIServer) handleTopologyPriors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	priors, err := api.stateStore.LoadTopologyPriors(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to load topology priors", zap.Error(err))
+		http.Error(w, "Failed to load topology priors", http.StatusInternalServerError)
+		return
+	}
+	if priors == nil {
+		http.Error(w, "No learned priors saved (the topology manager saves them when it shuts down)", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priors)
+}
+
+// handleTopologyShadow handles GET /api/topology/shadow, the live topology's
+// stats side by side with the shadow topology's
+func (api *APIServer) handleTopologyShadow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	comparison, err := api.stateStore.LoadTopologyComparison(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get topology comparison", zap.Error(err))
+		http.Error(w, "Failed to get topology comparison", http.StatusInternalServerError)
+		return
+	}
+	if comparison == nil {
+		http.Error(w, "No shadow topology running (set SHADOW_TOPOLOGY on the topology manager)", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// handleTopologyAlgorithm handles GET /api/topology/algorithm, what the slime
+// mold algorithm did in each recent decay cycle
+func (api *APIServer) handleTopologyAlgorithm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l < 0 {
+			http.Error(w, "limit must be a whole number of cycles, 0 or more", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	cycles, err := api.stateStore.LoadAlgorithmCycles(r.Context(), limit)
+	if err != nil {
+		api.logger.Error("Failed to get algorithm cycles", zap.Error(err))
+		http.Error(w, "Failed to get algorithm cycles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"cycles": cycles,
+		"count":  len(cycles),
+	})
+}
+
+// handleTopologyPath handles GET /api/topology/path?from=...&to=..., the
+// strongest path between two agents, relaying through intermediaries
+func (api *APIServer) handleTopologyPath(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		http.Error(w, "from and to are required", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to get topology snapshot", zap.Error(err))
+		http.Error(w, "Failed to get topology", http.StatusInternalServerError)
+		return
+	}
+
+	// Unknown agents, or no path with PATH_FALLBACK=none
+	path, err := topology.SnapshotPath(api.config, snapshot, types.AgentID(from), types.AgentID(to))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(path)
+}
+
+// handleTopologyFreeze handles POST (freeze) and DELETE (unfreeze) on /api/topology/freeze.
+// The topology manager applies the request on its next snapshot cycle.
+func (api *APIServer) handleTopologyFreeze(w http.ResponseWriter, r *http.Request) {
+	freeze := &types.TopologyFreeze{}
+
+	switch r.Method {
+	case http.MethodPost:
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(freeze); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+		freeze.Frozen = true
+		if freeze.Reason == "" {
+			freeze.Reason = "frozen via API"
+		}
+	case http.MethodDelete:
+		freeze.Frozen = false
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	freeze.RequestedAt = time.Now()
+
+	if err := api.stateStore.SetTopologyFreeze(r.Context(), freeze); err != nil {
+		api.logger.Error("Failed to save topology freeze", zap.Error(err))
+		http.Error(w, "Failed to save request", http.StatusInternalServerError)
+		return
+	}
+
+	api.logger.Info("Topology freeze requested",
+		zap.Bool("frozen", freeze.Frozen),
+		zap.String("reason", freeze.Reason),
+		zap.String("requested_by", freeze.RequestedBy),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(freeze)
+}
+
+// handleGoals handles GET (list with status) and POST (create) on /api/goals
+func (api *APIServer) handleGoals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		goals, err := api.stateStore.ListGoals(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list goals", zap.Error(err))
+			http.Error(w, "Failed to list goals", http.StatusInternalServerError)
+			return
+		}
+
+		statuses := make([]types.GoalStatus, 0, len(goals))
+		for _, goal := range goals {
+			status, err := api.evaluateGoal(ctx, goal)
+			if err != nil {
+				api.logger.Warn("Failed to evaluate goal", zap.String("goal_id", string(goal.ID)), zap.Error(err))
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Goal.Name < statuses[j].Goal.Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"goals": statuses,
+			"count": len(statuses),
+		})
+
+	case http.MethodPost:
+		var goal types.Goal
+		if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := goal.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if goal.ID == "" {
+			goal.ID = types.NewGoalID(goal.Name)
+		}
+		goal.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveGoal(ctx, &goal); err != nil {
+			api.logger.Error("Failed to save goal", zap.Error(err))
+			http.Error(w, "Failed to save goal", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("Goal defined",
+			zap.String("goal_id", string(goal.ID)),
+			zap.String("metric", goal.Metric),
+			zap.String("target", fmt.Sprintf("%s %g", goal.Comparator, goal.Target)),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(goal)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGoal handles GET (status) and DELETE on /api/goals/{id}
+func (api *APIServer) handleGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	goalID := types.GoalID(r.URL.Path[len("/api/goals/"):])
+
+	goal, err := api.stateStore.LoadGoal(ctx, goalID)
+	if err != nil {
+		http.Error(w, "Goal not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status, err := api.evaluateGoal(ctx, goal)
+		if err != nil {
+			api.logger.Error("Failed to evaluate goal", zap.Error(err))
+			http.Error(w, "Failed to evaluate goal", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+
+	case http.MethodDelete:
+		if err := api.stateStore.DeleteGoal(ctx, goalID); err != nil {
+			api.logger.Error("Failed to delete goal", zap.Error(err))
+			http.Error(w, "Failed to delete goal", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// evaluateGoal loads a goal's progress within its window and evaluates it
+func (api *APIServer) evaluateGoal(ctx context.Context, goal *types.Goal) (types.GoalStatus, error) {
+	now := time.Now()
+	progress, err := api.stateStore.ListGoalProgress(ctx, goal.ID, now.Add(-goal.WindowDuration()))
+	if err != nil {
+		return types.GoalStatus{}, err
+	}
+	return goal.Evaluate(progress, now), nil
+}
+
+// handleDigest handles GET /api/digests/{id|latest}; ?format=markdown renders the report
+func (api *APIServer) handleDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/api/digests/"):]
+	d, err := api.stateStore.LoadDigest(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Digest not found", http.StatusNotFound)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprint(w, digest.Markdown(d))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// handleWebhooks handles GET (list) and POST (subscribe) on /api/webhooks
+func (api *APIServer) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := api.stateStore.ListWebhooks(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list webhooks", zap.Error(err))
+			http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+			return
+		}
+		for _, hook := range hooks {
+			hook.Secret = "" // Never echo secrets
+		}
+		sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.Before(hooks[j].CreatedAt) })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"webhooks": hooks,
+			"count":    len(hooks),
+		})
+
+	case http.MethodPost:
+		var hook types.Webhook
+		if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, "url must be an absolute http(s) URL", http.StatusBadRequest)
+			return
+		}
+		hook.ID = fmt.Sprintf("webhook-%d", time.Now().UnixNano())
+		hook.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveWebhook(ctx, &hook); err != nil {
+			api.logger.Error("Failed to save webhook", zap.Error(err))
+			http.Error(w, "Failed to save webhook", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("Webhook registered",
+			zap.String("webhook_id", hook.ID),
+			zap.Strings("events", hook.Events),
+		)
+
+		hook.Secret = ""
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(hook)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWebhook handles DELETE /api/webhooks/{id}
+func (api *APIServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/api/webhooks/"):]
+	if err := api.stateStore.DeleteWebhook(r.Context(), id); err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSavedQueries handles GET (list) and POST (register) on /api/saved-queries
+func (api *APIServer) handleSavedQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		queries, err := api.stateStore.ListSavedQueries(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list saved queries", zap.Error(err))
+			http.Error(w, "Failed to list saved queries", http.StatusInternalServerError)
+			return
+		}
+		for _, query := range queries {
+			query.Secret = "" // Never echo secrets
+		}
+		sort.Slice(queries, func(i, j int) bool { return queries[i].CreatedAt.Before(queries[j].CreatedAt) })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"saved_queries": queries,
+			"count":         len(queries),
+		})
+
+	case http.MethodPost:
+		var query types.SavedQuery
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := query.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.ID = fmt.Sprintf("query-%d", time.Now().UnixNano())
+		query.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveSavedQuery(ctx, &query); err != nil {
+			api.logger.Error("Failed to save query", zap.Error(err))
+			http.Error(w, "Failed to save query", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("Saved query registered",
+			zap.String("query_id", query.ID),
+			zap.String("name", query.Name),
+		)
+
+		query.Secret = ""
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(query)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSavedQuery handles DELETE /api/saved-queries/{id}
+func (api *APIServer) handleSavedQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/api/saved-queries/"):]
+	if err := api.stateStore.DeleteSavedQuery(r.Context(), id); err != nil {
+		http.Error(w, "Saved query not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSharingAgreements handles GET (list) and POST (create) on /api/sharing/agreements
+func (api *APIServer) handleSharingAgreements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		agreements, err := api.stateStore.ListSharingAgreements(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list sharing agreements", zap.Error(err))
+			http.Error(w, "Failed to list sharing agreements", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(agreements, func(i, j int) bool { return agreements[i].CreatedAt.Before(agreements[j].CreatedAt) })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"agreements": agreements,
+			"count":      len(agreements),
+		})
+
+	case http.MethodPost:
+		var agreement types.SharingAgreement
+		if err := json.NewDecoder(r.Body).Decode(&agreement); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := agreement.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		agreement.ID = types.NewSharingAgreementID()
+		agreement.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveSharingAgreement(ctx, &agreement, agreement.CreatedBy); err != nil {
+			api.logger.Error("Failed to save sharing agreement", zap.Error(err))
+			http.Error(w, "Failed to save sharing agreement", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("Sharing agreement created",
+			zap.String("agreement_id", agreement.ID),
+			zap.String("provider", agreement.Provider),
+			zap.String("consumer", agreement.Consumer),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(agreement)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSharingAgreement handles DELETE /api/sharing/agreements/{id}?actor=
+func (api *APIServer) handleSharingAgreement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Path[len("/api/sharing/agreements/"):]
+	if err := api.stateStore.RevokeSharingAgreement(r.Context(), id, r.URL.Query().Get("actor")); err != nil {
+		http.Error(w, "Sharing agreement not found", http.StatusNotFound)
+		return
+	}
+	api.logger.Info("Sharing agreement revoked", zap.String("agreement_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSharingAudit returns the audit trail of sharing agreement changes,
+// since the RFC 3339 time given by since (default: all)
+func (api *APIServer) handleSharingAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	entries, err := api.stateStore.ListSharingAudit(r.Context(), since)
+	if err != nil {
+		api.logger.Error("Failed to list sharing audit", zap.Error(err))
+		http.Error(w, "Failed to list sharing audit", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// handleProposalTemplates handles GET (list, including built-ins) and POST (register) on /api/proposal-templates
+func (api *APIServer) handleProposalTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := consensus.ListTemplates(ctx, api.stateStore)
+		if err != nil {
+			api.logger.Error("Failed to list proposal templates", zap.Error(err))
+			http.Error(w, "Failed to list proposal templates", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"templates": templates,
+			"count":     len(templates),
+		})
+
+	case http.MethodPost:
+		var template types.ProposalTemplate
+		if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := template.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		template.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveProposalTemplate(ctx, &template); err != nil {
+			api.logger.Error("Failed to save proposal template", zap.Error(err))
+			http.Error(w, "Failed to save proposal template", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("Proposal template registered",
+			zap.String("template", template.Name),
+			zap.String("type", string(template.Type)),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(template)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProposalTemplate handles GET and DELETE on /api/proposal-templates/{name}
+// and POST /api/proposal-templates/{name}/proposals, which proposes from the template
+func (api *APIServer) handleProposalTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, action, _ := strings.Cut(r.URL.Path[len("/api/proposal-templates/"):], "/")
+
+	switch {
+	case action == "proposals" && r.Method == http.MethodPost:
+		template, err := consensus.LookupTemplate(ctx, api.stateStore, name)
+		if err != nil {
+			http.Error(w, "Proposal template not found", http.StatusNotFound)
+			return
+		}
+
+		var request struct {
+			ProposerID types.AgentID  `json:"proposer_id"`
+			Params     map[string]any `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if request.ProposerID == "" {
+			http.Error(w, "proposer_id is required", http.StatusBadRequest)
+			return
+		}
+
+		// Render here so invalid parameters are reported to the caller, not just logged
+		proposal, err := consensus.Instantiate(template, request.ProposerID, request.Params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := api.messaging.PublishProposal(ctx, &types.Proposal{ProposerID: request.ProposerID}, messaging.ProposalOptions{Template: name, Params: request.Params}); err != nil {
+			api.logger.Error("Failed to publish proposal", zap.Error(err))
+			http.Error(w, "Failed to publish proposal", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{
+			"template":    name,
+			"proposer_id": request.ProposerID,
+			"type":        proposal.Type,
+			"content":     proposal.Content,
+		})
+
+	case action == "" && r.Method == http.MethodGet:
+		template, err := consensus.LookupTemplate(ctx, api.stateStore, name)
+		if err != nil {
+			http.Error(w, "Proposal template not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(template)
+
+	case action == "" && r.Method == http.MethodDelete:
+		if err := api.stateStore.DeleteProposalTemplate(ctx, name); err != nil {
+			http.Error(w, "Proposal template not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case action != "" && action != "proposals":
+		http.NotFound(w, r)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// proposalDetail is a proposal with its votes in order and the factors behind them
+type proposalDetail struct {
+	*types.Proposal
+	Votes   []types.Vote          `json:"votes"`
+	Tally   types.VoteTally       `json:"tally"`
+	Factors []types.FactorSummary `json:"factors"`
+}
+
+// newProposalDetail builds the detail view of a proposal
+func newProposalDetail(proposal *types.Proposal) proposalDetail {
+	votes := proposal.VoteList()
+	return proposalDetail{
+		Proposal: proposal,
+		Votes:    votes,
+		Tally:    proposal.Tally(),
+		Factors:  types.SummarizeFactors(votes),
+	}
+}
+
+// handleProposals handles GET /api/proposals, newest first (?status=<status>)
+func (api *APIServer) handleProposals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status := types.ProposalStatus(r.URL.Query().Get("status"))
+	recurrence := r.URL.Query().Get("recurrence")
+
+	proposals, err := api.stateStore.ListProposals(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to list proposals", zap.Error(err))
+		http.Error(w, "Failed to list proposals", http.StatusInternalServerError)
+		return
+	}
+
+	details := []proposalDetail{}
+	for _, proposal := range proposals {
+		if (status == "" || proposal.Status == status) && (recurrence == "" || proposal.Recurrence == recurrence) {
+			details = append(details, newProposalDetail(proposal))
+		}
+	}
+	sort.Slice(details, func(i, j int) bool { return details[i].CreatedAt.After(details[j].CreatedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"proposals": details,
+		"count":     len(details),
+	})
+}
+
+// handlePolicies handles GET /api/policies: the standing policies in force, by name
+func (api *APIServer) handlePolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	policies, err := api.stateStore.ListPolicies(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to list policies", zap.Error(err))
+		http.Error(w, "Failed to list policies", http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"policies": policies,
+		"count":    len(policies),
+	})
+}
+
+// handlePolicy handles GET /api/policies/{name}: the standing policy in force under a name
+func (api *APIServer) handlePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.URL.Path[len("/api/policies/"):]
+
+	policy, err := api.stateStore.LoadPolicy(r.Context(), name)
+	if err != nil {
+		api.logger.Error("Failed to load policy", zap.String("policy", name), zap.Error(err))
+		http.Error(w, "Failed to load policy", http.StatusInternalServerError)
+		return
+	}
+	if policy == nil {
+		http.Error(w, "Policy not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// handleProposal handles GET /api/proposals/{id}: the proposal with every vote's rationale
+func (api *APIServer) handleProposal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/dependencies") {
+		api.handleProposalDependencies(w, r)
+		return
+	}
+	proposalID := types.ProposalID(r.URL.Path[len("/api/proposals/"):])
+
+	proposal, err := api.stateStore.LoadProposal(r.Context(), proposalID)
+	if err != nil {
+		http.Error(w, "Proposal not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newProposalDetail(proposal))
+}
+
+// handleProposalDependencies handles GET /api/proposals/dependencies, the graph
+// of every proposal with prerequisites or dependents, and GET
+// /api/proposals/{id}/dependencies, the part of it connected to one proposal
+func (api *APIServer) handleProposalDependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	root := types.ProposalID(strings.TrimSuffix(r.URL.Path[len("/api/proposals/"):], "dependencies"))
+	root = types.ProposalID(strings.TrimSuffix(string(root), "/"))
+	if root != "" {
+		if _, err := api.stateStore.LoadProposal(r.Context(), root); err != nil {
+			http.Error(w, "Proposal not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	proposals, err := api.stateStore.ListProposals(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to list proposals", zap.Error(err))
+		http.Error(w, "Failed to list proposals", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.NewDependencyGraph(proposals, root))
+}
+
+// handleBundles handles GET (list, newest first) and POST (propose) on /api/bundles
+func (api *APIServer) handleBundles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		bundles, err := api.stateStore.ListBundles(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list bundles", zap.Error(err))
+			http.Error(w, "Failed to list bundles", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(bundles, func(i, j int) bool { return bundles[i].CreatedAt.After(bundles[j].CreatedAt) })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"bundles": bundles,
+			"count":   len(bundles),
+		})
+
+	case http.MethodPost:
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		request, err := consensus.ParseBundleRequest(map[string]any{"bundle": body})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Render templated members here so invalid parameters are reported to the caller
+		items := body["proposals"].([]any) // Checked by ParseBundleRequest
+		proposals := make([]map[string]any, len(request.Proposals))
+		for i, member := range request.Proposals {
+			proposals[i] = items[i].(map[string]any)
+			if _, ok := proposals[i]["electorate"]; !ok && member.Electorate != nil {
+				proposals[i]["electorate"] = member.Electorate // Inherited from the bundle
+			}
+			if member.Template == "" {
+				continue
+			}
+			template, err := consensus.LookupTemplate(ctx, api.stateStore, member.Template)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("bundle proposal %d: proposal template not found", i), http.StatusBadRequest)
+				return
+			}
+			if _, err := consensus.Instantiate(template, member.ProposerID, member.Params); err != nil {
+				http.Error(w, fmt.Sprintf("bundle proposal %d: %v", i, err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := api.messaging.PublishBundle(ctx, request.ProposerID, request.Title, request.Rule, request.BestOf, proposals); err != nil {
+			api.logger.Error("Failed to publish bundle", zap.Error(err))
+			http.Error(w, "Failed to publish bundle", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{
+			"proposer_id": request.ProposerID,
+			"title":       request.Title,
+			"rule":        request.Rule,
+			"best_of":     request.BestOf,
+			"proposals":   len(request.Proposals),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleBundle handles GET /api/bundles/{id}: the bundle with the detail of each member proposal
+func (api *APIServer) handleBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	bundleID := types.BundleID(r.URL.Path[len("/api/bundles/"):])
+
+	bundle, err := api.stateStore.LoadBundle(ctx, bundleID)
+	if err != nil {
+		http.Error(w, "Bundle not found", http.StatusNotFound)
+		return
+	}
+
+	members := []proposalDetail{}
+	for _, proposalID := range bundle.Proposals {
+		proposal, err := api.stateStore.LoadProposal(ctx, proposalID)
+		if err != nil {
+			continue // Member records may outlive or predate the bundle's
+		}
+		members = append(members, newProposalDetail(proposal))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"bundle":    bundle,
+		"proposals": members,
+	})
+}
+
+// handleDecisions handles GET /api/decisions: the impact of accepted proposals
+// (?proposer=<agent_id>, ?verdict=<verdict>) and each proposer's track record
+func (api *APIServer) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	proposer := types.AgentID(r.URL.Query().Get("proposer"))
+	verdict := types.DecisionVerdict(r.URL.Query().Get("verdict"))
+
+	proposals, err := api.stateStore.ListProposals(ctx)
+	if err != nil {
+		api.logger.Error("Failed to list proposals", zap.Error(err))
+		http.Error(w, "Failed to list decisions", http.StatusInternalServerError)
+		return
+	}
+
+	impacts := []types.DecisionImpact{}
+	var all []types.DecisionImpact
+	for _, proposal := range proposals {
+		if proposal.Status != types.ProposalStatusAccepted {
+			continue
+		}
+		impact, err := api.evaluateDecision(ctx, proposal)
+		if err != nil {
+			api.logger.Warn("Failed to evaluate decision", zap.String("proposal_id", string(proposal.ID)), zap.Error(err))
+			continue
+		}
+		all = append(all, impact)
+		if (proposer == "" || impact.ProposerID == proposer) && (verdict == "" || impact.Verdict == verdict) {
+			impacts = append(impacts, impact)
+		}
+	}
+	sort.Slice(impacts, func(i, j int) bool { return impacts[i].DecidedAt.After(impacts[j].DecidedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"decisions": impacts,
+		"count":     len(impacts),
+		"proposers": types.SummarizeDecisions(all),
+	})
+}
+
+// handleDecision handles GET /api/decisions/{proposal_id}
+func (api *APIServer) handleDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	proposalID := types.ProposalID(r.URL.Path[len("/api/decisions/"):])
+
+	proposal, err := api.stateStore.LoadProposal(ctx, proposalID)
+	if err != nil {
+		http.Error(w, "Proposal not found", http.StatusNotFound)
+		return
+	}
+	if proposal.Status != types.ProposalStatusAccepted {
+		http.Error(w, fmt.Sprintf("Proposal is %s, not an accepted decision", proposal.Status), http.StatusConflict)
+		return
+	}
+
+	impact, err := api.evaluateDecision(ctx, proposal)
+	if err != nil {
+		api.logger.Error("Failed to evaluate decision", zap.Error(err))
+		http.Error(w, "Failed to evaluate decision", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(impact)
+}
+
+// evaluateDecision loads the outcomes linked to an accepted proposal and evaluates its impact
+func (api *APIServer) evaluateDecision(ctx context.Context, proposal *types.Proposal) (types.DecisionImpact, error) {
+	outcomes, err := api.stateStore.ListDecisionOutcomes(ctx, proposal.ID)
+	if err != nil {
+		return types.DecisionImpact{}, err
+	}
+	impact := types.EvaluateDecision(proposal, outcomes, time.Now())
+	if api.config.VoteStakes {
+		impact.Stakes = types.SettleStakes(proposal, impact.Verdict, api.config.StakeRewardRate, api.config.StakeSlashRate)
+	}
+	return impact, nil
+}
+
+// handleStakes handles GET /api/stakes: the stake accounts of agents that staked on votes
+func (api *APIServer) handleStakes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	accounts, err := api.stateStore.ListStakeAccounts(r.Context(), api.config.StakeInitialBalance)
+	if err != nil {
+		api.logger.Error("Failed to list stake accounts", zap.Error(err))
+		http.Error(w, "Failed to list stake accounts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled":         api.config.VoteStakes,
+		"initial_balance": api.config.StakeInitialBalance,
+		"accounts":        accounts,
+		"count":           len(accounts),
+	})
+}
+
+// handleStakeAccount handles GET /api/stakes/{agent_id}
+func (api *APIServer) handleStakeAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	agentID := types.AgentID(r.URL.Path[len("/api/stakes/"):])
+	if agentID == "" {
+		http.Error(w, "agent_id is required", http.StatusBadRequest)
+		return
+	}
+	account, err := api.stateStore.LoadStakeAccount(r.Context(), agentID, api.config.StakeInitialBalance)
+	if err != nil {
+		api.logger.Error("Failed to load stake account", zap.Error(err))
+		http.Error(w, "Failed to load stake account", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"agent_id":  account.AgentID,
+		"balance":   account.Balance,
+		"locked":    account.Locked,
+		"available": account.Available(),
+	})
+}
+
+// handleDebugReplay handles POST /api/debug/replay, which republishes a message
+// from the message history so a developer can reproduce how an agent handles it.
+// to_agent_id redirects it, e.g. to a local agent under a debugger.
+func (api *APIServer) handleDebugReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	var request struct {
+		MessageID string        `json:"message_id"`
+		ToAgentID types.AgentID `json:"to_agent_id,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.MessageID == "" {
+		http.Error(w, "message_id is required", http.StatusBadRequest)
+		return
+	}
+
+	original, err := api.stateStore.FindMessage(ctx, request.MessageID)
+	if err != nil {
+		api.logger.Error("Failed to search message history", zap.Error(err))
+		http.Error(w, "Failed to search message history", http.StatusInternalServerError)
+		return
+	}
+	if original == nil {
+		http.Error(w, "Message not found in history (kept for 24h)", http.StatusNotFound)
+		return
+	}
+
+	replay := *original
+	replay.ID = fmt.Sprintf("replay-%s-%d", original.ID, time.Now().UnixNano())
+	replay.ReplayOf = original.ID
+	replay.Timestamp = time.Now()
+	if request.ToAgentID != "" {
+		replay.ToAgentID = request.ToAgentID
+		replay.EdgeID = types.NewEdgeID(replay.FromAgentID, replay.ToAgentID)
+	}
+
+	if err := api.messaging.PublishMessage(ctx, "messages", &replay); err != nil {
+		api.logger.Error("Failed to publish replay", zap.Error(err))
+		http.Error(w, "Failed to publish replay", http.StatusInternalServerError)
+		return
+	}
+
+	api.logger.Info("Replayed message",
+		zap.String("message_id", original.ID),
+		zap.String("replay_id", replay.ID),
+		zap.String("to", string(replay.ToAgentID)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(replay)
+}
+
+// handleBackfill handles GET /api/backfill, reporting the progress of the
+// managers that rebuilt their state from Kafka in the earliest start mode
+func (api *APIServer) handleBackfill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backfills := []*types.BackfillProgress{}
+	for _, manager := range []string{"topology", "knowledge"} {
+		progress, err := api.stateStore.LoadBackfillProgress(r.Context(), manager)
+		if err != nil {
+			api.logger.Error("Failed to load backfill progress", zap.Error(err))
+			http.Error(w, "Failed to load backfill progress", http.StatusInternalServerError)
+			return
+		}
+		if progress != nil {
+			backfills = append(backfills, progress)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"backfills": backfills,
+		"count":     len(backfills),
+	})
+}
+
+// handleAMQL handles GET /api/amql?q=... and POST /api/amql {"query": "..."},
+// running an AMQL query over the insights or the current topology
+func (api *APIServer) handleAMQL(w http.ResponseWriter, r *http.Request) {
+	var input string
+	switch r.Method {
+	case http.MethodGet:
+		input = r.URL.Query().Get("q")
+	case http.MethodPost:
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		input = req.Query
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query, err := amql.Parse(input)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var items any
+	switch query.Source {
+	case amql.SourceInsights:
+		// Visibility rules apply to the requesting agent, as for /api/insights
+		insights, err := api.queryInsightsFromRedis(r.Context(), types.KnowledgeQuery{AgentID: types.AgentID(r.URL.Query().Get("agent_id"))})
+		if err != nil {
+			api.logger.Error("Failed to query insights", zap.Error(err))
+			http.Error(w, "Failed to query insights", http.StatusInternalServerError)
+			return
+		}
+		items = insights
+	case amql.SourceAgents, amql.SourceEdges:
+		snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
+		if err != nil {
+			api.logger.Warn("Failed to get topology snapshot for query", zap.Error(err))
+			http.Error(w, "No topology snapshot available", http.StatusNotFound)
+			return
+		}
+		if query.Source == amql.SourceAgents {
+			agents := make([]*types.Agent, 0, len(snapshot.Agents))
+			for _, agent := range snapshot.Agents {
+				agents = append(agents, agent)
+			}
+			items = agents
+		} else {
+			edges := make([]*types.Edge, 0, len(snapshot.Edges))
+			for _, edge := range snapshot.Edges {
+				edges = append(edges, edge)
+			}
+			items = edges
+		}
+	}
+
+	records, err := amql.Records(items)
+	if err != nil {
+		api.logger.Error("Failed to prepare query records", zap.Error(err))
+		http.Error(w, "Failed to run query", http.StatusInternalServerError)
+		return
+	}
+	results := query.Run(records)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"source":  query.Source,
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+// handleViews handles GET (list) and POST (define) on /api/views
+func (api *APIServer) handleViews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		defined, err := api.stateStore.ListInsightViews(ctx)
+		if err != nil {
+			api.logger.Error("Failed to list views", zap.Error(err))
+			http.Error(w, "Failed to list views", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(defined, func(i, j int) bool { return defined[i].Name < defined[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"views": defined,
+			"count": len(defined),
+		})
+
+	case http.MethodPost:
+		var view types.InsightView
+		if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := views.Validate(&view); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		view.CreatedAt = time.Now()
+
+		if err := api.stateStore.SaveInsightView(ctx, &view); err != nil {
+			api.logger.Error("Failed to save view", zap.Error(err))
+			http.Error(w, "Failed to save view", http.StatusInternalServerError)
+			return
+		}
+
+		api.logger.Info("View defined",
+			zap.String("view", view.Name),
+			zap.String("query", view.Query),
+			zap.String("group_by", view.GroupBy),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(view)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleView handles GET (materialized result) and DELETE on /api/views/{name}
+func (api *APIServer) handleView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.URL.Path[len("/api/views/"):]
+
+	switch r.Method {
+	case http.MethodGet:
+		result, err := api.stateStore.LoadViewResult(ctx, name)
+		if err != nil {
+			api.logger.Error("Failed to load view result", zap.Error(err))
+			http.Error(w, "Failed to load view", http.StatusInternalServerError)
+			return
+		}
+		if result == nil {
+			http.Error(w, "View not found or not materialized yet (is the knowledge manager running?)", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+
+	case http.MethodDelete:
+		if err := api.stateStore.DeleteInsightView(ctx, name); err != nil {
+			http.Error(w, "View not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleInsightAnalytics handles GET /api/analytics/insights: topic counts and
+// a trend over span (default 168h) in the span's bucket of analytics.Grid.
+// Insights of the ANALYTICS_PRIVATE_TEAMS are counted with differential
+// privacy noise; ?private=true noises every team's, for dashboards shared
+// outside the mesh.
+func (api *APIServer) handleInsightAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	// Only the grid's spans are served, so callers cannot request a fresh
+	// release over the same insights by nudging the span or bucket
+	span := 7 * 24 * time.Hour
+	if value := r.URL.Query().Get("span"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			http.Error(w, "span must be a duration such as 168h", http.StatusBadRequest)
+			return
+		}
+		span = parsed
+	}
+	bucket, ok := analytics.Grid[span]
+	if !ok {
+		http.Error(w, "span must be one of 24h, 168h or 720h", http.StatusBadRequest)
+		return
+	}
+	if value := r.URL.Query().Get("bucket"); value != "" {
+		if parsed, err := time.ParseDuration(value); err != nil || parsed != bucket {
+			http.Error(w, fmt.Sprintf("bucket of a %s span must be %s", span, bucket), http.StatusBadRequest)
+			return
+		}
+	}
+
+	opts := analytics.Options{
+		Bucket:       bucket,
+		PrivateTeams: api.config.AnalyticsPrivateTeams,
+		Epsilon:      api.config.AnalyticsEpsilon,
+		Delta:        api.config.AnalyticsDelta,
+	}
+	if r.URL.Query().Get("private") == "true" {
+		opts.PrivateTeams = []string{"*"}
+	}
+	now := time.Now()
+	opts.Start, opts.End = analytics.Window(now, span, bucket)
+
+	// Noised releases are cached per window, sparing the recount
+	key := fmt.Sprintf("%s:%s:%d:%s", span, bucket, opts.End.Unix(), strings.Join(opts.PrivateTeams, ","))
+	if len(opts.PrivateTeams) > 0 {
+		if cached, err := api.stateStore.LoadAnalytics(ctx, key); err != nil {
+			api.logger.Error("Failed to load cached analytics", zap.Error(err))
+			http.Error(w, "Failed to compute analytics", http.StatusInternalServerError)
+			return
+		} else if cached != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(cached)
+			return
+		}
+	}
+
+	insights, err := api.stateStore.ListInsights(ctx)
+	if err != nil {
+		api.logger.Error("Failed to list insights", zap.Error(err))
+		http.Error(w, "Failed to compute analytics", http.StatusInternalServerError)
+		return
+	}
+	if len(opts.PrivateTeams) > 0 {
+		if opts.Secret, err = api.stateStore.AnalyticsSecret(ctx); err != nil {
+			api.logger.Error("Failed to load analytics secret", zap.Error(err))
+			http.Error(w, "Failed to compute analytics", http.StatusInternalServerError)
+			return
+		}
+	}
+	result := analytics.Compute(insights, opts, now)
+
+	if result.Privacy != nil {
+		// Kept until the next bucket closes and the window moves on
+		if err := api.stateStore.SaveAnalytics(ctx, key, result, bucket); err != nil {
+			api.logger.Error("Failed to cache analytics", zap.Error(err))
+			http.Error(w, "Failed to compute analytics", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleCommunicationMatrix handles GET /api/analytics/communication-matrix:
+// messages and average edge weights between agent roles over span (default
+// 24h) in buckets (default 1h), from the topology's snapshot history
+func (api *APIServer) handleCommunicationMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	span, bucket := 24*time.Hour, time.Hour
+	for name, target := range map[string]*time.Duration{"span": &span, "bucket": &bucket} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				http.Error(w, fmt.Sprintf("%s must be a positive duration such as 1h", name), http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+	if span < bucket || span/bucket > analytics.MaxBuckets {
+		http.Error(w, fmt.Sprintf("span must cover between 1 and %d buckets", analytics.MaxBuckets), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	opts := analytics.Options{Bucket: bucket}
+	opts.Start, opts.End = analytics.Window(now, span, bucket)
+
+	// A bucket before the window gives the usage the first bucket's traffic is counted from
+	snapshots, err := api.stateStore.ListSnapshots(r.Context(), opts.Start.Add(-bucket), opts.End)
+	if err != nil {
+		api.logger.Error("Failed to list snapshots", zap.Error(err))
+		http.Error(w, "Failed to compute communication matrix", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.CommunicationMatrix(snapshots, opts, now))
+}
+
+// handleCompatibility handles GET /api/compatibility, reporting which protocol
+// versions this build accepts and how each agent in the mesh is handled
+func (api *APIServer) handleCompatibility(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	versions := []map[string]any{}
+	for v := 0; v <= types.ProtocolVersion+1; v++ {
+		entry := map[string]any{
+			"version":       v,
+			"compatibility": types.CheckProtocol(v, 0),
+		}
+		for _, release := range types.ProtocolReleases {
+			if release.Version == v {
+				entry["description"] = release.Description
+			}
+		}
+		if v == 0 {
+			entry["description"] = "Unversioned (treated as version 1)"
+		} else if v > types.ProtocolVersion {
+			entry["description"] = "Newer than this build"
+		}
+		versions = append(versions, entry)
+	}
+
+	agents := []map[string]any{}
+	if snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context()); err == nil {
+		for _, agent := range snapshot.Agents {
+			protocol := agent.Protocol
+			if protocol == 0 {
+				protocol = 1
+			}
+			agents = append(agents, map[string]any{
+				"agent_id":      agent.ID,
+				"protocol":      protocol,
+				"compatibility": types.CheckProtocol(protocol, 0),
+			})
+		}
+		sort.Slice(agents, func(i, j int) bool {
+			return agents[i]["agent_id"].(types.AgentID) < agents[j]["agent_id"].(types.AgentID)
+		})
+	} else {
+		api.logger.Debug("No topology snapshot for compatibility report", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"protocol_version":     types.ProtocolVersion,
+		"min_protocol_version": types.MinProtocolVersion,
+		"versions":             versions,
+		"agents":               agents,
+	})
+}
+
+// handleRouting handles GET /api/routing - task outcome statistics per route
+func (api *APIServer) handleRouting(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := api.stateStore.LoadRouteStats(r.Context())
+	if err != nil {
+		api.logger.Error("Failed to load route stats", zap.Error(err))
+		http.Error(w, "Failed to load route stats", http.StatusInternalServerError)
+		return
+	}
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		filtered := stats[:0]
+		for _, s := range stats {
+			if s.From == types.AgentID(from) {
+				filtered = append(filtered, s)
+			}
+		}
+		stats = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"learning": api.config.RoutingLearning,
+		"routes":   stats,
+		"count":    len(stats),
+	})
+}
+
+// handleRoutingCandidates handles GET /api/routing/candidates?from=<agent>&role=<role>.
+// It ranks the agents with the role as targets for a task from the given agent
+// and selects one, exploring when routing learning is enabled or spreading tasks
+// by load with ROUTING_LOAD_BALANCE. Slow links rank lower with ROUTING_LATENCY_TARGET. ?capability=<name> limits candidates to
+// agents with the capability, attested if ?attested=true or REQUIRE_ATTESTATION is set.
+// Only agents in the sender's namespace are candidates.
+func (api *APIServer) handleRoutingCandidates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from := types.AgentID(r.URL.Query().Get("from"))
+	role := r.URL.Query().Get("role")
+	capability := r.URL.Query().Get("capability")
+	attested := api.config.RequireAttestation || r.URL.Query().Get("attested") == "true"
+	if from == "" || (role == "" && capability == "") {
+		http.Error(w, "from and role or capability are required", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := api.stateStore.LoadGraphSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, "Topology not available", http.StatusServiceUnavailable)
+		return
+	}
+	stats, err := api.stateStore.LoadRouteStats(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to load route stats, ranking by weight only", zap.Error(err))
+	}
+	load, err := api.stateStore.LoadAgentLoad(r.Context())
+	if err != nil {
+		api.logger.Warn("Failed to load agent load, balancing by score only", zap.Error(err))
+	}
+
+	sender, known := snapshot.Agents[from]
+	candidates := []types.RouteCandidate{}
+	for id, agent := range snapshot.Agents {
+		if (role != "" && agent.Role != role) || id == from || !agent.Qualifies(capability, attested) || !agent.Routable() {
+			continue
+		}
+		if known && !agent.SameNamespace(sender) {
+			continue
+		}
+		candidate := types.RouteCandidate{AgentID: id, Role: agent.Role, Region: agent.Region, Load: load[id]}
+		if edge, ok := snapshot.Edges[api.config.EdgeMode.EdgeID(from, id)]; ok {
+			candidate.Weight, candidate.LatencyMs = edge.Weight, edge.LatencyP50Ms
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Prefer candidates in the sender's region, then over fast links
+	ranked := routing.Rank(api.config, from, candidates, stats)
+	if known {
+		ranked = routing.PreferRegion(api.config, sender.Region, ranked)
+	}
+	ranked = routing.PreferLowLatency(api.config, ranked)
+	response := map[string]any{
+		"learning":   api.config.RoutingLearning,
+		"candidates": ranked,
+	}
+	if selected, ok := routing.Select(api.config, ranked); ok {
+		response["selected"] = selected.AgentID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// requester returns the directory entry of the agent querying insights, or nil
+// for anonymous queries. Unknown agents get no attested data access.
+func (api *APIServer) requester(ctx context.Context, agentID types.AgentID) *types.Agent {
+	if agentID == "" {
+		return nil
+	}
+	if snapshot, err := api.stateStore.LoadGraphSnapshot(ctx); err == nil {
+		if agent, ok := snapshot.Agents[agentID]; ok {
+			return agent
+		}
+	}
+	return &types.Agent{ID: agentID}
+}
+
+// queryInsightsFromRedis queries insights from Redis with filters
+func (api *APIServer) queryInsightsFromRedis(ctx context.Context, query types.KnowledgeQuery) ([]types.Insight, error) {
+	// Read what the knowledge manager persisted, so every API replica serves the same view
+	return manager.QueryStoredInsights(ctx, api.stateStore, api.config, query, api.requester(ctx, query.AgentID))
 }
 
 // corsMiddleware adds CORS headers
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/connectors"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Connector: an agent that pulls knowledge from an external system
+// Converts Zendesk tickets, Jira issues or CRM export rows into insights
+// Publishes them to Kafka like any agent's insights
+// The source's credentials are read from CONNECTOR_TOKEN
+
+func main() {
+	// Parse command-line flags
+	source := flag.String("source", "", "Source to pull: zendesk, jira or crm (required)")
+	name := flag.String("name", "", "Agent name (default: <source>-connector)")
+	role := flag.String("role", "connector", "Agent role")
+	baseURL := flag.String("url", "", "Base URL of the Zendesk or Jira instance")
+	jql := flag.String("jql", "", "JQL filter of the Jira issues to pull (default: all)")
+	path := flag.String("path", "", "Path of the CRM CSV export")
+	agentID := flag.String("id", "", "Agent ID, to keep the same agent across restarts (default: generated)")
+	flag.Parse()
+
+	if *name == "" {
+		*name = *source + "-connector"
+	}
+
+	// Initialize logger
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	var src connectors.Source
+	switch *source {
+	case "zendesk":
+		src = connectors.NewZendeskSource(*baseURL, os.Getenv("CONNECTOR_TOKEN"))
+	case "jira":
+		src = connectors.NewJiraSource(*baseURL, *jql, os.Getenv("CONNECTOR_TOKEN"))
+	case "crm":
+		src = connectors.NewCRMExportSource(*path)
+	default:
+		fmt.Println("Usage: connector -source=zendesk|jira -url=<base url> [-jql=<filter>] | -source=crm -path=<export.csv>")
+		os.Exit(1)
+	}
+	if (*source == "crm" && *path == "") || (*source != "crm" && *baseURL == "") {
+		logger.Fatal("Missing source location; zendesk and jira need -url, crm needs -path")
+	}
+
+	logger.Info("Starting AgentMesh Cortex Connector", zap.String("source", *source), zap.String("name", *name))
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+
+	agent := &types.Agent{
+		ID:           types.AgentID(*agentID),
+		Name:         *name,
+		Role:         *role,
+		Status:       types.AgentStatusActive,
+		Capabilities: []types.Capability{},
+		Metadata:     map[string]string{"source": *source},
+		CreatedAt:    time.Now(),
+		LastSeenAt:   time.Now(),
+		Region:       cfg.Region,
+	}
+	if agent.ID == "" {
+		agent.ID = types.NewAgentID()
+	}
+	if err := agent.Validate(); err != nil {
+		logger.Fatal("Invalid agent definition", zap.Error(err))
+	}
+
+	// Initialize Kafka messaging
+	kafkaMessaging := messaging.NewKafkaMessaging(cfg, logger)
+	defer kafkaMessaging.Close()
+
+	connector := connectors.NewConnector(agent, src, kafkaMessaging, cfg, logger)
+	if err := connector.Start(context.Background()); err != nil {
+		logger.Fatal("Failed to start connector", zap.Error(err))
+	}
+	defer connector.Stop()
+
+	logger.Info("Connector running", zap.String("agent_id", string(agent.ID)))
+
+	// Wait for interrupt
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	logger.Info("Connector shutting down...")
+}
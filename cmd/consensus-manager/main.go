@@ -6,15 +6,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
-	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/health"
+	"github.com/avinashshinde/agentmesh-cortex/internal/manager"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
 	"github.com/avinashshinde/agentmesh-cortex/internal/state"
-	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
 // Consensus Manager: Central service that manages proposals and voting
@@ -34,7 +33,16 @@ func main() {
 	logger.Info("Starting Consensus Manager (Bee Swarm)")
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+	if os.Getenv("ESCALATION_POLICIES") != "" && cfg.EscalationPolicies == nil {
+		logger.Warn("Ignoring invalid ESCALATION_POLICIES; expired proposals will not escalate")
+	}
 
 	// Initialize Redis store
 	redisStore, err := state.NewRedisStore(cfg, logger)
@@ -47,38 +55,16 @@ func main() {
 	kafkaMessaging := messaging.NewKafkaMessaging(cfg, logger)
 	defer kafkaMessaging.Close()
 
-	// Initialize Bee consensus
-	beeConsensus := consensus.NewBeeConsensus(cfg, logger)
+	// Initialize Bee consensus manager
+	consensusManager := manager.NewConsensusManager(kafkaMessaging, redisStore, cfg, logger)
 	ctx := context.Background()
-	if err := beeConsensus.Start(ctx); err != nil {
+	if err := consensusManager.Start(ctx); err != nil {
 		logger.Fatal("Failed to start Bee consensus", zap.Error(err))
 	}
-	defer beeConsensus.Stop()
-
-	// Listen to proposals from Kafka
-	go listenToProposals(ctx, kafkaMessaging, beeConsensus, redisStore, logger)
-
-	// Listen to votes from Kafka
-	go listenToVotes(ctx, kafkaMessaging, beeConsensus, logger)
-
-	// Monitor consensus events
-	go monitorConsensusEvents(beeConsensus, kafkaMessaging, logger)
+	defer consensusManager.Stop()
 
-	// Print stats periodically
-	go func() {
-		ticker := time.NewTicker(15 * time.Second)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			stats := beeConsensus.GetStats()
-			logger.Info("Consensus stats",
-				zap.Int("total_proposals", stats["total_proposals"]),
-				zap.Int("pending", stats["pending_proposals"]),
-				zap.Int("accepted", stats["accepted_proposals"]),
-				zap.Int("active_agents", stats["active_agents"]),
-			)
-		}
-	}()
+	// Report our health for the system health rollup
+	go health.NewReporter("consensus-manager", redisStore, kafkaMessaging, logger, "consensus-manager").Run(ctx)
 
 	logger.Info("Consensus Manager running")
 
@@ -89,96 +75,3 @@ func main() {
 
 	logger.Info("Consensus Manager shutting down...")
 }
-
-func listenToProposals(ctx context.Context, messaging *messaging.KafkaMessaging, beeConsensus *consensus.BeeConsensus, redisStore *state.RedisStore, logger *zap.Logger) {
-	err := messaging.ConsumeMessages(ctx, "proposals", "consensus-manager", func(msg *types.Message) error {
-		// Parse proposal from message
-		proposalData, ok := msg.Payload["proposal"].(map[string]any)
-		if !ok {
-			return nil
-		}
-
-		proposerID := types.AgentID(proposalData["proposer_id"].(string))
-		proposalType := types.ProposalType(proposalData["type"].(string))
-		content := proposalData["content"].(map[string]any)
-
-		// Create proposal in consensus engine
-		proposal, err := beeConsensus.CreateProposal(proposerID, proposalType, content)
-		if err != nil {
-			logger.Error("Failed to create proposal", zap.Error(err))
-			return err
-		}
-
-		// Save to Redis
-		if err := redisStore.SaveProposal(ctx, proposal); err != nil {
-			logger.Error("Failed to save proposal to Redis", zap.Error(err))
-		}
-
-		logger.Info("Proposal created",
-			zap.String("proposal_id", string(proposal.ID)),
-			zap.String("proposer", string(proposerID)),
-		)
-
-		return nil
-	})
-
-	if err != nil && err != context.Canceled {
-		logger.Error("Proposal listener stopped", zap.Error(err))
-	}
-}
-
-func listenToVotes(ctx context.Context, messaging *messaging.KafkaMessaging, beeConsensus *consensus.BeeConsensus, logger *zap.Logger) {
-	err := messaging.ConsumeMessages(ctx, "votes", "consensus-manager", func(msg *types.Message) error {
-		// Parse vote from message
-		voteData, ok := msg.Payload["vote"].(map[string]any)
-		if !ok {
-			return nil
-		}
-
-		proposalID := types.ProposalID(voteData["proposal_id"].(string))
-		voterID := types.AgentID(voteData["voter_id"].(string))
-		support := voteData["support"].(bool)
-		intensity := voteData["intensity"].(float64)
-
-		// Register vote
-		if err := beeConsensus.Vote(proposalID, voterID, support, intensity); err != nil {
-			logger.Error("Failed to register vote", zap.Error(err))
-			return err
-		}
-
-		logger.Debug("Vote registered",
-			zap.String("proposal_id", string(proposalID)),
-			zap.String("voter_id", string(voterID)),
-			zap.Bool("support", support),
-		)
-
-		return nil
-	})
-
-	if err != nil && err != context.Canceled {
-		logger.Error("Vote listener stopped", zap.Error(err))
-	}
-}
-
-func monitorConsensusEvents(beeConsensus *consensus.BeeConsensus, messaging *messaging.KafkaMessaging, logger *zap.Logger) {
-	for event := range beeConsensus.EventChannel() {
-		switch event.Type {
-		case consensus.ConsensusEventProposalCreated:
-			logger.Info("[PROPOSAL] Proposal created",
-				zap.String("proposal_id", string(event.ProposalID)),
-			)
-		case consensus.ConsensusEventQuorumReached:
-			logger.Info("[QUORUM] Quorum reached!",
-				zap.String("proposal_id", string(event.ProposalID)),
-			)
-		case consensus.ConsensusEventProposalAccepted:
-			logger.Info("[ACCEPTED] Proposal ACCEPTED",
-				zap.String("proposal_id", string(event.ProposalID)),
-			)
-		case consensus.ConsensusEventProposalRejected:
-			logger.Info("[REJECTED] Proposal REJECTED",
-				zap.String("proposal_id", string(event.ProposalID)),
-			)
-		}
-	}
-}
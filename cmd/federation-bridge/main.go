@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/federation"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+)
+
+// Federation Bridge: links the mesh of one region to the other regions
+// Listens to the local Kafka cluster for pushed insights
+// Replicates the high-value ones to the Kafka clusters of its peers
+
+func main() {
+	// Initialize logger
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	logger.Info("Starting Federation Bridge")
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+	if os.Getenv("FEDERATION_PEERS") != "" && cfg.FederationPeers == nil {
+		logger.Fatal("Invalid FEDERATION_PEERS; expected region=broker1,broker2;region=broker3")
+	}
+
+	// Initialize Kafka messaging for the local region
+	kafkaMessaging := messaging.NewKafkaMessaging(cfg, logger)
+	defer kafkaMessaging.Close()
+
+	bridge := federation.NewBridge(kafkaMessaging, cfg, logger)
+	if err := bridge.Start(context.Background()); err != nil {
+		logger.Fatal("Failed to start federation bridge", zap.Error(err))
+	}
+	defer bridge.Stop()
+
+	logger.Info("Federation Bridge running")
+
+	// Wait for interrupt
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	logger.Info("Federation Bridge shutting down...")
+}
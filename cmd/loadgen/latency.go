@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// latencyRecorder consumes what loadgen produced and records publish-to-consume latency per topic
+type latencyRecorder struct {
+	runID  string
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+	probes  map[string]bool
+}
+
+// LatencyStats summarizes latency samples of one stream
+type LatencyStats struct {
+	Count int     `json:"count"`
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+func newLatencyRecorder(runID string, logger *zap.Logger) *latencyRecorder {
+	return &latencyRecorder{
+		runID:   runID,
+		logger:  logger,
+		samples: make(map[string][]time.Duration),
+		probes:  make(map[string]bool),
+	}
+}
+
+// consume reads a topic with a run-scoped consumer group until ctx is cancelled
+func (lr *latencyRecorder) consume(ctx context.Context, cfg *types.Config, topic string) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.KafkaBrokers,
+		Topic:       cfg.KafkaTopicPrefix + "." + topic,
+		GroupID:     "loadgen-" + lr.runID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     50 * time.Millisecond,
+		StartOffset: kafka.LastOffset,
+	})
+	defer reader.Close()
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			lr.logger.Debug("Failed to read message", zap.String("topic", topic), zap.Error(err))
+			continue
+		}
+		received := time.Now()
+
+		var message types.Message
+		if err := json.Unmarshal(msg.Value, &message); err != nil {
+			continue
+		}
+		lr.record(topic, &message, received)
+	}
+}
+
+// record stores the latency of one of this run's messages; messages from other producers are ignored
+func (lr *latencyRecorder) record(topic string, message *types.Message, received time.Time) {
+	if !strings.HasPrefix(message.ID, lr.runID) {
+		return
+	}
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	if strings.HasSuffix(message.ID, probeSuffix) {
+		lr.probes[topic] = true
+		return
+	}
+	lr.samples[topic] = append(lr.samples[topic], received.Sub(message.Timestamp))
+}
+
+// seenProbe reports whether the probe for topic has been consumed
+func (lr *latencyRecorder) seenProbe(topic string) bool {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.probes[topic]
+}
+
+// drain returns and clears the samples collected so far for topic
+func (lr *latencyRecorder) drain(topic string) LatencyStats {
+	lr.mu.Lock()
+	samples := lr.samples[topic]
+	delete(lr.samples, topic)
+	lr.mu.Unlock()
+
+	return summarize(samples)
+}
+
+// summarize computes nearest-rank percentiles
+func summarize(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]float64, len(samples))
+	for i, sample := range samples {
+		sorted[i] = toMs(sample)
+	}
+	sort.Float64s(sorted)
+
+	return LatencyStats{
+		Count: len(sorted),
+		P50Ms: types.Percentile(sorted, 0.50),
+		P95Ms: types.Percentile(sorted, 0.95),
+		P99Ms: types.Percentile(sorted, 0.99),
+		MaxMs: sorted[len(sorted)-1],
+	}
+}
+
+func toMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/personas"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Load Generator: drives a running mesh at configurable message/insight/vote rates
+// Runs a series of steps (multiples of the base rates), measuring publish-to-consume
+// latency and manager consumer lag, and reports the max sustainable throughput
+
+// stepReport is the outcome of one rate step
+type stepReport struct {
+	Step        int                      `json:"step"`
+	Multiplier  float64                  `json:"multiplier"`
+	Streams     map[string]*StreamResult `json:"streams"`
+	Sustainable bool                     `json:"sustainable"`
+	Reasons     []string                 `json:"reasons,omitempty"`
+}
+
+// runReport is the full loadgen output
+type runReport struct {
+	RunID          string             `json:"run_id"`
+	StartedAt      time.Time          `json:"started_at"`
+	Agents         int                `json:"agents"`
+	BaseRates      map[string]float64 `json:"base_rates"`
+	StepDuration   string             `json:"step_duration"`
+	MaxP99Ms       float64            `json:"max_p99_ms"`
+	MaxLag         int64              `json:"max_lag"`
+	Steps          []*stepReport      `json:"steps"`
+	MaxSustainable *stepReport        `json:"max_sustainable,omitempty"`
+}
+
+func main() {
+	agentCount := flag.Int("agents", 20, "Number of synthetic agents to join")
+	messageRate := flag.Float64("message-rate", 100, "Base agent-to-agent messages per second")
+	insightRate := flag.Float64("insight-rate", 10, "Base insights per second")
+	voteRate := flag.Float64("vote-rate", 10, "Base votes per second")
+	steps := flag.String("steps", "1,2,4,8", "Comma-separated multipliers applied to the base rates, one step each")
+	stepDuration := flag.Duration("step-duration", 30*time.Second, "How long each step produces traffic")
+	drain := flag.Duration("drain", 5*time.Second, "Wait after each step before measuring latency and lag")
+	workers := flag.Int("workers", 32, "Concurrent publishers per stream")
+	maxP99 := flag.Float64("max-p99", 500, "p99 latency (ms) above which a step is unsustainable")
+	maxLag := flag.Int64("max-lag", 1000, "Consumer lag above which a step is unsustainable")
+	reportPath := flag.String("report", "", "Write the JSON report to this file")
+	usePersonas := flag.Bool("personas", false, "Give agents the simulation personas' roles and send their messages instead of empty tasks")
+	verbose := flag.Bool("v", false, "Verbose logging")
+	flag.Parse()
+
+	logger := zap.NewNop()
+	if *verbose {
+		logger, _ = zap.NewDevelopment()
+	}
+	defer logger.Sync()
+
+	multipliers, err := parseMultipliers(*steps)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -steps: %v\n", err)
+		os.Exit(1)
+	}
+	if *agentCount < 2 {
+		fmt.Fprintln(os.Stderr, "-agents must be at least 2")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+	km := messaging.NewKafkaMessaging(cfg, logger)
+	defer km.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping after current step...")
+		cancel()
+	}()
+
+	runID := "loadgen-" + uuid.New().String()[:8]
+	report := &runReport{
+		RunID:        runID,
+		StartedAt:    time.Now(),
+		Agents:       *agentCount,
+		BaseRates:    map[string]float64{"messages": *messageRate, "insights": *insightRate, "votes": *voteRate},
+		StepDuration: stepDuration.String(),
+		MaxP99Ms:     *maxP99,
+		MaxLag:       *maxLag,
+	}
+
+	// Join synthetic agents so messages reinforce real edges
+	agents := make([]*types.Agent, *agentCount)
+	roles := personas.Roles()
+	for i := range agents {
+		agents[i] = &types.Agent{
+			ID:        types.AgentID(fmt.Sprintf("%s-agent-%d", runID, i)),
+			Name:      fmt.Sprintf("loadgen-%d", i),
+			Role:      "loadgen",
+			Status:    types.AgentStatusActive,
+			Metadata:  map[string]string{"loadgen_run": runID},
+			CreatedAt: time.Now(),
+		}
+		if *usePersonas {
+			persona, _ := personas.Get(roles[i%len(roles)])
+			agents[i].Role = persona.Role
+			agents[i].Capabilities = types.ParseCapabilities(persona.Capabilities)
+		}
+		if err := km.PublishTopologyEvent(ctx, types.TopologyEvent{
+			Type:      types.TopologyEventAgentJoined,
+			AgentID:   agents[i].ID,
+			Agent:     agents[i],
+			Timestamp: time.Now(),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to join agents: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	defer leaveAgents(km, agents)
+
+	streams := newStreams(km, agents, runID, *usePersonas)
+
+	// Start latency consumers and wait until they are positioned at the head of each topic
+	recorder := newLatencyRecorder(runID, logger)
+	consumeCtx, stopConsumers := context.WithCancel(ctx)
+	var consumers sync.WaitGroup
+	for _, s := range streams {
+		consumers.Add(1)
+		go func(topic string) {
+			defer consumers.Done()
+			recorder.consume(consumeCtx, cfg, topic)
+		}(s.name)
+	}
+	if err := waitForProbes(ctx, streams, recorder, runID); err != nil {
+		fmt.Fprintf(os.Stderr, "Latency consumers not ready: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Run %s: %d agents, %d steps of %s\n", runID, *agentCount, len(multipliers), *stepDuration)
+
+	for i, multiplier := range multipliers {
+		if ctx.Err() != nil {
+			break
+		}
+
+		step := &stepReport{Step: i + 1, Multiplier: multiplier, Streams: map[string]*StreamResult{}}
+		fmt.Printf("Step %d: x%g ...\n", step.Step, multiplier)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, s := range streams {
+			wg.Add(1)
+			go func(s *stream) {
+				defer wg.Done()
+				result := s.run(ctx, runID, step.Step, report.BaseRates[s.name]*multiplier, *stepDuration, *workers)
+				mu.Lock()
+				step.Streams[s.name] = &result
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+
+		time.Sleep(*drain)
+
+		for _, s := range streams {
+			result := step.Streams[s.name]
+			result.Latency = recorder.drain(s.name)
+
+			lag, err := km.ConsumerLag(ctx, s.group)
+			if err != nil {
+				logger.Warn("Failed to read consumer lag", zap.String("group", s.group), zap.Error(err))
+			}
+			result.ConsumerLag = lag[cfg.KafkaTopicPrefix+"."+s.name]
+		}
+
+		evaluate(step, *maxP99, *maxLag)
+		report.Steps = append(report.Steps, step)
+		if step.Sustainable && (report.MaxSustainable == nil || multiplier > report.MaxSustainable.Multiplier) {
+			report.MaxSustainable = step
+		}
+	}
+
+	stopConsumers()
+	consumers.Wait()
+
+	printReport(report)
+
+	if *reportPath != "" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*reportPath, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Report written to %s\n", *reportPath)
+	}
+}
+
+// evaluate marks a step sustainable when every active stream kept up with its target,
+// stayed under the p99 latency budget and its manager drained to within the lag budget
+func evaluate(step *stepReport, maxP99 float64, maxLag int64) {
+	for name, r := range step.Streams {
+		if r.TargetRate <= 0 {
+			continue
+		}
+		if r.AchievedRate < 0.95*r.TargetRate {
+			step.Reasons = append(step.Reasons, fmt.Sprintf("%s: achieved %.0f/s of %.0f/s", name, r.AchievedRate, r.TargetRate))
+		}
+		if r.Errors > 0 {
+			step.Reasons = append(step.Reasons, fmt.Sprintf("%s: %d publish errors", name, r.Errors))
+		}
+		if r.Latency.P99Ms > maxP99 {
+			step.Reasons = append(step.Reasons, fmt.Sprintf("%s: p99 %.0fms > %.0fms", name, r.Latency.P99Ms, maxP99))
+		}
+		if r.ConsumerLag > maxLag {
+			step.Reasons = append(step.Reasons, fmt.Sprintf("%s: consumer lag %d > %d", name, r.ConsumerLag, maxLag))
+		}
+	}
+	step.Sustainable = len(step.Reasons) == 0
+}
+
+// waitForProbes publishes a probe per topic until the latency consumers have seen it
+func waitForProbes(ctx context.Context, streams []*stream, recorder *latencyRecorder, runID string) error {
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		ready := true
+		for _, s := range streams {
+			if recorder.seenProbe(s.name) {
+				continue
+			}
+			ready = false
+			if err := s.publish(ctx, runID+"-"+s.name+probeSuffix); err != nil {
+				return err
+			}
+		}
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for consumer group to join")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// leaveAgents removes the synthetic agents from the topology
+func leaveAgents(km *messaging.KafkaMessaging, agents []*types.Agent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, agent := range agents {
+		km.PublishTopologyEvent(ctx, types.TopologyEvent{
+			Type:      types.TopologyEventAgentLeft,
+			AgentID:   agent.ID,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+func parseMultipliers(s string) ([]float64, error) {
+	var multipliers []float64
+	for _, part := range strings.Split(s, ",") {
+		m, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if m <= 0 {
+			return nil, fmt.Errorf("multiplier must be positive, got %g", m)
+		}
+		multipliers = append(multipliers, m)
+	}
+	return multipliers, nil
+}
+
+func printReport(report *runReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSTEP\tSTREAM\tTARGET/s\tACHIEVED/s\tP50ms\tP95ms\tP99ms\tLAG\tOK")
+	for _, step := range report.Steps {
+		for _, name := range []string{"messages", "insights", "votes"} {
+			r := step.Streams[name]
+			if r == nil || r.TargetRate <= 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%d (x%g)\t%s\t%.0f\t%.0f\t%.1f\t%.1f\t%.1f\t%d\t%v\n",
+				step.Step, step.Multiplier, name, r.TargetRate, r.AchievedRate,
+				r.Latency.P50Ms, r.Latency.P95Ms, r.Latency.P99Ms, r.ConsumerLag, step.Sustainable)
+		}
+	}
+	w.Flush()
+
+	for _, step := range report.Steps {
+		for _, reason := range step.Reasons {
+			fmt.Printf("  step %d: %s\n", step.Step, reason)
+		}
+	}
+
+	if report.MaxSustainable == nil {
+		fmt.Println("\nNo step was sustainable")
+		return
+	}
+	best := report.MaxSustainable
+	fmt.Printf("\nMax sustainable throughput: step %d (x%g): %.0f messages/s, %.0f insights/s, %.0f votes/s\n",
+		best.Step, best.Multiplier,
+		achieved(best, "messages"), achieved(best, "insights"), achieved(best, "votes"))
+}
+
+func achieved(step *stepReport, name string) float64 {
+	if r := step.Streams[name]; r != nil {
+		return r.AchievedRate
+	}
+	return 0
+}
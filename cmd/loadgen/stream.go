@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/personas"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// probeSuffix marks messages used to confirm the latency consumers are positioned
+const probeSuffix = "-probe"
+
+// stream produces one kind of traffic at a target rate
+type stream struct {
+	name  string // Kafka topic (without prefix)
+	group string // Manager consumer group whose lag is reported for this topic
+
+	publish func(ctx context.Context, id string) error
+}
+
+// StreamResult reports what a stream achieved during one step
+type StreamResult struct {
+	TargetRate   float64      `json:"target_rate"`
+	AchievedRate float64      `json:"achieved_rate"`
+	Sent         int64        `json:"sent"`
+	Errors       int64        `json:"errors"`
+	Dropped      int64        `json:"dropped"` // Sends skipped because all workers were busy
+	Latency      LatencyStats `json:"latency"`
+	ConsumerLag  int64        `json:"consumer_lag"`
+}
+
+// newStreams builds the message, insight and vote streams for a set of synthetic agents.
+// With usePersonas, messages are the ones the sender's persona would send, to an agent of the target role.
+func newStreams(km *messaging.KafkaMessaging, agents []*types.Agent, runID string, usePersonas bool) []*stream {
+	pick := func() *types.Agent { return agents[rand.Intn(len(agents))] }
+
+	byRole := make(map[string][]*types.Agent)
+	roster := make(map[string]*personas.Persona)
+	if usePersonas {
+		for _, agent := range agents {
+			byRole[agent.Role] = append(byRole[agent.Role], agent)
+			if _, ok := roster[agent.Role]; !ok {
+				roster[agent.Role], _ = personas.Get(agent.Role)
+			}
+		}
+	}
+	var composed atomic.Int64
+
+	return []*stream{
+		{
+			name:  "messages",
+			group: "topology-reinforcement",
+			publish: func(ctx context.Context, id string) error {
+				from, to := pick(), pick()
+				payload := map[string]any{}
+				if persona := roster[from.Role]; persona != nil {
+					if action, ok := persona.Compose(int(composed.Add(1))); ok {
+						payload = action.Payload
+						if targets := byRole[action.TargetRole]; len(targets) > 0 {
+							to = targets[rand.Intn(len(targets))]
+						}
+					}
+				}
+				payload["loadgen_run"] = runID
+
+				return km.PublishMessage(ctx, "messages", &types.Message{
+					ID:          id,
+					FromAgentID: from.ID,
+					ToAgentID:   to.ID,
+					Type:        types.MessageTypeTask,
+					Payload:     payload,
+					Timestamp:   time.Now(),
+				})
+			},
+		},
+		{
+			name:  "insights",
+			group: "knowledge-manager",
+			publish: func(ctx context.Context, id string) error {
+				agent := pick()
+				return km.PublishInsight(ctx, &types.Insight{
+					ID:         types.InsightID(id),
+					AgentID:    agent.ID,
+					AgentRole:  agent.Role,
+					Type:       types.InsightTypeAnomaly,
+					Topic:      "loadgen",
+					Content:    "Synthetic insight from load generator",
+					Confidence: rand.Float64(),
+					Metadata:   map[string]string{"loadgen_run": runID},
+					Privacy:    types.InsightPrivacyPublic,
+					CreatedAt:  time.Now(),
+				})
+			},
+		},
+		{
+			// Votes reference a synthetic proposal, so the consensus manager parses
+			// and rejects them: this measures consumer throughput, not quorum logic
+			name:  "votes",
+			group: "consensus-manager",
+			publish: func(ctx context.Context, id string) error {
+				voter := pick()
+				return km.PublishMessage(ctx, "votes", &types.Message{
+					ID:          id,
+					FromAgentID: voter.ID,
+					Type:        types.MessageTypeVote,
+					Payload: map[string]any{
+						"vote": map[string]any{
+							"proposal_id": "loadgen-" + runID,
+							"voter_id":    string(voter.ID),
+							"support":     rand.Intn(2) == 0,
+							"intensity":   rand.Float64(),
+						},
+					},
+					Timestamp: time.Now(),
+				})
+			},
+		},
+	}
+}
+
+// run publishes at rate messages/second for duration using a pool of workers.
+// Sending is paced every 10ms so rates well above 100/s are reachable.
+func (s *stream) run(ctx context.Context, runID string, step int, rate float64, duration time.Duration, workers int) StreamResult {
+	result := StreamResult{TargetRate: rate}
+	if rate <= 0 {
+		return result
+	}
+
+	var sent, errors int64
+	jobs := make(chan string, workers*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if err := s.publish(ctx, id); err != nil {
+					atomic.AddInt64(&errors, 1)
+					continue
+				}
+				atomic.AddInt64(&sent, 1)
+			}
+		}()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	var scheduled int64
+
+pacing:
+	for {
+		select {
+		case <-ctx.Done():
+			break pacing
+		case now := <-ticker.C:
+			elapsed := now.Sub(start)
+			if elapsed >= duration {
+				break pacing
+			}
+			due := int64(elapsed.Seconds()*rate) - scheduled
+			for ; due > 0; due-- {
+				scheduled++
+				select {
+				case jobs <- fmt.Sprintf("%s-%d-%s-%d", runID, step, s.name, scheduled):
+				default:
+					result.Dropped++
+				}
+			}
+		}
+	}
+	ticker.Stop()
+	close(jobs)
+	wg.Wait()
+
+	result.Sent = sent
+	result.Errors = errors
+	result.AchievedRate = float64(sent) / time.Since(start).Seconds()
+	return result
+}
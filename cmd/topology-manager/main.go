@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,9 +12,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/handoff"
+	"github.com/avinashshinde/agentmesh-cortex/internal/health"
+	"github.com/avinashshinde/agentmesh-cortex/internal/manager"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
 	"github.com/avinashshinde/agentmesh-cortex/internal/state"
-	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
@@ -34,7 +37,16 @@ func main() {
 	logger.Info("Starting Topology Manager (SlimeMold)")
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
+	if os.Getenv("ATTESTATION_CA_KEYS") != "" && cfg.AttestationKeys == nil {
+		logger.Warn("Ignoring invalid ATTESTATION_CA_KEYS; no agent attestation will verify")
+	}
 
 	// Initialize Redis store
 	redisStore, err := state.NewRedisStore(cfg, logger)
@@ -47,32 +59,31 @@ func main() {
 	kafkaMessaging := messaging.NewKafkaMessaging(cfg, logger)
 	defer kafkaMessaging.Close()
 
-	// Initialize SlimeMold topology
-	slimeMold := topology.NewSlimeMoldTopology(cfg, logger)
+	// Initialize SlimeMold topology manager
+	topologyManager := manager.NewTopologyManager(kafkaMessaging, redisStore, cfg, logger)
 	ctx := context.Background()
-	if err := slimeMold.Start(ctx); err != nil {
+	if err := startWithHandoff(ctx, cfg, topologyManager, logger); err != nil {
 		logger.Fatal("Failed to start SlimeMold", zap.Error(err))
 	}
-	defer slimeMold.Stop()
-
-	// Start listening to topology events from Kafka
-	go listenToTopologyEvents(ctx, kafkaMessaging, slimeMold, logger)
-
-	// Start listening to messages (for edge reinforcement)
-	go listenToMessages(ctx, kafkaMessaging, slimeMold, logger)
-
-	// Periodically save snapshot to Redis
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			snapshot := slimeMold.GetSnapshot()
-			if err := redisStore.SaveGraphSnapshot(ctx, snapshot); err != nil {
-				logger.Error("Failed to save snapshot", zap.Error(err))
+	defer topologyManager.Stop()
+
+	// Serve our state to the next instance during rolling upgrades
+	var handoffDone <-chan struct{} // nil blocks forever when handoff is disabled
+	if cfg.HandoffAddr != "" {
+		handoffServer := handoff.NewServer("topology", topologyManager, logger)
+		go func() {
+			if err := handoffServer.Serve(cfg.HandoffAddr); err != nil {
+				logger.Error("Handoff server stopped", zap.Error(err))
 			}
-		}
-	}()
+		}()
+		defer handoffServer.Stop()
+		handoffDone = handoffServer.Done()
+	}
+
+	// Report our health for the system health rollup
+	reporter := health.NewReporter("topology-manager", redisStore, kafkaMessaging, logger, "topology-manager", "topology-reinforcement", "topology-lifecycle")
+	reporter.SetActive(topologyManager.Active)
+	go reporter.Run(ctx)
 
 	// Print stats periodically
 	go func() {
@@ -80,7 +91,7 @@ func main() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			slimeMold.PrintStats()
+			topologyManager.SlimeMold().PrintStats()
 		}
 	}()
 
@@ -89,54 +100,26 @@ func main() {
 	// Wait for interrupt
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	<-sigCh
+	select {
+	case <-sigCh:
+	case <-handoffDone:
+		logger.Info("State handed off to new instance")
+	}
 
 	logger.Info("Topology Manager shutting down...")
 }
 
-func listenToTopologyEvents(ctx context.Context, messaging *messaging.KafkaMessaging, slimeMold *topology.SlimeMoldTopology, logger *zap.Logger) {
-	// Listen to topology events (agent joined/left)
-	err := messaging.ConsumeTopologyEvents(ctx, "topology", "topology-manager", func(event types.TopologyEvent) error {
-		switch event.Type {
-		case types.TopologyEventAgentJoined:
-			if event.Agent != nil {
-				if err := slimeMold.AddAgent(event.Agent); err != nil {
-					logger.Error("Failed to add agent", zap.Error(err))
-				} else {
-					logger.Info("Agent added to topology",
-						zap.String("agent_id", string(event.Agent.ID)),
-						zap.String("name", event.Agent.Name),
-						zap.String("role", event.Agent.Role))
-				}
-			}
-
-		case types.TopologyEventAgentLeft:
-			if err := slimeMold.RemoveAgent(event.AgentID); err != nil {
-				logger.Error("Failed to remove agent", zap.Error(err))
-			} else {
-				logger.Info("Agent removed from topology", zap.String("agent_id", string(event.AgentID)))
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil && err != context.Canceled {
-		logger.Error("Topology event listener stopped", zap.Error(err))
+// startWithHandoff starts the manager, first taking state from cfg.HandoffFrom when set
+func startWithHandoff(ctx context.Context, cfg *types.Config, tm *manager.TopologyManager, logger *zap.Logger) error {
+	start := func() error { return tm.Start(ctx) }
+	if cfg.HandoffFrom == "" {
+		return start()
 	}
-}
-
-func listenToMessages(ctx context.Context, messaging *messaging.KafkaMessaging, slimeMold *topology.SlimeMoldTopology, logger *zap.Logger) {
-	// Listen to all messages for edge reinforcement
-	err := messaging.ConsumeMessages(ctx, "messages", "topology-reinforcement", func(msg *types.Message) error {
-		// Reinforce edge for every message
-		if err := slimeMold.ReinforceEdge(msg.FromAgentID, msg.ToAgentID); err != nil {
-			logger.Debug("Failed to reinforce edge", zap.Error(err))
-		}
-		return nil
-	})
 
-	if err != nil && err != context.Canceled {
-		logger.Error("Message listener stopped", zap.Error(err))
+	err := handoff.Takeover(ctx, cfg.HandoffFrom, "topology", tm, start, logger)
+	if errors.Is(err, handoff.ErrUnavailable) {
+		logger.Warn("No state from previous instance, starting fresh", zap.Error(err))
+		return start()
 	}
+	return err
 }
@@ -107,7 +107,7 @@ func createECommerceAgents(
 				Name:         "Sales Agent",
 				Role:         "sales",
 				Status:       types.AgentStatusActive,
-				Capabilities: []string{"process_order", "upsell", "discount_approval"},
+				Capabilities: types.ParseCapabilities([]string{"process_order", "upsell", "discount_approval"}),
 				CreatedAt:    now,
 				LastSeenAt:   now,
 			},
@@ -121,7 +121,7 @@ func createECommerceAgents(
 				Name:         "Support Agent",
 				Role:         "support",
 				Status:       types.AgentStatusActive,
-				Capabilities: []string{"handle_ticket", "refund_approval", "escalate"},
+				Capabilities: types.ParseCapabilities([]string{"handle_ticket", "refund_approval", "escalate"}),
 				CreatedAt:    now,
 				LastSeenAt:   now,
 			},
@@ -135,7 +135,7 @@ func createECommerceAgents(
 				Name:         "Inventory Agent",
 				Role:         "inventory",
 				Status:       types.AgentStatusActive,
-				Capabilities: []string{"check_stock", "reserve_items", "restock_alert"},
+				Capabilities: types.ParseCapabilities([]string{"check_stock", "reserve_items", "restock_alert"}),
 				CreatedAt:    now,
 				LastSeenAt:   now,
 			},
@@ -149,7 +149,7 @@ func createECommerceAgents(
 				Name:         "Fraud Agent",
 				Role:         "fraud",
 				Status:       types.AgentStatusActive,
-				Capabilities: []string{"risk_assessment", "block_transaction", "verify_user"},
+				Capabilities: types.ParseCapabilities([]string{"risk_assessment", "block_transaction", "verify_user"}),
 				CreatedAt:    now,
 				LastSeenAt:   now,
 			},
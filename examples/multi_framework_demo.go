@@ -13,6 +13,7 @@ import (
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/personas"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/adapters"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
@@ -45,7 +46,7 @@ func NewAgentRegistry(messaging *messaging.KafkaMessaging, ctx context.Context,
 
 	// Start listening to topology events
 	go func() {
-		err := messaging.ConsumeTopologyEvents(ctx, "topology", "agent-registry", func(event types.TopologyEvent) error {
+		err := messaging.ConsumeTopologyEvents(ctx, "topology", "agent-registry", func(ctx context.Context, event types.TopologyEvent) error {
 			registry.handleTopologyEvent(event)
 			return nil
 		})
@@ -139,7 +140,10 @@ func main() {
 	logger.Info("")
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
 
 	// Create context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -361,9 +365,9 @@ func main() {
 	time.Sleep(3 * time.Second)
 
 	// Start periodic messaging for each agent
-	go startNativeCoordinatorMessaging(nativeAgent, agentRegistry, messaging, ctx, logger)
-	go startOpenAIAgentMessaging(openaiAdapter, agentRegistry, messaging, ctx, logger)
-	go startLangChainAgentMessaging(langchainAdapter, agentRegistry, messaging, ctx, logger)
+	go startPersonaMessaging(nativeAgent, 15*time.Second, []string{"sales", "support", "inventory", "fraud"}, agentRegistry, messaging, ctx, logger)
+	go startPersonaMessaging(openaiAdapter.GetAgent(), 18*time.Second, []string{"sales", "support"}, agentRegistry, messaging, ctx, logger)
+	go startPersonaMessaging(langchainAdapter.GetAgent(), 20*time.Second, nil, agentRegistry, messaging, ctx, logger)
 
 	// Keep running until interrupted
 	logger.Info("Press Ctrl+C to stop...")
@@ -383,11 +387,9 @@ func createNativeAgent(messaging *messaging.KafkaMessaging, cfg *types.Config, l
 		Name:         "Native Coordinator",
 		Role:         "coordinator",
 		Status:       types.AgentStatusActive,
-		Capabilities: []string{"coordination", "synthesis", "decision_making"},
-		Metadata: map[string]string{
-			"framework": "agentmesh_native",
-			"language":  "go",
-		},
+		Capabilities: types.ParseCapabilities([]string{"coordination", "synthesis", "decision_making"}),
+		Framework:    types.FrameworkNative,
+		Language:     "go",
 		CreatedAt:  time.Now(),
 		LastSeenAt: time.Now(),
 	}
@@ -409,56 +411,17 @@ func createNativeAgent(messaging *messaging.KafkaMessaging, cfg *types.Config, l
 	return agent
 }
 
-// startNativeCoordinatorMessaging starts periodic messaging for the native coordinator
-func startNativeCoordinatorMessaging(agent *types.Agent, registry *AgentRegistry, messaging *messaging.KafkaMessaging, ctx context.Context, logger *zap.Logger) {
-	ticker := time.NewTicker(15 * time.Second)
-	defer ticker.Stop()
-
-	counter := 0
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			counter++
-
-			// Coordinator broadcasts status updates every 15 seconds
-			// Rotate between target agents
-			targets := []string{"sales", "support", "inventory", "fraud"}
-			targetRole := targets[counter%len(targets)]
-
-			// CRITICAL: Resolve role to actual agent ID
-			targetAgentID := registry.GetAgentByRole(targetRole)
-			if targetAgentID == "" {
-				logger.Debug("Coordinator cannot find agent for role", zap.String("role", targetRole))
-				continue
-			}
-
-			msg := &types.Message{
-				ID:          fmt.Sprintf("%s-%d", agent.ID, time.Now().UnixNano()),
-				FromAgentID: agent.ID,
-				ToAgentID:   targetAgentID, // Use resolved agent ID
-				Type:        types.MessageTypeTask,
-				Payload: map[string]any{
-					"action":      "coordination_update",
-					"status":      "coordinating",
-					"description": fmt.Sprintf("Coordinator status check #%d - All agents operational", counter),
-				},
-				Timestamp: time.Now(),
-			}
-
-			messaging.PublishMessage(ctx, "messages", msg)
-			logger.Debug("Coordinator sent message",
-				zap.String("target_role", targetRole),
-				zap.String("target_agent_id", string(targetAgentID)),
-			)
-		}
+// startPersonaMessaging sends the messages of the agent's role persona every
+// interval, optionally limited to the given target roles
+func startPersonaMessaging(agent *types.Agent, interval time.Duration, targets []string, registry *AgentRegistry, messaging *messaging.KafkaMessaging, ctx context.Context, logger *zap.Logger) {
+	persona, ok := personas.Get(agent.Role)
+	if !ok {
+		logger.Warn("No persona for role", zap.String("role", agent.Role))
+		return
 	}
-}
+	persona.Params.Targets = targets
 
-// startOpenAIAgentMessaging starts periodic messaging for the OpenAI research agent
-func startOpenAIAgentMessaging(adapter *adapters.OpenAIAdapter, registry *AgentRegistry, messaging *messaging.KafkaMessaging, ctx context.Context, logger *zap.Logger) {
-	ticker := time.NewTicker(18 * time.Second)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	counter := 0
@@ -469,79 +432,33 @@ func startOpenAIAgentMessaging(adapter *adapters.OpenAIAdapter, registry *AgentR
 		case <-ticker.C:
 			counter++
 
-			// Research agent requests data and shares findings
-			targets := []string{"sales", "support"}
-			targetRole := targets[counter%len(targets)]
-
-			// CRITICAL: Resolve role to actual agent ID
-			targetAgentID := registry.GetAgentByRole(targetRole)
-			if targetAgentID == "" {
-				logger.Debug("OpenAI agent cannot find agent for role", zap.String("role", targetRole))
+			action, ok := persona.Compose(counter)
+			if !ok {
 				continue
 			}
 
-			msg := &types.Message{
-				ID:          fmt.Sprintf("%s-%d", adapter.GetAgent().ID, time.Now().UnixNano()),
-				FromAgentID: adapter.GetAgent().ID,
-				ToAgentID:   targetAgentID, // Use resolved agent ID
-				Type:        types.MessageTypeTask,
-				Payload: map[string]any{
-					"action":      "research_request",
-					"topic":       fmt.Sprintf("market_trend_%d", counter),
-					"description": fmt.Sprintf("OpenAI Research: Requesting %s data for analysis #%d", targetRole, counter),
-				},
-				Timestamp: time.Now(),
-			}
-
-			messaging.PublishMessage(ctx, "messages", msg)
-			logger.Debug("OpenAI agent sent message",
-				zap.String("target_role", targetRole),
-				zap.String("target_agent_id", string(targetAgentID)),
-			)
-		}
-	}
-}
-
-// startLangChainAgentMessaging starts periodic messaging for the LangChain analyst agent
-func startLangChainAgentMessaging(adapter *adapters.LangChainAdapter, registry *AgentRegistry, messaging *messaging.KafkaMessaging, ctx context.Context, logger *zap.Logger) {
-	ticker := time.NewTicker(20 * time.Second)
-	defer ticker.Stop()
-
-	counter := 0
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			counter++
-
-			// Analyst agent provides insights to other agents
-			targets := []string{"sales", "inventory", "fraud"}
-			targetRole := targets[counter%len(targets)]
-
 			// CRITICAL: Resolve role to actual agent ID
-			targetAgentID := registry.GetAgentByRole(targetRole)
+			targetAgentID := registry.GetAgentByRole(action.TargetRole)
 			if targetAgentID == "" {
-				logger.Debug("LangChain agent cannot find agent for role", zap.String("role", targetRole))
+				logger.Debug("Agent cannot find agent for role",
+					zap.String("agent", agent.Name),
+					zap.String("role", action.TargetRole))
 				continue
 			}
 
 			msg := &types.Message{
-				ID:          fmt.Sprintf("%s-%d", adapter.GetAgent().ID, time.Now().UnixNano()),
-				FromAgentID: adapter.GetAgent().ID,
+				ID:          fmt.Sprintf("%s-%d", agent.ID, time.Now().UnixNano()),
+				FromAgentID: agent.ID,
 				ToAgentID:   targetAgentID, // Use resolved agent ID
-				Type:        types.MessageTypeTask,
-				Payload: map[string]any{
-					"action":      "analysis_report",
-					"metric":      fmt.Sprintf("kpi_%d", counter),
-					"description": fmt.Sprintf("LangChain Analyst: Sharing analysis report #%d with %s", counter, targetRole),
-				},
-				Timestamp: time.Now(),
+				Type:        action.Type,
+				Payload:     action.Payload,
+				Timestamp:   time.Now(),
 			}
 
 			messaging.PublishMessage(ctx, "messages", msg)
-			logger.Debug("LangChain agent sent message",
-				zap.String("target_role", targetRole),
+			logger.Debug("Agent sent message",
+				zap.String("agent", agent.Name),
+				zap.String("target_role", action.TargetRole),
 				zap.String("target_agent_id", string(targetAgentID)),
 			)
 		}
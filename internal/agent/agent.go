@@ -3,12 +3,14 @@ package agent
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
 	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/metrics"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 	"go.uber.org/zap"
 )
@@ -21,16 +23,34 @@ type AgentRuntime struct {
 	messaging *messaging.KafkaMessaging
 	logger    *zap.Logger
 	config    *types.Config
-
-	handlers map[types.MessageType]MessageHandler
-	mu       sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
+	spool     *Spool          // Nil unless SpoolDir is configured
+	cache     *KnowledgeCache // Nil unless EnableKnowledgeCache was called
+	workers   int             // Conversations handled at once, see EnableConversationWorkers
+	processed processedLog    // Recently processed messages, for the debug console
+	dedup     *dedupWindow    // IDs of handled messages; nil without AGENT_DEDUP_WINDOW
+	standby   *Standby        // Nil unless EnableStandby was called
+	reporter  *metrics.Reporter
+	lifecycle types.AgentLifecycle
+
+	conversations *ConversationStore // Recent messages of its own and the primary's conversations
+
+	handlers   map[types.MessageType]MessageHandler
+	middleware []Middleware // Around every handler, inside Recover
+	mu         sync.RWMutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 }
 
-// MessageHandler is a function that handles incoming messages
-type MessageHandler func(msg *types.Message) error
+// spoolingReason is the degraded reason while messages wait in the spool
+const spoolingReason = "broker unreachable, spooling messages"
+
+// lifecycleTimeout bounds publishing the stopped event after the runtime's context is done
+const lifecycleTimeout = 5 * time.Second
+
+// MessageHandler is a function that handles incoming messages. The context
+// ends after CONSUMER_HANDLER_TIMEOUT, if set, or when the runtime stops.
+type MessageHandler func(ctx context.Context, msg *types.Message) error
 
 // NewAgentRuntime creates a new agent runtime
 func NewAgentRuntime(
@@ -43,7 +63,7 @@ func NewAgentRuntime(
 ) *AgentRuntime {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &AgentRuntime{
+	ar := &AgentRuntime{
 		agent:     agent,
 		topology:  topology,
 		consensus: consensus,
@@ -51,9 +71,26 @@ func NewAgentRuntime(
 		config:    config,
 		logger:    logger.With(zap.String("agent_id", string(agent.ID)), zap.String("agent_name", agent.Name)),
 		handlers:  make(map[types.MessageType]MessageHandler),
+		dedup:     newDedupWindow(config.AgentDedupWindow),
 		ctx:       ctx,
 		cancel:    cancel,
+
+		conversations: NewConversationStore(config.ConversationStoreSize),
+	}
+
+	if config.AgentDryRun && messaging != nil {
+		messaging.EnableDryRun()
 	}
+	// A dry run never fails to publish, and must not drain the spool of the live agent
+	if config.SpoolDir != "" && !config.AgentDryRun {
+		spool, err := OpenSpool(filepath.Join(config.SpoolDir, string(agent.ID)), config.SpoolMaxMessages, config.SpoolMaxBytes)
+		if err != nil {
+			ar.logger.Warn("Spool disabled", zap.Error(err))
+		} else {
+			ar.spool = spool
+		}
+	}
+	return ar
 }
 
 // RegisterHandler registers a message handler for a message type
@@ -63,35 +100,62 @@ func (ar *AgentRuntime) RegisterHandler(msgType types.MessageType, handler Messa
 	ar.handlers[msgType] = handler
 }
 
+// SetReporter reports the runtime's own metrics, e.g. suppressed duplicate
+// messages, to Prometheus; call before Start
+func (ar *AgentRuntime) SetReporter(reporter *metrics.Reporter) {
+	ar.reporter = reporter
+}
+
 // Start starts the agent runtime
 func (ar *AgentRuntime) Start() error {
 	ar.logger.Info("Starting agent runtime",
 		zap.String("role", ar.agent.Role),
-		zap.Strings("capabilities", ar.agent.Capabilities),
+		zap.Strings("capabilities", ar.agent.CapabilityNames()),
 	)
 
+	ar.reportLifecycle(types.LifecycleStarting, "")
+
 	// Register agent in consensus
 	ar.consensus.RegisterAgent(ar.agent.ID)
 
 	// Add agent to topology
 	if err := ar.topology.AddAgent(ar.agent); err != nil {
+		ar.reportLifecycle(types.LifecycleStopped, err.Error())
 		return fmt.Errorf("failed to add agent to topology: %w", err)
 	}
 
 	// Start message consumers
-	ar.wg.Add(3)
+	ar.wg.Add(4)
 	go ar.consumeMessages()
+	go ar.consumePushedInsights()
 	go ar.consumeProposals()
 	go ar.sendHeartbeats()
 
+	if ar.spool != nil {
+		ar.wg.Add(1)
+		go ar.flushSpool()
+	}
+	if ar.cache != nil {
+		ar.wg.Add(1)
+		go ar.syncKnowledgeCache()
+	}
+	if ar.standby != nil {
+		ar.wg.Add(2)
+		go ar.watchPrimaryHeartbeats()
+		go ar.checkPrimary()
+	}
+
+	ar.reportLifecycle(types.LifecycleReady, "")
 	return nil
 }
 
 // Stop stops the agent runtime
 func (ar *AgentRuntime) Stop() error {
 	ar.logger.Info("Stopping agent runtime")
+	ar.reportLifecycle(types.LifecycleDraining, "")
 	ar.cancel()
 	ar.wg.Wait()
+	ar.reportLifecycle(types.LifecycleStopped, "")
 
 	// Unregister from consensus
 	ar.consensus.UnregisterAgent(ar.agent.ID)
@@ -106,24 +170,32 @@ func (ar *AgentRuntime) Stop() error {
 
 // SendMessage sends a message to another agent
 func (ar *AgentRuntime) SendMessage(toAgentID types.AgentID, msgType types.MessageType, payload map[string]any) error {
+	return ar.SendInConversation("", toAgentID, msgType, payload)
+}
+
+// SendInConversation sends a message to another agent as part of a
+// conversation, which receivers with conversation workers handle in order
+func (ar *AgentRuntime) SendInConversation(conversationID string, toAgentID types.AgentID, msgType types.MessageType, payload map[string]any) error {
 	message := &types.Message{
-		ID:          fmt.Sprintf("%s-%d", ar.agent.ID, time.Now().UnixNano()),
-		FromAgentID: ar.agent.ID,
-		ToAgentID:   toAgentID,
-		Type:        msgType,
-		Payload:     payload,
-		Metadata:    map[string]string{"agent_role": ar.agent.Role},
-		Timestamp:   time.Now(),
-		EdgeID:      types.NewEdgeID(ar.agent.ID, toAgentID),
+		ID:             fmt.Sprintf("%s-%d", ar.agent.ID, time.Now().UnixNano()),
+		FromAgentID:    ar.agent.ID,
+		ToAgentID:      toAgentID,
+		ConversationID: conversationID,
+		Type:           msgType,
+		Payload:        payload,
+		Metadata:       map[string]string{"agent_role": ar.agent.Role},
+		Timestamp:      time.Now(),
+		EdgeID:         types.NewEdgeID(ar.agent.ID, toAgentID),
 	}
 
 	// Publish message to Kafka
-	if err := ar.messaging.PublishMessage(ar.ctx, "messages", message); err != nil {
+	if err := ar.publish("messages", message); err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
+	ar.conversations.Record(message)
 
 	// Reinforce edge in topology
-	if err := ar.topology.ReinforceEdge(ar.agent.ID, toAgentID); err != nil {
+	if err := ar.topology.ReinforceEdge(ar.agent.ID, toAgentID, msgType); err != nil {
 		ar.logger.Warn("Failed to reinforce edge", zap.Error(err))
 	}
 
@@ -135,6 +207,140 @@ func (ar *AgentRuntime) SendMessage(toAgentID types.AgentID, msgType types.Messa
 	return nil
 }
 
+// SendTask sends a typed task action, e.g. types.CheckStock, to another agent
+func (ar *AgentRuntime) SendTask(toAgentID types.AgentID, action types.TaskAction) error {
+	payload, err := types.EncodeTaskAction(action)
+	if err != nil {
+		return err
+	}
+	return ar.SendMessage(toAgentID, types.MessageTypeTask, payload)
+}
+
+// ReportGoalProgress publishes a measurement towards a mesh goal as a typed insight
+func (ar *AgentRuntime) ReportGoalProgress(goalID types.GoalID, value float64) error {
+	insight := types.NewGoalProgressInsight(ar.agent.ID, ar.agent.Role, goalID, value)
+	if err := ar.publish("insights", messaging.InsightMessage(insight)); err != nil {
+		return fmt.Errorf("failed to publish goal progress: %w", err)
+	}
+	return nil
+}
+
+// ReportDecisionOutcome publishes a measurement of an accepted proposal's effect as a typed insight
+func (ar *AgentRuntime) ReportDecisionOutcome(proposalID types.ProposalID, metric string, value float64) error {
+	insight := types.NewDecisionOutcomeInsight(ar.agent.ID, ar.agent.Role, proposalID, metric, value)
+	if err := ar.publish("insights", messaging.InsightMessage(insight)); err != nil {
+		return fmt.Errorf("failed to publish decision outcome: %w", err)
+	}
+	return nil
+}
+
+// PublishInsight publishes an insight, spooling it if the broker is unreachable
+func (ar *AgentRuntime) PublishInsight(insight *types.Insight) error {
+	if err := ar.publish("insights", messaging.InsightMessage(insight)); err != nil {
+		return fmt.Errorf("failed to publish insight: %w", err)
+	}
+	return nil
+}
+
+// EnableKnowledgeCache keeps the insights matching filter that this agent may
+// read in a local cache, synced from the mesh once the runtime starts; call before Start
+func (ar *AgentRuntime) EnableKnowledgeCache(filter types.KnowledgeQuery) *KnowledgeCache {
+	ar.cache = NewKnowledgeCache(filter, ar.agent, ar.config)
+	return ar.cache
+}
+
+// EnableConversationWorkers handles direct messages on up to workers
+// goroutines instead of one at a time. Messages of the same conversation are
+// still handled one at a time in the order received, and so are messages of
+// the same sender outside a conversation; handlers must be safe to run for
+// different conversations concurrently. Call before Start.
+func (ar *AgentRuntime) EnableConversationWorkers(workers int) {
+	ar.workers = workers
+}
+
+// ReportLifecycle publishes a lifecycle state change on the lifecycle topic,
+// e.g. degraded with a reason while a dependency is down, then ready again.
+// The runtime reports starting, ready, draining and stopped itself, and
+// degraded while the broker is unreachable and messages are spooled.
+func (ar *AgentRuntime) ReportLifecycle(state types.LifecycleState, reason string) error {
+	if _, err := types.ParseLifecycleState(string(state)); err != nil {
+		return err
+	}
+
+	ar.mu.Lock()
+	previous := ar.lifecycle.State
+	ar.lifecycle = types.AgentLifecycle{State: state, Reason: reason, Since: time.Now()}
+	lifecycle := ar.lifecycle
+	ar.agent.Lifecycle = &lifecycle
+	ar.mu.Unlock()
+
+	event := &types.LifecycleEvent{
+		AgentID:   ar.agent.ID,
+		State:     state,
+		Previous:  previous,
+		Reason:    reason,
+		Timestamp: lifecycle.Since,
+	}
+	if ar.ctx.Err() != nil {
+		// Stopped: the spool is no longer flushed, so publish directly
+		ctx, cancel := context.WithTimeout(context.Background(), lifecycleTimeout)
+		defer cancel()
+		if err := ar.messaging.PublishLifecycleEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to publish lifecycle event: %w", err)
+		}
+		return nil
+	}
+	if err := ar.publish("lifecycle", event.Message()); err != nil {
+		return fmt.Errorf("failed to publish lifecycle event: %w", err)
+	}
+	return nil
+}
+
+// Lifecycle returns the lifecycle state the agent last reported
+func (ar *AgentRuntime) Lifecycle() types.AgentLifecycle {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	return ar.lifecycle
+}
+
+// reportLifecycle reports a state change the runtime detected itself, logging failures
+func (ar *AgentRuntime) reportLifecycle(state types.LifecycleState, reason string) {
+	if err := ar.ReportLifecycle(state, reason); err != nil {
+		ar.logger.Warn("Failed to report lifecycle state", zap.String("state", string(state)), zap.Error(err))
+	}
+}
+
+// SpoolStats returns the state of the store-and-forward spool, or false if it is disabled
+func (ar *AgentRuntime) SpoolStats() (SpoolStats, bool) {
+	if ar.spool == nil {
+		return SpoolStats{}, false
+	}
+	return ar.spool.Stats(), true
+}
+
+// publish publishes a message to Kafka. If the broker is unreachable and a
+// spool is configured, the message is queued on disk and sent by flushSpool;
+// messages that can never be published are not spooled.
+func (ar *AgentRuntime) publish(topic string, message *types.Message) error {
+	err := ar.messaging.PublishMessage(ar.ctx, topic, message)
+	if err == nil || ar.spool == nil || ar.ctx.Err() != nil || messaging.Permanent(err) {
+		return err
+	}
+	if spoolErr := ar.spool.Enqueue(topic, message); spoolErr != nil {
+		return fmt.Errorf("%w (spool: %v)", err, spoolErr)
+	}
+
+	ar.logger.Warn("Broker unreachable, spooled message",
+		zap.String("topic", topic),
+		zap.String("message_id", message.ID),
+		zap.Error(err),
+	)
+	if ar.Lifecycle().State == types.LifecycleReady {
+		ar.reportLifecycle(types.LifecycleDegraded, spoolingReason)
+	}
+	return nil
+}
+
 // ProposeAction creates a new proposal for consensus
 func (ar *AgentRuntime) ProposeAction(proposalType types.ProposalType, content map[string]any) (*types.Proposal, error) {
 	proposal, err := ar.consensus.CreateProposal(ar.agent.ID, proposalType, content)
@@ -143,7 +349,7 @@ func (ar *AgentRuntime) ProposeAction(proposalType types.ProposalType, content m
 	}
 
 	// Publish proposal to Kafka
-	if err := ar.messaging.PublishProposal(ar.ctx, proposal); err != nil {
+	if err := ar.messaging.PublishProposal(ar.ctx, proposal, messaging.ProposalOptions{}); err != nil {
 		ar.logger.Error("Failed to publish proposal", zap.Error(err))
 	}
 
@@ -155,9 +361,115 @@ func (ar *AgentRuntime) ProposeAction(proposalType types.ProposalType, content m
 	return proposal, nil
 }
 
+// ProposeFromTemplate asks the consensus manager to create a proposal from a
+// registered or built-in template, e.g. "large_order_approval"
+func (ar *AgentRuntime) ProposeFromTemplate(template string, params map[string]any) error {
+	if err := ar.messaging.PublishProposal(ar.ctx, &types.Proposal{ProposerID: ar.agent.ID}, messaging.ProposalOptions{Template: template, Params: params}); err != nil {
+		return fmt.Errorf("failed to publish templated proposal: %w", err)
+	}
+
+	ar.logger.Info("Proposed from template", zap.String("template", template))
+	return nil
+}
+
+// ProposeInWindow asks the consensus manager to create a proposal only valid
+// within a window, e.g. a discount during a flash sale. Accepted, it executes
+// when the window opens; without quorum by the window's end, it expires.
+func (ar *AgentRuntime) ProposeInWindow(proposalType types.ProposalType, content map[string]any, window types.ProposalWindow) error {
+	if !window.End.After(window.Start) || window.Closed(time.Now()) {
+		return fmt.Errorf("window %s to %s is empty or closed", window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339))
+	}
+	if err := ar.messaging.PublishProposal(ar.ctx, ar.proposal(proposalType, content), messaging.ProposalOptions{Window: &window}); err != nil {
+		return fmt.Errorf("failed to publish windowed proposal: %w", err)
+	}
+
+	ar.logger.Info("Proposed in window",
+		zap.String("type", string(proposalType)),
+		zap.Time("start", window.Start),
+		zap.Time("end", window.End),
+	)
+	return nil
+}
+
+// ProposePolicy asks the consensus manager to create a proposal for a standing
+// policy, e.g. a discount cap. Once accepted it remains in force until another
+// proposal for the same policy passes.
+func (ar *AgentRuntime) ProposePolicy(policy string, proposalType types.ProposalType, content map[string]any) error {
+	if !types.ValidPolicyName(policy) {
+		return fmt.Errorf("invalid policy name %q", policy)
+	}
+	if err := ar.messaging.PublishProposal(ar.ctx, ar.proposal(proposalType, content), messaging.ProposalOptions{Policy: policy}); err != nil {
+		return fmt.Errorf("failed to publish policy proposal: %w", err)
+	}
+
+	ar.logger.Info("Proposed policy", zap.String("policy", policy), zap.String("type", string(proposalType)))
+	return nil
+}
+
+// ProposeAfter asks the consensus manager to create a proposal that depends on
+// others, e.g. a price change on the budget for it. It is only accepted once
+// they all are, and expires if one of them is rejected or expires for good.
+func (ar *AgentRuntime) ProposeAfter(dependsOn []types.ProposalID, proposalType types.ProposalType, content map[string]any) error {
+	if len(dependsOn) == 0 || len(dependsOn) > types.MaxProposalDependencies {
+		return fmt.Errorf("a dependent proposal needs 1 to %d prerequisites", types.MaxProposalDependencies)
+	}
+	if err := ar.messaging.PublishProposal(ar.ctx, ar.proposal(proposalType, content), messaging.ProposalOptions{DependsOn: dependsOn}); err != nil {
+		return fmt.Errorf("failed to publish dependent proposal: %w", err)
+	}
+
+	ar.logger.Info("Proposed after prerequisites", zap.String("type", string(proposalType)), zap.Int("prerequisites", len(dependsOn)))
+	return nil
+}
+
+// proposal returns the proposal of the agent a request publishes
+func (ar *AgentRuntime) proposal(proposalType types.ProposalType, content map[string]any) *types.Proposal {
+	return &types.Proposal{ProposerID: ar.agent.ID, Type: proposalType, Content: content}
+}
+
+// ProposeBundle asks the consensus manager to create a bundle of related
+// proposals voted on as a slate, e.g. coordinated price changes across regions
+func (ar *AgentRuntime) ProposeBundle(title string, rule types.BundleRule, bestOf int, proposals ...map[string]any) error {
+	if err := types.ValidateBundle(rule, bestOf, len(proposals)); err != nil {
+		return err
+	}
+	if err := ar.messaging.PublishBundle(ar.ctx, ar.agent.ID, title, rule, bestOf, proposals); err != nil {
+		return fmt.Errorf("failed to publish bundle: %w", err)
+	}
+
+	ar.logger.Info("Proposed bundle", zap.String("title", title), zap.Int("proposals", len(proposals)))
+	return nil
+}
+
+// VoteOnBundle casts one vote on every proposal of a bundle
+func (ar *AgentRuntime) VoteOnBundle(bundleID types.BundleID, support bool, intensity float64, rationale string) error {
+	err := ar.consensus.VoteBundle(bundleID, &consensus.VoteRequest{
+		VoterID:   ar.agent.ID,
+		Support:   support,
+		Intensity: intensity,
+		Rationale: rationale,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to vote on bundle: %w", err)
+	}
+	return nil
+}
+
 // VoteOnProposal votes on a proposal
 func (ar *AgentRuntime) VoteOnProposal(proposalID types.ProposalID, support bool, intensity float64) error {
-	if err := ar.consensus.Vote(proposalID, ar.agent.ID, support, intensity); err != nil {
+	return ar.VoteWithRationale(proposalID, support, intensity, "")
+}
+
+// VoteWithRationale votes on a proposal and records why
+func (ar *AgentRuntime) VoteWithRationale(proposalID types.ProposalID, support bool, intensity float64, rationale string, factors ...types.VoteFactor) error {
+	err := ar.consensus.CastVote(&consensus.VoteRequest{
+		ProposalID: proposalID,
+		VoterID:    ar.agent.ID,
+		Support:    support,
+		Intensity:  intensity,
+		Rationale:  rationale,
+		Factors:    factors,
+	})
+	if err != nil {
 		return fmt.Errorf("failed to vote: %w", err)
 	}
 
@@ -170,31 +482,179 @@ func (ar *AgentRuntime) VoteOnProposal(proposalID types.ProposalID, support bool
 	return nil
 }
 
+// VoteWithStake votes on a proposal and commits stake points to the vote,
+// rewarded if the decision's impact proves it right and slashed otherwise
+func (ar *AgentRuntime) VoteWithStake(proposalID types.ProposalID, support bool, intensity, stake float64, rationale string) error {
+	err := ar.consensus.CastVote(&consensus.VoteRequest{
+		ProposalID: proposalID,
+		VoterID:    ar.agent.ID,
+		Support:    support,
+		Intensity:  intensity,
+		Rationale:  rationale,
+		Stake:      stake,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to vote: %w", err)
+	}
+	return nil
+}
+
+// Abstain takes part in a proposal's vote without supporting or rejecting it
+func (ar *AgentRuntime) Abstain(proposalID types.ProposalID, rationale string) error {
+	err := ar.consensus.CastVote(&consensus.VoteRequest{
+		ProposalID: proposalID,
+		VoterID:    ar.agent.ID,
+		Choice:     types.VoteChoiceAbstain,
+		Rationale:  rationale,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abstain: %w", err)
+	}
+	return nil
+}
+
+// VoteConditionally supports a proposal only if the condition holds for its content
+func (ar *AgentRuntime) VoteConditionally(proposalID types.ProposalID, condition types.VoteCondition, intensity float64, rationale string) error {
+	err := ar.consensus.CastVote(&consensus.VoteRequest{
+		ProposalID: proposalID,
+		VoterID:    ar.agent.ID,
+		Intensity:  intensity,
+		Rationale:  rationale,
+		Choice:     types.VoteChoiceConditional,
+		Condition:  &condition,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to vote: %w", err)
+	}
+	return nil
+}
+
 // consumeMessages consumes messages from Kafka
 func (ar *AgentRuntime) consumeMessages() {
 	defer ar.wg.Done()
 
+	var queues *ConversationQueues
+	if ar.workers > 1 {
+		// Queued messages outlive the consumer's handler call, so their
+		// handlers get a context of their own
+		queues = NewConversationQueues(ar.workers, func(msg *types.Message) {
+			ctx, cancel := ar.handlerContext()
+			defer cancel()
+			if err := ar.dispatch(ctx, "messages", msg, msg.Type); err != nil {
+				ar.logger.Error("Failed to handle message", zap.Error(err), zap.String("message_id", msg.ID))
+			}
+		})
+		// Messages already read are handled before the runtime stops
+		defer queues.Wait()
+	}
+
 	groupID := fmt.Sprintf("agent-%s", ar.agent.ID)
-	err := ar.messaging.ConsumeMessages(ar.ctx, "messages", groupID, func(msg *types.Message) error {
-		// Only process messages addressed to this agent
-		if msg.ToAgentID != ar.agent.ID {
+	err := ar.messaging.ConsumeMessages(ar.ctx, "messages", groupID, func(ctx context.Context, msg *types.Message) error {
+		// Only process messages addressed to this agent, or to the primary it
+		// stands by for once it took over; the primary's are mirrored
+		switch {
+		case msg.ToAgentID == ar.agent.ID:
+			ar.conversations.Record(msg)
+		case ar.standby != nil && msg.ToAgentID == ar.standby.Primary():
+			ar.conversations.Record(msg)
+			if !ar.standby.Receive(msg) {
+				return nil
+			}
+		case ar.standby != nil && msg.FromAgentID == ar.standby.Primary():
+			ar.conversations.Record(msg)
+			return nil
+		default:
+			return nil
+		}
+
+		if queues != nil {
+			queues.Submit(msg)
 			return nil
 		}
+		return ar.dispatch(ctx, "messages", msg, msg.Type)
+	})
+
+	if err != nil && err != context.Canceled {
+		ar.logger.Error("Message consumption stopped", zap.Error(err))
+	}
+}
+
+// handlerContext returns a handler context ending after
+// CONSUMER_HANDLER_TIMEOUT, if set, or when the runtime stops
+func (ar *AgentRuntime) handlerContext() (context.Context, context.CancelFunc) {
+	if ar.config.ConsumerHandlerTimeout > 0 {
+		return context.WithTimeout(ar.ctx, ar.config.ConsumerHandlerTimeout)
+	}
+	return context.WithCancel(ar.ctx)
+}
 
-		ar.mu.RLock()
-		handler, exists := ar.handlers[msg.Type]
-		ar.mu.RUnlock()
+// consumePushedInsights passes insights pushed by the knowledge manager to the
+// MessageTypeInsightPush handler, if one is registered. Insights whose type
+// requires verification are only passed on once verified.
+func (ar *AgentRuntime) consumePushedInsights() {
+	defer ar.wg.Done()
 
-		if exists {
-			return handler(msg)
+	groupID := fmt.Sprintf("agent-%s-push", ar.agent.ID)
+	err := ar.messaging.ConsumeMessages(ar.ctx, "insights-push", groupID, func(ctx context.Context, msg *types.Message) error {
+		if msg.FromAgentID == ar.agent.ID {
+			return nil // Own insight
+		}
+		if !types.PushedTo(msg, ar.agent.Role) {
+			ar.record("insights-push", msg, OutcomeNotShared, nil, 0)
+			return nil
+		}
+		insight, err := types.PushedInsight(msg)
+		if err != nil {
+			ar.record("insights-push", msg, OutcomeBadPayload, err, 0)
+			return nil
+		}
+		// Pushes carry the knowledge manager's version, e.g. once verified
+		if ar.cache != nil {
+			ar.cache.Put(insight, true)
+		}
+		if !insight.Actionable(ar.config) {
+			ar.logger.Debug("Ignoring unverified pushed insight", zap.String("message_id", msg.ID))
+			ar.record("insights-push", msg, OutcomeUnverified, nil, 0)
+			return nil
 		}
 
-		ar.logger.Debug("No handler for message type", zap.String("type", string(msg.Type)))
+		return ar.dispatch(ctx, "insights-push", msg, types.MessageTypeInsightPush)
+	})
+
+	if err != nil && err != context.Canceled {
+		ar.logger.Error("Pushed insight consumption stopped", zap.Error(err))
+	}
+}
+
+// syncKnowledgeCache loads the current knowledge from the compacted insight
+// topic, then follows the insight topic from its earliest retained offset so no
+// insight published before the compacted topic caught up is missed
+func (ar *AgentRuntime) syncKnowledgeCache() {
+	defer ar.wg.Done()
+
+	err := ar.messaging.ReadCompactedInsights(ar.ctx, func(insight *types.Insight) error {
+		ar.cache.Put(insight, true)
+		return nil
+	})
+	if err != nil {
+		ar.logger.Warn("Failed to load compacted insights into knowledge cache", zap.Error(err))
+	}
+
+	groupID := fmt.Sprintf("agent-%s-cache", ar.agent.ID)
+	if _, err := ar.messaging.SeekGroup(ar.ctx, groupID, []string{"insights"}, types.StartModeEarliest); err != nil {
+		ar.logger.Warn("Failed to rewind knowledge cache consumer", zap.Error(err))
+	}
+	ar.logger.Info("Knowledge cache loaded", zap.Int("insights", ar.cache.Len()))
+
+	err = ar.messaging.ConsumeMessages(ar.ctx, "insights", groupID, func(ctx context.Context, msg *types.Message) error {
+		if insight, err := types.PushedInsight(msg); err == nil {
+			ar.cache.Put(insight, false)
+		}
 		return nil
 	})
 
 	if err != nil && err != context.Canceled {
-		ar.logger.Error("Message consumption stopped", zap.Error(err))
+		ar.logger.Error("Knowledge cache sync stopped", zap.Error(err))
 	}
 }
 
@@ -203,7 +663,7 @@ func (ar *AgentRuntime) consumeProposals() {
 	defer ar.wg.Done()
 
 	groupID := "proposals-all"
-	err := ar.messaging.ConsumeMessages(ar.ctx, "proposals", groupID, func(msg *types.Message) error {
+	err := ar.messaging.ConsumeMessages(ar.ctx, "proposals", groupID, func(ctx context.Context, msg *types.Message) error {
 		// Proposals are broadcast to all agents - evaluate and vote
 		return ar.evaluateProposal(msg)
 	})
@@ -239,15 +699,24 @@ func (ar *AgentRuntime) evaluateProposal(msg *types.Message) error {
 	voteIntensity := waggle.Intensity
 
 	// Cast vote
-	proposalID := types.ProposalID(msg.Payload["proposal_id"].(string))
-	return ar.VoteOnProposal(proposalID, support, voteIntensity)
+	proposalID, ok := msg.Payload["proposal_id"].(string)
+	if !ok {
+		return nil
+	}
+	rationale := fmt.Sprintf("waggle intensity %.2f against minimum %.2f", waggle.Intensity, ar.config.WaggleIntensityMin)
+	return ar.VoteWithRationale(types.ProposalID(proposalID), support, voteIntensity, rationale, types.VoteFactor{
+		Name:   "waggle_intensity",
+		Weight: waggle.Intensity - ar.config.WaggleIntensityMin,
+		Value:  waggle.Intensity,
+	})
 }
 
-// sendHeartbeats sends periodic heartbeats
+// sendHeartbeats publishes a heartbeat every HeartbeatInterval, by which the
+// topology manager tells the agent is alive
 func (ar *AgentRuntime) sendHeartbeats() {
 	defer ar.wg.Done()
 
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(ar.config.HeartbeatInterval)
 	defer ticker.Stop()
 
 	for {
@@ -258,11 +727,55 @@ func (ar *AgentRuntime) sendHeartbeats() {
 			ar.agent.LastSeenAt = time.Now()
 			ar.agent.Status = types.AgentStatusActive
 
+			if err := ar.messaging.PublishHeartbeat(ar.ctx, ar.agent.ID); err != nil {
+				ar.logger.Debug("Failed to send heartbeat", zap.Error(err))
+				continue
+			}
 			ar.logger.Debug("Heartbeat sent")
 		}
 	}
 }
 
+// flushSpool periodically sends the spooled messages once the broker is reachable
+func (ar *AgentRuntime) flushSpool() {
+	defer ar.wg.Done()
+
+	ticker := time.NewTicker(ar.config.SpoolFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ar.ctx.Done():
+			return
+		case <-ticker.C:
+			if ar.spool.Stats().Queued == 0 {
+				continue
+			}
+			rejected := ar.spool.Stats().Rejected
+			flushed, err := ar.spool.Flush(ar.ctx, ar.messaging.PublishMessage)
+			stats := ar.spool.Stats()
+			if stats.Rejected > rejected {
+				ar.logger.Warn("Dropped spooled messages the broker rejected",
+					zap.Int64("rejected", stats.Rejected-rejected),
+					zap.Int("queued", stats.Queued),
+				)
+			}
+			if flushed > 0 {
+				ar.logger.Info("Flushed spooled messages",
+					zap.Int("flushed", flushed),
+					zap.Int("queued", stats.Queued),
+					zap.Int64("dropped", stats.Dropped),
+				)
+			}
+			if err != nil {
+				ar.logger.Debug("Broker still unreachable", zap.Int("queued", stats.Queued), zap.Error(err))
+			} else if lifecycle := ar.Lifecycle(); stats.Queued == 0 && lifecycle.State == types.LifecycleDegraded && lifecycle.Reason == spoolingReason {
+				ar.reportLifecycle(types.LifecycleReady, "")
+			}
+		}
+	}
+}
+
 // GetAgent returns the agent instance
 func (ar *AgentRuntime) GetAgent() *types.Agent {
 	return ar.agent
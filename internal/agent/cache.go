@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// KnowledgeCache keeps the insights relevant to an agent in memory so its
+// business logic can query them locally instead of calling the REST API.
+// The runtime keeps it in sync with the mesh; see EnableKnowledgeCache.
+type KnowledgeCache struct {
+	filter  types.KnowledgeQuery // Which insights are cached
+	viewer  *types.Agent         // Only insights visible to this agent are cached
+	config  *types.Config
+	maxSize int // Oldest insights are evicted beyond it (0 = unlimited)
+
+	mu       sync.RWMutex
+	insights map[types.InsightID]*types.Insight
+}
+
+// NewKnowledgeCache creates an empty cache of the insights matching filter
+// that viewer may read
+func NewKnowledgeCache(filter types.KnowledgeQuery, viewer *types.Agent, config *types.Config) *KnowledgeCache {
+	return &KnowledgeCache{
+		filter:   filter,
+		viewer:   viewer,
+		config:   config,
+		maxSize:  config.KnowledgeCacheSize,
+		insights: make(map[types.InsightID]*types.Insight),
+	}
+}
+
+// Put caches an insight if it matches the filter. A newer version of a cached
+// insight replaces it only if replace is set, so raw insights replayed from the
+// insight topic never overwrite the knowledge manager's verified versions.
+func (c *KnowledgeCache) Put(insight *types.Insight, replace bool) bool {
+	if !c.filter.Matches(insight) || (c.viewer != nil && !insight.VisibleTo(c.viewer)) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, cached := c.insights[insight.ID]; cached && !replace {
+		return false
+	}
+	c.insights[insight.ID] = insight
+
+	if c.maxSize > 0 && len(c.insights) > c.maxSize {
+		c.evictOldest()
+	}
+	return true
+}
+
+// Get returns a cached insight
+func (c *KnowledgeCache) Get(id types.InsightID) (types.Insight, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	insight, ok := c.insights[id]
+	if !ok {
+		return types.Insight{}, false
+	}
+	return *insight, true
+}
+
+// Query returns the cached insights matching a query, newest first.
+// Actionable and Limit apply as for the REST API; AgentID and Question are ignored.
+func (c *KnowledgeCache) Query(query types.KnowledgeQuery) []types.Insight {
+	c.mu.RLock()
+	matching := make([]types.Insight, 0)
+	for _, insight := range c.insights {
+		if !query.Matches(insight) || (query.Actionable && !insight.Actionable(c.config)) {
+			continue
+		}
+		matching = append(matching, *insight)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+	if query.Limit > 0 && len(matching) > query.Limit {
+		matching = matching[:query.Limit]
+	}
+	return matching
+}
+
+// Len returns the number of cached insights
+func (c *KnowledgeCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.insights)
+}
+
+// evictOldest removes the oldest insight (must be called with mu held)
+func (c *KnowledgeCache) evictOldest() {
+	var oldest *types.Insight
+	for _, insight := range c.insights {
+		if oldest == nil || insight.CreatedAt.Before(oldest.CreatedAt) {
+			oldest = insight
+		}
+	}
+	if oldest != nil {
+		delete(c.insights, oldest.ID)
+	}
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// conversationBacklog is how many received messages per worker may wait for
+// their conversation before the consumer stops reading
+const conversationBacklog = 100
+
+// ConversationQueues handles messages on a bounded number of goroutines with
+// a queue per conversation: a conversation's messages are handled one at a
+// time in the order received, different conversations concurrently. The
+// runtime uses them with EnableConversationWorkers; they can also be used for
+// messages consumed outside a runtime.
+type ConversationQueues struct {
+	handle func(*types.Message)
+	queue  *messaging.KeyedQueue
+}
+
+// NewConversationQueues creates queues handling up to workers conversations at once
+func NewConversationQueues(workers int, handle func(*types.Message)) *ConversationQueues {
+	workers = max(workers, 1)
+	return &ConversationQueues{
+		handle: handle,
+		queue:  messaging.NewKeyedQueue(workers, workers*conversationBacklog, 0),
+	}
+}
+
+// Submit queues a message behind the earlier ones of its conversation. It
+// blocks while the backlog is full, or a new conversation waits for a worker.
+func (q *ConversationQueues) Submit(msg *types.Message) {
+	q.queue.Submit(msg.OrderingKey(), func() { q.handle(msg) }, nil)
+}
+
+// Wait blocks until every submitted message is handled
+func (q *ConversationQueues) Wait() {
+	q.queue.Wait()
+}
+
+// conversationHistory is how many of a conversation's latest messages a
+// ConversationStore keeps
+const conversationHistory = 100
+
+// ConversationStore keeps the latest messages of the conversations an agent
+// takes part in, or stands by for, dropping the least recently active
+// conversation once full. A standby taking over picks the conversations of its
+// primary up where they were.
+type ConversationStore struct {
+	mu            sync.Mutex
+	size          int
+	conversations map[string]*list.Element
+	order         *list.List // Most recently active first
+}
+
+// storedConversation is a conversation's entry in a ConversationStore
+type storedConversation struct {
+	id       string
+	messages []*types.Message
+}
+
+// NewConversationStore creates a store of size conversations, unlimited below 1
+func NewConversationStore(size int) *ConversationStore {
+	return &ConversationStore{
+		size:          size,
+		conversations: make(map[string]*list.Element),
+		order:         list.New(),
+	}
+}
+
+// Record adds a message to its conversation's history; messages outside a
+// conversation are not kept
+func (s *ConversationStore) Record(msg *types.Message) {
+	if msg.ConversationID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.conversations[msg.ConversationID]
+	if ok {
+		s.order.MoveToFront(element)
+	} else {
+		if s.size > 0 && s.order.Len() == s.size {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.conversations, oldest.Value.(*storedConversation).id)
+		}
+		element = s.order.PushFront(&storedConversation{id: msg.ConversationID})
+		s.conversations[msg.ConversationID] = element
+	}
+
+	conversation := element.Value.(*storedConversation)
+	if len(conversation.messages) == conversationHistory {
+		conversation.messages = conversation.messages[1:]
+	}
+	conversation.messages = append(conversation.messages, msg)
+}
+
+// History returns a conversation's kept messages, oldest first
+func (s *ConversationStore) History(conversationID string) []*types.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.conversations[conversationID]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(element.Value.(*storedConversation).messages)
+}
+
+// Conversations returns the IDs of the kept conversations, most recently active first
+func (s *ConversationStore) Conversations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, s.order.Len())
+	for element := s.order.Front(); element != nil; element = element.Next() {
+		ids = append(ids, element.Value.(*storedConversation).id)
+	}
+	return ids
+}
@@ -0,0 +1,328 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// DebugHistory is how many processed messages the debug console keeps per agent
+const DebugHistory = 50
+
+// Outcomes of a processed message, to answer "why didn't this agent react"
+const (
+	OutcomeHandled    = "handled"
+	OutcomeFailed     = "failed"      // The handler returned an error
+	OutcomeNoHandler  = "no_handler"  // No handler is registered for the message type
+	OutcomeNotShared  = "not_shared"  // A pushed insight not shared with the agent's team
+	OutcomeUnverified = "unverified"  // A pushed insight whose type must be verified first
+	OutcomeBadPayload = "bad_payload" // A pushed insight that could not be decoded
+	OutcomeDuplicate  = "duplicate"   // A redelivery of a handled message, see AGENT_DEDUP_WINDOW
+	OutcomeRejected   = "rejected"    // Rejected by the Authorize middleware
+	OutcomeCancelled  = "cancelled"   // The handler failed after its context ended, see CONSUMER_HANDLER_TIMEOUT
+)
+
+// ProcessedMessage records how the runtime processed a message
+type ProcessedMessage struct {
+	ID          string            `json:"id"`
+	Topic       string            `json:"topic"`
+	Type        types.MessageType `json:"type"`
+	From        types.AgentID     `json:"from"`
+	ReplayOf    string            `json:"replay_of,omitempty"`
+	Outcome     string            `json:"outcome"`
+	Error       string            `json:"error,omitempty"`
+	Duration    time.Duration     `json:"duration"`
+	ProcessedAt time.Time         `json:"processed_at"`
+}
+
+// DebugStats are the runtime's counters
+type DebugStats struct {
+	Goroutines int         `json:"goroutines"` // Of the whole process, shared by its agents
+	InFlight   int64       `json:"in_flight"`  // Handlers running now
+	Processed  int64       `json:"processed"`
+	Failed     int64       `json:"failed"`
+	Duplicates int64       `json:"duplicates"`      // Redeliveries not passed to handlers
+	Cancelled  int64       `json:"cancelled"`       // Handlers still running when their context ended
+	Spool      *SpoolStats `json:"spool,omitempty"` // Messages waiting for the broker
+}
+
+// DebugState is what the debug console shows about an agent
+type DebugState struct {
+	Agent       *types.Agent          `json:"agent"`
+	Handlers    []types.MessageType   `json:"handlers"`
+	DryRun      bool                  `json:"dry_run,omitempty"`      // Publishing only logs, see AGENT_DRY_RUN
+	CacheFilter *types.KnowledgeQuery `json:"cache_filter,omitempty"` // Nil without a knowledge cache
+	CacheSize   int                   `json:"cache_size"`
+	Cache       []types.Insight       `json:"cache,omitempty"` // Newest first
+	Recent      []ProcessedMessage    `json:"recent"`          // Newest first
+	Stats       DebugStats            `json:"stats"`
+}
+
+// processedLog is a ring of the last DebugHistory processed messages
+type processedLog struct {
+	mu         sync.Mutex
+	entries    []ProcessedMessage
+	next       int
+	inFlight   atomic.Int64
+	processed  atomic.Int64
+	failed     atomic.Int64
+	duplicates atomic.Int64
+	cancelled  atomic.Int64
+}
+
+func (l *processedLog) add(entry ProcessedMessage) {
+	l.processed.Add(1)
+	switch entry.Outcome {
+	case OutcomeFailed, OutcomeCancelled:
+		l.failed.Add(1)
+	case OutcomeDuplicate:
+		l.duplicates.Add(1)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < DebugHistory {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % DebugHistory
+}
+
+// recent returns the logged messages, newest first
+func (l *processedLog) recent() []ProcessedMessage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := make([]ProcessedMessage, 0, len(l.entries))
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		recent = append(recent, l.entries[(l.next+i)%len(l.entries)])
+	}
+	return recent
+}
+
+// dispatch passes a message to the handler registered for handlerType with
+// ctx and records the outcome for the debug console
+func (ar *AgentRuntime) dispatch(ctx context.Context, topic string, msg *types.Message, handlerType types.MessageType) error {
+	ar.mu.RLock()
+	handler, exists := ar.handlers[handlerType]
+	middleware := ar.middleware
+	ar.mu.RUnlock()
+
+	if !exists {
+		ar.logger.Debug("No handler for message type", zap.String("type", string(handlerType)))
+		ar.record(topic, msg, OutcomeNoHandler, nil, 0)
+		return nil
+	}
+
+	// Consumer group rebalances redeliver messages whose offsets were not
+	// committed yet; IDs are unique per topic
+	key := topic + "/" + msg.ID
+	if msg.ID != "" && ar.dedup.seen(key) {
+		ar.suppressDuplicate(topic, msg, 0)
+		return nil
+	}
+
+	handler = Recover(ar.logger)(Chain(handler, middleware...))
+	ar.processed.inFlight.Add(1)
+	start := time.Now()
+	err := handler(ctx, msg)
+	ar.processed.inFlight.Add(-1)
+	if ctx.Err() != nil {
+		ar.cancelled(topic, msg, ctx.Err())
+	}
+
+	switch {
+	case err == nil:
+		if msg.ID != "" {
+			ar.dedup.remember(key)
+		}
+		ar.record(topic, msg, OutcomeHandled, nil, time.Since(start))
+	case errors.Is(err, ErrDuplicate):
+		ar.suppressDuplicate(topic, msg, time.Since(start))
+		return nil
+	case errors.Is(err, ErrUnauthorized):
+		ar.logger.Warn("Rejected message", zap.String("message_id", msg.ID), zap.Error(err))
+		ar.record(topic, msg, OutcomeRejected, err, time.Since(start))
+		return nil
+	case ctx.Err() != nil:
+		ar.record(topic, msg, OutcomeCancelled, err, time.Since(start))
+	default:
+		ar.record(topic, msg, OutcomeFailed, err, time.Since(start))
+	}
+	return err
+}
+
+// suppressDuplicate records a redelivered message that was not handled again
+func (ar *AgentRuntime) suppressDuplicate(topic string, msg *types.Message, duration time.Duration) {
+	ar.logger.Debug("Suppressed duplicate message",
+		zap.String("topic", topic),
+		zap.String("message_id", msg.ID))
+	if ar.reporter != nil {
+		ar.reporter.RecordDuplicateSuppressed(msg.Type)
+	}
+	ar.record(topic, msg, OutcomeDuplicate, nil, duration)
+}
+
+// cancelled counts a handler still running when its context ended
+func (ar *AgentRuntime) cancelled(topic string, msg *types.Message, cause error) {
+	ar.logger.Warn("Handler outlasted its context",
+		zap.String("topic", topic),
+		zap.String("message_id", msg.ID),
+		zap.Error(cause))
+	ar.processed.cancelled.Add(1)
+	if ar.reporter != nil {
+		ar.reporter.RecordHandlerCancelled(msg.Type, cause)
+	}
+}
+
+// record logs a processed message for the debug console
+func (ar *AgentRuntime) record(topic string, msg *types.Message, outcome string, err error, duration time.Duration) {
+	entry := ProcessedMessage{
+		ID:          msg.ID,
+		Topic:       topic,
+		Type:        msg.Type,
+		From:        msg.FromAgentID,
+		ReplayOf:    msg.ReplayOf,
+		Outcome:     outcome,
+		Duration:    duration,
+		ProcessedAt: time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	ar.processed.add(entry)
+}
+
+// DebugState returns the agent's handlers, cache and recently processed
+// messages; at most maxCached cached insights are included (0 = none)
+func (ar *AgentRuntime) DebugState(maxCached int) *DebugState {
+	state := &DebugState{
+		Agent:  ar.agent,
+		DryRun: ar.messaging != nil && ar.messaging.DryRun(),
+		Recent: ar.processed.recent(),
+		Stats: DebugStats{
+			Goroutines: runtime.NumGoroutine(),
+			InFlight:   ar.processed.inFlight.Load(),
+			Processed:  ar.processed.processed.Load(),
+			Failed:     ar.processed.failed.Load(),
+			Duplicates: ar.processed.duplicates.Load(),
+			Cancelled:  ar.processed.cancelled.Load(),
+		},
+	}
+
+	ar.mu.RLock()
+	for msgType := range ar.handlers {
+		state.Handlers = append(state.Handlers, msgType)
+	}
+	ar.mu.RUnlock()
+	sort.Slice(state.Handlers, func(i, j int) bool { return state.Handlers[i] < state.Handlers[j] })
+
+	if ar.cache != nil {
+		filter := ar.cache.filter
+		state.CacheFilter = &filter
+		state.CacheSize = ar.cache.Len()
+		if maxCached > 0 {
+			state.Cache = ar.cache.Query(types.KnowledgeQuery{Limit: maxCached})
+		}
+	}
+	if stats, ok := ar.SpoolStats(); ok {
+		state.Stats.Spool = &stats
+	}
+	return state
+}
+
+// ServeDebug serves the debug console of the given agents on cfg.AgentDebugAddr
+// until ctx is done; it returns at once if the address is not set.
+//
+// GET /debug/agents lists the agents; GET /debug/agents/{id}?cache=N shows one
+// with up to N cached insights (default 20). Requests must carry
+// "Authorization: Bearer <AGENT_DEBUG_TOKEN>"; without a token only loopback
+// clients are served, since the console shows insights the agent may read.
+func ServeDebug(ctx context.Context, cfg *types.Config, logger *zap.Logger, runtimes ...*AgentRuntime) error {
+	if cfg.AgentDebugAddr == "" {
+		return nil
+	}
+
+	byID := make(map[types.AgentID]*AgentRuntime, len(runtimes))
+	for _, ar := range runtimes {
+		byID[ar.agent.ID] = ar
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/agents", func(w http.ResponseWriter, r *http.Request) {
+		ids := make([]types.AgentID, 0, len(byID))
+		for id := range byID {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"agents": ids})
+	})
+	mux.HandleFunc("/debug/agents/", func(w http.ResponseWriter, r *http.Request) {
+		ar, ok := byID[types.AgentID(strings.TrimPrefix(r.URL.Path, "/debug/agents/"))]
+		if !ok {
+			http.Error(w, "Agent not found", http.StatusNotFound)
+			return
+		}
+		maxCached := 20
+		if value := r.URL.Query().Get("cache"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				http.Error(w, "cache must be a non-negative number", http.StatusBadRequest)
+				return
+			}
+			maxCached = parsed
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ar.DebugState(maxCached))
+	})
+
+	server := &http.Server{Addr: cfg.AgentDebugAddr, Handler: debugGuard(cfg.AgentDebugToken, mux)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	logger.Info("Agent debug console listening", zap.String("addr", cfg.AgentDebugAddr), zap.Int("agents", len(runtimes)))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// debugGuard allows GET requests with the token, or from loopback clients if no token is set
+func debugGuard(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err != nil || !net.ParseIP(host).IsLoopback() {
+			http.Error(w, "Set AGENT_DEBUG_TOKEN to use the debug console remotely", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
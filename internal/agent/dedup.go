@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// dedupWindow remembers the most recently handled message IDs, forgetting the
+// least recently seen first once full
+type dedupWindow struct {
+	mu    sync.Mutex
+	size  int
+	ids   map[string]*list.Element
+	order *list.List // Most recently seen first
+}
+
+// newDedupWindow creates a window of size IDs, or nil (remembering nothing) below 1
+func newDedupWindow(size int) *dedupWindow {
+	if size < 1 {
+		return nil
+	}
+	return &dedupWindow{size: size, ids: make(map[string]*list.Element, size), order: list.New()}
+}
+
+// seen reports whether id is in the window, keeping it there longer if so
+func (w *dedupWindow) seen(id string) bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	element, ok := w.ids[id]
+	if ok {
+		w.order.MoveToFront(element)
+	}
+	return ok
+}
+
+// remember adds id to the window
+func (w *dedupWindow) remember(id string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if element, ok := w.ids[id]; ok {
+		w.order.MoveToFront(element)
+		return
+	}
+	if w.order.Len() == w.size {
+		oldest := w.order.Back()
+		w.order.Remove(oldest)
+		delete(w.ids, oldest.Value.(string))
+	}
+	w.ids[id] = w.order.PushFront(id)
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/metrics"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Middleware wraps a message handler, e.g. to log, measure or reject messages
+type Middleware func(next MessageHandler) MessageHandler
+
+var (
+	// ErrDuplicate is returned by Dedup for a message that was already handled
+	ErrDuplicate = errors.New("duplicate message")
+	// ErrUnauthorized is returned by Authorize for a rejected message
+	ErrUnauthorized = errors.New("unauthorized message")
+)
+
+// Use adds middleware around every handler of the runtime. The first middleware
+// added is the outermost; Recover always wraps them all, so a panic in a
+// handler or a middleware fails the message instead of the consumer.
+func (ar *AgentRuntime) Use(middleware ...Middleware) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	ar.middleware = append(ar.middleware, middleware...)
+}
+
+// Chain wraps a handler in middleware, the first being the outermost
+func Chain(handler MessageHandler, middleware ...Middleware) MessageHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Recover turns a panicking handler into a failed message
+func Recover(logger *zap.Logger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Handler panicked",
+						zap.String("message_id", msg.ID),
+						zap.Any("panic", r),
+						zap.ByteString("stack", debug.Stack()),
+					)
+					err = fmt.Errorf("handler panicked: %v", r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging logs every handled message with its duration, and failures with the error
+func Logging(logger *zap.Logger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+			fields := []zap.Field{
+				zap.String("message_id", msg.ID),
+				zap.String("type", string(msg.Type)),
+				zap.String("from", string(msg.FromAgentID)),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.Warn("Message handler failed", append(fields, zap.Error(err))...)
+			} else {
+				logger.Debug("Message handled", fields...)
+			}
+			return err
+		}
+	}
+}
+
+// Metrics counts received messages by type and observes handler latency
+func Metrics(reporter *metrics.Reporter) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) error {
+			reporter.RecordMessageReceived(msg.Type)
+			start := time.Now()
+			err := next(ctx, msg)
+			reporter.RecordMessageLatency(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// SpanStarter starts a span for a message and returns the function ending it,
+// so a tracer can be plugged in without the SDK depending on it
+type SpanStarter func(msg *types.Message) (end func(err error))
+
+// Tracing wraps every handler call in a span
+func Tracing(start SpanStarter) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) error {
+			end := start(msg)
+			err := next(ctx, msg)
+			end(err)
+			return err
+		}
+	}
+}
+
+// Dedup drops messages whose ID was among the last size message IDs handled
+// or dropped, e.g. redeliveries after a consumer rebalance. Failed messages are
+// not remembered, so a redelivery is handled again. The runtime already does
+// this for every handler with AGENT_DEDUP_WINDOW; Dedup is for handlers
+// chained outside a runtime, or a window of their own.
+func Dedup(size int) Middleware {
+	window := newDedupWindow(max(size, 1))
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) error {
+			if window.seen(msg.ID) {
+				return ErrDuplicate
+			}
+			if err := next(ctx, msg); err != nil {
+				return err
+			}
+			window.remember(msg.ID)
+			return nil
+		}
+	}
+}
+
+// Authorize passes on only the messages check accepts; rejections wrap ErrUnauthorized
+func Authorize(check func(msg *types.Message) error) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *types.Message) error {
+			if err := check(msg); err != nil {
+				return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// AllowSenders accepts messages from the given agents only
+func AllowSenders(ids ...types.AgentID) func(msg *types.Message) error {
+	allowed := make(map[types.AgentID]bool, len(ids))
+	for _, id := range ids {
+		allowed[id] = true
+	}
+	return func(msg *types.Message) error {
+		if !allowed[msg.FromAgentID] {
+			return fmt.Errorf("sender %s is not allowed", msg.FromAgentID)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// SpoolEntry is a message waiting in the spool until the broker is reachable
+type SpoolEntry struct {
+	Topic    string         `json:"topic"`
+	Message  *types.Message `json:"message"`
+	QueuedAt time.Time      `json:"queued_at"`
+}
+
+// SpoolStats reports the state and activity of a spool
+type SpoolStats struct {
+	Queued     int   `json:"queued"`
+	Bytes      int   `json:"bytes"`
+	Flushed    int64 `json:"flushed"`
+	Dropped    int64 `json:"dropped"`    // Oldest entries dropped to stay within the limits
+	Rejected   int64 `json:"rejected"`   // Entries dropped at flush because they can never be published
+	Duplicates int64 `json:"duplicates"` // Messages already queued or flushed
+}
+
+// PublishFunc publishes a spooled message to its topic
+type PublishFunc func(ctx context.Context, topic string, message *types.Message) error
+
+// Spool stores messages on disk while the broker is unreachable, one file per
+// message named by queue order, and flushes them oldest first once it is back.
+// Messages are deduplicated by ID, so retrying a send never queues it twice.
+type Spool struct {
+	dir         string
+	maxMessages int
+	maxBytes    int
+
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+	files     []spoolFile // Oldest first
+	queued    map[string]bool
+	delivered map[string]bool
+	recent    []string // Flushed message IDs, oldest first
+	lastSeq   int64
+	stats     SpoolStats
+}
+
+// spoolFile is a queued message on disk
+type spoolFile struct {
+	name string
+	id   string
+	size int
+}
+
+// OpenSpool opens the spool in dir, creating it if needed and loading the
+// messages left by a previous run. A limit of 0 disables it.
+func OpenSpool(dir string, maxMessages, maxBytes int) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	s := &Spool{
+		dir:         dir,
+		maxMessages: maxMessages,
+		maxBytes:    maxBytes,
+		queued:      make(map[string]bool),
+		delivered:   make(map[string]bool),
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			os.Remove(path) // Interrupted write
+			continue
+		}
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		entry, size, err := readSpoolEntry(path)
+		if err != nil || s.queued[entry.Message.ID] {
+			os.Remove(path) // Torn write or duplicate
+			continue
+		}
+		s.files = append(s.files, spoolFile{name: e.Name(), id: entry.Message.ID, size: size})
+		s.queued[entry.Message.ID] = true
+		s.stats.Bytes += size
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].name < s.files[j].name })
+	if n := len(s.files); n > 0 {
+		fmt.Sscanf(s.files[n-1].name, "%d.json", &s.lastSeq)
+	}
+	s.stats.Queued = len(s.files)
+	return s, nil
+}
+
+// Enqueue stores a message until the next flush, dropping the oldest messages
+// if the spool is over its limits
+func (s *Spool) Enqueue(topic string, message *types.Message) error {
+	data, err := json.Marshal(SpoolEntry{Topic: topic, Message: message, QueuedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal spool entry: %w", err)
+	}
+	if s.maxBytes > 0 && len(data) > s.maxBytes {
+		return fmt.Errorf("message of %d bytes exceeds the spool limit of %d bytes", len(data), s.maxBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queued[message.ID] || s.delivered[message.ID] {
+		s.stats.Duplicates++
+		return nil
+	}
+
+	// Names sort in queue order
+	s.lastSeq = max(time.Now().UnixNano(), s.lastSeq+1)
+	name := fmt.Sprintf("%020d.json", s.lastSeq)
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write spool entry: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write spool entry: %w", err)
+	}
+
+	s.files = append(s.files, spoolFile{name: name, id: message.ID, size: len(data)})
+	s.queued[message.ID] = true
+	s.stats.Bytes += len(data)
+
+	for len(s.files) > 1 && s.overLimits() {
+		s.remove(s.files[0])
+		s.files = s.files[1:]
+		s.stats.Dropped++
+	}
+	s.stats.Queued = len(s.files)
+	return nil
+}
+
+// Flush publishes the queued messages oldest first and removes them from the
+// spool. Messages failing permanently (see messaging.Permanent) are dropped
+// and counted as rejected; any other failure stops the flush, keeping the
+// rest for the next one.
+func (s *Spool) Flush(ctx context.Context, publish PublishFunc) (int, error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	pending := append([]spoolFile(nil), s.files...)
+	s.mu.Unlock()
+
+	flushed := 0
+	for _, f := range pending {
+		entry, _, err := readSpoolEntry(filepath.Join(s.dir, f.name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Dropped since the flush started
+		} else if err != nil {
+			s.forget(f, false)
+			continue
+		}
+		if err := publish(ctx, entry.Topic, entry.Message); messaging.Permanent(err) {
+			s.forget(f, false)
+			s.mu.Lock()
+			s.stats.Rejected++
+			s.mu.Unlock()
+			continue
+		} else if err != nil {
+			return flushed, err
+		}
+		s.forget(f, true)
+		flushed++
+	}
+	return flushed, nil
+}
+
+// Stats returns the spool's state and activity
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// forget removes a queued file, remembering its message ID if it was delivered
+func (s *Spool) forget(f spoolFile, delivered bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.files {
+		if s.files[i].name == f.name {
+			s.remove(f)
+			s.files = append(s.files[:i], s.files[i+1:]...)
+			break
+		}
+	}
+	s.stats.Queued = len(s.files)
+	if !delivered {
+		return
+	}
+
+	s.stats.Flushed++
+	s.delivered[f.id] = true
+	s.recent = append(s.recent, f.id)
+	// Remember as many flushed IDs as the spool can hold
+	if limit := max(s.maxMessages, 1000); len(s.recent) > limit {
+		delete(s.delivered, s.recent[0])
+		s.recent = s.recent[1:]
+	}
+}
+
+// remove deletes a queued file; the caller updates s.files
+func (s *Spool) remove(f spoolFile) {
+	os.Remove(filepath.Join(s.dir, f.name))
+	delete(s.queued, f.id)
+	s.stats.Bytes -= f.size
+}
+
+// overLimits reports whether the spool holds more than its limits allow
+func (s *Spool) overLimits() bool {
+	return (s.maxMessages > 0 && len(s.files) > s.maxMessages) ||
+		(s.maxBytes > 0 && s.stats.Bytes > s.maxBytes)
+}
+
+// readSpoolEntry loads a queued message and its size on disk
+func readSpoolEntry(path string) (*SpoolEntry, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	var entry SpoolEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, 0, err
+	}
+	if entry.Message == nil || entry.Message.ID == "" {
+		return nil, 0, fmt.Errorf("spool entry without a message ID")
+	}
+	return &entry, len(data), nil
+}
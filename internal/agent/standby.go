@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// standbyBacklog is how many messages to a live primary a standby holds, in
+// case the primary dies before handling them
+const standbyBacklog = 1000
+
+// Standby follows a primary agent's heartbeats for the agent standing by for
+// it. Once the primary has been silent for the timeout, the standby takes
+// over: it handles the messages addressed to the primary since its last
+// heartbeat, and the new ones, until the primary sends a heartbeat again.
+type Standby struct {
+	primary types.AgentID
+	timeout time.Duration
+
+	mu        sync.Mutex
+	lastSeen  time.Time
+	takenOver time.Time        // Zero while standing by
+	held      []*types.Message // Messages to the primary since its last heartbeat
+}
+
+// NewStandby creates a standby for primary, counting it as seen at now
+func NewStandby(primary types.AgentID, timeout time.Duration, now time.Time) *Standby {
+	return &Standby{primary: primary, timeout: timeout, lastSeen: now}
+}
+
+// Primary returns the agent stood by for
+func (s *Standby) Primary() types.AgentID {
+	return s.primary
+}
+
+// Active reports whether the standby has taken over
+func (s *Standby) Active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.takenOver.IsZero()
+}
+
+// Heartbeat records the primary's heartbeat sent at the given time, and
+// reports whether the standby stepped back because the primary is alive again
+func (s *Standby) Heartbeat(at time.Time) (steppedBack bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !at.After(s.lastSeen) {
+		return false
+	}
+	s.lastSeen = at
+
+	// Messages sent before the heartbeat count as handled by the primary
+	kept := s.held[:0]
+	for _, msg := range s.held {
+		if !msg.Timestamp.Before(at) {
+			kept = append(kept, msg)
+		}
+	}
+	clear(s.held[len(kept):])
+	s.held = kept
+
+	if s.takenOver.IsZero() || !at.After(s.takenOver) {
+		return false
+	}
+	s.takenOver = time.Time{}
+	return true
+}
+
+// Receive reports whether a message addressed to the primary is to be handled
+// now; while standing by it is held instead
+func (s *Standby) Receive(msg *types.Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.takenOver.IsZero() {
+		return true
+	}
+	if len(s.held) == standbyBacklog {
+		s.held[0] = nil
+		s.held = s.held[1:]
+	}
+	s.held = append(s.held, msg)
+	return false
+}
+
+// Check takes over if the primary has been silent for the timeout at now,
+// returning the held messages to handle first
+func (s *Standby) Check(now time.Time) (held []*types.Message, tookOver bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.takenOver.IsZero() || now.Sub(s.lastSeen) < s.timeout {
+		return nil, false
+	}
+	s.takenOver = now
+	held, s.held = s.held, nil
+	return held, true
+}
+
+// EnableStandby makes the agent a warm standby for primary, an agent of the
+// same critical role (see CRITICAL_ROLES). The standby mirrors the messages of
+// the primary's conversations in its conversation store and, once the primary
+// misses its heartbeats for AGENT_OFFLINE_TIMEOUT, handles the messages
+// addressed to it until it is back. To mirror the primary's knowledge too,
+// enable the knowledge cache with the primary's filter. Call before Start.
+func (ar *AgentRuntime) EnableStandby(primary types.AgentID) error {
+	if !ar.config.IsCriticalRole(ar.agent.Role) {
+		return fmt.Errorf("role %s is not critical, add it to CRITICAL_ROLES to give its agents a standby", ar.agent.Role)
+	}
+	if primary == ar.agent.ID {
+		return fmt.Errorf("agent %s cannot stand by for itself", primary)
+	}
+	ar.standby = NewStandby(primary, ar.config.AgentOfflineTimeout, time.Now())
+	ar.agent.StandbyFor = primary
+	return nil
+}
+
+// Conversations returns the store of the conversations the agent, or the
+// primary it stands by for, takes part in
+func (ar *AgentRuntime) Conversations() *ConversationStore {
+	return ar.conversations
+}
+
+// watchPrimaryHeartbeats follows the heartbeats of the primary stood by for
+func (ar *AgentRuntime) watchPrimaryHeartbeats() {
+	defer ar.wg.Done()
+
+	groupID := fmt.Sprintf("agent-%s-standby", ar.agent.ID)
+	err := ar.messaging.ConsumeMessages(ar.ctx, "heartbeats", groupID, func(ctx context.Context, msg *types.Message) error {
+		if msg.Type != types.MessageTypeHeartbeat || msg.FromAgentID != ar.standby.Primary() {
+			return nil
+		}
+		if ar.standby.Heartbeat(msg.Timestamp) {
+			ar.logger.Info("Primary back online, standing by again", zap.String("primary", string(ar.standby.Primary())))
+		}
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		ar.logger.Error("Primary heartbeat consumption stopped", zap.Error(err))
+	}
+}
+
+// checkPrimary takes over for the primary once it missed its heartbeats,
+// handling the messages it may not have handled first
+func (ar *AgentRuntime) checkPrimary() {
+	defer ar.wg.Done()
+
+	ticker := time.NewTicker(max(ar.config.AgentOfflineTimeout/3, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ar.ctx.Done():
+			return
+		case now := <-ticker.C:
+			held, tookOver := ar.standby.Check(now)
+			if !tookOver {
+				continue
+			}
+			ar.logger.Warn("Primary missed its heartbeats, taking over",
+				zap.String("primary", string(ar.standby.Primary())),
+				zap.Duration("timeout", ar.config.AgentOfflineTimeout),
+				zap.Int("held_messages", len(held)),
+			)
+			for _, msg := range held {
+				ctx, cancel := ar.handlerContext()
+				if err := ar.dispatch(ctx, "messages", msg, msg.Type); err != nil {
+					ar.logger.Error("Failed to handle message", zap.Error(err), zap.String("message_id", msg.ID))
+				}
+				cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package alerting evaluates threshold and rate-of-change rules on mesh
+// metrics, so operators are notified of consumer lag, a collapsing topology
+// or a growing proposal backlog through webhooks, without running Prometheus
+// and Alertmanager.
+//
+// Each evaluation samples the metrics (Collect) and checks every rule
+// (Engine.Evaluate). A rule fires once when its metric crosses the limit and
+// resolves once when it no longer does; rate-of-change rules compare against
+// the sample taken a window earlier, so they only fire once that much history
+// has been sampled.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Sample is the value of each mesh metric at one time; metrics that could not
+// be measured are missing
+type Sample struct {
+	At      time.Time
+	Metrics map[string]float64
+}
+
+// Source provides the state metrics are sampled from (implemented by state.RedisStore)
+type Source interface {
+	ListServiceHealth(ctx context.Context) ([]types.ServiceHealth, error)
+	LoadGraphSnapshot(ctx context.Context) (*types.GraphSnapshot, error)
+	ListProposals(ctx context.Context) ([]*types.Proposal, error)
+}
+
+// Collect samples the mesh metrics at now
+func Collect(ctx context.Context, source Source, now time.Time) (Sample, error) {
+	sample := Sample{At: now, Metrics: make(map[string]float64)}
+
+	reports, err := source.ListServiceHealth(ctx)
+	if err != nil {
+		return sample, fmt.Errorf("failed to list service health: %w", err)
+	}
+	if len(reports) > 0 {
+		var lag int64
+		for _, report := range reports {
+			for _, behind := range report.ConsumerLag {
+				lag = max(lag, behind)
+			}
+		}
+		sample.Metrics[types.AlertMetricConsumerLag] = float64(lag)
+	}
+
+	proposals, err := source.ListProposals(ctx)
+	if err != nil {
+		return sample, fmt.Errorf("failed to list proposals: %w", err)
+	}
+	backlog := 0
+	for _, proposal := range proposals {
+		if proposal.Status == types.ProposalStatusPending {
+			backlog++
+		}
+	}
+	sample.Metrics[types.AlertMetricProposalBacklog] = float64(backlog)
+
+	// No snapshot yet leaves the topology metrics unmeasured
+	if snapshot, err := source.LoadGraphSnapshot(ctx); err == nil {
+		sample.Metrics[types.AlertMetricDensity] = snapshot.Stats.Density
+		sample.Metrics[types.AlertMetricActiveEdges] = float64(snapshot.Stats.ActiveEdges)
+		sample.Metrics[types.AlertMetricAgents] = float64(snapshot.Stats.TotalAgents)
+	}
+	return sample, nil
+}
+
+// Engine evaluates alerting rules over successive samples. It is not safe for
+// concurrent use.
+type Engine struct {
+	rules   []types.AlertRule
+	history []Sample // Oldest first, as far back as the longest window
+	firing  map[string]*types.Alert
+}
+
+// NewEngine creates an engine for the rules
+func NewEngine(rules []types.AlertRule) *Engine {
+	return &Engine{
+		rules:  rules,
+		firing: make(map[string]*types.Alert),
+	}
+}
+
+// Evaluate checks every rule against a sample, returning the alerts that
+// started firing and those that resolved
+func (e *Engine) Evaluate(sample Sample) (fired, resolved []*types.Alert) {
+	e.record(sample)
+
+	for _, rule := range e.rules {
+		name := rule.String()
+		value, ok := e.value(rule, sample)
+		if !ok {
+			continue // Unmeasured: keep the rule as it was
+		}
+
+		alert, isFiring := e.firing[name]
+		switch breached := rule.Breached(value); {
+		case breached && !isFiring:
+			alert = &types.Alert{Rule: name, Metric: rule.Metric, Value: value, Limit: rule.Limit, FiredAt: sample.At}
+			e.firing[name] = alert
+			fired = append(fired, alert)
+		case breached:
+			alert.Value = value
+		case isFiring:
+			resolvedAt := sample.At
+			alert.Value, alert.ResolvedAt = value, &resolvedAt
+			delete(e.firing, name)
+			resolved = append(resolved, alert)
+		}
+	}
+	return fired, resolved
+}
+
+// Firing returns the alerts currently firing
+func (e *Engine) Firing() []*types.Alert {
+	alerts := make([]*types.Alert, 0, len(e.firing))
+	for _, rule := range e.rules {
+		if alert, ok := e.firing[rule.String()]; ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// value returns what a rule compares against its limit: the metric, or its
+// relative change since the last sample at least a window old
+func (e *Engine) value(rule types.AlertRule, sample Sample) (float64, bool) {
+	current, ok := sample.Metrics[rule.Metric]
+	if !ok || rule.Window <= 0 {
+		return current, ok
+	}
+
+	var base *Sample
+	for i := range e.history {
+		if e.history[i].At.After(sample.At.Add(-rule.Window)) {
+			break
+		}
+		if _, measured := e.history[i].Metrics[rule.Metric]; measured {
+			base = &e.history[i]
+		}
+	}
+	if base == nil {
+		return 0, false
+	}
+	previous := base.Metrics[rule.Metric]
+	if previous == 0 {
+		return 0, false // No relative change from nothing
+	}
+	return (current - previous) / math.Abs(previous), true
+}
+
+// record adds a sample to the history, dropping samples no rule looks back to
+func (e *Engine) record(sample Sample) {
+	var longest time.Duration
+	for _, rule := range e.rules {
+		longest = max(longest, rule.Window)
+	}
+	e.history = append(e.history, sample)
+
+	// Keep the newest sample at least the longest window old, as the base of the next evaluation
+	keep := 0
+	for i := range e.history {
+		if !e.history[i].At.After(sample.At.Add(-longest)) {
+			keep = i
+		}
+	}
+	e.history = e.history[keep:]
+}
@@ -0,0 +1,247 @@
+// Package amql implements AMQL, the AgentMesh query language: a small subset
+// of SQL for filtering insights and the topology, e.g.
+//
+//	SELECT insights WHERE topic = 'pricing' AND confidence > 0.8 ORDER BY created_at DESC LIMIT 20
+//
+// Queries are evaluated against the JSON form of the records, so every JSON
+// field can be queried, nested ones with dots (rank.score).
+package amql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit applies to queries without a LIMIT
+	DefaultLimit = 100
+
+	// MaxLimit bounds the results of a query
+	MaxLimit = 1000
+)
+
+// Source is the record set a query selects from
+type Source string
+
+const (
+	SourceInsights Source = "insights"
+	SourceAgents   Source = "agents"
+	SourceEdges    Source = "edges"
+)
+
+// Operator compares a field with the values of a condition
+type Operator string
+
+const (
+	OpEqual        Operator = "="
+	OpNotEqual     Operator = "!="
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpContains     Operator = "CONTAINS" // Substring (case-insensitive) or list element
+	OpIn           Operator = "IN"
+)
+
+// Condition compares a record field with one value, or a list for IN
+type Condition struct {
+	Field  string   `json:"field"`
+	Op     Operator `json:"op"`
+	Values []any    `json:"values"`
+}
+
+// Query is a parsed AMQL query. Its conditions must all hold.
+type Query struct {
+	Source     Source      `json:"source"`
+	Conditions []Condition `json:"conditions,omitempty"`
+	OrderBy    string      `json:"order_by,omitempty"`
+	Descending bool        `json:"descending,omitempty"`
+	Limit      int         `json:"limit"`
+}
+
+// Records converts a slice of records (insights, agents, edges) to the JSON
+// form queries are evaluated against
+func Records(items any) ([]map[string]any, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal records: %w", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal records: %w", err)
+	}
+	return records, nil
+}
+
+// Run filters, orders and limits records
+func (q *Query) Run(records []map[string]any) []map[string]any {
+	results := make([]map[string]any, 0)
+	for _, record := range records {
+		if q.Match(record) {
+			results = append(results, record)
+		}
+	}
+
+	if q.OrderBy != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := Field(results[i], q.OrderBy), Field(results[j], q.OrderBy)
+			if a == nil || b == nil {
+				return a != nil // Records without the field last
+			}
+			c, ok := compare(a, b)
+			if !ok {
+				return false
+			}
+			if q.Descending {
+				return c > 0
+			}
+			return c < 0
+		})
+	}
+
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+// Match reports whether a record satisfies every condition
+func (q *Query) Match(record map[string]any) bool {
+	for _, cond := range q.Conditions {
+		if !cond.match(Field(record, cond.Field)) {
+			return false
+		}
+	}
+	return true
+}
+
+// match evaluates the condition against a field value (nil if missing)
+func (c *Condition) match(value any) bool {
+	switch c.Op {
+	case OpIn:
+		for _, v := range c.Values {
+			if equal(value, v) {
+				return true
+			}
+		}
+		return false
+	case OpContains:
+		return contains(value, c.Values[0])
+	case OpEqual:
+		return equal(value, c.Values[0])
+	case OpNotEqual:
+		return !equal(value, c.Values[0])
+	}
+
+	if value == nil {
+		return false
+	}
+	cmp, ok := compare(value, c.Values[0])
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case OpGreater:
+		return cmp > 0
+	case OpGreaterEqual:
+		return cmp >= 0
+	case OpLess:
+		return cmp < 0
+	case OpLessEqual:
+		return cmp <= 0
+	}
+	return false
+}
+
+// Field returns a field of a record, following dots into nested objects
+func Field(record map[string]any, field string) any {
+	var value any = record
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// equal reports whether a field value equals a literal; lists match if any element does
+func equal(value, literal any) bool {
+	if list, ok := value.([]any); ok {
+		for _, element := range list {
+			if equal(element, literal) {
+				return true
+			}
+		}
+		return false
+	}
+	c, ok := compare(value, literal)
+	return ok && c == 0
+}
+
+// contains reports whether a string holds a substring (ignoring case) or a list holds an element
+func contains(value, literal any) bool {
+	switch v := value.(type) {
+	case string:
+		s, ok := literal.(string)
+		return ok && strings.Contains(strings.ToLower(v), strings.ToLower(s))
+	case []any:
+		return equal(v, literal)
+	}
+	return false
+}
+
+// compare orders two values of the same kind; false sorts before true, and
+// strings that are both timestamps compare as times. It returns false for values of different kinds.
+func compare(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case !x && y:
+			return -1, true
+		case x && !y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		if tx, err := parseTime(x); err == nil {
+			if ty, err := parseTime(y); err == nil {
+				return tx.Compare(ty), true
+			}
+		}
+		return strings.Compare(x, y), true
+	}
+	return 0, false
+}
+
+// parseTime parses an RFC 3339 timestamp or a date
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
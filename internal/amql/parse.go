@@ -0,0 +1,294 @@
+package amql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Parse parses an AMQL query:
+//
+//	SELECT <insights|agents|edges>
+//	  [WHERE <field> <op> <value> {AND <field> <op> <value>}]
+//	  [ORDER BY <field> [ASC|DESC]]
+//	  [LIMIT <n>]
+//
+// Operators are =, !=, >, >=, <, <=, CONTAINS and IN ('a', 'b'). Values are
+// 'quoted strings', numbers, true or false. Keywords are case-insensitive.
+func Parse(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.query()
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota // Keywords, sources and field names
+	tokenString
+	tokenNumber
+	tokenSymbol // Operators, parentheses and commas
+	tokenEnd
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a query into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '\'':
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' { // '' escapes a quote
+						sb.WriteRune('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), start})
+		case strings.ContainsRune("!<>", r) && i+1 < len(runes) && runes[i+1] == '=':
+			i += 2
+			tokens = append(tokens, token{tokenSymbol, string(runes[start:i]), start})
+		case strings.ContainsRune("=<>(),", r):
+			i++
+			tokens = append(tokens, token{tokenSymbol, string(r), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+		}
+	}
+	return append(tokens, token{tokenEnd, "", len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the given keyword
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(word string) error {
+	if !p.keyword(word) {
+		return p.unexpected(word)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEnd {
+		return fmt.Errorf("expected %s at end of query", expected)
+	}
+	return fmt.Errorf("expected %s at position %d, got %q", expected, t.pos, t.text)
+}
+
+func (p *parser) query() (*Query, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	q := &Query{Limit: DefaultLimit}
+	source := p.next()
+	switch Source(strings.ToLower(source.text)) {
+	case SourceInsights, SourceAgents, SourceEdges:
+		if source.kind != tokenWord {
+			return nil, fmt.Errorf("unknown source %q", source.text)
+		}
+		q.Source = Source(strings.ToLower(source.text))
+	default:
+		p.pos--
+		return nil, p.unexpected("insights, agents or edges")
+	}
+
+	if p.keyword("WHERE") {
+		for {
+			cond, err := p.condition()
+			if err != nil {
+				return nil, err
+			}
+			q.Conditions = append(q.Conditions, cond)
+			if !p.keyword("AND") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		q.OrderBy = field
+		if p.keyword("DESC") {
+			q.Descending = true
+		} else {
+			p.keyword("ASC")
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != tokenNumber || err != nil || n <= 0 {
+			return nil, fmt.Errorf("expected a positive LIMIT at position %d, got %q", t.pos, t.text)
+		}
+		if n > MaxLimit {
+			return nil, fmt.Errorf("LIMIT %d exceeds the maximum of %d", n, MaxLimit)
+		}
+		q.Limit = n
+	}
+
+	if p.peek().kind != tokenEnd {
+		return nil, p.unexpected("end of query")
+	}
+	return q, nil
+}
+
+// field parses a field name, rejecting reserved keywords
+func (p *parser) field() (string, error) {
+	t := p.peek()
+	if t.kind != tokenWord || isKeyword(t.text) {
+		return "", p.unexpected("a field name")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *parser) condition() (Condition, error) {
+	field, err := p.field()
+	if err != nil {
+		return Condition{}, err
+	}
+	cond := Condition{Field: field}
+
+	switch t := p.peek(); {
+	case t.kind == tokenSymbol && isComparison(t.text):
+		p.pos++
+		cond.Op = Operator(t.text)
+	case p.keyword("CONTAINS"):
+		cond.Op = OpContains
+	case p.keyword("IN"):
+		cond.Op = OpIn
+	default:
+		return Condition{}, p.unexpected("an operator")
+	}
+
+	if cond.Op != OpIn {
+		value, err := p.value()
+		if err != nil {
+			return Condition{}, err
+		}
+		cond.Values = []any{value}
+		return cond, nil
+	}
+
+	if t := p.next(); t.text != "(" || t.kind != tokenSymbol {
+		p.pos--
+		return Condition{}, p.unexpected("(")
+	}
+	for {
+		value, err := p.value()
+		if err != nil {
+			return Condition{}, err
+		}
+		cond.Values = append(cond.Values, value)
+		t := p.next()
+		if t.kind == tokenSymbol && t.text == ")" {
+			return cond, nil
+		}
+		if t.kind != tokenSymbol || t.text != "," {
+			p.pos--
+			return Condition{}, p.unexpected(", or )")
+		}
+	}
+}
+
+// value parses a literal into the type JSON decoding gives record fields
+func (p *parser) value() (any, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return t.text, nil
+	case t.kind == tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return n, nil
+	case t.kind == tokenWord && strings.EqualFold(t.text, "true"):
+		return true, nil
+	case t.kind == tokenWord && strings.EqualFold(t.text, "false"):
+		return false, nil
+	}
+	p.pos--
+	return nil, p.unexpected("a value")
+}
+
+func isComparison(op string) bool {
+	switch Operator(op) {
+	case OpEqual, OpNotEqual, OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+		return true
+	}
+	return false
+}
+
+func isKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "SELECT", "WHERE", "AND", "ORDER", "BY", "ASC", "DESC", "LIMIT", "CONTAINS", "IN", "TRUE", "FALSE":
+		return true
+	}
+	return false
+}
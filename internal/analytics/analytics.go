@@ -0,0 +1,193 @@
+// Package analytics aggregates insights into topic counts and trends that can
+// be shared across teams.
+//
+// Insights of regulated teams are counted under differential privacy: each
+// count gets Laplace noise, so a release reveals almost nothing about whether
+// any one insight exists. An insight adds one to one topic and one trend
+// bucket, so each histogram has sensitivity 1 and the budget epsilon is split
+// evenly between them. Topics are keys as well as counts, so a topic seen only
+// in regulated insights is listed only when its noised count clears a
+// threshold, which it does for a single insight with probability delta.
+// Insights of other teams are counted exactly.
+//
+// Noise is derived from a secret and the cell it is added to, a trend bucket
+// or a window's topic, rather than drawn per request: every release counting
+// a cell adds the same noise, so overlapping releases cannot be averaged
+// against each other. Releases only cover the spans of the Grid, which bounds
+// how many distinct cells an insight is counted in.
+//
+// Protection is per insight: an agent publishing many insights derived from
+// the same customer spends the budget once per insight.
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// MaxBuckets bounds the trend points of one release
+const MaxBuckets = 1000
+
+// Grid is the spans insight analytics are released over, each with its bucket.
+// Daily buckets line up across spans, so they share their noise.
+var Grid = map[time.Duration]time.Duration{
+	24 * time.Hour:      time.Hour,
+	7 * 24 * time.Hour:  24 * time.Hour,
+	30 * 24 * time.Hour: 24 * time.Hour,
+}
+
+// Options selects the insights and the privacy of a release
+type Options struct {
+	Start        time.Time
+	End          time.Time
+	Bucket       time.Duration
+	PrivateTeams []string // Teams counted with noise; "*" = all
+	Epsilon      float64
+	Delta        float64
+	Secret       []byte // Key the noise is derived from
+}
+
+// Private reports whether a team's insights are counted with noise
+func (o *Options) Private(team string) bool {
+	for _, t := range o.PrivateTeams {
+		if t == "*" || t == team {
+			return true
+		}
+	}
+	return false
+}
+
+// Threshold is the noised count a topic seen only in regulated insights needs
+// to be listed: a single insight's count of 1 exceeds it with probability
+// delta under noise of scale 2/epsilon.
+func (o *Options) Threshold() float64 {
+	return 1 + (2/o.Epsilon)*math.Log(1/(2*o.Delta))
+}
+
+// Window returns the span ending at the last bucket boundary before now, so a
+// release can be cached until the next bucket closes and its buckets line up
+// with those of other releases
+func Window(now time.Time, span, bucket time.Duration) (time.Time, time.Time) {
+	end := now.Truncate(bucket)
+	return end.Add(-span), end
+}
+
+// counts holds exact counts and the counts to be noised
+type counts struct {
+	exact   int
+	private int
+}
+
+// Compute aggregates the insights created in [Start, End)
+func Compute(insights []*types.Insight, opts Options, now time.Time) *types.InsightAnalytics {
+	buckets := int(opts.End.Sub(opts.Start) / opts.Bucket)
+	topics := make(map[string]*counts)
+	trend := make([]counts, buckets)
+	noised := len(opts.PrivateTeams) > 0
+
+	for _, insight := range insights {
+		if insight.CreatedAt.Before(opts.Start) || !insight.CreatedAt.Before(opts.End) {
+			continue
+		}
+		bucket := int(insight.CreatedAt.Sub(opts.Start) / opts.Bucket)
+		if bucket >= buckets {
+			continue
+		}
+		topic, ok := topics[insight.Topic]
+		if !ok {
+			topic = &counts{}
+			topics[insight.Topic] = topic
+		}
+		if noised && opts.Private(insight.AgentRole) {
+			topic.private++
+			trend[bucket].private++
+		} else {
+			topic.exact++
+			trend[bucket].exact++
+		}
+	}
+
+	result := &types.InsightAnalytics{
+		Start:       opts.Start,
+		End:         opts.End,
+		Bucket:      opts.Bucket.String(),
+		Topics:      []types.TopicCount{},
+		Trend:       make([]types.TrendPoint, buckets),
+		GeneratedAt: now,
+	}
+	scale := 2 / opts.Epsilon // Half the budget each for topics and trend
+	if noised {
+		result.Privacy = &types.AnalyticsPrivacy{
+			Teams:     opts.PrivateTeams,
+			Epsilon:   opts.Epsilon,
+			Delta:     opts.Delta,
+			Threshold: opts.Threshold(),
+		}
+	}
+
+	for name, topic := range topics {
+		count := topic.exact
+		if noised {
+			noisy := float64(topic.private) + opts.noise(fmt.Sprintf("topic:%d:%d:%s", opts.Start.Unix(), opts.End.Unix(), name), scale)
+			// Exact counts already reveal the topic; otherwise it must clear the threshold
+			if topic.exact == 0 && noisy < result.Privacy.Threshold {
+				continue
+			}
+			count += clampCount(noisy)
+		}
+		result.Topics = append(result.Topics, types.TopicCount{Topic: name, Count: count})
+	}
+	sort.Slice(result.Topics, func(i, j int) bool {
+		if result.Topics[i].Count != result.Topics[j].Count {
+			return result.Topics[i].Count > result.Topics[j].Count
+		}
+		return result.Topics[i].Topic < result.Topics[j].Topic
+	})
+
+	for i, bucket := range trend {
+		start := opts.Start.Add(time.Duration(i) * opts.Bucket)
+		count := bucket.exact
+		if noised {
+			count += clampCount(float64(bucket.private) + opts.noise(fmt.Sprintf("trend:%s:%d", opts.Bucket, start.Unix()), scale))
+		}
+		result.Trend[i] = types.TrendPoint{Start: start, Count: count}
+		result.Total += count
+	}
+	return result
+}
+
+// noise returns the Laplace noise of a cell, the same for every release
+func (o *Options) noise(cell string, scale float64) float64 {
+	mac := hmac.New(sha256.New, o.Secret)
+	mac.Write([]byte(cell))
+	seed := int64(binary.BigEndian.Uint64(mac.Sum(nil)))
+	return laplace(rand.New(rand.NewSource(seed)), scale)
+}
+
+// laplace draws from the Laplace distribution centred on 0 with the given scale
+func laplace(rng *rand.Rand, scale float64) float64 {
+	u := rng.Float64() - 0.5
+	for u == -0.5 { // ln(0) at the edge of the range
+		u = rng.Float64() - 0.5
+	}
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
+
+// clampCount rounds a noised count to a non-negative integer
+func clampCount(value float64) int {
+	if value < 0 {
+		return 0
+	}
+	return int(math.Round(value))
+}
@@ -0,0 +1,166 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// rolePair is the sorted pair of roles an edge connects
+type rolePair [2]string
+
+func newRolePair(a, b string) rolePair {
+	if b < a {
+		a, b = b, a
+	}
+	return rolePair{a, b}
+}
+
+// pairState is what a snapshot shows of the edges between two roles
+type pairState struct {
+	edges  int
+	weight float64 // Sum over the edges
+}
+
+// CommunicationMatrix aggregates the traffic between agent roles in [Start,
+// End) per Bucket from timestamped snapshots, oldest first. Messages in a
+// bucket are the growth of edge usage between the last snapshots before its
+// start and its end, so snapshots before Start are the baseline; without one,
+// traffic is counted from the first snapshot in the window. Privacy options
+// are ignored: the matrix only counts messages, not their content.
+func CommunicationMatrix(snapshots []*types.GraphSnapshot, opts Options, now time.Time) *types.CommunicationMatrix {
+	buckets := int(opts.End.Sub(opts.Start) / opts.Bucket)
+
+	// Agents may have left by a later snapshot, so remember every role seen
+	roles := make(map[types.AgentID]string)
+	for _, snapshot := range snapshots {
+		for id, agent := range snapshot.Agents {
+			roles[id] = agent.Role
+		}
+	}
+	pairOf := func(edge *types.Edge) rolePair {
+		return newRolePair(roles[edge.SourceID], roles[edge.TargetID])
+	}
+
+	// stateAt returns the last snapshot taken before t, nil if none
+	next := 0
+	var current *types.GraphSnapshot
+	stateAt := func(t time.Time) *types.GraphSnapshot {
+		for next < len(snapshots) && snapshots[next].Timestamp.Before(t) {
+			current = snapshots[next]
+			next++
+		}
+		return current
+	}
+
+	pairs := make(map[rolePair]*types.RolePairTraffic)
+	pairFor := func(pair rolePair) *types.RolePairTraffic {
+		traffic, ok := pairs[pair]
+		if !ok {
+			traffic = &types.RolePairTraffic{Roles: pair, Windows: make([]types.RolePairWindow, buckets)}
+			for i := range traffic.Windows {
+				traffic.Windows[i].Start = opts.Start.Add(time.Duration(i) * opts.Bucket)
+			}
+			pairs[pair] = traffic
+		}
+		return traffic
+	}
+
+	previous := stateAt(opts.Start)
+	var last *types.GraphSnapshot
+	for i := 0; i < buckets; i++ {
+		state := stateAt(opts.Start.Add(time.Duration(i+1) * opts.Bucket))
+		if state == nil {
+			continue
+		}
+		last = state
+
+		if previous != nil {
+			for id, edge := range state.Edges {
+				growth, byType := edge.Usage, edge.UsageByType
+				if before, ok := previous.Edges[id]; ok {
+					growth, byType = edge.Usage-before.Usage, usageGrowth(before.UsageByType, edge.UsageByType)
+				}
+				if growth <= 0 {
+					continue
+				}
+				traffic := pairFor(pairOf(edge))
+				traffic.Windows[i].Messages += growth
+				traffic.Messages += growth
+				for msgType, count := range byType {
+					if traffic.ByType == nil {
+						traffic.ByType = make(map[types.MessageType]int64)
+					}
+					traffic.ByType[msgType] += count
+				}
+			}
+		}
+		for pair, edges := range pairStates(state, pairOf) {
+			window := &pairFor(pair).Windows[i]
+			window.Edges, window.AvgWeight = edges.edges, edges.weight/float64(edges.edges)
+		}
+		previous = state
+	}
+	if last != nil {
+		for pair, edges := range pairStates(last, pairOf) {
+			traffic := pairFor(pair)
+			traffic.Edges, traffic.AvgWeight = edges.edges, edges.weight/float64(edges.edges)
+		}
+	}
+
+	result := &types.CommunicationMatrix{
+		Start:       opts.Start,
+		End:         opts.End,
+		Bucket:      opts.Bucket.String(),
+		Roles:       []string{},
+		Pairs:       make([]types.RolePairTraffic, 0, len(pairs)),
+		GeneratedAt: now,
+	}
+	seen := make(map[string]bool)
+	for pair, traffic := range pairs {
+		result.Pairs = append(result.Pairs, *traffic)
+		for _, role := range pair {
+			if !seen[role] {
+				seen[role] = true
+				result.Roles = append(result.Roles, role)
+			}
+		}
+	}
+	sort.Strings(result.Roles)
+	sort.Slice(result.Pairs, func(i, j int) bool {
+		a, b := result.Pairs[i], result.Pairs[j]
+		if a.Messages != b.Messages {
+			return a.Messages > b.Messages
+		}
+		if a.Roles[0] != b.Roles[0] {
+			return a.Roles[0] < b.Roles[0]
+		}
+		return a.Roles[1] < b.Roles[1]
+	})
+	return result
+}
+
+// pairStates sums the edges of a snapshot by role pair
+func pairStates(snapshot *types.GraphSnapshot, pairOf func(*types.Edge) rolePair) map[rolePair]pairState {
+	states := make(map[rolePair]pairState)
+	for _, edge := range snapshot.Edges {
+		pair := pairOf(edge)
+		state := states[pair]
+		state.edges++
+		state.weight += edge.Weight
+		states[pair] = state
+	}
+	return states
+}
+
+// usageGrowth returns the per-type usage added between two states of an edge
+func usageGrowth(before, after map[types.MessageType]int64) map[types.MessageType]int64 {
+	growth := make(map[types.MessageType]int64)
+	for msgType, count := range after {
+		if added := count - before[msgType]; added > 0 {
+			growth[msgType] = added
+		}
+	}
+	return growth
+}
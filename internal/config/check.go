@@ -0,0 +1,337 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// typicalMessageInterval is how often a pair of collaborating agents typically
+// exchange messages; edges that decay away faster are lost between messages
+const typicalMessageInterval = time.Minute
+
+// Validate fails on settings the services cannot run with
+func Validate(cfg *types.Config) error {
+	if problems := Problems(cfg); len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Problems returns the invalid settings of a configuration, each with how to fix it
+func Problems(cfg *types.Config) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// Topology
+	if cfg.InitialEdgeWeight <= 0 || cfg.InitialEdgeWeight > 1 {
+		add("INITIAL_EDGE_WEIGHT is %g; set it between 0 and 1", cfg.InitialEdgeWeight)
+	}
+	if cfg.PruneThreshold >= cfg.InitialEdgeWeight {
+		add("PRUNE_THRESHOLD (%g) is not below INITIAL_EDGE_WEIGHT (%g), so new edges are pruned on the first decay; lower PRUNE_THRESHOLD", cfg.PruneThreshold, cfg.InitialEdgeWeight)
+	}
+	if cfg.EdgePriorHistory < 0 {
+		add("EDGE_PRIOR_HISTORY is %s; set a positive duration such as 24h, or 0 to forget edges of agents that left", cfg.EdgePriorHistory)
+	}
+	checkPrior := func(name string, prior float64) {
+		if prior < 0 || prior > 1 {
+			add("%s is %g; set it between 0 and 1, or 0 to use INITIAL_EDGE_WEIGHT", name, prior)
+		} else if prior > 0 && prior <= cfg.PruneThreshold {
+			add("%s (%g) is not above PRUNE_THRESHOLD (%g), so the edges it seeds are pruned on the first decay; raise it", name, prior, cfg.PruneThreshold)
+		}
+	}
+	checkPrior("EDGE_PRIOR_DEPENDENCY", cfg.EdgePriorDependency)
+	checkPrior("EDGE_PRIOR_SAME_ROLE", cfg.EdgePriorSameRole)
+	if cfg.LearnedPriors && cfg.LearnedPriorsMaxAge <= 0 {
+		add("LEARNED_PRIORS_MAX_AGE is %s; set a positive duration such as 168h, or LEARNED_PRIORS=false", cfg.LearnedPriorsMaxAge)
+	}
+	if cfg.ReinforcementAmount <= 0 || cfg.ReinforcementAmount > 1 {
+		add("REINFORCEMENT_AMOUNT is %g; set it above 0 and at most 1 (weight gained per message)", cfg.ReinforcementAmount)
+	}
+	if cfg.DecayRate < 0 || cfg.DecayRate >= 1 {
+		add("DECAY_RATE is %g; set it between 0 and 1 (weight lost per interval)", cfg.DecayRate)
+	}
+	if cfg.DecayInterval <= 0 {
+		add("DECAY_INTERVAL is %s; set a positive duration such as 5s", cfg.DecayInterval)
+	}
+	if cfg.PathFallback != types.PathFallbackDirect && cfg.PathFallback != types.PathFallbackNone {
+		add("PATH_FALLBACK is %q; set it to direct or none", cfg.PathFallback)
+	}
+	if cfg.EdgeScoring.Scorer() == nil {
+		add("EDGE_SCORING is %q; set it to additive or ewma", cfg.EdgeScoring)
+	}
+	if cfg.EdgeBootstrap != types.EdgeBootstrapLazy && cfg.EdgeBootstrap != types.EdgeBootstrapFullMesh {
+		add("EDGE_BOOTSTRAP is %q; set it to lazy or full_mesh", cfg.EdgeBootstrap)
+	}
+	if cfg.EdgeMode != types.EdgeModeDirected && cfg.EdgeMode != types.EdgeModeUndirected {
+		add("EDGE_MODE is %q; set it to directed or undirected", cfg.EdgeMode)
+	}
+	switch cfg.PruneStrategy {
+	case "", types.PruneStrategyThreshold:
+	case types.PruneStrategyTopK:
+		if cfg.PruneTopK < 1 {
+			add("PRUNE_TOP_K is %d; set it to the strongest edges each agent keeps, 1 or more", cfg.PruneTopK)
+		}
+	case types.PruneStrategyAge:
+		if cfg.PruneMaxAge <= 0 {
+			add("PRUNE_MAX_AGE is %s; set a positive duration such as 1h", cfg.PruneMaxAge)
+		}
+	default:
+		add("PRUNE_STRATEGY is %q; set it to threshold, top_k or age", cfg.PruneStrategy)
+	}
+	if cfg.TargetReduction < 0 || cfg.TargetReduction >= 100 {
+		add("TARGET_REDUCTION is %g; set it between 0 and 100 (%% of full-mesh edges removed, 0 = no target)", cfg.TargetReduction)
+	}
+	if cfg.TargetDensity < 0 || cfg.TargetDensity > 1 {
+		add("TARGET_DENSITY is %g; set it between 0 and 1 (0 = no target)", cfg.TargetDensity)
+	}
+	if cfg.TargetReduction > 0 && cfg.TargetDensity > 0 {
+		add("both TARGET_REDUCTION and TARGET_DENSITY are set; set only one, they measure the same thing")
+	}
+	if cfg.TargetReduction > 0 || cfg.TargetDensity > 0 {
+		if cfg.TuningMaxDecayRate <= 0 || cfg.TuningMaxDecayRate >= 1 {
+			add("TUNING_MAX_DECAY_RATE is %g; set it between 0 and 1", cfg.TuningMaxDecayRate)
+		}
+		if cfg.TuningMaxPruneThreshold <= 0 || cfg.TuningMaxPruneThreshold >= cfg.InitialEdgeWeight {
+			add("TUNING_MAX_PRUNE_THRESHOLD (%g) is not between 0 and INITIAL_EDGE_WEIGHT (%g), so tuning could prune new edges on the first decay; lower it", cfg.TuningMaxPruneThreshold, cfg.InitialEdgeWeight)
+		}
+	}
+
+	if cfg.DecayReferenceThroughput < 0 {
+		add("DECAY_REFERENCE_THROUGHPUT is %g; set it to the messages per second DECAY_RATE suits, or 0 for a fixed rate", cfg.DecayReferenceThroughput)
+	}
+	if cfg.AlgorithmHistory < 0 {
+		add("ALGORITHM_HISTORY is %d; set it to the decay cycles to keep, or 0 for none", cfg.AlgorithmHistory)
+	}
+	if cfg.TopologyHealthWindow <= 0 {
+		add("TOPOLOGY_HEALTH_WINDOW is %s; set a positive duration such as 15m", cfg.TopologyHealthWindow)
+	}
+	if cfg.OscillationPrunes == 1 || cfg.OscillationPrunes < 0 {
+		add("OSCILLATION_PRUNES is %d; set it to 2 or more prunes, or 0 to ignore oscillation", cfg.OscillationPrunes)
+	}
+	if cfg.OscillationFreezeEdges < 0 {
+		add("OSCILLATION_FREEZE_EDGES is %d; set it to the oscillating edges that freeze the topology, or 0 for never", cfg.OscillationFreezeEdges)
+	}
+	if cfg.StarvedEdgeWeight < 0 || cfg.StarvedEdgeWeight > 1 {
+		add("STARVED_EDGE_WEIGHT is %g; set it between 0 and 1 (0 = off)", cfg.StarvedEdgeWeight)
+	}
+	if cfg.HubOverloadShare < 0 || cfg.HubOverloadShare >= 1 {
+		add("HUB_OVERLOAD_SHARE is %g; set it between 0 and 1 (0 = off)", cfg.HubOverloadShare)
+	}
+
+	// Consensus
+	if cfg.QuorumThreshold <= 0 || cfg.QuorumThreshold > 1 {
+		add("QUORUM_THRESHOLD is %g; set it above 0 and at most 1", cfg.QuorumThreshold)
+	}
+	if cfg.ProposalTimeout <= 0 {
+		add("PROPOSAL_TIMEOUT is %s, so proposals expire immediately; set a positive duration", cfg.ProposalTimeout)
+	}
+	if cfg.VoteStakes {
+		if cfg.StakeInitialBalance <= 0 {
+			add("STAKE_INITIAL_BALANCE is %g, so no agent can stake; set a positive balance", cfg.StakeInitialBalance)
+		}
+		if cfg.StakeRewardRate < 0 {
+			add("STAKE_REWARD_RATE is %g; set it to 0 or more", cfg.StakeRewardRate)
+		}
+		if cfg.StakeSlashRate < 0 || cfg.StakeSlashRate > 1 {
+			add("STAKE_SLASH_RATE is %g; set it between 0 and 1 so balances cannot go negative", cfg.StakeSlashRate)
+		}
+	}
+
+	// Routing and regions
+	if cfg.RoutingExploration < 0 || cfg.RoutingExploration > 1 {
+		add("ROUTING_EXPLORATION is %g; set it between 0 and 1", cfg.RoutingExploration)
+	}
+	if cfg.RoutingLatencyTarget < 0 {
+		add("ROUTING_LATENCY_TARGET is %s; set 0 to ignore latency or a positive duration", cfg.RoutingLatencyTarget)
+	}
+	if cfg.CrossRegionPenalty < 0 || cfg.CrossRegionPenalty > 1 {
+		add("ROUTING_CROSS_REGION_PENALTY is %g; set it between 0 and 1", cfg.CrossRegionPenalty)
+	}
+	if cfg.RequireAttestation && len(cfg.AttestationKeys) == 0 {
+		add("REQUIRE_ATTESTATION is on but no ATTESTATION_CA_KEYS are trusted, so no agent can join; set ATTESTATION_CA_KEYS")
+	}
+
+	if cfg.PruneMinDegree < 0 {
+		add("PRUNE_MIN_DEGREE is %d; set it to 0 (no protection) or more", cfg.PruneMinDegree)
+	}
+	if cfg.AgentDedupWindow < 0 {
+		add("AGENT_DEDUP_WINDOW is %d; set it to 0 (no duplicate suppression) or more", cfg.AgentDedupWindow)
+	}
+	if cfg.ConversationStoreSize < 0 {
+		add("CONVERSATION_STORE_SIZE is %d; set it to 0 (unlimited) or more", cfg.ConversationStoreSize)
+	}
+	if cfg.SnapshotCheckpointEvery < 0 {
+		add("SNAPSHOT_CHECKPOINT_EVERY is %d; set it to 1 or more", cfg.SnapshotCheckpointEvery)
+	}
+	if cfg.LayoutIterations < 0 {
+		add("LAYOUT_ITERATIONS is %d; set it to 0 (no layout) or more", cfg.LayoutIterations)
+	}
+
+	// Incident mode
+	if _, err := types.ParseIncidentAutoStatus(string(cfg.IncidentAutoStatus)); err != nil {
+		add("INCIDENT_AUTO_STATUS: %v", err)
+	}
+	if cfg.IncidentSlowdown < 1 {
+		add("INCIDENT_SLOWDOWN is %d; set it to 1 (no slowdown) or more", cfg.IncidentSlowdown)
+	}
+
+	// Manager start modes and handoff
+	if _, err := types.ParseStartMode(string(cfg.TopologyStartMode)); err != nil {
+		add("TOPOLOGY_START_MODE: %v", err)
+	}
+	if _, err := types.ParseStartMode(string(cfg.KnowledgeStartMode)); err != nil {
+		add("KNOWLEDGE_START_MODE: %v", err)
+	}
+	if cfg.TopologyStartMode == types.StartModeReplay && cfg.TopologyReplayFrom.IsZero() {
+		add("TOPOLOGY_START_MODE is replay but TOPOLOGY_REPLAY_FROM is unset or invalid; set it to an RFC 3339 timestamp or a duration such as 72h")
+	}
+	if cfg.KnowledgeStartMode == types.StartModeReplay {
+		add("KNOWLEDGE_START_MODE: replay is only supported by the topology manager; use earliest to rebuild the knowledge base")
+	}
+	if cfg.HandoffFrom != "" && cfg.HandoffFrom == cfg.HandoffAddr {
+		add("HANDOFF_FROM equals HANDOFF_ADDR (%s), so an instance would take over from itself; point HANDOFF_FROM at the previous instance", cfg.HandoffFrom)
+	}
+
+	// Analytics privacy
+	if cfg.AnalyticsEpsilon <= 0 {
+		add("ANALYTICS_DP_EPSILON is %g; set it above 0 (1 is a common choice)", cfg.AnalyticsEpsilon)
+	}
+	if cfg.AnalyticsDelta <= 0 || cfg.AnalyticsDelta >= 1 {
+		add("ANALYTICS_DP_DELTA is %g; set it between 0 and 1, well below 1/insights (e.g. 1e-6)", cfg.AnalyticsDelta)
+	}
+
+	// Alerting
+	if len(cfg.AlertRules) > 0 && cfg.AlertInterval <= 0 {
+		add("ALERT_INTERVAL is %s; set a positive duration such as 30s for ALERT_RULES to be evaluated", cfg.AlertInterval)
+	}
+
+	// Bulk insight ingestion
+	if cfg.InsightBatchLimit <= 0 {
+		add("INSIGHT_BATCH_LIMIT is %d; set a positive number of insights such as 1000", cfg.InsightBatchLimit)
+	}
+
+	// Agent liveness
+	if cfg.HeartbeatInterval <= 0 {
+		add("HEARTBEAT_INTERVAL is %s; set a positive duration such as 30s", cfg.HeartbeatInterval)
+	}
+	if cfg.AgentOfflineTimeout <= 0 {
+		add("AGENT_OFFLINE_TIMEOUT is %s; set a positive duration such as 90s", cfg.AgentOfflineTimeout)
+	}
+	if cfg.AgentEvictionTimeout < 0 {
+		add("AGENT_EVICTION_TIMEOUT is %s; set a duration of at least 0 (0 never removes offline agents)", cfg.AgentEvictionTimeout)
+	}
+
+	// Insight embeddings
+	if cfg.EmbeddingURL != "" {
+		if cfg.EmbeddingModel == "" {
+			add("EMBEDDING_MODEL is empty; name the model EMBEDDING_URL serves, e.g. text-embedding-3-small")
+		}
+		if cfg.EmbeddingBatchSize <= 0 {
+			add("EMBEDDING_BATCH_SIZE is %d; set a positive number of texts such as 64", cfg.EmbeddingBatchSize)
+		}
+		if cfg.EmbeddingBatchInterval <= 0 {
+			add("EMBEDDING_BATCH_INTERVAL is %s; set a positive duration such as 2s", cfg.EmbeddingBatchInterval)
+		}
+	}
+
+	// External knowledge connectors
+	if cfg.ConnectorInterval <= 0 {
+		add("CONNECTOR_INTERVAL is %s; set a positive duration such as 5m", cfg.ConnectorInterval)
+	}
+	if cfg.ConnectorLookback < 0 {
+		add("CONNECTOR_LOOKBACK is %s; set a duration of at least 0", cfg.ConnectorLookback)
+	}
+
+	// Infrastructure
+	for _, broker := range cfg.KafkaBrokers {
+		if strings.TrimSpace(broker) == "" {
+			add("KAFKA_BROKERS contains an empty entry; list brokers as host:port,host:port")
+			break
+		}
+	}
+	if cfg.KafkaTopicPrefix == "" {
+		add("KAFKA_TOPIC_PREFIX is empty; services would not find the mesh topics")
+	}
+	if cfg.ConsumerWorkers < 1 {
+		add("CONSUMER_WORKERS is %d; set it to 1 (one message at a time) or more", cfg.ConsumerWorkers)
+	}
+	if cfg.ConsumerMaxInFlight < cfg.ConsumerWorkers {
+		add("CONSUMER_MAX_IN_FLIGHT (%d) is below CONSUMER_WORKERS (%d), so workers would sit idle; raise it", cfg.ConsumerMaxInFlight, cfg.ConsumerWorkers)
+	}
+	if cfg.ConsumerHandlerTimeout < 0 {
+		add("CONSUMER_HANDLER_TIMEOUT is %s; set a duration of at least 0 (0 = no limit)", cfg.ConsumerHandlerTimeout)
+	}
+
+	return problems
+}
+
+// Warnings returns settings that are valid but likely a mistake
+func Warnings(cfg *types.Config) []string {
+	var warnings []string
+	add := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Edges lose DecayRate per interval, so an unused edge survives this long
+	if cfg.DecayRate > 0 && cfg.DecayInterval > 0 && cfg.InitialEdgeWeight > cfg.PruneThreshold {
+		intervals := math.Ceil((cfg.InitialEdgeWeight - cfg.PruneThreshold) / cfg.DecayRate)
+		if lifetime := time.Duration(intervals) * cfg.DecayInterval; lifetime < typicalMessageInterval {
+			add("new edges are pruned after %s without messages, faster than agents typically message (%s); lower DECAY_RATE or raise DECAY_INTERVAL", lifetime, typicalMessageInterval)
+		}
+	}
+	byThreshold := cfg.PruneStrategy == "" || cfg.PruneStrategy == types.PruneStrategyThreshold
+	if cfg.DecayRate == 0 && byThreshold {
+		add("DECAY_RATE is 0, so edges never decay and the topology is never pruned")
+	}
+	if (cfg.TargetReduction > 0 || cfg.TargetDensity > 0) && !byThreshold {
+		add("TARGET_REDUCTION and TARGET_DENSITY tune PRUNE_THRESHOLD, which PRUNE_STRATEGY=%s ignores, so only the decay rate is tuned", cfg.PruneStrategy)
+	}
+	if cfg.ReinforcementAmount < cfg.DecayRate {
+		add("REINFORCEMENT_AMOUNT (%g) is below DECAY_RATE (%g), so edges used once per decay interval still weaken", cfg.ReinforcementAmount, cfg.DecayRate)
+	}
+	for pair, policy := range cfg.RolePolicies {
+		reinforcement, decay := cfg.ReinforcementAmount, cfg.DecayRate
+		if policy.ReinforcementAmount > 0 {
+			reinforcement = policy.ReinforcementAmount
+		}
+		if policy.DecayRate > 0 {
+			decay = policy.DecayRate
+		}
+		if reinforcement < decay {
+			add("ROLE_POLICIES for %s reinforce by %g but decay by %g, so their edges used once per decay interval still weaken", pair, reinforcement, decay)
+		}
+	}
+	if cfg.HeartbeatInterval > 0 && cfg.AgentOfflineTimeout > 0 && cfg.AgentOfflineTimeout < 2*cfg.HeartbeatInterval {
+		add("AGENT_OFFLINE_TIMEOUT (%s) is under two HEARTBEAT_INTERVALs (%s), so one late heartbeat marks an agent offline", cfg.AgentOfflineTimeout, cfg.HeartbeatInterval)
+	}
+	if cfg.AgentEvictionTimeout > 0 && cfg.AgentEvictionTimeout < cfg.AgentOfflineTimeout {
+		add("AGENT_EVICTION_TIMEOUT (%s) is below AGENT_OFFLINE_TIMEOUT (%s), so agents are removed before they are marked offline", cfg.AgentEvictionTimeout, cfg.AgentOfflineTimeout)
+	}
+	for _, constraint := range cfg.EdgeConstraints {
+		if !constraint.Deny && constraint.MaxWeight <= cfg.PruneThreshold {
+			add("EDGE_CONSTRAINTS cap %s/%s at %g, not above PRUNE_THRESHOLD (%g), so their edges are pruned; deny them instead", constraint.A, constraint.B, constraint.MaxWeight, cfg.PruneThreshold)
+		}
+	}
+	for _, rule := range cfg.AlertRules {
+		if rule.Window > 0 && rule.Window < cfg.AlertInterval {
+			add("alert rule %s looks back less than ALERT_INTERVAL (%s), so it compares against the previous evaluation instead; widen its window", rule, cfg.AlertInterval)
+		}
+	}
+	if len(cfg.FederationPeers) > 0 && cfg.Region == "" {
+		add("FEDERATION_PEERS is set but MESH_REGION is not, so the federation bridge refuses to start; set MESH_REGION")
+	}
+	if cfg.AgentDebugAddr != "" && cfg.AgentDebugToken == "" {
+		add("AGENT_DEBUG_ADDR is set without AGENT_DEBUG_TOKEN, so only local clients can use the agent debug console")
+	}
+	if len(cfg.AnalyticsPrivateTeams) > 0 && cfg.AnalyticsEpsilon > 10 {
+		add("ANALYTICS_DP_EPSILON is %g, so the noise on regulated teams' analytics gives little privacy; lower it", cfg.AnalyticsEpsilon)
+	}
+
+	return warnings
+}
@@ -1,38 +1,215 @@
 package config
 
 import (
-	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
-// Load loads configuration from environment variables
-func Load() *types.Config {
+// Load loads configuration from environment variables, the config file and
+// its profile (see LoadEffective) and validates it. The configuration is
+// returned even when invalid, so tools can report on it.
+func Load() (*types.Config, error) {
+	effective, err := LoadEffective()
+	if effective == nil {
+		return nil, err
+	}
+	return effective.Config, err
+}
+
+// resolve builds the configuration from the settings
+func resolve(s *settings) *types.Config {
 	return &types.Config{
 		// Topology settings
-		InitialEdgeWeight:   getEnvFloat("INITIAL_EDGE_WEIGHT", 0.5),
-		ReinforcementAmount: getEnvFloat("REINFORCEMENT_AMOUNT", 0.1),
-		DecayRate:           getEnvFloat("DECAY_RATE", 0.02), // Reduced from 0.05 to 0.02 (2% decay per interval)
-		DecayInterval:       getEnvDuration("DECAY_INTERVAL", 5*time.Second),
-		PruneThreshold:      getEnvFloat("PRUNE_THRESHOLD", 0.1),
+		InitialEdgeWeight:   s.getFloat("INITIAL_EDGE_WEIGHT", 0.5),
+		ReinforcementAmount: s.getFloat("REINFORCEMENT_AMOUNT", 0.1),
+		DecayRate:           s.getFloat("DECAY_RATE", 0.02), // Reduced from 0.05 to 0.02 (2% decay per interval)
+		DecayInterval:       s.getDuration("DECAY_INTERVAL", 5*time.Second),
+		PruneThreshold:      s.getFloat("PRUNE_THRESHOLD", 0.1),
+		PruneMinDegree:      s.getInt("PRUNE_MIN_DEGREE", 1),
+		PathFallback:        types.PathFallback(s.get("PATH_FALLBACK", string(types.PathFallbackDirect))),
+		EdgeScoring:         types.EdgeScoring(s.get("EDGE_SCORING", string(types.EdgeScoringAdditive))),
+		EdgeBootstrap:       types.EdgeBootstrap(s.get("EDGE_BOOTSTRAP", string(types.EdgeBootstrapLazy))),
+		EdgeMode:            types.EdgeMode(s.get("EDGE_MODE", string(types.EdgeModeDirected))),
+		EdgePriorHistory:    s.getDuration("EDGE_PRIOR_HISTORY", 24*time.Hour),
+		EdgePriorDependency: s.getFloat("EDGE_PRIOR_DEPENDENCY", 0.7),
+		EdgePriorSameRole:   s.getFloat("EDGE_PRIOR_SAME_ROLE", 0),
+		LearnedPriors:       s.getBool("LEARNED_PRIORS", true),
+		LearnedPriorsMaxAge: s.getDuration("LEARNED_PRIORS_MAX_AGE", 7*24*time.Hour),
+		PruneStrategy:       types.PruneStrategy(s.get("PRUNE_STRATEGY", string(types.PruneStrategyThreshold))),
+		PruneTopK:           s.getInt("PRUNE_TOP_K", 3),
+		PruneMaxAge:         s.getDuration("PRUNE_MAX_AGE", time.Hour),
+		ShadowTopology:      s.getTopologyParams("SHADOW_TOPOLOGY"),
+		RolePolicies:        s.getRolePolicies("ROLE_POLICIES"),
+		EdgeConstraints:     s.getEdgeConstraints("EDGE_CONSTRAINTS"),
+
+		// Reduction target controller
+		TargetReduction:         s.getFloat("TARGET_REDUCTION", 0),
+		TargetDensity:           s.getFloat("TARGET_DENSITY", 0),
+		TuningMaxDecayRate:      s.getFloat("TUNING_MAX_DECAY_RATE", 0.1),
+		TuningMaxPruneThreshold: s.getFloat("TUNING_MAX_PRUNE_THRESHOLD", 0.3),
+
+		// Adaptive decay
+		DecayReferenceThroughput: s.getFloat("DECAY_REFERENCE_THROUGHPUT", 0),
+
+		// Algorithm observability
+		AlgorithmHistory: s.getInt("ALGORITHM_HISTORY", 720),
+
+		// Topology health
+		TopologyHealthWindow:   s.getDuration("TOPOLOGY_HEALTH_WINDOW", 15*time.Minute),
+		OscillationPrunes:      s.getInt("OSCILLATION_PRUNES", 3),
+		OscillationFreezeEdges: s.getInt("OSCILLATION_FREEZE_EDGES", 0),
+		StarvedEdgeWeight:      s.getFloat("STARVED_EDGE_WEIGHT", 0.3),
+		HubOverloadShare:       s.getFloat("HUB_OVERLOAD_SHARE", 0.5),
+
+		// Topology guardrails
+		MaxPrunePerCycle:         s.getInt("MAX_PRUNE_PER_CYCLE", 50),
+		MaxWeightChangePerMinute: s.getFloat("MAX_WEIGHT_CHANGE_PER_MINUTE", 0.5),
+		ChurnFreezeThreshold:     s.getInt("CHURN_FREEZE_THRESHOLD", 500),
+		ChurnWindow:              s.getDuration("CHURN_WINDOW", time.Minute),
+
+		// Insight ranking
+		InsightHalfLife:      s.getDuration("INSIGHT_HALF_LIFE", 6*time.Hour),
+		CorroborationWindow:  s.getDuration("CORROBORATION_WINDOW", 24*time.Hour),
+		InsightPushThreshold: s.getFloat("INSIGHT_PUSH_THRESHOLD", 0.6),
+
+		// Digests
+		DigestInterval:    s.getDuration("DIGEST_INTERVAL", 24*time.Hour),
+		DigestTopInsights: s.getInt("DIGEST_TOP_INSIGHTS", 10),
+
+		// Alerting
+		AlertRules:    s.getAlertRules("ALERT_RULES"),
+		AlertInterval: s.getDuration("ALERT_INTERVAL", 30*time.Second),
+
+		// Routing feedback
+		RoutingLearning:     s.getBool("ROUTING_LEARNING", false),
+		RoutingLearningRate: s.getFloat("ROUTING_LEARNING_RATE", 0.2),
+		RoutingLearnWeight:  s.getFloat("ROUTING_LEARN_WEIGHT", 0.5),
+		RoutingExploration:  s.getFloat("ROUTING_EXPLORATION", 0.1),
+		RoutingLoadBalance:  s.getBool("ROUTING_LOAD_BALANCE", false),
+		TaskTimeout:         s.getDuration("TASK_TIMEOUT", 30*time.Second),
+
+		RoutingLatencyTarget: s.getDuration("ROUTING_LATENCY_TARGET", 0),
 
 		// Consensus settings
-		QuorumThreshold:    getEnvFloat("QUORUM_THRESHOLD", 0.6),
-		ProposalTimeout:    getEnvDuration("PROPOSAL_TIMEOUT", 30*time.Second),
-		WaggleIntensityMin: getEnvFloat("WAGGLE_INTENSITY_MIN", 0.3),
+		QuorumThreshold:    s.getFloat("QUORUM_THRESHOLD", 0.6),
+		ProposalTimeout:    s.getDuration("PROPOSAL_TIMEOUT", 30*time.Second),
+		WaggleIntensityMin: s.getFloat("WAGGLE_INTENSITY_MIN", 0.3),
+		EscalationPolicies: s.getEscalationPolicies("ESCALATION_POLICIES"),
+		RecurringProposals: s.getRecurringProposals("RECURRING_PROPOSALS"),
+
+		// Vote stakes
+		VoteStakes:          s.getBool("VOTE_STAKES", false),
+		StakeInitialBalance: s.getFloat("STAKE_INITIAL_BALANCE", 100),
+		StakeRewardRate:     s.getFloat("STAKE_REWARD_RATE", 0.5),
+		StakeSlashRate:      s.getFloat("STAKE_SLASH_RATE", 1.0),
+
+		// Insight verification
+		VerifiedInsightTypes: types.ParseInsightTypes(s.get("VERIFIED_INSIGHT_TYPES", "")),
+
+		// Agent attestation
+		AttestationKeys:    s.getAttestationKeys("ATTESTATION_CA_KEYS"),
+		RequireAttestation: s.getBool("REQUIRE_ATTESTATION", false),
+
+		// Sandbox for new agents
+		SandboxProbation:   s.getDuration("SANDBOX_PROBATION", 0),
+		SandboxMessageRate: s.getInt("SANDBOX_MESSAGE_RATE", 30),
+
+		// Agent liveness
+		HeartbeatInterval:    s.getDuration("HEARTBEAT_INTERVAL", 30*time.Second),
+		AgentOfflineTimeout:  s.getDuration("AGENT_OFFLINE_TIMEOUT", 90*time.Second),
+		AgentEvictionTimeout: s.getDuration("AGENT_EVICTION_TIMEOUT", 10*time.Minute),
+
+		// Multi-region deployments
+		Region:             s.get("MESH_REGION", ""),
+		CrossRegionPenalty: s.getFloat("ROUTING_CROSS_REGION_PENALTY", 0.5),
+		FederationPeers:    s.getFederationPeers("FEDERATION_PEERS"),
+		FederationMinScore: s.getFloat("FEDERATION_MIN_SCORE", 0.8),
+
+		// Store-and-forward spool
+		SpoolDir:           s.get("SPOOL_DIR", ""),
+		SpoolMaxMessages:   s.getInt("SPOOL_MAX_MESSAGES", 10000),
+		SpoolMaxBytes:      s.getInt("SPOOL_MAX_BYTES", 64<<20),
+		SpoolFlushInterval: s.getDuration("SPOOL_FLUSH_INTERVAL", 10*time.Second),
+
+		// Agent-local knowledge cache
+		KnowledgeCacheSize: s.getInt("KNOWLEDGE_CACHE_SIZE", 10000),
+
+		// Compacted insight topic
+		InsightCompaction: s.getBool("INSIGHT_COMPACTION", true),
+
+		// Low-confidence insight tiering
+		InsightTiering: s.getTieringPolicies("INSIGHT_TIERING"),
+
+		// Bulk insight ingestion
+		InsightBatchLimit: s.getInt("INSIGHT_BATCH_LIMIT", 1000),
+
+		// Insight embeddings
+		EmbeddingURL:           s.get("EMBEDDING_URL", ""),
+		EmbeddingModel:         s.get("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingAPIKey:        s.get("EMBEDDING_API_KEY", ""),
+		EmbeddingBatchSize:     s.getInt("EMBEDDING_BATCH_SIZE", 64),
+		EmbeddingBatchInterval: s.getDuration("EMBEDDING_BATCH_INTERVAL", 2*time.Second),
+
+		// External knowledge connectors
+		ConnectorInterval: s.getDuration("CONNECTOR_INTERVAL", 5*time.Minute),
+		ConnectorLookback: s.getDuration("CONNECTOR_LOOKBACK", 24*time.Hour),
+
+		// Topology snapshot checkpoints
+		SnapshotCheckpointEvery: s.getInt("SNAPSHOT_CHECKPOINT_EVERY", 12),
+
+		// Dashboard layout
+		LayoutIterations: s.getInt("LAYOUT_ITERATIONS", 50),
+
+		// Manager start modes
+		TopologyStartMode:  types.StartMode(s.get("TOPOLOGY_START_MODE", "resume")),
+		TopologyReplayFrom: s.getReplayFrom("TOPOLOGY_REPLAY_FROM"),
+		KnowledgeStartMode: types.StartMode(s.get("KNOWLEDGE_START_MODE", "resume")),
+
+		// System health rollup
+		HealthMaxConsumerLag: int64(s.getInt("HEALTH_MAX_CONSUMER_LAG", 10000)),
+		HealthMaxSnapshotAge: s.getDuration("HEALTH_MAX_SNAPSHOT_AGE", time.Minute),
+
+		// Incident mode
+		IncidentAutoStatus: types.HealthStatus(s.get("INCIDENT_AUTO_STATUS", "")),
+		IncidentSlowdown:   s.getInt("INCIDENT_SLOWDOWN", 4),
+
+		// Differentially private analytics
+		AnalyticsPrivateTeams: splitList(s.get("ANALYTICS_PRIVATE_TEAMS", "")),
+		AnalyticsEpsilon:      s.getFloat("ANALYTICS_DP_EPSILON", 1.0),
+		AnalyticsDelta:        s.getFloat("ANALYTICS_DP_DELTA", 1e-6),
 
 		// Infrastructure
-		KafkaBrokers:     strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
-		KafkaTopicPrefix: getEnv("KAFKA_TOPIC_PREFIX", "agentmesh"),
-		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisDB:          getEnvInt("REDIS_DB", 0),
+		KafkaBrokers:     strings.Split(s.get("KAFKA_BROKERS", "localhost:9092"), ","),
+		KafkaTopicPrefix: s.get("KAFKA_TOPIC_PREFIX", "agentmesh"),
+		RedisAddr:        s.get("REDIS_ADDR", "localhost:6379"),
+		RedisDB:          s.getInt("REDIS_DB", 0),
+
+		// Kafka consumers
+		ConsumerWorkers:        s.getInt("CONSUMER_WORKERS", 1),
+		ConsumerMaxInFlight:    s.getInt("CONSUMER_MAX_IN_FLIGHT", 100),
+		ConsumerHandlerTimeout: s.getDuration("CONSUMER_HANDLER_TIMEOUT", 0),
 
 		// Server
-		HTTPPort:      getEnvInt("HTTP_PORT", 8080),
-		WebSocketPort: getEnvInt("WEBSOCKET_PORT", 8081),
+		HTTPPort:      s.getInt("HTTP_PORT", 8080),
+		WebSocketPort: s.getInt("WEBSOCKET_PORT", 8081),
+
+		// Rolling upgrades
+		HandoffAddr: s.get("HANDOFF_ADDR", ""),
+		HandoffFrom: s.get("HANDOFF_FROM", ""),
+
+		// Agent debug console
+		AgentDebugAddr:  s.get("AGENT_DEBUG_ADDR", ""),
+		AgentDebugToken: s.get("AGENT_DEBUG_TOKEN", ""),
+		AgentDryRun:     s.getBool("AGENT_DRY_RUN", false),
+
+		// Duplicate suppression in agent consumers
+		AgentDedupWindow: s.getInt("AGENT_DEDUP_WINDOW", 10000),
+
+		// Warm standbys
+		CriticalRoles:         splitList(s.get("CRITICAL_ROLES", "")),
+		ConversationStoreSize: s.getInt("CONVERSATION_STORE_SIZE", 1000),
 	}
 }
 
@@ -44,11 +221,98 @@ func Default() *types.Config {
 		DecayRate:           0.02, // Reduced from 0.05 to 0.02 (2% decay per interval)
 		DecayInterval:       5 * time.Second,
 		PruneThreshold:      0.1,
+		PruneMinDegree:      1,
+		PathFallback:        types.PathFallbackDirect,
+		EdgeScoring:         types.EdgeScoringAdditive,
+		EdgeBootstrap:       types.EdgeBootstrapLazy,
+		EdgeMode:            types.EdgeModeDirected,
+		EdgePriorHistory:    24 * time.Hour,
+		EdgePriorDependency: 0.7,
+		LearnedPriors:       true,
+		LearnedPriorsMaxAge: 7 * 24 * time.Hour,
+		PruneStrategy:       types.PruneStrategyThreshold,
+		PruneTopK:           3,
+		PruneMaxAge:         time.Hour,
+
+		TuningMaxDecayRate:      0.1,
+		TuningMaxPruneThreshold: 0.3,
+
+		AlgorithmHistory: 720,
+
+		TopologyHealthWindow: 15 * time.Minute,
+		OscillationPrunes:    3,
+		StarvedEdgeWeight:    0.3,
+		HubOverloadShare:     0.5,
+
+		MaxPrunePerCycle:         50,
+		MaxWeightChangePerMinute: 0.5,
+		ChurnFreezeThreshold:     500,
+		ChurnWindow:              time.Minute,
+
+		InsightHalfLife:      6 * time.Hour,
+		CorroborationWindow:  24 * time.Hour,
+		InsightPushThreshold: 0.6,
+
+		DigestInterval:    24 * time.Hour,
+		DigestTopInsights: 10,
+
+		AlertInterval: 30 * time.Second,
+
+		InsightBatchLimit: 1000,
+
+		EmbeddingModel:         "text-embedding-3-small",
+		EmbeddingBatchSize:     64,
+		EmbeddingBatchInterval: 2 * time.Second,
+
+		ConnectorInterval: 5 * time.Minute,
+		ConnectorLookback: 24 * time.Hour,
+
+		RoutingLearningRate: 0.2,
+		RoutingLearnWeight:  0.5,
+		RoutingExploration:  0.1,
+		TaskTimeout:         30 * time.Second,
 
 		QuorumThreshold:    0.6,
 		ProposalTimeout:    30 * time.Second,
 		WaggleIntensityMin: 0.3,
 
+		StakeInitialBalance: 100,
+		StakeRewardRate:     0.5,
+		StakeSlashRate:      1.0,
+
+		SandboxMessageRate: 30,
+
+		HeartbeatInterval:    30 * time.Second,
+		AgentOfflineTimeout:  90 * time.Second,
+		AgentEvictionTimeout: 10 * time.Minute,
+
+		CrossRegionPenalty: 0.5,
+		FederationMinScore: 0.8,
+
+		SpoolMaxMessages:   10000,
+		SpoolMaxBytes:      64 << 20,
+		SpoolFlushInterval: 10 * time.Second,
+		KnowledgeCacheSize: 10000,
+		AgentDedupWindow:   10000,
+
+		ConversationStoreSize: 1000,
+
+		InsightCompaction: true,
+
+		SnapshotCheckpointEvery: 12,
+		LayoutIterations:        50,
+
+		HealthMaxConsumerLag: 10000,
+		HealthMaxSnapshotAge: time.Minute,
+
+		IncidentSlowdown: 4,
+
+		AnalyticsEpsilon: 1.0,
+		AnalyticsDelta:   1e-6,
+
+		ConsumerWorkers:     1,
+		ConsumerMaxInFlight: 100,
+
 		KafkaBrokers:     []string{"localhost:9092"},
 		KafkaTopicPrefix: "agentmesh",
 		RedisAddr:        "localhost:6379",
@@ -59,37 +323,13 @@ func Default() *types.Config {
 	}
 }
 
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
-
-func getEnvFloat(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
-		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-			return floatValue
-		}
-	}
-	return defaultValue
-}
-
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// splitList parses a comma-separated list, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	return defaultValue
+	return items
 }
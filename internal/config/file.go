@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey is the file section holding the named profiles
+const profilesKey = "profiles"
+
+// loadFile reads a YAML or TOML config file into its top-level settings and
+// its named profiles. Keys are the environment variable names, in any case
+// and with - or . for _, e.g. decay_rate for DECAY_RATE. Lists are joined
+// with commas, e.g. kafka_brokers: [a:9092, b:9092].
+func loadFile(path string) (map[string]string, map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case ".toml":
+		if doc, err = parseTOML(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported config file type %q (use .yaml, .yml or .toml)", ext)
+	}
+
+	base := make(map[string]string)
+	profiles := make(map[string]map[string]string)
+	for key, value := range doc {
+		if strings.EqualFold(key, profilesKey) {
+			named, ok := value.(map[string]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s in %s must map profile names to settings", profilesKey, path)
+			}
+			for name, values := range named {
+				profile, ok := values.(map[string]any)
+				if !ok {
+					return nil, nil, fmt.Errorf("profile %q in %s must map settings to values", name, path)
+				}
+				if profiles[name], err = flatten(profile); err != nil {
+					return nil, nil, fmt.Errorf("profile %q in %s: %w", name, path, err)
+				}
+			}
+			continue
+		}
+		if err := setValue(base, key, value); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return base, profiles, nil
+}
+
+// flatten converts a section of settings to strings keyed by environment variable name
+func flatten(section map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(section))
+	for key, value := range section {
+		if err := setValue(values, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// setValue stores a scalar or list setting under its environment variable name
+func setValue(values map[string]string, key string, value any) error {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+	switch v := value.(type) {
+	case nil:
+		values[name] = ""
+	case map[string]any:
+		return fmt.Errorf("setting %s must be a value or a list, not a section", key)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		values[name] = strings.Join(items, ",")
+	default:
+		values[name] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+// parseTOML parses the subset of TOML config files use: key = value pairs of
+// strings, numbers, booleans and single-line arrays, at the top level and in
+// [profiles.<name>] tables
+func parseTOML(data []byte) (map[string]any, error) {
+	doc := make(map[string]any)
+	section := doc
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(stripComment(scanner.Text()))
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", line)
+			}
+			header := strings.TrimSpace(text[1 : len(text)-1])
+			parts := strings.SplitN(header, ".", 2)
+			if len(parts) != 2 || parts[0] != profilesKey {
+				return nil, fmt.Errorf("line %d: unsupported table [%s] (only [%s.<name>])", line, header, profilesKey)
+			}
+			profiles, _ := doc[profilesKey].(map[string]any)
+			if profiles == nil {
+				profiles = make(map[string]any)
+				doc[profilesKey] = profiles
+			}
+			name := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+			section = make(map[string]any)
+			profiles[name] = section
+			continue
+		}
+
+		key, raw, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		section[strings.Trim(strings.TrimSpace(key), `"'`)] = value
+	}
+	return doc, scanner.Err()
+}
+
+// parseTOMLValue parses a string, number, boolean or single-line array
+func parseTOMLValue(raw string) (any, error) {
+	switch {
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("arrays must be on one line")
+		}
+		items := []any{}
+		for _, item := range splitTOMLArray(raw[1 : len(raw)-1]) {
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return nil, fmt.Errorf("invalid value %s (quote strings)", raw)
+	}
+	return number, nil
+}
+
+// splitTOMLArray splits array items on commas outside strings
+func splitTOMLArray(body string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, body[start:i])
+			start = i + 1
+		}
+	}
+	items = append(items, body[start:])
+
+	trimmed := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return trimmed
+}
+
+// stripComment removes a # comment outside strings
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Environment variables selecting the config file and its profile
+const (
+	FileEnv    = "AGENTMESH_CONFIG"  // Path of a YAML or TOML config file
+	ProfileEnv = "AGENTMESH_PROFILE" // Profile of the file to apply, e.g. dev, staging or prod
+)
+
+// Sources of a setting's value, from lowest to highest precedence; profile
+// sources are "profile:<name>"
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// secretSettings are redacted from the effective configuration
+var secretSettings = map[string]bool{
+	"AGENT_DEBUG_TOKEN": true,
+	"EMBEDDING_API_KEY": true,
+}
+
+// Setting is a resolved configuration setting
+type Setting struct {
+	Key    string `json:"key"` // Environment variable name, e.g. DECAY_RATE
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Effective is a loaded configuration and where each of its settings came from
+type Effective struct {
+	Config   *types.Config `json:"config"`
+	File     string        `json:"file,omitempty"`
+	Profile  string        `json:"profile,omitempty"`
+	Settings []Setting     `json:"settings"`          // Sorted by key
+	Ignored  []string      `json:"ignored,omitempty"` // Values that did not parse; the defaults were used instead
+}
+
+// LoadEffective loads the configuration and records the source of every
+// setting. Environment variables override the profile named by
+// AGENTMESH_PROFILE, which overrides the top level of the file named by
+// AGENTMESH_CONFIG, which overrides the defaults. It fails without a
+// configuration if the file cannot be read, and with one if it is invalid.
+func LoadEffective() (*Effective, error) {
+	s := &settings{resolved: make(map[string]Setting)}
+	effective := &Effective{File: os.Getenv(FileEnv), Profile: os.Getenv(ProfileEnv)}
+
+	if effective.File != "" {
+		base, profiles, err := loadFile(effective.File)
+		if err != nil {
+			return nil, err
+		}
+		if effective.Profile != "" {
+			profile, ok := profiles[effective.Profile]
+			if !ok {
+				return nil, fmt.Errorf("profile %q is not defined in %s", effective.Profile, effective.File)
+			}
+			s.layers = append(s.layers, layer{source: "profile:" + effective.Profile, values: profile})
+		}
+		s.layers = append(s.layers, layer{source: SourceFile, values: base})
+	} else if effective.Profile != "" {
+		return nil, fmt.Errorf("%s is set but %s is not", ProfileEnv, FileEnv)
+	}
+
+	effective.Config = resolve(s)
+
+	// Every key of the file must be a setting; anything else is a typo
+	for _, l := range s.layers {
+		for key := range l.values {
+			if _, ok := s.resolved[key]; !ok {
+				return nil, fmt.Errorf("unknown setting %s in %s (%s)", key, effective.File, l.source)
+			}
+		}
+	}
+
+	for _, setting := range s.resolved {
+		if secretSettings[setting.Key] && setting.Source != SourceDefault {
+			setting.Value = "[redacted]"
+		}
+		effective.Settings = append(effective.Settings, setting)
+	}
+	sort.Slice(effective.Settings, func(i, j int) bool { return effective.Settings[i].Key < effective.Settings[j].Key })
+	effective.Ignored = s.ignored
+
+	return effective, Validate(effective.Config)
+}
+
+// layer is one source of setting values, keyed by environment variable name
+type layer struct {
+	source string
+	values map[string]string
+}
+
+// settings resolves settings from the environment, then the file layers, then the defaults
+type settings struct {
+	layers   []layer // Highest precedence first
+	resolved map[string]Setting
+	ignored  []string
+}
+
+// lookup returns the configured value of a setting, if any, and records its source
+func (s *settings) lookup(key string) (string, bool) {
+	if value := os.Getenv(key); value != "" {
+		s.resolved[key] = Setting{Key: key, Value: value, Source: SourceEnv}
+		return value, true
+	}
+	for _, l := range s.layers {
+		if value, ok := l.values[key]; ok && value != "" {
+			s.resolved[key] = Setting{Key: key, Value: value, Source: l.source}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// fallback records that a setting uses its default, after an invalid value if err is set
+func (s *settings) fallback(key, defaultValue string, err error) {
+	if err != nil {
+		configured := s.resolved[key]
+		s.ignored = append(s.ignored, fmt.Sprintf("%s=%q from %s is invalid (%v); using the default", key, configured.Value, configured.Source, err))
+	}
+	s.resolved[key] = Setting{Key: key, Value: defaultValue, Source: SourceDefault}
+}
+
+func (s *settings) get(key, defaultValue string) string {
+	if value, ok := s.lookup(key); ok {
+		return value
+	}
+	s.fallback(key, defaultValue, nil)
+	return defaultValue
+}
+
+func (s *settings) getInt(key string, defaultValue int) int {
+	value, ok := s.lookup(key)
+	if !ok {
+		s.fallback(key, strconv.Itoa(defaultValue), nil)
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		s.fallback(key, strconv.Itoa(defaultValue), err)
+		return defaultValue
+	}
+	return intValue
+}
+
+func (s *settings) getFloat(key string, defaultValue float64) float64 {
+	value, ok := s.lookup(key)
+	if !ok {
+		s.fallback(key, strconv.FormatFloat(defaultValue, 'g', -1, 64), nil)
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		s.fallback(key, strconv.FormatFloat(defaultValue, 'g', -1, 64), err)
+		return defaultValue
+	}
+	return floatValue
+}
+
+func (s *settings) getBool(key string, defaultValue bool) bool {
+	value, ok := s.lookup(key)
+	if !ok {
+		s.fallback(key, strconv.FormatBool(defaultValue), nil)
+		return defaultValue
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		s.fallback(key, strconv.FormatBool(defaultValue), err)
+		return defaultValue
+	}
+	return boolValue
+}
+
+func (s *settings) getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := s.lookup(key)
+	if !ok {
+		s.fallback(key, defaultValue.String(), nil)
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		s.fallback(key, defaultValue.String(), err)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEscalationPolicies parses escalation policies from JSON; invalid policies disable escalation
+func (s *settings) getEscalationPolicies(key string) types.EscalationPolicies {
+	return parsed(s, key, types.ParseEscalationPolicies)
+}
+
+// getRecurringProposals parses recurring proposals from JSON; invalid ones disable them all
+func (s *settings) getRecurringProposals(key string) []types.RecurringProposal {
+	return parsed(s, key, types.ParseRecurringProposals)
+}
+
+// getAttestationKeys parses operator CA keys; invalid keys trust no CA
+func (s *settings) getAttestationKeys(key string) map[string]ed25519.PublicKey {
+	return parsed(s, key, types.ParseAttestationKeys)
+}
+
+// getFederationPeers parses the Kafka brokers of the other regions; invalid peers federate with none
+func (s *settings) getFederationPeers(key string) map[string][]string {
+	return parsed(s, key, types.ParseFederationPeers)
+}
+
+// getTopologyParams parses the parameters of a shadow topology; invalid parameters run none
+func (s *settings) getTopologyParams(key string) *types.TopologyParams {
+	return parsed(s, key, types.ParseTopologyParams)
+}
+
+// getAlertRules parses alerting rules; invalid rules alert on nothing
+func (s *settings) getAlertRules(key string) []types.AlertRule {
+	return parsed(s, key, types.ParseAlertRules)
+}
+
+// getTieringPolicies parses insight tiering policies; invalid policies store every insight
+func (s *settings) getTieringPolicies(key string) types.TieringPolicies {
+	return parsed(s, key, types.ParseTieringPolicies)
+}
+
+// getRolePolicies parses per-role-pair reinforcement and decay; invalid policies apply none
+func (s *settings) getRolePolicies(key string) types.RolePolicies {
+	return parsed(s, key, types.ParseRolePolicies)
+}
+
+// getReplayFrom parses where a replay starts, relative to now for a duration;
+// an invalid value leaves it unset
+func (s *settings) getReplayFrom(key string) time.Time {
+	return parsed(s, key, func(value string) (time.Time, error) {
+		return types.ParseReplayFrom(value, time.Now())
+	})
+}
+
+// getEdgeConstraints parses edges forbidden or capped between roles or agents;
+// invalid constraints apply none
+func (s *settings) getEdgeConstraints(key string) types.EdgeConstraints {
+	return parsed(s, key, types.ParseEdgeConstraints)
+}
+
+// parsed resolves a setting with a parser, falling back to the zero value
+func parsed[T any](s *settings, key string, parse func(string) (T, error)) T {
+	var zero T
+	value, ok := s.lookup(key)
+	if !ok {
+		s.fallback(key, "", nil)
+		return zero
+	}
+	result, err := parse(value)
+	if err != nil {
+		s.fallback(key, "", err)
+		return zero
+	}
+	return result
+}
@@ -0,0 +1,265 @@
+// Package connectors brings knowledge from outside systems into the mesh.
+//
+// A Connector runs as an agent of its own: it joins the topology, pulls the
+// records of one external Source (Zendesk tickets, Jira issues, sales CRM
+// exports) changed since its last pull every ConnectorInterval, and publishes
+// each as an insight carrying provenance metadata: the source, the record's ID,
+// URL and update time. Every revision of a record gets its own insight ID, so
+// pulling the same records again after a restart publishes no duplicates.
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Provenance metadata keys of insights published by connectors
+const (
+	MetaSource          = "source"            // Source kind, e.g. "zendesk"
+	MetaSourceID        = "source_id"         // Record ID in the source
+	MetaSourceURL       = "source_url"        // Link to the record
+	MetaSourceUpdatedAt = "source_updated_at" // Record revision, RFC 3339
+	MetaConnector       = "connector"         // Name of the connector agent
+)
+
+// requestTimeout bounds each request to an external source
+const requestTimeout = 30 * time.Second
+
+// Record is an item pulled from an external source, already mapped to the
+// insight it becomes
+type Record struct {
+	ID         string
+	URL        string
+	UpdatedAt  time.Time
+	Type       types.InsightType
+	Topic      string
+	Content    string
+	Confidence float64
+	Tags       []string
+	Data       map[string]any
+}
+
+// Source is an external system records are pulled from
+type Source interface {
+	// Kind names the source, e.g. "zendesk"
+	Kind() string
+	// Fetch returns the records changed at or after since
+	Fetch(ctx context.Context, since time.Time) ([]Record, error)
+}
+
+// Publisher is the part of the messaging layer a connector publishes to
+type Publisher interface {
+	PublishInsight(ctx context.Context, insight *types.Insight) error
+	PublishTopologyEvent(ctx context.Context, event types.TopologyEvent) error
+	PublishHeartbeat(ctx context.Context, agentID types.AgentID) error
+}
+
+// Insight converts a record into an insight published by a connector agent
+func (r Record) Insight(agent *types.Agent, kind string) *types.Insight {
+	insight := types.NewInsight(agent.ID, agent.Role, r.Type, r.Topic, r.Content, r.Confidence)
+	insight.ID = types.InsightID(fmt.Sprintf("insight-%s-%s-%d", kind, r.ID, r.UpdatedAt.Unix()))
+	insight.CreatedAt = r.UpdatedAt
+	insight.Tags = append(append(insight.Tags, r.Tags...), "source:"+kind)
+	maps.Copy(insight.Data, r.Data)
+	insight.Metadata[MetaSource] = kind
+	insight.Metadata[MetaSourceID] = r.ID
+	insight.Metadata[MetaSourceUpdatedAt] = r.UpdatedAt.UTC().Format(time.RFC3339)
+	insight.Metadata[MetaConnector] = agent.Name
+	if r.URL != "" {
+		insight.Metadata[MetaSourceURL] = r.URL
+	}
+	return insight
+}
+
+// Connector periodically pulls a source into the insights topic
+type Connector struct {
+	agent     *types.Agent
+	source    Source
+	publisher Publisher
+	config    *types.Config
+	logger    *zap.Logger
+
+	// The newest record revision published, and the records published at it,
+	// as sources return the records updated at since again
+	since    time.Time
+	atCursor map[string]bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewConnector creates a connector agent pulling from source, starting with
+// the records changed within ConnectorLookback
+func NewConnector(agent *types.Agent, source Source, publisher Publisher, cfg *types.Config, logger *zap.Logger) *Connector {
+	return &Connector{
+		agent:     agent,
+		source:    source,
+		publisher: publisher,
+		config:    cfg,
+		logger:    logger.With(zap.String("agent_id", string(agent.ID)), zap.String("source", source.Kind())),
+		since:     time.Now().Add(-cfg.ConnectorLookback),
+		atCursor:  make(map[string]bool),
+	}
+}
+
+// Start joins the mesh and starts pulling
+func (c *Connector) Start(ctx context.Context) error {
+	if c.config.ConnectorInterval <= 0 {
+		return fmt.Errorf("CONNECTOR_INTERVAL must be positive")
+	}
+	if c.config.HeartbeatInterval <= 0 {
+		return fmt.Errorf("HEARTBEAT_INTERVAL must be positive")
+	}
+	joinEvent := types.TopologyEvent{
+		Type:      types.TopologyEventAgentJoined,
+		AgentID:   c.agent.ID,
+		Agent:     c.agent,
+		Timestamp: time.Now(),
+	}
+	if err := c.publisher.PublishTopologyEvent(ctx, joinEvent); err != nil {
+		return fmt.Errorf("failed to publish join event: %w", err)
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx)
+	}()
+	go func() {
+		defer c.wg.Done()
+		c.sendHeartbeats(ctx)
+	}()
+
+	c.logger.Info("Connector started",
+		zap.Duration("interval", c.config.ConnectorInterval),
+		zap.Time("since", c.since),
+	)
+	return nil
+}
+
+// Stop stops pulling and leaves the mesh
+func (c *Connector) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return c.publisher.PublishTopologyEvent(ctx, types.TopologyEvent{
+		Type:      types.TopologyEventAgentLeft,
+		AgentID:   c.agent.ID,
+		Timestamp: time.Now(),
+	})
+}
+
+// run pulls right away, then every ConnectorInterval
+func (c *Connector) run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.ConnectorInterval)
+	defer ticker.Stop()
+
+	for {
+		if published, err := c.Pull(ctx); err != nil {
+			c.logger.Warn("Failed to pull from source", zap.Error(err))
+		} else if published > 0 {
+			c.logger.Info("Pulled records into the mesh", zap.Int("insights", published))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeats keeps the connector from being evicted between pulls
+func (c *Connector) sendHeartbeats(ctx context.Context) {
+	ticker := time.NewTicker(c.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.publisher.PublishHeartbeat(ctx, c.agent.ID); err != nil {
+				c.logger.Warn("Failed to send heartbeat", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Pull publishes the records changed since the last pull and returns how many
+// were published. A pull that fails part way keeps what it published, and the
+// next one resumes after it.
+func (c *Connector) Pull(ctx context.Context) (int, error) {
+	records, err := c.source.Fetch(ctx, c.since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s records: %w", c.source.Kind(), err)
+	}
+	slices.SortStableFunc(records, func(a, b Record) int { return a.UpdatedAt.Compare(b.UpdatedAt) })
+
+	published := 0
+	for _, record := range records {
+		if record.UpdatedAt.Before(c.since) || (record.UpdatedAt.Equal(c.since) && c.atCursor[record.ID]) {
+			continue
+		}
+		if err := c.publisher.PublishInsight(ctx, record.Insight(c.agent, c.source.Kind())); err != nil {
+			return published, fmt.Errorf("failed to publish %s record %s: %w", c.source.Kind(), record.ID, err)
+		}
+		published++
+
+		if record.UpdatedAt.After(c.since) {
+			c.since = record.UpdatedAt
+			clear(c.atCursor)
+		}
+		c.atCursor[record.ID] = true
+	}
+	return published, nil
+}
+
+// getJSON fetches a URL of a source's API and decodes the JSON response into out
+func getJSON(ctx context.Context, client *http.Client, url string, authorize func(*http.Request), out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorize != nil {
+		authorize(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// confidence parses an optional confidence, falling back to a source's default
+func confidence(value string, fallback float64) float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		return fallback
+	}
+	return parsed
+}
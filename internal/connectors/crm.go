@@ -0,0 +1,120 @@
+package connectors
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// CRMExportSource pulls the rows of a sales CRM's CSV export. The export is
+// read again on every pull, so it may be replaced by a scheduled export.
+//
+// Rows need an id and an updated_at (RFC 3339) column and at least one of
+// subject and notes. The optional topic, type, confidence and url columns
+// override the defaults (topic "sales", customer feedback, confidence 0.7);
+// all other columns go into the insight's data.
+type CRMExportSource struct {
+	path string
+}
+
+// NewCRMExportSource creates a source of the rows of a CSV export
+func NewCRMExportSource(path string) *CRMExportSource {
+	return &CRMExportSource{path: path}
+}
+
+// Kind names the source
+func (c *CRMExportSource) Kind() string { return "crm" }
+
+// Fetch returns the rows updated at or after since
+func (c *CRMExportSource) Fetch(ctx context.Context, since time.Time) ([]Record, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CRM export: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CRM export header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasSubject := columns["subject"]
+	_, hasNotes := columns["notes"]
+	if _, ok := columns["id"]; !ok {
+		return nil, fmt.Errorf("CRM export has no id column")
+	}
+	if _, ok := columns["updated_at"]; !ok {
+		return nil, fmt.Errorf("CRM export has no updated_at column")
+	}
+	if !hasSubject && !hasNotes {
+		return nil, fmt.Errorf("CRM export has neither a subject nor a notes column")
+	}
+
+	var records []Record
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read CRM export: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		updated, err := time.Parse(time.RFC3339, field("updated_at"))
+		if err != nil {
+			return nil, fmt.Errorf("CRM export line %d has an invalid updated_at: %w", line, err)
+		}
+		if updated.Before(since) {
+			continue
+		}
+		record := Record{
+			ID:         field("id"),
+			URL:        field("url"),
+			UpdatedAt:  updated,
+			Type:       types.InsightTypeCustomerFeedback,
+			Topic:      "sales",
+			Content:    joinContent(field("subject"), field("notes")),
+			Confidence: confidence(field("confidence"), 0.7),
+			Data:       make(map[string]any),
+		}
+		if record.ID == "" || record.Content == "" {
+			return nil, fmt.Errorf("CRM export line %d needs an id and a subject or notes", line)
+		}
+		if topic := field("topic"); topic != "" {
+			record.Topic = topic
+		}
+		if insightType := field("type"); insightType != "" {
+			record.Type = types.InsightType(insightType)
+		}
+		for name, i := range columns {
+			switch name {
+			case "id", "updated_at", "subject", "notes", "topic", "type", "confidence", "url":
+			default:
+				if i < len(row) && row[i] != "" {
+					record.Data[name] = row[i]
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,148 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// jiraPageSize is the issues requested per page; jiraMaxPages bounds the pages
+// of one pull, the rest follow on the next
+const (
+	jiraPageSize = 100
+	jiraMaxPages = 10
+)
+
+// jiraTimeLayout is the format of Jira's timestamps
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// JiraSource pulls issues matching a JQL query through the search API
+type JiraSource struct {
+	baseURL string // e.g. "https://acme.atlassian.net"
+	jql     string // Filter ANDed with the update time, e.g. "project = OPS"
+	token   string // "email:api-token" for Jira Cloud, a personal access token otherwise
+	client  *http.Client
+}
+
+// NewJiraSource creates a source of the issues matching jql ("" = all the
+// token can see), authenticating with "email:api-token" or a personal access token
+func NewJiraSource(baseURL, jql, token string) *JiraSource {
+	return &JiraSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		jql:     jql,
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Kind names the source
+func (j *JiraSource) Kind() string { return "jira" }
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string   `json:"summary"`
+		Description string   `json:"description"`
+		Updated     string   `json:"updated"`
+		Labels      []string `json:"labels"`
+		IssueType   struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Priority struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		Components []struct {
+			Name string `json:"name"`
+		} `json:"components"`
+	} `json:"fields"`
+}
+
+// Fetch returns the issues updated at or after since. JQL compares minutes in
+// the time zone of the token's user, so the query is relative to now in whole
+// minutes and returns issues updated up to a minute before since too.
+func (j *JiraSource) Fetch(ctx context.Context, since time.Time) ([]Record, error) {
+	minutes := int(time.Since(since)/time.Minute) + 1
+	jql := fmt.Sprintf(`updated >= "-%dm" ORDER BY updated ASC`, minutes)
+	if j.jql != "" {
+		jql = "(" + j.jql + ") AND " + jql
+	}
+
+	var records []Record
+	for page := 0; page < jiraMaxPages; page++ {
+		query := url.Values{
+			"jql":        {jql},
+			"startAt":    {fmt.Sprint(page * jiraPageSize)},
+			"maxResults": {fmt.Sprint(jiraPageSize)},
+			"fields":     {"summary,description,updated,labels,issuetype,priority,status,components"},
+		}
+		var body struct {
+			Total  int         `json:"total"`
+			Issues []jiraIssue `json:"issues"`
+		}
+		if err := getJSON(ctx, j.client, j.baseURL+"/rest/api/2/search?"+query.Encode(), j.authorize, &body); err != nil {
+			return nil, err
+		}
+		for _, issue := range body.Issues {
+			record, err := j.record(issue)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		if len(body.Issues) < jiraPageSize || (page+1)*jiraPageSize >= body.Total {
+			break
+		}
+	}
+	return records, nil
+}
+
+func (j *JiraSource) authorize(req *http.Request) {
+	if user, password, ok := strings.Cut(j.token, ":"); ok {
+		req.SetBasicAuth(user, password)
+	} else if j.token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+}
+
+// record maps an issue: bugs are product issues, everything else process
+// improvements, on the topic of the issue's first component or label
+func (j *JiraSource) record(issue jiraIssue) (Record, error) {
+	updated, err := time.Parse(jiraTimeLayout, issue.Fields.Updated)
+	if err != nil {
+		return Record{}, fmt.Errorf("issue %s has an invalid update time: %w", issue.Key, err)
+	}
+	project, _, _ := strings.Cut(issue.Key, "-")
+
+	record := Record{
+		ID:         issue.Key,
+		URL:        j.baseURL + "/browse/" + url.PathEscape(issue.Key),
+		UpdatedAt:  updated,
+		Type:       types.InsightTypeProcessImprovement,
+		Topic:      strings.ToLower(project),
+		Content:    joinContent(issue.Fields.Summary, issue.Fields.Description),
+		Confidence: 0.7,
+		Tags:       issue.Fields.Labels,
+		Data: map[string]any{
+			"issue_type": issue.Fields.IssueType.Name,
+			"priority":   issue.Fields.Priority.Name,
+			"status":     issue.Fields.Status.Name,
+		},
+	}
+	if strings.EqualFold(issue.Fields.IssueType.Name, "bug") {
+		record.Type = types.InsightTypeProductIssue
+	}
+	if len(issue.Fields.Components) > 0 {
+		record.Topic = issue.Fields.Components[0].Name
+	} else if len(issue.Fields.Labels) > 0 {
+		record.Topic = issue.Fields.Labels[0]
+	}
+	return record, nil
+}
@@ -0,0 +1,120 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// zendeskMaxPages bounds the pages of one pull; the rest follow on the next
+const zendeskMaxPages = 10
+
+// ZendeskSource pulls support tickets through the incremental export API
+type ZendeskSource struct {
+	baseURL string // e.g. "https://acme.zendesk.com"
+	token   string // "email/token:api-token"
+	client  *http.Client
+}
+
+// NewZendeskSource creates a source of the tickets of a Zendesk account,
+// authenticating with an API token given as "email/token:api-token"
+func NewZendeskSource(baseURL, token string) *ZendeskSource {
+	return &ZendeskSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Kind names the source
+func (z *ZendeskSource) Kind() string { return "zendesk" }
+
+type zendeskTicket struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Type        string    `json:"type"`
+	Subject     string    `json:"subject"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Priority    string    `json:"priority"`
+	Tags        []string  `json:"tags"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Fetch returns the tickets updated at or after since
+func (z *ZendeskSource) Fetch(ctx context.Context, since time.Time) ([]Record, error) {
+	next := fmt.Sprintf("%s/api/v2/incremental/tickets.json?start_time=%d", z.baseURL, since.Unix())
+	var records []Record
+	for page := 0; page < zendeskMaxPages && next != ""; page++ {
+		var body struct {
+			Tickets     []zendeskTicket `json:"tickets"`
+			NextPage    string          `json:"next_page"`
+			EndOfStream bool            `json:"end_of_stream"`
+		}
+		if err := getJSON(ctx, z.client, next, z.authorize, &body); err != nil {
+			return nil, err
+		}
+		for _, ticket := range body.Tickets {
+			records = append(records, z.record(ticket))
+		}
+		if body.EndOfStream {
+			break
+		}
+		next = body.NextPage
+	}
+	return records, nil
+}
+
+func (z *ZendeskSource) authorize(req *http.Request) {
+	if user, password, ok := strings.Cut(z.token, ":"); ok {
+		req.SetBasicAuth(user, password)
+	}
+}
+
+// record maps a ticket: problems and incidents are product issues, everything
+// else customer feedback, on the topic of the ticket's first tag
+func (z *ZendeskSource) record(ticket zendeskTicket) Record {
+	id := strconv.FormatInt(ticket.ID, 10)
+	record := Record{
+		ID:         id,
+		URL:        z.baseURL + "/agent/tickets/" + url.PathEscape(id),
+		UpdatedAt:  ticket.UpdatedAt,
+		Type:       types.InsightTypeCustomerFeedback,
+		Topic:      "customer_support",
+		Content:    joinContent(ticket.Subject, ticket.Description),
+		Confidence: 0.6,
+		Tags:       ticket.Tags,
+		Data: map[string]any{
+			"status":   ticket.Status,
+			"priority": ticket.Priority,
+		},
+	}
+	if ticket.Type == "problem" || ticket.Type == "incident" {
+		record.Type = types.InsightTypeProductIssue
+	}
+	if len(ticket.Tags) > 0 {
+		record.Topic = ticket.Tags[0]
+	}
+	if ticket.Priority == "high" || ticket.Priority == "urgent" {
+		record.Confidence = 0.8
+	}
+	return record
+}
+
+// joinContent combines a record's title and text into an insight's content
+func joinContent(title, text string) string {
+	title, text = strings.TrimSpace(title), strings.TrimSpace(text)
+	switch {
+	case title == "":
+		return text
+	case text == "":
+		return title
+	}
+	return title + ": " + text
+}
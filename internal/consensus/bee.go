@@ -13,7 +13,9 @@ import (
 // BeeConsensus implements the bee-inspired consensus mechanism
 type BeeConsensus struct {
 	proposals map[types.ProposalID]*types.Proposal
-	agents    map[types.AgentID]bool // Track active agents
+	bundles   map[types.BundleID]*types.ProposalBundle
+	agents    map[types.AgentID]string    // Active agents and their roles
+	sandboxed map[types.AgentID]time.Time // Agents on probation and when it ends
 	config    *types.Config
 	logger    *zap.Logger
 	eventChan chan ConsensusEvent
@@ -28,6 +30,7 @@ type ConsensusEvent struct {
 	Type       ConsensusEventType
 	ProposalID types.ProposalID
 	Proposal   *types.Proposal
+	Bundle     *types.ProposalBundle // Set for bundle events
 	Timestamp  time.Time
 }
 
@@ -39,15 +42,20 @@ const (
 	ConsensusEventProposalAccepted ConsensusEventType = "proposal_accepted"
 	ConsensusEventProposalRejected ConsensusEventType = "proposal_rejected"
 	ConsensusEventProposalExpired  ConsensusEventType = "proposal_expired"
+	ConsensusEventProposalExecuted ConsensusEventType = "proposal_executed"
 	ConsensusEventVoteReceived     ConsensusEventType = "vote_received"
 	ConsensusEventQuorumReached    ConsensusEventType = "quorum_reached"
+	ConsensusEventBundleCreated    ConsensusEventType = "bundle_created"
+	ConsensusEventBundleDecided    ConsensusEventType = "bundle_decided"
 )
 
 // NewBeeConsensus creates a new bee consensus manager
 func NewBeeConsensus(config *types.Config, logger *zap.Logger) *BeeConsensus {
 	return &BeeConsensus{
 		proposals: make(map[types.ProposalID]*types.Proposal),
-		agents:    make(map[types.AgentID]bool),
+		bundles:   make(map[types.BundleID]*types.ProposalBundle),
+		agents:    make(map[types.AgentID]string),
+		sandboxed: make(map[types.AgentID]time.Time),
 		config:    config,
 		logger:    logger,
 		eventChan: make(chan ConsensusEvent, 100),
@@ -80,9 +88,15 @@ func (bc *BeeConsensus) Stop() error {
 
 // RegisterAgent registers an agent for consensus participation
 func (bc *BeeConsensus) RegisterAgent(agentID types.AgentID) {
+	bc.RegisterAgentRole(agentID, "")
+}
+
+// RegisterAgentRole registers an agent with its role, which decides the
+// proposals it may vote on when they have an electorate
+func (bc *BeeConsensus) RegisterAgentRole(agentID types.AgentID, role string) {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
-	bc.agents[agentID] = true
+	bc.agents[agentID] = role
 }
 
 // UnregisterAgent removes an agent from consensus participation
@@ -90,6 +104,35 @@ func (bc *BeeConsensus) UnregisterAgent(agentID types.AgentID) {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 	delete(bc.agents, agentID)
+	delete(bc.sandboxed, agentID)
+}
+
+// SandboxAgent makes an agent's votes non-binding until its probation ends
+func (bc *BeeConsensus) SandboxAgent(agentID types.AgentID, until time.Time) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.sandboxed[agentID] = until
+}
+
+// ReleaseSandbox makes an agent's votes binding before its probation ends,
+// e.g. once an operator approved it
+func (bc *BeeConsensus) ReleaseSandbox(agentID types.AgentID) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	delete(bc.sandboxed, agentID)
+}
+
+// Sandboxed reports whether an agent's votes are currently non-binding
+func (bc *BeeConsensus) Sandboxed(agentID types.AgentID) bool {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.onProbation(agentID, time.Now())
+}
+
+// onProbation reports whether an agent is sandboxed (must be called with bc.mu held)
+func (bc *BeeConsensus) onProbation(agentID types.AgentID, now time.Time) bool {
+	until, ok := bc.sandboxed[agentID]
+	return ok && now.Before(until)
 }
 
 // GetAgentCount returns the number of active agents
@@ -99,21 +142,77 @@ func (bc *BeeConsensus) GetAgentCount() int {
 	return len(bc.agents)
 }
 
+// electorateSize returns the number of active agents whose votes on a proposal
+// are binding; sandboxed agents are left out
+func (bc *BeeConsensus) electorateSize(proposal *types.Proposal) int {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	now := time.Now()
+	size := 0
+	for id, role := range bc.agents {
+		if proposal.Eligible(role) && !bc.onProbation(id, now) {
+			size++
+		}
+	}
+	return size
+}
+
 // CreateProposal creates a new consensus proposal with waggle dance
 func (bc *BeeConsensus) CreateProposal(proposerID types.AgentID, proposalType types.ProposalType, content map[string]any) (*types.Proposal, error) {
+	return bc.Propose(&ProposalRequest{ProposerID: proposerID, Type: proposalType, Content: content})
+}
+
+// Propose creates a proposal from an instantiated request, keeping its template and waggle
+func (bc *BeeConsensus) Propose(request *ProposalRequest) (*types.Proposal, error) {
+	return bc.propose(request, "", time.Now().Add(bc.config.ProposalTimeout))
+}
+
+// propose creates a proposal, optionally as a member of a bundle
+func (bc *BeeConsensus) propose(request *ProposalRequest, bundle types.BundleID, expiresAt time.Time) (*types.Proposal, error) {
+	if request.Template != "" && request.Content == nil {
+		return nil, fmt.Errorf("proposal template %s was not instantiated", request.Template)
+	}
+
+	waggle := GenerateWaggleDance(request.Content)
+	if request.Waggle != nil {
+		waggle = *request.Waggle
+	}
+	// Voting ends when the window closes at the latest
+	if request.Window != nil && request.Window.End.Before(expiresAt) {
+		expiresAt = request.Window.End
+	}
+
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
+	if len(request.DependsOn) > 0 {
+		if bundle != "" {
+			return nil, fmt.Errorf("bundled proposals cannot depend on other proposals")
+		}
+		if err := bc.checkDependencies(request.DependsOn); err != nil {
+			return nil, err
+		}
+	}
+
 	proposal := &types.Proposal{
 		ID:         types.NewProposalID(),
-		ProposerID: proposerID,
-		Type:       proposalType,
-		Content:    content,
-		Waggle:     GenerateWaggleDance(content),
+		ProposerID: request.ProposerID,
+		Type:       request.Type,
+		Content:    request.Content,
+		Waggle:     waggle,
+		Template:   request.Template,
+		Bundle:     bundle,
+		Electorate: request.Electorate,
+		Window:     request.Window,
+		Recurrence: request.Recurrence,
+		Round:      request.Round,
+		Policy:     request.Policy,
+		DependsOn:  request.DependsOn,
 		Votes:      make(map[types.AgentID]types.Vote),
 		Status:     types.ProposalStatusPending,
 		CreatedAt:  time.Now(),
-		ExpiresAt:  time.Now().Add(bc.config.ProposalTimeout),
+		ExpiresAt:  expiresAt,
 	}
 
 	bc.proposals[proposal.ID] = proposal
@@ -127,8 +226,8 @@ func (bc *BeeConsensus) CreateProposal(proposerID types.AgentID, proposalType ty
 
 	bc.logger.Info("Proposal created",
 		zap.String("proposal_id", string(proposal.ID)),
-		zap.String("proposer_id", string(proposerID)),
-		zap.String("type", string(proposalType)),
+		zap.String("proposer_id", string(request.ProposerID)),
+		zap.String("type", string(request.Type)),
 		zap.Float64("waggle_intensity", proposal.Waggle.Intensity),
 	)
 
@@ -137,6 +236,27 @@ func (bc *BeeConsensus) CreateProposal(proposerID types.AgentID, proposalType ty
 
 // Vote submits a vote for a proposal
 func (bc *BeeConsensus) Vote(proposalID types.ProposalID, voterID types.AgentID, support bool, intensity float64) error {
+	return bc.CastVote(&VoteRequest{
+		ProposalID: proposalID,
+		VoterID:    voterID,
+		Support:    support,
+		Intensity:  intensity,
+	})
+}
+
+// CastVote submits a vote together with its optional rationale
+func (bc *BeeConsensus) CastVote(request *VoteRequest) error {
+	proposalID, voterID := request.ProposalID, request.VoterID
+	if err := types.ValidateRationale(request.Rationale, request.Factors); err != nil {
+		return err
+	}
+	if err := types.ValidateChoice(request.Choice, request.Condition); err != nil {
+		return err
+	}
+	if err := types.ValidateStake(request.Stake, request.Choice); err != nil {
+		return err
+	}
+
 	bc.mu.RLock()
 	proposal, exists := bc.proposals[proposalID]
 	bc.mu.RUnlock()
@@ -149,11 +269,29 @@ func (bc *BeeConsensus) Vote(proposalID types.ProposalID, voterID types.AgentID,
 		return fmt.Errorf("proposal %s is not pending (status: %s)", proposalID, proposal.Status)
 	}
 
+	bc.mu.RLock()
+	role := bc.agents[voterID]
+	nonBinding := bc.onProbation(voterID, time.Now())
+	bc.mu.RUnlock()
+	if !proposal.Eligible(role) {
+		return fmt.Errorf("agent %s (role %q) is not in the electorate of proposal %s", voterID, role, proposalID)
+	}
+
 	vote := types.Vote{
 		VoterID:   voterID,
-		Support:   support,
-		Intensity: intensity,
+		Support:   request.Support,
+		Intensity: request.Intensity,
 		Timestamp: time.Now(),
+		Rationale: request.Rationale,
+		Factors:   request.Factors,
+		Choice:    request.Choice,
+		Condition: request.Condition,
+
+		NonBinding: nonBinding,
+		Stake:      request.Stake,
+	}
+	if vote.Choice != "" {
+		vote.Support = vote.Choice == types.VoteChoiceSupport || vote.Choice == types.VoteChoiceConditional
 	}
 
 	proposal.AddVote(vote)
@@ -165,16 +303,19 @@ func (bc *BeeConsensus) Vote(proposalID types.ProposalID, voterID types.AgentID,
 		Timestamp:  time.Now(),
 	})
 
-	// Check if quorum reached
-	quorum := proposal.GetQuorum(bc.GetAgentCount())
-	if quorum >= bc.config.QuorumThreshold {
-		bc.finalizeProposal(proposal, types.ProposalStatusAccepted)
+	// Check if quorum reached; bundled proposals are decided with their slate,
+	// dependent ones once their prerequisites are accepted
+	quorum := proposal.GetQuorum(bc.electorateSize(proposal))
+	if proposal.Bundle != "" {
+		bc.checkBundle(proposal.Bundle, false)
+	} else if quorum >= bc.config.QuorumThreshold {
+		bc.accept(proposal)
 	}
 
 	bc.logger.Debug("Vote received",
 		zap.String("proposal_id", string(proposalID)),
 		zap.String("voter_id", string(voterID)),
-		zap.Bool("support", support),
+		zap.Bool("support", request.Support),
 		zap.Float64("quorum", quorum),
 	)
 
@@ -209,8 +350,18 @@ func (bc *BeeConsensus) GetPendingProposals() []*types.Proposal {
 
 // finalizeProposal finalizes a proposal with the given status
 func (bc *BeeConsensus) finalizeProposal(proposal *types.Proposal, status types.ProposalStatus) {
+	// Fix the outcome of conditional votes against the content being decided
+	proposal.ResolveConditions()
+
+	// A proposal accepted after its window closed can no longer execute
+	now := time.Now()
+	if status == types.ProposalStatusAccepted && proposal.Window != nil && proposal.Window.Closed(now) {
+		status = types.ProposalStatusExpired
+	}
+
 	bc.mu.Lock()
 	proposal.Status = status
+	proposal.DecidedAt = now
 	bc.mu.Unlock()
 
 	eventType := ConsensusEventProposalAccepted
@@ -241,6 +392,28 @@ func (bc *BeeConsensus) finalizeProposal(proposal *types.Proposal, status types.
 		zap.String("status", string(status)),
 		zap.Int("votes", len(proposal.Votes)),
 	)
+
+	// Accepted within its window, the proposal executes right away
+	bc.execute(proposal, now)
+	bc.SettleDependents(proposal)
+}
+
+// execute executes an accepted proposal whose window is open
+func (bc *BeeConsensus) execute(proposal *types.Proposal, now time.Time) {
+	if !proposal.Execute(now) {
+		return
+	}
+	bc.emitEvent(ConsensusEvent{
+		Type:       ConsensusEventProposalExecuted,
+		ProposalID: proposal.ID,
+		Proposal:   proposal,
+		Timestamp:  now,
+	})
+	bc.logger.Info("Proposal executed",
+		zap.String("proposal_id", string(proposal.ID)),
+		zap.Time("window_start", proposal.Window.Start),
+		zap.Time("window_end", proposal.Window.End),
+	)
 }
 
 // runExpirationLoop periodically checks for expired proposals
@@ -262,22 +435,39 @@ func (bc *BeeConsensus) runExpirationLoop(ctx context.Context) {
 	}
 }
 
-// checkExpiredProposals checks and expires pending proposals that have timed out
+// checkExpiredProposals checks and expires pending proposals that have timed
+// out, and executes accepted proposals whose window opened
 func (bc *BeeConsensus) checkExpiredProposals() {
 	bc.mu.RLock()
 	expiredProposals := []*types.Proposal{}
+	scheduled := []*types.Proposal{}
 	now := time.Now()
 
 	for _, proposal := range bc.proposals {
-		if proposal.Status == types.ProposalStatusPending && now.After(proposal.ExpiresAt) {
+		if proposal.Status == types.ProposalStatusPending && proposal.Bundle == "" && now.After(proposal.ExpiresAt) {
 			expiredProposals = append(expiredProposals, proposal)
 		}
+		if proposal.Status == types.ProposalStatusAccepted && proposal.Window != nil {
+			scheduled = append(scheduled, proposal)
+		}
+	}
+	expiredBundles := []types.BundleID{}
+	for id, bundle := range bc.bundles {
+		if bundle.Status == types.ProposalStatusPending && now.After(bundle.ExpiresAt) {
+			expiredBundles = append(expiredBundles, id)
+		}
 	}
 	bc.mu.RUnlock()
 
 	for _, proposal := range expiredProposals {
 		bc.finalizeProposal(proposal, types.ProposalStatusExpired)
 	}
+	for _, id := range expiredBundles {
+		bc.checkBundle(id, true)
+	}
+	for _, proposal := range scheduled {
+		bc.execute(proposal, now)
+	}
 }
 
 // EventChannel returns the channel for consensus events
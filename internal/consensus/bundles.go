@@ -0,0 +1,226 @@
+package consensus
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// BundleRequest is a slate of related proposals submitted together
+type BundleRequest struct {
+	ProposerID types.AgentID
+	Title      string
+	Rule       types.BundleRule
+	BestOf     int // best_of: most members accepted, 0 = 1
+	Proposals  []*ProposalRequest
+}
+
+// ProposeBundle creates a bundle and its member proposals. Members share the
+// bundle's voting window and are decided together by its rule.
+func (bc *BeeConsensus) ProposeBundle(request *BundleRequest) (*types.ProposalBundle, error) {
+	if err := types.ValidateBundle(request.Rule, request.BestOf, len(request.Proposals)); err != nil {
+		return nil, err
+	}
+	for i, member := range request.Proposals {
+		if member.Template != "" && member.Content == nil {
+			return nil, fmt.Errorf("bundle proposal %d: template %s was not instantiated", i, member.Template)
+		}
+	}
+
+	bundle := &types.ProposalBundle{
+		ID:         types.NewBundleID(),
+		ProposerID: request.ProposerID,
+		Title:      request.Title,
+		Rule:       request.Rule,
+		BestOf:     request.BestOf,
+		Status:     types.ProposalStatusPending,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(bc.config.ProposalTimeout),
+	}
+	if bundle.Rule == types.BundleRuleBestOf && bundle.BestOf == 0 {
+		bundle.BestOf = 1
+	}
+
+	for _, member := range request.Proposals {
+		proposal, err := bc.propose(member, bundle.ID, bundle.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Proposals = append(bundle.Proposals, proposal.ID)
+	}
+
+	bc.mu.Lock()
+	bc.bundles[bundle.ID] = bundle
+	bc.mu.Unlock()
+
+	bc.emitEvent(ConsensusEvent{
+		Type:      ConsensusEventBundleCreated,
+		Bundle:    bundle,
+		Timestamp: time.Now(),
+	})
+
+	bc.logger.Info("Proposal bundle created",
+		zap.String("bundle_id", string(bundle.ID)),
+		zap.String("proposer_id", string(bundle.ProposerID)),
+		zap.String("rule", string(bundle.Rule)),
+		zap.Int("proposals", len(bundle.Proposals)),
+	)
+
+	return bundle, nil
+}
+
+// VoteBundle casts the same vote on every pending member of a bundle. It fails
+// only if the vote could not be cast on any member.
+func (bc *BeeConsensus) VoteBundle(bundleID types.BundleID, request *VoteRequest) error {
+	if request.Stake > 0 {
+		return fmt.Errorf("bundle votes cannot stake, stake on each proposal instead")
+	}
+	bundle, err := bc.GetBundle(bundleID)
+	if err != nil {
+		return err
+	}
+
+	cast := 0
+	var lastErr error
+	for _, proposalID := range bundle.Proposals {
+		vote := *request
+		vote.ProposalID = proposalID
+		if err := bc.CastVote(&vote); err != nil {
+			lastErr = err
+			continue
+		}
+		cast++
+	}
+	if cast == 0 {
+		return fmt.Errorf("failed to vote on bundle %s: %w", bundleID, lastErr)
+	}
+	return nil
+}
+
+// GetBundle retrieves a bundle by ID
+func (bc *BeeConsensus) GetBundle(bundleID types.BundleID) (*types.ProposalBundle, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	bundle, exists := bc.bundles[bundleID]
+	if !exists {
+		return nil, fmt.Errorf("bundle %s not found", bundleID)
+	}
+	return bundle, nil
+}
+
+// checkBundle decides a bundle once every member reached quorum, or when it expires
+func (bc *BeeConsensus) checkBundle(bundleID types.BundleID, expired bool) {
+	bc.mu.RLock()
+	bundle, exists := bc.bundles[bundleID]
+	if !exists || bundle.Status != types.ProposalStatusPending {
+		bc.mu.RUnlock()
+		return
+	}
+	members := make([]*types.Proposal, 0, len(bundle.Proposals))
+	for _, proposalID := range bundle.Proposals {
+		if proposal, ok := bc.proposals[proposalID]; ok {
+			members = append(members, proposal)
+		}
+	}
+	bc.mu.RUnlock()
+
+	quorum := make(map[types.ProposalID]float64, len(members))
+	var reached []*types.Proposal
+	for _, proposal := range members {
+		quorum[proposal.ID] = proposal.GetQuorum(bc.electorateSize(proposal))
+		if quorum[proposal.ID] >= bc.config.QuorumThreshold {
+			reached = append(reached, proposal)
+		}
+	}
+	if !expired && len(reached) < len(members) {
+		return
+	}
+
+	decisions, status := bc.decideBundle(bundle, members, reached, quorum)
+
+	// Claim the decision so concurrent votes do not finalize members twice
+	bc.mu.Lock()
+	if bundle.Status != types.ProposalStatusPending {
+		bc.mu.Unlock()
+		return
+	}
+	bundle.Status = status
+	bundle.DecidedAt = time.Now()
+	bc.mu.Unlock()
+
+	accepted := 0
+	for _, proposal := range members {
+		bc.finalizeProposal(proposal, decisions[proposal.ID])
+		if decisions[proposal.ID] == types.ProposalStatusAccepted {
+			accepted++
+		}
+	}
+
+	bc.emitEvent(ConsensusEvent{
+		Type:      ConsensusEventBundleDecided,
+		Bundle:    bundle,
+		Timestamp: time.Now(),
+	})
+
+	bc.logger.Info("Proposal bundle decided",
+		zap.String("bundle_id", string(bundleID)),
+		zap.String("status", string(status)),
+		zap.Int("accepted", accepted),
+		zap.Int("proposals", len(members)),
+	)
+}
+
+// decideBundle applies a bundle's rule, returning each member's status and the bundle's
+func (bc *BeeConsensus) decideBundle(bundle *types.ProposalBundle, members, reached []*types.Proposal, quorum map[types.ProposalID]float64) (map[types.ProposalID]types.ProposalStatus, types.ProposalStatus) {
+	decisions := make(map[types.ProposalID]types.ProposalStatus, len(members))
+	for _, proposal := range members {
+		decisions[proposal.ID] = types.ProposalStatusExpired
+	}
+
+	if bundle.Rule == types.BundleRuleAllOrNothing {
+		if len(reached) == len(members) {
+			for _, proposal := range members {
+				decisions[proposal.ID] = types.ProposalStatusAccepted
+			}
+			return decisions, types.ProposalStatusAccepted
+		}
+		// The slate failed, so members that reached quorum are rejected with it
+		for _, proposal := range reached {
+			decisions[proposal.ID] = types.ProposalStatusRejected
+		}
+		return decisions, types.ProposalStatusExpired
+	}
+
+	// best_of: the members with the most support win, weighted by vote intensity on ties
+	sensor := NewQuorumSensor(bc.config.QuorumThreshold)
+	weighted := make(map[types.ProposalID]float64, len(reached))
+	for _, proposal := range reached {
+		weighted[proposal.ID] = sensor.CalculateWeightedQuorum(proposal, bc.electorateSize(proposal))
+	}
+	sort.SliceStable(reached, func(i, j int) bool {
+		a, b := reached[i].ID, reached[j].ID
+		if quorum[a] != quorum[b] {
+			return quorum[a] > quorum[b]
+		}
+		if weighted[a] != weighted[b] {
+			return weighted[a] > weighted[b]
+		}
+		return a < b
+	})
+
+	status := types.ProposalStatusExpired
+	for i, proposal := range reached {
+		if i < bundle.BestOf {
+			decisions[proposal.ID] = types.ProposalStatusAccepted
+			status = types.ProposalStatusAccepted
+		} else {
+			decisions[proposal.ID] = types.ProposalStatusRejected
+		}
+	}
+	return decisions, status
+}
@@ -0,0 +1,164 @@
+package consensus
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// prerequisiteState is how far the prerequisites of a proposal got
+type prerequisiteState int
+
+const (
+	prerequisitesMet     prerequisiteState = iota // All accepted
+	prerequisitesPending                          // Some still to be decided
+	prerequisitesFailed                           // One rejected, or expired for good
+)
+
+// checkDependencies validates the prerequisites of a new proposal: each must
+// be known and not have failed already (must be called with bc.mu held)
+func (bc *BeeConsensus) checkDependencies(dependsOn []types.ProposalID) error {
+	for _, id := range dependsOn {
+		if _, ok := bc.proposals[id]; !ok {
+			return fmt.Errorf("prerequisite proposal %s not found", id)
+		}
+		if latest := bc.latest(id); bc.failed(latest) {
+			return fmt.Errorf("prerequisite proposal %s is %s", id, latest.Status)
+		}
+	}
+	return nil
+}
+
+// latest follows an expired proposal through its escalations to the
+// re-proposal that stands for it now (must be called with bc.mu held)
+func (bc *BeeConsensus) latest(id types.ProposalID) *types.Proposal {
+	proposal := bc.proposals[id]
+	for proposal != nil && proposal.Status == types.ProposalStatusExpired {
+		var next *types.Proposal
+		for _, p := range bc.proposals {
+			if p.EscalatedFrom == proposal.ID {
+				next = p
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		proposal = next
+	}
+	return proposal
+}
+
+// failed reports whether a prerequisite can no longer be accepted: it was
+// rejected, or expired with no escalation left to re-propose or decide it
+// (must be called with bc.mu held)
+func (bc *BeeConsensus) failed(proposal *types.Proposal) bool {
+	switch {
+	case proposal == nil:
+		return true
+	case proposal.Status == types.ProposalStatusRejected:
+		return true
+	case proposal.Status != types.ProposalStatusExpired:
+		return false
+	case proposal.Bundle != "" || proposal.BlockedBy != "":
+		return true // Never escalated
+	}
+	_, steps := NextEscalation(bc.config.EscalationPolicies, proposal)
+	return len(steps) == 0
+}
+
+// prerequisites returns how far a proposal's prerequisites got, and the one
+// that failed if any (must be called with bc.mu held)
+func (bc *BeeConsensus) prerequisites(proposal *types.Proposal) (prerequisiteState, types.ProposalID) {
+	state := prerequisitesMet
+	for _, id := range proposal.DependsOn {
+		latest := bc.latest(id)
+		switch {
+		case latest != nil && latest.Status == types.ProposalStatusAccepted:
+		case bc.failed(latest):
+			return prerequisitesFailed, id
+		default:
+			state = prerequisitesPending
+		}
+	}
+	return state, ""
+}
+
+// accept finalizes a proposal that reached quorum once its prerequisites are
+// accepted. Until then it stays pending; if one of them fails it expires.
+func (bc *BeeConsensus) accept(proposal *types.Proposal) {
+	bc.mu.RLock()
+	state, prerequisite := bc.prerequisites(proposal)
+	bc.mu.RUnlock()
+
+	switch state {
+	case prerequisitesMet:
+		bc.finalizeProposal(proposal, types.ProposalStatusAccepted)
+	case prerequisitesFailed:
+		bc.block(proposal, prerequisite)
+	default:
+		bc.logger.Debug("Proposal reached quorum, waiting for its prerequisites",
+			zap.String("proposal_id", string(proposal.ID)),
+		)
+	}
+}
+
+// block expires a pending proposal whose prerequisite failed
+func (bc *BeeConsensus) block(proposal *types.Proposal, prerequisite types.ProposalID) {
+	bc.mu.Lock()
+	if proposal.Status != types.ProposalStatusPending {
+		bc.mu.Unlock()
+		return
+	}
+	proposal.BlockedBy = prerequisite
+	bc.mu.Unlock()
+
+	bc.logger.Info("Prerequisite failed, expiring dependent proposal",
+		zap.String("proposal_id", string(proposal.ID)),
+		zap.String("prerequisite", string(prerequisite)),
+	)
+	bc.finalizeProposal(proposal, types.ProposalStatusExpired)
+}
+
+// SettleDependents decides the pending proposals that depend on a decided
+// one, or on the proposal it re-proposes: those whose prerequisites are now
+// all accepted are accepted if they reached quorum, and those with a failed
+// prerequisite expire. The consensus manager calls it again once an expired
+// proposal's escalation ran, which may have re-proposed or decided it.
+func (bc *BeeConsensus) SettleDependents(proposal *types.Proposal) {
+	bc.mu.RLock()
+	chain := make(map[types.ProposalID]bool)
+	for p := proposal; p != nil; p = bc.proposals[p.EscalatedFrom] {
+		chain[p.ID] = true
+	}
+	var dependents []*types.Proposal
+	for _, p := range bc.proposals {
+		if p.Status != types.ProposalStatusPending {
+			continue
+		}
+		for _, id := range p.DependsOn {
+			if chain[id] {
+				dependents = append(dependents, p)
+				break
+			}
+		}
+	}
+	bc.mu.RUnlock()
+
+	for _, dependent := range dependents {
+		bc.mu.RLock()
+		state, prerequisite := bc.prerequisites(dependent)
+		bc.mu.RUnlock()
+
+		switch state {
+		case prerequisitesFailed:
+			bc.block(dependent, prerequisite)
+		case prerequisitesMet:
+			if dependent.GetQuorum(bc.electorateSize(dependent)) >= bc.config.QuorumThreshold {
+				bc.finalizeProposal(dependent, types.ProposalStatusAccepted)
+			}
+		}
+	}
+}
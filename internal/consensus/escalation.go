@@ -0,0 +1,128 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// NextEscalation returns the escalation steps to run for an expired proposal,
+// starting after the last step in its history: any notify steps plus the
+// step that follows them. It returns the chain index of the first step, and
+// no steps once the chain is exhausted.
+func NextEscalation(policies types.EscalationPolicies, proposal *types.Proposal) (int, []types.EscalationStep) {
+	chain := policies[proposal.Type]
+
+	start := 0
+	if history := proposal.EscalationHistory(); len(history) > 0 {
+		start = history[len(history)-1].Step + 1
+	}
+	if start >= len(chain) {
+		return start, nil
+	}
+
+	end := start
+	for end < len(chain) && chain[end].Action == types.EscalationNotify {
+		end++
+	}
+	if end < len(chain) {
+		end++ // Include the step that resolves this round
+	}
+	return start, chain[start:end]
+}
+
+// Repropose opens a new voting round for an expired proposal with the given
+// electorate and voting window. The new proposal keeps the escalation history.
+func (bc *BeeConsensus) Repropose(expired *types.Proposal, electorate []string, timeout time.Duration) (*types.Proposal, error) {
+	if expired.Status != types.ProposalStatusExpired {
+		return nil, fmt.Errorf("proposal %s is not expired (status: %s)", expired.ID, expired.Status)
+	}
+	expiresAt := time.Now().Add(timeout)
+	if expired.Window != nil {
+		if expired.Window.Closed(time.Now()) {
+			return nil, fmt.Errorf("window of proposal %s closed at %s", expired.ID, expired.Window.End.Format(time.RFC3339))
+		}
+		if expired.Window.End.Before(expiresAt) {
+			expiresAt = expired.Window.End
+		}
+	}
+
+	bc.mu.Lock()
+	proposal := &types.Proposal{
+		ID:            types.NewProposalID(),
+		ProposerID:    expired.ProposerID,
+		Type:          expired.Type,
+		Content:       expired.Content,
+		Waggle:        expired.Waggle,
+		Template:      expired.Template,
+		Electorate:    electorate,
+		Window:        expired.Window,
+		Recurrence:    expired.Recurrence,
+		Round:         expired.Round,
+		Policy:        expired.Policy,
+		DependsOn:     expired.DependsOn,
+		EscalatedFrom: expired.ID,
+		Escalations:   expired.EscalationHistory(),
+		Votes:         make(map[types.AgentID]types.Vote),
+		Status:        types.ProposalStatusPending,
+		CreatedAt:     time.Now(),
+		ExpiresAt:     expiresAt,
+	}
+	bc.proposals[proposal.ID] = proposal
+	bc.mu.Unlock()
+
+	bc.emitEvent(ConsensusEvent{
+		Type:       ConsensusEventProposalCreated,
+		ProposalID: proposal.ID,
+		Proposal:   proposal,
+		Timestamp:  time.Now(),
+	})
+
+	bc.logger.Info("Proposal re-proposed",
+		zap.String("proposal_id", string(proposal.ID)),
+		zap.String("escalated_from", string(expired.ID)),
+		zap.Strings("electorate", electorate),
+		zap.Duration("timeout", timeout),
+	)
+	return proposal, nil
+}
+
+// Resolve applies a default decision to an expired proposal
+func (bc *BeeConsensus) Resolve(expired *types.Proposal, decision types.ProposalStatus) error {
+	eventType := ConsensusEventProposalAccepted
+	switch decision {
+	case types.ProposalStatusAccepted:
+	case types.ProposalStatusRejected:
+		eventType = ConsensusEventProposalRejected
+	default:
+		return fmt.Errorf("default decision must be accepted or rejected, got %s", decision)
+	}
+
+	expired.ResolveConditions()
+
+	bc.mu.Lock()
+	if expired.Status != types.ProposalStatusExpired {
+		bc.mu.Unlock()
+		return fmt.Errorf("proposal %s is not expired (status: %s)", expired.ID, expired.Status)
+	}
+	expired.Status = decision
+	expired.DecidedAt = time.Now()
+	bc.mu.Unlock()
+
+	bc.emitEvent(ConsensusEvent{
+		Type:       eventType,
+		ProposalID: expired.ID,
+		Proposal:   expired,
+		Timestamp:  time.Now(),
+	})
+
+	bc.logger.Info("Default decision applied",
+		zap.String("proposal_id", string(expired.ID)),
+		zap.String("decision", string(decision)),
+	)
+	bc.SettleDependents(expired)
+	return nil
+}
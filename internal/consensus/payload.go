@@ -0,0 +1,365 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ProposalRequest is a proposal submitted over Kafka, before the engine assigns it an ID.
+// A request naming a Template carries Params instead of Type and Content until it is instantiated.
+type ProposalRequest struct {
+	ProposerID types.AgentID
+	Type       types.ProposalType
+	Content    map[string]any
+	Template   string
+	Params     map[string]any
+	Waggle     *types.WaggleDance    // Replaces the waggle derived from Content
+	Electorate []string              // Roles that may vote, empty = every agent
+	Window     *types.ProposalWindow // Time window the proposal is valid in, nil = always
+	Policy     string                // Standing policy it puts in force once accepted
+	Recurrence string                // Recurring proposal this is a round of
+	Round      int
+	DependsOn  []types.ProposalID // Prerequisites that must be accepted first
+}
+
+// VoteRequest is a vote submitted over Kafka
+type VoteRequest struct {
+	ProposalID types.ProposalID
+	BundleID   types.BundleID // Votes on every member of a bundle instead of ProposalID
+	VoterID    types.AgentID
+	Support    bool
+	Intensity  float64
+	Rationale  string
+	Factors    []types.VoteFactor
+	Choice     types.VoteChoice     // Empty = support or reject by Support
+	Condition  *types.VoteCondition // Required for conditional votes
+	Stake      float64              // Points committed to the vote, see VOTE_STAKES
+}
+
+// ParseProposalRequest validates the "proposal" object of a proposals-topic message payload.
+// Payloads come from untrusted agents, so every field is type-checked instead of asserted.
+func ParseProposalRequest(payload map[string]any) (*ProposalRequest, error) {
+	data, ok := payload["proposal"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("payload has no proposal object")
+	}
+
+	proposerID, ok := data["proposer_id"].(string)
+	if !ok || proposerID == "" {
+		return nil, fmt.Errorf("proposal has no proposer_id")
+	}
+
+	var electorate []string
+	if raw, ok := data["electorate"]; ok {
+		roles, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("proposal electorate must be a list of roles")
+		}
+		for _, r := range roles {
+			role, ok := r.(string)
+			if !ok || role == "" {
+				return nil, fmt.Errorf("proposal electorate must be a list of roles")
+			}
+			electorate = append(electorate, role)
+		}
+	}
+
+	window, err := parseProposalWindow(data["window"])
+	if err != nil {
+		return nil, err
+	}
+	policy := ""
+	if raw, ok := data["policy"]; ok {
+		if policy, ok = raw.(string); !ok || !types.ValidPolicyName(policy) {
+			return nil, fmt.Errorf("proposal policy must be a name")
+		}
+	}
+	dependsOn, err := parseDependencies(data["depends_on"])
+	if err != nil {
+		return nil, err
+	}
+
+	if template, ok := data["template"]; ok {
+		name, ok := template.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("proposal template must be a name")
+		}
+		params := map[string]any{}
+		if raw, ok := data["params"]; ok {
+			if params, ok = raw.(map[string]any); !ok {
+				return nil, fmt.Errorf("proposal params must be an object")
+			}
+		}
+		return &ProposalRequest{
+			ProposerID: types.AgentID(proposerID),
+			Template:   name,
+			Params:     params,
+			Electorate: electorate,
+			Window:     window,
+			Policy:     policy,
+			DependsOn:  dependsOn,
+		}, nil
+	}
+
+	proposalType, ok := data["type"].(string)
+	if !ok || proposalType == "" {
+		return nil, fmt.Errorf("proposal has no type")
+	}
+	content, ok := data["content"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("proposal content must be an object")
+	}
+
+	return &ProposalRequest{
+		ProposerID: types.AgentID(proposerID),
+		Type:       types.ProposalType(proposalType),
+		Content:    content,
+		Electorate: electorate,
+		Window:     window,
+		Policy:     policy,
+		DependsOn:  dependsOn,
+	}, nil
+}
+
+// parseDependencies type-checks the optional "depends_on" list of a proposal
+func parseDependencies(raw any) ([]types.ProposalID, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("proposal depends_on must be a list of proposal IDs")
+	}
+	if len(list) > types.MaxProposalDependencies {
+		return nil, fmt.Errorf("a proposal depends on at most %d proposals", types.MaxProposalDependencies)
+	}
+
+	dependsOn := make([]types.ProposalID, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		id, ok := item.(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("proposal depends_on must be a list of proposal IDs")
+		}
+		if !seen[id] {
+			seen[id] = true
+			dependsOn = append(dependsOn, types.ProposalID(id))
+		}
+	}
+	return dependsOn, nil
+}
+
+// parseProposalWindow type-checks the optional "window" of a proposal
+func parseProposalWindow(raw any) (*types.ProposalWindow, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	fields, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("proposal window must be an object")
+	}
+	start, _ := fields["start"].(string)
+	end, _ := fields["end"].(string)
+	window, err := types.ParseProposalWindow(start, end, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposal window: %w", err)
+	}
+	return window, nil
+}
+
+// ParseBundleRequest validates the "bundle" object of a proposals-topic message payload.
+// Each member is parsed like a proposal and inherits the bundle's proposer and electorate.
+func ParseBundleRequest(payload map[string]any) (*BundleRequest, error) {
+	data, ok := payload["bundle"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("payload has no bundle object")
+	}
+
+	proposerID, ok := data["proposer_id"].(string)
+	if !ok || proposerID == "" {
+		return nil, fmt.Errorf("bundle has no proposer_id")
+	}
+	title := ""
+	if raw, ok := data["title"]; ok {
+		if title, ok = raw.(string); !ok {
+			return nil, fmt.Errorf("bundle title must be a string")
+		}
+	}
+	rule, ok := data["rule"].(string)
+	if !ok {
+		return nil, fmt.Errorf("bundle has no rule")
+	}
+	bestOf := 0
+	if raw, ok := data["best_of"]; ok {
+		n, ok := raw.(float64)
+		if !ok || n != float64(int(n)) {
+			return nil, fmt.Errorf("bundle best_of must be an integer")
+		}
+		bestOf = int(n)
+	}
+
+	items, ok := data["proposals"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("bundle proposals must be a list")
+	}
+	if err := types.ValidateBundle(types.BundleRule(rule), bestOf, len(items)); err != nil {
+		return nil, err
+	}
+
+	request := &BundleRequest{
+		ProposerID: types.AgentID(proposerID),
+		Title:      title,
+		Rule:       types.BundleRule(rule),
+		BestOf:     bestOf,
+	}
+	for i, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("bundle proposal %d must be an object", i)
+		}
+		member := map[string]any{"proposer_id": proposerID}
+		if electorate, ok := data["electorate"]; ok {
+			member["electorate"] = electorate
+		}
+		for k, v := range fields {
+			member[k] = v
+		}
+		proposal, err := ParseProposalRequest(map[string]any{"proposal": member})
+		if err != nil {
+			return nil, fmt.Errorf("bundle proposal %d: %w", i, err)
+		}
+		request.Proposals = append(request.Proposals, proposal)
+	}
+	return request, nil
+}
+
+// ParseVoteRequest validates the "vote" object of a votes-topic message payload
+func ParseVoteRequest(payload map[string]any) (*VoteRequest, error) {
+	data, ok := payload["vote"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("payload has no vote object")
+	}
+
+	proposalID, _ := data["proposal_id"].(string)
+	bundleID, _ := data["bundle_id"].(string)
+	if (proposalID == "") == (bundleID == "") {
+		return nil, fmt.Errorf("vote needs exactly one of proposal_id or bundle_id")
+	}
+	voterID, ok := data["voter_id"].(string)
+	if !ok || voterID == "" {
+		return nil, fmt.Errorf("vote has no voter_id")
+	}
+	choice, condition, err := parseVoteChoice(data)
+	if err != nil {
+		return nil, err
+	}
+	support, ok := data["support"].(bool)
+	if choice != "" {
+		support = choice == types.VoteChoiceSupport || choice == types.VoteChoiceConditional
+	} else if !ok {
+		return nil, fmt.Errorf("vote support must be a boolean")
+	}
+	intensity, ok := data["intensity"].(float64)
+	if !ok && choice != types.VoteChoiceAbstain {
+		return nil, fmt.Errorf("vote intensity must be a number")
+	}
+	if intensity < 0 || intensity > 1 {
+		return nil, fmt.Errorf("vote intensity %f out of range [0, 1]", intensity)
+	}
+
+	rationale := ""
+	if raw, ok := data["rationale"]; ok {
+		if rationale, ok = raw.(string); !ok {
+			return nil, fmt.Errorf("vote rationale must be a string")
+		}
+	}
+	factors, err := parseVoteFactors(data["factors"])
+	if err != nil {
+		return nil, err
+	}
+	if err := types.ValidateRationale(rationale, factors); err != nil {
+		return nil, err
+	}
+	stake := 0.0
+	if raw, ok := data["stake"]; ok {
+		if stake, ok = raw.(float64); !ok {
+			return nil, fmt.Errorf("vote stake must be a number")
+		}
+		if err := types.ValidateStake(stake, choice); err != nil {
+			return nil, err
+		}
+		if stake > 0 && bundleID != "" {
+			return nil, fmt.Errorf("bundle votes cannot stake, stake on each proposal instead")
+		}
+	}
+
+	return &VoteRequest{
+		ProposalID: types.ProposalID(proposalID),
+		BundleID:   types.BundleID(bundleID),
+		VoterID:    types.AgentID(voterID),
+		Support:    support,
+		Intensity:  intensity,
+		Rationale:  rationale,
+		Factors:    factors,
+		Choice:     choice,
+		Condition:  condition,
+		Stake:      stake,
+	}, nil
+}
+
+// parseVoteChoice type-checks the optional "choice" and "condition" of a vote
+func parseVoteChoice(data map[string]any) (types.VoteChoice, *types.VoteCondition, error) {
+	var choice types.VoteChoice
+	if raw, ok := data["choice"]; ok {
+		name, ok := raw.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("vote choice must be a string")
+		}
+		choice = types.VoteChoice(name)
+	}
+
+	var condition *types.VoteCondition
+	if raw, ok := data["condition"]; ok {
+		fields, ok := raw.(map[string]any)
+		if !ok {
+			return "", nil, fmt.Errorf("vote condition must be an object")
+		}
+		condition = &types.VoteCondition{Value: fields["value"]}
+		condition.Field, _ = fields["field"].(string)
+		condition.Operator, _ = fields["operator"].(string)
+	}
+
+	if err := types.ValidateChoice(choice, condition); err != nil {
+		return "", nil, err
+	}
+	return choice, condition, nil
+}
+
+// parseVoteFactors type-checks the optional "factors" list of a vote
+func parseVoteFactors(raw any) ([]types.VoteFactor, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("vote factors must be a list")
+	}
+
+	factors := make([]types.VoteFactor, 0, len(list))
+	for i, item := range list {
+		data, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("vote factor %d must be an object", i)
+		}
+		name, _ := data["name"].(string)
+		weight, ok := data["weight"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("vote factor %d weight must be a number", i)
+		}
+		detail, _ := data["detail"].(string)
+		factors = append(factors, types.VoteFactor{Name: name, Weight: weight, Value: data["value"], Detail: detail})
+	}
+	return factors, nil
+}
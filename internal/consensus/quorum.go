@@ -33,10 +33,13 @@ func (qs *QuorumSensor) CalculateWeightedQuorum(proposal *types.Proposal, totalA
 	var supportWeight float64
 
 	for _, vote := range proposal.Votes {
+		if vote.NonBinding || vote.EffectiveChoice() == types.VoteChoiceAbstain {
+			continue // Abstentions take no side, sandboxed agents do not count
+		}
 		weight := vote.Intensity // Use intensity as weight
 		totalWeight += weight
 
-		if vote.Support {
+		if proposal.InFavor(vote) {
 			supportWeight += weight
 		}
 	}
@@ -85,10 +88,18 @@ func (qs *QuorumSensor) GetQuorumStatus(proposal *types.Proposal, totalAgents in
 
 	supportCount := 0
 	rejectCount := 0
+	abstainCount := 0
 	avgIntensity := 0.0
 
 	for _, vote := range proposal.Votes {
-		if vote.Support {
+		if vote.NonBinding {
+			continue
+		}
+		if vote.EffectiveChoice() == types.VoteChoiceAbstain {
+			abstainCount++
+			continue
+		}
+		if proposal.InFavor(vote) {
 			supportCount++
 		} else {
 			rejectCount++
@@ -96,8 +107,8 @@ func (qs *QuorumSensor) GetQuorumStatus(proposal *types.Proposal, totalAgents in
 		avgIntensity += vote.Intensity
 	}
 
-	if len(proposal.Votes) > 0 {
-		avgIntensity /= float64(len(proposal.Votes))
+	if sided := supportCount + rejectCount; sided > 0 {
+		avgIntensity /= float64(sided)
 	}
 
 	return QuorumStatus{
@@ -106,6 +117,7 @@ func (qs *QuorumSensor) GetQuorumStatus(proposal *types.Proposal, totalAgents in
 		RequiredQuorum:   qs.threshold,
 		SupportCount:     supportCount,
 		RejectCount:      rejectCount,
+		AbstainCount:     abstainCount,
 		TotalVotes:       len(proposal.Votes),
 		TotalAgents:      totalAgents,
 		AverageIntensity: avgIntensity,
@@ -119,6 +131,7 @@ type QuorumStatus struct {
 	RequiredQuorum   float64 `json:"required_quorum"`
 	SupportCount     int     `json:"support_count"`
 	RejectCount      int     `json:"reject_count"`
+	AbstainCount     int     `json:"abstain_count"`
 	TotalVotes       int     `json:"total_votes"`
 	TotalAgents      int     `json:"total_agents"`
 	AverageIntensity float64 `json:"average_intensity"`
@@ -134,7 +147,7 @@ func (qs *QuorumSensor) IsStrongQuorum(proposal *types.Proposal, totalAgents int
 	// Check if supporting votes have high enough intensity
 	strongVotes := 0
 	for _, vote := range proposal.Votes {
-		if vote.Support && vote.Intensity >= minIntensity {
+		if proposal.InFavor(vote) && vote.Intensity >= minIntensity {
 			strongVotes++
 		}
 	}
@@ -155,7 +168,10 @@ func (qs *QuorumSensor) DetectConsensusPattern(proposal *types.Proposal) Consens
 	avgRejectIntensity := 0.0
 
 	for _, vote := range proposal.Votes {
-		if vote.Support {
+		if vote.NonBinding || vote.EffectiveChoice() == types.VoteChoiceAbstain {
+			continue
+		}
+		if proposal.InFavor(vote) {
 			supportCount++
 			avgSupportIntensity += vote.Intensity
 		} else {
@@ -164,6 +180,9 @@ func (qs *QuorumSensor) DetectConsensusPattern(proposal *types.Proposal) Consens
 		}
 	}
 
+	if supportCount+rejectCount == 0 {
+		return ConsensusPatternUnknown // Only abstentions
+	}
 	if supportCount > 0 {
 		avgSupportIntensity /= float64(supportCount)
 	}
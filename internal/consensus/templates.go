@@ -0,0 +1,138 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TemplateStore loads registered proposal templates (implemented by state.RedisStore)
+type TemplateStore interface {
+	LoadProposalTemplate(ctx context.Context, name string) (*types.ProposalTemplate, error)
+	ListProposalTemplates(ctx context.Context) ([]*types.ProposalTemplate, error)
+}
+
+// BuiltinTemplates returns the templates available without registering them
+func BuiltinTemplates() []*types.ProposalTemplate {
+	return []*types.ProposalTemplate{
+		{
+			Name:        "large_order_approval",
+			Description: "Approve an order above the automatic approval limit",
+			Type:        types.ProposalTypeDecision,
+			Content: map[string]any{
+				"action":      "approve_order",
+				"order_id":    "{{order_id}}",
+				"amount":      "{{amount}}",
+				"customer_id": "{{customer_id}}",
+				"priority":    "{{priority}}",
+				"description": "Approve order {{order_id}} of ${{amount}}",
+			},
+			Params: []types.TemplateParam{
+				{Name: "order_id", Type: types.TemplateParamString, Required: true},
+				{Name: "amount", Type: types.TemplateParamNumber, Required: true, Description: "Order total in dollars"},
+				{Name: "customer_id", Type: types.TemplateParamString, Default: ""},
+				{Name: "priority", Type: types.TemplateParamString, Default: "high"},
+			},
+		},
+		{
+			Name:        "price_change",
+			Description: "Change the price of a product",
+			Type:        types.ProposalTypeAction,
+			Content: map[string]any{
+				"action":      "change_price",
+				"product":     "{{product}}",
+				"old_price":   "{{old_price}}",
+				"new_price":   "{{new_price}}",
+				"reason":      "{{reason}}",
+				"priority":    "medium",
+				"description": "Change the price of {{product}} from ${{old_price}} to ${{new_price}}",
+			},
+			Params: []types.TemplateParam{
+				{Name: "product", Type: types.TemplateParamString, Required: true},
+				{Name: "old_price", Type: types.TemplateParamNumber, Required: true},
+				{Name: "new_price", Type: types.TemplateParamNumber, Required: true},
+				{Name: "reason", Type: types.TemplateParamString, Default: "market adjustment"},
+			},
+			Waggle: &types.WaggleDance{Intensity: 0.6, Duration: 600, Angle: 90, Repetitions: 6},
+		},
+		{
+			Name:        types.InsightVerificationTemplate,
+			Description: "Verify an insight before agents act on it",
+			Type:        types.ProposalTypeDecision,
+			Content: map[string]any{
+				"action":       "verify_insight",
+				"insight_id":   "{{insight_id}}",
+				"insight_type": "{{insight_type}}",
+				"agent_id":     "{{agent_id}}",
+				"topic":        "{{topic}}",
+				"summary":      "{{summary}}",
+				"confidence":   "{{confidence}}",
+				"priority":     "high",
+				"description":  "Verify {{insight_type}} insight from {{agent_id}}: {{summary}}",
+			},
+			Params: []types.TemplateParam{
+				{Name: "insight_id", Type: types.TemplateParamString, Required: true},
+				{Name: "insight_type", Type: types.TemplateParamString, Required: true},
+				{Name: "agent_id", Type: types.TemplateParamString, Required: true},
+				{Name: "topic", Type: types.TemplateParamString, Default: ""},
+				{Name: "summary", Type: types.TemplateParamString, Default: ""},
+				{Name: "confidence", Type: types.TemplateParamNumber, Default: 0.0},
+			},
+		},
+	}
+}
+
+// LookupTemplate returns a registered template, falling back to the built-in ones
+func LookupTemplate(ctx context.Context, store TemplateStore, name string) (*types.ProposalTemplate, error) {
+	if store != nil {
+		if template, err := store.LoadProposalTemplate(ctx, name); err == nil {
+			return template, nil
+		}
+	}
+	for _, template := range BuiltinTemplates() {
+		if template.Name == name {
+			return template, nil
+		}
+	}
+	return nil, fmt.Errorf("proposal template %q not found", name)
+}
+
+// ListTemplates returns the registered and built-in templates by name;
+// registered templates replace built-in ones of the same name
+func ListTemplates(ctx context.Context, store TemplateStore) ([]*types.ProposalTemplate, error) {
+	byName := make(map[string]*types.ProposalTemplate)
+	for _, template := range BuiltinTemplates() {
+		byName[template.Name] = template
+	}
+	registered, err := store.ListProposalTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range registered {
+		byName[template.Name] = template
+	}
+
+	templates := make([]*types.ProposalTemplate, 0, len(byName))
+	for _, template := range byName {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Instantiate renders a template into a proposal request
+func Instantiate(template *types.ProposalTemplate, proposerID types.AgentID, params map[string]any) (*ProposalRequest, error) {
+	content, err := template.Render(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", template.Name, err)
+	}
+	return &ProposalRequest{
+		ProposerID: proposerID,
+		Type:       template.Type,
+		Content:    content,
+		Template:   template.Name,
+		Waggle:     template.Waggle,
+	}, nil
+}
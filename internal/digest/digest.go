@@ -0,0 +1,174 @@
+// Package digest compiles periodic reports of mesh activity.
+//
+// A digest covers one period and lists the top ranked insights, newly detected
+// patterns, accepted proposals and how the topology changed. Compile builds the
+// structured report; Markdown renders it for humans.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/ranking"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Inputs is the raw material for a digest; entries outside the period are ignored
+type Inputs struct {
+	Insights  []*types.Insight       // All known insights, also the ranking corpus
+	Patterns  []types.Pattern        // Detected patterns
+	Proposals []*types.Proposal      // Known proposals
+	Snapshots []*types.GraphSnapshot // Topology snapshots of the period, oldest first
+}
+
+// Compile builds the digest for the period ending at end
+func Compile(config *types.Config, start, end time.Time, in Inputs) *types.Digest {
+	digest := &types.Digest{
+		ID:                types.NewDigestID(end),
+		PeriodStart:       start,
+		PeriodEnd:         end,
+		TopInsights:       []types.Insight{},
+		NewPatterns:       []types.Pattern{},
+		AcceptedProposals: []*types.Proposal{},
+		GeneratedAt:       time.Now(),
+	}
+
+	for _, insight := range in.Insights {
+		if within(insight.CreatedAt, start, end) && insight.Type != types.InsightTypeGoalProgress {
+			digest.TopInsights = append(digest.TopInsights, *insight)
+		}
+	}
+	digest.InsightCount = len(digest.TopInsights)
+	ranking.NewRanker(config, in.Insights).Sort(digest.TopInsights, end)
+	if limit := config.DigestTopInsights; limit > 0 && len(digest.TopInsights) > limit {
+		digest.TopInsights = digest.TopInsights[:limit]
+	}
+
+	for _, pattern := range in.Patterns {
+		if within(pattern.DetectedAt, start, end) {
+			digest.NewPatterns = append(digest.NewPatterns, pattern)
+		}
+	}
+	sort.Slice(digest.NewPatterns, func(i, j int) bool {
+		return digest.NewPatterns[i].Frequency > digest.NewPatterns[j].Frequency
+	})
+
+	for _, proposal := range in.Proposals {
+		if proposal.Status == types.ProposalStatusAccepted && within(proposal.CreatedAt, start, end) {
+			digest.AcceptedProposals = append(digest.AcceptedProposals, proposal)
+		}
+	}
+	sort.Slice(digest.AcceptedProposals, func(i, j int) bool {
+		return digest.AcceptedProposals[i].CreatedAt.Before(digest.AcceptedProposals[j].CreatedAt)
+	})
+
+	digest.Topology = topologyChanges(in.Snapshots)
+	return digest
+}
+
+// within reports whether t falls in [start, end]
+func within(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
+
+// topologyChanges compares the first and last snapshot
+func topologyChanges(snapshots []*types.GraphSnapshot) types.TopologyChanges {
+	changes := types.TopologyChanges{
+		Snapshots:    len(snapshots),
+		AgentsJoined: []types.AgentID{},
+		AgentsLeft:   []types.AgentID{},
+	}
+	if len(snapshots) == 0 {
+		return changes
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	changes.Start = first.Stats
+	changes.End = last.Stats
+
+	for id := range last.Agents {
+		if _, ok := first.Agents[id]; !ok {
+			changes.AgentsJoined = append(changes.AgentsJoined, id)
+		}
+	}
+	for id := range first.Agents {
+		if _, ok := last.Agents[id]; !ok {
+			changes.AgentsLeft = append(changes.AgentsLeft, id)
+		}
+	}
+	sort.Slice(changes.AgentsJoined, func(i, j int) bool { return changes.AgentsJoined[i] < changes.AgentsJoined[j] })
+	sort.Slice(changes.AgentsLeft, func(i, j int) bool { return changes.AgentsLeft[i] < changes.AgentsLeft[j] })
+
+	for id := range last.Edges {
+		if _, ok := first.Edges[id]; !ok {
+			changes.EdgesFormed++
+		}
+	}
+	for id := range first.Edges {
+		if _, ok := last.Edges[id]; !ok {
+			changes.EdgesPruned++
+		}
+	}
+	return changes
+}
+
+// Markdown renders a digest as a Markdown report
+func Markdown(d *types.Digest) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# AgentMesh Digest\n\n")
+	fmt.Fprintf(&b, "%s to %s\n\n", d.PeriodStart.UTC().Format(time.RFC3339), d.PeriodEnd.UTC().Format(time.RFC3339))
+
+	fmt.Fprintf(&b, "## Top Insights\n\n")
+	if len(d.TopInsights) == 0 {
+		fmt.Fprintf(&b, "No insights in this period.\n\n")
+	} else {
+		fmt.Fprintf(&b, "%d insights shared; the most important:\n\n", d.InsightCount)
+		for i, insight := range d.TopInsights {
+			score := 0.0
+			if insight.Rank != nil {
+				score = insight.Rank.Score
+			}
+			fmt.Fprintf(&b, "%d. **%s** (%s, %s) %s _score %.2f_\n",
+				i+1, insight.Topic, insight.AgentRole, insight.Type, insight.Content, score)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	fmt.Fprintf(&b, "## New Patterns\n\n")
+	if len(d.NewPatterns) == 0 {
+		fmt.Fprintf(&b, "No new patterns.\n\n")
+	} else {
+		for _, pattern := range d.NewPatterns {
+			fmt.Fprintf(&b, "- %s (%s, seen %d times)\n", pattern.Description, pattern.Type, pattern.Frequency)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	fmt.Fprintf(&b, "## Accepted Proposals\n\n")
+	if len(d.AcceptedProposals) == 0 {
+		fmt.Fprintf(&b, "No proposals accepted.\n\n")
+	} else {
+		for _, proposal := range d.AcceptedProposals {
+			fmt.Fprintf(&b, "- `%s` %s proposal by %s with %d votes\n",
+				proposal.ID, proposal.Type, proposal.ProposerID, len(proposal.Votes))
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	t := d.Topology
+	fmt.Fprintf(&b, "## Topology\n\n")
+	if t.Snapshots == 0 {
+		fmt.Fprintf(&b, "No topology snapshots in this period.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "| | Start | End |\n|---|---|---|\n")
+	fmt.Fprintf(&b, "| Agents | %d | %d |\n", t.Start.TotalAgents, t.End.TotalAgents)
+	fmt.Fprintf(&b, "| Edges | %d | %d |\n", t.Start.TotalEdges, t.End.TotalEdges)
+	fmt.Fprintf(&b, "| Density | %.2f | %.2f |\n\n", t.Start.Density, t.End.Density)
+	fmt.Fprintf(&b, "%d agents joined, %d left; %d edges formed, %d pruned.\n",
+		len(t.AgentsJoined), len(t.AgentsLeft), t.EdgesFormed, t.EdgesPruned)
+	return b.String()
+}
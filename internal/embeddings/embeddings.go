@@ -0,0 +1,150 @@
+// Package embeddings turns insight text into vectors for semantic search.
+//
+// An Embedder sits in front of an embedding Provider. It looks every text up
+// in a Cache keyed by model and content hash first, so repeated content is
+// embedded once, and sends the rest to the provider in batches of up to
+// EMBEDDING_BATCH_SIZE texts. Changing the provider or model changes the cache
+// key, and Reembed re-embeds the stored insights under the new one.
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Provider embeds texts with one model
+type Provider interface {
+	// Model names the provider and model, e.g. "api.openai.com/text-embedding-3-small";
+	// vectors of different models are never mixed
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Cache stores vectors by model and content hash
+type Cache interface {
+	GetEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error)
+	SaveEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error
+}
+
+// Stats counts the texts an embedder served from the cache and from the provider
+type Stats struct {
+	CacheHits int64 `json:"cache_hits"`
+	Embedded  int64 `json:"embedded"`
+	Requests  int64 `json:"requests"` // Batches sent to the provider
+}
+
+// Embedder embeds texts through the cache and batched provider requests
+type Embedder struct {
+	provider  Provider
+	cache     Cache
+	batchSize int
+
+	hits, embedded, requests atomic.Int64
+}
+
+// NewEmbedder creates an embedder sending at most batchSize texts per provider request
+func NewEmbedder(provider Provider, cache Cache, batchSize int) *Embedder {
+	return &Embedder{provider: provider, cache: cache, batchSize: max(batchSize, 1)}
+}
+
+// Model names the provider and model texts are embedded with
+func (e *Embedder) Model() string {
+	return e.provider.Model()
+}
+
+// Stats returns the embedder's counters
+func (e *Embedder) Stats() Stats {
+	return Stats{CacheHits: e.hits.Load(), Embedded: e.embedded.Load(), Requests: e.requests.Load()}
+}
+
+// Embed returns one vector per text, in order. Cached texts and repeats
+// within texts are not sent to the provider again.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := e.provider.Model()
+	hashes := make([]string, len(texts))
+	unique := make([]string, 0, len(texts))
+	seen := make(map[string]bool, len(texts))
+	for i, text := range texts {
+		hashes[i] = types.ContentHash(text)
+		if !seen[hashes[i]] {
+			seen[hashes[i]] = true
+			unique = append(unique, hashes[i])
+		}
+	}
+
+	vectors, err := e.cache.GetEmbeddings(ctx, model, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+	if vectors == nil {
+		vectors = make(map[string][]float32)
+	}
+	e.hits.Add(int64(len(vectors)))
+
+	// Texts of the hashes missing from the cache, each once
+	var missing []string
+	var missingHashes []string
+	for i, hash := range hashes {
+		if _, ok := vectors[hash]; !ok && seen[hash] {
+			seen[hash] = false
+			missing = append(missing, texts[i])
+			missingHashes = append(missingHashes, hash)
+		}
+	}
+
+	for start := 0; start < len(missing); start += e.batchSize {
+		end := min(start+e.batchSize, len(missing))
+		e.requests.Add(1)
+		batch, err := e.provider.Embed(ctx, missing[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %d texts: %w", end-start, err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("provider returned %d vectors for %d texts", len(batch), end-start)
+		}
+
+		embedded := make(map[string][]float32, len(batch))
+		for i, vector := range batch {
+			embedded[missingHashes[start+i]] = vector
+			vectors[missingHashes[start+i]] = vector
+		}
+		if err := e.cache.SaveEmbeddings(ctx, model, embedded); err != nil {
+			return nil, fmt.Errorf("failed to save embeddings: %w", err)
+		}
+		e.embedded.Add(int64(len(batch)))
+	}
+
+	result := make([][]float32, len(texts))
+	for i, hash := range hashes {
+		result[i] = vectors[hash]
+	}
+	return result, nil
+}
+
+// Reembed embeds the stored insights with the embedder's model, a batch at a
+// time, reporting the insights done after each batch. Insights already in the
+// cache under the model are skipped, so a migration that stopped resumes
+// where it left off.
+func (e *Embedder) Reembed(ctx context.Context, insights []*types.Insight, progress func(done int)) error {
+	for start := 0; start < len(insights); start += e.batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+e.batchSize, len(insights))
+		texts := make([]string, 0, end-start)
+		for _, insight := range insights[start:end] {
+			texts = append(texts, insight.EmbeddingText())
+		}
+		if _, err := e.Embed(ctx, texts); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(end)
+		}
+	}
+	return nil
+}
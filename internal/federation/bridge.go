@@ -0,0 +1,116 @@
+// Package federation links the meshes of a multi-region deployment.
+//
+// Each region runs its own Kafka cluster and its own mesh services. The Bridge
+// runs next to one region's mesh and replicates its high-value insights (the
+// insights its knowledge manager pushes with a rank score of at least
+// FederationMinScore) into the insight topic of every other region, where the
+// knowledge manager there ingests them like local ones. Replicated insights
+// keep their origin region, so they are never replicated again.
+package federation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Bridge replicates high-value insights from the local region to its peers
+type Bridge struct {
+	local  *messaging.KafkaMessaging
+	peers  map[string]*messaging.KafkaMessaging
+	config *types.Config
+	logger *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBridge creates a bridge from the local region to cfg.FederationPeers
+func NewBridge(local *messaging.KafkaMessaging, cfg *types.Config, logger *zap.Logger) *Bridge {
+	peers := make(map[string]*messaging.KafkaMessaging, len(cfg.FederationPeers))
+	for region, brokers := range cfg.FederationPeers {
+		if region == cfg.Region {
+			continue
+		}
+		peerConfig := *cfg
+		peerConfig.KafkaBrokers = brokers
+		peers[region] = messaging.NewKafkaMessaging(&peerConfig, logger)
+	}
+	return &Bridge{
+		local:  local,
+		peers:  peers,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Start starts replicating pushed insights
+func (b *Bridge) Start(ctx context.Context) error {
+	if b.config.Region == "" {
+		return fmt.Errorf("MESH_REGION is required to federate")
+	}
+	if len(b.peers) == 0 {
+		return fmt.Errorf("no federation peers configured")
+	}
+
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.replicateInsights(ctx)
+	}()
+
+	b.logger.Info("Federation bridge started",
+		zap.String("region", b.config.Region),
+		zap.Int("peers", len(b.peers)),
+		zap.Float64("min_score", b.config.FederationMinScore),
+	)
+	return nil
+}
+
+// Stop stops replicating and closes the peer connections
+func (b *Bridge) Stop() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+	for _, peer := range b.peers {
+		peer.Close()
+	}
+	return nil
+}
+
+// replicateInsights consumes the local insight pushes and forwards the replicable ones
+func (b *Bridge) replicateInsights(ctx context.Context) {
+	err := b.local.ConsumeMessages(ctx, "insights-push", "federation-bridge", func(ctx context.Context, msg *types.Message) error {
+		insight, err := types.PushedInsight(msg)
+		if err != nil || !types.Replicable(msg, insight, b.config) {
+			return nil
+		}
+
+		// Peers rank the insight against their own corpus
+		insight.Rank = nil
+		for region, peer := range b.peers {
+			if err := peer.PublishInsight(ctx, insight); err != nil {
+				b.logger.Error("Failed to replicate insight",
+					zap.String("insight_id", string(insight.ID)),
+					zap.String("region", region),
+					zap.Error(err))
+				continue
+			}
+			b.logger.Debug("Replicated insight",
+				zap.String("insight_id", string(insight.ID)),
+				zap.String("region", region))
+		}
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		b.logger.Error("Insight replication stopped", zap.Error(err))
+	}
+}
@@ -0,0 +1,76 @@
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ErrUnavailable is returned by Takeover when no state could be obtained from the
+// predecessor; callers usually start without it
+var ErrUnavailable = errors.New("predecessor unavailable")
+
+// Takeover pulls state from the instance at addr, imports it, runs start and then
+// tells the old instance to step down. If import or start fails, the old instance
+// is told to resume and the error is returned; errors after start are only logged.
+func Takeover(ctx context.Context, addr, manager string, importer Importer, start func() error, logger *zap.Logger) error {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: failed to connect to %s: %v", ErrUnavailable, addr, err)
+	}
+	defer conn.Close()
+
+	requester, _ := os.Hostname()
+	requester = fmt.Sprintf("%s/%d", requester, os.Getpid())
+
+	resp := new(TransferResponse)
+	err = conn.Invoke(ctx, "/"+serviceName+"/Transfer", &TransferRequest{
+		Manager:         manager,
+		Requester:       requester,
+		ProtocolVersion: types.ProtocolVersion,
+	}, resp)
+	if err != nil {
+		return fmt.Errorf("%w: failed to request state from %s: %v", ErrUnavailable, addr, err)
+	}
+
+	logger.Info("Received state from predecessor",
+		zap.String("addr", addr),
+		zap.String("manager", manager),
+		zap.Int("bytes", len(resp.State)),
+		zap.Time("exported_at", resp.ExportedAt),
+	)
+
+	abort := func(cause error) error {
+		req := &CompleteRequest{Manager: manager, Requester: requester, Aborted: true, Reason: cause.Error()}
+		if err := conn.Invoke(ctx, "/"+serviceName+"/Complete", req, new(CompleteResponse)); err != nil {
+			logger.Warn("Failed to abort handoff, predecessor will resume after timeout", zap.Error(err))
+		}
+		return cause
+	}
+
+	if err := importer.ImportState(resp.State); err != nil {
+		return abort(fmt.Errorf("failed to import state: %w", err))
+	}
+	if err := start(); err != nil {
+		return abort(fmt.Errorf("failed to start after import: %w", err))
+	}
+
+	err = conn.Invoke(ctx, "/"+serviceName+"/Complete", &CompleteRequest{Manager: manager, Requester: requester}, new(CompleteResponse))
+	if err != nil {
+		// We are already running; the predecessor resumes after its timeout and
+		// both instances share the consumer group, so nothing is lost
+		logger.Warn("Failed to confirm handoff, predecessor will resume", zap.Error(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Server exposes a manager's state to a successor instance
+type Server struct {
+	manager         string
+	provider        Provider
+	completeTimeout time.Duration
+	logger          *zap.Logger
+
+	grpcServer *grpc.Server
+	done       chan struct{}
+
+	mu        sync.Mutex
+	exporting bool
+	timer     *time.Timer
+}
+
+// NewServer creates a handoff server for the named manager ("topology", "knowledge")
+func NewServer(manager string, provider Provider, logger *zap.Logger) *Server {
+	return &Server{
+		manager:         manager,
+		provider:        provider,
+		completeTimeout: DefaultCompleteTimeout,
+		logger:          logger.With(zap.String("component", "handoff"), zap.String("manager", manager)),
+		done:            make(chan struct{}),
+	}
+}
+
+// Serve listens on addr and serves handoff requests until Stop is called
+func (s *Server) Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.grpcServer = grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	s.grpcServer.RegisterService(&serviceDesc, s)
+
+	s.logger.Info("Handoff server listening", zap.String("addr", listener.Addr().String()))
+	return s.grpcServer.Serve(listener)
+}
+
+// Stop stops serving
+func (s *Server) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
+}
+
+// Done is closed once a successor has taken over; the process should exit
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Transfer freezes the manager and returns its state
+func (s *Server) Transfer(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	if req.Manager != s.manager {
+		return nil, status.Errorf(codes.InvalidArgument, "this instance runs the %s manager, not %s", s.manager, req.Manager)
+	}
+	if types.CheckProtocol(req.ProtocolVersion, 0) == types.CompatibilityUnsupported {
+		return nil, status.Errorf(codes.FailedPrecondition, "protocol version %d is not supported", req.ProtocolVersion)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil, status.Error(codes.FailedPrecondition, "state was already handed off")
+	default:
+	}
+	if s.exporting {
+		return nil, status.Error(codes.FailedPrecondition, "a handoff is already in progress")
+	}
+
+	state, err := s.provider.ExportState(ctx)
+	if err != nil {
+		// ExportState may have stopped listeners before failing
+		s.resume("export failed")
+		return nil, status.Errorf(codes.Internal, "failed to export state: %v", err)
+	}
+
+	s.exporting = true
+	s.timer = time.AfterFunc(s.completeTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.exporting {
+			s.exporting = false
+			s.resume("successor did not complete in time")
+		}
+	})
+
+	s.logger.Info("State exported to successor",
+		zap.String("requester", req.Requester),
+		zap.Int("bytes", len(state)),
+	)
+
+	return &TransferResponse{
+		Manager:         s.manager,
+		State:           state,
+		ExportedAt:      time.Now(),
+		ProtocolVersion: types.ProtocolVersion,
+	}, nil
+}
+
+// Complete finishes a transfer: on success the instance steps down, on abort it resumes
+func (s *Server) Complete(ctx context.Context, req *CompleteRequest) (*CompleteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.exporting {
+		return nil, status.Error(codes.FailedPrecondition, "no handoff in progress")
+	}
+	s.exporting = false
+	s.timer.Stop()
+
+	if req.Aborted {
+		s.resume("successor aborted: " + req.Reason)
+		return &CompleteResponse{}, nil
+	}
+
+	s.logger.Info("Successor took over", zap.String("requester", req.Requester))
+	close(s.done)
+	return &CompleteResponse{}, nil
+}
+
+// resume restarts the provider after a failed handoff (must be called with mu held)
+func (s *Server) resume(reason string) {
+	s.logger.Warn("Handoff did not complete, resuming", zap.String("reason", reason))
+	if err := s.provider.ResumeAfterHandoff(); err != nil {
+		s.logger.Error("Failed to resume after handoff", zap.Error(err))
+	}
+}
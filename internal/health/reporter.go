@@ -0,0 +1,116 @@
+// Package health reports the health of a service instance to Redis, where the
+// API server rolls the reports of all services up into the system health.
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// ReportInterval is how often an instance reports its health
+	ReportInterval = 10 * time.Second
+
+	// reportTTL lets a report survive a few missed intervals before it expires
+	reportTTL = 3 * ReportInterval
+)
+
+// Reporter periodically reports a service instance's health
+type Reporter struct {
+	service   string
+	instance  string
+	groups    []string // Consumer groups whose lag is reported
+	active    func() bool
+	store     *state.RedisStore
+	messaging *messaging.KafkaMessaging
+	logger    *zap.Logger
+	startedAt time.Time
+}
+
+// NewReporter creates a reporter for a service consuming with the given consumer groups
+func NewReporter(service string, store *state.RedisStore, msg *messaging.KafkaMessaging, logger *zap.Logger, groups ...string) *Reporter {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Reporter{
+		service:   service,
+		instance:  fmt.Sprintf("%s:%d", host, os.Getpid()),
+		groups:    groups,
+		active:    func() bool { return true },
+		store:     store,
+		messaging: msg,
+		logger:    logger,
+		startedAt: time.Now(),
+	}
+}
+
+// SetActive sets how the reporter tells whether the instance is doing the
+// service's work, e.g. it stops being active once it handed its state off
+func (r *Reporter) SetActive(active func() bool) {
+	r.active = active
+}
+
+// Run reports the health until ctx is cancelled
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(ReportInterval)
+	defer ticker.Stop()
+
+	for {
+		r.report(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report checks the instance and saves its health
+func (r *Reporter) report(ctx context.Context) {
+	health := &types.ServiceHealth{
+		Service:     r.service,
+		Instance:    r.instance,
+		Active:      r.active(),
+		ConsumerLag: make(map[string]int64),
+		StartedAt:   r.startedAt,
+		ReportedAt:  time.Now(),
+	}
+
+	if cancellations := r.messaging.HandlerCancellations(); len(cancellations) > 0 {
+		health.HandlerCancellations = cancellations
+	}
+
+	if offset, err := r.store.ClockOffset(ctx); err == nil {
+		health.ClockOffset = offset
+	}
+
+	if err := r.messaging.Ping(ctx); err != nil {
+		health.KafkaError = err.Error()
+	} else {
+		for _, group := range r.groups {
+			lag, err := r.messaging.ConsumerLag(ctx, group)
+			if err != nil {
+				r.logger.Debug("Failed to measure consumer lag", zap.String("group", group), zap.Error(err))
+				continue
+			}
+			var total int64
+			for _, behind := range lag {
+				total += behind
+			}
+			health.ConsumerLag[group] = total
+		}
+	}
+
+	if err := r.store.SaveServiceHealth(ctx, health, reportTTL); err != nil {
+		r.logger.Warn("Failed to report health", zap.Error(err))
+	}
+}
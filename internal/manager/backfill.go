@@ -0,0 +1,147 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// backfillReportInterval is how often a rebuilding manager reports its progress
+const backfillReportInterval = 5 * time.Second
+
+// backfill tracks a manager replaying its topics to rebuild its state.
+// A nil backfill means the manager is not rebuilding.
+type backfill struct {
+	mu       sync.Mutex
+	progress types.BackfillProgress
+	store    *state.RedisStore
+	logger   *zap.Logger
+}
+
+// startBackfill moves the manager's consumer groups (group ID -> topics) to
+// where the start mode begins and, in StartModeEarliest, returns a tracker
+// reporting the replay until the retained backlog is consumed
+func startBackfill(
+	ctx context.Context,
+	msg *messaging.KafkaMessaging,
+	store *state.RedisStore,
+	logger *zap.Logger,
+	manager string,
+	mode types.StartMode,
+	groups map[string][]string,
+) (*backfill, error) {
+	var total int64
+	for groupID, topics := range groups {
+		replay, err := msg.SeekGroup(ctx, groupID, topics, mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start %s from %s offsets: %w", groupID, mode, err)
+		}
+		total += replay
+	}
+	if mode != types.StartModeEarliest {
+		logger.Info("Consumers started", zap.String("start_mode", string(mode)))
+		return nil, nil
+	}
+	return newBackfill(ctx, store, logger, manager, total), nil
+}
+
+// newBackfill starts tracking the replay of total messages to rebuild a manager's state
+func newBackfill(ctx context.Context, store *state.RedisStore, logger *zap.Logger, manager string, total int64) *backfill {
+	now := time.Now()
+	b := &backfill{
+		progress: types.BackfillProgress{Manager: manager, Total: total, StartedAt: now, UpdatedAt: now},
+		store:    store,
+		logger:   logger,
+	}
+	if total == 0 {
+		b.progress.CompletedAt = &now
+	}
+	logger.Info("Rebuilding state from Kafka", zap.Int64("messages", total))
+	b.save(ctx)
+
+	if b.progress.CompletedAt == nil {
+		go b.report(ctx)
+	}
+	return b
+}
+
+// replaying reports whether the backlog is still being replayed
+func (b *backfill) replaying() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.progress.CompletedAt == nil
+}
+
+// observe counts a consumed message towards the backlog
+func (b *backfill) observe(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	done := b.progress.Observe(time.Now())
+	b.mu.Unlock()
+
+	if done {
+		b.logger.Info("State rebuilt from Kafka",
+			zap.Int64("messages", b.progress.Total),
+			zap.Duration("took", time.Since(b.progress.StartedAt)))
+		b.save(ctx)
+	}
+}
+
+// finish marks the backlog replayed, even if fewer messages than counted were
+// read, e.g. because offsets were taken by transaction markers
+func (b *backfill) finish(ctx context.Context) {
+	b.mu.Lock()
+	if b.progress.CompletedAt != nil {
+		b.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	b.progress.UpdatedAt, b.progress.CompletedAt = now, &now
+	b.mu.Unlock()
+	b.save(ctx)
+}
+
+// report periodically logs and saves the progress until the backlog is replayed
+func (b *backfill) report(ctx context.Context) {
+	ticker := time.NewTicker(backfillReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !b.replaying() {
+				return
+			}
+			b.save(ctx)
+		}
+	}
+}
+
+// save records the progress in Redis for the API
+func (b *backfill) save(ctx context.Context) {
+	b.mu.Lock()
+	progress := b.progress
+	b.mu.Unlock()
+
+	b.logger.Info("Backfill progress",
+		zap.Int64("processed", progress.Processed),
+		zap.Int64("total", progress.Total),
+		zap.Float64("percent", progress.Percent()))
+	if err := b.store.SaveBackfillProgress(ctx, &progress); err != nil {
+		b.logger.Warn("Failed to save backfill progress", zap.Error(err))
+	}
+}
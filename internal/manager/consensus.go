@@ -0,0 +1,453 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/internal/webhook"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ConsensusManager manages proposals and voting
+// Listens to Kafka for proposals and votes
+// Applies Bee consensus algorithm (quorum detection)
+// Publishes results to Redis
+type ConsensusManager struct {
+	messaging    *messaging.KafkaMessaging
+	redisStore   *state.RedisStore
+	beeConsensus *consensus.BeeConsensus
+	webhooks     *webhook.Dispatcher
+	config       *types.Config
+	logger       *zap.Logger
+
+	cancel context.CancelFunc
+}
+
+// NewConsensusManager creates a consensus manager
+func NewConsensusManager(msg *messaging.KafkaMessaging, store *state.RedisStore, cfg *types.Config, logger *zap.Logger) *ConsensusManager {
+	return &ConsensusManager{
+		messaging:    msg,
+		redisStore:   store,
+		beeConsensus: consensus.NewBeeConsensus(cfg, logger),
+		webhooks:     webhook.NewDispatcher(store, logger),
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// Start starts the Bee consensus engine and the Kafka listeners
+func (cm *ConsensusManager) Start(ctx context.Context) error {
+	ctx, cm.cancel = context.WithCancel(ctx)
+
+	if err := cm.beeConsensus.Start(ctx); err != nil {
+		return err
+	}
+
+	// Track mesh membership so quorum is computed against live agents
+	go cm.listenToTopologyEvents(ctx)
+
+	// Listen to proposals from Kafka
+	go cm.listenToProposals(ctx)
+
+	// Listen to votes from Kafka
+	go cm.listenToVotes(ctx)
+
+	// Monitor consensus events
+	go cm.monitorConsensusEvents(ctx)
+
+	// Propose the rounds of recurring proposals as they fall due
+	go cm.runRecurringProposals(ctx)
+
+	// Print stats periodically
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats := cm.beeConsensus.GetStats()
+				cm.logger.Info("Consensus stats",
+					zap.Int("total_proposals", stats["total_proposals"]),
+					zap.Int("pending", stats["pending_proposals"]),
+					zap.Int("accepted", stats["accepted_proposals"]),
+					zap.Int("active_agents", stats["active_agents"]),
+				)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the listeners and the consensus engine
+func (cm *ConsensusManager) Stop() error {
+	if cm.cancel != nil {
+		cm.cancel()
+	}
+	return cm.beeConsensus.Stop()
+}
+
+// Consensus returns the underlying Bee consensus engine
+func (cm *ConsensusManager) Consensus() *consensus.BeeConsensus {
+	return cm.beeConsensus
+}
+
+func (cm *ConsensusManager) listenToTopologyEvents(ctx context.Context) {
+	err := cm.messaging.ConsumeTopologyEvents(ctx, "topology", "consensus-manager", func(ctx context.Context, event types.TopologyEvent) error {
+		switch event.Type {
+		case types.TopologyEventAgentJoined:
+			if event.Agent != nil {
+				cm.beeConsensus.RegisterAgentRole(event.Agent.ID, event.Agent.Role)
+				cm.sandbox(event.Agent)
+			}
+		case types.TopologyEventAgentLeft:
+			cm.beeConsensus.UnregisterAgent(event.AgentID)
+		}
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		cm.logger.Error("Topology event listener stopped", zap.Error(err))
+	}
+}
+
+// sandbox makes the votes of a joining agent without a verified attestation
+// non-binding for its probation
+func (cm *ConsensusManager) sandbox(agent *types.Agent) {
+	now := time.Now()
+	agent.VerifyAttestations(cm.config.AttestationKeys, now)
+	agent.EnterSandbox(cm.config.SandboxProbation, now)
+	if agent.Sandbox != nil {
+		cm.beeConsensus.SandboxAgent(agent.ID, agent.Sandbox.Until)
+	}
+}
+
+// releaseIfApproved ends a sandboxed voter's probation once an operator approved it
+func (cm *ConsensusManager) releaseIfApproved(ctx context.Context, voterID types.AgentID) {
+	if !cm.beeConsensus.Sandboxed(voterID) {
+		return
+	}
+	approved, err := cm.redisStore.AgentApproved(ctx, voterID)
+	if err != nil {
+		cm.logger.Warn("Failed to check agent approval", zap.Error(err))
+	} else if approved {
+		cm.beeConsensus.ReleaseSandbox(voterID)
+	}
+}
+
+func (cm *ConsensusManager) listenToProposals(ctx context.Context) {
+	err := cm.messaging.ConsumeMessages(ctx, "proposals", "consensus-manager", func(ctx context.Context, msg *types.Message) error {
+		if _, ok := msg.Payload["bundle"]; ok {
+			return cm.createBundle(ctx, msg.Payload)
+		}
+
+		// Parse proposal from message
+		if _, ok := msg.Payload["proposal"]; !ok {
+			return nil
+		}
+		request, err := consensus.ParseProposalRequest(msg.Payload)
+		if err != nil {
+			return fmt.Errorf("invalid proposal: %w", err)
+		}
+		if request, err = cm.instantiate(ctx, request); err != nil {
+			return err
+		}
+
+		// Create proposal in consensus engine
+		proposal, err := cm.beeConsensus.Propose(request)
+		if err != nil {
+			cm.logger.Error("Failed to create proposal", zap.Error(err))
+			return err
+		}
+
+		// Save to Redis
+		if err := cm.redisStore.SaveProposal(ctx, proposal); err != nil {
+			cm.logger.Error("Failed to save proposal to Redis", zap.Error(err))
+		}
+
+		cm.logger.Info("Proposal created",
+			zap.String("proposal_id", string(proposal.ID)),
+			zap.String("proposer", string(request.ProposerID)),
+		)
+
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		cm.logger.Error("Proposal listener stopped", zap.Error(err))
+	}
+}
+
+// instantiate expands a templated request into its content
+func (cm *ConsensusManager) instantiate(ctx context.Context, request *consensus.ProposalRequest) (*consensus.ProposalRequest, error) {
+	if request.Template == "" {
+		return request, nil
+	}
+	template, err := consensus.LookupTemplate(ctx, cm.redisStore, request.Template)
+	if err != nil {
+		return nil, err
+	}
+	electorate, window, policy := request.Electorate, request.Window, request.Policy
+	if request, err = consensus.Instantiate(template, request.ProposerID, request.Params); err != nil {
+		return nil, err
+	}
+	request.Electorate, request.Window, request.Policy = electorate, window, policy
+	return request, nil
+}
+
+// createBundle creates a bundle of proposals voted on as a slate
+func (cm *ConsensusManager) createBundle(ctx context.Context, payload map[string]any) error {
+	request, err := consensus.ParseBundleRequest(payload)
+	if err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	for i, member := range request.Proposals {
+		if request.Proposals[i], err = cm.instantiate(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	bundle, err := cm.beeConsensus.ProposeBundle(request)
+	if err != nil {
+		cm.logger.Error("Failed to create bundle", zap.Error(err))
+		return err
+	}
+	cm.saveBundle(ctx, bundle)
+
+	cm.logger.Info("Bundle created",
+		zap.String("bundle_id", string(bundle.ID)),
+		zap.String("proposer", string(bundle.ProposerID)),
+		zap.Int("proposals", len(bundle.Proposals)),
+	)
+	return nil
+}
+
+func (cm *ConsensusManager) listenToVotes(ctx context.Context) {
+	err := cm.messaging.ConsumeMessages(ctx, "votes", "consensus-manager", func(ctx context.Context, msg *types.Message) error {
+		// Parse vote from message
+		if _, ok := msg.Payload["vote"]; !ok {
+			return nil
+		}
+		vote, err := consensus.ParseVoteRequest(msg.Payload)
+		if err != nil {
+			return fmt.Errorf("invalid vote: %w", err)
+		}
+		cm.releaseIfApproved(ctx, vote.VoterID)
+
+		// A bundle vote is cast on every member of the slate
+		if vote.BundleID != "" {
+			if err := cm.beeConsensus.VoteBundle(vote.BundleID, vote); err != nil {
+				cm.logger.Error("Failed to register bundle vote", zap.Error(err))
+				return err
+			}
+			if bundle, err := cm.beeConsensus.GetBundle(vote.BundleID); err == nil {
+				cm.saveBundle(ctx, bundle)
+			}
+			cm.logger.Debug("Bundle vote registered",
+				zap.String("bundle_id", string(vote.BundleID)),
+				zap.String("voter_id", string(vote.VoterID)),
+				zap.Bool("support", vote.Support),
+			)
+			return nil
+		}
+
+		// Register vote, locking its stake once cast
+		lock, err := cm.stakeVote(ctx, vote)
+		if err != nil {
+			cm.logger.Warn("Vote stake refused", zap.Error(err))
+			return err
+		}
+		if err := cm.beeConsensus.CastVote(vote); err != nil {
+			cm.logger.Error("Failed to register vote", zap.Error(err))
+			return err
+		}
+		if lock != 0 {
+			if err := cm.redisStore.LockStake(ctx, vote.VoterID, lock); err != nil {
+				cm.logger.Error("Failed to lock vote stake", zap.Error(err), zap.String("voter_id", string(vote.VoterID)))
+			}
+		}
+
+		// Persist the vote and its rationale while the proposal is still pending
+		if proposal, err := cm.beeConsensus.GetProposal(vote.ProposalID); err == nil {
+			cm.saveOutcome(ctx, proposal)
+		}
+
+		cm.logger.Debug("Vote registered",
+			zap.String("proposal_id", string(vote.ProposalID)),
+			zap.String("voter_id", string(vote.VoterID)),
+			zap.Bool("support", vote.Support),
+			zap.Int("factors", len(vote.Factors)),
+		)
+
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		cm.logger.Error("Vote listener stopped", zap.Error(err))
+	}
+}
+
+func (cm *ConsensusManager) monitorConsensusEvents(ctx context.Context) {
+	for event := range cm.beeConsensus.EventChannel() {
+		switch event.Type {
+		case consensus.ConsensusEventProposalCreated:
+			cm.logger.Info("[PROPOSAL] Proposal created",
+				zap.String("proposal_id", string(event.ProposalID)),
+			)
+		case consensus.ConsensusEventQuorumReached:
+			cm.logger.Info("[QUORUM] Quorum reached!",
+				zap.String("proposal_id", string(event.ProposalID)),
+			)
+		case consensus.ConsensusEventProposalAccepted:
+			cm.logger.Info("[ACCEPTED] Proposal ACCEPTED",
+				zap.String("proposal_id", string(event.ProposalID)),
+			)
+			cm.saveOutcome(ctx, event.Proposal)
+			cm.recordRound(ctx, event.Proposal)
+			if event.Proposal != nil && event.Proposal.Window == nil {
+				cm.enactPolicy(ctx, event.Proposal)
+			}
+			// Stakes wait for the decision's verdict, unless it states no intent to measure
+			if event.Proposal != nil {
+				if intent, err := event.Proposal.Intent(); err != nil || intent == nil {
+					cm.releaseStakes(ctx, event.Proposal)
+				}
+			}
+		case consensus.ConsensusEventProposalRejected:
+			cm.logger.Info("[REJECTED] Proposal REJECTED",
+				zap.String("proposal_id", string(event.ProposalID)),
+			)
+			cm.saveOutcome(ctx, event.Proposal)
+			cm.recordRound(ctx, event.Proposal)
+			cm.releaseStakes(ctx, event.Proposal)
+		case consensus.ConsensusEventProposalExpired:
+			cm.saveOutcome(ctx, event.Proposal)
+			cm.recordRound(ctx, event.Proposal)
+			cm.escalate(ctx, event.Proposal)
+			// A default decision settles the stakes with its own event; a re-proposal is voted on anew
+			if event.Proposal != nil && event.Proposal.Status == types.ProposalStatusExpired {
+				cm.releaseStakes(ctx, event.Proposal)
+			}
+		case consensus.ConsensusEventProposalExecuted:
+			cm.logger.Info("[EXECUTED] Proposal window opened, executing",
+				zap.String("proposal_id", string(event.ProposalID)),
+			)
+			cm.saveOutcome(ctx, event.Proposal)
+			cm.announceExecution(ctx, event.Proposal)
+			cm.enactPolicy(ctx, event.Proposal) // A policy with a window is in force once it opens
+		case consensus.ConsensusEventBundleDecided:
+			cm.logger.Info("[BUNDLE] Bundle decided",
+				zap.String("bundle_id", string(event.Bundle.ID)),
+				zap.String("status", string(event.Bundle.Status)),
+			)
+			cm.saveBundle(ctx, event.Bundle)
+		}
+	}
+}
+
+// escalate runs the next steps of the proposal type's escalation chain
+// for a proposal that expired without quorum
+func (cm *ConsensusManager) escalate(ctx context.Context, proposal *types.Proposal) {
+	if proposal == nil || proposal.Bundle != "" || proposal.BlockedBy != "" {
+		return // Bundled proposals are decided with their slate, blocked ones have nothing left to decide
+	}
+	start, steps := consensus.NextEscalation(cm.config.EscalationPolicies, proposal)
+	if len(steps) == 0 {
+		return
+	}
+
+	for i, step := range steps {
+		record := types.EscalationRecord{
+			Step:       start + i,
+			Action:     step.Action,
+			ProposalID: proposal.ID,
+			At:         time.Now(),
+		}
+
+		switch step.Action {
+		case types.EscalationNotify:
+			notified, err := cm.webhooks.Publish(ctx, types.WebhookEventProposalEscalated, map[string]any{
+				"proposal": proposal,
+				"step":     record.Step,
+			})
+			if err != nil {
+				cm.logger.Error("Failed to notify escalation webhooks", zap.Error(err), zap.String("proposal_id", string(proposal.ID)))
+			}
+			record.Notified = notified
+			proposal.RecordEscalation(record)
+
+		case types.EscalationRepropose:
+			electorate := types.WidenElectorate(proposal.Electorate, step.Electorate)
+			next, err := cm.beeConsensus.Repropose(proposal, electorate, step.TimeoutDuration(cm.config.ProposalTimeout))
+			if err != nil {
+				cm.logger.Error("Failed to re-propose", zap.Error(err), zap.String("proposal_id", string(proposal.ID)))
+				break
+			}
+			// Record on both proposals so either one shows the whole chain
+			record.NewID = next.ID
+			record.Electorate = electorate
+			proposal.RecordEscalation(record)
+			next.RecordEscalation(record)
+			if err := cm.redisStore.SaveProposal(ctx, next); err != nil {
+				cm.logger.Error("Failed to save re-proposed proposal", zap.Error(err), zap.String("proposal_id", string(next.ID)))
+			}
+
+		case types.EscalationDefault:
+			record.Decision = step.Decision
+			proposal.RecordEscalation(record)
+			if err := cm.beeConsensus.Resolve(proposal, step.Decision); err != nil {
+				cm.logger.Error("Failed to apply default decision", zap.Error(err), zap.String("proposal_id", string(proposal.ID)))
+			}
+		}
+
+		cm.logger.Info("[ESCALATED] Proposal escalated",
+			zap.String("proposal_id", string(proposal.ID)),
+			zap.Int("step", record.Step),
+			zap.String("action", string(step.Action)),
+		)
+	}
+
+	cm.saveOutcome(ctx, proposal)
+
+	// Dependents wait for an expired prerequisite until its escalation ran
+	cm.beeConsensus.SettleDependents(proposal)
+}
+
+// announceExecution notifies the proposal.executed webhooks that an accepted
+// proposal's window opened, so whoever acts on it can apply it
+func (cm *ConsensusManager) announceExecution(ctx context.Context, proposal *types.Proposal) {
+	if _, err := cm.webhooks.Publish(ctx, types.WebhookEventProposalExecuted, map[string]any{"proposal": proposal}); err != nil {
+		cm.logger.Error("Failed to notify execution webhooks", zap.Error(err), zap.String("proposal_id", string(proposal.ID)))
+	}
+}
+
+// saveOutcome persists a finalized proposal so its status is visible outside the manager
+func (cm *ConsensusManager) saveOutcome(ctx context.Context, proposal *types.Proposal) {
+	if proposal == nil {
+		return
+	}
+	if err := cm.redisStore.SaveProposal(ctx, proposal); err != nil {
+		cm.logger.Error("Failed to save proposal outcome", zap.Error(err), zap.String("proposal_id", string(proposal.ID)))
+	}
+}
+
+// saveBundle persists a bundle and its member proposals
+func (cm *ConsensusManager) saveBundle(ctx context.Context, bundle *types.ProposalBundle) {
+	if err := cm.redisStore.SaveBundle(ctx, bundle); err != nil {
+		cm.logger.Error("Failed to save bundle", zap.Error(err), zap.String("bundle_id", string(bundle.ID)))
+	}
+	for _, proposalID := range bundle.Proposals {
+		if proposal, err := cm.beeConsensus.GetProposal(proposalID); err == nil {
+			cm.saveOutcome(ctx, proposal)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// queueEmbedding queues an insight to be embedded with the next batch
+func (km *KnowledgeManager) queueEmbedding(insight *types.Insight) {
+	if km.embedder == nil {
+		return
+	}
+	km.embedMutex.Lock()
+	km.embedQueue = append(km.embedQueue, insight)
+	km.embedMutex.Unlock()
+}
+
+// embedInsights re-embeds the stored insights if the embedding model changed,
+// and embeds new insights in batches every EmbeddingBatchInterval
+func (km *KnowledgeManager) embedInsights() {
+	go km.migrateEmbeddings(km.ctx)
+
+	ticker := time.NewTicker(km.config.EmbeddingBatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case <-ticker.C:
+			km.embedMutex.Lock()
+			queued := km.embedQueue
+			km.embedQueue = nil
+			km.embedMutex.Unlock()
+			if len(queued) == 0 {
+				continue
+			}
+
+			texts := make([]string, len(queued))
+			for i, insight := range queued {
+				texts[i] = insight.EmbeddingText()
+			}
+			if _, err := km.embedder.Embed(km.ctx, texts); err != nil {
+				// Requeue so the insights are embedded once the provider recovers
+				km.embedMutex.Lock()
+				km.embedQueue = append(queued, km.embedQueue...)
+				km.embedMutex.Unlock()
+				km.logger.Warn("Failed to embed insights", zap.Int("count", len(queued)), zap.Error(err))
+				continue
+			}
+			km.logger.Debug("Embedded insights", zap.Int("count", len(queued)), zap.Any("stats", km.embedder.Stats()))
+		}
+	}
+}
+
+// migrateEmbeddings re-embeds every stored insight when they were embedded
+// with another provider or model, or never, saving its progress as it goes.
+// A migration that stops is resumed on the next start; texts already
+// embedded with the new model come from the cache.
+func (km *KnowledgeManager) migrateEmbeddings(ctx context.Context) {
+	status, err := km.stateStore.LoadEmbeddingStatus(ctx)
+	if err != nil {
+		km.logger.Warn("Failed to load embedding status", zap.Error(err))
+		return
+	}
+	model := km.embedder.Model()
+	if status == nil {
+		status = &types.EmbeddingStatus{}
+	}
+	if status.Model == model {
+		return
+	}
+
+	km.insightsMutex.RLock()
+	insights := km.corpus()
+	km.insightsMutex.RUnlock()
+
+	migration := &types.EmbeddingMigration{From: status.Model, To: model, Total: len(insights), StartedAt: time.Now()}
+	status.Migration = migration
+	save := func() {
+		if err := km.stateStore.SaveEmbeddingStatus(ctx, status); err != nil {
+			km.logger.Warn("Failed to save embedding status", zap.Error(err))
+		}
+	}
+	save()
+	km.logger.Info("Re-embedding insights",
+		zap.String("from", migration.From),
+		zap.String("to", migration.To),
+		zap.Int("insights", migration.Total))
+
+	err = km.embedder.Reembed(ctx, insights, func(done int) {
+		migration.Done = done
+		save()
+	})
+	if err != nil {
+		migration.Error = err.Error()
+		save()
+		km.logger.Warn("Re-embedding stopped", zap.Int("done", migration.Done), zap.Error(err))
+		return
+	}
+
+	finished := time.Now()
+	migration.FinishedAt = &finished
+	status.Model = model
+	save()
+	km.logger.Info("Re-embedded insights", zap.Int("insights", migration.Total), zap.Duration("took", finished.Sub(migration.StartedAt)))
+}
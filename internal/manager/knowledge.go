@@ -0,0 +1,1354 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/alerting"
+	"github.com/avinashshinde/agentmesh-cortex/internal/amql"
+	"github.com/avinashshinde/agentmesh-cortex/internal/digest"
+	"github.com/avinashshinde/agentmesh-cortex/internal/embeddings"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/ranking"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/internal/views"
+	"github.com/avinashshinde/agentmesh-cortex/internal/webhook"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// decisionEvaluationInterval is how often accepted proposals are checked for a final verdict
+	decisionEvaluationInterval = time.Minute
+
+	// decisionImpactAuthor is the agent ID decision_impact insights are published under
+	decisionImpactAuthor types.AgentID = "knowledge-manager"
+
+	// verificationInterval is how often pending insight verifications are checked
+	verificationInterval = 10 * time.Second
+
+	// verificationProposer is the agent ID insight verification proposals are made under
+	verificationProposer types.AgentID = "knowledge-manager"
+
+	// viewRefreshInterval is how often materialized views are refreshed once insights changed
+	viewRefreshInterval = time.Second
+
+	// compactedBootstrapTimeout bounds loading the knowledge base from the compacted insight topic
+	compactedBootstrapTimeout = 2 * time.Minute
+)
+
+// KnowledgeManager manages the collective knowledge from all agents
+type KnowledgeManager struct {
+	messaging  *messaging.KafkaMessaging
+	stateStore *state.RedisStore
+	config     *types.Config
+	logger     *zap.Logger
+
+	// In-memory cache for fast queries
+	insights      map[types.InsightID]*types.Insight
+	insightsMutex sync.RWMutex
+
+	// Indexes for fast querying
+	indexByTopic map[string][]types.InsightID
+	indexByAgent map[types.AgentID][]types.InsightID
+	indexByType  map[types.InsightType][]types.InsightID
+	indexMutex   sync.RWMutex
+
+	// Detected patterns by type and subject
+	patterns      map[string]*types.Pattern
+	patternsMutex sync.Mutex
+
+	// Counts of low-confidence insights tiering did not store individually, by topic
+	summaries      map[string]*types.InsightTierSummary
+	summariesMutex sync.Mutex
+
+	webhooks *webhook.Dispatcher
+
+	// Replay of the insight topic in the earliest start mode, nil otherwise
+	backfill *backfill
+
+	// Fingerprints of the insight versions published to the compacted topic
+	compacted      map[types.InsightID][sha256.Size]byte
+	compactedMutex sync.Mutex
+
+	// Set when insights changed since materialized views were last refreshed
+	viewsDirty atomic.Bool
+
+	// Embeds insights for semantic search; nil without EMBEDDING_URL
+	embedder   *embeddings.Embedder
+	embedQueue []*types.Insight
+	embedMutex sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Insight consumer and persistence; paused while state is handed off
+	stopConsuming context.CancelFunc
+	consumers     sync.WaitGroup
+	active        atomic.Bool
+}
+
+// NewKnowledgeManager creates a knowledge manager
+func NewKnowledgeManager(
+	msg *messaging.KafkaMessaging,
+	store *state.RedisStore,
+	cfg *types.Config,
+	logger *zap.Logger,
+) *KnowledgeManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	var embedder *embeddings.Embedder
+	if cfg.EmbeddingURL != "" {
+		provider := embeddings.NewOpenAIProvider(cfg.EmbeddingURL, cfg.EmbeddingModel, cfg.EmbeddingAPIKey)
+		embedder = embeddings.NewEmbedder(provider, store, cfg.EmbeddingBatchSize)
+	}
+	return &KnowledgeManager{
+		messaging:    msg,
+		stateStore:   store,
+		config:       cfg,
+		logger:       logger.With(zap.String("component", "knowledge-manager")),
+		insights:     make(map[types.InsightID]*types.Insight),
+		indexByTopic: make(map[string][]types.InsightID),
+		indexByAgent: make(map[types.AgentID][]types.InsightID),
+		indexByType:  make(map[types.InsightType][]types.InsightID),
+		patterns:     make(map[string]*types.Pattern),
+		compacted:    make(map[types.InsightID][sha256.Size]byte),
+		summaries:    make(map[string]*types.InsightTierSummary),
+		webhooks:     webhook.NewDispatcher(store, logger),
+		embedder:     embedder,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start loads persisted insights and starts consuming, persistence and pattern detection
+func (km *KnowledgeManager) Start(ctx context.Context) error {
+	km.logger.Info("Knowledge Manager starting")
+
+	mode, err := types.ParseStartMode(string(km.config.KnowledgeStartMode))
+	if err != nil {
+		return err
+	}
+
+	// Load existing insights from Redis, unless rebuilding them from Kafka
+	if mode != types.StartModeEarliest {
+		if err := km.loadInsightsFromRedis(); err != nil {
+			km.logger.Warn("Failed to load insights from Redis", zap.Error(err))
+		}
+		if err := km.loadInsightSummaries(ctx); err != nil {
+			km.logger.Warn("Failed to load insight summaries from Redis", zap.Error(err))
+		}
+	}
+
+	// Bootstrap the current knowledge from the compacted insight topic
+	if km.config.InsightCompaction {
+		if err := km.messaging.EnsureCompactedTopic(ctx, messaging.CompactedInsightsTopic); err != nil {
+			km.logger.Warn("Failed to create compacted insight topic", zap.Error(err))
+		} else if mode != types.StartModeEarliest {
+			if err := km.loadCompactedInsights(ctx); err != nil {
+				km.logger.Warn("Failed to load insights from compacted topic", zap.Error(err))
+			}
+		}
+	}
+
+	km.backfill, err = startBackfill(ctx, km.messaging, km.stateStore, km.logger, "knowledge", mode,
+		map[string][]string{"knowledge-manager": {"insights"}})
+	if err != nil {
+		return err
+	}
+
+	km.startConsuming()
+
+	// Start pattern detection
+	go km.detectPatterns()
+
+	// Start periodic digests
+	go km.generateDigests()
+
+	// Start alerting on mesh metrics
+	go km.evaluateAlerts()
+
+	// Start decision impact evaluation
+	go km.evaluateDecisions()
+
+	// Start insight verification tracking
+	go km.trackVerifications()
+
+	// Start materialized view maintenance
+	go km.refreshViews()
+
+	// Start embedding insights for semantic search
+	if km.embedder != nil {
+		go km.embedInsights()
+	}
+
+	return nil
+}
+
+// startConsuming starts the insight consumer and periodic persistence
+func (km *KnowledgeManager) startConsuming() {
+	ctx, cancel := context.WithCancel(km.ctx)
+	km.stopConsuming = cancel
+
+	km.consumers.Add(2)
+	km.active.Store(true)
+
+	// Start insight consumer
+	go func() {
+		defer km.consumers.Done()
+		km.consumeInsights(ctx)
+	}()
+
+	// Start periodic persistence
+	go func() {
+		defer km.consumers.Done()
+		km.periodicPersistence(ctx)
+	}()
+}
+
+// ExportState stops consuming, persists insights and returns them for a successor
+func (km *KnowledgeManager) ExportState(ctx context.Context) (json.RawMessage, error) {
+	km.active.Store(false)
+	km.stopConsuming()
+	km.consumers.Wait()
+
+	if err := km.saveInsightsToRedis(); err != nil {
+		km.logger.Warn("Failed to save insights before handoff", zap.Error(err))
+	}
+
+	km.insightsMutex.RLock()
+	defer km.insightsMutex.RUnlock()
+
+	insights := make([]*types.Insight, 0, len(km.insights))
+	for _, insight := range km.insights {
+		insights = append(insights, insight)
+	}
+	return json.Marshal(insights)
+}
+
+// Active reports whether the manager is consuming, i.e. has not handed its state off
+func (km *KnowledgeManager) Active() bool {
+	return km.active.Load()
+}
+
+// ResumeAfterHandoff restarts consuming after an aborted handoff
+func (km *KnowledgeManager) ResumeAfterHandoff() error {
+	km.startConsuming()
+	return nil
+}
+
+// ImportState loads insights handed over by a previous instance; call before Start
+func (km *KnowledgeManager) ImportState(state json.RawMessage) error {
+	var insights []*types.Insight
+	if err := json.Unmarshal(state, &insights); err != nil {
+		return fmt.Errorf("failed to decode knowledge state: %w", err)
+	}
+	for _, insight := range insights {
+		km.addInsight(insight)
+	}
+	km.logger.Info("Imported insights from previous instance", zap.Int("count", len(insights)))
+	return nil
+}
+
+// Stop persists insights and stops background work
+func (km *KnowledgeManager) Stop() error {
+	km.logger.Info("Knowledge Manager stopping")
+
+	// Save insights to Redis before shutdown
+	if err := km.saveInsightsToRedis(); err != nil {
+		km.logger.Error("Failed to save insights to Redis", zap.Error(err))
+	}
+	km.publishCompacted(km.ctx)
+
+	km.cancel()
+	return nil
+}
+
+// consumeInsights listens to Kafka for insights published by agents
+func (km *KnowledgeManager) consumeInsights(ctx context.Context) {
+	groupID := "knowledge-manager"
+	err := km.messaging.ConsumeMessages(ctx, "insights", groupID, func(ctx context.Context, msg *types.Message) error {
+		replaying := km.backfill.replaying()
+		km.backfill.observe(ctx)
+
+		// Parse insight from message payload
+		insightData, ok := msg.Payload["insight"]
+		if !ok {
+			return fmt.Errorf("message missing insight data")
+		}
+
+		// Convert to JSON and back to Insight struct
+		jsonData, err := json.Marshal(insightData)
+		if err != nil {
+			return fmt.Errorf("failed to marshal insight: %w", err)
+		}
+
+		var insight types.Insight
+		if err := json.Unmarshal(jsonData, &insight); err != nil {
+			return fmt.Errorf("failed to unmarshal insight: %w", err)
+		}
+
+		// Only the knowledge manager verifies insights; those of sandboxed
+		// agents stay unverified
+		insight.Verification = nil
+		insight.Unverified = false
+		if author := km.requester(insight.AgentID); author != nil {
+			insight.Unverified = author.Sandboxed(time.Now())
+		}
+
+		// Insights replicated from another region keep their origin
+		if insight.Region == "" {
+			insight.Region = km.config.Region
+		}
+
+		// Low-confidence insights of tiered topics are counted, and only a sample stored
+		if !km.tierInsight(&insight) {
+			return nil
+		}
+
+		// Add to knowledge base
+		km.addInsight(&insight)
+		km.queueEmbedding(&insight)
+
+		// Replayed insights only rebuild the knowledge base: their goal
+		// progress, verification votes, pushes and outcomes already happened
+		if replaying {
+			return nil
+		}
+
+		// Goal progress reports also feed objective tracking; insights that
+		// need verification are put to a vote first; other insights are
+		// pushed to agents when they rank high enough
+		if insight.Type == types.InsightTypeGoalProgress {
+			km.recordGoalProgress(ctx, &insight)
+		} else if km.config.RequiresVerification(insight.Type) && insight.Verification == nil {
+			km.requestVerification(ctx, &insight)
+		} else {
+			km.pushIfImportant(ctx, &insight)
+		}
+
+		// Clients with saved queries are called back instead of polling
+		km.notifySavedQueries(ctx, &insight, false)
+
+		// Insights tagged with a proposal measure the decision's impact
+		if insight.LinkedProposal() != "" && insight.Type != types.InsightTypeDecisionImpact {
+			km.recordDecisionOutcome(ctx, &insight)
+		}
+
+		km.logger.Info("Received insight",
+			zap.String("insight_id", string(insight.ID)),
+			zap.String("agent_id", string(insight.AgentID)),
+			zap.String("type", string(insight.Type)),
+			zap.String("topic", insight.Topic),
+			zap.Float64("confidence", insight.Confidence),
+		)
+
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		km.logger.Error("Insight consumption stopped", zap.Error(err))
+	}
+}
+
+// recordGoalProgress stores the measurement carried by a goal_progress insight
+func (km *KnowledgeManager) recordGoalProgress(ctx context.Context, insight *types.Insight) {
+	progress, err := insight.GoalProgress()
+	if err != nil {
+		km.logger.Warn("Ignoring malformed goal progress", zap.Error(err))
+		return
+	}
+	if err := km.stateStore.RecordGoalProgress(ctx, progress); err != nil {
+		km.logger.Error("Failed to record goal progress", zap.Error(err))
+	}
+}
+
+// recordDecisionOutcome links an insight to the proposal it is tagged with
+func (km *KnowledgeManager) recordDecisionOutcome(ctx context.Context, insight *types.Insight) {
+	outcome, err := insight.DecisionOutcome()
+	if err != nil {
+		km.logger.Warn("Ignoring malformed decision outcome", zap.Error(err))
+		return
+	}
+	if err := km.stateStore.RecordDecisionOutcome(ctx, outcome); err != nil {
+		km.logger.Error("Failed to record decision outcome", zap.Error(err))
+	}
+}
+
+// pushIfImportant ranks a new public insight and pushes it to agents if it scores above the push threshold
+func (km *KnowledgeManager) pushIfImportant(ctx context.Context, insight *types.Insight) {
+	if insight.Privacy != types.InsightPrivacyPublic || insight.RequiredAccess != "" || !insight.Actionable(km.config) {
+		return // Pushes reach every agent, so access-scoped insights are only served on query
+	}
+
+	km.insightsMutex.RLock()
+	ranker := ranking.NewRanker(km.config, km.corpus())
+	km.insightsMutex.RUnlock()
+
+	rank := ranker.Score(insight, time.Now())
+	if !ranker.ShouldPush(rank) {
+		return
+	}
+
+	// Teams sharing only through agreements push to the teams they cover
+	policy, err := km.stateStore.ListSharingAgreements(ctx)
+	if err != nil {
+		km.logger.Error("Failed to load sharing agreements, not pushing", zap.Error(err))
+		return
+	}
+
+	pushed := *insight
+	pushed.Rank = &rank
+	if err := km.messaging.PublishInsightPush(ctx, &pushed, policy.Audience(insight, time.Now())); err != nil {
+		km.logger.Error("Failed to push insight", zap.Error(err))
+		return
+	}
+	km.logger.Info("Pushed insight to agents",
+		zap.String("insight_id", string(insight.ID)),
+		zap.Float64("score", rank.Score),
+	)
+}
+
+// notifySavedQueries delivers a new insight to the callbacks of the saved
+// queries it matches. Queries for actionable insights only get insights that
+// need verification once verified is set.
+func (km *KnowledgeManager) notifySavedQueries(ctx context.Context, insight *types.Insight, verified bool) {
+	queries, err := km.stateStore.ListSavedQueries(ctx)
+	if err != nil {
+		km.logger.Error("Failed to load saved queries", zap.Error(err))
+		return
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	km.insightsMutex.RLock()
+	match := *insight
+	km.insightsMutex.RUnlock()
+	actionable := match.Actionable(km.config)
+	now := time.Now()
+
+	var policy types.SharingPolicy
+	var policyErr error
+	policyLoaded := false
+
+	notified := 0
+	for _, saved := range queries {
+		query := saved.Query
+		if !query.Matches(&match) || (query.Actionable && !actionable) || (verified && !query.Actionable) {
+			continue
+		}
+
+		// Callbacks leave the mesh: anonymous queries only get what is pushed
+		// to every agent, others what the agent may read
+		if requester := km.requester(query.AgentID); requester == nil {
+			if match.Privacy != types.InsightPrivacyPublic || match.RequiredAccess != "" {
+				continue
+			}
+		} else {
+			if !policyLoaded {
+				policy, policyErr = km.sharingPolicy(requester)
+				policyLoaded = true
+			}
+			if !match.VisibleTo(requester) || !policy.Allows(&match, requester.Role, now) {
+				continue
+			}
+			if policyErr != nil && match.AgentRole != requester.Role {
+				continue
+			}
+		}
+
+		err := km.webhooks.Send(saved.Callback(), types.WebhookEventQueryMatch, types.QueryMatch{
+			QueryID:   saved.ID,
+			QueryName: saved.Name,
+			Insight:   match,
+		})
+		if err != nil {
+			km.logger.Error("Failed to notify saved query", zap.String("query_id", saved.ID), zap.Error(err))
+			continue
+		}
+		notified++
+	}
+
+	if notified > 0 {
+		km.logger.Info("Notified saved queries",
+			zap.String("insight_id", string(match.ID)),
+			zap.Int("queries", notified),
+		)
+	}
+}
+
+// corpus returns all known insights (must be called with insightsMutex held)
+func (km *KnowledgeManager) corpus() []*types.Insight {
+	corpus := make([]*types.Insight, 0, len(km.insights))
+	for _, insight := range km.insights {
+		corpus = append(corpus, insight)
+	}
+	return corpus
+}
+
+// tierInsight applies the INSIGHT_TIERING policy of the insight's topic,
+// counting it in the topic's summary when it is low-confidence, and reports
+// whether to store it. Goal progress and decision outcomes are always stored.
+func (km *KnowledgeManager) tierInsight(insight *types.Insight) bool {
+	if len(km.config.InsightTiering) == 0 || insight.Type == types.InsightTypeGoalProgress ||
+		insight.Type == types.InsightTypeDecisionImpact || insight.LinkedProposal() != "" {
+		return true
+	}
+	store, summarize := km.config.InsightTiering.Tier(insight)
+	if !summarize {
+		return store
+	}
+
+	km.summariesMutex.Lock()
+	defer km.summariesMutex.Unlock()
+	summary, ok := km.summaries[insight.Topic]
+	if !ok {
+		summary = &types.InsightTierSummary{Topic: insight.Topic}
+		km.summaries[insight.Topic] = summary
+	}
+	summary.Add(insight, store)
+	return store
+}
+
+// InsightSummaries returns copies of the low-confidence insight summaries by topic
+func (km *KnowledgeManager) InsightSummaries() map[string]types.InsightTierSummary {
+	km.summariesMutex.Lock()
+	defer km.summariesMutex.Unlock()
+
+	summaries := make(map[string]types.InsightTierSummary, len(km.summaries))
+	for topic, summary := range km.summaries {
+		copied := *summary
+		copied.ByType = make(map[types.InsightType]int64, len(summary.ByType))
+		for insightType, count := range summary.ByType {
+			copied.ByType[insightType] = count
+		}
+		copied.ByRole = make(map[string]int64, len(summary.ByRole))
+		for role, count := range summary.ByRole {
+			copied.ByRole[role] = count
+		}
+		summaries[topic] = copied
+	}
+	return summaries
+}
+
+// addInsight adds an insight to the knowledge base and updates indexes
+func (km *KnowledgeManager) addInsight(insight *types.Insight) {
+	km.insightsMutex.Lock()
+	_, known := km.insights[insight.ID]
+	km.insights[insight.ID] = insight
+	km.insightsMutex.Unlock()
+	km.viewsDirty.Store(true)
+
+	// Replayed or bootstrapped insights are already indexed
+	if known {
+		return
+	}
+
+	// Update indexes
+	km.indexMutex.Lock()
+	defer km.indexMutex.Unlock()
+
+	// Index by topic
+	km.indexByTopic[insight.Topic] = append(km.indexByTopic[insight.Topic], insight.ID)
+
+	// Index by agent
+	km.indexByAgent[insight.AgentID] = append(km.indexByAgent[insight.AgentID], insight.ID)
+
+	// Index by type
+	km.indexByType[insight.Type] = append(km.indexByType[insight.Type], insight.ID)
+}
+
+// QueryInsights queries the knowledge base with filters
+func (km *KnowledgeManager) QueryInsights(query types.KnowledgeQuery) types.KnowledgeQueryResult {
+	requester := km.requester(query.AgentID)
+	policy, policyErr := km.sharingPolicy(requester)
+	now := time.Now()
+
+	km.insightsMutex.RLock()
+	defer km.insightsMutex.RUnlock()
+
+	var matchingInsights []types.Insight
+
+	// Get candidate insights from indexes
+	var candidateIDs []types.InsightID
+
+	if len(query.Topics) > 0 {
+		// Filter by topics
+		km.indexMutex.RLock()
+		for _, topic := range query.Topics {
+			candidateIDs = append(candidateIDs, km.indexByTopic[topic]...)
+		}
+		km.indexMutex.RUnlock()
+	} else if len(query.InsightTypes) > 0 {
+		// Filter by insight types
+		km.indexMutex.RLock()
+		for _, insightType := range query.InsightTypes {
+			candidateIDs = append(candidateIDs, km.indexByType[insightType]...)
+		}
+		km.indexMutex.RUnlock()
+	} else {
+		// No filters - check all insights
+		for id := range km.insights {
+			candidateIDs = append(candidateIDs, id)
+		}
+	}
+
+	// Apply filters
+	for _, insightID := range candidateIDs {
+		insight, ok := km.insights[insightID]
+		if !ok {
+			continue
+		}
+
+		// Check confidence threshold
+		if insight.Confidence < query.MinConfidence {
+			continue
+		}
+
+		// Check verification
+		if query.Actionable && !insight.Actionable(km.config) {
+			continue
+		}
+
+		// Check what the requesting agent may read; without the sharing
+		// agreements, only its own team's insights
+		if requester != nil && !insight.VisibleTo(requester) {
+			continue
+		}
+		if requester != nil && !policy.Allows(insight, requester.Role, now) {
+			continue
+		}
+		if policyErr != nil && insight.AgentRole != requester.Role {
+			continue
+		}
+
+		// Check time range
+		if query.TimeFrom != nil && insight.CreatedAt.Before(*query.TimeFrom) {
+			continue
+		}
+		if query.TimeTo != nil && insight.CreatedAt.After(*query.TimeTo) {
+			continue
+		}
+
+		// Check agent types
+		if len(query.AgentTypes) > 0 {
+			found := false
+			for _, agentType := range query.AgentTypes {
+				if insight.AgentRole == agentType {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+
+		matchingInsights = append(matchingInsights, *insight)
+	}
+
+	// Most important first, then apply limit
+	ranking.NewRanker(km.config, km.corpus()).Sort(matchingInsights, time.Now())
+	if query.Limit > 0 && len(matchingInsights) > query.Limit {
+		matchingInsights = matchingInsights[:query.Limit]
+	}
+
+	return types.KnowledgeQueryResult{
+		Query:     query,
+		Insights:  matchingInsights,
+		Count:     len(matchingInsights),
+		Timestamp: time.Now(),
+	}
+}
+
+// QueryStoredInsights answers a knowledge query from the insights a knowledge
+// manager persisted to Redis, for processes that run none themselves like the
+// API server. The requester is nil for anonymous queries.
+func QueryStoredInsights(ctx context.Context, store *state.RedisStore, cfg *types.Config, query types.KnowledgeQuery, requester *types.Agent) ([]types.Insight, error) {
+	stored, err := store.ListInsights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	insights := make([]types.Insight, len(stored))
+	for i, insight := range stored {
+		insights[i] = *insight
+	}
+
+	var policy types.SharingPolicy
+	if requester != nil {
+		if policy, err = store.ListSharingAgreements(ctx); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+
+	// Apply filters
+	var filtered []types.Insight
+	for _, insight := range insights {
+		// Filter by confidence
+		if insight.Confidence < query.MinConfidence {
+			continue
+		}
+
+		// Filter out insights still awaiting verification
+		if query.Actionable && !insight.Actionable(cfg) {
+			continue
+		}
+
+		// Filter by what the requesting agent may read
+		if requester != nil && !insight.VisibleTo(requester) {
+			continue
+		}
+		if requester != nil && !policy.Allows(&insight, requester.Role, now) {
+			continue
+		}
+
+		// Filter by topics
+		if len(query.Topics) > 0 {
+			found := false
+			for _, topic := range query.Topics {
+				if insight.Topic == topic {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+
+		// Filter by agent types
+		if len(query.AgentTypes) > 0 {
+			found := false
+			for _, agentType := range query.AgentTypes {
+				if insight.AgentRole == agentType {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+
+		filtered = append(filtered, insight)
+	}
+
+	// Most important first, then apply limit
+	corpus := make([]*types.Insight, len(insights))
+	for i := range insights {
+		corpus[i] = &insights[i]
+	}
+	ranking.NewRanker(cfg, corpus).Sort(filtered, time.Now())
+	if query.Limit > 0 && len(filtered) > query.Limit {
+		filtered = filtered[:query.Limit]
+	}
+
+	return filtered, nil
+}
+
+// requester returns the directory entry of an agent, or nil for anonymous
+// queries. Unknown agents get no attested data access and no sandbox.
+func (km *KnowledgeManager) requester(agentID types.AgentID) *types.Agent {
+	if agentID == "" {
+		return nil
+	}
+	if snapshot, err := km.stateStore.LoadGraphSnapshot(km.ctx); err == nil {
+		if agent, ok := snapshot.Agents[agentID]; ok {
+			return agent
+		}
+	}
+	return &types.Agent{ID: agentID}
+}
+
+// sharingPolicy loads the sharing agreements that apply to a requesting agent;
+// anonymous queries are not subject to them
+func (km *KnowledgeManager) sharingPolicy(requester *types.Agent) (types.SharingPolicy, error) {
+	if requester == nil {
+		return nil, nil
+	}
+	policy, err := km.stateStore.ListSharingAgreements(km.ctx)
+	if err != nil {
+		km.logger.Warn("Failed to load sharing agreements", zap.Error(err))
+	}
+	return policy, err
+}
+
+// detectPatterns analyzes insights to detect emergent patterns
+func (km *KnowledgeManager) detectPatterns() {
+	ticker := time.NewTicker(60 * time.Second) // Check every minute
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case <-ticker.C:
+			km.analyzePatterns()
+		}
+	}
+}
+
+// analyzePatterns looks for repeated topics or correlations across insights
+func (km *KnowledgeManager) analyzePatterns() {
+	km.insightsMutex.RLock()
+	defer km.insightsMutex.RUnlock()
+
+	// Group insights by topic, leaving out those of sandboxed agents
+	byTopic := make(map[string][]types.InsightID)
+	for id, insight := range km.insights {
+		if insight.Unverified {
+			continue
+		}
+		byTopic[insight.Topic] = append(byTopic[insight.Topic], id)
+	}
+
+	km.patternsMutex.Lock()
+	defer km.patternsMutex.Unlock()
+
+	// Record patterns where topic appears 3+ times
+	for topic, ids := range byTopic {
+		if len(ids) < 3 {
+			continue
+		}
+
+		key := "repeated_topic:" + topic
+		pattern, known := km.patterns[key]
+		if !known {
+			pattern = &types.Pattern{
+				ID:          key,
+				Type:        "repeated_topic",
+				Description: fmt.Sprintf("Repeated insights about %s", topic),
+				DetectedAt:  time.Now(),
+			}
+			km.patterns[key] = pattern
+		}
+		pattern.Insights = ids
+		pattern.Frequency = len(ids)
+		pattern.Confidence = 1 - 1/float64(len(ids))
+
+		if !known {
+			km.logger.Info("Pattern detected",
+				zap.String("type", pattern.Type),
+				zap.String("topic", topic),
+				zap.Int("frequency", pattern.Frequency),
+			)
+		}
+	}
+}
+
+// Patterns returns the patterns detected so far
+func (km *KnowledgeManager) Patterns() []types.Pattern {
+	km.patternsMutex.Lock()
+	defer km.patternsMutex.Unlock()
+
+	patterns := make([]types.Pattern, 0, len(km.patterns))
+	for _, pattern := range km.patterns {
+		patterns = append(patterns, *pattern)
+	}
+	return patterns
+}
+
+// generateDigests compiles and publishes a digest every DigestInterval, or
+// every INCIDENT_SLOWDOWN intervals during an incident
+func (km *KnowledgeManager) generateDigests() {
+	interval := km.config.DigestInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var throttle types.IncidentThrottle
+	start := time.Now()
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !throttle.Allow(km.incidentActive(), km.config.IncidentSlowdown) {
+				continue // The next digest covers this interval too
+			}
+			if _, err := km.PublishDigest(km.ctx, start, now); err != nil {
+				km.logger.Error("Failed to publish digest", zap.Error(err))
+			}
+			start = now
+		}
+	}
+}
+
+// incidentActive reports whether an incident is declared; one that cannot be
+// read counts as none
+func (km *KnowledgeManager) incidentActive() bool {
+	incident, err := km.stateStore.LoadIncident(km.ctx)
+	if err != nil {
+		km.logger.Warn("Failed to read incident", zap.Error(err))
+	}
+	return incident != nil
+}
+
+// evaluateAlerts checks the ALERT_RULES every AlertInterval and delivers the
+// alerts that fire or resolve to webhooks
+func (km *KnowledgeManager) evaluateAlerts() {
+	interval := km.config.AlertInterval
+	if len(km.config.AlertRules) == 0 || interval <= 0 {
+		return
+	}
+	engine := alerting.NewEngine(km.config.AlertRules)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case now := <-ticker.C:
+			sample, err := alerting.Collect(km.ctx, km.stateStore, now)
+			if err != nil {
+				km.logger.Warn("Failed to sample mesh metrics", zap.Error(err))
+				continue
+			}
+			fired, resolved := engine.Evaluate(sample)
+			for _, alert := range fired {
+				km.logger.Warn("Alert firing",
+					zap.String("rule", alert.Rule),
+					zap.Float64("value", alert.Value))
+				km.notifyAlert(types.WebhookEventAlertFiring, alert)
+			}
+			for _, alert := range resolved {
+				km.logger.Info("Alert resolved",
+					zap.String("rule", alert.Rule),
+					zap.Float64("value", alert.Value))
+				km.notifyAlert(types.WebhookEventAlertResolved, alert)
+			}
+		}
+	}
+}
+
+// notifyAlert delivers an alert to the webhooks subscribed to the event
+func (km *KnowledgeManager) notifyAlert(event string, alert *types.Alert) {
+	if _, err := km.webhooks.Publish(km.ctx, event, alert); err != nil {
+		km.logger.Warn("Failed to deliver alert to webhooks", zap.Error(err), zap.String("rule", alert.Rule))
+	}
+}
+
+// CompileDigest builds the digest for [start, end] from known insights and
+// patterns and the proposals and topology snapshots stored in Redis
+func (km *KnowledgeManager) CompileDigest(ctx context.Context, start, end time.Time) (*types.Digest, error) {
+	proposals, err := km.stateStore.ListProposals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := km.stateStore.ListSnapshots(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	km.insightsMutex.RLock()
+	insights := km.corpus()
+	km.insightsMutex.RUnlock()
+
+	return digest.Compile(km.config, start, end, digest.Inputs{
+		Insights:  insights,
+		Patterns:  km.Patterns(),
+		Proposals: proposals,
+		Snapshots: snapshots,
+	}), nil
+}
+
+// PublishDigest compiles a digest, stores it, publishes it on the digests
+// topic and delivers it to webhooks subscribed to digest.published
+func (km *KnowledgeManager) PublishDigest(ctx context.Context, start, end time.Time) (*types.Digest, error) {
+	d, err := km.CompileDigest(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile digest: %w", err)
+	}
+	markdown := digest.Markdown(d)
+
+	if err := km.stateStore.SaveDigest(ctx, d); err != nil {
+		km.logger.Warn("Failed to store digest", zap.Error(err))
+	}
+	if err := km.messaging.PublishDigest(ctx, d, markdown); err != nil {
+		return d, fmt.Errorf("failed to publish digest: %w", err)
+	}
+
+	hooks, err := km.webhooks.Publish(ctx, types.WebhookEventDigest, map[string]any{
+		"digest":   d,
+		"markdown": markdown,
+	})
+	if err != nil {
+		km.logger.Warn("Failed to deliver digest to webhooks", zap.Error(err))
+	}
+
+	km.logger.Info("Digest published",
+		zap.String("digest_id", d.ID),
+		zap.Int("insights", d.InsightCount),
+		zap.Int("patterns", len(d.NewPatterns)),
+		zap.Int("accepted_proposals", len(d.AcceptedProposals)),
+		zap.Int("webhooks", hooks),
+	)
+	return d, nil
+}
+
+// evaluateDecisions announces the verdict of each accepted proposal once its
+// evaluation window closes, as a decision_impact insight that feeds the
+// proposer's reputation
+func (km *KnowledgeManager) evaluateDecisions() {
+	ticker := time.NewTicker(decisionEvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := km.announceDecisionImpacts(km.ctx, now); err != nil {
+				km.logger.Error("Failed to evaluate decisions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// announceDecisionImpacts publishes the final verdicts not announced yet
+func (km *KnowledgeManager) announceDecisionImpacts(ctx context.Context, now time.Time) error {
+	proposals, err := km.stateStore.ListProposals(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, proposal := range proposals {
+		if proposal.Status != types.ProposalStatusAccepted {
+			continue
+		}
+		outcomes, err := km.stateStore.ListDecisionOutcomes(ctx, proposal.ID)
+		if err != nil {
+			return err
+		}
+		impact := types.EvaluateDecision(proposal, outcomes, now)
+		if !impact.Verdict.Final() {
+			continue
+		}
+		if first, err := km.stateStore.MarkDecisionEvaluated(ctx, proposal.ID); err != nil || !first {
+			continue
+		}
+		// Settle the stakes on the decision by its verdict, once
+		if km.config.VoteStakes {
+			settleStakes(ctx, km.stateStore, km.config, km.logger, proposal, impact.Verdict)
+		}
+
+		if err := km.messaging.PublishInsight(ctx, types.NewDecisionImpactInsight(decisionImpactAuthor, impact)); err != nil {
+			km.logger.Error("Failed to publish decision impact", zap.Error(err))
+			continue
+		}
+		km.logger.Info("Decision impact evaluated",
+			zap.String("proposal_id", string(proposal.ID)),
+			zap.String("proposer_id", string(proposal.ProposerID)),
+			zap.String("verdict", string(impact.Verdict)),
+		)
+	}
+	return nil
+}
+
+// requestVerification marks an insight pending and proposes it to the mesh
+// with the insight_verification template
+func (km *KnowledgeManager) requestVerification(ctx context.Context, insight *types.Insight) {
+	km.insightsMutex.Lock()
+	insight.Verification = &types.InsightVerification{Status: types.InsightVerificationPending}
+	km.insightsMutex.Unlock()
+	km.viewsDirty.Store(true)
+
+	params := map[string]any{
+		"insight_id":   string(insight.ID),
+		"insight_type": string(insight.Type),
+		"agent_id":     string(insight.AgentID),
+		"topic":        insight.Topic,
+		"summary":      insight.Content,
+		"confidence":   insight.Confidence,
+	}
+	if err := km.messaging.PublishProposal(ctx, &types.Proposal{ProposerID: verificationProposer}, messaging.ProposalOptions{Template: types.InsightVerificationTemplate, Params: params}); err != nil {
+		km.logger.Error("Failed to propose insight verification", zap.Error(err), zap.String("insight_id", string(insight.ID)))
+		return
+	}
+	km.logger.Info("Insight awaiting verification",
+		zap.String("insight_id", string(insight.ID)),
+		zap.String("type", string(insight.Type)),
+	)
+}
+
+// trackVerifications applies the outcome of verification proposals to pending insights
+func (km *KnowledgeManager) trackVerifications() {
+	ticker := time.NewTicker(verificationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := km.resolveVerifications(km.ctx); err != nil {
+				km.logger.Error("Failed to check insight verifications", zap.Error(err))
+			}
+		}
+	}
+}
+
+// resolveVerifications updates pending insights from their verification proposals
+// and pushes the ones that were verified
+func (km *KnowledgeManager) resolveVerifications(ctx context.Context) error {
+	km.insightsMutex.RLock()
+	var pending []*types.Insight
+	for _, insight := range km.insights {
+		if insight.Verification != nil && insight.Verification.Status == types.InsightVerificationPending {
+			pending = append(pending, insight)
+		}
+	}
+	km.insightsMutex.RUnlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	proposals, err := km.stateStore.ListProposals(ctx)
+	if err != nil {
+		return err
+	}
+	byInsight := make(map[string][]*types.Proposal)
+	for _, proposal := range proposals {
+		if proposal.Template != types.InsightVerificationTemplate {
+			continue
+		}
+		if id, ok := proposal.Content["insight_id"].(string); ok {
+			byInsight[id] = append(byInsight[id], proposal)
+		}
+	}
+
+	for _, insight := range pending {
+		status, proposal := types.VerificationOutcome(byInsight[string(insight.ID)])
+
+		km.insightsMutex.Lock()
+		if proposal != nil {
+			insight.Verification.ProposalID = proposal.ID
+		}
+		insight.Verification.Status = status
+		if status != types.InsightVerificationPending {
+			insight.Verification.DecidedAt = proposal.DecidedAt
+		}
+		km.insightsMutex.Unlock()
+
+		if status == types.InsightVerificationPending {
+			continue
+		}
+		km.viewsDirty.Store(true)
+		km.logger.Info("Insight verification decided",
+			zap.String("insight_id", string(insight.ID)),
+			zap.String("proposal_id", string(proposal.ID)),
+			zap.String("status", string(status)),
+		)
+		if status == types.InsightVerificationVerified {
+			km.pushIfImportant(ctx, insight)
+			km.notifySavedQueries(ctx, insight, true)
+		}
+	}
+	return nil
+}
+
+// refreshViews keeps the materialized insight views in Redis up to date
+func (km *KnowledgeManager) refreshViews() {
+	ticker := time.NewTicker(viewRefreshInterval)
+	defer ticker.Stop()
+
+	// Definition time of each view as last materialized
+	materialized := make(map[string]time.Time)
+	for {
+		select {
+		case <-km.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := km.materializeViews(km.ctx, materialized, now); err != nil {
+				km.logger.Error("Failed to refresh views", zap.Error(err))
+			}
+		}
+	}
+}
+
+// materializeViews recomputes every view if insights changed, and otherwise
+// only the views defined or redefined since they were last materialized
+func (km *KnowledgeManager) materializeViews(ctx context.Context, materialized map[string]time.Time, now time.Time) error {
+	defined, err := km.stateStore.ListInsightViews(ctx)
+	if err != nil {
+		return err
+	}
+
+	dirty := km.viewsDirty.Swap(false)
+	var stale []*types.InsightView
+	for _, view := range defined {
+		if at, ok := materialized[view.Name]; dirty || !ok || !at.Equal(view.CreatedAt) {
+			stale = append(stale, view)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	km.insightsMutex.RLock()
+	records, err := amql.Records(km.corpus())
+	km.insightsMutex.RUnlock()
+	if err != nil {
+		km.viewsDirty.Store(true)
+		return err
+	}
+
+	for _, view := range stale {
+		result, err := views.Compute(view, records, now)
+		if err != nil {
+			km.logger.Warn("Skipping invalid view", zap.String("view", view.Name), zap.Error(err))
+			materialized[view.Name] = view.CreatedAt // Warn once per definition
+			continue
+		}
+		if err := km.stateStore.SaveViewResult(ctx, result); err != nil {
+			km.viewsDirty.Store(true)
+			return err
+		}
+		materialized[view.Name] = view.CreatedAt
+	}
+	km.logger.Debug("Refreshed views", zap.Int("count", len(stale)))
+	return nil
+}
+
+// periodicPersistence saves insights to Redis every 30 seconds
+func (km *KnowledgeManager) periodicPersistence(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := km.saveInsightsToRedis(); err != nil {
+				km.logger.Error("Failed to persist insights", zap.Error(err))
+			}
+			km.publishCompacted(ctx)
+		}
+	}
+}
+
+// saveInsightsToRedis persists all insights to Redis
+func (km *KnowledgeManager) saveInsightsToRedis() error {
+	km.insightsMutex.RLock()
+	defer km.insightsMutex.RUnlock()
+
+	for id, insight := range km.insights {
+		key := fmt.Sprintf("insight:%s", id)
+		if err := km.stateStore.Set(km.ctx, key, insight, 7*24*time.Hour); err != nil {
+			return fmt.Errorf("failed to save insight %s: %w", id, err)
+		}
+	}
+
+	km.logger.Debug("Persisted insights to Redis", zap.Int("count", len(km.insights)))
+
+	if len(km.config.InsightTiering) == 0 {
+		return nil
+	}
+	summaries := km.InsightSummaries()
+	stored := make(map[string]*types.InsightTierSummary, len(summaries))
+	for topic := range summaries {
+		summary := summaries[topic]
+		stored[topic] = &summary
+	}
+	return km.stateStore.SaveInsightSummaries(km.ctx, stored)
+}
+
+// loadInsightSummaries resumes the low-confidence insight counts persisted by
+// the previous instance
+func (km *KnowledgeManager) loadInsightSummaries(ctx context.Context) error {
+	summaries, err := km.stateStore.LoadInsightSummaries(ctx)
+	if err != nil {
+		return err
+	}
+	km.summariesMutex.Lock()
+	defer km.summariesMutex.Unlock()
+	for topic, summary := range summaries {
+		km.summaries[topic] = summary
+	}
+	return nil
+}
+
+// publishCompacted publishes new and changed insights to the compacted insight topic
+func (km *KnowledgeManager) publishCompacted(ctx context.Context) {
+	if !km.config.InsightCompaction {
+		return
+	}
+
+	km.compactedMutex.Lock()
+	defer km.compactedMutex.Unlock()
+
+	var changed []types.Insight
+	var fingerprints [][sha256.Size]byte
+	km.insightsMutex.RLock()
+	for id, insight := range km.insights {
+		data, err := json.Marshal(insight)
+		if err != nil {
+			continue
+		}
+		if fingerprint := sha256.Sum256(data); km.compacted[id] != fingerprint {
+			changed = append(changed, *insight)
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	km.insightsMutex.RUnlock()
+
+	for i := range changed {
+		if err := km.messaging.PublishCompactedInsight(ctx, &changed[i]); err != nil {
+			km.logger.Warn("Failed to publish compacted insights", zap.Error(err))
+			return
+		}
+		km.compacted[changed[i].ID] = fingerprints[i]
+	}
+	if len(changed) > 0 {
+		km.logger.Debug("Published insights to compacted topic", zap.Int("count", len(changed)))
+	}
+}
+
+// loadCompactedInsights bootstraps the knowledge base from the compacted insight topic
+func (km *KnowledgeManager) loadCompactedInsights(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, compactedBootstrapTimeout)
+	defer cancel()
+
+	km.compactedMutex.Lock()
+	defer km.compactedMutex.Unlock()
+
+	count := 0
+	err := km.messaging.ReadCompactedInsights(ctx, func(insight *types.Insight) error {
+		km.insightsMutex.RLock()
+		_, known := km.insights[insight.ID]
+		km.insightsMutex.RUnlock()
+		if known {
+			return nil // Handed over by the previous instance, at least as recent
+		}
+
+		if data, err := json.Marshal(insight); err == nil {
+			km.compacted[insight.ID] = sha256.Sum256(data)
+		}
+		km.addInsight(insight)
+		count++
+		return nil
+	})
+	km.logger.Info("Loaded insights from compacted topic", zap.Int("count", count))
+	return err
+}
+
+// loadInsightsFromRedis loads existing insights from Redis
+func (km *KnowledgeManager) loadInsightsFromRedis() error {
+	// Note: This is a simplified version
+	// In production, you'd use SCAN to iterate through all insight:* keys
+	km.logger.Info("Loading insights from Redis")
+	return nil
+}
@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// listenToHeartbeats records when each agent was last seen alive
+func (tm *TopologyManager) listenToHeartbeats(ctx context.Context) {
+	err := tm.messaging.ConsumeMessages(ctx, "heartbeats", "topology-heartbeats", func(ctx context.Context, msg *types.Message) error {
+		if msg.Type != types.MessageTypeHeartbeat {
+			return nil
+		}
+
+		known, revived := tm.slimeMold.GetGraph().Heartbeat(msg.FromAgentID, msg.Timestamp)
+		switch {
+		case !known:
+			tm.logger.Debug("Heartbeat from unknown agent", zap.String("agent_id", string(msg.FromAgentID)))
+		case revived:
+			tm.logger.Info("Agent back online", zap.String("agent_id", string(msg.FromAgentID)))
+		}
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		tm.logger.Error("Heartbeat listener stopped", zap.Error(err))
+	}
+}
+
+// sweepLiveness marks agents that stopped sending heartbeats offline and,
+// after AgentEvictionTimeout, removes them by publishing their agent_left
+// event, as if they had left
+func (tm *TopologyManager) sweepLiveness(ctx context.Context) {
+	// Agents restored from a snapshot or handed over count as seen now
+	floor := time.Now()
+
+	ticker := time.NewTicker(max(tm.config.AgentOfflineTimeout/3, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			offline, evict := tm.slimeMold.GetGraph().SweepLiveness(now, floor, tm.config.AgentOfflineTimeout, tm.config.AgentEvictionTimeout)
+			for _, agentID := range offline {
+				tm.logger.Warn("Agent missed its heartbeats, marked offline",
+					zap.String("agent_id", string(agentID)),
+					zap.Duration("timeout", tm.config.AgentOfflineTimeout))
+				tm.reportFailover(agentID)
+			}
+			for _, agentID := range evict {
+				event := types.TopologyEvent{
+					Type:      types.TopologyEventAgentLeft,
+					AgentID:   agentID,
+					Timestamp: now,
+				}
+				if err := tm.messaging.PublishTopologyEvent(ctx, event); err != nil {
+					tm.logger.Warn("Failed to evict stale agent", zap.String("agent_id", string(agentID)), zap.Error(err))
+					continue
+				}
+				tm.logger.Warn("Evicted stale agent",
+					zap.String("agent_id", string(agentID)),
+					zap.Duration("timeout", tm.config.AgentEvictionTimeout))
+			}
+		}
+	}
+}
+
+// reportFailover logs whether an agent of a critical role that went offline
+// has a standby to take over its messages
+func (tm *TopologyManager) reportFailover(agentID types.AgentID) {
+	graph := tm.slimeMold.GetGraph()
+	agent, err := graph.GetAgent(agentID)
+	if err != nil || !tm.config.IsCriticalRole(agent.Role) {
+		return
+	}
+	if standby := graph.StandbyOf(agentID); standby != nil {
+		tm.logger.Warn("Critical agent offline, standby taking over",
+			zap.String("agent_id", string(agentID)),
+			zap.String("role", agent.Role),
+			zap.String("standby_id", string(standby.ID)))
+		return
+	}
+	tm.logger.Error("Critical agent offline without a standby",
+		zap.String("agent_id", string(agentID)),
+		zap.String("role", agent.Role))
+}
@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// recurringCheckInterval is how often recurring proposals are checked for a due round
+const recurringCheckInterval = time.Minute
+
+// runRecurringProposals proposes the rounds of the RECURRING_PROPOSALS as they fall due
+func (cm *ConsensusManager) runRecurringProposals(ctx context.Context) {
+	if len(cm.config.RecurringProposals) == 0 {
+		return
+	}
+	ticker := time.NewTicker(recurringCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		for i := range cm.config.RecurringProposals {
+			cm.proposeRound(ctx, &cm.config.RecurringProposals[i], time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// proposeRound proposes the next round of a recurring proposal if it is due,
+// carrying the outcome of the previous rounds
+func (cm *ConsensusManager) proposeRound(ctx context.Context, recurring *types.RecurringProposal, now time.Time) {
+	logger := cm.logger.With(zap.String("recurring", recurring.Name))
+	progress, err := cm.redisStore.LoadRecurrence(ctx, recurring.Name)
+	if err != nil {
+		logger.Warn("Failed to load recurring proposal progress", zap.Error(err))
+		return
+	}
+	if progress == nil {
+		progress = &types.RecurrenceState{Name: recurring.Name}
+	}
+	if !progress.Due(recurring.Interval(), now) {
+		return
+	}
+	round := progress.Round + 1
+	if claimed, err := cm.redisStore.ClaimRecurringRound(ctx, recurring.Name, round, recurring.Interval()); err != nil || !claimed {
+		if err != nil {
+			logger.Warn("Failed to claim recurring round", zap.Error(err))
+		}
+		return
+	}
+
+	request, err := cm.instantiate(ctx, &consensus.ProposalRequest{
+		ProposerID: types.SchedulerAgentID,
+		Type:       recurring.Type,
+		Content:    recurring.Content,
+		Template:   recurring.Template,
+		Params:     recurring.Params,
+		Electorate: recurring.Electorate,
+		Policy:     recurring.Policy,
+	})
+	if err != nil {
+		logger.Error("Failed to instantiate recurring proposal", zap.Error(err))
+		return
+	}
+
+	request.Content = types.RoundContent(request.Content, round, progress.Rounds)
+	request.Recurrence, request.Round = recurring.Name, round
+
+	proposal, err := cm.beeConsensus.Propose(request)
+	if err != nil {
+		logger.Error("Failed to propose recurring round", zap.Error(err))
+		return
+	}
+	cm.saveOutcome(ctx, proposal)
+
+	previous := len(progress.Rounds)
+	progress.Propose(proposal, now, recurring.RoundsCarried())
+	if err := cm.redisStore.SaveRecurrence(ctx, progress); err != nil {
+		logger.Error("Failed to save recurring proposal progress", zap.Error(err))
+	}
+	logger.Info("[RECURRING] Round proposed",
+		zap.Int("round", round),
+		zap.String("proposal_id", string(proposal.ID)),
+		zap.Int("previous_rounds", previous),
+	)
+}
+
+// recordRound records the outcome of a recurring proposal's round, carried
+// into its next rounds
+func (cm *ConsensusManager) recordRound(ctx context.Context, proposal *types.Proposal) {
+	if proposal == nil || proposal.Recurrence == "" {
+		return
+	}
+	progress, err := cm.redisStore.LoadRecurrence(ctx, proposal.Recurrence)
+	if err != nil || progress == nil || !progress.Decide(proposal) {
+		if err != nil {
+			cm.logger.Warn("Failed to load recurring proposal progress", zap.Error(err), zap.String("recurring", proposal.Recurrence))
+		}
+		return
+	}
+	if err := cm.redisStore.SaveRecurrence(ctx, progress); err != nil {
+		cm.logger.Error("Failed to save recurring proposal progress", zap.Error(err), zap.String("recurring", proposal.Recurrence))
+	}
+}
+
+// enactPolicy puts an accepted proposal for a standing policy in force,
+// superseding the proposal previously in force
+func (cm *ConsensusManager) enactPolicy(ctx context.Context, proposal *types.Proposal) {
+	if proposal == nil || proposal.Policy == "" {
+		return
+	}
+	logger := cm.logger.With(zap.String("policy", proposal.Policy), zap.String("proposal_id", string(proposal.ID)))
+	current, err := cm.redisStore.LoadPolicy(ctx, proposal.Policy)
+	if err != nil {
+		logger.Error("Failed to load policy in force", zap.Error(err))
+		return
+	}
+
+	policy := types.NewStandingPolicy(proposal, current, time.Now())
+	if err := cm.redisStore.SavePolicy(ctx, policy); err != nil {
+		logger.Error("Failed to put policy in force", zap.Error(err))
+		return
+	}
+
+	// Mark the superseded proposal, while it is still kept
+	if policy.Supersedes != "" {
+		if superseded, err := cm.redisStore.LoadProposal(ctx, policy.Supersedes); err == nil {
+			superseded.SupersededBy = proposal.ID
+			cm.saveOutcome(ctx, superseded)
+		}
+	}
+	logger.Info("[POLICY] Standing policy in force", zap.String("supersedes", string(policy.Supersedes)))
+}
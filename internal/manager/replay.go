@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+)
+
+// replayTopics are the topics the replay start mode rebuilds the topology
+// from; at equal publish times, records are replayed in this order so agents
+// join before their lifecycle events and messages
+var replayTopics = []string{"topology", "lifecycle", "messages"}
+
+// replay rebuilds the graph from the records published to the topology,
+// lifecycle and messages topics since from. The topology tells time by the
+// replayed records, and decay cycles run every DecayInterval of replayed
+// time, so the same records always yield the same graph. The consumer groups
+// then continue with the first record not replayed. Call before SlimeMold
+// starts its decay loop.
+func (tm *TopologyManager) replay(ctx context.Context, from time.Time) error {
+	if from.IsZero() {
+		return fmt.Errorf("TOPOLOGY_REPLAY_FROM must be set in the replay start mode")
+	}
+	plan, err := tm.messaging.PlanReplay(ctx, replayTopics, from)
+	if err != nil {
+		return fmt.Errorf("failed to plan topology replay: %w", err)
+	}
+	tm.logger.Info("Replaying topology from Kafka",
+		zap.Time("from", from),
+		zap.Int64("records", plan.Total))
+	tm.backfill = newBackfill(ctx, tm.redisStore, tm.logger, "topology", plan.Total)
+
+	now := from
+	tm.slimeMold.SetClock(func() time.Time { return now })
+	defer tm.slimeMold.SetClock(nil)
+	nextDecay := from.Add(tm.config.DecayInterval)
+
+	err = tm.messaging.Replay(ctx, plan, func(record messaging.ReplayRecord) error {
+		tm.backfill.observe(ctx)
+
+		// Run the decay cycles due before the record, at the time they were due
+		for tm.config.DecayInterval > 0 && !record.Time.Before(nextDecay) {
+			now = nextDecay
+			tm.slimeMold.DecayCycle()
+			nextDecay = nextDecay.Add(tm.config.DecayInterval)
+		}
+		// Records published with a clock behind the others do not turn time back
+		if record.Time.After(now) {
+			now = record.Time
+		}
+
+		switch {
+		case record.Event != nil:
+			tm.applyTopologyEvent(ctx, *record.Event, now)
+		case record.Message != nil && record.Topic == "lifecycle":
+			tm.applyLifecycleEvent(record.Message)
+		case record.Message != nil:
+			tm.applyMessage(ctx, record.Message, now, true)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay topology: %w", err)
+	}
+	tm.backfill.finish(ctx)
+
+	for groupID, topics := range topologyGroups {
+		if err := tm.messaging.CommitReplay(ctx, plan, groupID, topics...); err != nil {
+			return fmt.Errorf("failed to continue %s after the replay: %w", groupID, err)
+		}
+	}
+
+	snapshot := tm.slimeMold.GetSnapshot()
+	tm.logger.Info("Topology rebuilt from Kafka",
+		zap.Time("from", from),
+		zap.Time("until", now),
+		zap.Int("agents", len(snapshot.Agents)),
+		zap.Int("edges", len(snapshot.Edges)))
+	return nil
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// shadowTopology is a second slime mold under the SHADOW_TOPOLOGY parameters,
+// fed the same agents and messages as the live one. It only observes: nothing
+// routes by it, persists its graph or publishes its events, so operators can
+// compare parameters on real traffic before switching. All methods are no-ops
+// on a nil shadow, i.e. when none is configured.
+type shadowTopology struct {
+	slimeMold *topology.SlimeMoldTopology
+	params    types.TopologyParams
+	since     time.Time
+}
+
+// newShadowTopology creates the shadow topology configured, or returns nil
+func newShadowTopology(cfg *types.Config, logger *zap.Logger) *shadowTopology {
+	if cfg.ShadowTopology == nil {
+		return nil
+	}
+	shadowCfg := cfg.ShadowTopology.Apply(cfg)
+	// Only warnings, the live topology already logs every join and leave
+	logger = logger.Named("shadow").WithOptions(zap.IncreaseLevel(zapcore.WarnLevel))
+	return &shadowTopology{
+		slimeMold: topology.NewSlimeMoldTopology(shadowCfg, logger),
+		params:    types.TopologyParamsOf(shadowCfg),
+	}
+}
+
+// start runs the shadow's decay loop and discards its events
+func (s *shadowTopology) start(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	s.since = time.Now()
+	go func() {
+		for range s.slimeMold.EventChannel() {
+		}
+	}()
+	return s.slimeMold.Start(ctx)
+}
+
+func (s *shadowTopology) stop() {
+	if s != nil {
+		s.slimeMold.Stop()
+	}
+}
+
+// addAgent adds a copy of the agent, since each graph updates its own agents
+func (s *shadowTopology) addAgent(agent *types.Agent) {
+	if s != nil {
+		shadowAgent := *agent
+		s.slimeMold.AddAgent(&shadowAgent)
+	}
+}
+
+func (s *shadowTopology) removeAgent(agentID types.AgentID) {
+	if s != nil {
+		s.slimeMold.RemoveAgent(agentID)
+	}
+}
+
+func (s *shadowTopology) setLifecycle(agentID types.AgentID, lifecycle *types.AgentLifecycle) {
+	if s != nil {
+		s.slimeMold.GetGraph().SetLifecycle(agentID, lifecycle)
+	}
+}
+
+func (s *shadowTopology) releaseSandbox(agentID types.AgentID) {
+	if s != nil {
+		s.slimeMold.GetGraph().ReleaseSandbox(agentID)
+	}
+}
+
+func (s *shadowTopology) reinforceEdge(sourceID, targetID types.AgentID, msgType types.MessageType) {
+	if s != nil {
+		s.slimeMold.ReinforceEdge(sourceID, targetID, msgType)
+	}
+}
+
+// restore starts the shadow from the live graph handed over by a previous instance
+func (s *shadowTopology) restore(snapshot *types.GraphSnapshot) {
+	if s != nil {
+		s.slimeMold.Restore(snapshot)
+	}
+}
+
+// compare puts the live topology's stats side by side with the shadow's
+func (s *shadowTopology) compare(live *types.GraphSnapshot, liveParams types.TopologyParams) *types.TopologyComparison {
+	shadow := s.slimeMold.GetSnapshot()
+	comparison := &types.TopologyComparison{
+		Live:      types.TopologyVariant{Params: liveParams, Stats: live.Stats},
+		Shadow:    types.TopologyVariant{Params: s.params, Stats: shadow.Stats},
+		Since:     s.since,
+		Timestamp: shadow.Timestamp,
+	}
+	comparison.Live.Stats.Centrality = nil
+	comparison.Shadow.Stats.Centrality = nil
+	return comparison
+}
@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// stakeVote checks that the voter can cover a vote's stake and returns the
+// points to lock once the vote is cast: the stake less what the voter already
+// staked on the proposal, which the vote replaces. Without VOTE_STAKES stakes
+// are ignored.
+func (cm *ConsensusManager) stakeVote(ctx context.Context, vote *consensus.VoteRequest) (float64, error) {
+	if !cm.config.VoteStakes {
+		vote.Stake = 0
+		return 0, nil
+	}
+	previous := 0.0
+	if proposal, err := cm.beeConsensus.GetProposal(vote.ProposalID); err == nil {
+		previous = proposal.Stakes()[vote.VoterID]
+	}
+	delta := vote.Stake - previous
+	if delta <= 0 {
+		return delta, nil
+	}
+
+	account, err := cm.redisStore.LoadStakeAccount(ctx, vote.VoterID, cm.config.StakeInitialBalance)
+	if err != nil {
+		return 0, err
+	}
+	if account.Available() < delta {
+		return 0, fmt.Errorf("agent %s cannot stake %g, %g available", vote.VoterID, vote.Stake, account.Available()+previous)
+	}
+	return delta, nil
+}
+
+// releaseStakes settles the stakes on a proposal that will not get a verdict,
+// because it was not accepted or states no intent, returning them unchanged
+func (cm *ConsensusManager) releaseStakes(ctx context.Context, proposal *types.Proposal) {
+	if proposal == nil || !cm.config.VoteStakes {
+		return
+	}
+	settleStakes(ctx, cm.redisStore, cm.config, cm.logger, proposal, "")
+}
+
+// settleStakes rewards the right stakes on a proposal and slashes the wrong
+// ones by its verdict, and unlocks them
+func settleStakes(ctx context.Context, store *state.RedisStore, cfg *types.Config, logger *zap.Logger, proposal *types.Proposal, verdict types.DecisionVerdict) {
+	for _, settlement := range types.SettleStakes(proposal, verdict, cfg.StakeRewardRate, cfg.StakeSlashRate) {
+		if err := store.SettleStake(ctx, settlement); err != nil {
+			logger.Error("Failed to settle stake", zap.Error(err),
+				zap.String("proposal_id", string(proposal.ID)),
+				zap.String("agent_id", string(settlement.AgentID)),
+			)
+			continue
+		}
+		if settlement.Change != 0 {
+			logger.Info("Stake settled",
+				zap.String("proposal_id", string(proposal.ID)),
+				zap.String("agent_id", string(settlement.AgentID)),
+				zap.Float64("stake", settlement.Stake),
+				zap.Bool("correct", settlement.Correct),
+				zap.Float64("change", settlement.Change),
+			)
+		}
+	}
+}
@@ -0,0 +1,727 @@
+// Package manager hosts the central mesh services (topology, consensus, knowledge).
+// The cmd binaries are thin wrappers around these types, which lets tests run
+// the services in-process against real Kafka and Redis.
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// snapshotInterval is how often the topology manager persists the graph to Redis
+const snapshotInterval = 5 * time.Second
+
+// topologyHealthAuthor is the agent ID topology_health insights are published under
+const topologyHealthAuthor types.AgentID = "topology-manager"
+
+// topologyGroups are the topology manager's consumer groups and their topics
+var topologyGroups = map[string][]string{
+	"topology-manager":       {"topology"},
+	"topology-reinforcement": {"messages"},
+	"topology-lifecycle":     {"lifecycle"},
+}
+
+// TopologyManager maintains the network graph
+// Listens to Kafka for agent/message events
+// Applies SlimeMold algorithm (reinforcement, decay, pruning)
+// Publishes updates to Redis
+type TopologyManager struct {
+	messaging  *messaging.KafkaMessaging
+	redisStore *state.RedisStore
+	slimeMold  *topology.SlimeMoldTopology
+	shadow     *shadowTopology // Nil without SHADOW_TOPOLOGY
+	layout     *topology.Layout
+	routes     *routing.Learner
+	limiter    *types.MessageLimiter // Message rate of sandboxed agents
+	backfill   *backfill             // Replay in the earliest start mode, nil otherwise
+	config     *types.Config
+	logger     *zap.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Kafka listeners and snapshot persistence; paused while state is handed off
+	stopListeners context.CancelFunc
+	listeners     sync.WaitGroup
+	active        atomic.Bool
+
+	// Graph handed over by the previous instance, not to be replaced on Start
+	imported bool
+
+	// Last snapshot saved, the base of the next delta; nil forces a checkpoint
+	saved           *types.GraphSnapshot
+	sequence        uint64
+	sinceCheckpoint int
+
+	// Last decay cycle saved for GET /api/topology/algorithm
+	algorithmCycle uint64
+
+	// Health issues already published as insights, by key, with when they were detected
+	healthReported map[string]time.Time
+}
+
+// NewTopologyManager creates a topology manager
+func NewTopologyManager(msg *messaging.KafkaMessaging, store *state.RedisStore, cfg *types.Config, logger *zap.Logger) *TopologyManager {
+	return &TopologyManager{
+		messaging:  msg,
+		redisStore: store,
+		slimeMold:  topology.NewSlimeMoldTopology(cfg, logger),
+		shadow:     newShadowTopology(cfg, logger),
+		layout:     topology.NewLayout(cfg.LayoutIterations),
+		routes:     routing.NewLearner(cfg),
+		limiter:    types.NewMessageLimiter(cfg.SandboxMessageRate),
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+// Start starts SlimeMold and the Kafka listeners and snapshot loop
+func (tm *TopologyManager) Start(ctx context.Context) error {
+	ctx, tm.cancel = context.WithCancel(ctx)
+
+	tm.ctx = ctx
+
+	mode, err := types.ParseStartMode(string(tm.config.TopologyStartMode))
+	if err != nil {
+		return err
+	}
+
+	// Continue learning from the route outcomes of the previous run, unless
+	// relearning them from the replayed messages
+	if mode != types.StartModeEarliest && mode != types.StartModeReplay {
+		if stats, err := tm.redisStore.LoadRouteStats(ctx); err != nil {
+			tm.logger.Warn("Failed to load route stats", zap.Error(err))
+		} else if len(stats) > 0 {
+			tm.routes.Restore(stats)
+		}
+	}
+
+	// Seed new edges with the weights learned in the previous session, unless
+	// relearning them from the replayed messages
+	if tm.config.LearnedPriors && mode != types.StartModeEarliest && mode != types.StartModeReplay {
+		tm.applyLearnedPriors(ctx)
+	}
+
+	// Seed the edges of agents returning from a previous run with their old weights
+	if tm.config.EdgePriorHistory > 0 {
+		if history, err := tm.redisStore.LoadEdgeHistory(ctx); err != nil {
+			tm.logger.Warn("Failed to load edge history", zap.Error(err))
+		} else {
+			tm.slimeMold.GetGraph().RestoreEdgeHistory(history)
+		}
+	}
+
+	// Warm-start from the graph of the previous run, unless it was handed over
+	// or is rebuilt from the replayed topics
+	if !tm.imported && mode != types.StartModeEarliest && mode != types.StartModeReplay {
+		tm.restoreSnapshot(ctx)
+	}
+
+	if err := tm.shadow.start(ctx); err != nil {
+		return err
+	}
+
+	// Rebuild the graph before the decay loop starts, so it decays by replayed time
+	if mode == types.StartModeReplay {
+		if err := tm.replay(ctx, tm.config.TopologyReplayFrom); err != nil {
+			return err
+		}
+	} else if tm.backfill, err = startBackfill(ctx, tm.messaging, tm.redisStore, tm.logger, "topology", mode, topologyGroups); err != nil {
+		return err
+	}
+
+	if err := tm.slimeMold.Start(ctx); err != nil {
+		return err
+	}
+
+	tm.startListeners()
+	return nil
+}
+
+// startListeners starts the Kafka listeners and the snapshot loop
+func (tm *TopologyManager) startListeners() {
+	ctx, cancel := context.WithCancel(tm.ctx)
+	tm.stopListeners = cancel
+
+	tm.listeners.Add(6)
+	tm.active.Store(true)
+
+	// Start listening to topology events from Kafka
+	go func() {
+		defer tm.listeners.Done()
+		tm.listenToTopologyEvents(ctx)
+	}()
+
+	// Start listening to messages (for edge reinforcement)
+	go func() {
+		defer tm.listeners.Done()
+		tm.listenToMessages(ctx)
+	}()
+
+	// Record the lifecycle states agents report in the directory
+	go func() {
+		defer tm.listeners.Done()
+		tm.listenToLifecycleEvents(ctx)
+	}()
+
+	// Track agent liveness from heartbeats and evict agents gone silent
+	go func() {
+		defer tm.listeners.Done()
+		tm.listenToHeartbeats(ctx)
+	}()
+	go func() {
+		defer tm.listeners.Done()
+		tm.sweepLiveness(ctx)
+	}()
+
+	// Periodically save snapshot to Redis
+	go func() {
+		defer tm.listeners.Done()
+		tm.persistSnapshots(ctx)
+	}()
+}
+
+// ExportState stops consuming, persists a final snapshot and returns the graph for a successor
+func (tm *TopologyManager) ExportState(ctx context.Context) (json.RawMessage, error) {
+	tm.active.Store(false)
+	tm.stopListeners()
+	tm.listeners.Wait()
+
+	snapshot := tm.slimeMold.GetSnapshot()
+	if err := tm.saveSnapshot(ctx, snapshot, true); err != nil {
+		tm.logger.Warn("Failed to save snapshot before handoff", zap.Error(err))
+	}
+	if err := tm.redisStore.SaveRouteStats(ctx, tm.routes.Stats()); err != nil {
+		tm.logger.Warn("Failed to save route stats before handoff", zap.Error(err))
+	}
+	return json.Marshal(snapshot)
+}
+
+// ResumeAfterHandoff restarts consuming after an aborted handoff
+func (tm *TopologyManager) ResumeAfterHandoff() error {
+	tm.startListeners()
+	return nil
+}
+
+// ImportState restores the graph handed over by a previous instance; call before Start
+func (tm *TopologyManager) ImportState(state json.RawMessage) error {
+	var snapshot types.GraphSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return fmt.Errorf("failed to decode topology state: %w", err)
+	}
+	if snapshot.Agents == nil || snapshot.Edges == nil {
+		return fmt.Errorf("topology state is missing agents or edges")
+	}
+	tm.slimeMold.Restore(&snapshot)
+	tm.layout.Restore(snapshot.Layout)
+	tm.imported = true
+
+	// The shadow restores a copy of its own
+	if tm.shadow != nil {
+		var shadowSnapshot types.GraphSnapshot
+		json.Unmarshal(state, &shadowSnapshot)
+		tm.shadow.restore(&shadowSnapshot)
+	}
+	return nil
+}
+
+// Stop saves the learned priors and stops the listeners and SlimeMold
+func (tm *TopologyManager) Stop() error {
+	tm.saveLearnedPriors()
+	if tm.cancel != nil {
+		tm.cancel()
+	}
+	tm.shadow.stop()
+	return tm.slimeMold.Stop()
+}
+
+// restoreSnapshot restores the graph from the last snapshot saved to Redis, so
+// the learned edge weights survive a restart instead of the mesh starting over
+func (tm *TopologyManager) restoreSnapshot(ctx context.Context) {
+	snapshot, err := tm.redisStore.LoadGraphSnapshot(ctx)
+	if errors.Is(err, state.ErrNoSnapshot) {
+		tm.logger.Info("No topology snapshot to restore, starting empty")
+		return
+	} else if err != nil {
+		tm.logger.Warn("Failed to load topology snapshot, starting empty", zap.Error(err))
+		return
+	}
+	if snapshot.Agents == nil || snapshot.Edges == nil {
+		tm.logger.Warn("Topology snapshot is missing agents or edges, starting empty")
+		return
+	}
+
+	// The shadow restores a copy of its own
+	if tm.shadow != nil {
+		if data, err := json.Marshal(snapshot); err == nil {
+			var shadowSnapshot types.GraphSnapshot
+			json.Unmarshal(data, &shadowSnapshot)
+			tm.shadow.restore(&shadowSnapshot)
+		}
+	}
+	tm.slimeMold.Restore(snapshot)
+	tm.layout.Restore(snapshot.Layout)
+
+	// Continue the sequence, so consumers see the next checkpoint follow it
+	tm.sequence = snapshot.Sequence
+	tm.logger.Info("Warm-started topology from the last snapshot",
+		zap.Time("taken_at", snapshot.Timestamp),
+		zap.Duration("age", time.Since(snapshot.Timestamp)),
+	)
+}
+
+// applyLearnedPriors seeds new edges with the weights learned before the
+// last shutdown, unless they are older than LEARNED_PRIORS_MAX_AGE
+func (tm *TopologyManager) applyLearnedPriors(ctx context.Context) {
+	priors, err := tm.redisStore.LoadTopologyPriors(ctx)
+	if err != nil {
+		tm.logger.Warn("Failed to load learned priors", zap.Error(err))
+		return
+	}
+	if priors == nil {
+		return
+	}
+	if age := time.Since(priors.SavedAt); age > tm.config.LearnedPriorsMaxAge {
+		tm.logger.Info("Ignoring stale learned priors", zap.Duration("age", age))
+		return
+	}
+	tm.slimeMold.GetGraph().SetPriors(priors)
+	tm.logger.Info("Seeding new edges with learned priors",
+		zap.Int("edges", len(priors.Edges)),
+		zap.Int("role_pairs", len(priors.Roles)),
+		zap.Time("saved_at", priors.SavedAt),
+	)
+}
+
+// saveLearnedPriors saves the edge weights learned this session for the next
+// one. A session that learned no edges keeps the previous priors, and an
+// instance that handed its state off leaves them to its successor.
+func (tm *TopologyManager) saveLearnedPriors() {
+	if !tm.config.LearnedPriors || !tm.Active() {
+		return
+	}
+	priors := tm.slimeMold.GetGraph().LearnedPriors()
+	if len(priors.Edges) == 0 {
+		return
+	}
+	if err := tm.redisStore.SaveTopologyPriors(tm.ctx, priors); err != nil {
+		tm.logger.Warn("Failed to save learned priors", zap.Error(err))
+		return
+	}
+	tm.logger.Info("Saved learned priors", zap.Int("edges", len(priors.Edges)), zap.Int("role_pairs", len(priors.Roles)))
+}
+
+// Active reports whether the manager is consuming, i.e. has not handed its state off
+func (tm *TopologyManager) Active() bool {
+	return tm.active.Load()
+}
+
+// SlimeMold returns the underlying topology
+func (tm *TopologyManager) SlimeMold() *topology.SlimeMoldTopology {
+	return tm.slimeMold
+}
+
+// Routes returns the route outcome learner
+func (tm *TopologyManager) Routes() *routing.Learner {
+	return tm.routes
+}
+
+func (tm *TopologyManager) persistSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot := tm.slimeMold.GetSnapshot()
+			if err := tm.saveSnapshot(ctx, snapshot, false); err != nil {
+				tm.logger.Error("Failed to save snapshot", zap.Error(err))
+			}
+			tm.saveShadowComparison(ctx, snapshot)
+			tm.syncGuardrails(ctx)
+			tm.syncIncident(ctx)
+			tm.syncSandbox(ctx)
+			tm.persistRouteStats(ctx)
+			tm.persistEdgeHistory(ctx)
+			tm.persistAlgorithmCycles(ctx)
+			tm.reportTopologyHealth(ctx)
+		}
+	}
+}
+
+// saveSnapshot lays out a snapshot's agents and saves it to Redis: a full
+// checkpoint every SnapshotCheckpointEvery snapshots, or when forced, and only
+// what changed since the previous snapshot otherwise
+func (tm *TopologyManager) saveSnapshot(ctx context.Context, snapshot *types.GraphSnapshot, checkpoint bool) error {
+	snapshot.Layout = tm.layout.Update(snapshot)
+	tm.sequence++
+	snapshot.Sequence = tm.sequence
+	checkpoint = checkpoint || tm.saved == nil || tm.sinceCheckpoint+1 >= tm.config.SnapshotCheckpointEvery
+
+	var err error
+	if checkpoint {
+		err = tm.redisStore.SaveGraphSnapshot(ctx, snapshot)
+	} else {
+		err = tm.redisStore.SaveSnapshotDelta(ctx, types.NewSnapshotDelta(tm.saved, snapshot))
+	}
+	if err != nil {
+		// Consumers cannot skip a delta, so start over from a checkpoint
+		tm.saved = nil
+		return err
+	}
+
+	tm.saved = snapshot
+	if checkpoint {
+		tm.sinceCheckpoint = 0
+	} else {
+		tm.sinceCheckpoint++
+	}
+	return nil
+}
+
+// saveShadowComparison publishes the live and shadow topology stats side by side
+func (tm *TopologyManager) saveShadowComparison(ctx context.Context, live *types.GraphSnapshot) {
+	if tm.shadow == nil {
+		return
+	}
+	comparison := tm.shadow.compare(live, types.TopologyParamsOf(tm.config))
+	if err := tm.redisStore.SaveTopologyComparison(ctx, comparison); err != nil {
+		tm.logger.Warn("Failed to save topology comparison", zap.Error(err))
+	}
+}
+
+// syncGuardrails applies manual freezes requested through the API and reports guardrail status
+func (tm *TopologyManager) syncGuardrails(ctx context.Context) {
+	guardrails := tm.slimeMold.Guardrails()
+
+	freeze, err := tm.redisStore.LoadTopologyFreeze(ctx)
+	switch {
+	case err != nil:
+		tm.logger.Warn("Failed to read topology freeze", zap.Error(err))
+	case freeze == nil:
+		// Request removed out of band
+		if guardrails.Manual() {
+			guardrails.Unfreeze()
+		}
+	case freeze.Frozen:
+		if !guardrails.Manual() {
+			guardrails.Freeze(freeze.Reason, true)
+		}
+	default:
+		// One-shot unfreeze, also lifts a breaker freeze
+		guardrails.Unfreeze()
+		if err := tm.redisStore.ClearTopologyFreeze(ctx); err != nil {
+			tm.logger.Warn("Failed to clear topology unfreeze request", zap.Error(err))
+		}
+	}
+
+	if err := tm.redisStore.SaveGuardrailStatus(ctx, guardrails.Status()); err != nil {
+		tm.logger.Warn("Failed to save guardrail status", zap.Error(err))
+	}
+}
+
+// syncIncident pauses decay while an incident is declared, so edges are not
+// pruned for traffic that stopped because of it
+func (tm *TopologyManager) syncIncident(ctx context.Context) {
+	incident, err := tm.redisStore.LoadIncident(ctx)
+	if err != nil {
+		tm.logger.Warn("Failed to read incident", zap.Error(err))
+		return
+	}
+	if !tm.slimeMold.PauseDecay(incident != nil) {
+		return
+	}
+	if incident != nil {
+		tm.logger.Warn("Incident declared, topology decay paused",
+			zap.String("reason", incident.Reason),
+			zap.Bool("automatic", incident.Automatic))
+	} else {
+		tm.logger.Info("Incident cleared, topology decay resumed")
+	}
+}
+
+// sandbox puts a joining agent without a verified attestation on probation,
+// unless an operator already approved it
+func (tm *TopologyManager) sandbox(ctx context.Context, agent *types.Agent, now time.Time) {
+	agent.EnterSandbox(tm.config.SandboxProbation, now)
+	if agent.Sandbox == nil {
+		return
+	}
+	if approved, err := tm.redisStore.AgentApproved(ctx, agent.ID); err != nil {
+		tm.logger.Warn("Failed to check agent approval", zap.Error(err))
+	} else if approved {
+		agent.Sandbox = nil
+		return
+	}
+	tm.logger.Info("Agent sandboxed",
+		zap.String("agent_id", string(agent.ID)),
+		zap.Time("until", agent.Sandbox.Until))
+}
+
+// syncSandbox releases the sandboxed agents an operator approved through the API
+func (tm *TopologyManager) syncSandbox(ctx context.Context) {
+	approved, err := tm.redisStore.ListApprovedAgents(ctx)
+	if err != nil {
+		tm.logger.Warn("Failed to read agent approvals", zap.Error(err))
+		return
+	}
+	for _, agentID := range approved {
+		tm.shadow.releaseSandbox(agentID)
+		if tm.slimeMold.GetGraph().ReleaseSandbox(agentID) {
+			tm.logger.Info("Agent released from sandbox", zap.String("agent_id", string(agentID)))
+		}
+	}
+}
+
+// persistRouteStats fails tasks whose response is overdue and saves the route
+// statistics and the tasks in flight per agent
+func (tm *TopologyManager) persistRouteStats(ctx context.Context) {
+	if expired := tm.routes.ExpirePending(time.Now()); len(expired) > 0 {
+		tm.logger.Debug("Tasks timed out without a response", zap.Int("count", len(expired)))
+		for _, outcome := range expired {
+			tm.recordDecisionOutcome(ctx, outcome)
+		}
+	}
+	if err := tm.redisStore.SaveRouteStats(ctx, tm.routes.Stats()); err != nil {
+		tm.logger.Warn("Failed to save route stats", zap.Error(err))
+	}
+	if err := tm.redisStore.SaveAgentLoad(ctx, tm.routes.Load()); err != nil {
+		tm.logger.Warn("Failed to save agent load", zap.Error(err))
+	}
+}
+
+// persistEdgeHistory saves the weights of the edges removed with agents that
+// left, so they seed the edges after a restart too
+func (tm *TopologyManager) persistEdgeHistory(ctx context.Context) {
+	if tm.config.EdgePriorHistory <= 0 {
+		return
+	}
+	if err := tm.redisStore.SaveEdgeHistory(ctx, tm.slimeMold.GetGraph().EdgeHistory()); err != nil {
+		tm.logger.Warn("Failed to save edge history", zap.Error(err))
+	}
+}
+
+// persistAlgorithmCycles saves the decay cycles recorded since the last save
+func (tm *TopologyManager) persistAlgorithmCycles(ctx context.Context) {
+	cycles := tm.slimeMold.AlgorithmCycles(tm.algorithmCycle)
+	if len(cycles) == 0 {
+		return
+	}
+	if err := tm.redisStore.AppendAlgorithmCycles(ctx, cycles, tm.config.AlgorithmHistory); err != nil {
+		tm.logger.Warn("Failed to save algorithm cycles", zap.Error(err))
+		return
+	}
+	tm.algorithmCycle = cycles[len(cycles)-1].Cycle
+}
+
+// reportTopologyHealth saves the topology's current health issues and
+// publishes an insight for each one detected since the last report
+func (tm *TopologyManager) reportTopologyHealth(ctx context.Context) {
+	health := tm.slimeMold.TopologyHealth()
+	if err := tm.redisStore.SaveTopologyHealth(ctx, health); err != nil {
+		tm.logger.Warn("Failed to save topology health", zap.Error(err))
+	}
+
+	reported := make(map[string]time.Time, len(health.Issues))
+	for _, issue := range health.Issues {
+		if at, ok := tm.healthReported[issue.Key()]; ok && at.Equal(issue.DetectedAt) {
+			reported[issue.Key()] = at
+			continue
+		}
+		if err := tm.messaging.PublishInsight(ctx, types.NewTopologyHealthInsight(topologyHealthAuthor, issue)); err != nil {
+			tm.logger.Warn("Failed to publish topology health insight", zap.Error(err), zap.String("issue", issue.Key()))
+			continue
+		}
+		reported[issue.Key()] = issue.DetectedAt
+	}
+	tm.healthReported = reported
+}
+
+// recordDecisionOutcome links the outcome of a task carrying out a decision to its proposal
+func (tm *TopologyManager) recordDecisionOutcome(ctx context.Context, outcome types.TaskOutcome) {
+	decision, ok := outcome.DecisionOutcome()
+	if !ok {
+		return
+	}
+	if err := tm.redisStore.RecordDecisionOutcome(ctx, decision); err != nil {
+		tm.logger.Warn("Failed to record decision outcome", zap.Error(err))
+	}
+}
+
+func (tm *TopologyManager) listenToTopologyEvents(ctx context.Context) {
+	// Listen to topology events (agent joined/left)
+	err := tm.messaging.ConsumeTopologyEvents(ctx, "topology", "topology-manager", func(ctx context.Context, event types.TopologyEvent) error {
+		tm.backfill.observe(ctx)
+		tm.applyTopologyEvent(ctx, event, time.Now())
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		tm.logger.Error("Topology event listener stopped", zap.Error(err))
+	}
+}
+
+// applyTopologyEvent adds or removes the agent of a topology event received at
+// the given time
+func (tm *TopologyManager) applyTopologyEvent(ctx context.Context, event types.TopologyEvent, now time.Time) {
+	switch event.Type {
+	case types.TopologyEventAgentJoined:
+		if event.Agent != nil {
+			event.Agent.PromoteMetadata()
+			event.Agent.Protocol = event.Protocol()
+			if event.Compatibility() == types.CompatibilityDegraded {
+				tm.logger.Warn("Agent joined with a newer protocol, running degraded",
+					zap.String("agent_id", string(event.Agent.ID)),
+					zap.Int("protocol_version", event.Agent.Protocol),
+					zap.Int("supported_version", types.ProtocolVersion))
+			}
+			if err := event.Agent.Validate(); err != nil {
+				tm.logger.Warn("Rejected invalid agent",
+					zap.String("agent_id", string(event.Agent.ID)),
+					zap.Error(err))
+				return
+			}
+			// Only attestations signed by a trusted CA count
+			for _, err := range event.Agent.VerifyAttestations(tm.config.AttestationKeys, now) {
+				tm.logger.Warn("Rejected agent attestation",
+					zap.String("agent_id", string(event.Agent.ID)),
+					zap.Error(err))
+			}
+			tm.sandbox(ctx, event.Agent, now)
+			if err := tm.slimeMold.AddAgent(event.Agent); err != nil {
+				tm.logger.Error("Failed to add agent", zap.Error(err))
+			} else {
+				tm.shadow.addAgent(event.Agent)
+				tm.logger.Info("Agent added to topology",
+					zap.String("agent_id", string(event.Agent.ID)),
+					zap.String("name", event.Agent.Name),
+					zap.String("role", event.Agent.Role))
+			}
+		}
+
+	case types.TopologyEventAgentLeft:
+		if err := tm.slimeMold.RemoveAgent(event.AgentID); err != nil {
+			tm.logger.Error("Failed to remove agent", zap.Error(err))
+		} else {
+			tm.logger.Info("Agent removed from topology", zap.String("agent_id", string(event.AgentID)))
+		}
+		tm.shadow.removeAgent(event.AgentID)
+		tm.routes.Forget(event.AgentID)
+		tm.limiter.Forget(event.AgentID)
+	}
+}
+
+// listenToLifecycleEvents records each agent's last reported lifecycle state
+// on its directory entry, where the API, routing and dashboard read it
+func (tm *TopologyManager) listenToLifecycleEvents(ctx context.Context) {
+	err := tm.messaging.ConsumeMessages(ctx, "lifecycle", "topology-lifecycle", func(ctx context.Context, msg *types.Message) error {
+		tm.backfill.observe(ctx)
+		tm.applyLifecycleEvent(msg)
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		tm.logger.Error("Lifecycle event listener stopped", zap.Error(err))
+	}
+}
+
+// applyLifecycleEvent records the lifecycle state an agent reported
+func (tm *TopologyManager) applyLifecycleEvent(msg *types.Message) {
+	event, err := types.LifecycleEventFrom(msg)
+	if err != nil {
+		tm.logger.Debug("Ignored invalid lifecycle event", zap.String("message_id", msg.ID), zap.Error(err))
+		return
+	}
+
+	lifecycle := &types.AgentLifecycle{State: event.State, Reason: event.Reason, Since: event.Timestamp}
+	tm.shadow.setLifecycle(event.AgentID, lifecycle)
+	if !tm.slimeMold.GetGraph().SetLifecycle(event.AgentID, lifecycle) {
+		tm.logger.Debug("Lifecycle event for unknown agent", zap.String("agent_id", string(event.AgentID)))
+		return
+	}
+	tm.logger.Info("Agent lifecycle changed",
+		zap.String("agent_id", string(event.AgentID)),
+		zap.String("state", string(event.State)),
+		zap.String("reason", event.Reason))
+}
+
+func (tm *TopologyManager) listenToMessages(ctx context.Context) {
+	// Listen to all messages for edge reinforcement
+	err := tm.messaging.ConsumeMessages(ctx, "messages", "topology-reinforcement", func(ctx context.Context, msg *types.Message) error {
+		replaying := tm.backfill.replaying()
+		tm.backfill.observe(ctx)
+		tm.applyMessage(ctx, msg, time.Now(), replaying)
+		return nil
+	})
+
+	if err != nil && err != context.Canceled {
+		tm.logger.Error("Message listener stopped", zap.Error(err))
+	}
+}
+
+// applyMessage reinforces the edge a message was sent over at the given time
+// and scores the route of its task. Messages replayed to rebuild the topology
+// are not recorded again.
+func (tm *TopologyManager) applyMessage(ctx context.Context, msg *types.Message, now time.Time, replaying bool) {
+	// Debug replays reproduce a handler's behaviour without affecting the mesh
+	if msg.Replayed() {
+		tm.logger.Debug("Ignored replayed message",
+			zap.String("id", msg.ID),
+			zap.String("replay_of", msg.ReplayOf))
+		return
+	}
+
+	// Messages a sandboxed agent sends beyond its rate neither shape the
+	// topology nor train routing
+	if tm.slimeMold.GetGraph().Sandboxed(msg.FromAgentID, now) && !tm.limiter.Allow(msg.FromAgentID, now) {
+		tm.logger.Debug("Ignored message over sandbox rate limit",
+			zap.String("from", string(msg.FromAgentID)))
+		return
+	}
+
+	// Reinforce edge for every message
+	if err := tm.slimeMold.ReinforceEdge(msg.FromAgentID, msg.ToAgentID, msg.Type); err != nil {
+		tm.logger.Debug("Failed to reinforce edge", zap.Error(err))
+	}
+	tm.shadow.reinforceEdge(msg.FromAgentID, msg.ToAgentID, msg.Type)
+
+	// Latency from publish to consume; replayed messages were published long ago
+	if !replaying && !msg.Timestamp.IsZero() {
+		tm.slimeMold.ObserveLatency(msg.FromAgentID, msg.ToAgentID, now.Sub(msg.Timestamp))
+	}
+
+	// Score the route a task took once its response arrives
+	if outcome := tm.routes.Observe(msg); outcome != nil {
+		tm.logger.Debug("Task outcome recorded",
+			zap.String("from", string(outcome.From)),
+			zap.String("to", string(outcome.To)),
+			zap.Bool("success", outcome.Success),
+			zap.Duration("latency", outcome.Latency))
+		if !replaying {
+			tm.recordDecisionOutcome(ctx, *outcome)
+		}
+	}
+
+	// Record message history for dashboard replay; replayed messages are already recorded
+	if replaying {
+		return
+	}
+	if err := tm.redisStore.SaveMessage(ctx, msg); err != nil {
+		tm.logger.Debug("Failed to record message history", zap.Error(err))
+	}
+}
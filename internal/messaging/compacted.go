@@ -0,0 +1,136 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// CompactedInsightsTopic holds the latest version of every insight, keyed by
+// insight ID, so Kafka log compaction keeps exactly the current knowledge
+const CompactedInsightsTopic = "insights-compacted"
+
+// EnsureCompactedTopic creates a log-compacted topic, with the broker's default
+// partitions and replication, unless it already exists
+func (km *KafkaMessaging) EnsureCompactedTopic(ctx context.Context, topic string) error {
+	fullTopic := km.config.KafkaTopicPrefix + "." + topic
+	resp, err := km.client().CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{{
+			Topic:             fullTopic,
+			NumPartitions:     -1,
+			ReplicationFactor: -1,
+			ConfigEntries: []kafka.ConfigEntry{
+				{ConfigName: "cleanup.policy", ConfigValue: "compact"},
+			},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", fullTopic, err)
+	}
+	if err := resp.Errors[fullTopic]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return fmt.Errorf("failed to create %s: %w", fullTopic, err)
+	}
+	return nil
+}
+
+// PublishCompactedInsight publishes the current version of an insight to the compacted topic
+func (km *KafkaMessaging) PublishCompactedInsight(ctx context.Context, insight *types.Insight) error {
+	return km.PublishMessage(ctx, CompactedInsightsTopic, InsightMessage(insight))
+}
+
+// ReadCompactedInsights reads every insight in the compacted topic, up to the
+// offsets it ends at when called, without joining a consumer group. Consumers
+// use it to bootstrap the current knowledge before following the insight topic.
+func (km *KafkaMessaging) ReadCompactedInsights(ctx context.Context, handler func(*types.Insight) error) error {
+	fullTopic := km.config.KafkaTopicPrefix + "." + CompactedInsightsTopic
+	partitions, err := km.meshPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	if len(partitions[fullTopic]) == 0 {
+		return nil // Nothing published yet
+	}
+
+	var requests []kafka.OffsetRequest
+	for _, id := range partitions[fullTopic] {
+		requests = append(requests, kafka.FirstOffsetOf(id), kafka.LastOffsetOf(id))
+	}
+	ends, err := km.client().ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{fullTopic: requests},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	for _, p := range ends.Topics[fullTopic] {
+		if p.Error != nil {
+			return fmt.Errorf("failed to list offsets of %s[%d]: %w", fullTopic, p.Partition, p.Error)
+		}
+		if p.LastOffset <= p.FirstOffset {
+			continue
+		}
+		if err := km.readPartition(ctx, fullTopic, p, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPartition reads one partition of the compacted topic up to its last offset
+func (km *KafkaMessaging) readPartition(ctx context.Context, fullTopic string, p kafka.PartitionOffsets, handler func(*types.Insight) error) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   km.config.KafkaBrokers,
+		Topic:     fullTopic,
+		Partition: p.Partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(p.FirstOffset); err != nil {
+		return fmt.Errorf("failed to seek %s[%d]: %w", fullTopic, p.Partition, err)
+	}
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read %s[%d]: %w", fullTopic, p.Partition, err)
+		}
+
+		// Tombstones (empty values) mark deleted keys
+		if len(msg.Value) > 0 {
+			insight, err := decodeCompactedInsight(msg.Value)
+			if err != nil {
+				km.logger.Warn("Skipping unreadable compacted insight", zap.String("key", string(msg.Key)), zap.Error(err))
+			} else if err := handler(insight); err != nil {
+				return err
+			}
+		}
+
+		if msg.Offset >= p.LastOffset-1 {
+			return nil
+		}
+	}
+}
+
+// decodeCompactedInsight decodes the insight carried by a compacted topic record
+func decodeCompactedInsight(value []byte) (*types.Insight, error) {
+	var envelope struct {
+		Payload struct {
+			Insight *types.Insight `json:"insight"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Payload.Insight == nil {
+		return nil, fmt.Errorf("record without an insight")
+	}
+	return envelope.Payload.Insight, nil
+}
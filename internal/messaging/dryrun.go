@@ -0,0 +1,47 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// dryRunGroupSuffix keeps the consumer groups of a dry-run client apart from
+// the live agent's, so a shadow agent neither steals its partitions nor moves
+// its offsets
+const dryRunGroupSuffix = "-dryrun"
+
+// EnableDryRun makes the client log the records it would publish instead of
+// writing them. Consuming is unaffected, so an agent in dry run follows live
+// traffic without influencing topology or consensus.
+func (km *KafkaMessaging) EnableDryRun() {
+	km.dryRun.Store(true)
+	km.logger.Info("Dry run enabled, records will be logged instead of published")
+}
+
+// DryRun reports whether the client is in dry run
+func (km *KafkaMessaging) DryRun() bool {
+	return km.dryRun.Load()
+}
+
+// group returns the consumer group the client joins for groupID
+func (km *KafkaMessaging) group(groupID string) string {
+	if groupID == "" || !km.DryRun() {
+		return groupID
+	}
+	return groupID + dryRunGroupSuffix
+}
+
+// write publishes a record to a topic, or logs it in dry run
+func (km *KafkaMessaging) write(ctx context.Context, topic string, record kafka.Message) error {
+	if km.DryRun() {
+		km.logger.Info("Dry run: would publish",
+			zap.String("topic", km.config.KafkaTopicPrefix+"."+topic),
+			zap.ByteString("key", record.Key),
+			zap.ByteString("value", record.Value),
+		)
+		return nil
+	}
+	return km.GetWriter(topic).WriteMessages(ctx, record)
+}
@@ -3,8 +3,10 @@ package messaging
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -21,6 +23,10 @@ type KafkaMessaging struct {
 	readers   map[string]*kafka.Reader
 	writersMu sync.RWMutex
 	readersMu sync.RWMutex
+
+	degradedPeers sync.Map // degradedPeer -> struct{}
+	dryRun        atomic.Bool
+	cancellations sync.Map // Topic -> *atomic.Int64, see HandlerCancellations
 }
 
 // NewKafkaMessaging creates a new Kafka messaging system
@@ -57,7 +63,7 @@ func (km *KafkaMessaging) GetWriter(topic string) *kafka.Writer {
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(km.config.KafkaBrokers...),
 		Topic:        fullTopic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{}, // Records with the same key share a partition, unkeyed ones spread
 		BatchSize:    100,
 		BatchTimeout: 10 * time.Millisecond,
 		RequiredAcks: kafka.RequireOne,
@@ -72,6 +78,7 @@ func (km *KafkaMessaging) GetWriter(topic string) *kafka.Writer {
 
 // GetReader gets or creates a Kafka reader for a topic
 func (km *KafkaMessaging) GetReader(topic, groupID string) *kafka.Reader {
+	groupID = km.group(groupID)
 	fullTopic := km.config.KafkaTopicPrefix + "." + topic
 	key := fullTopic + ":" + groupID
 
@@ -111,17 +118,18 @@ func (km *KafkaMessaging) GetReader(topic, groupID string) *kafka.Reader {
 	return reader
 }
 
-// PublishMessage publishes a message to a topic
+// PublishMessage publishes a message to a topic, keyed by its ordering key so
+// the messages of a conversation or sender keep their order on one partition
 func (km *KafkaMessaging) PublishMessage(ctx context.Context, topic string, message *types.Message) error {
-	writer := km.GetWriter(topic)
+	types.StampProtocol(&message.ProtocolVersion, &message.MinProtocolVersion)
 
 	data, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	err = writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(message.ID),
+	err = km.write(ctx, topic, kafka.Message{
+		Key:   []byte(message.OrderingKey()),
 		Value: data,
 		Time:  message.Timestamp,
 	})
@@ -139,11 +147,64 @@ func (km *KafkaMessaging) PublishMessage(ctx context.Context, topic string, mess
 	return nil
 }
 
-// ConsumeMessages consumes messages from a topic
-func (km *KafkaMessaging) ConsumeMessages(ctx context.Context, topic, groupID string, handler func(*types.Message) error) error {
+// Permanent reports whether a publish failed in a way retrying cannot fix: the
+// message cannot be encoded, or the broker refuses it, e.g. as too large.
+// Unreachable brokers, leader elections and timeouts are not permanent.
+func Permanent(err error) bool {
+	var (
+		writeErrs   kafka.WriteErrors
+		tooLarge    kafka.MessageTooLargeError
+		kafkaErr    kafka.Error
+		unsupported *json.UnsupportedTypeError
+		badValue    *json.UnsupportedValueError
+		marshaler   *json.MarshalerError
+	)
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &writeErrs):
+		for _, writeErr := range writeErrs {
+			if writeErr != nil && !Permanent(writeErr) {
+				return false
+			}
+		}
+		return writeErrs.Count() > 0
+	case errors.As(err, &tooLarge), errors.As(err, &unsupported), errors.As(err, &badValue), errors.As(err, &marshaler):
+		return true
+	case errors.As(err, &kafkaErr):
+		switch kafkaErr {
+		case kafka.MessageSizeTooLarge, kafka.RecordListTooLarge, kafka.InvalidRecord, kafka.InvalidTimestamp:
+			return true
+		}
+	}
+	return false
+}
+
+// ConsumeMessages consumes messages from a topic. Each handler call gets a
+// context ending after CONSUMER_HANDLER_TIMEOUT or when ctx does. With
+// CONSUMER_WORKERS above 1, handler runs for several messages at once, but one
+// at a time for the messages of a conversation or, outside one, of a sender
+// (see Message.OrderingKey). Messages already read are handled before it returns.
+func (km *KafkaMessaging) ConsumeMessages(ctx context.Context, topic, groupID string, handler func(context.Context, *types.Message) error) error {
 	reader := km.GetReader(topic, groupID)
 	defer reader.Close()
 
+	pool := km.handlers()
+	if pool != nil {
+		defer pool.Wait()
+	}
+	handle := func(message *types.Message) {
+		err := km.runHandler(ctx, topic, func(handlerCtx context.Context) error {
+			return handler(handlerCtx, message)
+		})
+		if err != nil {
+			km.logger.Error("Failed to handle message",
+				zap.Error(err),
+				zap.String("message_id", message.ID),
+			)
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -160,43 +221,90 @@ func (km *KafkaMessaging) ConsumeMessages(ctx context.Context, topic, groupID st
 				km.logger.Error("Failed to unmarshal message", zap.Error(err))
 				continue
 			}
+			if !km.acceptProtocol("message", message.FromAgentID, message.Compatibility(), message.ProtocolVersion, message.MinProtocolVersion) {
+				continue
+			}
 
-			if err := handler(&message); err != nil {
-				km.logger.Error("Failed to handle message",
-					zap.Error(err),
-					zap.String("message_id", message.ID),
-				)
+			if pool == nil {
+				handle(&message)
+				continue
 			}
+			pool.Submit(message.OrderingKey(), func() { handle(&message) },
+				km.overrun(zap.String("topic", topic), zap.String("message_id", message.ID)))
 		}
 	}
 }
 
 // PublishInsight publishes an insight to the knowledge mesh
 func (km *KafkaMessaging) PublishInsight(ctx context.Context, insight *types.Insight) error {
-	// Wrap insight in a message
-	message := &types.Message{
+	return km.PublishMessage(ctx, "insights", InsightMessage(insight))
+}
+
+// InsightMessage wraps an insight in the message published to the insights topic
+func InsightMessage(insight *types.Insight) *types.Message {
+	return &types.Message{
 		ID:          string(insight.ID),
 		FromAgentID: insight.AgentID,
-		Type:        "insight",
+		Type:        types.MessageTypeInsight,
 		Payload: map[string]any{
 			"insight": insight,
 		},
 		Timestamp: insight.CreatedAt,
 	}
+}
+
+// PublishInsightPush broadcasts a high-importance insight to all agents, or only
+// to those of the audience teams if one is given
+func (km *KafkaMessaging) PublishInsightPush(ctx context.Context, insight *types.Insight, audience []string) error {
+	message := &types.Message{
+		ID:          fmt.Sprintf("push-%s", insight.ID),
+		FromAgentID: insight.AgentID,
+		Type:        types.MessageTypeInsightPush,
+		Payload: map[string]any{
+			"insight": insight,
+		},
+		Timestamp: time.Now(),
+	}
+	if audience != nil {
+		message.Payload["audience"] = audience
+	}
+
+	return km.PublishMessage(ctx, "insights-push", message)
+}
+
+// PublishLifecycleEvent publishes an agent's lifecycle state change
+func (km *KafkaMessaging) PublishLifecycleEvent(ctx context.Context, event *types.LifecycleEvent) error {
+	return km.PublishMessage(ctx, "lifecycle", event.Message())
+}
+
+// PublishHeartbeat publishes an agent's heartbeat to the heartbeats topic
+func (km *KafkaMessaging) PublishHeartbeat(ctx context.Context, agentID types.AgentID) error {
+	return km.PublishMessage(ctx, "heartbeats", types.NewHeartbeat(agentID, time.Now()))
+}
+
+// PublishDigest publishes a compiled digest with its Markdown rendering
+func (km *KafkaMessaging) PublishDigest(ctx context.Context, digest *types.Digest, markdown string) error {
+	message := &types.Message{
+		ID:   digest.ID,
+		Type: types.MessageTypeDigest,
+		Payload: map[string]any{
+			"digest":   digest,
+			"markdown": markdown,
+		},
+		Timestamp: digest.GeneratedAt,
+	}
 
-	return km.PublishMessage(ctx, "insights", message)
+	return km.PublishMessage(ctx, "digests", message)
 }
 
 // PublishTopologyEvent publishes a topology event
 func (km *KafkaMessaging) PublishTopologyEvent(ctx context.Context, event types.TopologyEvent) error {
-	writer := km.GetWriter("topology")
-
-	data, err := json.Marshal(event)
+	data, err := types.EncodeTopologyEvent(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return err
 	}
 
-	err = writer.WriteMessages(ctx, kafka.Message{
+	err = km.write(ctx, "topology", kafka.Message{
 		Key:   []byte(string(event.Type)),
 		Value: data,
 		Time:  event.Timestamp,
@@ -209,11 +317,29 @@ func (km *KafkaMessaging) PublishTopologyEvent(ctx context.Context, event types.
 	return nil
 }
 
-// ConsumeTopologyEvents consumes topology events from a topic
-func (km *KafkaMessaging) ConsumeTopologyEvents(ctx context.Context, topic, groupID string, handler func(types.TopologyEvent) error) error {
+// ConsumeTopologyEvents consumes topology events from a topic, with handler
+// contexts and workers as ConsumeMessages. Events are handled one at a time
+// per agent or edge (see topologyEventKey).
+func (km *KafkaMessaging) ConsumeTopologyEvents(ctx context.Context, topic, groupID string, handler func(context.Context, types.TopologyEvent) error) error {
 	reader := km.GetReader(topic, groupID)
 	defer reader.Close()
 
+	pool := km.handlers()
+	if pool != nil {
+		defer pool.Wait()
+	}
+	handle := func(event types.TopologyEvent) {
+		err := km.runHandler(ctx, topic, func(handlerCtx context.Context) error {
+			return handler(handlerCtx, event)
+		})
+		if err != nil {
+			km.logger.Error("Failed to handle topology event",
+				zap.Error(err),
+				zap.String("event_type", string(event.Type)),
+			)
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -225,42 +351,112 @@ func (km *KafkaMessaging) ConsumeTopologyEvents(ctx context.Context, topic, grou
 				continue
 			}
 
-			var event types.TopologyEvent
-			if err := json.Unmarshal(msg.Value, &event); err != nil {
-				km.logger.Error("Failed to unmarshal topology event", zap.Error(err))
+			event, err := types.DecodeTopologyEvent(msg.Value)
+			if err != nil {
+				km.logger.Error("Failed to decode topology event", zap.Error(err))
+				continue
+			}
+			if !km.acceptProtocol("topology_event", event.AgentID, event.Compatibility(), event.ProtocolVersion, event.MinProtocolVersion) {
 				continue
 			}
 
-			if err := handler(event); err != nil {
-				km.logger.Error("Failed to handle topology event",
-					zap.Error(err),
-					zap.String("event_type", string(event.Type)),
-				)
+			if pool == nil {
+				handle(event)
+				continue
 			}
+			pool.Submit(topologyEventKey(event), func() { handle(event) },
+				km.overrun(zap.String("topic", topic), zap.String("event_type", string(event.Type))))
 		}
 	}
 }
 
-// PublishProposal publishes a consensus proposal
-func (km *KafkaMessaging) PublishProposal(ctx context.Context, proposal *types.Proposal) error {
-	writer := km.GetWriter("proposals")
-
-	data, err := json.Marshal(proposal)
-	if err != nil {
-		return fmt.Errorf("failed to marshal proposal: %w", err)
+// topologyEventKey groups the topology events handled in the order published:
+// an agent's joins and leaves, an edge's changes, and the freezes and
+// unfreezes of the whole topology
+func topologyEventKey(event types.TopologyEvent) string {
+	switch {
+	case event.AgentID != "":
+		return "agent/" + string(event.AgentID)
+	case event.Agent != nil:
+		return "agent/" + string(event.Agent.ID)
+	case event.EdgeID != "":
+		return "edge/" + string(event.EdgeID)
 	}
+	return "topology"
+}
 
-	err = writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(string(proposal.ID)),
-		Value: data,
-		Time:  proposal.CreatedAt,
-	})
+// ProposalOptions are the optional parts of a proposal request, none by default
+type ProposalOptions struct {
+	Template  string                // Template to create the proposal from, instead of its type and content
+	Params    map[string]any        // Parameters substituted into the template
+	Window    *types.ProposalWindow // Time window the proposal is only valid in
+	Policy    string                // Standing policy it supersedes once accepted
+	DependsOn []types.ProposalID    // Proposals that must be accepted before it can be
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to write proposal: %w", err)
+// PublishProposal asks the consensus manager to create a proposal by the
+// proposal's proposer, of its type and content unless opts names a template
+func (km *KafkaMessaging) PublishProposal(ctx context.Context, proposal *types.Proposal, opts ProposalOptions) error {
+	request := map[string]any{"proposer_id": string(proposal.ProposerID)}
+	if opts.Template != "" {
+		request["template"] = opts.Template
+		if opts.Params != nil {
+			request["params"] = opts.Params
+		}
+	} else {
+		request["type"] = string(proposal.Type)
+		request["content"] = proposal.Content
+	}
+	if opts.Window != nil {
+		request["window"] = map[string]any{
+			"start": opts.Window.Start.Format(time.RFC3339),
+			"end":   opts.Window.End.Format(time.RFC3339),
+		}
+	}
+	if opts.Policy != "" {
+		request["policy"] = opts.Policy
+	}
+	if len(opts.DependsOn) > 0 {
+		prerequisites := make([]any, len(opts.DependsOn))
+		for i, id := range opts.DependsOn {
+			prerequisites[i] = string(id)
+		}
+		request["depends_on"] = prerequisites
 	}
 
-	return nil
+	message := &types.Message{
+		ID:          fmt.Sprintf("%s-proposal-%d", proposal.ProposerID, time.Now().UnixNano()),
+		FromAgentID: proposal.ProposerID,
+		Type:        types.MessageTypeWaggle,
+		Payload:     map[string]any{"proposal": request},
+		Timestamp:   time.Now(),
+	}
+	return km.PublishMessage(ctx, "proposals", message)
+}
+
+// PublishBundle asks the consensus manager to create a bundle of proposals voted on as a slate.
+// Each proposal is a {type, content} or {template, params} object.
+func (km *KafkaMessaging) PublishBundle(ctx context.Context, proposerID types.AgentID, title string, rule types.BundleRule, bestOf int, proposals []map[string]any) error {
+	members := make([]any, len(proposals))
+	for i, proposal := range proposals {
+		members[i] = proposal
+	}
+	message := &types.Message{
+		ID:          fmt.Sprintf("%s-bundle-%d", proposerID, time.Now().UnixNano()),
+		FromAgentID: proposerID,
+		Type:        types.MessageTypeWaggle,
+		Payload: map[string]any{
+			"bundle": map[string]any{
+				"proposer_id": string(proposerID),
+				"title":       title,
+				"rule":        string(rule),
+				"best_of":     bestOf,
+				"proposals":   members,
+			},
+		},
+		Timestamp: time.Now(),
+	}
+	return km.PublishMessage(ctx, "proposals", message)
 }
 
 // Close closes all Kafka connections
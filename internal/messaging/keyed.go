@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyedQueue runs tasks on a bounded number of goroutines with a queue per
+// key: the tasks of a key run one at a time in the order submitted, those of
+// different keys concurrently. Consumers hand it the records that must stay in
+// order, e.g. by Message.OrderingKey.
+type KeyedQueue struct {
+	timeout time.Duration
+	workers chan struct{} // Keys being run
+	backlog chan struct{} // Tasks submitted and not done yet
+
+	mu      sync.Mutex
+	pending map[string][]keyedTask // Waiting tasks of the keys being run
+	wg      sync.WaitGroup
+}
+
+// keyedTask is a task waiting in a KeyedQueue
+type keyedTask struct {
+	run     func()
+	overrun func() // Nil = not watched
+}
+
+// NewKeyedQueue creates a queue running up to workers keys at once, which
+// holds at most backlog tasks submitted and not done yet. With a timeout, a
+// task still running after it is reported to its overrun callback.
+func NewKeyedQueue(workers, backlog int, timeout time.Duration) *KeyedQueue {
+	workers = max(workers, 1)
+	return &KeyedQueue{
+		timeout: timeout,
+		workers: make(chan struct{}, workers),
+		backlog: make(chan struct{}, max(backlog, workers)),
+		pending: make(map[string][]keyedTask),
+	}
+}
+
+// Submit queues a task behind the earlier ones of its key. It blocks while the
+// backlog is full, or a new key waits for a worker. overrun, if not nil, is
+// called once the task runs past the timeout; the later tasks of its key keep
+// waiting until it returns.
+func (q *KeyedQueue) Submit(key string, run func(), overrun func()) {
+	q.backlog <- struct{}{}
+	task := keyedTask{run: run, overrun: overrun}
+
+	q.mu.Lock()
+	if queue, active := q.pending[key]; active {
+		q.pending[key] = append(queue, task)
+		q.mu.Unlock()
+		return
+	}
+	q.pending[key] = nil
+	q.mu.Unlock()
+
+	q.workers <- struct{}{}
+	q.wg.Add(1)
+	go q.drain(key, task)
+}
+
+// drain runs a key's tasks until its queue is empty
+func (q *KeyedQueue) drain(key string, task keyedTask) {
+	defer q.wg.Done()
+	for active := true; active; {
+		q.call(task)
+		<-q.backlog
+
+		q.mu.Lock()
+		if queue := q.pending[key]; len(queue) > 0 {
+			task, q.pending[key] = queue[0], queue[1:]
+		} else {
+			delete(q.pending, key)
+			active = false
+		}
+		q.mu.Unlock()
+	}
+	<-q.workers
+}
+
+// call runs a task, watching it for the timeout
+func (q *KeyedQueue) call(task keyedTask) {
+	if q.timeout > 0 && task.overrun != nil {
+		timer := time.AfterFunc(q.timeout, task.overrun)
+		defer timer.Stop()
+	}
+	task.run()
+}
+
+// Wait blocks until every submitted task is done
+func (q *KeyedQueue) Wait() {
+	q.wg.Wait()
+}
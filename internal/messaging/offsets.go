@@ -0,0 +1,295 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// GroupOffsets records the committed offsets of a consumer group on mesh topics
+type GroupOffsets struct {
+	GroupID string                   `json:"group_id"`
+	Topics  map[string]map[int]int64 `json:"topics"` // topic -> partition -> committed offset
+}
+
+// client returns a kafka-go admin client for the configured brokers
+func (km *KafkaMessaging) client() *kafka.Client {
+	return &kafka.Client{
+		Addr:    kafka.TCP(km.config.KafkaBrokers...),
+		Timeout: 10 * time.Second,
+	}
+}
+
+// meshPartitions returns the partitions of every topic under the configured prefix
+func (km *KafkaMessaging) meshPartitions(ctx context.Context) (map[string][]int, error) {
+	metadata, err := km.client().Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	prefix := km.config.KafkaTopicPrefix + "."
+	partitions := make(map[string][]int)
+	for _, topic := range metadata.Topics {
+		if !strings.HasPrefix(topic.Name, prefix) {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+		}
+	}
+
+	return partitions, nil
+}
+
+// ConsumerGroupOffsets returns committed offsets of all consumer groups on mesh topics
+func (km *KafkaMessaging) ConsumerGroupOffsets(ctx context.Context) ([]GroupOffsets, error) {
+	partitions, err := km.meshPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return []GroupOffsets{}, nil
+	}
+
+	client := km.client()
+	groups, err := client.ListGroups(ctx, &kafka.ListGroupsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	if groups.Error != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", groups.Error)
+	}
+
+	result := []GroupOffsets{}
+	for _, group := range groups.Groups {
+		resp, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+			GroupID: group.GroupID,
+			Topics:  partitions,
+		})
+		if err != nil {
+			km.logger.Warn("Failed to fetch group offsets", zap.String("group_id", group.GroupID), zap.Error(err))
+			continue
+		}
+
+		offsets := GroupOffsets{GroupID: group.GroupID, Topics: map[string]map[int]int64{}}
+		for topic, topicPartitions := range resp.Topics {
+			for _, p := range topicPartitions {
+				if p.Error != nil || p.CommittedOffset < 0 {
+					continue
+				}
+				if offsets.Topics[topic] == nil {
+					offsets.Topics[topic] = map[int]int64{}
+				}
+				offsets.Topics[topic][p.Partition] = p.CommittedOffset
+			}
+		}
+
+		if len(offsets.Topics) > 0 {
+			result = append(result, offsets)
+		}
+	}
+
+	return result, nil
+}
+
+// CommitGroupOffsets commits offsets on behalf of a consumer group.
+// The group must have no active members, otherwise the broker rejects the commit.
+func (km *KafkaMessaging) CommitGroupOffsets(ctx context.Context, offsets GroupOffsets) error {
+	topics := make(map[string][]kafka.OffsetCommit)
+	for topic, partitions := range offsets.Topics {
+		for partition, offset := range partitions {
+			topics[topic] = append(topics[topic], kafka.OffsetCommit{Partition: partition, Offset: offset})
+		}
+	}
+
+	resp, err := km.client().OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      offsets.GroupID,
+		GenerationID: -1,
+		Topics:       topics,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit offsets for %s: %w", offsets.GroupID, err)
+	}
+
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return fmt.Errorf("failed to commit %s[%d] for %s: %w", topic, p.Partition, offsets.GroupID, p.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ConsumerLag returns how many messages a consumer group is behind on each mesh topic it consumes.
+// Topics the group has never committed to are omitted; uncommitted partitions count from the start of the log.
+func (km *KafkaMessaging) ConsumerLag(ctx context.Context, groupID string) (map[string]int64, error) {
+	partitions, err := km.meshPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lag := make(map[string]int64)
+	if len(partitions) == 0 {
+		return lag, nil
+	}
+
+	client := km.client()
+
+	requests := make(map[string][]kafka.OffsetRequest)
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			requests[topic] = append(requests[topic], kafka.FirstOffsetOf(id), kafka.LastOffsetOf(id))
+		}
+	}
+	ends, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets for %s: %w", groupID, err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("failed to fetch offsets for %s: %w", groupID, committed.Error)
+	}
+
+	positions := make(map[string]map[int]int64)
+	for topic, topicPartitions := range committed.Topics {
+		for _, p := range topicPartitions {
+			if p.Error != nil || p.CommittedOffset < 0 {
+				continue
+			}
+			if positions[topic] == nil {
+				positions[topic] = make(map[int]int64)
+			}
+			positions[topic][p.Partition] = p.CommittedOffset
+		}
+	}
+
+	for topic, topicPartitions := range ends.Topics {
+		if positions[topic] == nil {
+			continue
+		}
+		lag[topic] = 0
+		for _, p := range topicPartitions {
+			if p.Error != nil {
+				continue
+			}
+			position, ok := positions[topic][p.Partition]
+			if !ok {
+				position = p.FirstOffset
+			}
+			if behind := p.LastOffset - position; behind > 0 {
+				lag[topic] += behind
+			}
+		}
+	}
+
+	return lag, nil
+}
+
+// SeekGroup moves a consumer group's committed offsets on topics to their first
+// (StartModeEarliest) or last (StartModeLatest) offsets and returns how many
+// messages the group will replay. StartModeResume leaves the offsets alone.
+// The group must have no active members, otherwise the broker rejects the commit.
+func (km *KafkaMessaging) SeekGroup(ctx context.Context, groupID string, topics []string, mode types.StartMode) (int64, error) {
+	if mode == types.StartModeResume {
+		return 0, nil
+	}
+
+	partitions, err := km.meshPartitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	requests := make(map[string][]kafka.OffsetRequest)
+	for _, topic := range topics {
+		fullTopic := km.config.KafkaTopicPrefix + "." + topic
+		for _, id := range partitions[fullTopic] {
+			requests[fullTopic] = append(requests[fullTopic], kafka.FirstOffsetOf(id), kafka.LastOffsetOf(id))
+		}
+	}
+	if len(requests) == 0 {
+		return 0, nil // Topics not created yet, nothing to skip or replay
+	}
+
+	ends, err := km.client().ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	var replay int64
+	offsets := GroupOffsets{GroupID: km.group(groupID), Topics: map[string]map[int]int64{}}
+	for topic, topicPartitions := range ends.Topics {
+		for _, p := range topicPartitions {
+			if p.Error != nil {
+				return 0, fmt.Errorf("failed to list offsets of %s[%d]: %w", topic, p.Partition, p.Error)
+			}
+			if offsets.Topics[topic] == nil {
+				offsets.Topics[topic] = map[int]int64{}
+			}
+			if mode == types.StartModeEarliest {
+				offsets.Topics[topic][p.Partition] = p.FirstOffset
+				replay += p.LastOffset - p.FirstOffset
+			} else {
+				offsets.Topics[topic][p.Partition] = p.LastOffset
+			}
+		}
+	}
+
+	if err := km.CommitGroupOffsets(ctx, offsets); err != nil {
+		return 0, err
+	}
+	return replay, nil
+}
+
+// Ping checks that the brokers are reachable
+func (km *KafkaMessaging) Ping(ctx context.Context) error {
+	if _, err := km.client().Metadata(ctx, &kafka.MetadataRequest{Topics: []string{}}); err != nil {
+		return fmt.Errorf("failed to reach Kafka brokers: %w", err)
+	}
+	return nil
+}
+
+// TopicHealth describes a mesh topic as the brokers report it
+type TopicHealth struct {
+	Topic       string `json:"topic"` // Without the prefix
+	Partitions  int    `json:"partitions"`
+	Unavailable int    `json:"unavailable"` // Partitions with no leader or a metadata error
+}
+
+// DescribeTopics returns the health of the given mesh topics; topics that do not exist are omitted
+func (km *KafkaMessaging) DescribeTopics(ctx context.Context, topics ...string) (map[string]TopicHealth, error) {
+	prefix := km.config.KafkaTopicPrefix + "."
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = prefix + topic
+	}
+
+	metadata, err := km.client().Metadata(ctx, &kafka.MetadataRequest{Topics: names})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	described := make(map[string]TopicHealth)
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil || !strings.HasPrefix(topic.Name, prefix) {
+			continue // Unknown topic
+		}
+		health := TopicHealth{Topic: strings.TrimPrefix(topic.Name, prefix), Partitions: len(topic.Partitions)}
+		for _, partition := range topic.Partitions {
+			if partition.Error != nil || partition.Leader.Host == "" {
+				health.Unavailable++
+			}
+		}
+		described[health.Topic] = health
+	}
+	return described, nil
+}
@@ -0,0 +1,43 @@
+package messaging
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// acceptProtocol decides whether a consumed envelope is handled.
+// Unsupported envelopes are dropped; envelopes from newer peers are handled with
+// unknown fields ignored, and the downgrade is logged once per sender and version.
+func (km *KafkaMessaging) acceptProtocol(kind string, sender types.AgentID, compatibility types.Compatibility, version, minVersion int) bool {
+	switch compatibility {
+	case types.CompatibilityUnsupported:
+		km.logger.Warn("Dropping envelope with unsupported protocol version",
+			zap.String("kind", kind),
+			zap.String("sender", string(sender)),
+			zap.Int("protocol_version", version),
+			zap.Int("min_protocol_version", minVersion),
+			zap.Int("supported_version", types.ProtocolVersion),
+		)
+		return false
+
+	case types.CompatibilityDegraded:
+		key := degradedPeer{sender: sender, version: version}
+		if _, seen := km.degradedPeers.LoadOrStore(key, struct{}{}); !seen {
+			km.logger.Info("Peer speaks a newer protocol, ignoring unknown fields",
+				zap.String("kind", kind),
+				zap.String("sender", string(sender)),
+				zap.Int("protocol_version", version),
+				zap.Int("supported_version", types.ProtocolVersion),
+			)
+		}
+	}
+
+	return true
+}
+
+// degradedPeer identifies a sender already reported as running a newer protocol
+type degradedPeer struct {
+	sender  types.AgentID
+	version int
+}
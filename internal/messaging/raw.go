@@ -0,0 +1,53 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// TailRaw reads records published to a topic from now on, without decoding them,
+// until ctx is cancelled. groupID should be unique so no other consumer is affected.
+func (km *KafkaMessaging) TailRaw(ctx context.Context, topic, groupID string, handler func(key, value []byte, at time.Time) error) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     km.config.KafkaBrokers,
+		Topic:       km.config.KafkaTopicPrefix + "." + topic,
+		GroupID:     groupID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     100 * time.Millisecond,
+		StartOffset: kafka.LastOffset,
+	})
+	defer reader.Close()
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			km.logger.Error("Failed to read record", zap.String("topic", topic), zap.Error(err))
+			continue
+		}
+
+		if err := handler(msg.Key, msg.Value, msg.Time); err != nil {
+			km.logger.Error("Failed to handle record", zap.String("topic", topic), zap.Error(err))
+		}
+	}
+}
+
+// PublishRaw writes an already encoded record to a topic
+func (km *KafkaMessaging) PublishRaw(ctx context.Context, topic string, key, value []byte) error {
+	err := km.write(ctx, topic, kafka.Message{
+		Key:   key,
+		Value: value,
+		Time:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ReplayRecord is a record replayed from a mesh topic, decoded the way the
+// topic's listener decodes it: a topology event on the topology topic, a
+// message on any other. Both are nil for records that could not be decoded or
+// carry an unsupported protocol version.
+type ReplayRecord struct {
+	Topic     string // Without the topic prefix
+	Partition int
+	Offset    int64
+	Time      time.Time // When the record was published
+	Event     *types.TopologyEvent
+	Message   *types.Message
+}
+
+// ReplayPlan is the span of mesh topics a replay reads: on every partition,
+// from the first record published at or after From to the end of the
+// partition when the replay was planned
+type ReplayPlan struct {
+	From   time.Time
+	Total  int64 // Records to replay
+	topics []string
+	ranges []replayRange
+}
+
+// replayRange is the span of one partition a replay reads
+type replayRange struct {
+	topic      string // Without the topic prefix
+	partition  int
+	start, end int64 // end is the offset after the last record replayed
+}
+
+// PlanReplay finds where each partition of topics reaches from, and where it
+// ends now. Records published later are left to the topics' consumer groups.
+func (km *KafkaMessaging) PlanReplay(ctx context.Context, topics []string, from time.Time) (*ReplayPlan, error) {
+	partitions, err := km.meshPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	starts := make(map[string][]kafka.OffsetRequest)
+	ends := make(map[string][]kafka.OffsetRequest)
+	for _, topic := range topics {
+		fullTopic := km.config.KafkaTopicPrefix + "." + topic
+		for _, id := range partitions[fullTopic] {
+			starts[fullTopic] = append(starts[fullTopic], kafka.TimeOffsetOf(id, from))
+			ends[fullTopic] = append(ends[fullTopic], kafka.LastOffsetOf(id))
+		}
+	}
+
+	plan := &ReplayPlan{From: from, topics: topics}
+	if len(ends) == 0 {
+		return plan, nil // Topics not created yet, nothing to replay
+	}
+
+	// Time lookups go in a request of their own, as a request may ask for each partition once
+	startOffsets, err := km.client().ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: starts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets at %s: %w", from.Format(time.RFC3339), err)
+	}
+	endOffsets, err := km.client().ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: ends})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	for _, topic := range topics {
+		fullTopic := km.config.KafkaTopicPrefix + "." + topic
+		startOf := make(map[int]int64)
+		for _, p := range startOffsets.Topics[fullTopic] {
+			if p.Error != nil {
+				return nil, fmt.Errorf("failed to list offsets of %s[%d]: %w", fullTopic, p.Partition, p.Error)
+			}
+			// A partition with no record at or after from has no offset, or -1
+			startOf[p.Partition] = -1
+			for offset := range p.Offsets {
+				startOf[p.Partition] = offset
+			}
+		}
+		for _, p := range endOffsets.Topics[fullTopic] {
+			if p.Error != nil {
+				return nil, fmt.Errorf("failed to list offsets of %s[%d]: %w", fullTopic, p.Partition, p.Error)
+			}
+			start, ok := startOf[p.Partition]
+			if !ok || start < 0 || start > p.LastOffset {
+				start = p.LastOffset
+			}
+			plan.ranges = append(plan.ranges, replayRange{topic: topic, partition: p.Partition, start: start, end: p.LastOffset})
+			plan.Total += p.LastOffset - start
+		}
+	}
+	return plan, nil
+}
+
+// Replay reads the records of a plan and hands them to handler in a
+// deterministic order: by publish time, then in the order the plan's topics
+// were given, then by partition and offset. It stops at the first error.
+func (km *KafkaMessaging) Replay(ctx context.Context, plan *ReplayPlan, handler func(ReplayRecord) error) error {
+	type head struct {
+		rng    replayRange
+		order  int
+		reader *kafka.Reader
+		msg    kafka.Message
+	}
+	order := make(map[string]int, len(plan.topics))
+	for i, topic := range plan.topics {
+		order[topic] = i
+	}
+
+	var heads []*head
+	defer func() {
+		for _, h := range heads {
+			h.reader.Close()
+		}
+	}()
+	read := func(h *head) error {
+		msg, err := h.reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read %s[%d]: %w", h.rng.topic, h.rng.partition, err)
+		}
+		h.msg = msg
+		return nil
+	}
+
+	for _, rng := range plan.ranges {
+		if rng.start >= rng.end {
+			continue
+		}
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   km.config.KafkaBrokers,
+			Topic:     km.config.KafkaTopicPrefix + "." + rng.topic,
+			Partition: rng.partition,
+			MinBytes:  1,
+			MaxBytes:  10e6,
+		})
+		h := &head{rng: rng, order: order[rng.topic], reader: reader}
+		heads = append(heads, h)
+		if err := reader.SetOffset(rng.start); err != nil {
+			return fmt.Errorf("failed to seek %s[%d]: %w", rng.topic, rng.partition, err)
+		}
+		if err := read(h); err != nil {
+			return err
+		}
+	}
+
+	for len(heads) > 0 {
+		next := 0
+		for i, h := range heads[1:] {
+			if replayBefore(h.msg, h.order, heads[next].msg, heads[next].order) {
+				next = i + 1
+			}
+		}
+		h := heads[next]
+
+		if err := handler(km.decodeReplayRecord(h.rng.topic, h.msg)); err != nil {
+			return err
+		}
+
+		if h.msg.Offset >= h.rng.end-1 {
+			h.reader.Close()
+			heads = append(heads[:next], heads[next+1:]...)
+			continue
+		}
+		if err := read(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayBefore reports whether record a, of the topic at order aOrder in the
+// plan, is replayed before record b
+func replayBefore(a kafka.Message, aOrder int, b kafka.Message, bOrder int) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time)
+	}
+	if aOrder != bOrder {
+		return aOrder < bOrder
+	}
+	if a.Partition != b.Partition {
+		return a.Partition < b.Partition
+	}
+	return a.Offset < b.Offset
+}
+
+// decodeReplayRecord decodes a replayed record as the topic's listener would
+func (km *KafkaMessaging) decodeReplayRecord(topic string, msg kafka.Message) ReplayRecord {
+	record := ReplayRecord{Topic: topic, Partition: msg.Partition, Offset: msg.Offset, Time: msg.Time}
+
+	if topic == "topology" {
+		event, err := types.DecodeTopologyEvent(msg.Value)
+		if err != nil {
+			km.logger.Warn("Skipping undecodable topology event", zap.Int64("offset", msg.Offset), zap.Error(err))
+			return record
+		}
+		if km.acceptProtocol("topology_event", event.AgentID, event.Compatibility(), event.ProtocolVersion, event.MinProtocolVersion) {
+			record.Event = &event
+		}
+		return record
+	}
+
+	var message types.Message
+	if err := json.Unmarshal(msg.Value, &message); err != nil {
+		km.logger.Warn("Skipping undecodable message", zap.String("topic", topic), zap.Int64("offset", msg.Offset), zap.Error(err))
+		return record
+	}
+	if km.acceptProtocol("message", message.FromAgentID, message.Compatibility(), message.ProtocolVersion, message.MinProtocolVersion) {
+		record.Message = &message
+	}
+	return record
+}
+
+// CommitReplay moves a consumer group's offsets on topics to where a replay
+// ended, so the group's listener continues with the first record not replayed
+func (km *KafkaMessaging) CommitReplay(ctx context.Context, plan *ReplayPlan, groupID string, topics ...string) error {
+	offsets := GroupOffsets{GroupID: km.group(groupID), Topics: map[string]map[int]int64{}}
+	for _, rng := range plan.ranges {
+		for _, topic := range topics {
+			if rng.topic != topic {
+				continue
+			}
+			fullTopic := km.config.KafkaTopicPrefix + "." + topic
+			if offsets.Topics[fullTopic] == nil {
+				offsets.Topics[fullTopic] = map[int]int64{}
+			}
+			offsets.Topics[fullTopic][rng.partition] = rng.end
+		}
+	}
+	if len(offsets.Topics) == 0 {
+		return nil
+	}
+	return km.CommitGroupOffsets(ctx, offsets)
+}
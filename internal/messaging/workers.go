@@ -0,0 +1,64 @@
+package messaging
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// handlers returns the queue a consumer hands records to, up to
+// CONSUMER_WORKERS at once with CONSUMER_MAX_IN_FLIGHT read and not handled
+// yet, or nil to handle them on the reading goroutine, one at a time without a
+// timeout
+func (km *KafkaMessaging) handlers() *KeyedQueue {
+	workers := max(km.config.ConsumerWorkers, 1)
+	if workers == 1 && km.config.ConsumerHandlerTimeout <= 0 {
+		return nil
+	}
+	return NewKeyedQueue(workers, km.config.ConsumerMaxInFlight, km.config.ConsumerHandlerTimeout)
+}
+
+// overrun returns the warning about a handler still running after
+// CONSUMER_HANDLER_TIMEOUT despite its context's deadline. The later records
+// of its key wait until it returns, so they never overlap with it.
+func (km *KafkaMessaging) overrun(fields ...zap.Field) func() {
+	return func() {
+		km.logger.Warn("Handler timed out, holding its key until it returns",
+			append(fields, zap.Duration("timeout", km.config.ConsumerHandlerTimeout))...)
+	}
+}
+
+// handlerContext returns the context a handler of a record consumed under ctx
+// runs with, ending after CONSUMER_HANDLER_TIMEOUT (if set) or with ctx
+func (km *KafkaMessaging) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if km.config.ConsumerHandlerTimeout > 0 {
+		return context.WithTimeout(ctx, km.config.ConsumerHandlerTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// runHandler calls handle with a handler context, counting a call whose
+// context ended before it returned as a cancellation on topic
+func (km *KafkaMessaging) runHandler(ctx context.Context, topic string, handle func(context.Context) error) error {
+	handlerCtx, cancel := km.handlerContext(ctx)
+	defer cancel()
+
+	err := handle(handlerCtx)
+	if handlerCtx.Err() != nil {
+		count, _ := km.cancellations.LoadOrStore(topic, new(atomic.Int64))
+		count.(*atomic.Int64).Add(1)
+	}
+	return err
+}
+
+// HandlerCancellations returns per topic how many handler calls outlasted
+// their context, timing out or running into shutdown, since the start
+func (km *KafkaMessaging) HandlerCancellations() map[string]int64 {
+	counts := make(map[string]int64)
+	km.cancellations.Range(func(topic, count any) bool {
+		counts[topic.(string)] = count.(*atomic.Int64).Load()
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,275 @@
+package personas
+
+import (
+	"fmt"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// registry builds a fresh persona per role so callers can tune their copy
+var registry = map[string]func() *Persona{
+	"sales":       sales,
+	"support":     support,
+	"inventory":   inventory,
+	"fraud":       fraud,
+	"research":    research,
+	"analyst":     analyst,
+	"coordinator": coordinator,
+}
+
+// sales checks stock with inventory and has fraud verify transactions
+func sales() *Persona {
+	return &Persona{
+		Role:         "sales",
+		Capabilities: []string{"order_management", "pricing", "customer_relations"},
+		Behaviors: []Behavior{
+			{
+				Every:   2,
+				Targets: []string{"inventory"},
+				Payload: func(tick int, target string) map[string]any {
+					product := fmt.Sprintf("Product-%d", tick)
+					return payload(&types.CheckStock{
+						Product:     product,
+						SKU:         fmt.Sprintf("SKU-%d", tick%50),
+						Qty:         tick % 10,
+						Description: fmt.Sprintf("Check stock availability for %s (qty: %d)", product, tick%10),
+					})
+				},
+			},
+			{
+				Every:   3,
+				Targets: []string{"fraud"},
+				Payload: func(tick int, target string) map[string]any {
+					orderID := fmt.Sprintf("ORD-%d", tick)
+					amount := float64(tick * 100)
+					return payload(&types.VerifyTransaction{
+						OrderID:     orderID,
+						Amount:      amount,
+						Description: fmt.Sprintf("Verify transaction %s ($%.2f)", orderID, amount),
+					})
+				},
+			},
+		},
+		Learning: []LearnRule{{
+			Actions:    []string{"check_price", "negotiate_price"},
+			Type:       types.InsightTypePricingIssue,
+			Topic:      "pricing",
+			Confidence: 0.7,
+			Content: func(msg *types.Message) string {
+				return fmt.Sprintf("Customer interested in pricing for %v", msg.Payload["product"])
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// support escalates tickets to sales, inventory and fraud
+func support() *Persona {
+	return &Persona{
+		Role:         "support",
+		Capabilities: []string{"ticketing", "customer_support", "escalation"},
+		Behaviors: []Behavior{{
+			Every:   2,
+			Targets: []string{"sales", "inventory", "fraud"},
+			Payload: func(tick int, target string) map[string]any {
+				ticketID := fmt.Sprintf("TKT-%d", tick)
+				action, issueType := "escalate", "pricing_complaint"
+				switch target {
+				case "inventory":
+					action, issueType = "check_delivery", "shipping_delay"
+				case "fraud":
+					action, issueType = "verify_account", "suspicious_activity"
+				default:
+					return payload(&types.EscalateTicket{
+						TicketID:    ticketID,
+						IssueType:   issueType,
+						Description: fmt.Sprintf("Support %s for ticket %s - %s", action, ticketID, issueType),
+					})
+				}
+				return map[string]any{
+					"action":      action,
+					"ticket_id":   ticketID,
+					"issue_type":  issueType,
+					"description": fmt.Sprintf("Support %s for ticket %s - %s", action, ticketID, issueType),
+				}
+			},
+		}},
+		Goals: []GoalBehavior{{
+			// Minutes the previous ticket took to resolve
+			GoalID:  GoalTicketResolution,
+			Every:   3,
+			Measure: func(tick int) float64 { return float64(20 + (tick*17)%70) },
+		}},
+		Learning: []LearnRule{{
+			Actions:    []string{"report_issue"},
+			Type:       types.InsightTypeProductIssue,
+			Topic:      "product_quality",
+			Confidence: 0.85,
+			Content: func(msg *types.Message) string {
+				return fmt.Sprintf("Customer reported issue: %v", msg.Payload["issue"])
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// inventory alerts sales to low stock and support to delayed deliveries
+func inventory() *Persona {
+	return &Persona{
+		Role:         "inventory",
+		Capabilities: []string{"stock_management", "forecasting", "logistics"},
+		Behaviors: []Behavior{{
+			Every:   2,
+			Targets: []string{"sales", "support"},
+			Payload: func(tick int, target string) map[string]any {
+				product := fmt.Sprintf("Product-%d", tick)
+				if target != "support" {
+					return payload(&types.StockAlert{
+						Product:     product,
+						Level:       "low",
+						Description: fmt.Sprintf("stock_alert for %s - status: low", product),
+					})
+				}
+				action, level := "delivery_update", "delayed"
+				return map[string]any{
+					"action":      action,
+					"product":     product,
+					"level":       level,
+					"description": fmt.Sprintf("%s for %s - status: %s", action, product, level),
+				}
+			},
+		}},
+		Goals: []GoalBehavior{{
+			// Percentage of SKUs currently out of stock
+			GoalID:  GoalStockoutRate,
+			Every:   3,
+			Measure: func(tick int) float64 { return float64((tick*7)%40) / 10 },
+		}},
+		Learning: []LearnRule{{
+			Actions:    []string{"check_stock"},
+			Type:       types.InsightTypeInventoryTrend,
+			Topic:      "inventory",
+			Confidence: 0.5,
+			Content: func(msg *types.Message) string {
+				check, err := types.DecodeTaskAction(msg)
+				if err != nil {
+					return "Stock check for an unknown SKU"
+				}
+				return fmt.Sprintf("Stock check for SKU: %s", check.(*types.CheckStock).SKU)
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// fraud raises alerts to sales and account suspensions to support
+func fraud() *Persona {
+	return &Persona{
+		Role:         "fraud",
+		Capabilities: []string{"fraud_detection", "risk_scoring", "verification"},
+		Behaviors: []Behavior{{
+			Every:   3,
+			Targets: []string{"sales", "support"},
+			Payload: func(tick int, target string) map[string]any {
+				txnID := fmt.Sprintf("TXN-%d", tick)
+				if target != "support" {
+					return payload(&types.FraudAlert{
+						Transaction: txnID,
+						RiskLevel:   "medium",
+						Description: fmt.Sprintf("fraud_alert for transaction %s - risk: medium", txnID),
+					})
+				}
+				action, riskLevel := "account_suspension", "high"
+				return map[string]any{
+					"action":      action,
+					"transaction": txnID,
+					"risk_level":  riskLevel,
+					"description": fmt.Sprintf("%s for transaction %s - risk: %s", action, txnID, riskLevel),
+				}
+			},
+		}},
+		Learning: []LearnRule{{
+			Actions:    []string{"verify_user", "check_transaction"},
+			Type:       types.InsightTypeFraudPattern,
+			Topic:      "fraud_detection",
+			Confidence: 0.6,
+			Content: func(msg *types.Message) string {
+				return fmt.Sprintf("Verification requested for %v", msg.Payload["user_id"])
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// research requests market data from sales, support and inventory
+func research() *Persona {
+	return &Persona{
+		Role:         "research",
+		Capabilities: []string{"research", "market_analysis", "web_search"},
+		Behaviors: []Behavior{{
+			Every:   2,
+			Targets: []string{"sales", "support", "inventory"},
+			Payload: func(tick int, target string) map[string]any {
+				return map[string]any{
+					"action":      "research_request",
+					"topic":       fmt.Sprintf("market_trend_%d", tick),
+					"priority":    "high",
+					"description": fmt.Sprintf("Research: Requesting %s data for market analysis #%d", target, tick),
+				}
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// analyst shares analysis reports with sales, inventory and fraud
+func analyst() *Persona {
+	return &Persona{
+		Role:         "analyst",
+		Capabilities: []string{"data_analysis", "forecasting", "reporting"},
+		Behaviors: []Behavior{{
+			Every:   2,
+			Targets: []string{"sales", "inventory", "fraud"},
+			Payload: func(tick int, target string) map[string]any {
+				return map[string]any{
+					"action":      "analysis_report",
+					"metric":      fmt.Sprintf("kpi_%d", tick),
+					"trend":       "increasing",
+					"description": fmt.Sprintf("Analyst: Market analysis report #%d for %s", tick, target),
+				}
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// coordinator sends health checks to every other role
+func coordinator() *Persona {
+	return &Persona{
+		Role:         "coordinator",
+		Capabilities: []string{"coordination", "synthesis", "decision_making"},
+		Behaviors: []Behavior{{
+			Every:   2,
+			Targets: []string{"sales", "support", "inventory", "fraud", "research", "analyst"},
+			Payload: func(tick int, target string) map[string]any {
+				return map[string]any{
+					"action":      "coordination_update",
+					"status":      "all_systems_operational",
+					"check_id":    fmt.Sprintf("health_check_%d", tick),
+					"description": fmt.Sprintf("Coordinator: System health check #%d - %s status OK", tick, target),
+				}
+			},
+		}},
+		Params: DefaultParams(),
+	}
+}
+
+// payload encodes a typed task action, falling back to just its name should
+// encoding fail
+func payload(action types.TaskAction) map[string]any {
+	encoded, err := types.EncodeTaskAction(action)
+	if err != nil {
+		return map[string]any{"action": action.Action()}
+	}
+	return encoded
+}
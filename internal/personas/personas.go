@@ -0,0 +1,225 @@
+// Package personas describes how simulated agents behave.
+//
+// A Persona bundles what an agent of one role sends (which roles it messages,
+// how often and with which payloads), which goals it reports on and which
+// insights it learns from the tasks it receives. cmd/agent, the examples and
+// the load generator compose personas instead of each carrying their own
+// per-role switch. Params tune a persona without redefining it.
+package personas
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Goals the simulated agents report progress towards. Define them with
+// POST /api/goals using these IDs to see them on the dashboard.
+const (
+	GoalTicketResolution types.GoalID = "ticket-resolution-time"
+	GoalStockoutRate     types.GoalID = "stockout-rate"
+)
+
+// DefaultInterval is the time between persona ticks
+const DefaultInterval = 10 * time.Second
+
+// Action is a message a persona sends to an agent of a role
+type Action struct {
+	TargetRole string
+	Type       types.MessageType
+	Payload    map[string]any
+}
+
+// Report is a goal measurement a persona reports
+type Report struct {
+	GoalID types.GoalID
+	Value  float64
+}
+
+// Behavior is one kind of message a persona sends every Every ticks,
+// rotating through Targets by tick
+type Behavior struct {
+	Every   int
+	Targets []string
+	Payload func(tick int, target string) map[string]any
+}
+
+// GoalBehavior reports a goal measurement every Every ticks
+type GoalBehavior struct {
+	GoalID  types.GoalID
+	Every   int
+	Measure func(tick int) float64
+}
+
+// LearnRule turns a received task with one of Actions into an insight
+type LearnRule struct {
+	Actions    []string
+	Type       types.InsightType
+	Topic      string
+	Confidence float64
+	Content    func(msg *types.Message) string
+}
+
+// Params tune a persona's behavior
+type Params struct {
+	Activity    float64       // Multiplies how often messages and reports are sent (1 = as defined)
+	Targets     []string      // Replaces the roles every behavior messages when non-empty
+	Interval    time.Duration // Time between ticks
+	FailureRate float64       // Share of received tasks answered as failed (0-1)
+}
+
+// Persona is the simulated behavior of an agent role
+type Persona struct {
+	Role         string
+	Capabilities []string
+	Behaviors    []Behavior
+	Goals        []GoalBehavior
+	Learning     []LearnRule
+	Params       Params
+}
+
+// DefaultParams returns the parameters personas are defined with
+func DefaultParams() Params {
+	return Params{Activity: 1, Interval: DefaultInterval}
+}
+
+// Tick returns what the persona sends and reports on the given tick (starting at 1)
+func (p *Persona) Tick(tick int) ([]Action, []Report) {
+	var actions []Action
+	for i := range p.Behaviors {
+		b := &p.Behaviors[i]
+		if tick%p.every(b.Every) == 0 {
+			actions = append(actions, p.action(b, tick))
+		}
+	}
+
+	var reports []Report
+	for _, g := range p.Goals {
+		if tick%p.every(g.Every) == 0 {
+			reports = append(reports, Report{GoalID: g.GoalID, Value: g.Measure(tick)})
+		}
+	}
+	return actions, reports
+}
+
+// Compose returns the n-th message the persona sends regardless of schedule,
+// cycling through its behaviors; ok is false when it sends nothing
+func (p *Persona) Compose(n int) (Action, bool) {
+	if len(p.Behaviors) == 0 {
+		return Action{}, false
+	}
+	b := &p.Behaviors[n%len(p.Behaviors)]
+	return p.action(b, n), true
+}
+
+// Learn returns the insight an agent of this persona learns from a message, or nil
+func (p *Persona) Learn(agent *types.Agent, msg *types.Message) *types.Insight {
+	if msg.Type != types.MessageTypeTask {
+		return nil
+	}
+	action := msg.ActionName()
+	for _, rule := range p.Learning {
+		for _, a := range rule.Actions {
+			if a == action {
+				return types.NewInsight(agent.ID, agent.Role, rule.Type, rule.Topic, rule.Content(msg), rule.Confidence)
+			}
+		}
+	}
+	return nil
+}
+
+// Fails reports whether the persona answers the n-th received task as failed.
+// Failures are spread evenly so that FailureRate holds over any run of tasks.
+func (p *Persona) Fails(n int) bool {
+	rate := math.Max(0, math.Min(1, p.Params.FailureRate))
+	return math.Floor(float64(n+1)*rate) > math.Floor(float64(n)*rate)
+}
+
+// action builds a behavior's message for a tick
+func (p *Persona) action(b *Behavior, tick int) Action {
+	targets := b.Targets
+	if len(p.Params.Targets) > 0 {
+		targets = p.Params.Targets
+	}
+	target := targets[tick%len(targets)]
+	return Action{
+		TargetRole: target,
+		Type:       types.MessageTypeTask,
+		Payload:    b.Payload(tick, target),
+	}
+}
+
+// every scales an interval in ticks by Activity
+func (p *Persona) every(ticks int) int {
+	activity := p.Params.Activity
+	if activity <= 0 {
+		activity = 1
+	}
+	return max(1, int(math.Round(float64(ticks)/activity)))
+}
+
+// Get returns a copy of the persona for a role
+func Get(role string) (*Persona, bool) {
+	build, ok := registry[role]
+	if !ok {
+		return nil, false
+	}
+	return build(), true
+}
+
+// Roles returns the roles that have a persona, sorted
+func Roles() []string {
+	roles := make([]string, 0, len(registry))
+	for role := range registry {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// ParseParams applies comma-separated key=value overrides to params.
+// Keys are activity, targets (separated by |), interval and failure_rate,
+// e.g. "activity=2,targets=sales|support,failure_rate=0.1".
+func ParseParams(params *Params, spec string) error {
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("invalid persona parameter %q: want key=value", field)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "activity":
+			activity, err := strconv.ParseFloat(value, 64)
+			if err != nil || activity <= 0 {
+				return fmt.Errorf("invalid activity %q: want a positive number", value)
+			}
+			params.Activity = activity
+		case "targets":
+			params.Targets = strings.Split(value, "|")
+		case "interval":
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("invalid interval %q: want a positive duration", value)
+			}
+			params.Interval = interval
+		case "failure_rate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return fmt.Errorf("invalid failure_rate %q: want a number between 0 and 1", value)
+			}
+			params.FailureRate = rate
+		default:
+			return fmt.Errorf("unknown persona parameter %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package ranking scores insights by importance.
+//
+// An insight's score blends four signals: novelty (how few insights of the
+// same topic and type came before it, not counting recent ones from other
+// agents, which corroborate rather than repeat it), the reputation of the
+// agent that reported it (how often other agents corroborated that agent and
+// how often its accepted proposals achieved their intent),
+// corroboration (how many other agents reported the same topic around the
+// same time) and recency. Scores order query results and decide which new
+// insights are pushed to agents proactively.
+package ranking
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Signal weights; they sum to 1 so scores stay within 0-1
+const (
+	noveltyWeight       = 0.25
+	reputationWeight    = 0.25
+	corroborationWeight = 0.3
+	recencyWeight       = 0.2
+)
+
+// Ranker scores insights against a corpus of known insights
+type Ranker struct {
+	config *types.Config
+
+	byTopic    map[string][]*types.Insight // Sorted by CreatedAt
+	reputation map[types.AgentID]float64
+}
+
+// NewRanker indexes the corpus. The corpus is read, not retained, so the
+// Ranker reflects the insights known when it was created.
+func NewRanker(config *types.Config, corpus []*types.Insight) *Ranker {
+	r := &Ranker{
+		config:     config,
+		byTopic:    make(map[string][]*types.Insight),
+		reputation: make(map[types.AgentID]float64),
+	}
+
+	for _, insight := range corpus {
+		r.byTopic[insight.Topic] = append(r.byTopic[insight.Topic], insight)
+	}
+	for _, insights := range r.byTopic {
+		sort.Slice(insights, func(i, j int) bool { return insights[i].CreatedAt.Before(insights[j].CreatedAt) })
+	}
+
+	// Reputation: Laplace-smoothed share of an agent's insights that others
+	// corroborated and of its decisions that achieved their intent
+	total := make(map[types.AgentID]int)
+	corroborated := make(map[types.AgentID]int)
+	for _, insight := range corpus {
+		if proposer, verdict, ok := insight.DecisionImpact(); ok {
+			total[proposer]++
+			if verdict == types.DecisionVerdictAchieved {
+				corroborated[proposer]++
+			}
+			continue
+		}
+		total[insight.AgentID]++
+		if r.corroborators(insight) > 0 {
+			corroborated[insight.AgentID]++
+		}
+	}
+	for agentID, n := range total {
+		r.reputation[agentID] = float64(corroborated[agentID]+1) / float64(n+2)
+	}
+	return r
+}
+
+// Score ranks an insight, which need not be part of the corpus
+func (r *Ranker) Score(insight *types.Insight, now time.Time) types.InsightRank {
+	// Earlier reports are repeats if they are older than the corroboration
+	// window or came from the same agent
+	earlier := 0
+	corroborationStart := insight.CreatedAt.Add(-r.config.CorroborationWindow)
+	for _, other := range r.byTopic[insight.Topic] {
+		if !other.CreatedAt.Before(insight.CreatedAt) {
+			break
+		}
+		if other.Type == insight.Type && other.ID != insight.ID &&
+			(other.AgentID == insight.AgentID || other.CreatedAt.Before(corroborationStart)) {
+			earlier++
+		}
+	}
+
+	corroborators := r.corroborators(insight)
+	rank := types.InsightRank{
+		Novelty:       1 / float64(1+earlier),
+		Reputation:    r.Reputation(insight.AgentID),
+		Corroboration: float64(corroborators) / float64(corroborators+2),
+		Recency:       r.recency(insight.CreatedAt, now),
+		Corroborators: corroborators,
+	}
+	rank.Score = noveltyWeight*rank.Novelty +
+		reputationWeight*rank.Reputation +
+		corroborationWeight*rank.Corroboration +
+		recencyWeight*rank.Recency
+	return rank
+}
+
+// Reputation returns an agent's reputation, 0.5 for agents without insights
+func (r *Ranker) Reputation(agentID types.AgentID) float64 {
+	if reputation, ok := r.reputation[agentID]; ok {
+		return reputation
+	}
+	return 0.5
+}
+
+// Sort ranks insights in place, most important first, and sets their Rank
+func (r *Ranker) Sort(insights []types.Insight, now time.Time) {
+	for i := range insights {
+		rank := r.Score(&insights[i], now)
+		insights[i].Rank = &rank
+	}
+	sort.SliceStable(insights, func(i, j int) bool {
+		return insights[i].Rank.Score > insights[j].Rank.Score
+	})
+}
+
+// ShouldPush reports whether a rank is high enough to push the insight to agents
+func (r *Ranker) ShouldPush(rank types.InsightRank) bool {
+	return r.config.InsightPushThreshold > 0 && rank.Score >= r.config.InsightPushThreshold
+}
+
+// corroborators counts other agents reporting the insight's topic within CorroborationWindow of it
+func (r *Ranker) corroborators(insight *types.Insight) int {
+	agents := make(map[types.AgentID]bool)
+	for _, other := range r.byTopic[insight.Topic] {
+		if other.AgentID == insight.AgentID {
+			continue
+		}
+		gap := other.CreatedAt.Sub(insight.CreatedAt)
+		if math.Abs(float64(gap)) <= float64(r.config.CorroborationWindow) {
+			agents[other.AgentID] = true
+		}
+	}
+	return len(agents)
+}
+
+// recency halves every InsightHalfLife
+func (r *Ranker) recency(createdAt, now time.Time) float64 {
+	if r.config.InsightHalfLife <= 0 {
+		return 1
+	}
+	age := now.Sub(createdAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(r.config.InsightHalfLife))
+}
@@ -0,0 +1,46 @@
+package replay
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Frame is a historical snapshot or message the dashboard replays
+type Frame struct {
+	At       time.Time
+	Snapshot *types.GraphSnapshot // Set for snapshot frames
+	Message  *types.Message       // Set for message frames
+}
+
+// Timeline merges snapshot and message history into frames in time order. A
+// snapshot comes before a message with the same timestamp, and frames of the
+// same kind and time keep their order.
+func Timeline(snapshots []*types.GraphSnapshot, messages []*types.Message) []Frame {
+	frames := make([]Frame, 0, len(snapshots)+len(messages))
+	for _, snapshot := range snapshots {
+		frames = append(frames, Frame{At: snapshot.Timestamp, Snapshot: snapshot})
+	}
+	for _, message := range messages {
+		frames = append(frames, Frame{At: message.Timestamp, Message: message})
+	}
+
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].At.Before(frames[j].At)
+	})
+	return frames
+}
+
+// ParseTime accepts RFC3339 timestamps or Unix seconds
+func ParseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("required")
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,37 @@
+package routing
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// balanceFloor is the least score a candidate is drawn with, so that agents
+// without an edge yet still receive tasks and grow one
+const balanceFloor = 0.05
+
+// Balance draws one of the ranked candidates at random, each with a chance
+// proportional to its score over one plus its tasks in flight. Tasks to a
+// role spread over all of its agents, favouring strong edges and idle agents.
+func Balance(ranked []types.RouteCandidate) (types.RouteCandidate, bool) {
+	if len(ranked) == 0 {
+		return types.RouteCandidate{}, false
+	}
+
+	shares := make([]float64, len(ranked))
+	total := 0.0
+	for i, c := range ranked {
+		shares[i] = (balanceFloor + math.Max(0, c.Score)) / float64(1+max(0, c.Load))
+		total += shares[i]
+	}
+
+	draw := rand.Float64() * total
+	for i, share := range shares {
+		if draw < share {
+			return ranked[i], true
+		}
+		draw -= share
+	}
+	return ranked[len(ranked)-1], true
+}
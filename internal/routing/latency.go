@@ -0,0 +1,28 @@
+package routing
+
+import (
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// PreferLowLatency scales the score of ranked candidates by the latency of
+// the edge to them against ROUTING_LATENCY_TARGET, halving it at the target,
+// and ranks them again, so tasks favour fast links. Candidates without latency
+// samples, or all of them without a target, are left as they are.
+func PreferLowLatency(config *types.Config, ranked []types.RouteCandidate) []types.RouteCandidate {
+	if config.RoutingLatencyTarget <= 0 {
+		return ranked
+	}
+	for i := range ranked {
+		ranked[i].Score *= types.LatencyFactor(ranked[i].LatencyMs, config.RoutingLatencyTarget)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].AgentID < ranked[j].AgentID
+	})
+	return ranked
+}
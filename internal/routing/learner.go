@@ -0,0 +1,284 @@
+// Package routing scores routes by the outcomes of the tasks sent along them.
+//
+// Pheromone weights only measure how often a route is used. The Learner adds
+// feedback on how well it works: every task response (success, failure and
+// latency) updates a per-route value, and Rank can blend that value into the
+// choice of which agent receives the next task.
+package routing
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// initialValue is the value assumed for a route without outcomes
+const initialValue = 0.5
+
+// latencyEMA is the smoothing factor of the route latency average
+const latencyEMA = 0.2
+
+// routeKey identifies a directed route
+type routeKey struct {
+	from types.AgentID
+	to   types.AgentID
+}
+
+// pendingTask is a task waiting for its response
+type pendingTask struct {
+	from     types.AgentID
+	to       types.AgentID
+	sentAt   time.Time
+	proposal types.ProposalID // Decision the task carries out, if any
+}
+
+// Learner records task outcomes per route and learns a value for each route
+type Learner struct {
+	config *types.Config
+
+	mu      sync.Mutex
+	pending map[string]pendingTask
+	routes  map[routeKey]*types.RouteStats
+}
+
+// NewLearner creates a learner using the routing settings of config
+func NewLearner(config *types.Config) *Learner {
+	return &Learner{
+		config:  config,
+		pending: make(map[string]pendingTask),
+		routes:  make(map[routeKey]*types.RouteStats),
+	}
+}
+
+// Observe tracks tasks and matches responses to them.
+// It returns the outcome when msg completes a pending task, or nil.
+func (l *Learner) Observe(msg *types.Message) *types.TaskOutcome {
+	switch msg.Type {
+	case types.MessageTypeTask:
+		if msg.FromAgentID == msg.ToAgentID {
+			return nil
+		}
+		l.mu.Lock()
+		proposal, _ := msg.Payload["proposal_id"].(string)
+		l.pending[msg.ID] = pendingTask{from: msg.FromAgentID, to: msg.ToAgentID, sentAt: msg.Timestamp, proposal: types.ProposalID(proposal)}
+		l.mu.Unlock()
+
+	case types.MessageTypeResponse:
+		if msg.InReplyTo == "" {
+			return nil
+		}
+		l.mu.Lock()
+		task, ok := l.pending[msg.InReplyTo]
+		delete(l.pending, msg.InReplyTo)
+		l.mu.Unlock()
+		if !ok {
+			return nil
+		}
+
+		outcome := types.TaskOutcome{
+			TaskID:      msg.InReplyTo,
+			From:        task.from,
+			To:          task.to,
+			Success:     msg.Succeeded(),
+			Latency:     max(0, msg.Timestamp.Sub(task.sentAt)),
+			CompletedAt: msg.Timestamp,
+			ProposalID:  task.proposal,
+		}
+		l.Record(outcome)
+		return &outcome
+	}
+	return nil
+}
+
+// ExpirePending fails tasks that have waited longer than TaskTimeout and returns their outcomes
+func (l *Learner) ExpirePending(now time.Time) []types.TaskOutcome {
+	timeout := l.config.TaskTimeout
+	if timeout <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	var expired []types.TaskOutcome
+	for id, task := range l.pending {
+		if now.Sub(task.sentAt) >= timeout {
+			expired = append(expired, types.TaskOutcome{
+				TaskID:      id,
+				From:        task.from,
+				To:          task.to,
+				TimedOut:    true,
+				Latency:     now.Sub(task.sentAt),
+				CompletedAt: now,
+				ProposalID:  task.proposal,
+			})
+			delete(l.pending, id)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, outcome := range expired {
+		l.Record(outcome)
+	}
+	return expired
+}
+
+// Record updates a route's statistics and learned value with an outcome
+func (l *Learner) Record(outcome types.TaskOutcome) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := routeKey{from: outcome.From, to: outcome.To}
+	stats, ok := l.routes[key]
+	if !ok {
+		stats = &types.RouteStats{From: outcome.From, To: outcome.To, Value: initialValue}
+		l.routes[key] = stats
+	}
+
+	stats.Tasks++
+	if outcome.Success {
+		stats.Successes++
+		latencyMs := float64(outcome.Latency.Milliseconds())
+		if stats.Successes == 1 {
+			stats.AvgLatencyMs = latencyMs
+		} else {
+			stats.AvgLatencyMs += latencyEMA * (latencyMs - stats.AvgLatencyMs)
+		}
+	} else {
+		stats.Failures++
+		if outcome.TimedOut {
+			stats.Timeouts++
+		}
+	}
+
+	stats.Value += l.config.RoutingLearningRate * (l.reward(outcome) - stats.Value)
+	stats.UpdatedAt = outcome.CompletedAt
+}
+
+// reward scores an outcome: 0 for a failure, up to 1 for an instant success.
+// Successes lose up to half their reward as latency approaches TaskTimeout.
+func (l *Learner) reward(outcome types.TaskOutcome) float64 {
+	if !outcome.Success {
+		return 0
+	}
+	if l.config.TaskTimeout <= 0 {
+		return 1
+	}
+	slowness := math.Min(1, float64(outcome.Latency)/float64(l.config.TaskTimeout))
+	return 1 - 0.5*slowness
+}
+
+// Forget drops the routes and pending tasks of an agent that left the mesh
+func (l *Learner) Forget(agentID types.AgentID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.routes {
+		if key.from == agentID || key.to == agentID {
+			delete(l.routes, key)
+		}
+	}
+	for id, task := range l.pending {
+		if task.from == agentID || task.to == agentID {
+			delete(l.pending, id)
+		}
+	}
+}
+
+// Stats returns the statistics of every route, ordered by source and target
+func (l *Learner) Stats() []types.RouteStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make([]types.RouteStats, 0, len(l.routes))
+	for _, s := range l.routes {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].From != stats[j].From {
+			return stats[i].From < stats[j].From
+		}
+		return stats[i].To < stats[j].To
+	})
+	return stats
+}
+
+// Load returns how many tasks each agent has in flight, i.e. received and not
+// responded to yet
+func (l *Learner) Load() map[types.AgentID]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	load := make(map[types.AgentID]int)
+	for _, task := range l.pending {
+		load[task.to]++
+	}
+	return load
+}
+
+// Restore replaces the route statistics, e.g. with ones loaded from Redis
+func (l *Learner) Restore(stats []types.RouteStats) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.routes = make(map[routeKey]*types.RouteStats, len(stats))
+	for i := range stats {
+		s := stats[i]
+		l.routes[routeKey{from: s.From, to: s.To}] = &s
+	}
+}
+
+// Rank scores candidates for a task from an agent, best first.
+// Without RoutingLearning the score is the pheromone weight; with it, the score
+// blends in the learned route value by RoutingLearnWeight.
+func Rank(config *types.Config, from types.AgentID, candidates []types.RouteCandidate, stats []types.RouteStats) []types.RouteCandidate {
+	values := make(map[types.AgentID]float64)
+	for _, s := range stats {
+		if s.From == from {
+			values[s.To] = s.Value
+		}
+	}
+
+	ranked := make([]types.RouteCandidate, len(candidates))
+	copy(ranked, candidates)
+	for i := range ranked {
+		c := &ranked[i]
+		c.Score = c.Weight
+		value, ok := values[c.AgentID]
+		if ok {
+			c.Value = &value
+		} else {
+			value = initialValue
+		}
+		if config.RoutingLearning {
+			weight := math.Max(0, math.Min(1, config.RoutingLearnWeight))
+			c.Score = (1-weight)*c.Weight + weight*value
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].AgentID < ranked[j].AgentID
+	})
+	return ranked
+}
+
+// Select picks the best ranked candidate, or with probability RoutingExploration
+// (only while learning) a random one so that untried routes still get outcomes.
+// With RoutingLoadBalance it draws one by score and load instead, see Balance.
+func Select(config *types.Config, ranked []types.RouteCandidate) (types.RouteCandidate, bool) {
+	if config.RoutingLoadBalance {
+		return Balance(ranked)
+	}
+	if len(ranked) == 0 {
+		return types.RouteCandidate{}, false
+	}
+	if config.RoutingLearning && len(ranked) > 1 && rand.Float64() < config.RoutingExploration {
+		return ranked[rand.Intn(len(ranked))], true
+	}
+	return ranked[0], true
+}
@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"math"
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// PreferRegion lowers the score of ranked candidates outside the sender's region
+// by CrossRegionPenalty and ranks them again, so tasks stay within a region
+// unless a remote agent is clearly better. Candidates or senders without a
+// region are left as they are.
+func PreferRegion(config *types.Config, region string, ranked []types.RouteCandidate) []types.RouteCandidate {
+	if region == "" {
+		return ranked
+	}
+	penalty := math.Max(0, math.Min(1, config.CrossRegionPenalty))
+	for i := range ranked {
+		if ranked[i].Region != "" && ranked[i].Region != region {
+			ranked[i].Score *= 1 - penalty
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].AgentID < ranked[j].AgentID
+	})
+	return ranked
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// algorithmCyclesKey holds the topology manager's latest decay cycles, oldest first
+const algorithmCyclesKey = "topology:algorithm"
+
+// AppendAlgorithmCycles appends decay cycles, keeping only the latest keep
+func (rs *RedisStore) AppendAlgorithmCycles(ctx context.Context, cycles []types.AlgorithmCycle, keep int) error {
+	if len(cycles) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(cycles))
+	for i, cycle := range cycles {
+		data, err := json.Marshal(cycle)
+		if err != nil {
+			return fmt.Errorf("failed to marshal algorithm cycle: %w", err)
+		}
+		values[i] = data
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.RPush(ctx, algorithmCyclesKey, values...)
+	pipe.LTrim(ctx, algorithmCyclesKey, int64(-keep), -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save algorithm cycles: %w", err)
+	}
+	return nil
+}
+
+// LoadAlgorithmCycles returns the latest limit decay cycles, oldest first, or all with limit 0
+func (rs *RedisStore) LoadAlgorithmCycles(ctx context.Context, limit int) ([]types.AlgorithmCycle, error) {
+	values, err := rs.client.LRange(ctx, algorithmCyclesKey, int64(-limit), -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load algorithm cycles: %w", err)
+	}
+
+	cycles := make([]types.AlgorithmCycle, 0, len(values))
+	for _, value := range values {
+		var cycle types.AlgorithmCycle
+		if err := json.Unmarshal([]byte(value), &cycle); err != nil {
+			continue
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles, nil
+}
@@ -0,0 +1,62 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// analyticsSecretKey holds the key analytics noise is derived from
+const analyticsSecretKey = "analytics:secret"
+
+// AnalyticsSecret returns the key analytics noise is derived from, created on
+// first use and shared by every replica so they all add the same noise
+func (rs *RedisStore) AnalyticsSecret(ctx context.Context) ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate analytics secret: %w", err)
+	}
+	if err := rs.client.SetNX(ctx, analyticsSecretKey, secret, 0).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save analytics secret: %w", err)
+	}
+	secret, err := rs.client.Get(ctx, analyticsSecretKey).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics secret: %w", err)
+	}
+	return secret, nil
+}
+
+// SaveAnalytics caches a noised analytics release, so the same window is
+// answered with the same noise until it expires
+func (rs *RedisStore) SaveAnalytics(ctx context.Context, key string, analytics *types.InsightAnalytics, ttl time.Duration) error {
+	data, err := json.Marshal(analytics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics: %w", err)
+	}
+	if err := rs.client.Set(ctx, fmt.Sprintf("analytics:%s", key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save analytics: %w", err)
+	}
+	return nil
+}
+
+// LoadAnalytics loads a cached analytics release, nil if there is none
+func (rs *RedisStore) LoadAnalytics(ctx context.Context, key string) (*types.InsightAnalytics, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("analytics:%s", key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load analytics: %w", err)
+	}
+
+	var analytics types.InsightAnalytics
+	if err := json.Unmarshal(data, &analytics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analytics: %w", err)
+	}
+	return &analytics, nil
+}
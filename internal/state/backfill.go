@@ -0,0 +1,39 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// SaveBackfillProgress records the progress of a manager rebuilding its state from Kafka
+func (rs *RedisStore) SaveBackfillProgress(ctx context.Context, progress *types.BackfillProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backfill progress: %w", err)
+	}
+	if err := rs.client.Set(ctx, fmt.Sprintf("backfill:%s", progress.Manager), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save backfill progress: %w", err)
+	}
+	return nil
+}
+
+// LoadBackfillProgress returns a manager's latest backfill, or nil if it never rebuilt its state
+func (rs *RedisStore) LoadBackfillProgress(ctx context.Context, manager string) (*types.BackfillProgress, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("backfill:%s", manager)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load backfill progress: %w", err)
+	}
+
+	var progress types.BackfillProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backfill progress: %w", err)
+	}
+	return &progress, nil
+}
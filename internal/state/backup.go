@@ -0,0 +1,166 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// KeyDump is a portable, type-aware copy of a single Redis key.
+// Unlike DUMP/RESTORE payloads it is plain JSON and independent of the Redis version.
+type KeyDump struct {
+	Key     string            `json:"key"`
+	Type    string            `json:"type"`
+	TTLMs   int64             `json:"ttl_ms,omitempty"`  // Remaining TTL, 0 = no expiry
+	Value   string            `json:"value,omitempty"`   // string keys
+	Members []string          `json:"members,omitempty"` // set and list keys
+	Scored  []ScoredMember    `json:"scored,omitempty"`  // sorted set keys
+	Fields  map[string]string `json:"fields,omitempty"`  // hash keys
+}
+
+// ScoredMember is a sorted set member with its score
+type ScoredMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// DumpKeys copies every key matching pattern (e.g. "*") into portable dumps
+func (rs *RedisStore) DumpKeys(ctx context.Context, pattern string) ([]KeyDump, error) {
+	dumps := []KeyDump{}
+
+	iter := rs.client.Scan(ctx, 0, pattern, 500).Iterator()
+	for iter.Next(ctx) {
+		dump, err := rs.dumpKey(ctx, iter.Val())
+		if err == redis.Nil {
+			// Key expired between SCAN and read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dumps = append(dumps, *dump)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan keys: %w", err)
+	}
+
+	return dumps, nil
+}
+
+// dumpKey reads a single key according to its Redis type
+func (rs *RedisStore) dumpKey(ctx context.Context, key string) (*KeyDump, error) {
+	keyType, err := rs.client.Type(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read type of %s: %w", key, err)
+	}
+
+	dump := &KeyDump{Key: key, Type: keyType}
+
+	ttl, err := rs.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TTL of %s: %w", key, err)
+	}
+	if ttl > 0 {
+		dump.TTLMs = ttl.Milliseconds()
+	}
+
+	switch keyType {
+	case "none":
+		return nil, redis.Nil
+	case "string":
+		dump.Value, err = rs.client.Get(ctx, key).Result()
+	case "set":
+		dump.Members, err = rs.client.SMembers(ctx, key).Result()
+	case "list":
+		dump.Members, err = rs.client.LRange(ctx, key, 0, -1).Result()
+	case "hash":
+		dump.Fields, err = rs.client.HGetAll(ctx, key).Result()
+	case "zset":
+		var scored []redis.Z
+		scored, err = rs.client.ZRangeWithScores(ctx, key, 0, -1).Result()
+		for _, z := range scored {
+			dump.Scored = append(dump.Scored, ScoredMember{Member: fmt.Sprint(z.Member), Score: z.Score})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q for key %s", keyType, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	return dump, nil
+}
+
+// RestoreKeys writes dumps back to Redis, preserving remaining TTLs.
+// Existing keys are skipped unless overwrite is set.
+func (rs *RedisStore) RestoreKeys(ctx context.Context, dumps []KeyDump, overwrite bool) (restored int, skipped int, err error) {
+	for _, dump := range dumps {
+		if !overwrite {
+			exists, err := rs.client.Exists(ctx, dump.Key).Result()
+			if err != nil {
+				return restored, skipped, fmt.Errorf("failed to check %s: %w", dump.Key, err)
+			}
+			if exists > 0 {
+				skipped++
+				continue
+			}
+		}
+
+		pipe := rs.client.TxPipeline()
+		pipe.Del(ctx, dump.Key)
+
+		switch dump.Type {
+		case "string":
+			pipe.Set(ctx, dump.Key, dump.Value, 0)
+		case "set":
+			if len(dump.Members) > 0 {
+				pipe.SAdd(ctx, dump.Key, toInterfaces(dump.Members)...)
+			}
+		case "list":
+			if len(dump.Members) > 0 {
+				pipe.RPush(ctx, dump.Key, toInterfaces(dump.Members)...)
+			}
+		case "hash":
+			if len(dump.Fields) > 0 {
+				pipe.HSet(ctx, dump.Key, dump.Fields)
+			}
+		case "zset":
+			members := make([]redis.Z, len(dump.Scored))
+			for i, m := range dump.Scored {
+				members[i] = redis.Z{Member: m.Member, Score: m.Score}
+			}
+			if len(members) > 0 {
+				pipe.ZAdd(ctx, dump.Key, members...)
+			}
+		default:
+			rs.logger.Warn("Skipping key with unsupported type",
+				zap.String("key", dump.Key),
+				zap.String("type", dump.Type),
+			)
+			skipped++
+			continue
+		}
+
+		if dump.TTLMs > 0 {
+			pipe.PExpire(ctx, dump.Key, time.Duration(dump.TTLMs)*time.Millisecond)
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return restored, skipped, fmt.Errorf("failed to restore %s: %w", dump.Key, err)
+		}
+		restored++
+	}
+
+	return restored, skipped, nil
+}
+
+func toInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// bundlesIndexKey is the set of known proposal bundle IDs
+const bundlesIndexKey = "bundles:all"
+
+// SaveBundle saves a proposal bundle, kept as long as its member proposals
+func (rs *RedisStore) SaveBundle(ctx context.Context, bundle *types.ProposalBundle) error {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	ttl := time.Until(bundle.ExpiresAt) + time.Hour
+	if bundle.Status == types.ProposalStatusAccepted {
+		ttl = decisionRetention
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("bundle:%s", bundle.ID), data, ttl)
+	pipe.SAdd(ctx, bundlesIndexKey, string(bundle.ID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save bundle: %w", err)
+	}
+	return nil
+}
+
+// LoadBundle loads a proposal bundle
+func (rs *RedisStore) LoadBundle(ctx context.Context, bundleID types.BundleID) (*types.ProposalBundle, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("bundle:%s", bundleID)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("bundle not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load bundle: %w", err)
+	}
+
+	var bundle types.ProposalBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+// ListBundles loads all known bundles, dropping index entries of expired ones
+func (rs *RedisStore) ListBundles(ctx context.Context) ([]*types.ProposalBundle, error) {
+	ids, err := rs.client.SMembers(ctx, bundlesIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundles: %w", err)
+	}
+
+	bundles := make([]*types.ProposalBundle, 0, len(ids))
+	for _, id := range ids {
+		key := fmt.Sprintf("bundle:%s", id)
+		data, err := rs.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			rs.client.SRem(ctx, bundlesIndexKey, id)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load bundle: %w", err)
+		}
+
+		var bundle types.ProposalBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			rs.logger.Warn("Skipping unreadable bundle", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		bundles = append(bundles, &bundle)
+	}
+	return bundles, nil
+}
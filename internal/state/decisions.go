@@ -0,0 +1,66 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// decisionRetention is how long accepted proposals and their outcomes are
+// kept so their impact can still be evaluated
+const decisionRetention = 7 * 24 * time.Hour
+
+// decisionOutcomesKey is a sorted set of outcome JSON scored by Unix milliseconds
+func decisionOutcomesKey(proposalID types.ProposalID) string {
+	return fmt.Sprintf("decisions:outcomes:%s", proposalID)
+}
+
+// RecordDecisionOutcome links an outcome to an accepted proposal
+func (rs *RedisStore) RecordDecisionOutcome(ctx context.Context, outcome types.DecisionOutcome) error {
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision outcome: %w", err)
+	}
+
+	key := decisionOutcomesKey(outcome.ProposalID)
+	pipe := rs.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(outcome.ReportedAt.UnixMilli()), Member: data})
+	pipe.Expire(ctx, key, decisionRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record decision outcome: %w", err)
+	}
+	return nil
+}
+
+// ListDecisionOutcomes returns the outcomes linked to a proposal, oldest first
+func (rs *RedisStore) ListDecisionOutcomes(ctx context.Context, proposalID types.ProposalID) ([]types.DecisionOutcome, error) {
+	members, err := rs.client.ZRange(ctx, decisionOutcomesKey(proposalID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decision outcomes: %w", err)
+	}
+
+	outcomes := make([]types.DecisionOutcome, 0, len(members))
+	for _, member := range members {
+		var o types.DecisionOutcome
+		if err := json.Unmarshal([]byte(member), &o); err != nil {
+			continue
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, nil
+}
+
+// MarkDecisionEvaluated records that a decision's final verdict was announced.
+// It returns false if it already was.
+func (rs *RedisStore) MarkDecisionEvaluated(ctx context.Context, proposalID types.ProposalID) (bool, error) {
+	ok, err := rs.client.SetNX(ctx, fmt.Sprintf("decisions:evaluated:%s", proposalID), time.Now().Unix(), decisionRetention).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark decision evaluated: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,52 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// latestDigestKey holds the most recent digest
+	latestDigestKey = "digest:latest"
+
+	// digestRetention bounds how long individual digests are kept
+	digestRetention = 30 * 24 * time.Hour
+)
+
+// SaveDigest stores a digest and marks it as the latest
+func (rs *RedisStore) SaveDigest(ctx context.Context, digest *types.Digest) error {
+	data, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("digest:%s", digest.ID), data, digestRetention)
+	pipe.Set(ctx, latestDigestKey, data, digestRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save digest: %w", err)
+	}
+	return nil
+}
+
+// LoadDigest loads a digest by ID, or the latest one for "latest"
+func (rs *RedisStore) LoadDigest(ctx context.Context, id string) (*types.Digest, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("digest:%s", id)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("digest not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load digest: %w", err)
+	}
+
+	var digest types.Digest
+	if err := json.Unmarshal(data, &digest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal digest: %w", err)
+	}
+	return &digest, nil
+}
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// edgeHistoryKey holds the weights of the edges removed with agents that
+// left, which seed the edges when the agents return (EDGE_PRIOR_HISTORY)
+const edgeHistoryKey = "topology:edge_history"
+
+// SaveEdgeHistory replaces the stored edge history
+func (rs *RedisStore) SaveEdgeHistory(ctx context.Context, history map[types.EdgeID]types.EdgeMemory) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal edge history: %w", err)
+	}
+	if err := rs.client.Set(ctx, edgeHistoryKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save edge history: %w", err)
+	}
+	return nil
+}
+
+// LoadEdgeHistory returns the stored edge history, or none if never saved
+func (rs *RedisStore) LoadEdgeHistory(ctx context.Context) (map[types.EdgeID]types.EdgeMemory, error) {
+	data, err := rs.client.Get(ctx, edgeHistoryKey).Bytes()
+	if err == redis.Nil {
+		return map[types.EdgeID]types.EdgeMemory{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load edge history: %w", err)
+	}
+
+	var history map[types.EdgeID]types.EdgeMemory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal edge history: %w", err)
+	}
+	return history, nil
+}
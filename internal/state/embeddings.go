@@ -0,0 +1,116 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// embeddingTTL keeps vectors of content not seen again for a month
+const embeddingTTL = 30 * 24 * time.Hour
+
+// embeddingStatusKey holds the model stored insights are embedded with
+const embeddingStatusKey = "embeddings:status"
+
+// embeddingKey is the key of a vector, by model and content hash
+func embeddingKey(model, hash string) string {
+	return fmt.Sprintf("embedding:%s:%s", model, hash)
+}
+
+// GetEmbeddings returns the cached vectors of the given content hashes under a
+// model; hashes without one are left out
+func (rs *RedisStore) GetEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error) {
+	vectors := make(map[string][]float32, len(hashes))
+	if len(hashes) == 0 {
+		return vectors, nil
+	}
+
+	keys := make([]string, len(hashes))
+	for i, hash := range hashes {
+		keys[i] = embeddingKey(model, hash)
+	}
+	values, err := rs.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	for i, value := range values {
+		if data, ok := value.(string); ok {
+			if vector, ok := decodeVector(data); ok {
+				vectors[hashes[i]] = vector
+			}
+		}
+	}
+	return vectors, nil
+}
+
+// SaveEmbeddings caches vectors by content hash under a model
+func (rs *RedisStore) SaveEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error {
+	pipe := rs.client.Pipeline()
+	for hash, vector := range vectors {
+		pipe.Set(ctx, embeddingKey(model, hash), encodeVector(vector), embeddingTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save embeddings: %w", err)
+	}
+	return nil
+}
+
+// SaveEmbeddingStatus saves the embedding model and migration progress
+func (rs *RedisStore) SaveEmbeddingStatus(ctx context.Context, status *types.EmbeddingStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding status: %w", err)
+	}
+	if err := rs.client.Set(ctx, embeddingStatusKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save embedding status: %w", err)
+	}
+	return nil
+}
+
+// LoadEmbeddingStatus loads the embedding model and migration progress, nil
+// if insights were never embedded
+func (rs *RedisStore) LoadEmbeddingStatus(ctx context.Context) (*types.EmbeddingStatus, error) {
+	data, err := rs.client.Get(ctx, embeddingStatusKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load embedding status: %w", err)
+	}
+
+	var status types.EmbeddingStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embedding status: %w", err)
+	}
+	return &status, nil
+}
+
+// encodeVector packs a vector as base64 of little-endian float32s, under half
+// its JSON size; base64 keeps backups of the key (which are JSON) intact
+func encodeVector(vector []float32) string {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// decodeVector unpacks a vector packed by encodeVector
+func decodeVector(encoded string) ([]float32, bool) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data)%4 != 0 {
+		return nil, false
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector, true
+}
@@ -0,0 +1,129 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// goalsIndexKey is the set of defined goal IDs
+	goalsIndexKey = "goals:all"
+
+	// goalProgressRetention bounds how long progress reports are kept
+	goalProgressRetention = 7 * 24 * time.Hour
+)
+
+// goalProgressKey is a sorted set of progress JSON scored by Unix milliseconds
+func goalProgressKey(goalID types.GoalID) string {
+	return fmt.Sprintf("goals:progress:%s", goalID)
+}
+
+// SaveGoal creates or replaces a goal definition
+func (rs *RedisStore) SaveGoal(ctx context.Context, goal *types.Goal) error {
+	data, err := encodeVersioned(SchemaGoal, goal)
+	if err != nil {
+		return fmt.Errorf("failed to marshal goal: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("goal:%s", goal.ID), data, 0)
+	pipe.SAdd(ctx, goalsIndexKey, string(goal.ID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save goal: %w", err)
+	}
+	return nil
+}
+
+// LoadGoal loads a goal definition
+func (rs *RedisStore) LoadGoal(ctx context.Context, goalID types.GoalID) (*types.Goal, error) {
+	key := fmt.Sprintf("goal:%s", goalID)
+	data, err := rs.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("goal not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load goal: %w", err)
+	}
+
+	var goal types.Goal
+	if err := rs.decode(SchemaGoal, key, data, &goal); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal goal: %w", err)
+	}
+	return &goal, nil
+}
+
+// ListGoals returns all goal definitions
+func (rs *RedisStore) ListGoals(ctx context.Context) ([]*types.Goal, error) {
+	ids, err := rs.client.SMembers(ctx, goalsIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+
+	goals := make([]*types.Goal, 0, len(ids))
+	for _, id := range ids {
+		goal, err := rs.LoadGoal(ctx, types.GoalID(id))
+		if err != nil {
+			rs.logger.Warn("Skipping unreadable goal", zap.String("goal_id", id), zap.Error(err))
+			continue
+		}
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+// DeleteGoal removes a goal and its progress history
+func (rs *RedisStore) DeleteGoal(ctx context.Context, goalID types.GoalID) error {
+	pipe := rs.client.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf("goal:%s", goalID), goalProgressKey(goalID))
+	pipe.SRem(ctx, goalsIndexKey, string(goalID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+	return nil
+}
+
+// RecordGoalProgress appends a progress report, trimming reports past retention
+func (rs *RedisStore) RecordGoalProgress(ctx context.Context, progress types.GoalProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal goal progress: %w", err)
+	}
+
+	key := goalProgressKey(progress.GoalID)
+	cutoff := time.Now().Add(-goalProgressRetention).UnixMilli()
+
+	pipe := rs.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(progress.ReportedAt.UnixMilli()), Member: data})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("(%d", cutoff))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record goal progress: %w", err)
+	}
+	return nil
+}
+
+// ListGoalProgress returns progress reports for a goal since the given time, oldest first
+func (rs *RedisStore) ListGoalProgress(ctx context.Context, goalID types.GoalID, since time.Time) ([]types.GoalProgress, error) {
+	members, err := rs.client.ZRangeByScore(ctx, goalProgressKey(goalID), &redis.ZRangeBy{
+		Min: fmt.Sprintf("%d", since.UnixMilli()),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goal progress: %w", err)
+	}
+
+	progress := make([]types.GoalProgress, 0, len(members))
+	for _, member := range members {
+		var p types.GoalProgress
+		if err := json.Unmarshal([]byte(member), &p); err != nil {
+			continue
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
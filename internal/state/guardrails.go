@@ -0,0 +1,87 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// topologyFreezeKey holds a manual freeze or unfreeze requested through the API
+	topologyFreezeKey = "topology:freeze"
+
+	// guardrailStatusKey holds the topology manager's latest guardrail status
+	guardrailStatusKey = "topology:guardrails"
+
+	// guardrailStatusTTL lets the status expire when the topology manager stops reporting
+	guardrailStatusTTL = time.Minute
+)
+
+// SetTopologyFreeze records a freeze request for the topology manager to apply
+func (rs *RedisStore) SetTopologyFreeze(ctx context.Context, freeze *types.TopologyFreeze) error {
+	data, err := json.Marshal(freeze)
+	if err != nil {
+		return fmt.Errorf("failed to marshal freeze: %w", err)
+	}
+	if err := rs.client.Set(ctx, topologyFreezeKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save freeze: %w", err)
+	}
+	return nil
+}
+
+// LoadTopologyFreeze returns the pending freeze request, or nil if none
+func (rs *RedisStore) LoadTopologyFreeze(ctx context.Context) (*types.TopologyFreeze, error) {
+	data, err := rs.client.Get(ctx, topologyFreezeKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load freeze: %w", err)
+	}
+
+	var freeze types.TopologyFreeze
+	if err := json.Unmarshal(data, &freeze); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal freeze: %w", err)
+	}
+	return &freeze, nil
+}
+
+// ClearTopologyFreeze removes the freeze request
+func (rs *RedisStore) ClearTopologyFreeze(ctx context.Context) error {
+	if err := rs.client.Del(ctx, topologyFreezeKey).Err(); err != nil {
+		return fmt.Errorf("failed to clear freeze: %w", err)
+	}
+	return nil
+}
+
+// SaveGuardrailStatus publishes the topology manager's guardrail status
+func (rs *RedisStore) SaveGuardrailStatus(ctx context.Context, status types.GuardrailStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guardrail status: %w", err)
+	}
+	if err := rs.client.Set(ctx, guardrailStatusKey, data, guardrailStatusTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save guardrail status: %w", err)
+	}
+	return nil
+}
+
+// LoadGuardrailStatus returns the last reported guardrail status
+func (rs *RedisStore) LoadGuardrailStatus(ctx context.Context) (*types.GuardrailStatus, error) {
+	data, err := rs.client.Get(ctx, guardrailStatusKey).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("no guardrail status reported")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load guardrail status: %w", err)
+	}
+
+	var status types.GuardrailStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal guardrail status: %w", err)
+	}
+	return &status, nil
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Ping checks that Redis is reachable
+func (rs *RedisStore) Ping(ctx context.Context) error {
+	if err := rs.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+	return nil
+}
+
+// ClockOffset returns how far the local clock is ahead of the Redis server's,
+// which serves as the reference clock all services are compared against
+func (rs *RedisStore) ClockOffset(ctx context.Context) (time.Duration, error) {
+	sent := time.Now()
+	server, err := rs.client.Time(ctx).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Redis time: %w", err)
+	}
+	received := time.Now()
+	local := sent.Add(received.Sub(sent) / 2) // Assume the server answered halfway through the round trip
+	return local.Sub(server), nil
+}
+
+// SaveServiceHealth stores a service instance's health report. The report
+// expires after ttl, so an instance that stops reporting drops out.
+func (rs *RedisStore) SaveServiceHealth(ctx context.Context, health *types.ServiceHealth, ttl time.Duration) error {
+	data, err := json.Marshal(health)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service health: %w", err)
+	}
+	key := fmt.Sprintf("health:%s:%s", health.Service, health.Instance)
+	if err := rs.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save service health: %w", err)
+	}
+	return nil
+}
+
+// ListServiceHealth returns the unexpired health reports of all service instances
+func (rs *RedisStore) ListServiceHealth(ctx context.Context) ([]types.ServiceHealth, error) {
+	reports := []types.ServiceHealth{}
+
+	iter := rs.client.Scan(ctx, 0, "health:*", 500).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		data, err := rs.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			continue // Expired between SCAN and read
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load service health: %w", err)
+		}
+
+		var health types.ServiceHealth
+		if err := json.Unmarshal(data, &health); err != nil {
+			rs.logger.Warn("Skipping unreadable health report", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		reports = append(reports, health)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan health reports: %w", err)
+	}
+
+	return reports, nil
+}
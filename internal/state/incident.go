@@ -0,0 +1,50 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// incidentKey holds the active incident, declared through the API or on a health drop
+const incidentKey = "mesh:incident"
+
+// SetIncident declares incident mode, replacing any active incident
+func (rs *RedisStore) SetIncident(ctx context.Context, incident *types.Incident) error {
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident: %w", err)
+	}
+	if err := rs.client.Set(ctx, incidentKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save incident: %w", err)
+	}
+	return nil
+}
+
+// LoadIncident returns the active incident, or nil if none
+func (rs *RedisStore) LoadIncident(ctx context.Context) (*types.Incident, error) {
+	data, err := rs.client.Get(ctx, incidentKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load incident: %w", err)
+	}
+
+	var incident types.Incident
+	if err := json.Unmarshal(data, &incident); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal incident: %w", err)
+	}
+	return &incident, nil
+}
+
+// ClearIncident ends incident mode
+func (rs *RedisStore) ClearIncident(ctx context.Context) error {
+	if err := rs.client.Del(ctx, incidentKey).Err(); err != nil {
+		return fmt.Errorf("failed to clear incident: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// insightScanBatch is how many insight keys ListInsights scans and reads at once
+const insightScanBatch = 500
+
+// ListInsights returns the insights the knowledge manager persisted, reading
+// each batch of scanned keys with one MGET
+func (rs *RedisStore) ListInsights(ctx context.Context) ([]*types.Insight, error) {
+	insights := []*types.Insight{}
+
+	var cursor uint64
+	for {
+		keys, next, err := rs.client.Scan(ctx, cursor, "insight:*", insightScanBatch).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan insights: %w", err)
+		}
+		if len(keys) > 0 {
+			values, err := rs.client.MGet(ctx, keys...).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load insights: %w", err)
+			}
+			for i, value := range values {
+				data, ok := value.(string)
+				if !ok {
+					continue // Expired between SCAN and read
+				}
+
+				var insight types.Insight
+				if err := rs.decode(SchemaInsight, keys[i], []byte(data), &insight); err != nil {
+					rs.logger.Warn("Skipping unreadable insight", zap.String("key", keys[i]), zap.Error(err))
+					continue
+				}
+				insights = append(insights, &insight)
+			}
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	return insights, nil
+}
+
+// ExistingInsights returns which of the given insights are already persisted
+func (rs *RedisStore) ExistingInsights(ctx context.Context, ids []types.InsightID) (map[types.InsightID]bool, error) {
+	existing := make(map[types.InsightID]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	pipe := rs.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		counts[i] = pipe.Exists(ctx, fmt.Sprintf("insight:%s", id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check insights: %w", err)
+	}
+	for i, count := range counts {
+		if count.Val() > 0 {
+			existing[ids[i]] = true
+		}
+	}
+	return existing, nil
+}
+
+// insightSummariesKey holds the counts of insights tiering did not store
+// individually; "insights:" stays outside the "insight:*" keys of stored insights
+const insightSummariesKey = "insights:tiered"
+
+// insightSummariesTTL matches the retention of stored insights
+const insightSummariesTTL = 7 * 24 * time.Hour
+
+// SaveInsightSummaries saves the low-confidence insight summaries by topic
+func (rs *RedisStore) SaveInsightSummaries(ctx context.Context, summaries map[string]*types.InsightTierSummary) error {
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal insight summaries: %w", err)
+	}
+	if err := rs.client.Set(ctx, insightSummariesKey, data, insightSummariesTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save insight summaries: %w", err)
+	}
+	return nil
+}
+
+// LoadInsightSummaries loads the low-confidence insight summaries by topic,
+// nil if there are none
+func (rs *RedisStore) LoadInsightSummaries(ctx context.Context) (map[string]*types.InsightTierSummary, error) {
+	data, err := rs.client.Get(ctx, insightSummariesKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load insight summaries: %w", err)
+	}
+
+	var summaries map[string]*types.InsightTierSummary
+	if err := json.Unmarshal(data, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal insight summaries: %w", err)
+	}
+	return summaries, nil
+}
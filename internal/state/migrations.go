@@ -0,0 +1,119 @@
+package state
+
+import "github.com/avinashshinde/agentmesh-cortex/pkg/types"
+
+// Registered schema migrations.
+//
+// To evolve a persisted type, append a Migration with From equal to the
+// schema's current version. Old records are upgraded lazily when read and can
+// be rewritten in bulk with `agentmeshctl migrate`.
+
+func init() {
+	RegisterMigration(Migration{
+		Schema:      SchemaAgent,
+		From:        0,
+		Description: "Default missing metadata and capabilities",
+		Apply: func(doc map[string]any) error {
+			defaultField(doc, "metadata", map[string]any{})
+			defaultField(doc, "capabilities", []any{})
+			return nil
+		},
+	})
+
+	RegisterMigration(Migration{
+		Schema:      SchemaSnapshot,
+		From:        0,
+		Description: "Default missing agent and edge maps",
+		Apply: func(doc map[string]any) error {
+			defaultField(doc, "agents", map[string]any{})
+			defaultField(doc, "edges", map[string]any{})
+			return nil
+		},
+	})
+
+	RegisterMigration(Migration{
+		Schema:      SchemaAgent,
+		From:        1,
+		Description: "Promote well-known metadata to typed fields and structure capabilities",
+		Apply: func(doc map[string]any) error {
+			upgradeAgentProfile(doc)
+			return nil
+		},
+	})
+
+	RegisterMigration(Migration{
+		Schema:      SchemaSnapshot,
+		From:        1,
+		Description: "Upgrade agent profiles embedded in the snapshot",
+		Apply: func(doc map[string]any) error {
+			agents, _ := doc["agents"].(map[string]any)
+			for _, agent := range agents {
+				if agentDoc, ok := agent.(map[string]any); ok {
+					upgradeAgentProfile(agentDoc)
+				}
+			}
+			return nil
+		},
+	})
+
+	RegisterMigration(Migration{
+		Schema:      SchemaProposal,
+		From:        0,
+		Description: "Default missing votes map",
+		Apply: func(doc map[string]any) error {
+			defaultField(doc, "votes", map[string]any{})
+			return nil
+		},
+	})
+
+	RegisterMigration(Migration{
+		Schema:      SchemaInsight,
+		From:        0,
+		Description: "Insights stored before privacy controls default to public",
+		Apply: func(doc map[string]any) error {
+			if privacy, _ := doc["privacy"].(string); privacy == "" {
+				doc["privacy"] = "public"
+			}
+			defaultField(doc, "tags", []any{})
+			defaultField(doc, "metadata", map[string]any{})
+			defaultField(doc, "data", map[string]any{})
+			return nil
+		},
+	})
+}
+
+// defaultField sets doc[key] when it is missing or null
+func defaultField(doc map[string]any, key string, value any) {
+	if v, ok := doc[key]; !ok || v == nil {
+		doc[key] = value
+	}
+}
+
+// upgradeAgentProfile converts plain-string capabilities to objects and moves
+// framework, model, language and version out of metadata
+func upgradeAgentProfile(doc map[string]any) {
+	if capabilities, ok := doc["capabilities"].([]any); ok {
+		for i, c := range capabilities {
+			if name, ok := c.(string); ok {
+				capability := types.ParseCapability(name)
+				upgraded := map[string]any{"name": capability.Name}
+				if capability.Version != "" {
+					upgraded["version"] = capability.Version
+				}
+				capabilities[i] = upgraded
+			}
+		}
+	}
+
+	metadata, _ := doc["metadata"].(map[string]any)
+	for _, key := range []string{"framework", "model", "language", "version"} {
+		value, ok := metadata[key]
+		if !ok {
+			continue
+		}
+		if existing, _ := doc[key].(string); existing == "" {
+			doc[key] = value
+		}
+		delete(metadata, key)
+	}
+}
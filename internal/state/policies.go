@@ -0,0 +1,107 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// policiesIndexKey is the set of the names of the standing policies in force
+const policiesIndexKey = "policies:all"
+
+// SavePolicy puts a standing policy in force, replacing the one of the same name
+func (rs *RedisStore) SavePolicy(ctx context.Context, policy *types.StandingPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal policy: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("policy:%s", policy.Name), data, 0)
+	pipe.SAdd(ctx, policiesIndexKey, policy.Name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save policy: %w", err)
+	}
+	return nil
+}
+
+// LoadPolicy returns the standing policy in force under a name, or nil if none
+func (rs *RedisStore) LoadPolicy(ctx context.Context, name string) (*types.StandingPolicy, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("policy:%s", name)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+
+	var policy types.StandingPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// ListPolicies returns the standing policies in force
+func (rs *RedisStore) ListPolicies(ctx context.Context) ([]*types.StandingPolicy, error) {
+	names, err := rs.client.SMembers(ctx, policiesIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	policies := make([]*types.StandingPolicy, 0, len(names))
+	for _, name := range names {
+		policy, err := rs.LoadPolicy(ctx, name)
+		if err != nil {
+			rs.logger.Warn("Skipping unreadable policy", zap.String("policy", name), zap.Error(err))
+			continue
+		}
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// SaveRecurrence saves the progress of a recurring proposal
+func (rs *RedisStore) SaveRecurrence(ctx context.Context, recurrence *types.RecurrenceState) error {
+	data, err := json.Marshal(recurrence)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recurrence: %w", err)
+	}
+	if err := rs.client.Set(ctx, fmt.Sprintf("recurring:%s", recurrence.Name), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save recurrence: %w", err)
+	}
+	return nil
+}
+
+// LoadRecurrence returns the progress of a recurring proposal, or nil before its first round
+func (rs *RedisStore) LoadRecurrence(ctx context.Context, name string) (*types.RecurrenceState, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("recurring:%s", name)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load recurrence: %w", err)
+	}
+
+	var recurrence types.RecurrenceState
+	if err := json.Unmarshal(data, &recurrence); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recurrence: %w", err)
+	}
+	return &recurrence, nil
+}
+
+// ClaimRecurringRound claims a round of a recurring proposal, so that only one
+// consensus manager proposes it during a handoff; false if already claimed
+func (rs *RedisStore) ClaimRecurringRound(ctx context.Context, name string, round int, ttl time.Duration) (bool, error) {
+	ok, err := rs.client.SetNX(ctx, fmt.Sprintf("recurring:%s:round:%d", name, round), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim recurring round: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// topologyPriorsKey holds the edge weights the topology learned before the
+// topology manager last shut down, gzipped JSON
+const topologyPriorsKey = "topology:priors"
+
+// SaveTopologyPriors replaces the stored learned priors
+func (rs *RedisStore) SaveTopologyPriors(ctx context.Context, priors *types.TopologyPriors) error {
+	var data bytes.Buffer
+	gz := gzip.NewWriter(&data)
+	if err := json.NewEncoder(gz).Encode(priors); err != nil {
+		return fmt.Errorf("failed to marshal topology priors: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress topology priors: %w", err)
+	}
+	if err := rs.client.Set(ctx, topologyPriorsKey, data.Bytes(), 0).Err(); err != nil {
+		return fmt.Errorf("failed to save topology priors: %w", err)
+	}
+	return nil
+}
+
+// LoadTopologyPriors returns the stored learned priors, or nil if never saved
+func (rs *RedisStore) LoadTopologyPriors(ctx context.Context) (*types.TopologyPriors, error) {
+	data, err := rs.client.Get(ctx, topologyPriorsKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load topology priors: %w", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress topology priors: %w", err)
+	}
+	defer gz.Close()
+	var priors types.TopologyPriors
+	if err := json.NewDecoder(gz).Decode(&priors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal topology priors: %w", err)
+	}
+	return &priors, nil
+}
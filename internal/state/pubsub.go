@@ -0,0 +1,123 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Delays between attempts of a fanout to subscribe, doubling up to the maximum
+const (
+	fanoutRetry    = time.Second
+	fanoutMaxRetry = 30 * time.Second
+)
+
+// Publish sends a payload to every subscriber of a Redis Pub/Sub channel
+func (rs *RedisStore) Publish(ctx context.Context, channel string, payload []byte) error {
+	if err := rs.client.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Subscribe calls handler with every payload published on a Redis Pub/Sub
+// channel until ctx is cancelled. The subscription reconnects by itself;
+// payloads published while disconnected are lost.
+func (rs *RedisStore) Subscribe(ctx context.Context, channel string, handler func(payload []byte)) error {
+	return rs.subscribe(ctx, channel, func() {}, handler)
+}
+
+// subscribe is Subscribe, calling subscribed once the subscription is in place
+func (rs *RedisStore) subscribe(ctx context.Context, channel string, subscribed func(), handler func(payload []byte)) error {
+	sub := rs.client.Subscribe(ctx, channel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+	subscribed()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handler([]byte(msg.Payload))
+		}
+	}
+}
+
+// Fanout shares frames between the replicas of a server over a Redis Pub/Sub
+// channel: every frame one replica publishes is delivered on all of them
+type Fanout struct {
+	store      *RedisStore // Nil = deliver to this replica only
+	channel    string
+	deliver    func(frame any)
+	logger     *zap.Logger
+	subscribed atomic.Bool // Whether Relay receives the channel's frames
+}
+
+// NewFanout creates a fanout over a channel, handing frames to deliver
+func NewFanout(store *RedisStore, channel string, deliver func(frame any), logger *zap.Logger) *Fanout {
+	return &Fanout{store: store, channel: channel, deliver: deliver, logger: logger}
+}
+
+// Publish sends a frame to every replica through Redis. It also delivers the
+// frame to this replica directly without Redis, when publishing fails, or
+// while Relay is not subscribed to the channel.
+func (f *Fanout) Publish(ctx context.Context, frame any) {
+	if f.store != nil {
+		data, err := json.Marshal(frame)
+		if err == nil {
+			err = f.store.Publish(ctx, f.channel, data)
+		}
+		if err != nil {
+			f.logger.Warn("Failed to fan out frame, delivering locally only", zap.String("channel", f.channel), zap.Error(err))
+		} else if f.subscribed.Load() {
+			return
+		}
+	}
+	f.deliver(frame)
+}
+
+// Relay delivers the frames published by any replica, as raw JSON, until ctx
+// is cancelled; it returns at once without Redis. A failed subscription is
+// retried with backoff, Publish delivering locally in the meantime.
+func (f *Fanout) Relay(ctx context.Context) {
+	if f.store == nil {
+		return
+	}
+	retry := fanoutRetry
+	for {
+		err := f.store.subscribe(ctx, f.channel, func() {
+			f.subscribed.Store(true)
+			retry = fanoutRetry
+		}, func(payload []byte) {
+			f.deliver(json.RawMessage(payload))
+		})
+		f.subscribed.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		f.logger.Warn("Frame relay interrupted, retrying",
+			zap.String("channel", f.channel),
+			zap.Duration("retry_in", retry),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, fanoutMaxRetry)
+	}
+}
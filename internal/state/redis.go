@@ -3,7 +3,9 @@ package state
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,6 +14,29 @@ import (
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
+const (
+	// snapshotIndexKey is a sorted set of timestamped snapshot keys scored by Unix time
+	snapshotIndexKey = "graph:snapshots"
+
+	// snapshotDeltasKey is a list of the snapshot deltas published since the
+	// latest checkpoint, oldest first
+	snapshotDeltasKey = "graph:deltas"
+
+	// snapshotDeltaHistoryKey is a sorted set of snapshot delta JSON scored by
+	// Unix milliseconds, which history rebuilds the topology between checkpoints from
+	snapshotDeltaHistoryKey = "graph:deltas:history"
+
+	// messageHistoryKey is a sorted set of message JSON scored by Unix milliseconds
+	messageHistoryKey = "messages:history"
+
+	// historyRetention bounds how long snapshot and message history is kept
+	historyRetention = 24 * time.Hour
+)
+
+// ErrNoSnapshot is returned by LoadGraphSnapshot when no snapshot was saved yet,
+// and by SnapshotAt when no snapshot kept was taken by then
+var ErrNoSnapshot = errors.New("no snapshot found")
+
 // RedisStore handles Redis-based state management
 type RedisStore struct {
 	client *redis.Client
@@ -43,48 +68,366 @@ func NewRedisStore(config *types.Config, logger *zap.Logger) (*RedisStore, error
 	}, nil
 }
 
-// SaveGraphSnapshot saves a graph snapshot to Redis
+// SaveGraphSnapshot saves a graph snapshot to Redis as the checkpoint later
+// deltas apply to
 func (rs *RedisStore) SaveGraphSnapshot(ctx context.Context, snapshot *types.GraphSnapshot) error {
-	data, err := json.Marshal(snapshot)
+	data, err := encodeVersioned(SchemaSnapshot, snapshot)
 	if err != nil {
 		return fmt.Errorf("failed to marshal snapshot: %w", err)
 	}
 
-	key := "graph:snapshot:latest"
-	if err := rs.client.Set(ctx, key, data, 0).Err(); err != nil {
+	// A checkpoint supersedes the deltas published since the previous one
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, "graph:snapshot:latest", data, 0)
+	pipe.Del(ctx, snapshotDeltasKey)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to save snapshot: %w", err)
 	}
 
 	// Also save with timestamp for history
 	timestampKey := fmt.Sprintf("graph:snapshot:%d", snapshot.Timestamp.Unix())
-	if err := rs.client.Set(ctx, timestampKey, data, 24*time.Hour).Err(); err != nil {
+	if err := rs.client.Set(ctx, timestampKey, data, historyRetention).Err(); err != nil {
 		rs.logger.Warn("Failed to save timestamped snapshot", zap.Error(err))
+		return nil
+	}
+
+	// Index the timestamped snapshot so history can be read back by time range
+	pipe = rs.client.TxPipeline()
+	pipe.ZAdd(ctx, snapshotIndexKey, redis.Z{Score: float64(snapshot.Timestamp.Unix()), Member: timestampKey})
+	pipe.ZRemRangeByScore(ctx, snapshotIndexKey, "-inf", fmt.Sprintf("(%d", time.Now().Add(-historyRetention).Unix()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		rs.logger.Warn("Failed to index timestamped snapshot", zap.Error(err))
+	}
+
+	return nil
+}
+
+// SaveSnapshotDelta appends a delta to the ones applying to the latest
+// checkpoint, and records it in the history
+func (rs *RedisStore) SaveSnapshotDelta(ctx context.Context, delta *types.SnapshotDelta) error {
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot delta: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.RPush(ctx, snapshotDeltasKey, data)
+	pipe.ZAdd(ctx, snapshotDeltaHistoryKey, redis.Z{Score: float64(delta.Timestamp.UnixMilli()), Member: data})
+	pipe.ZRemRangeByScore(ctx, snapshotDeltaHistoryKey, "-inf", fmt.Sprintf("(%d", time.Now().Add(-historyRetention).UnixMilli()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save snapshot delta: %w", err)
+	}
+	return nil
+}
+
+// ListSnapshots returns the topology after each checkpoint and delta saved
+// within [from, to], oldest first. Deltas apply to the checkpoint before them,
+// taken before from if need be; those that do not, e.g. after a gap, are left
+// out until the next checkpoint.
+func (rs *RedisStore) ListSnapshots(ctx context.Context, from, to time.Time) ([]*types.GraphSnapshot, error) {
+	checkpoints, err := rs.listCheckpoints(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deltas, err := rs.listDeltas(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(deltas) == 0 {
+		return checkpoints, nil
+	}
+
+	var reconstructor types.SnapshotReconstructor
+	if base, err := rs.SnapshotAt(ctx, from); err == nil {
+		reconstructor.Checkpoint(base)
+	} else if !errors.Is(err, ErrNoSnapshot) {
+		return nil, err
+	}
+
+	snapshots := make([]*types.GraphSnapshot, 0, len(checkpoints)+len(deltas))
+	for len(checkpoints) > 0 || len(deltas) > 0 {
+		if len(deltas) == 0 || (len(checkpoints) > 0 && !deltas[0].Timestamp.Before(checkpoints[0].Timestamp)) {
+			reconstructor.Checkpoint(checkpoints[0])
+			snapshots = append(snapshots, checkpoints[0])
+			checkpoints = checkpoints[1:]
+			continue
+		}
+
+		delta := deltas[0]
+		deltas = deltas[1:]
+		if current := reconstructor.Snapshot(); current != nil && delta.Sequence <= current.Sequence {
+			continue // Already part of the topology as of from
+		}
+		if err := reconstructor.Apply(delta); err != nil {
+			rs.logger.Debug("Snapshot delta does not apply", zap.Error(err))
+			continue
+		}
+		// The reconstructor keeps changing its snapshot, so hand out a copy
+		var copied types.SnapshotReconstructor
+		copied.Checkpoint(reconstructor.Snapshot())
+		snapshots = append(snapshots, copied.Snapshot())
+	}
+
+	return snapshots, nil
+}
+
+// CountHistory returns how many snapshots ListSnapshots and messages
+// ListMessages would return for [from, to] at most, without loading them
+func (rs *RedisStore) CountHistory(ctx context.Context, from, to time.Time) (snapshots, messages int64, err error) {
+	pipe := rs.client.Pipeline()
+	checkpoints := pipe.ZCount(ctx, snapshotIndexKey, fmt.Sprintf("%d", from.Unix()), fmt.Sprintf("%d", to.Unix()))
+	deltas := pipe.ZCount(ctx, snapshotDeltaHistoryKey, fmt.Sprintf("%d", from.UnixMilli()), fmt.Sprintf("%d", to.UnixMilli()))
+	recorded := pipe.ZCount(ctx, messageHistoryKey, fmt.Sprintf("%d", from.UnixMilli()), fmt.Sprintf("%d", to.UnixMilli()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to count history: %w", err)
+	}
+	return checkpoints.Val() + deltas.Val(), recorded.Val(), nil
+}
+
+// listCheckpoints returns the timestamped checkpoints taken within [from, to], oldest first
+func (rs *RedisStore) listCheckpoints(ctx context.Context, from, to time.Time) ([]*types.GraphSnapshot, error) {
+	keys, err := rs.client.ZRangeByScore(ctx, snapshotIndexKey, &redis.ZRangeBy{
+		Min: fmt.Sprintf("%d", from.Unix()),
+		Max: fmt.Sprintf("%d", to.Unix()),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	if len(keys) == 0 {
+		return []*types.GraphSnapshot{}, nil
+	}
+
+	values, err := rs.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshots: %w", err)
+	}
+
+	snapshots := make([]*types.GraphSnapshot, 0, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Snapshot expired but index entry not yet trimmed
+			continue
+		}
+
+		var snapshot types.GraphSnapshot
+		if err := rs.decode(SchemaSnapshot, keys[i], []byte(data), &snapshot); err != nil {
+			rs.logger.Warn("Skipping unreadable snapshot", zap.String("key", keys[i]), zap.Error(err))
+			continue
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// listDeltas returns the deltas saved within [from, to], in the order they apply
+func (rs *RedisStore) listDeltas(ctx context.Context, from, to time.Time) ([]*types.SnapshotDelta, error) {
+	values, err := rs.client.ZRangeByScore(ctx, snapshotDeltaHistoryKey, &redis.ZRangeBy{
+		Min: fmt.Sprintf("%d", from.UnixMilli()),
+		Max: fmt.Sprintf("%d", to.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot deltas: %w", err)
+	}
+
+	deltas := make([]*types.SnapshotDelta, 0, len(values))
+	for _, value := range values {
+		var delta types.SnapshotDelta
+		if err := json.Unmarshal([]byte(value), &delta); err != nil {
+			rs.logger.Warn("Skipping unreadable snapshot delta", zap.Error(err))
+			continue
+		}
+		deltas = append(deltas, &delta)
+	}
+	// Deltas saved within the same millisecond sort by their JSON otherwise
+	sort.SliceStable(deltas, func(i, j int) bool {
+		if !deltas[i].Timestamp.Equal(deltas[j].Timestamp) {
+			return deltas[i].Timestamp.Before(deltas[j].Timestamp)
+		}
+		return deltas[i].Sequence < deltas[j].Sequence
+	})
+	return deltas, nil
+}
+
+// SnapshotAt returns the topology as of at: the last timestamped checkpoint
+// taken at or before it, with the deltas saved since then up to at applied
+func (rs *RedisStore) SnapshotAt(ctx context.Context, at time.Time) (*types.GraphSnapshot, error) {
+	keys, err := rs.client.ZRevRangeByScore(ctx, snapshotIndexKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", at.Unix()),
+		Count: 1,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find snapshot: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w at or before %s", ErrNoSnapshot, at.Format(time.RFC3339))
+	}
+
+	data, err := rs.client.Get(ctx, keys[0]).Bytes()
+	if err == redis.Nil {
+		// Snapshot expired but index entry not yet trimmed
+		return nil, fmt.Errorf("%w at or before %s", ErrNoSnapshot, at.Format(time.RFC3339))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	var snapshot types.GraphSnapshot
+	if err := rs.decode(SchemaSnapshot, keys[0], data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	deltas, err := rs.listDeltas(ctx, snapshot.Timestamp, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(deltas) == 0 {
+		return &snapshot, nil
+	}
+
+	var reconstructor types.SnapshotReconstructor
+	reconstructor.Checkpoint(&snapshot)
+	for _, delta := range deltas {
+		if err := reconstructor.Apply(delta); err != nil {
+			// Serve the topology as of the last delta that applied
+			rs.logger.Warn("Snapshot delta does not apply", zap.Error(err))
+			break
+		}
+	}
+	return reconstructor.Snapshot(), nil
+}
+
+// SaveMessage records a message in the time-ordered message history
+func (rs *RedisStore) SaveMessage(ctx context.Context, message *types.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.ZAdd(ctx, messageHistoryKey, redis.Z{Score: float64(message.Timestamp.UnixMilli()), Member: data})
+	pipe.ZRemRangeByScore(ctx, messageHistoryKey, "-inf", fmt.Sprintf("(%d", time.Now().Add(-historyRetention).UnixMilli()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
 	}
 
 	return nil
 }
 
-// LoadGraphSnapshot loads the latest graph snapshot from Redis
+// ListMessages returns recorded messages sent within [from, to], oldest first.
+// A limit <= 0 returns all matching messages.
+func (rs *RedisStore) ListMessages(ctx context.Context, from, to time.Time, limit int64) ([]*types.Message, error) {
+	rangeBy := &redis.ZRangeBy{
+		Min: fmt.Sprintf("%d", from.UnixMilli()),
+		Max: fmt.Sprintf("%d", to.UnixMilli()),
+	}
+	if limit > 0 {
+		rangeBy.Count = limit
+	}
+
+	members, err := rs.client.ZRangeByScore(ctx, messageHistoryKey, rangeBy).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	messages := make([]*types.Message, 0, len(members))
+	for _, member := range members {
+		var message types.Message
+		if err := json.Unmarshal([]byte(member), &message); err != nil {
+			rs.logger.Warn("Skipping unreadable message", zap.Error(err))
+			continue
+		}
+		messages = append(messages, &message)
+	}
+
+	return messages, nil
+}
+
+// FindMessage returns the recorded message with an ID, searching newest first;
+// nil if it is not in the history (or older than its retention)
+func (rs *RedisStore) FindMessage(ctx context.Context, id string) (*types.Message, error) {
+	const page = 500
+	for start := int64(0); ; start += page {
+		members, err := rs.client.ZRevRange(ctx, messageHistoryKey, start, start+page-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search messages: %w", err)
+		}
+		for _, member := range members {
+			var message types.Message
+			if err := json.Unmarshal([]byte(member), &message); err != nil {
+				continue
+			}
+			if message.ID == id {
+				return &message, nil
+			}
+		}
+		if len(members) < page {
+			return nil, nil
+		}
+	}
+}
+
+// LoadGraphSnapshot loads the latest graph snapshot from Redis: the latest
+// checkpoint with the deltas published since applied
 func (rs *RedisStore) LoadGraphSnapshot(ctx context.Context) (*types.GraphSnapshot, error) {
 	key := "graph:snapshot:latest"
-	data, err := rs.client.Get(ctx, key).Bytes()
+	pipe := rs.client.TxPipeline()
+	checkpoint := pipe.Get(ctx, key)
+	deltas := pipe.LRange(ctx, snapshotDeltasKey, 0, -1)
+	_, err := pipe.Exec(ctx)
 	if err == redis.Nil {
-		return nil, fmt.Errorf("no snapshot found")
+		return nil, ErrNoSnapshot
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to load snapshot: %w", err)
 	}
 
 	var snapshot types.GraphSnapshot
-	if err := json.Unmarshal(data, &snapshot); err != nil {
+	if err := rs.decode(SchemaSnapshot, key, []byte(checkpoint.Val()), &snapshot); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
 	}
+	if len(deltas.Val()) == 0 {
+		return &snapshot, nil
+	}
 
-	return &snapshot, nil
+	var reconstructor types.SnapshotReconstructor
+	reconstructor.Checkpoint(&snapshot)
+	for _, data := range deltas.Val() {
+		var delta types.SnapshotDelta
+		if err := json.Unmarshal([]byte(data), &delta); err != nil {
+			rs.logger.Warn("Unreadable snapshot delta", zap.Error(err))
+			break
+		}
+		if err := reconstructor.Apply(&delta); err != nil {
+			// Serve the topology as of the last delta that applied
+			rs.logger.Warn("Snapshot delta does not apply", zap.Error(err))
+			break
+		}
+	}
+	return reconstructor.Snapshot(), nil
+}
+
+// SnapshotAgents returns the agents of the latest topology snapshot sorted by
+// ID, only those of one sub-mesh unless namespace is empty
+func (rs *RedisStore) SnapshotAgents(ctx context.Context, namespace string) ([]*types.Agent, error) {
+	snapshot, err := rs.LoadGraphSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	agents := []*types.Agent{}
+	for _, agent := range snapshot.Agents {
+		if namespace == "" || agent.MeshNamespace() == namespace {
+			agents = append(agents, agent)
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	return agents, nil
 }
 
 // SaveAgent saves an agent to Redis
 func (rs *RedisStore) SaveAgent(ctx context.Context, agent *types.Agent) error {
-	data, err := json.Marshal(agent)
+	data, err := encodeVersioned(SchemaAgent, agent)
 	if err != nil {
 		return fmt.Errorf("failed to marshal agent: %w", err)
 	}
@@ -113,7 +456,7 @@ func (rs *RedisStore) LoadAgent(ctx context.Context, agentID types.AgentID) (*ty
 	}
 
 	var agent types.Agent
-	if err := json.Unmarshal(data, &agent); err != nil {
+	if err := rs.decode(SchemaAgent, key, data, &agent); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal agent: %w", err)
 	}
 
@@ -122,13 +465,19 @@ func (rs *RedisStore) LoadAgent(ctx context.Context, agentID types.AgentID) (*ty
 
 // SaveProposal saves a proposal to Redis
 func (rs *RedisStore) SaveProposal(ctx context.Context, proposal *types.Proposal) error {
-	data, err := json.Marshal(proposal)
+	data, err := encodeVersioned(SchemaProposal, proposal)
 	if err != nil {
 		return fmt.Errorf("failed to marshal proposal: %w", err)
 	}
 
 	key := fmt.Sprintf("proposal:%s", proposal.ID)
 	ttl := time.Until(proposal.ExpiresAt) + time.Hour // Keep for 1 hour after expiry
+	if proposal.Status == types.ProposalStatusAccepted {
+		ttl = decisionRetention // Keep decisions while their impact is measured
+		if proposal.Window != nil {
+			ttl = max(ttl, time.Until(proposal.Window.End)+time.Hour) // Keep scheduled ones until they ran
+		}
+	}
 	if err := rs.client.Set(ctx, key, data, ttl).Err(); err != nil {
 		return fmt.Errorf("failed to save proposal: %w", err)
 	}
@@ -152,13 +501,41 @@ func (rs *RedisStore) LoadProposal(ctx context.Context, proposalID types.Proposa
 	}
 
 	var proposal types.Proposal
-	if err := json.Unmarshal(data, &proposal); err != nil {
+	if err := rs.decode(SchemaProposal, key, data, &proposal); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal proposal: %w", err)
 	}
 
 	return &proposal, nil
 }
 
+// ListProposals loads all known proposals, dropping index entries of expired ones
+func (rs *RedisStore) ListProposals(ctx context.Context) ([]*types.Proposal, error) {
+	ids, err := rs.client.SMembers(ctx, "proposals:all").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proposals: %w", err)
+	}
+
+	proposals := make([]*types.Proposal, 0, len(ids))
+	for _, id := range ids {
+		key := fmt.Sprintf("proposal:%s", id)
+		data, err := rs.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			rs.client.SRem(ctx, "proposals:all", id)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load proposal: %w", err)
+		}
+
+		var proposal types.Proposal
+		if err := rs.decode(SchemaProposal, key, data, &proposal); err != nil {
+			rs.logger.Warn("Skipping unreadable proposal", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		proposals = append(proposals, &proposal)
+	}
+	return proposals, nil
+}
+
 // IncrementCounter increments a counter in Redis
 func (rs *RedisStore) IncrementCounter(ctx context.Context, key string) (int64, error) {
 	return rs.client.Incr(ctx, key).Result()
@@ -211,9 +588,10 @@ func (rs *RedisStore) DeleteAgent(ctx context.Context, agentID types.AgentID) er
 	return nil
 }
 
-// Set stores a generic value in Redis with TTL
+// Set stores a generic value in Redis with TTL.
+// Values under a versioned key prefix (agent:, insight:, ...) are stamped with their schema version.
 func (rs *RedisStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	data, err := json.Marshal(value)
+	data, err := EncodeRecord(key, value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
@@ -232,7 +610,12 @@ func (rs *RedisStore) Get(ctx context.Context, key string, dest interface{}) err
 		return fmt.Errorf("failed to get key: %w", err)
 	}
 
-	if err := json.Unmarshal(data, dest); err != nil {
+	if schema, ok := schemaForKey(key); ok {
+		err = rs.decode(schema, key, data, dest)
+	} else {
+		err = json.Unmarshal(data, dest)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to unmarshal value: %w", err)
 	}
 
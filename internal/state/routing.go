@@ -0,0 +1,73 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// routeStatsKey holds the topology manager's latest route statistics
+const routeStatsKey = "routing:stats"
+
+// agentLoadKey holds the tasks in flight to each agent, as last seen by the topology manager
+const agentLoadKey = "routing:load"
+
+// SaveRouteStats replaces the stored route statistics
+func (rs *RedisStore) SaveRouteStats(ctx context.Context, stats []types.RouteStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal route stats: %w", err)
+	}
+	if err := rs.client.Set(ctx, routeStatsKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save route stats: %w", err)
+	}
+	return nil
+}
+
+// LoadRouteStats returns the stored route statistics, or none if never saved
+func (rs *RedisStore) LoadRouteStats(ctx context.Context) ([]types.RouteStats, error) {
+	data, err := rs.client.Get(ctx, routeStatsKey).Bytes()
+	if err == redis.Nil {
+		return []types.RouteStats{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load route stats: %w", err)
+	}
+
+	var stats []types.RouteStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal route stats: %w", err)
+	}
+	return stats, nil
+}
+
+// SaveAgentLoad replaces the stored tasks in flight per agent
+func (rs *RedisStore) SaveAgentLoad(ctx context.Context, load map[types.AgentID]int) error {
+	data, err := json.Marshal(load)
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent load: %w", err)
+	}
+	if err := rs.client.Set(ctx, agentLoadKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save agent load: %w", err)
+	}
+	return nil
+}
+
+// LoadAgentLoad returns the stored tasks in flight per agent, or none if never saved
+func (rs *RedisStore) LoadAgentLoad(ctx context.Context) (map[types.AgentID]int, error) {
+	data, err := rs.client.Get(ctx, agentLoadKey).Bytes()
+	if err == redis.Nil {
+		return map[types.AgentID]int{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load agent load: %w", err)
+	}
+
+	var load map[types.AgentID]int
+	if err := json.Unmarshal(data, &load); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal agent load: %w", err)
+	}
+	return load, nil
+}
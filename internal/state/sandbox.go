@@ -0,0 +1,41 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// sandboxApprovedKey is the set of agents an operator released from the sandbox
+const sandboxApprovedKey = "sandbox:approved"
+
+// ApproveAgent records an operator's approval releasing an agent from the sandbox
+func (rs *RedisStore) ApproveAgent(ctx context.Context, agentID types.AgentID) error {
+	if err := rs.client.SAdd(ctx, sandboxApprovedKey, string(agentID)).Err(); err != nil {
+		return fmt.Errorf("failed to approve agent: %w", err)
+	}
+	return nil
+}
+
+// AgentApproved reports whether an operator released an agent from the sandbox
+func (rs *RedisStore) AgentApproved(ctx context.Context, agentID types.AgentID) (bool, error) {
+	approved, err := rs.client.SIsMember(ctx, sandboxApprovedKey, string(agentID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check agent approval: %w", err)
+	}
+	return approved, nil
+}
+
+// ListApprovedAgents returns the agents an operator released from the sandbox
+func (rs *RedisStore) ListApprovedAgents(ctx context.Context) ([]types.AgentID, error) {
+	ids, err := rs.client.SMembers(ctx, sandboxApprovedKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approved agents: %w", err)
+	}
+	agents := make([]types.AgentID, 0, len(ids))
+	for _, id := range ids {
+		agents = append(agents, types.AgentID(id))
+	}
+	return agents, nil
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// savedQueriesIndexKey is the set of saved query IDs
+const savedQueriesIndexKey = "saved_queries:all"
+
+// SaveSavedQuery creates or replaces a saved query
+func (rs *RedisStore) SaveSavedQuery(ctx context.Context, query *types.SavedQuery) error {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saved query: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("saved_query:%s", query.ID), data, 0)
+	pipe.SAdd(ctx, savedQueriesIndexKey, query.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save saved query: %w", err)
+	}
+	return nil
+}
+
+// ListSavedQueries returns all saved queries
+func (rs *RedisStore) ListSavedQueries(ctx context.Context) ([]*types.SavedQuery, error) {
+	ids, err := rs.client.SMembers(ctx, savedQueriesIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved queries: %w", err)
+	}
+
+	queries := make([]*types.SavedQuery, 0, len(ids))
+	for _, id := range ids {
+		data, err := rs.client.Get(ctx, fmt.Sprintf("saved_query:%s", id)).Bytes()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load saved query: %w", err)
+		}
+
+		var query types.SavedQuery
+		if err := json.Unmarshal(data, &query); err != nil {
+			rs.logger.Warn("Skipping unreadable saved query", zap.String("query_id", id), zap.Error(err))
+			continue
+		}
+		queries = append(queries, &query)
+	}
+	return queries, nil
+}
+
+// DeleteSavedQuery removes a saved query
+func (rs *RedisStore) DeleteSavedQuery(ctx context.Context, id string) error {
+	pipe := rs.client.TxPipeline()
+	del := pipe.Del(ctx, fmt.Sprintf("saved_query:%s", id))
+	pipe.SRem(ctx, savedQueriesIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+	if del.Val() == 0 {
+		return fmt.Errorf("saved query not found")
+	}
+	return nil
+}
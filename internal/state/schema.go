@@ -0,0 +1,254 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Schema identifies a kind of record persisted in Redis
+type Schema string
+
+const (
+	SchemaAgent    Schema = "agent"
+	SchemaSnapshot Schema = "graph_snapshot"
+	SchemaProposal Schema = "proposal"
+	SchemaInsight  Schema = "insight"
+	SchemaGoal     Schema = "goal"
+)
+
+// schemaVersionField is injected into every versioned JSON record.
+// Records written before versioning existed have no field and are treated as version 0.
+const schemaVersionField = "schema_version"
+
+// ErrNewerSchema is returned for records written with a newer schema version
+// than this binary supports, which it cannot read without losing fields
+var ErrNewerSchema = errors.New("record has a newer schema version than supported")
+
+// Migration upgrades a decoded record of a schema from version From to From+1
+type Migration struct {
+	Schema      Schema
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// migrations holds registered migrations per schema, ordered by From
+var migrations = map[Schema][]Migration{}
+
+// RegisterMigration adds a migration step. Steps must be registered in order
+// with no gaps, so CurrentVersion(schema) always equals the number of steps.
+func RegisterMigration(m Migration) {
+	steps := migrations[m.Schema]
+	if m.From != len(steps) {
+		panic(fmt.Sprintf("migration for %s must start at version %d, got %d", m.Schema, len(steps), m.From))
+	}
+	migrations[m.Schema] = append(steps, m)
+}
+
+// CurrentVersion returns the version new records of a schema are written with
+func CurrentVersion(schema Schema) int {
+	return len(migrations[schema])
+}
+
+// Schemas returns all schemas with registered migrations, sorted
+func Schemas() []Schema {
+	schemas := make([]Schema, 0, len(migrations))
+	for schema := range migrations {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i] < schemas[j] })
+	return schemas
+}
+
+// schemaKeyPatterns maps each schema to the Redis key pattern its records live under
+var schemaKeyPatterns = map[Schema]string{
+	SchemaAgent:    "agent:*",
+	SchemaSnapshot: "graph:snapshot:*",
+	SchemaProposal: "proposal:*",
+	SchemaInsight:  "insight:*",
+	SchemaGoal:     "goal:*",
+}
+
+// schemaForKey returns the schema of the record stored under key, if any
+func schemaForKey(key string) (Schema, bool) {
+	for schema, pattern := range schemaKeyPatterns {
+		if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+			return schema, true
+		}
+	}
+	return "", false
+}
+
+// EncodeRecord marshals a value to be stored under key, stamped with the
+// schema version if the key holds versioned records
+func EncodeRecord(key string, value interface{}) ([]byte, error) {
+	if schema, ok := schemaForKey(key); ok {
+		return encodeVersioned(schema, value)
+	}
+	return json.Marshal(value)
+}
+
+// DecodeRecord unmarshals a record read from key into dest, migrating it first
+// if the key holds versioned records. Records with a newer schema version are
+// rejected with ErrNewerSchema.
+func DecodeRecord(key string, data []byte, dest interface{}) error {
+	if schema, ok := schemaForKey(key); ok {
+		_, err := decodeVersioned(schema, data, dest)
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// encodeVersioned marshals value as JSON stamped with the schema's current version
+func encodeVersioned(schema Schema, value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '{' {
+		return nil, fmt.Errorf("%s records must encode as JSON objects", schema)
+	}
+
+	stamp := fmt.Sprintf(`{"%s":%d`, schemaVersionField, CurrentVersion(schema))
+	if bytes.Equal(data, []byte("{}")) {
+		return []byte(stamp + "}"), nil
+	}
+	return append([]byte(stamp+","), data[1:]...), nil
+}
+
+// decodeVersioned unmarshals a record into dest, applying any pending migrations.
+// It returns the version the record was stored with.
+func decodeVersioned(schema Schema, data []byte, dest interface{}) (int, error) {
+	doc, version, err := migrateDocument(schema, data)
+	if err != nil {
+		return version, err
+	}
+
+	if doc == nil {
+		// Already current: decode directly
+		return version, json.Unmarshal(data, dest)
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return version, fmt.Errorf("failed to re-encode migrated %s: %w", schema, err)
+	}
+	return version, json.Unmarshal(migrated, dest)
+}
+
+// migrateDocument upgrades raw record JSON to the current version.
+// It returns a nil document when the record needed no migration, and
+// ErrNewerSchema when it has a newer version.
+func migrateDocument(schema Schema, data []byte) (map[string]any, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	version := 0
+	if v, ok := doc[schemaVersionField].(float64); ok {
+		version = int(v)
+	}
+
+	current := CurrentVersion(schema)
+	if version > current {
+		return nil, version, fmt.Errorf("%w: %s v%d, this binary supports v%d", ErrNewerSchema, schema, version, current)
+	}
+	if version == current {
+		return nil, version, nil
+	}
+
+	for _, m := range migrations[schema][version:] {
+		if err := m.Apply(doc); err != nil {
+			return nil, version, fmt.Errorf("migration %s v%d->v%d failed: %w", schema, m.From, m.From+1, err)
+		}
+	}
+	doc[schemaVersionField] = current
+
+	return doc, version, nil
+}
+
+// decode wraps decodeVersioned for store reads, warning about records written by newer code
+func (rs *RedisStore) decode(schema Schema, key string, data []byte, dest interface{}) error {
+	version, err := decodeVersioned(schema, data, dest)
+	if errors.Is(err, ErrNewerSchema) {
+		rs.logger.Warn("Record has a newer schema version than this binary supports",
+			zap.String("key", key),
+			zap.Int("version", version),
+			zap.Int("supported", CurrentVersion(schema)),
+		)
+	}
+	return err
+}
+
+// MigrationReport summarizes a MigrateAll pass over one schema
+type MigrationReport struct {
+	Schema   Schema `json:"schema"`
+	Version  int    `json:"version"`
+	Scanned  int    `json:"scanned"`
+	Migrated int    `json:"migrated"`
+	Failed   int    `json:"failed"`
+}
+
+// MigrateAll rewrites every stale versioned record at its schema's current version.
+// TTLs are preserved. With dryRun set, records are counted but not written.
+// Records with a newer version are left alone and counted as failed.
+func (rs *RedisStore) MigrateAll(ctx context.Context, dryRun bool) ([]MigrationReport, error) {
+	reports := []MigrationReport{}
+
+	for _, schema := range Schemas() {
+		report := MigrationReport{Schema: schema, Version: CurrentVersion(schema)}
+
+		iter := rs.client.Scan(ctx, 0, schemaKeyPatterns[schema], 500).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			data, err := rs.client.Get(ctx, key).Bytes()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				// Not a string key (or unreadable), nothing to migrate
+				continue
+			}
+			report.Scanned++
+
+			doc, _, err := migrateDocument(schema, data)
+			if err != nil {
+				rs.logger.Warn("Failed to migrate record", zap.String("key", key), zap.Error(err))
+				report.Failed++
+				continue
+			}
+			if doc == nil {
+				continue
+			}
+
+			if !dryRun {
+				migrated, err := json.Marshal(doc)
+				if err != nil {
+					return reports, fmt.Errorf("failed to encode migrated %s: %w", key, err)
+				}
+				if err := rs.client.Set(ctx, key, migrated, redis.KeepTTL).Err(); err != nil {
+					return reports, fmt.Errorf("failed to write migrated %s: %w", key, err)
+				}
+			}
+			report.Migrated++
+		}
+		if err := iter.Err(); err != nil {
+			return reports, fmt.Errorf("failed to scan %s records: %w", schema, err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// topologyComparisonKey holds the latest comparison of the live and shadow topologies
+	topologyComparisonKey = "topology:shadow"
+
+	// topologyComparisonTTL lets the comparison expire once the shadow topology is turned off
+	topologyComparisonTTL = time.Minute
+)
+
+// SaveTopologyComparison publishes the topology manager's live and shadow topology stats
+func (rs *RedisStore) SaveTopologyComparison(ctx context.Context, comparison *types.TopologyComparison) error {
+	data, err := json.Marshal(comparison)
+	if err != nil {
+		return fmt.Errorf("failed to marshal topology comparison: %w", err)
+	}
+	if err := rs.client.Set(ctx, topologyComparisonKey, data, topologyComparisonTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save topology comparison: %w", err)
+	}
+	return nil
+}
+
+// LoadTopologyComparison returns the last topology comparison, or nil if no
+// shadow topology is running
+func (rs *RedisStore) LoadTopologyComparison(ctx context.Context) (*types.TopologyComparison, error) {
+	data, err := rs.client.Get(ctx, topologyComparisonKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load topology comparison: %w", err)
+	}
+
+	var comparison types.TopologyComparison
+	if err := json.Unmarshal(data, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal topology comparison: %w", err)
+	}
+	return &comparison, nil
+}
@@ -0,0 +1,133 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// sharingIndexKey is the set of sharing agreement IDs
+	sharingIndexKey = "sharing:agreements"
+
+	// sharingAuditKey is the audit trail of agreement changes, scored by time
+	sharingAuditKey = "sharing:audit"
+)
+
+// SaveSharingAgreement stores an agreement and records the change in the audit trail
+func (rs *RedisStore) SaveSharingAgreement(ctx context.Context, agreement *types.SharingAgreement, actor string) error {
+	data, err := json.Marshal(agreement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sharing agreement: %w", err)
+	}
+	entry, err := sharingAuditEntry(types.SharingAuditCreated, agreement, actor)
+	if err != nil {
+		return err
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("sharing:agreement:%s", agreement.ID), data, 0)
+	pipe.SAdd(ctx, sharingIndexKey, agreement.ID)
+	pipe.ZAdd(ctx, sharingAuditKey, entry)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save sharing agreement: %w", err)
+	}
+	return nil
+}
+
+// ListSharingAgreements returns all sharing agreements
+func (rs *RedisStore) ListSharingAgreements(ctx context.Context) (types.SharingPolicy, error) {
+	ids, err := rs.client.SMembers(ctx, sharingIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sharing agreements: %w", err)
+	}
+
+	agreements := make(types.SharingPolicy, 0, len(ids))
+	for _, id := range ids {
+		data, err := rs.client.Get(ctx, fmt.Sprintf("sharing:agreement:%s", id)).Bytes()
+		if err == redis.Nil {
+			rs.client.SRem(ctx, sharingIndexKey, id)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load sharing agreement: %w", err)
+		}
+
+		var agreement types.SharingAgreement
+		if err := json.Unmarshal(data, &agreement); err != nil {
+			rs.logger.Warn("Skipping unreadable sharing agreement", zap.String("agreement_id", id), zap.Error(err))
+			continue
+		}
+		agreements = append(agreements, &agreement)
+	}
+	return agreements, nil
+}
+
+// RevokeSharingAgreement removes an agreement and records the change in the audit trail
+func (rs *RedisStore) RevokeSharingAgreement(ctx context.Context, id, actor string) error {
+	key := fmt.Sprintf("sharing:agreement:%s", id)
+	data, err := rs.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return fmt.Errorf("sharing agreement not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to load sharing agreement: %w", err)
+	}
+	var agreement types.SharingAgreement
+	if err := json.Unmarshal(data, &agreement); err != nil {
+		return fmt.Errorf("failed to unmarshal sharing agreement: %w", err)
+	}
+	entry, err := sharingAuditEntry(types.SharingAuditRevoked, &agreement, actor)
+	if err != nil {
+		return err
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.SRem(ctx, sharingIndexKey, id)
+	pipe.ZAdd(ctx, sharingAuditKey, entry)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to revoke sharing agreement: %w", err)
+	}
+	return nil
+}
+
+// ListSharingAudit returns the audit trail of agreement changes since a time, oldest first
+func (rs *RedisStore) ListSharingAudit(ctx context.Context, since time.Time) ([]types.SharingAuditEntry, error) {
+	members, err := rs.client.ZRangeByScore(ctx, sharingAuditKey, &redis.ZRangeBy{
+		Min: fmt.Sprintf("%d", since.UnixMilli()),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sharing audit: %w", err)
+	}
+
+	entries := make([]types.SharingAuditEntry, 0, len(members))
+	for _, member := range members {
+		var entry types.SharingAuditEntry
+		if err := json.Unmarshal([]byte(member), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// sharingAuditEntry builds the audit trail member for an agreement change
+func sharingAuditEntry(action types.SharingAuditAction, agreement *types.SharingAgreement, actor string) (redis.Z, error) {
+	now := time.Now()
+	data, err := json.Marshal(types.SharingAuditEntry{
+		Action:    action,
+		Agreement: *agreement,
+		Actor:     actor,
+		Timestamp: now,
+	})
+	if err != nil {
+		return redis.Z{}, fmt.Errorf("failed to marshal sharing audit entry: %w", err)
+	}
+	return redis.Z{Score: float64(now.UnixMilli()), Member: data}, nil
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// proposalTemplatesIndexKey is the set of registered proposal template names
+const proposalTemplatesIndexKey = "proposal_templates:all"
+
+// SaveProposalTemplate registers or replaces a proposal template
+func (rs *RedisStore) SaveProposalTemplate(ctx context.Context, template *types.ProposalTemplate) error {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("failed to marshal proposal template: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("proposal_template:%s", template.Name), data, 0)
+	pipe.SAdd(ctx, proposalTemplatesIndexKey, template.Name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save proposal template: %w", err)
+	}
+	return nil
+}
+
+// LoadProposalTemplate loads a registered proposal template
+func (rs *RedisStore) LoadProposalTemplate(ctx context.Context, name string) (*types.ProposalTemplate, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("proposal_template:%s", name)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("proposal template not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load proposal template: %w", err)
+	}
+
+	var template types.ProposalTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal template: %w", err)
+	}
+	return &template, nil
+}
+
+// ListProposalTemplates returns all registered proposal templates
+func (rs *RedisStore) ListProposalTemplates(ctx context.Context) ([]*types.ProposalTemplate, error) {
+	names, err := rs.client.SMembers(ctx, proposalTemplatesIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proposal templates: %w", err)
+	}
+
+	templates := make([]*types.ProposalTemplate, 0, len(names))
+	for _, name := range names {
+		template, err := rs.LoadProposalTemplate(ctx, name)
+		if err != nil {
+			rs.logger.Warn("Skipping unreadable proposal template", zap.String("template", name), zap.Error(err))
+			continue
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// DeleteProposalTemplate removes a registered proposal template
+func (rs *RedisStore) DeleteProposalTemplate(ctx context.Context, name string) error {
+	pipe := rs.client.TxPipeline()
+	del := pipe.Del(ctx, fmt.Sprintf("proposal_template:%s", name))
+	pipe.SRem(ctx, proposalTemplatesIndexKey, name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete proposal template: %w", err)
+	}
+	if del.Val() == 0 {
+		return fmt.Errorf("proposal template not found")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// topologyHealthKey holds the topology manager's current topology health issues
+	topologyHealthKey = "topology:health"
+
+	// topologyHealthTTL lets the issues expire when the topology manager stops reporting
+	topologyHealthTTL = time.Minute
+)
+
+// SaveTopologyHealth publishes the topology's current health issues
+func (rs *RedisStore) SaveTopologyHealth(ctx context.Context, health types.TopologyHealth) error {
+	data, err := json.Marshal(health)
+	if err != nil {
+		return fmt.Errorf("failed to marshal topology health: %w", err)
+	}
+	if err := rs.client.Set(ctx, topologyHealthKey, data, topologyHealthTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save topology health: %w", err)
+	}
+	return nil
+}
+
+// LoadTopologyHealth returns the last reported topology health
+func (rs *RedisStore) LoadTopologyHealth(ctx context.Context) (*types.TopologyHealth, error) {
+	data, err := rs.client.Get(ctx, topologyHealthKey).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("no topology health reported")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load topology health: %w", err)
+	}
+
+	var health types.TopologyHealth
+	if err := json.Unmarshal(data, &health); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal topology health: %w", err)
+	}
+	return &health, nil
+}
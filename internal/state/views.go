@@ -0,0 +1,100 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// viewsIndexKey is the set of defined insight view names
+const viewsIndexKey = "views:all"
+
+// SaveInsightView creates or replaces a materialized view definition
+func (rs *RedisStore) SaveInsightView(ctx context.Context, view *types.InsightView) error {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return fmt.Errorf("failed to marshal view: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("view:%s", view.Name), data, 0)
+	pipe.SAdd(ctx, viewsIndexKey, view.Name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	return nil
+}
+
+// ListInsightViews returns all materialized view definitions
+func (rs *RedisStore) ListInsightViews(ctx context.Context) ([]*types.InsightView, error) {
+	names, err := rs.client.SMembers(ctx, viewsIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	views := make([]*types.InsightView, 0, len(names))
+	for _, name := range names {
+		data, err := rs.client.Get(ctx, fmt.Sprintf("view:%s", name)).Bytes()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load view: %w", err)
+		}
+
+		var view types.InsightView
+		if err := json.Unmarshal(data, &view); err != nil {
+			rs.logger.Warn("Skipping unreadable view", zap.String("view", name), zap.Error(err))
+			continue
+		}
+		views = append(views, &view)
+	}
+	return views, nil
+}
+
+// DeleteInsightView removes a view definition and its result
+func (rs *RedisStore) DeleteInsightView(ctx context.Context, name string) error {
+	pipe := rs.client.TxPipeline()
+	del := pipe.Del(ctx, fmt.Sprintf("view:%s", name))
+	pipe.Del(ctx, fmt.Sprintf("view_result:%s", name))
+	pipe.SRem(ctx, viewsIndexKey, name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	if del.Val() == 0 {
+		return fmt.Errorf("view not found")
+	}
+	return nil
+}
+
+// SaveViewResult stores the materialized content of a view
+func (rs *RedisStore) SaveViewResult(ctx context.Context, result *types.ViewResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal view result: %w", err)
+	}
+	if err := rs.client.Set(ctx, fmt.Sprintf("view_result:%s", result.View), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save view result: %w", err)
+	}
+	return nil
+}
+
+// LoadViewResult loads the materialized content of a view, nil if not computed yet
+func (rs *RedisStore) LoadViewResult(ctx context.Context, name string) (*types.ViewResult, error) {
+	data, err := rs.client.Get(ctx, fmt.Sprintf("view_result:%s", name)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load view result: %w", err)
+	}
+
+	var result types.ViewResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal view result: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// webhooksIndexKey is the set of registered webhook IDs
+const webhooksIndexKey = "webhooks:all"
+
+// SaveWebhook creates or replaces a webhook subscription
+func (rs *RedisStore) SaveWebhook(ctx context.Context, hook *types.Webhook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("webhook:%s", hook.ID), data, 0)
+	pipe.SAdd(ctx, webhooksIndexKey, hook.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhooks returns all webhook subscriptions
+func (rs *RedisStore) ListWebhooks(ctx context.Context) ([]*types.Webhook, error) {
+	ids, err := rs.client.SMembers(ctx, webhooksIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	hooks := make([]*types.Webhook, 0, len(ids))
+	for _, id := range ids {
+		data, err := rs.client.Get(ctx, fmt.Sprintf("webhook:%s", id)).Bytes()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load webhook: %w", err)
+		}
+
+		var hook types.Webhook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			rs.logger.Warn("Skipping unreadable webhook", zap.String("webhook_id", id), zap.Error(err))
+			continue
+		}
+		hooks = append(hooks, &hook)
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes a webhook subscription
+func (rs *RedisStore) DeleteWebhook(ctx context.Context, id string) error {
+	pipe := rs.client.TxPipeline()
+	del := pipe.Del(ctx, fmt.Sprintf("webhook:%s", id))
+	pipe.SRem(ctx, webhooksIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if del.Val() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
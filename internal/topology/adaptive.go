@@ -0,0 +1,112 @@
+package topology
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// adaptiveDecaySmoothing is the weight of the latest cycle in the
+	// throughput average, so one burst or lull doesn't swing the decay rate
+	adaptiveDecaySmoothing = 0.3
+
+	// Bounds of the factor the decay rate is scaled by: a silent mesh still
+	// decays a little and a flood cannot wipe every edge in one cycle
+	adaptiveDecayMinFactor = 0.1
+	adaptiveDecayMaxFactor = 10
+
+	// adaptiveDecayMaxRate caps the scaled decay rate per cycle
+	adaptiveDecayMaxRate = 0.5
+)
+
+// decayController scales the decay rate by recent message throughput against
+// DECAY_REFERENCE_THROUGHPUT, so edges fade per message rather than per tick:
+// a quiet mesh keeps its paths and a busy one prunes as fast as it reinforces,
+// and the topology evolves the same at any traffic volume
+type decayController struct {
+	reference float64 // Messages per second DECAY_RATE is meant for
+
+	mu         sync.Mutex
+	messages   int64
+	lastUpdate time.Time
+	throughput float64 // Smoothed messages per second
+	factor     float64
+	measured   bool
+}
+
+// newDecayController returns the controller for a config, or nil without a
+// reference throughput
+func newDecayController(cfg *types.Config) *decayController {
+	if cfg.DecayReferenceThroughput <= 0 {
+		return nil
+	}
+	return &decayController{
+		reference:  cfg.DecayReferenceThroughput,
+		lastUpdate: time.Now(),
+		factor:     1,
+	}
+}
+
+// observe counts a message through the topology
+func (c *decayController) observe() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.messages++
+	c.mu.Unlock()
+}
+
+// reset discards the messages counted so far and measures throughput from now on
+func (c *decayController) reset(now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages, c.lastUpdate = 0, now
+}
+
+// update folds the messages since the last update into the throughput and
+// returns the status to decay by
+func (c *decayController) update(now time.Time) *types.AdaptiveDecayStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elapsed := now.Sub(c.lastUpdate).Seconds(); elapsed > 0 {
+		current := float64(c.messages) / elapsed
+		if c.measured {
+			c.throughput += adaptiveDecaySmoothing * (current - c.throughput)
+		} else {
+			c.throughput, c.measured = current, true
+		}
+		c.messages, c.lastUpdate = 0, now
+		c.factor = math.Min(math.Max(c.throughput/c.reference, adaptiveDecayMinFactor), adaptiveDecayMaxFactor)
+	}
+	return &types.AdaptiveDecayStatus{
+		ReferenceThroughput: c.reference,
+		Throughput:          c.throughput,
+		Factor:              c.factor,
+	}
+}
+
+// SetAdaptiveDecay scales the decay rate of later cycles by status.Factor;
+// nil decays at the tuned or configured rate again
+func (g *Graph) SetAdaptiveDecay(status *types.AdaptiveDecayStatus) {
+	g.adaptive.Store(status)
+}
+
+// AdaptiveDecay reports the throughput the decay rate is scaled by and the
+// resulting rate, nil without adaptive decay
+func (g *Graph) AdaptiveDecay() *types.AdaptiveDecayStatus {
+	status := g.adaptive.Load()
+	if status == nil {
+		return nil
+	}
+	out := *status
+	out.DecayRate = g.decayRate()
+	return &out
+}
@@ -0,0 +1,87 @@
+package topology
+
+import (
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// pruneOutcome is how the edges considered by one prune fared
+type pruneOutcome struct {
+	threshold  float64
+	candidates int // Picked by the pruning strategy
+	protected  int // Kept to leave agents connected
+	deferred   int // Postponed by the per-cycle cap
+}
+
+// algorithmRecorder collects what the slime mold algorithm does between decay
+// cycles and keeps the last ALGORITHM_HISTORY cycles
+type algorithmRecorder struct {
+	mu             sync.Mutex
+	size           int
+	cycle          uint64
+	reinforcements map[types.EdgeID]int64
+	cycles         []types.AlgorithmCycle // Oldest first
+}
+
+func newAlgorithmRecorder(size int) *algorithmRecorder {
+	return &algorithmRecorder{size: size, reinforcements: make(map[types.EdgeID]int64)}
+}
+
+// reinforced counts a reinforcement event on an edge
+func (r *algorithmRecorder) reinforced(edgeID types.EdgeID) {
+	r.mu.Lock()
+	r.reinforcements[edgeID]++
+	r.mu.Unlock()
+}
+
+// record completes a cycle with the reinforcements counted since the
+// previous one and keeps it
+func (r *algorithmRecorder) record(cycle types.AlgorithmCycle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cycle++
+	cycle.Cycle = r.cycle
+	for _, count := range r.reinforcements {
+		cycle.ReinforcementTotal += count
+	}
+	if len(r.reinforcements) > 0 {
+		cycle.Reinforcements = r.reinforcements
+		r.reinforcements = make(map[types.EdgeID]int64)
+	}
+
+	if r.size <= 0 {
+		return
+	}
+	r.cycles = append(r.cycles, cycle)
+	if len(r.cycles) > r.size {
+		r.cycles = append([]types.AlgorithmCycle(nil), r.cycles[len(r.cycles)-r.size:]...)
+	}
+}
+
+// since returns the kept cycles numbered after the given one, oldest first
+func (r *algorithmRecorder) since(cycle uint64) []types.AlgorithmCycle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var cycles []types.AlgorithmCycle
+	for _, c := range r.cycles {
+		if c.Cycle > cycle {
+			cycles = append(cycles, c)
+		}
+	}
+	return cycles
+}
+
+// weightEntropy returns the normalized entropy of the current edge weights
+func (g *Graph) weightEntropy() float64 {
+	g.rLockAll()
+	defer g.rUnlockAll()
+
+	weights := []float64{}
+	for _, edge := range g.allEdges() {
+		weights = append(weights, edge.GetWeight())
+	}
+	return types.WeightEntropy(weights)
+}
@@ -0,0 +1,182 @@
+package topology
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// BetweennessMaxAgents bounds the graphs betweenness is computed for: it takes
+// a shortest-path search from every agent, too slow for snapshots of larger meshes
+const BetweennessMaxAgents = 500
+
+// eigenvectorIterations bounds the power iteration of eigenvector centrality
+const eigenvectorIterations = 100
+
+// centrality scores every agent (must be called with all shards locked).
+//
+// Degree is the share of other agents an agent has an edge to or from.
+// Betweenness is the share of strongest paths between other agents that relay
+// through it, with the same edge costs as FindPath. Eigenvector centrality
+// weights connections by the centrality of the neighbour, treating edges as
+// undirected, and is scaled so the most central agent scores 1.
+func (g *Graph) centrality() map[types.AgentID]types.AgentCentrality {
+	count, _ := g.counts()
+	ids := make([]types.AgentID, 0, count)
+	for id := range g.allAgents() {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	index := make(map[types.AgentID]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	n := len(ids)
+	out := make([][]weightedEdge, n)
+	neighbours := make([]map[int]bool, n)
+	undirected := make([]map[int]float64, n)
+	for i := range ids {
+		neighbours[i] = make(map[int]bool)
+		undirected[i] = make(map[int]float64)
+	}
+	for _, edge := range g.allEdges() {
+		source, okSource := index[edge.SourceID]
+		target, okTarget := index[edge.TargetID]
+		weight := edge.GetWeight()
+		if !okSource || !okTarget || source == target || weight <= 0 {
+			continue
+		}
+		out[source] = append(out[source], weightedEdge{to: target, cost: pathCost(weight)})
+		if g.config.EdgeMode == types.EdgeModeUndirected {
+			out[target] = append(out[target], weightedEdge{to: source, cost: pathCost(weight)})
+		}
+		neighbours[source][target] = true
+		neighbours[target][source] = true
+		undirected[source][target] += weight
+		undirected[target][source] += weight
+	}
+
+	var betweenness []float64
+	if n <= BetweennessMaxAgents {
+		betweenness = brandes(ids, index, out)
+	}
+	eigenvector := powerIteration(undirected)
+
+	scores := make(map[types.AgentID]types.AgentCentrality, n)
+	for i, id := range ids {
+		score := types.AgentCentrality{Eigenvector: eigenvector[i]}
+		if n > 1 {
+			score.Degree = float64(len(neighbours[i])) / float64(n-1)
+		}
+		if betweenness != nil && n > 2 {
+			score.Betweenness = betweenness[i] / float64((n-1)*(n-2))
+		}
+		scores[id] = score
+	}
+	return scores
+}
+
+// weightedEdge is an outgoing edge with its path cost
+type weightedEdge struct {
+	to   int
+	cost float64
+}
+
+// brandes computes unnormalized betweenness with Brandes' algorithm over
+// weighted shortest paths
+func brandes(ids []types.AgentID, index map[types.AgentID]int, out [][]weightedEdge) []float64 {
+	const epsilon = 1e-12 // Below hopCost, so paths with more hops never tie
+	n := len(out)
+	betweenness := make([]float64, n)
+
+	for source := 0; source < n; source++ {
+		var order []int // Agents by increasing distance from source
+		predecessors := make([][]int, n)
+		paths := make([]float64, n)
+		distance := make([]float64, n)
+		for i := range distance {
+			distance[i] = math.Inf(1)
+		}
+		paths[source], distance[source] = 1, 0
+
+		done := make([]bool, n)
+		queue := &pathQueue{{agent: ids[source]}}
+		for queue.Len() > 0 {
+			current := index[heap.Pop(queue).(pathItem).agent]
+			if done[current] {
+				continue
+			}
+			done[current] = true
+			order = append(order, current)
+
+			for _, edge := range out[current] {
+				if done[edge.to] {
+					continue
+				}
+				cost := distance[current] + edge.cost
+				switch {
+				case cost < distance[edge.to]-epsilon:
+					distance[edge.to] = cost
+					paths[edge.to] = paths[current]
+					predecessors[edge.to] = []int{current}
+					heap.Push(queue, pathItem{agent: ids[edge.to], cost: cost})
+				case math.Abs(cost-distance[edge.to]) <= epsilon:
+					paths[edge.to] += paths[current]
+					predecessors[edge.to] = append(predecessors[edge.to], current)
+				}
+			}
+		}
+
+		dependency := make([]float64, n)
+		for i := len(order) - 1; i >= 0; i-- {
+			node := order[i]
+			for _, predecessor := range predecessors[node] {
+				dependency[predecessor] += paths[predecessor] / paths[node] * (1 + dependency[node])
+			}
+			if node != source {
+				betweenness[node] += dependency[node]
+			}
+		}
+	}
+	return betweenness
+}
+
+// powerIteration computes eigenvector centrality of a symmetric weighted
+// graph, scaled so the highest score is 1. Iterating with the identity added
+// converges on bipartite graphs too, and leaves the eigenvector unchanged.
+func powerIteration(adjacent []map[int]float64) []float64 {
+	n := len(adjacent)
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+
+	for iteration := 0; iteration < eigenvectorIterations; iteration++ {
+		next := make([]float64, n)
+		highest := 0.0
+		for i := range adjacent {
+			next[i] = scores[i]
+			for j, weight := range adjacent[i] {
+				next[i] += weight * scores[j]
+			}
+			highest = math.Max(highest, next[i])
+		}
+		if highest == 0 {
+			return next
+		}
+
+		change := 0.0
+		for i := range next {
+			next[i] /= highest
+			change += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if change < 1e-9*float64(n) {
+			break
+		}
+	}
+	return scores
+}
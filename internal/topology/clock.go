@@ -0,0 +1,46 @@
+package topology
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the time topology changes happen at
+type Clock func() time.Time
+
+// clockSource reads a replaceable clock, the wall clock by default
+type clockSource struct {
+	clock atomic.Pointer[Clock]
+}
+
+// set replaces the clock; nil restores the wall clock
+func (c *clockSource) set(clock Clock) {
+	if clock == nil {
+		c.clock.Store(nil)
+		return
+	}
+	c.clock.Store(&clock)
+}
+
+// now returns the current time of the clock
+func (c *clockSource) now() time.Time {
+	if clock := c.clock.Load(); clock != nil {
+		return (*clock)()
+	}
+	return time.Now()
+}
+
+// SetClock makes the graph and its guardrails tell time by clock instead of
+// the wall clock, e.g. by the replayed records while rebuilding from Kafka, so
+// the same records always yield the same graph. Nil restores the wall clock.
+func (g *Graph) SetClock(clock Clock) {
+	g.clock.set(clock)
+	g.guardrails.clock.set(clock)
+}
+
+// SetClock makes the topology tell time by clock, see Graph.SetClock. Adaptive
+// decay measures throughput from the clock's current time on.
+func (sm *SlimeMoldTopology) SetClock(clock Clock) {
+	sm.graph.SetClock(clock)
+	sm.decay.reset(sm.graph.clock.now())
+}
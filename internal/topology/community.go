@@ -0,0 +1,80 @@
+package topology
+
+import (
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// communityRounds bounds label propagation, which usually settles in a few rounds
+const communityRounds = 20
+
+// communities groups agents into clusters by weighted label propagation (must
+// be called with all shards locked). Every agent starts in its own cluster and
+// repeatedly joins the cluster its edges, in either direction, are strongest
+// to. Agents are visited in ID order and ties go to the lowest cluster, so the
+// same graph always yields the same clusters, numbered from 0 in ID order.
+func (g *Graph) communities() map[types.AgentID]int {
+	count, _ := g.counts()
+	ids := make([]types.AgentID, 0, count)
+	for id := range g.allAgents() {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	weights := make(map[types.AgentID]map[types.AgentID]float64, len(ids))
+	for _, edge := range g.allEdges() {
+		weight := edge.GetWeight()
+		_, source := g.agent(edge.SourceID)
+		_, target := g.agent(edge.TargetID)
+		if !source || !target || edge.SourceID == edge.TargetID || weight <= 0 {
+			continue
+		}
+		for _, pair := range [][2]types.AgentID{{edge.SourceID, edge.TargetID}, {edge.TargetID, edge.SourceID}} {
+			if weights[pair[0]] == nil {
+				weights[pair[0]] = make(map[types.AgentID]float64)
+			}
+			weights[pair[0]][pair[1]] += weight
+		}
+	}
+
+	labels := make(map[types.AgentID]int, len(ids))
+	for i, id := range ids {
+		labels[id] = i
+	}
+	for round := 0; round < communityRounds; round++ {
+		changed := false
+		for _, id := range ids {
+			strength := make(map[int]float64)
+			for neighbour, weight := range weights[id] {
+				strength[labels[neighbour]] += weight
+			}
+			best, bestStrength := labels[id], strength[labels[id]]
+			for label, s := range strength {
+				if s > bestStrength || (s == bestStrength && label < best) {
+					best, bestStrength = label, s
+				}
+			}
+			if best != labels[id] {
+				labels[id] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// Number the clusters from 0 in the order their first agent appears
+	numbers := make(map[int]int)
+	communities := make(map[types.AgentID]int, len(ids))
+	for _, id := range ids {
+		number, ok := numbers[labels[id]]
+		if !ok {
+			number = len(numbers)
+			numbers[labels[id]] = number
+		}
+		communities[id] = number
+	}
+	return communities
+}
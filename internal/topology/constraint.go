@@ -0,0 +1,56 @@
+package topology
+
+import (
+	"errors"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ErrEdgeForbidden is returned when an edge is denied by EDGE_CONSTRAINTS or
+// would join two namespaces
+var ErrEdgeForbidden = errors.New("edge is forbidden by edge constraints or namespaces")
+
+// edgeLimit returns whether an edge between two agents is allowed, in the
+// same namespace and by EDGE_CONSTRAINTS, and the most weight it may have,
+// with EDGE_MODE=undirected in both directions (must be called with their
+// shards locked)
+func (g *Graph) edgeLimit(source, target *types.Agent) (bool, float64) {
+	if source == nil || target == nil {
+		return true, 1.0
+	}
+	if !source.SameNamespace(target) {
+		return false, 0
+	}
+	if len(g.config.EdgeConstraints) == 0 {
+		return true, 1.0
+	}
+	allowed, maxWeight := g.config.EdgeConstraints.Limit(source, target)
+	if allowed && g.config.EdgeMode == types.EdgeModeUndirected {
+		back, backMax := g.config.EdgeConstraints.Limit(target, source)
+		return back, min(maxWeight, backMax)
+	}
+	return allowed, maxWeight
+}
+
+// newEdge creates an edge between two agents at its initial weight, capped by
+// EDGE_CONSTRAINTS, or nil if they forbid it or the agents are in different
+// namespaces (must be called with their shards locked)
+func (g *Graph) newEdge(source, target *types.Agent) *types.Edge {
+	allowed, maxWeight := g.edgeLimit(source, target)
+	if !allowed {
+		return nil
+	}
+	now := g.clock.now()
+	edge := &types.Edge{
+		ID:        types.NewEdgeID(source.ID, target.ID),
+		SourceID:  source.ID,
+		TargetID:  target.ID,
+		Weight:    min(g.initialWeight(source, target), maxWeight),
+		Usage:     0,
+		CreatedAt: now,
+		LastUsed:  now,
+	}
+	edge.TagRegion(source, target)
+	edge.SetScorer(g.scorer)
+	return edge
+}
@@ -0,0 +1,92 @@
+package topology
+
+import (
+	"math"
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Diff compares two topology snapshots, a before b. Weight changes smaller
+// than minChange are left out; added and pruned edges are always listed.
+func Diff(a, b *types.GraphSnapshot, minChange float64) *types.TopologyDiff {
+	diff := &types.TopologyDiff{
+		From:          a.Timestamp,
+		To:            b.Timestamp,
+		AddedAgents:   []*types.Agent{},
+		RemovedAgents: []*types.Agent{},
+		AddedEdges:    []*types.Edge{},
+		PrunedEdges:   []*types.Edge{},
+		WeightChanges: []types.EdgeWeightChange{},
+		StatsBefore:   a.HistoryPoint().Stats,
+		StatsAfter:    b.HistoryPoint().Stats,
+	}
+	diff.StatsDelta = types.NewStatsDelta(diff.StatsBefore, diff.StatsAfter)
+
+	for id, agent := range b.Agents {
+		if _, ok := a.Agents[id]; !ok {
+			diff.AddedAgents = append(diff.AddedAgents, agent)
+		}
+	}
+	for id, agent := range a.Agents {
+		if _, ok := b.Agents[id]; !ok {
+			diff.RemovedAgents = append(diff.RemovedAgents, agent)
+		}
+	}
+
+	// Edges are keyed by the map, which snapshots always fill
+	added, pruned := []types.EdgeID{}, []types.EdgeID{}
+	for id, edge := range b.Edges {
+		before, ok := a.Edges[id]
+		if !ok {
+			added = append(added, id)
+			continue
+		}
+		delta := edge.Weight - before.Weight
+		if delta == 0 || math.Abs(delta) < minChange {
+			continue
+		}
+		if delta > 0 {
+			diff.Strengthened++
+		} else {
+			diff.Weakened++
+		}
+		diff.WeightChanges = append(diff.WeightChanges, types.EdgeWeightChange{
+			EdgeID:   id,
+			SourceID: edge.SourceID,
+			TargetID: edge.TargetID,
+			Before:   before.Weight,
+			After:    edge.Weight,
+			Delta:    delta,
+		})
+	}
+	for id := range a.Edges {
+		if _, ok := b.Edges[id]; !ok {
+			pruned = append(pruned, id)
+		}
+	}
+
+	sortIDs(added)
+	sortIDs(pruned)
+	for _, id := range added {
+		diff.AddedEdges = append(diff.AddedEdges, b.Edges[id])
+	}
+	for _, id := range pruned {
+		diff.PrunedEdges = append(diff.PrunedEdges, a.Edges[id])
+	}
+	sort.Slice(diff.AddedAgents, func(i, j int) bool { return diff.AddedAgents[i].ID < diff.AddedAgents[j].ID })
+	sort.Slice(diff.RemovedAgents, func(i, j int) bool { return diff.RemovedAgents[i].ID < diff.RemovedAgents[j].ID })
+	sort.Slice(diff.WeightChanges, func(i, j int) bool {
+		x, y := math.Abs(diff.WeightChanges[i].Delta), math.Abs(diff.WeightChanges[j].Delta)
+		if x != y {
+			return x > y
+		}
+		return diff.WeightChanges[i].EdgeID < diff.WeightChanges[j].EdgeID
+	})
+	return diff
+}
+
+// sortIDs sorts edge IDs for a stable order
+func sortIDs(ids []types.EdgeID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
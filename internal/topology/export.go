@@ -0,0 +1,420 @@
+package topology
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ExportFormat identifies a graph interchange format
+type ExportFormat string
+
+const (
+	ExportFormatGraphML ExportFormat = "graphml" // yEd, Gephi, networkx
+	ExportFormatDOT     ExportFormat = "dot"     // Graphviz
+	ExportFormatGEXF    ExportFormat = "gexf"    // Gephi native
+)
+
+// ContentType returns the MIME type for the export format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatDOT:
+		return "text/vnd.graphviz"
+	default:
+		return "application/xml"
+	}
+}
+
+// Export writes a snapshot in the requested interchange format
+func Export(w io.Writer, snapshot *types.GraphSnapshot, format ExportFormat) error {
+	switch format {
+	case ExportFormatGraphML:
+		return ExportGraphML(w, snapshot)
+	case ExportFormatDOT:
+		return ExportDOT(w, snapshot)
+	case ExportFormatGEXF:
+		return ExportGEXF(w, snapshot)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportDOT writes a snapshot as a Graphviz digraph, or graph if its edges are undirected.
+// Edge pen width scales with weight so strong paths stand out when rendered.
+// In every format agents carry the community they were detected in, if any.
+func ExportDOT(w io.Writer, snapshot *types.GraphSnapshot) error {
+	var b strings.Builder
+
+	graphType, edgeOp := "digraph", "->"
+	if snapshot.Stats.Undirected {
+		graphType, edgeOp = "graph", "--"
+	}
+	fmt.Fprintf(&b, "%s agentmesh {\n", graphType)
+	fmt.Fprintf(&b, "  graph [timestamp=%s];\n", dotQuote(snapshot.Timestamp.Format(time.RFC3339)))
+	b.WriteString("  node [shape=ellipse];\n")
+
+	for _, id := range sortedAgentIDs(snapshot) {
+		agent := snapshot.Agents[id]
+		attrs := []string{
+			"label=" + dotQuote(agent.Name),
+			"role=" + dotQuote(agent.Role),
+			"status=" + dotQuote(string(agent.Status)),
+			"capabilities=" + dotQuote(strings.Join(agent.CapabilityNames(), ",")),
+			"framework=" + dotQuote(agent.Framework),
+			"model=" + dotQuote(agent.Model),
+			"language=" + dotQuote(agent.Language),
+			"version=" + dotQuote(agent.Version),
+		}
+		if community, ok := snapshot.Communities[id]; ok {
+			attrs = append(attrs, "community="+strconv.Itoa(community))
+		}
+		for _, key := range sortedKeys(agent.Metadata) {
+			attrs = append(attrs, dotQuote("meta_"+key)+"="+dotQuote(agent.Metadata[key]))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(string(id)), strings.Join(attrs, ", "))
+	}
+
+	for _, id := range sortedEdgeIDs(snapshot) {
+		edge := snapshot.Edges[id]
+		fmt.Fprintf(&b, "  %s %s %s [weight=%s, usage=%d, last_used=%s, penwidth=%s];\n",
+			dotQuote(string(edge.SourceID)),
+			edgeOp,
+			dotQuote(string(edge.TargetID)),
+			formatFloat(edge.Weight),
+			edge.Usage,
+			dotQuote(edge.LastUsed.Format(time.RFC3339)),
+			formatFloat(0.5+edge.Weight*4),
+		)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graphML mirrors the subset of the GraphML schema used for export
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ExportGraphML writes a snapshot as GraphML with agent and edge attributes
+func ExportGraphML(w io.Writer, snapshot *types.GraphSnapshot) error {
+	metaKeys := metadataKeys(snapshot)
+
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "role", For: "node", AttrName: "role", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "string"},
+			{ID: "capabilities", For: "node", AttrName: "capabilities", AttrType: "string"},
+			{ID: "framework", For: "node", AttrName: "framework", AttrType: "string"},
+			{ID: "model", For: "node", AttrName: "model", AttrType: "string"},
+			{ID: "language", For: "node", AttrName: "language", AttrType: "string"},
+			{ID: "version", For: "node", AttrName: "version", AttrType: "string"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "double"},
+			{ID: "usage", For: "edge", AttrName: "usage", AttrType: "long"},
+			{ID: "last_used", For: "edge", AttrName: "last_used", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          "agentmesh",
+			EdgeDefault: edgeType(snapshot),
+		},
+	}
+	if len(snapshot.Communities) > 0 {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "community", For: "node", AttrName: "community", AttrType: "int"})
+	}
+	for _, key := range metaKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "meta_" + key, For: "node", AttrName: "meta_" + key, AttrType: "string"})
+	}
+
+	for _, id := range sortedAgentIDs(snapshot) {
+		agent := snapshot.Agents[id]
+		node := graphMLNode{
+			ID: string(id),
+			Data: []graphMLData{
+				{Key: "name", Value: agent.Name},
+				{Key: "role", Value: agent.Role},
+				{Key: "status", Value: string(agent.Status)},
+				{Key: "capabilities", Value: strings.Join(agent.CapabilityNames(), ",")},
+				{Key: "framework", Value: agent.Framework},
+				{Key: "model", Value: agent.Model},
+				{Key: "language", Value: agent.Language},
+				{Key: "version", Value: agent.Version},
+			},
+		}
+		if community, ok := snapshot.Communities[id]; ok {
+			node.Data = append(node.Data, graphMLData{Key: "community", Value: strconv.Itoa(community)})
+		}
+		for _, key := range sortedKeys(agent.Metadata) {
+			node.Data = append(node.Data, graphMLData{Key: "meta_" + key, Value: agent.Metadata[key]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, id := range sortedEdgeIDs(snapshot) {
+		edge := snapshot.Edges[id]
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     string(id),
+			Source: string(edge.SourceID),
+			Target: string(edge.TargetID),
+			Data: []graphMLData{
+				{Key: "weight", Value: formatFloat(edge.Weight)},
+				{Key: "usage", Value: strconv.FormatInt(edge.Usage, 10)},
+				{Key: "last_used", Value: edge.LastUsed.Format(time.RFC3339)},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// gexf mirrors the subset of the GEXF 1.3 schema used for export
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	LastModified string `xml:"lastmodifieddate,attr"`
+	Creator      string `xml:"creator"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class     string          `xml:"class,attr"`
+	Attribute []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    string         `xml:"weight,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// ExportGEXF writes a snapshot as GEXF 1.3 for Gephi.
+// Edge weight uses the native GEXF weight attribute; usage is an edge attribute.
+func ExportGEXF(w io.Writer, snapshot *types.GraphSnapshot) error {
+	metaKeys := metadataKeys(snapshot)
+
+	nodeAttrs := gexfAttributes{
+		Class: "node",
+		Attribute: []gexfAttribute{
+			{ID: "role", Title: "role", Type: "string"},
+			{ID: "status", Title: "status", Type: "string"},
+			{ID: "capabilities", Title: "capabilities", Type: "string"},
+			{ID: "framework", Title: "framework", Type: "string"},
+			{ID: "model", Title: "model", Type: "string"},
+			{ID: "language", Title: "language", Type: "string"},
+			{ID: "version", Title: "version", Type: "string"},
+		},
+	}
+	if len(snapshot.Communities) > 0 {
+		nodeAttrs.Attribute = append(nodeAttrs.Attribute, gexfAttribute{ID: "community", Title: "community", Type: "integer"})
+	}
+	for _, key := range metaKeys {
+		nodeAttrs.Attribute = append(nodeAttrs.Attribute, gexfAttribute{ID: "meta_" + key, Title: "meta_" + key, Type: "string"})
+	}
+
+	doc := gexf{
+		Xmlns:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Meta: gexfMeta{
+			LastModified: snapshot.Timestamp.Format("2006-01-02"),
+			Creator:      "AgentMesh Cortex",
+		},
+		Graph: gexfGraph{
+			DefaultEdgeType: edgeType(snapshot),
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				nodeAttrs,
+				{
+					Class: "edge",
+					Attribute: []gexfAttribute{
+						{ID: "usage", Title: "usage", Type: "long"},
+						{ID: "last_used", Title: "last_used", Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, id := range sortedAgentIDs(snapshot) {
+		agent := snapshot.Agents[id]
+		node := gexfNode{
+			ID:    string(id),
+			Label: agent.Name,
+			AttValues: []gexfAttValue{
+				{For: "role", Value: agent.Role},
+				{For: "status", Value: string(agent.Status)},
+				{For: "capabilities", Value: strings.Join(agent.CapabilityNames(), ",")},
+				{For: "framework", Value: agent.Framework},
+				{For: "model", Value: agent.Model},
+				{For: "language", Value: agent.Language},
+				{For: "version", Value: agent.Version},
+			},
+		}
+		if community, ok := snapshot.Communities[id]; ok {
+			node.AttValues = append(node.AttValues, gexfAttValue{For: "community", Value: strconv.Itoa(community)})
+		}
+		for _, key := range sortedKeys(agent.Metadata) {
+			node.AttValues = append(node.AttValues, gexfAttValue{For: "meta_" + key, Value: agent.Metadata[key]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, id := range sortedEdgeIDs(snapshot) {
+		edge := snapshot.Edges[id]
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     string(id),
+			Source: string(edge.SourceID),
+			Target: string(edge.TargetID),
+			Weight: formatFloat(edge.Weight),
+			AttValues: []gexfAttValue{
+				{For: "usage", Value: strconv.FormatInt(edge.Usage, 10)},
+				{For: "last_used", Value: edge.LastUsed.Format(time.RFC3339)},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// writeXML writes an indented XML document with header
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// metadataKeys returns the union of agent metadata keys, sorted
+func metadataKeys(snapshot *types.GraphSnapshot) []string {
+	union := map[string]string{}
+	for _, agent := range snapshot.Agents {
+		for key := range agent.Metadata {
+			union[key] = ""
+		}
+	}
+	return sortedKeys(union)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedAgentIDs(snapshot *types.GraphSnapshot) []types.AgentID {
+	ids := make([]types.AgentID, 0, len(snapshot.Agents))
+	for id := range snapshot.Agents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func sortedEdgeIDs(snapshot *types.GraphSnapshot) []types.EdgeID {
+	ids := make([]types.EdgeID, 0, len(snapshot.Edges))
+	for id := range snapshot.Edges {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// edgeType returns whether a snapshot's edges are "directed" or "undirected"
+func edgeType(snapshot *types.GraphSnapshot) string {
+	if snapshot.Stats.Undirected {
+		return "undirected"
+	}
+	return "directed"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// dotQuote quotes a DOT identifier, escaping quotes and backslashes
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -2,119 +2,170 @@ package topology
 
 import (
 	"fmt"
-	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
-// Graph represents the agent communication network
+// Graph represents the agent communication network. Agents and the edges
+// from them are spread over shards with a lock each, see shard.go.
 type Graph struct {
-	agents map[types.AgentID]*types.Agent
-	edges  map[types.EdgeID]*types.Edge
+	shards [graphShards]*shard
 	config *types.Config
+	scorer types.EdgeScorer // Of every edge, see SetEdgeScorer
 
-	mu sync.RWMutex
+	pruning PruningStrategy // See SetPruningStrategy
+	history *edgeHistory    // Weights of edges whose agent left, see EDGE_PRIOR_HISTORY
+
+	guardrails *Guardrails
+	tuner      *tuner // Nil without a reduction or density target
+	adaptive   atomic.Pointer[types.AdaptiveDecayStatus]
+	priors     atomic.Pointer[types.TopologyPriors] // Learned in a previous session, see SetPriors
+	clock      clockSource
 }
 
-// NewGraph creates a new graph with full mesh topology
+// NewGraph creates a new graph
 func NewGraph(config *types.Config) *Graph {
 	return &Graph{
-		agents: make(map[types.AgentID]*types.Agent),
-		edges:  make(map[types.EdgeID]*types.Edge),
+		shards: newShards(),
 		config: config,
+		scorer: config.EdgeScoring.Scorer(),
+
+		pruning:    NewPruningStrategy(config),
+		history:    newEdgeHistory(),
+		guardrails: NewGuardrails(config),
+		tuner:      newTuner(config),
 	}
 }
 
-// AddAgent adds a new agent to the graph and creates edges to all existing agents (full mesh)
+// Guardrails returns the rate-of-change limits applied to this graph
+func (g *Graph) Guardrails() *Guardrails {
+	return g.guardrails
+}
+
+// SetEdgeScorer makes every edge, existing and new, turn its traffic into
+// weight with scorer instead of the EDGE_SCORING one
+func (g *Graph) SetEdgeScorer(scorer types.EdgeScorer) {
+	g.lockAll()
+	defer g.unlockAll()
+
+	g.scorer = scorer
+	for _, edge := range g.allEdges() {
+		edge.SetScorer(scorer)
+	}
+}
+
+// AddAgent adds a new agent to the graph. With EDGE_BOOTSTRAP=full_mesh it
+// also creates edges to and from all existing agents of its namespace (one
+// per pair with EDGE_MODE=undirected); otherwise its edges form as it sends
+// and receives messages.
 func (g *Graph) AddAgent(agent *types.Agent) error {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	g.lockAll()
+	defer g.unlockAll()
 
-	if _, exists := g.agents[agent.ID]; exists {
+	if _, exists := g.agent(agent.ID); exists {
 		return fmt.Errorf("agent %s already exists", agent.ID)
 	}
 
-	g.agents[agent.ID] = agent
+	g.shardOf(agent.ID).agents[agent.ID] = agent
+	if g.config.EdgeBootstrap != types.EdgeBootstrapFullMesh {
+		return nil
+	}
 
 	// Create self-loop edge for the agent (to track its own activity)
-	selfEdge := &types.Edge{
-		ID:        types.NewEdgeID(agent.ID, agent.ID),
-		SourceID:  agent.ID,
-		TargetID:  agent.ID,
-		Weight:    g.config.InitialEdgeWeight,
-		Usage:     0,
-		CreatedAt: time.Now(),
-		LastUsed:  time.Now(),
-	}
-	g.edges[selfEdge.ID] = selfEdge
-
-	// Create bidirectional edges to all existing agents (full mesh initialization)
-	for _, existingAgent := range g.agents {
+	selfEdge := g.newEdge(agent, agent)
+	g.putEdge(selfEdge)
+
+	// Create bidirectional edges to all existing agents (full mesh initialization),
+	// except those in other namespaces or EDGE_CONSTRAINTS forbid
+	for _, existingAgent := range g.allAgents() {
 		if existingAgent.ID == agent.ID {
 			continue
 		}
 
+		// One edge for both directions
+		if g.config.EdgeMode == types.EdgeModeUndirected {
+			source, target := agent, existingAgent
+			if target.ID < source.ID {
+				source, target = target, source
+			}
+			if edge := g.newEdge(source, target); edge != nil {
+				g.putEdge(edge)
+			}
+			continue
+		}
+
 		// Edge from new agent to existing agent
-		edge1 := &types.Edge{
-			ID:        types.NewEdgeID(agent.ID, existingAgent.ID),
-			SourceID:  agent.ID,
-			TargetID:  existingAgent.ID,
-			Weight:    g.config.InitialEdgeWeight,
-			Usage:     0,
-			CreatedAt: time.Now(),
-			LastUsed:  time.Now(),
+		if edge := g.newEdge(agent, existingAgent); edge != nil {
+			g.putEdge(edge)
 		}
-		g.edges[edge1.ID] = edge1
 
 		// Edge from existing agent to new agent
-		edge2 := &types.Edge{
-			ID:        types.NewEdgeID(existingAgent.ID, agent.ID),
-			SourceID:  existingAgent.ID,
-			TargetID:  agent.ID,
-			Weight:    g.config.InitialEdgeWeight,
-			Usage:     0,
-			CreatedAt: time.Now(),
-			LastUsed:  time.Now(),
+		if edge := g.newEdge(existingAgent, agent); edge != nil {
+			g.putEdge(edge)
 		}
-		g.edges[edge2.ID] = edge2
 	}
 
 	return nil
 }
 
-// RemoveAgent removes an agent and all its edges
+// RemoveAgent removes an agent and all its edges, remembering their weights
+// for EDGE_PRIOR_HISTORY
 func (g *Graph) RemoveAgent(agentID types.AgentID) error {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	g.lockAll()
+	defer g.unlockAll()
 
-	if _, exists := g.agents[agentID]; !exists {
+	if _, exists := g.agent(agentID); !exists {
 		return fmt.Errorf("agent %s not found", agentID)
 	}
 
 	// Remove all edges connected to this agent
-	edgesToRemove := []types.EdgeID{}
-	for edgeID, edge := range g.edges {
+	edgesToRemove := []*types.Edge{}
+	for _, edge := range g.allEdges() {
 		if edge.SourceID == agentID || edge.TargetID == agentID {
-			edgesToRemove = append(edgesToRemove, edgeID)
+			edgesToRemove = append(edgesToRemove, edge)
 		}
 	}
 
-	for _, edgeID := range edgesToRemove {
-		delete(g.edges, edgeID)
+	removedIDs := make([]types.EdgeID, 0, len(edgesToRemove))
+	for _, edge := range edgesToRemove {
+		g.deleteEdge(edge)
+		removedIDs = append(removedIDs, edge.ID)
+	}
+	g.guardrails.Forget(removedIDs...)
+	if g.config.EdgePriorHistory > 0 {
+		g.history.remember(edgesToRemove, g.clock.now())
 	}
 
-	delete(g.agents, agentID)
+	delete(g.shardOf(agentID).agents, agentID)
 	return nil
 }
 
+// edgeID returns the ID of the edge carrying traffic from sourceID to
+// targetID, the pair's one edge with EDGE_MODE=undirected
+func (g *Graph) edgeID(sourceID, targetID types.AgentID) types.EdgeID {
+	return g.config.EdgeMode.EdgeID(sourceID, targetID)
+}
+
+// lookupEdge finds an edge by ID in its source's shard; with
+// EDGE_MODE=undirected either direction finds the pair's edge
+func (g *Graph) lookupEdge(edgeID types.EdgeID) (*types.Edge, bool) {
+	sourceID, targetID, err := types.ParseEdgeID(edgeID)
+	if err != nil {
+		return nil, false
+	}
+	sourceID, targetID = g.config.EdgeMode.Endpoints(sourceID, targetID)
+	s := g.shardOf(sourceID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	edge, exists := s.edges[types.NewEdgeID(sourceID, targetID)]
+	return edge, exists
+}
+
 // GetEdge retrieves an edge by ID
 func (g *Graph) GetEdge(edgeID types.EdgeID) (*types.Edge, error) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	edge, exists := g.edges[edgeID]
+	edge, exists := g.lookupEdge(edgeID)
 	if !exists {
 		return nil, fmt.Errorf("edge %s not found", edgeID)
 	}
@@ -123,125 +174,280 @@ func (g *Graph) GetEdge(edgeID types.EdgeID) (*types.Edge, error) {
 
 // GetEdgeBetween retrieves the edge between two agents
 func (g *Graph) GetEdgeBetween(sourceID, targetID types.AgentID) (*types.Edge, error) {
-	edgeID := types.NewEdgeID(sourceID, targetID)
-	return g.GetEdge(edgeID)
+	return g.GetEdge(g.edgeID(sourceID, targetID))
 }
 
 // ReinforceEdge strengthens an edge (called when message passes through it)
-// If edge doesn't exist, it creates it first (SlimeMold behavior: paths form on first use)
-func (g *Graph) ReinforceEdge(edgeID types.EdgeID) error {
-	g.mu.Lock()
-	edge, exists := g.edges[edgeID]
+// and counts the message under its type, if given.
+// If edge doesn't exist, it creates it first (SlimeMold behavior: paths form on first use).
+// With EDGE_MODE=undirected traffic either way reinforces the pair's one edge.
+func (g *Graph) ReinforceEdge(edgeID types.EdgeID, msgType types.MessageType) error {
+	// Parse edge ID to get source and target
+	sourceID, targetID, err := types.ParseEdgeID(edgeID)
+	if err != nil {
+		return err
+	}
+	sourceID, targetID = g.config.EdgeMode.Endpoints(sourceID, targetID)
+	edgeID = types.NewEdgeID(sourceID, targetID)
 
-	if !exists {
-		// Parse edge ID to get source and target
-		// EdgeID format: "sourceID->targetID"
-		parts := strings.Split(string(edgeID), "->")
-		if len(parts) != 2 {
-			g.mu.Unlock()
-			return fmt.Errorf("invalid edge ID format: %s", edgeID)
-		}
+	// Existing edges only need their shards read, so they are reinforced concurrently
+	lock := g.lockEdge(sourceID, targetID, false)
+	edge, exists := lock.source.edges[edgeID]
+	created := false
 
-		sourceID := types.AgentID(parts[0])
-		targetID := types.AgentID(parts[1])
+	if !exists {
+		lock.unlock()
 
-		// Verify both agents exist
-		if _, exists := g.agents[sourceID]; !exists {
-			g.mu.Unlock()
-			return fmt.Errorf("source agent %s not found", sourceID)
-		}
-		if _, exists := g.agents[targetID]; !exists {
-			g.mu.Unlock()
-			return fmt.Errorf("target agent %s not found", targetID)
+		// New paths are a structural change, blocked while frozen
+		if g.guardrails.Frozen() {
+			return ErrTopologyFrozen
 		}
 
-		// Create new edge with initial weight (0.5 - moderate strength)
-		edge = &types.Edge{
-			ID:        edgeID,
-			SourceID:  sourceID,
-			TargetID:  targetID,
-			Weight:    0.5, // Initial weight for new paths
-			Usage:     0,
-			LastUsed:  time.Now(),
-			CreatedAt: time.Now(),
+		// Another message may have created the edge before the shard was locked for writing
+		lock = g.lockEdge(sourceID, targetID, true)
+		if edge, exists = lock.source.edges[edgeID]; !exists {
+			// Verify both agents exist
+			source, ok := lock.source.agents[sourceID]
+			if !ok {
+				lock.unlock()
+				return fmt.Errorf("source agent %s not found", sourceID)
+			}
+			target, ok := lock.target.agents[targetID]
+			if !ok {
+				lock.unlock()
+				return fmt.Errorf("target agent %s not found", targetID)
+			}
+
+			// Create new edge with its initial weight
+			edge = g.newEdge(source, target)
+			if edge == nil {
+				lock.unlock()
+				return ErrEdgeForbidden
+			}
+			lock.source.edges[edgeID] = edge
+			created = true
 		}
-		g.edges[edgeID] = edge
 	}
-	g.mu.Unlock()
 
-	// Reinforce the edge (whether newly created or existing)
-	edge.Reinforce(g.config.ReinforcementAmount)
+	// Edges from before a constraint was added are no longer reinforced past it
+	allowed, maxWeight := g.edgeLimit(lock.source.agents[edge.SourceID], lock.target.agents[edge.TargetID])
+	if !allowed {
+		lock.unlock()
+		return ErrEdgeForbidden
+	}
+	amount := min(edge.Gain(g.reinforcementAmount(edge)), max(0, maxWeight-edge.GetWeight()))
+	lock.unlock()
+
+	if created {
+		g.guardrails.RecordChurn(1)
+	}
+
+	// Reinforce the edge (whether newly created or existing); usage is still
+	// counted when the guardrails allow no weight change
+	edge.ReinforceAt(g.guardrails.AllowWeightChange(edgeID, amount), msgType, g.clock.now())
+	return nil
+}
+
+// SetEdgeMetadata annotates an edge, e.g. with the SLA or channel of the traffic
+// over it; an empty value removes the key
+func (g *Graph) SetEdgeMetadata(edgeID types.EdgeID, key, value string) error {
+	edge, exists := g.lookupEdge(edgeID)
+	if !exists {
+		return fmt.Errorf("edge %s not found", edgeID)
+	}
+	edge.SetMetadata(key, value)
+	return nil
+}
+
+// ObserveLatency records the latency of a message sent over an edge
+func (g *Graph) ObserveLatency(edgeID types.EdgeID, latency time.Duration) error {
+	edge, exists := g.lookupEdge(edgeID)
+	if !exists {
+		return fmt.Errorf("edge %s not found", edgeID)
+	}
+	edge.ObserveLatency(latency)
 	return nil
 }
 
 // DecayAllEdges applies decay to all edges (simulates pheromone evaporation)
 func (g *Graph) DecayAllEdges() {
-	g.mu.RLock()
-	edges := make([]*types.Edge, 0, len(g.edges))
-	for _, edge := range g.edges {
+	g.decayAllEdges()
+}
+
+// decayAllEdges decays all edges, returning the total weight removed
+func (g *Graph) decayAllEdges() float64 {
+	if g.guardrails.Frozen() {
+		return 0
+	}
+
+	decayRate := g.decayRate()
+	g.rLockAll()
+	_, count := g.counts()
+	edges := make([]*types.Edge, 0, count)
+	rates := make([]float64, 0, count)
+	for _, edge := range g.allEdges() {
 		edges = append(edges, edge)
+		rates = append(rates, g.edgeDecayRate(edge, decayRate))
 	}
-	g.mu.RUnlock()
+	g.rUnlockAll()
 
-	for _, edge := range edges {
-		edge.Decay(g.config.DecayRate)
+	applied := 0.0
+	for i, edge := range edges {
+		applied += edge.Decay(g.guardrails.AllowWeightChange(edge.ID, edge.Loss(rates[i])))
 	}
+	return applied
 }
 
-// PruneWeakEdges removes edges below the prune threshold
+// PruneWeakEdges removes the edges the pruning strategy picks, by default
+// those below the prune threshold, except those that would leave an agent
+// with fewer than PruneMinDegree edges: each agent keeps its strongest. At
+// most MaxPrunePerCycle edges are removed, in the strategy's order; the rest
+// wait for the next cycle.
 func (g *Graph) PruneWeakEdges() []types.EdgeID {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	prunedEdges, _ := g.pruneWeakEdges()
+	return prunedEdges
+}
+
+// pruneWeakEdges prunes weak edges, also reporting how the candidates fared
+func (g *Graph) pruneWeakEdges() ([]types.EdgeID, pruneOutcome) {
+	outcome := pruneOutcome{threshold: g.pruneThreshold()}
+	g.lockAll()
+
+	edges := []*types.Edge{}
+	for _, edge := range g.allEdges() {
+		edges = append(edges, edge)
+	}
+	strategy := g.pruning
+	if strategy == nil {
+		// Unknown PRUNE_STRATEGY, which Validate rejects
+		strategy = ThresholdPruning{}
+	}
+	candidates := strategy.Candidates(edges, outcome.threshold, g.clock.now())
+	outcome.candidates = len(candidates)
+	candidates = g.keepConnected(candidates)
+	outcome.protected = outcome.candidates - len(candidates)
+
+	limit := g.guardrails.LimitPrunes(len(candidates))
+	outcome.deferred = len(candidates) - limit
 
 	prunedEdges := []types.EdgeID{}
+	for _, edge := range candidates[:limit] {
+		prunedEdges = append(prunedEdges, edge.ID)
+		g.deleteEdge(edge)
+	}
+	g.unlockAll()
+
+	g.guardrails.Forget(prunedEdges...)
+	g.guardrails.RecordChurn(len(prunedEdges))
+
+	return prunedEdges, outcome
+}
 
-	for edgeID, edge := range g.edges {
-		if edge.GetWeight() < g.config.PruneThreshold {
-			prunedEdges = append(prunedEdges, edgeID)
-			delete(g.edges, edgeID)
+// keepConnected drops the prune candidates, weakest first, whose removal would
+// leave either agent with fewer than PruneMinDegree edges to other agents, as
+// if all earlier ones were removed (must be called with all shards locked)
+func (g *Graph) keepConnected(candidates []*types.Edge) []*types.Edge {
+	minDegree := g.config.PruneMinDegree
+	if minDegree <= 0 {
+		return candidates
+	}
+
+	degree := make(map[types.AgentID]int)
+	for _, edge := range g.allEdges() {
+		if edge.SourceID != edge.TargetID {
+			degree[edge.SourceID]++
+			degree[edge.TargetID]++
 		}
 	}
 
-	return prunedEdges
+	prunable := candidates[:0]
+	for _, edge := range candidates {
+		if edge.SourceID != edge.TargetID {
+			if degree[edge.SourceID] <= minDegree || degree[edge.TargetID] <= minDegree {
+				continue
+			}
+			degree[edge.SourceID]--
+			degree[edge.TargetID]--
+		}
+		prunable = append(prunable, edge)
+	}
+	return prunable
 }
 
 // GetSnapshot returns a snapshot of the current graph state
 func (g *Graph) GetSnapshot() *types.GraphSnapshot {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	g.rLockAll()
+	defer g.rUnlockAll()
 
 	// Deep copy agents and edges
 	agentsCopy := make(map[types.AgentID]*types.Agent)
-	for id, agent := range g.agents {
+	for id, agent := range g.allAgents() {
 		agentCopy := *agent
 		agentsCopy[id] = &agentCopy
 	}
 
 	edgesCopy := make(map[types.EdgeID]*types.Edge)
-	for id, edge := range g.edges {
-		edgeCopy := *edge
-		edgesCopy[id] = &edgeCopy
+	for id, edge := range g.allEdges() {
+		edgesCopy[id] = edge.Clone()
 	}
 
 	stats := g.calculateStats()
+	communities := g.communities()
+	for _, number := range communities {
+		stats.Communities = max(stats.Communities, number+1)
+	}
+	stats.Components = len(g.components())
 
 	return &types.GraphSnapshot{
-		Agents:    agentsCopy,
-		Edges:     edgesCopy,
-		Timestamp: time.Now(),
-		Stats:     stats,
+		Agents:      agentsCopy,
+		Edges:       edgesCopy,
+		Communities: communities,
+		Timestamp:   time.Now(),
+		Stats:       stats,
 	}
 }
 
-// calculateStats computes graph statistics (must be called with read lock held)
+// Restore replaces the graph contents with a snapshot
+func (g *Graph) Restore(snapshot *types.GraphSnapshot) {
+	g.lockAll()
+	defer g.unlockAll()
+
+	for _, s := range g.shards {
+		s.agents = make(map[types.AgentID]*types.Agent, len(snapshot.Agents)/graphShards)
+		s.edges = make(map[types.EdgeID]*types.Edge, len(snapshot.Edges)/graphShards)
+	}
+	for id, agent := range snapshot.Agents {
+		g.shardOf(id).agents[id] = agent
+	}
+	for id, edge := range snapshot.Edges {
+		edge.SetScorer(g.scorer)
+		if g.config.EdgeMode == types.EdgeModeUndirected && edge.TargetID < edge.SourceID {
+			id = g.undirect(edge)
+		}
+		if kept, exists := g.shardOf(edge.SourceID).edges[id]; exists && kept.GetWeight() >= edge.GetWeight() {
+			continue
+		}
+		g.shardOf(edge.SourceID).edges[id] = edge
+	}
+}
+
+// undirect turns an edge of a snapshot taken with EDGE_MODE=directed into its
+// pair's edge, of which Restore keeps the stronger direction
+func (g *Graph) undirect(edge *types.Edge) types.EdgeID {
+	edge.SourceID, edge.TargetID = edge.TargetID, edge.SourceID
+	edge.ID = types.NewEdgeID(edge.SourceID, edge.TargetID)
+	return edge.ID
+}
+
+// calculateStats computes graph statistics (must be called with all shards locked)
 func (g *Graph) calculateStats() types.GraphStats {
-	numAgents := len(g.agents)
-	numEdges := len(g.edges)
+	numAgents, numEdges := g.counts()
 
 	if numEdges == 0 {
 		return types.GraphStats{
 			TotalAgents: numAgents,
 			TotalEdges:  0,
+			Undirected:  g.config.EdgeMode == types.EdgeModeUndirected,
 		}
 	}
 
@@ -249,7 +455,7 @@ func (g *Graph) calculateStats() types.GraphStats {
 	activeEdges := 0
 	minWeight = 1.0 // Initialize to max possible weight
 
-	for _, edge := range g.edges {
+	for _, edge := range g.allEdges() {
 		weight := edge.GetWeight()
 		totalWeight += weight
 
@@ -266,19 +472,7 @@ func (g *Graph) calculateStats() types.GraphStats {
 
 	avgWeight := totalWeight / float64(numEdges)
 
-	// Calculate density (actual edges / possible edges in full mesh)
-	// In a directed full mesh, possible edges = n * (n - 1)
-	possibleEdges := numAgents * (numAgents - 1)
-	density := 0.0
-	if possibleEdges > 0 {
-		density = float64(numEdges) / float64(possibleEdges)
-	}
-
-	// Calculate reduction percentage from full mesh
-	reductionPercent := 0.0
-	if possibleEdges > 0 {
-		reductionPercent = (1.0 - density) * 100.0
-	}
+	density, reductionPercent := g.meshDensity(numEdges)
 
 	return types.GraphStats{
 		TotalAgents:      numAgents,
@@ -289,29 +483,69 @@ func (g *Graph) calculateStats() types.GraphStats {
 		MinWeight:        minWeight,
 		Density:          density,
 		ReductionPercent: reductionPercent,
+		Undirected:       g.config.EdgeMode == types.EdgeModeUndirected,
+		Centrality:       g.centrality(),
+		Namespaces:       g.namespaceStats(),
+		Tuning:           g.Tuning(),
+		AdaptiveDecay:    g.AdaptiveDecay(),
+	}
+}
+
+// meshDensity returns the share of a full mesh's edges a graph has, and the
+// percentage it is reduced by from full mesh. Edges never cross namespaces, so
+// the full mesh is that of each namespace (must be called with all shards locked).
+func (g *Graph) meshDensity(numEdges int) (density, reductionPercent float64) {
+	possibleEdges := 0
+	for _, agents := range g.namespaceSizes() {
+		possibleEdges += g.fullMeshEdges(agents)
+	}
+	return densityOf(numEdges, possibleEdges)
+}
+
+// fullMeshEdges returns how many edges a full mesh of n agents has, as
+// EDGE_BOOTSTRAP=full_mesh creates it: n self-loops and n * (n - 1) edges
+// between agents, half as many of those with EDGE_MODE=undirected
+func (g *Graph) fullMeshEdges(n int) int {
+	between := n * (n - 1)
+	if g.config.EdgeMode == types.EdgeModeUndirected {
+		between /= 2
+	}
+	return n + between
+}
+
+// densityOf returns the share of possible edges present and the percentage
+// they are reduced by
+func densityOf(numEdges, possibleEdges int) (density, reductionPercent float64) {
+	if possibleEdges == 0 {
+		return 0, 0
 	}
+	density = float64(numEdges) / float64(possibleEdges)
+	return density, (1.0 - density) * 100.0
 }
 
 // GetAgentCount returns the number of agents
 func (g *Graph) GetAgentCount() int {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return len(g.agents)
+	g.rLockAll()
+	defer g.rUnlockAll()
+	agents, _ := g.counts()
+	return agents
 }
 
 // GetEdgeCount returns the number of edges
 func (g *Graph) GetEdgeCount() int {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return len(g.edges)
+	g.rLockAll()
+	defer g.rUnlockAll()
+	_, edges := g.counts()
+	return edges
 }
 
 // GetAgent retrieves an agent by ID
 func (g *Graph) GetAgent(agentID types.AgentID) (*types.Agent, error) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	s := g.shardOf(agentID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	agent, exists := g.agents[agentID]
+	agent, exists := s.agents[agentID]
 	if !exists {
 		return nil, fmt.Errorf("agent %s not found", agentID)
 	}
@@ -320,11 +554,12 @@ func (g *Graph) GetAgent(agentID types.AgentID) (*types.Agent, error) {
 
 // GetAllAgents returns all agents
 func (g *Graph) GetAllAgents() []*types.Agent {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	g.rLockAll()
+	defer g.rUnlockAll()
 
-	agents := make([]*types.Agent, 0, len(g.agents))
-	for _, agent := range g.agents {
+	count, _ := g.counts()
+	agents := make([]*types.Agent, 0, count)
+	for _, agent := range g.allAgents() {
 		agents = append(agents, agent)
 	}
 	return agents
@@ -332,14 +567,41 @@ func (g *Graph) GetAllAgents() []*types.Agent {
 
 // GetNeighbors returns agents directly connected to the given agent (edges with weight > threshold)
 func (g *Graph) GetNeighbors(agentID types.AgentID, minWeight float64) []types.AgentID {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	if g.config.EdgeMode == types.EdgeModeUndirected {
+		return g.undirectedNeighbors(agentID, minWeight)
+	}
+
+	// Edges from an agent all live in its shard
+	s := g.shardOf(agentID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	neighbors := []types.AgentID{}
-	for _, edge := range g.edges {
+	for _, edge := range s.edges {
 		if edge.SourceID == agentID && edge.GetWeight() >= minWeight {
 			neighbors = append(neighbors, edge.TargetID)
 		}
 	}
 	return neighbors
 }
+
+// undirectedNeighbors returns the agents at the other end of the given
+// agent's edges with EDGE_MODE=undirected, which live in either agent's shard
+func (g *Graph) undirectedNeighbors(agentID types.AgentID, minWeight float64) []types.AgentID {
+	g.rLockAll()
+	defer g.rUnlockAll()
+
+	neighbors := []types.AgentID{}
+	for _, edge := range g.allEdges() {
+		if edge.GetWeight() < minWeight {
+			continue
+		}
+		switch agentID {
+		case edge.SourceID:
+			neighbors = append(neighbors, edge.TargetID)
+		case edge.TargetID:
+			neighbors = append(neighbors, edge.SourceID)
+		}
+	}
+	return neighbors
+}
@@ -0,0 +1,251 @@
+package topology
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ErrTopologyFrozen is returned when a structural change is attempted while the topology is frozen
+var ErrTopologyFrozen = errors.New("topology is frozen")
+
+// weightBudgetWindow is the window MaxWeightChangePerMinute applies to
+const weightBudgetWindow = time.Minute
+
+// Guardrails bounds how fast the topology may change.
+// It caps prunes per cycle and weight change per edge per minute, and trips a
+// circuit breaker that freezes the topology when structural churn (edges created
+// plus pruned) within ChurnWindow exceeds ChurnFreezeThreshold. Automatic freezes
+// clear once a quiet window has passed; manual freezes last until Unfreeze.
+type Guardrails struct {
+	config *types.Config
+
+	mu            sync.Mutex
+	budgets       map[types.EdgeID]*weightBudget
+	churn         []churnBucket
+	frozen        atomic.Bool // Written with mu held, read without it on hot paths
+	manual        bool
+	reason        string
+	frozenAt      time.Time
+	pruneDeferred int64
+	changeClamped int64
+
+	onTransition func(frozen bool, reason string)
+	clock        clockSource
+}
+
+// weightBudget tracks how much an edge's weight has moved in the current window
+type weightBudget struct {
+	start time.Time
+	used  float64
+}
+
+// churnBucket counts structural changes recorded at one instant
+type churnBucket struct {
+	at    time.Time
+	count int
+}
+
+// NewGuardrails creates guardrails from the topology configuration
+func NewGuardrails(config *types.Config) *Guardrails {
+	return &Guardrails{
+		config:  config,
+		budgets: make(map[types.EdgeID]*weightBudget),
+	}
+}
+
+// OnTransition registers a callback invoked whenever the topology freezes or unfreezes
+func (gr *Guardrails) OnTransition(fn func(frozen bool, reason string)) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	gr.onTransition = fn
+}
+
+// Frozen reports whether topology changes are currently blocked
+func (gr *Guardrails) Frozen() bool {
+	return gr.frozen.Load()
+}
+
+// Freeze blocks topology changes. Manual freezes are not lifted automatically.
+func (gr *Guardrails) Freeze(reason string, manual bool) {
+	gr.mu.Lock()
+	if gr.frozen.Load() && (gr.manual || !manual) {
+		gr.mu.Unlock()
+		return
+	}
+	changed := !gr.frozen.Load()
+	gr.frozen.Store(true)
+	gr.manual = manual
+	gr.reason = reason
+	gr.frozenAt = gr.clock.now()
+	callback := gr.onTransition
+	gr.mu.Unlock()
+
+	if changed && callback != nil {
+		callback(true, reason)
+	}
+}
+
+// Unfreeze lifts any freeze and resets the churn window
+func (gr *Guardrails) Unfreeze() {
+	gr.mu.Lock()
+	if !gr.frozen.Load() {
+		gr.mu.Unlock()
+		return
+	}
+	gr.frozen.Store(false)
+	gr.manual = false
+	gr.reason = ""
+	gr.churn = nil
+	callback := gr.onTransition
+	gr.mu.Unlock()
+
+	if callback != nil {
+		callback(false, "")
+	}
+}
+
+// Manual reports whether the current freeze was requested explicitly
+func (gr *Guardrails) Manual() bool {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	return gr.frozen.Load() && gr.manual
+}
+
+// AllowWeightChange returns how much of a weight change of the given magnitude
+// an edge may make now, and charges it against the edge's budget
+func (gr *Guardrails) AllowWeightChange(edgeID types.EdgeID, amount float64) float64 {
+	// Frozen and unbudgeted changes are settled without waiting on mu
+	if gr.frozen.Load() {
+		return 0
+	}
+	limit := gr.config.MaxWeightChangePerMinute
+	if limit <= 0 {
+		return amount
+	}
+
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	if gr.frozen.Load() {
+		return 0
+	}
+
+	now := gr.clock.now()
+	budget, ok := gr.budgets[edgeID]
+	if !ok || now.Sub(budget.start) >= weightBudgetWindow {
+		budget = &weightBudget{start: now}
+		gr.budgets[edgeID] = budget
+	}
+
+	allowed := math.Min(amount, math.Max(0, limit-budget.used))
+	if allowed < amount {
+		gr.changeClamped++
+	}
+	budget.used += allowed
+	return allowed
+}
+
+// Forget drops per-edge state for removed edges
+func (gr *Guardrails) Forget(edgeIDs ...types.EdgeID) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	for _, id := range edgeIDs {
+		delete(gr.budgets, id)
+	}
+}
+
+// LimitPrunes returns how many of the candidate prunes may run this cycle
+func (gr *Guardrails) LimitPrunes(candidates int) int {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	if gr.frozen.Load() {
+		return 0
+	}
+	limit := gr.config.MaxPrunePerCycle
+	if limit <= 0 || candidates <= limit {
+		return candidates
+	}
+	gr.pruneDeferred += int64(candidates - limit)
+	return limit
+}
+
+// RecordChurn counts structural changes and trips the breaker when the window limit is exceeded
+func (gr *Guardrails) RecordChurn(count int) {
+	if count <= 0 {
+		return
+	}
+
+	gr.mu.Lock()
+	now := gr.clock.now()
+	gr.churn = append(gr.churn, churnBucket{at: now, count: count})
+	churn := gr.windowChurn(now)
+	threshold := gr.config.ChurnFreezeThreshold
+	gr.mu.Unlock()
+
+	if threshold > 0 && churn > threshold {
+		gr.Freeze("churn exceeded threshold", false)
+	}
+}
+
+// Recover lifts an automatic freeze once a full quiet window has passed.
+// It returns true if the topology was unfrozen.
+func (gr *Guardrails) Recover() bool {
+	gr.mu.Lock()
+	now := gr.clock.now()
+	eligible := gr.frozen.Load() && !gr.manual &&
+		now.Sub(gr.frozenAt) >= gr.config.ChurnWindow &&
+		gr.windowChurn(now) <= gr.config.ChurnFreezeThreshold
+	gr.mu.Unlock()
+
+	if eligible {
+		gr.Unfreeze()
+	}
+	return eligible
+}
+
+// Status returns the current guardrail state
+func (gr *Guardrails) Status() types.GuardrailStatus {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	now := gr.clock.now()
+	status := types.GuardrailStatus{
+		Frozen:        gr.frozen.Load(),
+		Manual:        gr.frozen.Load() && gr.manual,
+		Reason:        gr.reason,
+		Churn:         gr.windowChurn(now),
+		ChurnLimit:    gr.config.ChurnFreezeThreshold,
+		ChurnWindow:   gr.config.ChurnWindow.String(),
+		MaxPrune:      gr.config.MaxPrunePerCycle,
+		MaxChange:     gr.config.MaxWeightChangePerMinute,
+		PruneDeferred: gr.pruneDeferred,
+		ChangeClamped: gr.changeClamped,
+		UpdatedAt:     now,
+	}
+	if gr.frozen.Load() {
+		frozenAt := gr.frozenAt
+		status.FrozenAt = &frozenAt
+	}
+	return status
+}
+
+// windowChurn drops expired buckets and sums the rest (must be called with mu held)
+func (gr *Guardrails) windowChurn(now time.Time) int {
+	cutoff := now.Add(-gr.config.ChurnWindow)
+	i := 0
+	for i < len(gr.churn) && !gr.churn[i].at.After(cutoff) {
+		i++
+	}
+	gr.churn = gr.churn[i:]
+
+	total := 0
+	for _, bucket := range gr.churn {
+		total += bucket.count
+	}
+	return total
+}
@@ -0,0 +1,192 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// hubMinAgents is how many agents a mesh needs before one receiving most of
+// the edge weight counts as overloaded rather than the only one doing the work
+const hubMinAgents = 4
+
+// healthMonitor tracks pathological topology states across decay cycles
+type healthMonitor struct {
+	config *types.Config
+
+	mu     sync.Mutex
+	prunes map[types.EdgeID][]time.Time   // Recent prunes per edge
+	active map[string]types.TopologyIssue // Current issues by key
+}
+
+func newHealthMonitor(config *types.Config) *healthMonitor {
+	return &healthMonitor{
+		config: config,
+		prunes: make(map[types.EdgeID][]time.Time),
+		active: make(map[string]types.TopologyIssue),
+	}
+}
+
+// update records a cycle's prunes, replaces the current issues with those
+// found in the graph and returns the ones not present after the previous cycle
+func (h *healthMonitor) update(g *Graph, pruned []types.EdgeID, now time.Time) []types.TopologyIssue {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, edgeID := range pruned {
+		h.prunes[edgeID] = append(h.prunes[edgeID], now)
+	}
+	for edgeID, times := range h.prunes {
+		recent := times[:0]
+		for _, at := range times {
+			if now.Sub(at) <= h.config.TopologyHealthWindow {
+				recent = append(recent, at)
+			}
+		}
+		if len(recent) == 0 {
+			delete(h.prunes, edgeID)
+		} else {
+			h.prunes[edgeID] = recent
+		}
+	}
+
+	issues := append(h.oscillatingEdges(now), g.healthIssues(h.config, now)...)
+	active := make(map[string]types.TopologyIssue, len(issues))
+	var detected []types.TopologyIssue
+	for _, issue := range issues {
+		if previous, ok := h.active[issue.Key()]; ok {
+			issue.DetectedAt = previous.DetectedAt
+		} else {
+			detected = append(detected, issue)
+		}
+		active[issue.Key()] = issue
+	}
+	h.active = active
+	return detected
+}
+
+// oscillatingEdges returns the edges pruned OSCILLATION_PRUNES times within
+// the window, each prune after the first undone by traffic recreating the edge
+func (h *healthMonitor) oscillatingEdges(now time.Time) []types.TopologyIssue {
+	if h.config.OscillationPrunes <= 0 {
+		return nil
+	}
+	var issues []types.TopologyIssue
+	for edgeID, times := range h.prunes {
+		if len(times) < h.config.OscillationPrunes {
+			continue
+		}
+		source, target, _ := types.ParseEdgeID(edgeID)
+		issues = append(issues, types.TopologyIssue{
+			Kind:       types.TopologyIssueOscillatingEdge,
+			Subject:    string(edgeID),
+			Detail:     fmt.Sprintf("Edge %s was pruned and recreated %d times in %s", edgeID, len(times), h.config.TopologyHealthWindow),
+			Suggestion: "Its traffic is too sparse to outlast decay; lower DECAY_RATE for its roles with ROLE_POLICIES, or prune by PRUNE_STRATEGY=age",
+			Agents:     []types.AgentID{source, target},
+			Value:      float64(len(times)),
+			DetectedAt: now,
+		})
+	}
+	return issues
+}
+
+// healthIssues finds starved roles and overloaded hubs in the graph
+func (g *Graph) healthIssues(config *types.Config, now time.Time) []types.TopologyIssue {
+	g.rLockAll()
+	defer g.rUnlockAll()
+
+	// Weight of the edges into each agent, either end of undirected edges
+	strongest := make(map[types.AgentID]float64)
+	received := make(map[types.AgentID]float64)
+	total := 0.0
+	for _, edge := range g.allEdges() {
+		if edge.SourceID == edge.TargetID {
+			continue
+		}
+		weight := edge.GetWeight()
+		ends := []types.AgentID{edge.TargetID}
+		if g.config.EdgeMode == types.EdgeModeUndirected {
+			ends = append(ends, edge.SourceID)
+		}
+		for _, id := range ends {
+			strongest[id] = max(strongest[id], weight)
+			received[id] += weight
+			total += weight
+		}
+	}
+
+	var issues []types.TopologyIssue
+	if config.StarvedEdgeWeight > 0 {
+		// Routable agents past their first window, by role
+		roles := make(map[string][]types.AgentID)
+		for id, agent := range g.allAgents() {
+			if agent.Role != "" && agent.Routable() && now.Sub(agent.CreatedAt) >= config.TopologyHealthWindow {
+				roles[agent.Role] = append(roles[agent.Role], id)
+			}
+		}
+		for role, agents := range roles {
+			best := 0.0
+			for _, id := range agents {
+				best = max(best, strongest[id])
+			}
+			if best >= config.StarvedEdgeWeight {
+				continue
+			}
+			sort.Slice(agents, func(i, j int) bool { return agents[i] < agents[j] })
+			issues = append(issues, types.TopologyIssue{
+				Kind:       types.TopologyIssueStarvedRole,
+				Subject:    role,
+				Detail:     fmt.Sprintf("No %s agent has an edge into it stronger than %g (strongest %.2f)", role, config.StarvedEdgeWeight, best),
+				Suggestion: fmt.Sprintf("Check that work is routed to role %s, or lower DECAY_RATE for its roles with ROLE_POLICIES", role),
+				Agents:     agents,
+				Value:      best,
+				DetectedAt: now,
+			})
+		}
+	}
+
+	agents, _ := g.counts()
+	if config.HubOverloadShare > 0 && agents >= hubMinAgents && total > 0 {
+		for id, weight := range received {
+			share := weight / total
+			if share <= config.HubOverloadShare {
+				continue
+			}
+			role := ""
+			if agent, ok := g.agent(id); ok {
+				role = agent.Role
+			}
+			issues = append(issues, types.TopologyIssue{
+				Kind:       types.TopologyIssueHubOverload,
+				Subject:    string(id),
+				Detail:     fmt.Sprintf("Agent %s receives %.0f%% of all edge weight", id, share*100),
+				Suggestion: fmt.Sprintf("Add agents of role %s and set ROUTING_LOAD_BALANCE=true to spread its work", role),
+				Agents:     []types.AgentID{id},
+				Value:      share,
+				DetectedAt: now,
+			})
+		}
+	}
+	return issues
+}
+
+// issues returns the current issues, oldest detected first
+func (h *healthMonitor) issues() []types.TopologyIssue {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	issues := make([]types.TopologyIssue, 0, len(h.active))
+	for _, issue := range h.active {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if !issues[i].DetectedAt.Equal(issues[j].DetectedAt) {
+			return issues[i].DetectedAt.Before(issues[j].DetectedAt)
+		}
+		return issues[i].Key() < issues[j].Key()
+	})
+	return issues
+}
@@ -0,0 +1,216 @@
+package topology
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// layoutPrecision rounds coordinates, so agents that barely moved keep
+	// their position and snapshot deltas leave the layout out
+	layoutPrecision = 1000
+	// layoutTemperature is the furthest an agent moves in the first iteration
+	// of a layout with new agents, and layoutSettle in one without
+	layoutTemperature = 0.1
+	layoutSettle      = 0.01
+	// layoutGravity pulls agents toward the center in proportion to their
+	// distance from it, keeping the mesh off the borders
+	layoutGravity = 2.0
+	// layoutSpacing is the ideal distance between agents, over the square root
+	// of their number
+	layoutSpacing = 0.5
+	// layoutSpread is how far from their neighbors new agents are placed
+	layoutSpread = 0.05
+)
+
+// Layout computes stable 2D positions of the agents for dashboards: a
+// force-directed layout in the unit square, where edges pull agents together
+// in proportion to their weight and all agents push each other apart.
+//
+// Each update continues from the previous positions, so agents move only as
+// far as the topology changed. An agent new to the layout starts near the
+// agents it has edges to, or at a position seeded by its ID, so the same
+// topology always lays out the same. It is safe for concurrent use.
+type Layout struct {
+	iterations int
+
+	mu        sync.Mutex
+	positions map[types.AgentID]types.LayoutPosition
+}
+
+// NewLayout creates a layout running the given iterations per update, none
+// with 0
+func NewLayout(iterations int) *Layout {
+	return &Layout{iterations: iterations, positions: make(map[types.AgentID]types.LayoutPosition)}
+}
+
+// Restore continues from the positions of an earlier layout, e.g. those in
+// the snapshot a previous instance handed over
+func (l *Layout) Restore(positions map[types.AgentID]types.LayoutPosition) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.positions = make(map[types.AgentID]types.LayoutPosition, len(positions))
+	for id, position := range positions {
+		l.positions[id] = position
+	}
+}
+
+// Update lays out a snapshot's agents and returns their positions, nil
+// without iterations
+func (l *Layout) Update(snapshot *types.GraphSnapshot) map[types.AgentID]types.LayoutPosition {
+	if l.iterations <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ids := make([]types.AgentID, 0, len(snapshot.Agents))
+	for id := range snapshot.Agents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	index := make(map[types.AgentID]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// Edges between distinct agents, both directions of a pair adding up
+	type spring struct {
+		a, b   int
+		weight float64
+	}
+	springs := []spring{}
+	neighbors := make([][]int, len(ids))
+	edgeIDs := make([]types.EdgeID, 0, len(snapshot.Edges))
+	for id := range snapshot.Edges {
+		edgeIDs = append(edgeIDs, id)
+	}
+	sortIDs(edgeIDs)
+	for _, id := range edgeIDs {
+		edge := snapshot.Edges[id]
+		a, okA := index[edge.SourceID]
+		b, okB := index[edge.TargetID]
+		if !okA || !okB || a == b {
+			continue
+		}
+		springs = append(springs, spring{a, b, edge.Weight})
+		neighbors[a] = append(neighbors[a], b)
+		neighbors[b] = append(neighbors[b], a)
+	}
+
+	// Known agents keep their place, new ones start by their placed neighbors
+	x, y := make([]float64, len(ids)), make([]float64, len(ids))
+	placed := make([]bool, len(ids))
+	added := false
+	for i, id := range ids {
+		if position, ok := l.positions[id]; ok {
+			x[i], y[i], placed[i] = position.X, position.Y, true
+		}
+	}
+	for i, id := range ids {
+		if placed[i] {
+			continue
+		}
+		added = true
+		seedX, seedY := layoutSeed(id)
+		count := 0
+		for _, j := range neighbors[i] {
+			if placed[j] {
+				x[i] += x[j]
+				y[i] += y[j]
+				count++
+			}
+		}
+		if count == 0 {
+			x[i], y[i] = seedX, seedY
+		} else {
+			x[i] = x[i]/float64(count) + (seedX-0.5)*2*layoutSpread
+			y[i] = y[i]/float64(count) + (seedY-0.5)*2*layoutSpread
+		}
+		placed[i] = true
+	}
+
+	temperature := layoutSettle
+	if added {
+		temperature = layoutTemperature
+	}
+	k := layoutSpacing / math.Sqrt(float64(max(len(ids), 1)))
+	dx, dy := make([]float64, len(ids)), make([]float64, len(ids))
+	for iteration := 0; iteration < l.iterations; iteration++ {
+		for i := range ids {
+			dx[i] = layoutGravity * (0.5 - x[i])
+			dy[i] = layoutGravity * (0.5 - y[i])
+		}
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				ox, oy := x[i]-x[j], y[i]-y[j]
+				distance := math.Hypot(ox, oy)
+				if distance < 1e-9 {
+					// Agents on the same spot part along a direction seeded by their order
+					angle := float64(i*len(ids)+j) * 2.399963
+					ox, oy, distance = math.Cos(angle)*1e-3, math.Sin(angle)*1e-3, 1e-3
+				}
+				force := k * k / distance
+				dx[i] += ox / distance * force
+				dy[i] += oy / distance * force
+				dx[j] -= ox / distance * force
+				dy[j] -= oy / distance * force
+			}
+		}
+		for _, s := range springs {
+			ox, oy := x[s.a]-x[s.b], y[s.a]-y[s.b]
+			distance := math.Hypot(ox, oy)
+			if distance < 1e-9 {
+				continue
+			}
+			force := distance * distance / k * s.weight
+			dx[s.a] -= ox / distance * force
+			dy[s.a] -= oy / distance * force
+			dx[s.b] += ox / distance * force
+			dy[s.b] += oy / distance * force
+		}
+
+		// Cool down linearly, so the last iterations only settle
+		step := temperature * (1 - float64(iteration)/float64(l.iterations))
+		for i := range ids {
+			length := math.Hypot(dx[i], dy[i])
+			if length < 1e-12 {
+				continue
+			}
+			move := math.Min(length, step)
+			x[i] = math.Min(1, math.Max(0, x[i]+dx[i]/length*move))
+			y[i] = math.Min(1, math.Max(0, y[i]+dy[i]/length*move))
+		}
+	}
+
+	positions := make(map[types.AgentID]types.LayoutPosition, len(ids))
+	for i, id := range ids {
+		positions[id] = types.LayoutPosition{X: roundCoordinate(x[i]), Y: roundCoordinate(y[i])}
+	}
+	l.positions = positions
+
+	out := make(map[types.AgentID]types.LayoutPosition, len(positions))
+	for id, position := range positions {
+		out[id] = position
+	}
+	return out
+}
+
+// layoutSeed returns the position an agent without placed neighbors starts
+// at, derived from its ID and away from the borders
+func layoutSeed(id types.AgentID) (float64, float64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(id))
+	sum := hash.Sum64()
+	return 0.1 + 0.8*float64(uint32(sum))/math.MaxUint32, 0.1 + 0.8*float64(sum>>32)/math.MaxUint32
+}
+
+// roundCoordinate rounds a coordinate to layoutPrecision
+func roundCoordinate(value float64) float64 {
+	return math.Round(value*layoutPrecision) / layoutPrecision
+}
@@ -0,0 +1,19 @@
+package topology
+
+import (
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// SetLifecycle records an agent's reported lifecycle state and reports whether the agent is in the graph
+func (g *Graph) SetLifecycle(agentID types.AgentID, lifecycle *types.AgentLifecycle) bool {
+	s := g.shardOf(agentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.agents[agentID]
+	if !exists {
+		return false
+	}
+	agent.Lifecycle = lifecycle
+	return true
+}
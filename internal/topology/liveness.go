@@ -0,0 +1,66 @@
+package topology
+
+import (
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Heartbeat records that an agent was seen alive at the given time, bringing
+// it back online if it was marked offline. It reports whether the agent is
+// known and whether it was offline.
+func (g *Graph) Heartbeat(agentID types.AgentID, at time.Time) (known, revived bool) {
+	s := g.shardOf(agentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.agents[agentID]
+	if !exists {
+		return false, false
+	}
+	if at.After(agent.LastSeenAt) {
+		agent.LastSeenAt = at
+	}
+	if agent.Status == types.AgentStatusOffline {
+		agent.Status = types.AgentStatusActive
+		return true, true
+	}
+	return true, false
+}
+
+// SweepLiveness marks the agents not seen for offlineAfter as offline and
+// returns them, with the agents not seen for evictAfter (0 = never evicted),
+// which the caller removes. Agents count as seen at floor at the latest, so
+// agents restored from a snapshot get a full timeout to send a heartbeat.
+func (g *Graph) SweepLiveness(now, floor time.Time, offlineAfter, evictAfter time.Duration) (offline, evict []types.AgentID) {
+	// Shards are swept one at a time, so heartbeats to the others go on meanwhile
+	for _, s := range g.shards {
+		s.mu.Lock()
+		for id, agent := range s.agents {
+			seen := agent.LastSeenAt
+			if seen.Before(floor) {
+				seen = floor
+			}
+			silent := now.Sub(seen)
+			if evictAfter > 0 && silent >= evictAfter {
+				evict = append(evict, id)
+			}
+			if silent >= offlineAfter && agent.Status != types.AgentStatusOffline {
+				agent.Status = types.AgentStatusOffline
+				offline = append(offline, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return offline, evict
+}
+
+// StandbyOf returns an online agent standing by for agentID, or nil if none is
+func (g *Graph) StandbyOf(agentID types.AgentID) *types.Agent {
+	for _, agent := range g.GetAllAgents() {
+		if agent.StandbyFor == agentID && agent.Status != types.AgentStatusOffline {
+			return agent
+		}
+	}
+	return nil
+}
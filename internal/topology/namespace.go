@@ -0,0 +1,49 @@
+package topology
+
+import (
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// NamespaceView returns the sub-mesh of one namespace: its agents and the
+// edges between them, with statistics computed for that part alone
+func NamespaceView(config *types.Config, snapshot *types.GraphSnapshot, namespace string) *types.GraphSnapshot {
+	return snapshotView(config, snapshot, func(agent *types.Agent) bool {
+		return agent.MeshNamespace() == namespace
+	})
+}
+
+// namespaceSizes returns the number of agents in each namespace (must be
+// called with all shards locked)
+func (g *Graph) namespaceSizes() map[string]int {
+	sizes := make(map[string]int)
+	for _, agent := range g.allAgents() {
+		sizes[agent.MeshNamespace()]++
+	}
+	return sizes
+}
+
+// namespaceStats computes the statistics of each namespace, nil while every
+// agent is in the default one (must be called with all shards locked)
+func (g *Graph) namespaceStats() map[string]types.NamespaceStats {
+	sizes := g.namespaceSizes()
+	if _, ok := sizes[types.DefaultNamespace]; ok && len(sizes) == 1 {
+		return nil
+	}
+
+	stats := make(map[string]types.NamespaceStats, len(sizes))
+	for namespace, agents := range sizes {
+		stats[namespace] = types.NamespaceStats{Agents: agents}
+	}
+	for _, edge := range g.allEdges() {
+		if source, ok := g.agent(edge.SourceID); ok {
+			namespace := stats[source.MeshNamespace()]
+			namespace.Edges++
+			stats[source.MeshNamespace()] = namespace
+		}
+	}
+	for name, namespace := range stats {
+		namespace.Density, namespace.ReductionPercent = densityOf(namespace.Edges, g.fullMeshEdges(namespace.Agents))
+		stats[name] = namespace
+	}
+	return stats
+}
@@ -0,0 +1,98 @@
+package topology
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// GetNeighborhood returns the subgraph within k hops of an agent over edges
+// at or above minWeight, followed in either direction
+func (g *Graph) GetNeighborhood(agentID types.AgentID, k int, minWeight float64) (*types.Neighborhood, error) {
+	g.rLockAll()
+	defer g.rUnlockAll()
+
+	neighborhood, err := neighborhood(g.agent, g.allEdges(), agentID, k, minWeight)
+	if err != nil {
+		return nil, err
+	}
+	// Copies, as GetSnapshot makes, so the caller reads them without the locks
+	for id, agent := range neighborhood.Agents {
+		agentCopy := *agent
+		neighborhood.Agents[id] = &agentCopy
+	}
+	for id, edge := range neighborhood.Edges {
+		neighborhood.Edges[id] = edge.Clone()
+	}
+	return neighborhood, nil
+}
+
+// SnapshotNeighborhood is GetNeighborhood over a topology snapshot
+func SnapshotNeighborhood(snapshot *types.GraphSnapshot, agentID types.AgentID, k int, minWeight float64) (*types.Neighborhood, error) {
+	agent := func(id types.AgentID) (*types.Agent, bool) {
+		agent, ok := snapshot.Agents[id]
+		return agent, ok
+	}
+	return neighborhood(agent, maps.All(snapshot.Edges), agentID, k, minWeight)
+}
+
+// neighborhood walks the edges breadth first from an agent, k hops out
+func neighborhood(agents func(types.AgentID) (*types.Agent, bool), edges iter.Seq2[types.EdgeID, *types.Edge], agentID types.AgentID, k int, minWeight float64) (*types.Neighborhood, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("k is %d; it must be 0 or more", k)
+	}
+	origin, ok := agents(agentID)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", agentID)
+	}
+
+	strong := make(map[types.EdgeID]*types.Edge)
+	adjacent := make(map[types.AgentID][]types.AgentID)
+	for id, edge := range edges {
+		if edge.GetWeight() < minWeight {
+			continue
+		}
+		strong[id] = edge
+		adjacent[edge.SourceID] = append(adjacent[edge.SourceID], edge.TargetID)
+		adjacent[edge.TargetID] = append(adjacent[edge.TargetID], edge.SourceID)
+	}
+
+	result := &types.Neighborhood{
+		AgentID:   agentID,
+		K:         k,
+		MinWeight: minWeight,
+		Hops:      map[types.AgentID]int{agentID: 0},
+		Agents:    map[types.AgentID]*types.Agent{agentID: origin},
+		Edges:     make(map[types.EdgeID]*types.Edge),
+	}
+	frontier := []types.AgentID{agentID}
+	for hop := 1; hop <= k && len(frontier) > 0; hop++ {
+		var next []types.AgentID
+		for _, id := range frontier {
+			for _, neighbor := range adjacent[id] {
+				if _, seen := result.Hops[neighbor]; seen {
+					continue
+				}
+				agent, ok := agents(neighbor)
+				if !ok {
+					continue // Edge to an agent that left
+				}
+				result.Hops[neighbor] = hop
+				result.Agents[neighbor] = agent
+				next = append(next, neighbor)
+			}
+		}
+		frontier = next
+	}
+
+	for id, edge := range strong {
+		_, source := result.Hops[edge.SourceID]
+		_, target := result.Hops[edge.TargetID]
+		if source && target {
+			result.Edges[id] = edge
+		}
+	}
+	return result, nil
+}
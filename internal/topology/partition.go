@@ -0,0 +1,66 @@
+package topology
+
+import (
+	"sort"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// components splits the agents into connected components, the islands of
+// agents that can still reach each other over edges in either direction
+// (must be called with all shards locked). Components are ordered largest
+// first, then by their lowest agent ID, and list their agents in ID order.
+func (g *Graph) components() [][]types.AgentID {
+	parent := make(map[types.AgentID]types.AgentID)
+	for id := range g.allAgents() {
+		parent[id] = id
+	}
+	var find func(id types.AgentID) types.AgentID
+	find = func(id types.AgentID) types.AgentID {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, edge := range g.allEdges() {
+		_, source := parent[edge.SourceID]
+		_, target := parent[edge.TargetID]
+		if !source || !target {
+			continue
+		}
+		a, b := find(edge.SourceID), find(edge.TargetID)
+		if a != b {
+			// Keep the lowest ID as the root, so results do not depend on map order
+			if b < a {
+				a, b = b, a
+			}
+			parent[b] = a
+		}
+	}
+
+	byRoot := make(map[types.AgentID][]types.AgentID)
+	for id := range parent {
+		root := find(id)
+		byRoot[root] = append(byRoot[root], id)
+	}
+	components := make([][]types.AgentID, 0, len(byRoot))
+	for _, members := range byRoot {
+		sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+		components = append(components, members)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if len(components[i]) != len(components[j]) {
+			return len(components[i]) > len(components[j])
+		}
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// Components returns the connected components of the graph, largest first;
+// more than one means the mesh split into isolated islands
+func (g *Graph) Components() [][]types.AgentID {
+	g.rLockAll()
+	defer g.rUnlockAll()
+	return g.components()
+}
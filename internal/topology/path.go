@@ -0,0 +1,141 @@
+package topology
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"math"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// ErrNoPath is returned when no path connects two agents and PATH_FALLBACK is none
+var ErrNoPath = errors.New("no path between agents")
+
+// hopCost is added to every edge's cost so that edges at full weight, whose
+// -ln(weight) is 0, still cost something and equally strong paths prefer fewer hops
+const hopCost = 1e-9
+
+// pathCost is the cost of crossing an edge of the given weight
+func pathCost(weight float64) float64 {
+	return hopCost - math.Log(weight)
+}
+
+// FindPath returns the strongest path from source to target over edges at or
+// above the prune threshold, relaying through intermediaries that take work.
+// Without one it falls back as configured by PathFallback.
+func (g *Graph) FindPath(sourceID, targetID types.AgentID) (*types.Path, error) {
+	threshold := g.pruneThreshold()
+	g.rLockAll()
+	defer g.rUnlockAll()
+	return findPath(g.config, threshold, g.agent, g.allEdges(), sourceID, targetID)
+}
+
+// SnapshotPath is FindPath over a topology snapshot, with the prune threshold
+// the snapshot was tuned to if any
+func SnapshotPath(cfg *types.Config, snapshot *types.GraphSnapshot, sourceID, targetID types.AgentID) (*types.Path, error) {
+	threshold := cfg.PruneThreshold
+	if snapshot.Stats.Tuning != nil {
+		threshold = snapshot.Stats.Tuning.PruneThreshold
+	}
+	agent := func(id types.AgentID) (*types.Agent, bool) {
+		agent, ok := snapshot.Agents[id]
+		return agent, ok
+	}
+	return findPath(cfg, threshold, agent, maps.All(snapshot.Edges), sourceID, targetID)
+}
+
+// findPath runs Dijkstra with -ln(weight) as the cost of an edge, so the
+// cheapest path is the one whose weights have the highest product: a strong
+// two-hop relay beats a weak direct edge, and each extra hop costs strength.
+// With a ROUTING_LATENCY_TARGET weights are discounted by the edges' latency.
+func findPath(cfg *types.Config, threshold float64, agents func(types.AgentID) (*types.Agent, bool), edges iter.Seq2[types.EdgeID, *types.Edge], sourceID, targetID types.AgentID) (*types.Path, error) {
+	for _, id := range []types.AgentID{sourceID, targetID} {
+		if _, ok := agents(id); !ok {
+			return nil, fmt.Errorf("agent %s not found", id)
+		}
+	}
+	if sourceID == targetID {
+		return &types.Path{Agents: []types.AgentID{sourceID}, Strength: 1}, nil
+	}
+
+	adjacent := make(map[types.AgentID][]*types.Edge)
+	for _, edge := range edges {
+		if weight := edge.GetWeight(); weight > 0 && weight >= threshold {
+			adjacent[edge.SourceID] = append(adjacent[edge.SourceID], edge)
+			if cfg.EdgeMode == types.EdgeModeUndirected && edge.SourceID != edge.TargetID {
+				adjacent[edge.TargetID] = append(adjacent[edge.TargetID], edge)
+			}
+		}
+	}
+
+	cost := map[types.AgentID]float64{sourceID: 0}
+	previous := make(map[types.AgentID]types.AgentID)
+	queue := &pathQueue{{agent: sourceID}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathItem)
+		if current.cost > cost[current.agent] {
+			continue // Stale entry
+		}
+		if current.agent == targetID {
+			break
+		}
+		for _, edge := range adjacent[current.agent] {
+			next := edge.TargetID
+			if next == current.agent {
+				next = edge.SourceID // Undirected edge followed backwards
+			}
+			agent, ok := agents(next)
+			if !ok || (next != targetID && !agent.Routable()) {
+				continue
+			}
+			nextCost := current.cost + pathCost(edge.GetWeight()*edge.LatencyFactor(cfg.RoutingLatencyTarget))
+			if known, seen := cost[next]; seen && known <= nextCost {
+				continue
+			}
+			cost[next] = nextCost
+			previous[next] = current.agent
+			heap.Push(queue, pathItem{agent: next, cost: nextCost})
+		}
+	}
+
+	if _, found := cost[targetID]; !found {
+		if cfg.PathFallback == types.PathFallbackNone {
+			return nil, fmt.Errorf("%w: %s to %s", ErrNoPath, sourceID, targetID)
+		}
+		return &types.Path{Agents: []types.AgentID{sourceID, targetID}, Hops: 1, Fallback: true}, nil
+	}
+
+	path := []types.AgentID{targetID}
+	for id := targetID; id != sourceID; {
+		id = previous[id]
+		path = append(path, id)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	hops := len(path) - 1
+	return &types.Path{Agents: path, Hops: hops, Strength: math.Exp(float64(hops)*hopCost - cost[targetID])}, nil
+}
+
+// pathItem is an agent reached at a cost, queued for Dijkstra
+type pathItem struct {
+	agent types.AgentID
+	cost  float64
+}
+
+// pathQueue is a min-heap of pathItems by cost
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
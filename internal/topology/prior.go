@@ -0,0 +1,178 @@
+package topology
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// edgeHistory remembers the weights of the edges removed with agents that
+// left, for EDGE_PRIOR_HISTORY
+type edgeHistory struct {
+	mu      sync.Mutex
+	weights map[types.EdgeID]types.EdgeMemory
+}
+
+func newEdgeHistory() *edgeHistory {
+	return &edgeHistory{weights: make(map[types.EdgeID]types.EdgeMemory)}
+}
+
+// remember keeps the weights of edges removed at the given time
+func (h *edgeHistory) remember(edges []*types.Edge, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, edge := range edges {
+		h.weights[edge.ID] = types.EdgeMemory{Weight: edge.GetWeight(), RemovedAt: now}
+	}
+}
+
+// recall returns and forgets the weight an edge had if it was removed within ttl
+func (h *edgeHistory) recall(edgeID types.EdgeID, now time.Time, ttl time.Duration) (float64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	memory, ok := h.weights[edgeID]
+	if !ok {
+		return 0, false
+	}
+	delete(h.weights, edgeID)
+	return memory.Weight, now.Sub(memory.RemovedAt) <= ttl
+}
+
+// expire forgets the edges removed longer than ttl ago and returns the rest
+func (h *edgeHistory) expire(now time.Time, ttl time.Duration) map[types.EdgeID]types.EdgeMemory {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	kept := make(map[types.EdgeID]types.EdgeMemory, len(h.weights))
+	for edgeID, memory := range h.weights {
+		if now.Sub(memory.RemovedAt) > ttl {
+			delete(h.weights, edgeID)
+			continue
+		}
+		kept[edgeID] = memory
+	}
+	return kept
+}
+
+// initialWeight returns the weight a new edge between two agents starts at:
+// the weight it had before one of them left, the weight learned in a previous
+// session between the agents or their roles, EDGE_PRIOR_DEPENDENCY if the
+// source depends on the target, EDGE_PRIOR_SAME_ROLE for agents of the same
+// role, or INITIAL_EDGE_WEIGHT, the first that applies
+func (g *Graph) initialWeight(source, target *types.Agent) float64 {
+	if g.config.EdgePriorHistory > 0 {
+		if weight, ok := g.history.recall(types.NewEdgeID(source.ID, target.ID), g.clock.now(), g.config.EdgePriorHistory); ok {
+			return weight
+		}
+	}
+	if source.ID == target.ID {
+		return g.config.InitialEdgeWeight
+	}
+	if weight, ok := g.learnedWeight(source, target); ok {
+		return weight
+	}
+
+	dependency := source.DependsOnAgent(target)
+	if g.config.EdgeMode == types.EdgeModeUndirected {
+		dependency = dependency || target.DependsOnAgent(source)
+	}
+	if dependency && g.config.EdgePriorDependency > 0 {
+		return g.config.EdgePriorDependency
+	}
+	if source.Role != "" && source.Role == target.Role && g.config.EdgePriorSameRole > 0 {
+		return g.config.EdgePriorSameRole
+	}
+	return g.config.InitialEdgeWeight
+}
+
+// EdgeHistory returns the weights of the edges removed with agents that left
+// within EDGE_PRIOR_HISTORY, which seed the edges when the agents return
+func (g *Graph) EdgeHistory() map[types.EdgeID]types.EdgeMemory {
+	return g.history.expire(g.clock.now(), g.config.EdgePriorHistory)
+}
+
+// RestoreEdgeHistory adds the weights of edges removed before a restart
+func (g *Graph) RestoreEdgeHistory(history map[types.EdgeID]types.EdgeMemory) {
+	g.history.mu.Lock()
+	defer g.history.mu.Unlock()
+	for edgeID, memory := range history {
+		if kept, ok := g.history.weights[edgeID]; !ok || kept.RemovedAt.Before(memory.RemovedAt) {
+			g.history.weights[edgeID] = memory
+		}
+	}
+}
+
+// learnedWeight returns the weight of the edge between two agents, or else the
+// mean weight between their roles, in the priors of a previous session
+func (g *Graph) learnedWeight(source, target *types.Agent) (float64, bool) {
+	priors := g.priors.Load()
+	if priors == nil {
+		return 0, false
+	}
+	undirected := g.config.EdgeMode == types.EdgeModeUndirected
+
+	if weight, ok := priors.Edges[types.NewEdgeID(source.ID, target.ID)]; ok {
+		return weight, true
+	}
+	if weight, ok := priors.Edges[types.NewEdgeID(target.ID, source.ID)]; ok && undirected {
+		return weight, true
+	}
+	if source.Role == "" || target.Role == "" {
+		return 0, false
+	}
+	if prior, ok := priors.Roles[types.RoleEdgeKey(source.Role, target.Role)]; ok {
+		return prior.Weight, true
+	}
+	if prior, ok := priors.Roles[types.RoleEdgeKey(target.Role, source.Role)]; ok && undirected {
+		return prior.Weight, true
+	}
+	return 0, false
+}
+
+// LearnedPriors summarizes the current edge weights, rounded to three
+// decimals, by pair of agents and by pair of roles, to seed the next session
+func (g *Graph) LearnedPriors() *types.TopologyPriors {
+	g.rLockAll()
+	defer g.rUnlockAll()
+
+	priors := &types.TopologyPriors{
+		Roles:   make(map[string]types.RolePrior),
+		Edges:   make(map[types.EdgeID]float64),
+		SavedAt: g.clock.now(),
+	}
+	totals := make(map[string]float64)
+	for id, edge := range g.allEdges() {
+		if edge.SourceID == edge.TargetID {
+			continue
+		}
+		weight := edge.GetWeight()
+		priors.Edges[id] = roundWeight(weight)
+
+		source, sourceOK := g.agent(edge.SourceID)
+		target, targetOK := g.agent(edge.TargetID)
+		if !sourceOK || !targetOK || source.Role == "" || target.Role == "" {
+			continue
+		}
+		key := types.RoleEdgeKey(source.Role, target.Role)
+		totals[key] += weight
+		prior := priors.Roles[key]
+		prior.Edges++
+		priors.Roles[key] = prior
+	}
+	for key, prior := range priors.Roles {
+		prior.Weight = roundWeight(totals[key] / float64(prior.Edges))
+		priors.Roles[key] = prior
+	}
+	return priors
+}
+
+// SetPriors makes new edges start at the weights learned in a previous
+// session, see initialWeight; nil forgets them
+func (g *Graph) SetPriors(priors *types.TopologyPriors) {
+	g.priors.Store(priors)
+}
+
+func roundWeight(weight float64) float64 {
+	return math.Round(weight*1000) / 1000
+}
@@ -0,0 +1,136 @@
+package topology
+
+import (
+	"sort"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// PruningStrategy decides which edges a decay cycle prunes. PRUNE_MIN_DEGREE
+// and MAX_PRUNE_PER_CYCLE still apply to the candidates it returns, in order.
+type PruningStrategy interface {
+	// Candidates returns the edges to prune, most prunable first. threshold
+	// is the prune threshold in effect, PRUNE_THRESHOLD or its tuned value.
+	Candidates(edges []*types.Edge, threshold float64, now time.Time) []*types.Edge
+}
+
+// NewPruningStrategy returns the built-in strategy PRUNE_STRATEGY names,
+// threshold if empty, or nil for an unknown one
+func NewPruningStrategy(config *types.Config) PruningStrategy {
+	switch config.PruneStrategy {
+	case "", types.PruneStrategyThreshold:
+		return ThresholdPruning{}
+	case types.PruneStrategyTopK:
+		return TopKPruning{K: config.PruneTopK}
+	case types.PruneStrategyAge:
+		return AgePruning{MaxAge: config.PruneMaxAge}
+	}
+	return nil
+}
+
+// ThresholdPruning is PruneStrategyThreshold: edges below the threshold, weakest first
+type ThresholdPruning struct{}
+
+func (ThresholdPruning) Candidates(edges []*types.Edge, threshold float64, now time.Time) []*types.Edge {
+	weights := edgeWeights(edges)
+	candidates := []*types.Edge{}
+	for _, edge := range edges {
+		if weights[edge] < threshold {
+			candidates = append(candidates, edge)
+		}
+	}
+	sortWeakestFirst(candidates, weights)
+	return candidates
+}
+
+// TopKPruning is PruneStrategyTopK: every edge outside the K strongest of
+// both its agents, counting edges in and out, weakest first
+type TopKPruning struct {
+	K int
+}
+
+func (p TopKPruning) Candidates(edges []*types.Edge, threshold float64, now time.Time) []*types.Edge {
+	weights := edgeWeights(edges)
+	byAgent := make(map[types.AgentID][]*types.Edge)
+	for _, edge := range edges {
+		byAgent[edge.SourceID] = append(byAgent[edge.SourceID], edge)
+		if edge.TargetID != edge.SourceID {
+			byAgent[edge.TargetID] = append(byAgent[edge.TargetID], edge)
+		}
+	}
+
+	kept := make(map[*types.Edge]bool)
+	for _, agentEdges := range byAgent {
+		sort.Slice(agentEdges, func(i, j int) bool {
+			if weights[agentEdges[i]] != weights[agentEdges[j]] {
+				return weights[agentEdges[i]] > weights[agentEdges[j]]
+			}
+			return agentEdges[i].ID < agentEdges[j].ID
+		})
+		for _, edge := range agentEdges[:min(p.K, len(agentEdges))] {
+			kept[edge] = true
+		}
+	}
+
+	candidates := []*types.Edge{}
+	for _, edge := range edges {
+		if !kept[edge] {
+			candidates = append(candidates, edge)
+		}
+	}
+	sortWeakestFirst(candidates, weights)
+	return candidates
+}
+
+// AgePruning is PruneStrategyAge: edges no message used for MaxAge, or since
+// they were created, least recently used first
+type AgePruning struct {
+	MaxAge time.Duration
+}
+
+func (p AgePruning) Candidates(edges []*types.Edge, threshold float64, now time.Time) []*types.Edge {
+	lastUsed := make(map[*types.Edge]time.Time, len(edges))
+	candidates := []*types.Edge{}
+	for _, edge := range edges {
+		lastUsed[edge] = edge.GetLastUsed()
+		if now.Sub(lastUsed[edge]) > p.MaxAge {
+			candidates = append(candidates, edge)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !lastUsed[candidates[i]].Equal(lastUsed[candidates[j]]) {
+			return lastUsed[candidates[i]].Before(lastUsed[candidates[j]])
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	return candidates
+}
+
+// edgeWeights reads each edge's weight once, so strategies sort by
+// consistent values while messages keep reinforcing edges
+func edgeWeights(edges []*types.Edge) map[*types.Edge]float64 {
+	weights := make(map[*types.Edge]float64, len(edges))
+	for _, edge := range edges {
+		weights[edge] = edge.GetWeight()
+	}
+	return weights
+}
+
+// sortWeakestFirst orders edges by weight, then ID
+func sortWeakestFirst(edges []*types.Edge, weights map[*types.Edge]float64) {
+	sort.Slice(edges, func(i, j int) bool {
+		if weights[edges[i]] != weights[edges[j]] {
+			return weights[edges[i]] < weights[edges[j]]
+		}
+		return edges[i].ID < edges[j].ID
+	})
+}
+
+// SetPruningStrategy makes decay cycles prune the edges strategy picks
+// instead of the PRUNE_STRATEGY ones
+func (g *Graph) SetPruningStrategy(strategy PruningStrategy) {
+	g.lockAll()
+	defer g.unlockAll()
+	g.pruning = strategy
+}
@@ -0,0 +1,50 @@
+package topology
+
+import (
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// RegionView returns the part of a snapshot within one region: its agents and
+// the edges between them, with statistics computed for that part alone
+func RegionView(config *types.Config, snapshot *types.GraphSnapshot, region string) *types.GraphSnapshot {
+	return snapshotView(config, snapshot, func(agent *types.Agent) bool {
+		return agent.Region == region
+	})
+}
+
+// snapshotView returns the part of a snapshot made of the agents keep accepts
+// and the edges between them, with statistics computed for that part alone
+func snapshotView(config *types.Config, snapshot *types.GraphSnapshot, keep func(*types.Agent) bool) *types.GraphSnapshot {
+	view := &types.GraphSnapshot{
+		Agents: make(map[types.AgentID]*types.Agent),
+		Edges:  make(map[types.EdgeID]*types.Edge),
+	}
+	for id, agent := range snapshot.Agents {
+		if keep(agent) {
+			view.Agents[id] = agent
+		}
+	}
+	for id, edge := range snapshot.Edges {
+		_, source := view.Agents[edge.SourceID]
+		_, target := view.Agents[edge.TargetID]
+		if source && target {
+			view.Edges[id] = edge
+		}
+	}
+
+	graph := NewGraph(config)
+	graph.Restore(view)
+	view = graph.GetSnapshot()
+	view.Timestamp = snapshot.Timestamp
+
+	// Agents stay where the full topology's layout put them
+	if snapshot.Layout != nil {
+		view.Layout = make(map[types.AgentID]types.LayoutPosition, len(view.Agents))
+		for id := range view.Agents {
+			if position, ok := snapshot.Layout[id]; ok {
+				view.Layout[id] = position
+			}
+		}
+	}
+	return view
+}
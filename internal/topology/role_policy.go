@@ -0,0 +1,44 @@
+package topology
+
+import (
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// rolePolicy returns the ROLE_POLICIES entry of the roles an edge connects
+// (must be called with the shards of its agents locked)
+func (g *Graph) rolePolicy(edge *types.Edge) (types.RolePolicy, bool) {
+	if len(g.config.RolePolicies) == 0 {
+		return types.RolePolicy{}, false
+	}
+	source, okSource := g.agent(edge.SourceID)
+	target, okTarget := g.agent(edge.TargetID)
+	if !okSource || !okTarget {
+		return types.RolePolicy{}, false
+	}
+	return g.config.RolePolicies.For(source.Role, target.Role)
+}
+
+// reinforcementAmount returns how much a message strengthens an edge, its
+// role pair's or the configured amount (must be called with the shards of its
+// agents locked)
+func (g *Graph) reinforcementAmount(edge *types.Edge) float64 {
+	if policy, ok := g.rolePolicy(edge); ok && policy.ReinforcementAmount > 0 {
+		return policy.ReinforcementAmount
+	}
+	return g.config.ReinforcementAmount
+}
+
+// edgeDecayRate returns the decay rate of an edge given the graph's rate in
+// effect: a role pair's own rate replaces DECAY_RATE, scaled the same way
+// tuning and adaptive decay scale it (must be called with the shards of its
+// agents locked)
+func (g *Graph) edgeDecayRate(edge *types.Edge, rate float64) float64 {
+	policy, ok := g.rolePolicy(edge)
+	if !ok || policy.DecayRate <= 0 {
+		return rate
+	}
+	if g.config.DecayRate <= 0 {
+		return policy.DecayRate
+	}
+	return policy.DecayRate * rate / g.config.DecayRate
+}
@@ -0,0 +1,31 @@
+package topology
+
+import (
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Sandboxed reports whether an agent in the graph is still on probation
+func (g *Graph) Sandboxed(agentID types.AgentID, now time.Time) bool {
+	s := g.shardOf(agentID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agent, exists := s.agents[agentID]
+	return exists && agent.Sandboxed(now)
+}
+
+// ReleaseSandbox ends an agent's probation early and reports whether it was sandboxed
+func (g *Graph) ReleaseSandbox(agentID types.AgentID) bool {
+	s := g.shardOf(agentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.agents[agentID]
+	if !exists || agent.Sandbox == nil {
+		return false
+	}
+	agent.Sandbox = nil
+	return true
+}
@@ -0,0 +1,168 @@
+package topology
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// graphShards is how many shards a graph's agents and edges are spread over.
+// Reinforcing an edge locks the shards of its two agents only, so messages
+// between different agents rarely wait on each other.
+const graphShards = 64
+
+// shard holds the agents whose ID hashes to it and the edges from them.
+// Shards are always locked in index order, so holders of several never deadlock.
+type shard struct {
+	index  int
+	mu     sync.RWMutex
+	agents map[types.AgentID]*types.Agent
+	edges  map[types.EdgeID]*types.Edge
+}
+
+// newShards creates the empty shards of a graph
+func newShards() [graphShards]*shard {
+	var shards [graphShards]*shard
+	for i := range shards {
+		shards[i] = &shard{
+			index:  i,
+			agents: make(map[types.AgentID]*types.Agent),
+			edges:  make(map[types.EdgeID]*types.Edge),
+		}
+	}
+	return shards
+}
+
+// shardOf returns the shard an agent and the edges from it live in, by the
+// FNV-1a hash of its ID
+func (g *Graph) shardOf(agentID types.AgentID) *shard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(agentID); i++ {
+		hash ^= uint32(agentID[i])
+		hash *= 16777619
+	}
+	return g.shards[hash%graphShards]
+}
+
+// edgeLock holds the shards of an edge's source and target agents: the
+// source's for the edge itself, the target's to read the target agent
+type edgeLock struct {
+	source, target *shard
+	write          bool // Source shard locked for writing
+}
+
+// lockEdge locks the shards of an edge between two agents, the source's for
+// writing if write is set and the target's for reading
+func (g *Graph) lockEdge(sourceID, targetID types.AgentID, write bool) edgeLock {
+	l := edgeLock{source: g.shardOf(sourceID), target: g.shardOf(targetID), write: write}
+	switch {
+	case l.source == l.target:
+		l.lockSource()
+	case l.source.index < l.target.index:
+		l.lockSource()
+		l.target.mu.RLock()
+	default:
+		l.target.mu.RLock()
+		l.lockSource()
+	}
+	return l
+}
+
+func (l edgeLock) lockSource() {
+	if l.write {
+		l.source.mu.Lock()
+	} else {
+		l.source.mu.RLock()
+	}
+}
+
+// unlock releases the shards taken by lockEdge
+func (l edgeLock) unlock() {
+	if l.source != l.target {
+		l.target.mu.RUnlock()
+	}
+	if l.write {
+		l.source.mu.Unlock()
+	} else {
+		l.source.mu.RUnlock()
+	}
+}
+
+// lockAll locks every shard for writing, for changes across the graph
+func (g *Graph) lockAll() {
+	for _, s := range g.shards {
+		s.mu.Lock()
+	}
+}
+
+func (g *Graph) unlockAll() {
+	for i := len(g.shards) - 1; i >= 0; i-- {
+		g.shards[i].mu.Unlock()
+	}
+}
+
+// rLockAll locks every shard for reading, for a consistent view of the graph
+func (g *Graph) rLockAll() {
+	for _, s := range g.shards {
+		s.mu.RLock()
+	}
+}
+
+func (g *Graph) rUnlockAll() {
+	for i := len(g.shards) - 1; i >= 0; i-- {
+		g.shards[i].mu.RUnlock()
+	}
+}
+
+// agent looks up an agent (must be called with its shard locked)
+func (g *Graph) agent(agentID types.AgentID) (*types.Agent, bool) {
+	agent, ok := g.shardOf(agentID).agents[agentID]
+	return agent, ok
+}
+
+// allAgents ranges over every agent (must be called with all shards locked)
+func (g *Graph) allAgents() iter.Seq2[types.AgentID, *types.Agent] {
+	return func(yield func(types.AgentID, *types.Agent) bool) {
+		for _, s := range g.shards {
+			for id, agent := range s.agents {
+				if !yield(id, agent) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// allEdges ranges over every edge (must be called with all shards locked)
+func (g *Graph) allEdges() iter.Seq2[types.EdgeID, *types.Edge] {
+	return func(yield func(types.EdgeID, *types.Edge) bool) {
+		for _, s := range g.shards {
+			for id, edge := range s.edges {
+				if !yield(id, edge) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// counts returns how many agents and edges the graph has (must be called with
+// all shards locked)
+func (g *Graph) counts() (agents, edges int) {
+	for _, s := range g.shards {
+		agents += len(s.agents)
+		edges += len(s.edges)
+	}
+	return agents, edges
+}
+
+// putEdge adds an edge to its source's shard (must be called with it locked)
+func (g *Graph) putEdge(edge *types.Edge) {
+	g.shardOf(edge.SourceID).edges[edge.ID] = edge
+}
+
+// deleteEdge removes an edge from its source's shard (must be called with it locked)
+func (g *Graph) deleteEdge(edge *types.Edge) {
+	delete(g.shardOf(edge.SourceID).edges, edge.ID)
+}
@@ -0,0 +1,161 @@
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/replay"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Simulator replays a recorded message trace against a topology with other
+// parameters, offline. Time follows the trace and decay cycles run every
+// DECAY_INTERVAL of trace time, as in the replay start mode, so a trace
+// hours long simulates in seconds and always yields the same topology.
+type Simulator struct {
+	slimeMold   *SlimeMoldTopology
+	interval    time.Duration
+	sampleEvery time.Duration // 0 samples no timeline
+
+	now        time.Time
+	nextDecay  time.Time
+	nextSample time.Time
+	cycle      uint64 // Last algorithm cycle counted
+	report     types.SimulationReport
+}
+
+// NewSimulator creates a simulator of a topology configured like config with
+// params applied, sampling the stats every sampleEvery of trace time
+func NewSimulator(config *types.Config, params types.TopologyParams, sampleEvery time.Duration) *Simulator {
+	config = params.Apply(config)
+	config.AlgorithmHistory = 1 // Only the latest cycle, to count its prunes
+
+	s := &Simulator{
+		slimeMold:   NewSlimeMoldTopology(config, zap.NewNop()),
+		interval:    config.DecayInterval,
+		sampleEvery: sampleEvery,
+		report:      types.SimulationReport{Params: types.TopologyParamsOf(config)},
+	}
+	s.slimeMold.SetClock(func() time.Time { return s.now })
+	return s
+}
+
+// advance moves trace time to a record's, running the decay cycles due before it
+func (s *Simulator) advance(at time.Time) {
+	if s.report.From.IsZero() {
+		s.now, s.report.From = at, at
+		s.nextDecay, s.nextSample = at.Add(s.interval), at
+		s.sample()
+	}
+	for s.interval > 0 && !at.Before(s.nextDecay) {
+		s.now = s.nextDecay
+		s.slimeMold.DecayCycle()
+		s.nextDecay = s.nextDecay.Add(s.interval)
+		s.report.Cycles++
+		for _, cycle := range s.slimeMold.AlgorithmCycles(s.cycle) {
+			s.report.Pruned += cycle.Pruned
+			s.cycle = cycle.Cycle
+		}
+		s.sample()
+	}
+	// Records stamped by a clock behind the others do not turn time back
+	if at.After(s.now) {
+		s.now = at
+	}
+	s.report.Until = s.now
+}
+
+// sample adds the current stats to the timeline when a sample is due
+func (s *Simulator) sample() {
+	if s.sampleEvery <= 0 || s.now.Before(s.nextSample) {
+		return
+	}
+	point := s.slimeMold.GetSnapshot().HistoryPoint()
+	point.Timestamp = s.now
+	s.report.Timeline = append(s.report.Timeline, point)
+	s.nextSample = s.now.Add(s.sampleEvery)
+}
+
+// Join adds an agent at the given trace time
+func (s *Simulator) Join(agent *types.Agent, at time.Time) error {
+	s.advance(at)
+	agent.PromoteMetadata()
+	if err := s.slimeMold.AddAgent(agent); err != nil {
+		return err
+	}
+	s.report.Agents++
+	return nil
+}
+
+// Leave removes an agent at the given trace time
+func (s *Simulator) Leave(agentID types.AgentID, at time.Time) error {
+	s.advance(at)
+	return s.slimeMold.RemoveAgent(agentID)
+}
+
+// Message reinforces the edge a message travels at the given trace time.
+// Agents first seen in a message, e.g. ones that joined before the trace
+// was recorded, join without a role.
+func (s *Simulator) Message(msg *types.Message, at time.Time) error {
+	if msg.FromAgentID == "" || msg.ToAgentID == "" || msg.Replayed() {
+		return nil
+	}
+	s.advance(at)
+	for _, id := range []types.AgentID{msg.FromAgentID, msg.ToAgentID} {
+		if _, err := s.slimeMold.GetGraph().GetAgent(id); err != nil {
+			s.Join(&types.Agent{ID: id, Status: types.AgentStatusActive, CreatedAt: at}, at)
+		}
+	}
+	if err := s.slimeMold.ReinforceEdge(msg.FromAgentID, msg.ToAgentID, msg.Type); err != nil {
+		return err
+	}
+	s.report.Messages++
+	return nil
+}
+
+// Replay feeds the agents joining and leaving and the messages of a recording
+// to the simulator; records of other topics are skipped
+func (s *Simulator) Replay(r *replay.Reader) error {
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		at := r.Header.StartedAt.Add(entry.Offset())
+
+		switch entry.Topic {
+		case "topology":
+			var event types.TopologyEvent
+			if err := json.Unmarshal(entry.Value, &event); err != nil {
+				return fmt.Errorf("failed to decode topology event: %w", err)
+			}
+			switch {
+			case event.Type == types.TopologyEventAgentJoined && event.Agent != nil:
+				s.Join(event.Agent, at)
+			case event.Type == types.TopologyEventAgentLeft:
+				s.Leave(event.AgentID, at)
+			}
+		case "messages":
+			var msg types.Message
+			if err := json.Unmarshal(entry.Value, &msg); err != nil {
+				return fmt.Errorf("failed to decode message: %w", err)
+			}
+			s.Message(&msg, at)
+		}
+	}
+}
+
+// Report returns the simulation's outcome up to the last record
+func (s *Simulator) Report() types.SimulationReport {
+	report := s.report
+	report.Stats = s.slimeMold.GetSnapshot().HistoryPoint().Stats
+	report.Timeline = append([]types.TopologyHistoryPoint(nil), s.report.Timeline...)
+	return report
+}
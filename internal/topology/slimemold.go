@@ -2,7 +2,9 @@ package topology
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
@@ -15,6 +17,11 @@ type SlimeMoldTopology struct {
 	config    *types.Config
 	logger    *zap.Logger
 	eventChan chan types.TopologyEvent
+	decay     *decayController // Nil without adaptive decay
+	paused    atomic.Bool      // Decay paused, see PauseDecay
+	islands   int              // Connected components after the last decay cycle
+	algorithm *algorithmRecorder
+	health    *healthMonitor
 
 	stopCh chan struct{}
 	wg     sync.WaitGroup
@@ -22,13 +29,18 @@ type SlimeMoldTopology struct {
 
 // NewSlimeMoldTopology creates a new slime mold topology manager
 func NewSlimeMoldTopology(config *types.Config, logger *zap.Logger) *SlimeMoldTopology {
-	return &SlimeMoldTopology{
+	sm := &SlimeMoldTopology{
 		graph:     NewGraph(config),
 		config:    config,
 		logger:    logger,
 		eventChan: make(chan types.TopologyEvent, 500), // Increased from 100 to 500 to handle mass pruning
 		stopCh:    make(chan struct{}),
+		decay:     newDecayController(config),
+		algorithm: newAlgorithmRecorder(config.AlgorithmHistory),
+		health:    newHealthMonitor(config),
 	}
+	sm.graph.Guardrails().OnTransition(sm.onFreezeTransition)
+	return sm
 }
 
 // Start begins the topology evolution process
@@ -37,6 +49,7 @@ func (sm *SlimeMoldTopology) Start(ctx context.Context) error {
 		zap.Float64("decay_rate", sm.config.DecayRate),
 		zap.Duration("decay_interval", sm.config.DecayInterval),
 		zap.Float64("prune_threshold", sm.config.PruneThreshold),
+		zap.Float64("decay_reference_throughput", sm.config.DecayReferenceThroughput),
 	)
 
 	// Start decay ticker
@@ -69,25 +82,48 @@ func (sm *SlimeMoldTopology) runDecayLoop(ctx context.Context) {
 		case <-sm.stopCh:
 			return
 		case <-ticker.C:
-			sm.applyDecayAndPrune()
+			sm.DecayCycle()
 		}
 	}
 }
 
-// applyDecayAndPrune applies decay to all edges and prunes weak ones
-func (sm *SlimeMoldTopology) applyDecayAndPrune() {
+// DecayCycle applies decay to all edges and prunes weak ones; the decay loop
+// runs one every DecayInterval
+func (sm *SlimeMoldTopology) DecayCycle() {
+	// Lift an automatic freeze once churn has settled
+	sm.graph.Guardrails().Recover()
+
+	if sm.paused.Load() {
+		sm.recordCycle(types.AlgorithmCycle{Paused: true})
+		return
+	}
+
+	// Scale decay by the traffic since the last cycle
+	if sm.decay != nil {
+		sm.graph.SetAdaptiveDecay(sm.decay.update(sm.graph.clock.now()))
+	}
+	cycle := types.AlgorithmCycle{Frozen: sm.graph.Guardrails().Frozen(), DecayRate: sm.graph.decayRate()}
+
 	// Apply decay to all edges
-	sm.graph.DecayAllEdges()
+	cycle.DecayApplied = sm.graph.decayAllEdges()
 
 	// Prune weak edges
-	prunedEdges := sm.graph.PruneWeakEdges()
+	prunedEdges, outcome := sm.graph.pruneWeakEdges()
+	cycle.PruneThreshold = outcome.threshold
+	cycle.PruneCandidates = outcome.candidates
+	cycle.PruneProtected = outcome.protected
+	cycle.PruneDeferred = outcome.deferred
+	cycle.Pruned = len(prunedEdges)
+
+	// Steer decay and pruning toward the reduction or density target
+	sm.graph.Tune()
 
 	// Emit events for pruned edges
 	for _, edgeID := range prunedEdges {
 		sm.emitEvent(types.TopologyEvent{
 			Type:      types.TopologyEventEdgeRemoved,
 			EdgeID:    edgeID,
-			Timestamp: time.Now(),
+			Timestamp: sm.graph.clock.now(),
 		})
 	}
 
@@ -97,6 +133,95 @@ func (sm *SlimeMoldTopology) applyDecayAndPrune() {
 			zap.Int("remaining_edges", sm.graph.GetEdgeCount()),
 		)
 	}
+
+	sm.detectPartition()
+	sm.checkHealth(prunedEdges)
+	sm.recordCycle(cycle)
+}
+
+// checkHealth reports pathological states the cycle left the topology in,
+// and freezes it once OSCILLATION_FREEZE_EDGES edges oscillate at once
+func (sm *SlimeMoldTopology) checkHealth(pruned []types.EdgeID) {
+	now := sm.graph.clock.now()
+	oscillating := 0
+	for _, issue := range sm.health.update(sm.graph, pruned, now) {
+		sm.logger.Warn("Topology health issue detected",
+			zap.String("kind", string(issue.Kind)),
+			zap.String("subject", issue.Subject),
+			zap.String("detail", issue.Detail),
+			zap.String("suggestion", issue.Suggestion),
+		)
+		sm.emitEvent(types.TopologyEvent{
+			Type:      types.TopologyEventHealthIssue,
+			Issue:     &issue,
+			Timestamp: now,
+		})
+	}
+	for _, issue := range sm.health.issues() {
+		if issue.Kind == types.TopologyIssueOscillatingEdge {
+			oscillating++
+		}
+	}
+
+	if limit := sm.config.OscillationFreezeEdges; limit > 0 && oscillating >= limit && !sm.graph.Guardrails().Frozen() {
+		sm.graph.Guardrails().Freeze(fmt.Sprintf("%d edges oscillating between pruned and recreated", oscillating), false)
+	}
+}
+
+// TopologyHealth returns the topology's current health issues
+func (sm *SlimeMoldTopology) TopologyHealth() types.TopologyHealth {
+	return types.TopologyHealth{
+		Issues:    sm.health.issues(),
+		UpdatedAt: sm.graph.clock.now(),
+	}
+}
+
+// recordCycle completes a decay cycle's record with the state of the edges
+// it left
+func (sm *SlimeMoldTopology) recordCycle(cycle types.AlgorithmCycle) {
+	cycle.Timestamp = sm.graph.clock.now()
+	cycle.Edges = sm.graph.GetEdgeCount()
+	cycle.WeightEntropy = sm.graph.weightEntropy()
+	sm.algorithm.record(cycle)
+}
+
+// AlgorithmCycles returns the recorded decay cycles after the given cycle
+// number, oldest first, 0 for all ALGORITHM_HISTORY kept
+func (sm *SlimeMoldTopology) AlgorithmCycles(after uint64) []types.AlgorithmCycle {
+	return sm.algorithm.since(after)
+}
+
+// detectPartition reports when the mesh split into more isolated islands
+// than after the previous decay cycle, e.g. because pruning removed the last
+// edges between two groups of agents
+func (sm *SlimeMoldTopology) detectPartition() {
+	components := sm.graph.Components()
+	previous := sm.islands
+	sm.islands = len(components)
+	if len(components) < 2 || len(components) <= previous {
+		return
+	}
+
+	sizes := make([]int, len(components))
+	for i, component := range components {
+		sizes[i] = len(component)
+	}
+	sm.logger.Warn("Mesh partitioned into isolated islands",
+		zap.Int("components", len(components)),
+		zap.Int("previous", previous),
+		zap.Ints("sizes", sizes),
+	)
+	sm.emitEvent(types.TopologyEvent{
+		Type:       types.TopologyEventPartitionDetected,
+		Components: components,
+		Timestamp:  sm.graph.clock.now(),
+	})
+}
+
+// PauseDecay pauses decay and pruning, e.g. during an incident, or resumes
+// them, reporting whether that changed anything
+func (sm *SlimeMoldTopology) PauseDecay(paused bool) bool {
+	return sm.paused.Swap(paused) != paused
 }
 
 // AddAgent adds a new agent to the topology
@@ -108,7 +233,7 @@ func (sm *SlimeMoldTopology) AddAgent(agent *types.Agent) error {
 	sm.emitEvent(types.TopologyEvent{
 		Type:      types.TopologyEventAgentJoined,
 		AgentID:   agent.ID,
-		Timestamp: time.Now(),
+		Timestamp: sm.graph.clock.now(),
 	})
 
 	sm.logger.Info("Agent joined mesh",
@@ -129,7 +254,7 @@ func (sm *SlimeMoldTopology) RemoveAgent(agentID types.AgentID) error {
 	sm.emitEvent(types.TopologyEvent{
 		Type:      types.TopologyEventAgentLeft,
 		AgentID:   agentID,
-		Timestamp: time.Now(),
+		Timestamp: sm.graph.clock.now(),
 	})
 
 	sm.logger.Info("Agent left mesh",
@@ -139,13 +264,15 @@ func (sm *SlimeMoldTopology) RemoveAgent(agentID types.AgentID) error {
 	return nil
 }
 
-// ReinforceEdge strengthens an edge when a message is sent through it
-func (sm *SlimeMoldTopology) ReinforceEdge(sourceID, targetID types.AgentID) error {
-	edgeID := types.NewEdgeID(sourceID, targetID)
+// ReinforceEdge strengthens an edge when a message of the given type is sent through it
+func (sm *SlimeMoldTopology) ReinforceEdge(sourceID, targetID types.AgentID, msgType types.MessageType) error {
+	edgeID := sm.graph.edgeID(sourceID, targetID)
 
-	if err := sm.graph.ReinforceEdge(edgeID); err != nil {
+	if err := sm.graph.ReinforceEdge(edgeID, msgType); err != nil {
 		return err
 	}
+	sm.decay.observe()
+	sm.algorithm.reinforced(edgeID)
 
 	// Get updated edge
 	edge, _ := sm.graph.GetEdge(edgeID)
@@ -154,18 +281,60 @@ func (sm *SlimeMoldTopology) ReinforceEdge(sourceID, targetID types.AgentID) err
 			Type:      types.TopologyEventEdgeStrength,
 			EdgeID:    edgeID,
 			Edge:      edge,
-			Timestamp: time.Now(),
+			Timestamp: sm.graph.clock.now(),
 		})
 	}
 
 	return nil
 }
 
+// ObserveLatency records how long a message from source to target took from
+// being published to being consumed, for the edge's rolling percentiles
+func (sm *SlimeMoldTopology) ObserveLatency(sourceID, targetID types.AgentID, latency time.Duration) error {
+	return sm.graph.ObserveLatency(sm.graph.edgeID(sourceID, targetID), latency)
+}
+
 // GetSnapshot returns the current graph snapshot
 func (sm *SlimeMoldTopology) GetSnapshot() *types.GraphSnapshot {
 	return sm.graph.GetSnapshot()
 }
 
+// Restore replaces the current graph with a snapshot, e.g. one handed over by a previous instance
+func (sm *SlimeMoldTopology) Restore(snapshot *types.GraphSnapshot) {
+	sm.graph.Restore(snapshot)
+	sm.logger.Info("Restored topology",
+		zap.Int("agents", len(snapshot.Agents)),
+		zap.Int("edges", len(snapshot.Edges)),
+	)
+}
+
+// Guardrails returns the topology's rate-of-change limits and circuit breaker
+func (sm *SlimeMoldTopology) Guardrails() *Guardrails {
+	return sm.graph.Guardrails()
+}
+
+// onFreezeTransition logs and broadcasts freeze state changes
+func (sm *SlimeMoldTopology) onFreezeTransition(frozen bool, reason string) {
+	eventType := types.TopologyEventUnfrozen
+	if frozen {
+		eventType = types.TopologyEventFrozen
+		status := sm.graph.Guardrails().Status()
+		sm.logger.Warn("Topology frozen, decay, pruning and new edges suspended",
+			zap.String("reason", reason),
+			zap.Bool("manual", status.Manual),
+			zap.Int("churn", status.Churn),
+			zap.Int("churn_limit", status.ChurnLimit),
+		)
+	} else {
+		sm.logger.Info("Topology unfrozen")
+	}
+
+	sm.emitEvent(types.TopologyEvent{
+		Type:      eventType,
+		Timestamp: sm.graph.clock.now(),
+	})
+}
+
 // GetGraph returns the underlying graph
 func (sm *SlimeMoldTopology) GetGraph() *Graph {
 	return sm.graph
@@ -187,16 +356,14 @@ func (sm *SlimeMoldTopology) emitEvent(event types.TopologyEvent) {
 	}
 }
 
-// GetOptimalPath returns the strongest path between two agents (for routing)
+// GetOptimalPath returns the strongest path between two agents (for routing),
+// which may relay through intermediaries when the direct edge is weak or pruned
 func (sm *SlimeMoldTopology) GetOptimalPath(sourceID, targetID types.AgentID) ([]types.AgentID, error) {
-	// Simple implementation: direct edge if strong enough, otherwise return empty (no path)
-	edge, err := sm.graph.GetEdgeBetween(sourceID, targetID)
-	if err == nil && edge.GetWeight() >= sm.config.PruneThreshold {
-		return []types.AgentID{sourceID, targetID}, nil
+	path, err := sm.graph.FindPath(sourceID, targetID)
+	if err != nil {
+		return nil, err
 	}
-
-	// For now, return direct path only. In future, implement Dijkstra for multi-hop paths
-	return []types.AgentID{sourceID, targetID}, nil
+	return path.Agents, nil
 }
 
 // PrintStats logs current topology statistics
@@ -0,0 +1,145 @@
+package topology
+
+import (
+	"math"
+	"sync"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// tuningTolerance is how far from the target (as a share, e.g. 2 points of
+	// reduction) the tuner holds the parameters
+	tuningTolerance = 0.02
+
+	// tuningMaxStep bounds the relative change of the parameters per cycle
+	tuningMaxStep = 0.5
+
+	// Lower bounds of the tuned parameters, so edges still decay and prune
+	tuningMinDecayRate      = 0.005
+	tuningMinPruneThreshold = 0.05
+)
+
+// tuner adjusts the decay rate and prune threshold every decay cycle so the
+// graph's reduction from full mesh, or its density, converges to the target:
+// both scale up while too many edges survive and down while too few do
+type tuner struct {
+	targetReduction float64
+	targetDensity   float64
+	maxDecayRate    float64
+	maxPrune        float64
+
+	mu             sync.Mutex
+	decayRate      float64
+	pruneThreshold float64
+	current        float64
+	converged      bool
+}
+
+// newTuner returns the tuner for a config's target, or nil without one
+func newTuner(cfg *types.Config) *tuner {
+	if cfg.TargetReduction <= 0 && cfg.TargetDensity <= 0 {
+		return nil
+	}
+	t := &tuner{
+		targetReduction: cfg.TargetReduction,
+		targetDensity:   cfg.TargetDensity,
+		maxDecayRate:    math.Max(cfg.TuningMaxDecayRate, tuningMinDecayRate),
+		maxPrune:        math.Max(cfg.TuningMaxPruneThreshold, tuningMinPruneThreshold),
+	}
+	t.decayRate = t.clamp(cfg.DecayRate, tuningMinDecayRate, t.maxDecayRate)
+	t.pruneThreshold = t.clamp(cfg.PruneThreshold, tuningMinPruneThreshold, t.maxPrune)
+	return t
+}
+
+func (t *tuner) clamp(value, low, high float64) float64 {
+	return math.Min(math.Max(value, low), high)
+}
+
+// params returns the decay rate and prune threshold currently in effect
+func (t *tuner) params() (decayRate, pruneThreshold float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.decayRate, t.pruneThreshold
+}
+
+// step moves the parameters toward the target given the graph's density
+func (t *tuner) step(density, reduction float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Positive while too many edges survive
+	var excess float64
+	if t.targetReduction > 0 {
+		t.current = reduction
+		excess = (t.targetReduction - reduction) / 100
+	} else {
+		t.current = density
+		excess = density - t.targetDensity
+	}
+
+	t.converged = math.Abs(excess) <= tuningTolerance
+	if t.converged {
+		return
+	}
+	factor := 1 + t.clamp(excess, -tuningMaxStep, tuningMaxStep)
+	t.decayRate = t.clamp(t.decayRate*factor, tuningMinDecayRate, t.maxDecayRate)
+	t.pruneThreshold = t.clamp(t.pruneThreshold*factor, tuningMinPruneThreshold, t.maxPrune)
+}
+
+// status reports the target, where the graph is and the parameters in effect
+func (t *tuner) status() *types.TuningStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &types.TuningStatus{
+		TargetReduction: t.targetReduction,
+		TargetDensity:   t.targetDensity,
+		Current:         t.current,
+		DecayRate:       t.decayRate,
+		PruneThreshold:  t.pruneThreshold,
+		Converged:       t.converged,
+	}
+}
+
+// decayRate returns the decay rate in effect, tuned or configured and scaled
+// by traffic under adaptive decay
+func (g *Graph) decayRate() float64 {
+	rate := g.config.DecayRate
+	if g.tuner != nil {
+		rate, _ = g.tuner.params()
+	}
+	if adaptive := g.adaptive.Load(); adaptive != nil {
+		rate = math.Min(rate*adaptive.Factor, adaptiveDecayMaxRate)
+	}
+	return rate
+}
+
+// pruneThreshold returns the prune threshold in effect, tuned or configured
+func (g *Graph) pruneThreshold() float64 {
+	if g.tuner == nil {
+		return g.config.PruneThreshold
+	}
+	_, threshold := g.tuner.params()
+	return threshold
+}
+
+// Tune moves the decay rate and prune threshold toward the configured
+// reduction or density target; a no-op without one or while frozen
+func (g *Graph) Tune() {
+	if g.tuner == nil || g.guardrails.Frozen() {
+		return
+	}
+	g.rLockAll()
+	_, edges := g.counts()
+	density, reduction := g.meshDensity(edges)
+	g.rUnlockAll()
+	g.tuner.step(density, reduction)
+}
+
+// Tuning reports the tuner's status, nil without a target
+func (g *Graph) Tuning() *types.TuningStatus {
+	if g.tuner == nil {
+		return nil
+	}
+	return g.tuner.status()
+}
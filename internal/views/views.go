@@ -0,0 +1,105 @@
+// Package views computes materialized views over insights.
+//
+// A view is an AMQL insight query whose matches are grouped by a field and
+// aggregated. The knowledge manager recomputes the views when insights change
+// and stores the results in Redis, so reading a view is a single lookup
+// instead of a filter over every insight per request.
+package views
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/amql"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Validate checks a view definition, including its query, and fills in defaults
+func Validate(view *types.InsightView) error {
+	if err := view.Validate(); err != nil {
+		return err
+	}
+	_, err := parse(view)
+	return err
+}
+
+// Compute evaluates a view over insight records (see amql.Records)
+func Compute(view *types.InsightView, records []map[string]any, now time.Time) (*types.ViewResult, error) {
+	query, err := parse(view)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ViewResult{View: view.Name, Rows: []types.ViewRow{}, RefreshedAt: now}
+	groups := make(map[string]*types.ViewRow)
+	for _, record := range records {
+		if !query.Match(record) {
+			continue
+		}
+		result.Matched++
+
+		group := ""
+		if view.GroupBy != "" {
+			if value := amql.Field(record, view.GroupBy); value != nil {
+				group = fmt.Sprint(value)
+			}
+		}
+		row, ok := groups[group]
+		if !ok {
+			row = &types.ViewRow{Group: group}
+			groups[group] = row
+		}
+
+		if view.Aggregate == types.ViewAggregateCount {
+			row.Count++
+			row.Value++
+			continue
+		}
+		value, ok := amql.Field(record, view.Field).(float64)
+		if !ok {
+			continue // Insights without the field only count towards Matched
+		}
+		switch {
+		case row.Count == 0:
+			row.Value = value
+		case view.Aggregate == types.ViewAggregateMin:
+			row.Value = math.Min(row.Value, value)
+		case view.Aggregate == types.ViewAggregateMax:
+			row.Value = math.Max(row.Value, value)
+		default: // Sum, averaged below
+			row.Value += value
+		}
+		row.Count++
+	}
+
+	for _, row := range groups {
+		if row.Count == 0 {
+			continue // No insight of the group has the field
+		}
+		if view.Aggregate == types.ViewAggregateAvg {
+			row.Value /= float64(row.Count)
+		}
+		result.Rows = append(result.Rows, *row)
+	}
+	sort.Slice(result.Rows, func(i, j int) bool {
+		if result.Rows[i].Value != result.Rows[j].Value {
+			return result.Rows[i].Value > result.Rows[j].Value
+		}
+		return result.Rows[i].Group < result.Rows[j].Group
+	})
+	return result, nil
+}
+
+// parse parses the view's query, which must select insights
+func parse(view *types.InsightView) (*amql.Query, error) {
+	query, err := amql.Parse(view.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid view query: %w", err)
+	}
+	if query.Source != amql.SourceInsights {
+		return nil, fmt.Errorf("view query must select insights, not %s", query.Source)
+	}
+	return query, nil
+}
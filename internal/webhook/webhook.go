@@ -0,0 +1,182 @@
+// Package webhook delivers mesh events to subscribed HTTP endpoints.
+//
+// Subscriptions are stored in Redis and managed through /api/webhooks. Each
+// delivery is a JSON types.WebhookDelivery POSTed to the endpoint; when the
+// subscription has a secret, the body is signed with HMAC-SHA256 in the
+// X-AgentMesh-Signature header ("sha256=<hex>"). Failed deliveries are
+// retried with backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// maxAttempts bounds delivery attempts per webhook
+	maxAttempts = 3
+
+	// deliveryTimeout bounds a single delivery attempt
+	deliveryTimeout = 10 * time.Second
+)
+
+// Source lists webhook subscriptions (implemented by state.RedisStore)
+type Source interface {
+	ListWebhooks(ctx context.Context) ([]*types.Webhook, error)
+}
+
+// Dispatcher delivers events to the webhooks subscribed to them
+type Dispatcher struct {
+	source  Source
+	client  *http.Client
+	backoff time.Duration
+	logger  *zap.Logger
+
+	wg sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher reading subscriptions from source
+func NewDispatcher(source Source, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		source:  source,
+		client:  &http.Client{Timeout: deliveryTimeout},
+		backoff: time.Second,
+		logger:  logger.With(zap.String("component", "webhooks")),
+	}
+}
+
+// SetBackoff changes the delay before the first retry; it doubles per attempt
+func (d *Dispatcher) SetBackoff(backoff time.Duration) {
+	d.backoff = backoff
+}
+
+// Publish delivers an event to every subscribed webhook in the background.
+// It returns the number of webhooks the event is being delivered to.
+func (d *Dispatcher) Publish(ctx context.Context, event string, data any) (int, error) {
+	hooks, err := d.source.ListWebhooks(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	body, err := encode(event, data)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, hook := range hooks {
+		if !hook.Wants(event) {
+			continue
+		}
+		count++
+		d.send(hook, event, body)
+	}
+	return count, nil
+}
+
+// Send delivers an event to a single endpoint in the background, whether or
+// not it is a registered subscription (e.g. the callback of a saved query)
+func (d *Dispatcher) Send(hook *types.Webhook, event string, data any) error {
+	body, err := encode(event, data)
+	if err != nil {
+		return err
+	}
+	d.send(hook, event, body)
+	return nil
+}
+
+// send starts delivering a body to a webhook
+func (d *Dispatcher) send(hook *types.Webhook, event string, body []byte) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(hook, event, body)
+	}()
+}
+
+// encode builds the JSON delivery of an event
+func encode(event string, data any) ([]byte, error) {
+	delivery := types.WebhookDelivery{
+		ID:        fmt.Sprintf("%s-%d", event, time.Now().UnixNano()),
+		Event:     event,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+	return body, nil
+}
+
+// Wait blocks until in-flight deliveries finish
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// deliver POSTs a body to a webhook, retrying failures
+func (d *Dispatcher) deliver(hook *types.Webhook, event string, body []byte) {
+	backoff := d.backoff
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = d.post(hook, event, body); err == nil {
+			d.logger.Debug("Webhook delivered",
+				zap.String("webhook_id", hook.ID),
+				zap.String("event", event))
+			return
+		}
+		if attempt < maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	d.logger.Warn("Webhook delivery failed",
+		zap.String("webhook_id", hook.ID),
+		zap.String("event", event),
+		zap.Int("attempts", maxAttempts),
+		zap.Error(err))
+}
+
+// post makes one delivery attempt
+func (d *Dispatcher) post(hook *types.Webhook, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AgentMesh-Event", event)
+	if hook.Secret != "" {
+		req.Header.Set("X-AgentMesh-Signature", Sign(hook.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the X-AgentMesh-Signature value for a body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
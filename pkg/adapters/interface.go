@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"time"
 
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
@@ -39,8 +40,12 @@ type AgentAdapter interface {
 	// Stop gracefully shuts down the agent
 	Stop() error
 
+	// ReportLifecycle publishes a lifecycle state change beyond those Start and
+	// Stop report, e.g. degraded while the LLM provider is rate limiting
+	ReportLifecycle(ctx context.Context, state types.LifecycleState, reason string) error
+
 	// GetCapabilities returns what this agent can do
-	GetCapabilities() []string
+	GetCapabilities() []types.Capability
 
 	// GetRole returns the agent's role (e.g., "sales", "support")
 	GetRole() string
@@ -58,7 +63,11 @@ type MeshConfig struct {
 	AgentID   types.AgentID
 	AgentName string
 	Role      string
-	Capabilities []string
+	Capabilities []string // Compact "name" or "name@version" form
+
+	// Deadline of the context each ReceiveMessage and ReceiveInsight call
+	// from the mesh gets (0 = none)
+	HandlerTimeout time.Duration
 }
 
 // InsightFilter allows agents to control what knowledge they receive
@@ -26,6 +26,8 @@ type LangChainAdapter struct {
 	config     *MeshConfig
 	logger     *zap.Logger
 	filter     *InsightFilter
+	sanitizer  *InsightSanitizer // Cleans received insights before they reach the vector store
+	lifecycle  lifecycleReporter
 
 	// Mock LangChain specific fields
 	chain      string // e.g., "ConversationalRetrievalChain"
@@ -48,11 +50,11 @@ func NewLangChainAdapter(
 		Name:         meshConfig.AgentName,
 		Role:         meshConfig.Role,
 		Status:       types.AgentStatusActive,
-		Capabilities: meshConfig.Capabilities,
+		Framework:    types.FrameworkLangChain,
+		Model:        getStringFromConfig(agentConfig, "llm", "gpt-3.5-turbo"),
+		Capabilities: types.ParseCapabilities(meshConfig.Capabilities),
 		Metadata: map[string]string{
-			"framework": "langchain",
 			"chain_type": getStringFromConfig(agentConfig, "chain", "ConversationalChain"),
-			"llm": getStringFromConfig(agentConfig, "llm", "gpt-3.5-turbo"),
 		},
 		CreatedAt:  time.Now(),
 		LastSeenAt: time.Now(),
@@ -63,6 +65,7 @@ func NewLangChainAdapter(
 		config:      meshConfig,
 		logger:      logger.With(zap.String("adapter", "langchain"), zap.String("agent_id", string(agent.ID))),
 		filter:      DefaultInsightFilter(),
+		sanitizer:   NewInsightSanitizer(nil),
 		chain:       getStringFromConfig(agentConfig, "chain", "ConversationalChain"),
 		vectorStore: getStringFromConfig(agentConfig, "vector_store", "memory"),
 		ctx:         ctx,
@@ -82,6 +85,8 @@ func (lc *LangChainAdapter) Start(ctx context.Context) error {
 		KafkaBrokers:     lc.config.KafkaBrokers,
 		KafkaTopicPrefix: "agentmesh",
 		RedisAddr:        lc.config.RedisAddr,
+
+		ConsumerHandlerTimeout: lc.config.HandlerTimeout,
 	}
 	lc.messaging = messaging.NewKafkaMessaging(cfg, lc.logger)
 
@@ -95,13 +100,16 @@ func (lc *LangChainAdapter) Start(ctx context.Context) error {
 	if err := lc.messaging.PublishTopologyEvent(ctx, joinEvent); err != nil {
 		return fmt.Errorf("failed to publish join event: %w", err)
 	}
+	lc.reportLifecycle(ctx, types.LifecycleStarting)
 
-	// Start message consumer
+	// Start message and pushed insight consumers
 	go lc.consumeMessages()
+	go lc.consumePushedInsights()
 
 	// Simulate LangChain agent running
 	go lc.simulateLangChainAgent()
 
+	lc.reportLifecycle(ctx, types.LifecycleReady)
 	lc.logger.Info("LangChain adapter started")
 	return nil
 }
@@ -109,6 +117,13 @@ func (lc *LangChainAdapter) Start(ctx context.Context) error {
 // Stop disconnects from AgentMesh
 func (lc *LangChainAdapter) Stop() error {
 	lc.logger.Info("Stopping LangChain adapter")
+	lc.reportLifecycle(lc.ctx, types.LifecycleDraining)
+	lc.cancel()
+
+	// The adapter's context is done, so announce the departure with a fresh one
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lc.reportLifecycle(ctx, types.LifecycleStopped)
 
 	// Publish agent left event
 	leaveEvent := types.TopologyEvent{
@@ -116,20 +131,31 @@ func (lc *LangChainAdapter) Stop() error {
 		AgentID:   lc.agent.ID,
 		Timestamp: time.Now(),
 	}
-	lc.messaging.PublishTopologyEvent(lc.ctx, leaveEvent)
+	lc.messaging.PublishTopologyEvent(ctx, leaveEvent)
 
-	lc.cancel()
 	lc.messaging.Close()
 	return nil
 }
 
+// ReportLifecycle publishes a lifecycle state change, e.g. degraded while the LLM provider is rate limiting
+func (lc *LangChainAdapter) ReportLifecycle(ctx context.Context, state types.LifecycleState, reason string) error {
+	return lc.lifecycle.report(ctx, lc.messaging, lc.agent, state, reason)
+}
+
+// reportLifecycle reports a state change of Start or Stop, logging failures
+func (lc *LangChainAdapter) reportLifecycle(ctx context.Context, state types.LifecycleState) {
+	if err := lc.ReportLifecycle(ctx, state, ""); err != nil {
+		lc.logger.Warn("Failed to report lifecycle state", zap.String("state", string(state)), zap.Error(err))
+	}
+}
+
 // GetAgent returns agent metadata
 func (lc *LangChainAdapter) GetAgent() *types.Agent {
 	return lc.agent
 }
 
 // GetCapabilities returns what this agent can do
-func (lc *LangChainAdapter) GetCapabilities() []string {
+func (lc *LangChainAdapter) GetCapabilities() []types.Capability {
 	return lc.agent.Capabilities
 }
 
@@ -167,13 +193,25 @@ func (lc *LangChainAdapter) ReceiveInsight(ctx context.Context, insight *types.I
 		zap.String("topic", insight.Topic),
 	)
 
+	sanitized, ok := lc.sanitizer.Sanitize(insight)
+	if !ok {
+		lc.logger.Warn("Dropped received insight before the vector store",
+			zap.String("insight_id", string(insight.ID)),
+			zap.String("from_agent", string(insight.AgentID)),
+			zap.Strings("flags", sanitized.Flags),
+		)
+		return nil
+	}
+
 	// In production:
-	// 1. Add insight to LangChain agent's vector store
-	// 2. Update agent memory
-	// 3. Incorporate into retrieval chain
+	// 1. Add sanitized.PromptBlock() to the LangChain agent's vector store,
+	//    with sanitized.Provenance() as document metadata
+	// 2. Retrieved blocks go into the prompt after InsightPreamble, never into
+	//    the chain's system prompt
 
 	lc.logger.Debug("Added insight to LangChain vector store (mock)",
 		zap.String("vector_store", lc.vectorStore),
+		zap.String("provenance", sanitized.Provenance()),
 	)
 
 	return nil
@@ -231,11 +269,11 @@ func (lc *LangChainAdapter) ReceiveMessage(ctx context.Context, msg *types.Messa
 // consumeMessages listens for messages from the mesh
 func (lc *LangChainAdapter) consumeMessages() {
 	groupID := fmt.Sprintf("langchain-%s", lc.agent.ID)
-	err := lc.messaging.ConsumeMessages(lc.ctx, "messages", groupID, func(msg *types.Message) error {
+	err := lc.messaging.ConsumeMessages(lc.ctx, "messages", groupID, func(ctx context.Context, msg *types.Message) error {
 		if msg.ToAgentID != lc.agent.ID {
 			return nil
 		}
-		return lc.ReceiveMessage(lc.ctx, msg)
+		return lc.ReceiveMessage(ctx, msg)
 	})
 
 	if err != nil && err != context.Canceled {
@@ -243,6 +281,26 @@ func (lc *LangChainAdapter) consumeMessages() {
 	}
 }
 
+// consumePushedInsights passes insights the knowledge manager pushes to this
+// agent's role to ReceiveInsight
+func (lc *LangChainAdapter) consumePushedInsights() {
+	groupID := fmt.Sprintf("langchain-%s-push", lc.agent.ID)
+	err := lc.messaging.ConsumeMessages(lc.ctx, "insights-push", groupID, func(ctx context.Context, msg *types.Message) error {
+		if msg.FromAgentID == lc.agent.ID || !types.PushedTo(msg, lc.agent.Role) {
+			return nil
+		}
+		insight, err := types.PushedInsight(msg)
+		if err != nil {
+			return err
+		}
+		return lc.ReceiveInsight(ctx, insight)
+	})
+
+	if err != nil && err != context.Canceled {
+		lc.logger.Error("Pushed insight consumption stopped", zap.Error(err))
+	}
+}
+
 // simulateLangChainAgent simulates the agent doing work and learning
 func (lc *LangChainAdapter) simulateLangChainAgent() {
 	ticker := time.NewTicker(45 * time.Second)
@@ -321,6 +379,11 @@ func (lc *LangChainAdapter) SetInsightFilter(filter *InsightFilter) {
 	)
 }
 
+// SetSanitizePolicy configures how received insights are cleaned before they reach the LLM
+func (lc *LangChainAdapter) SetSanitizePolicy(policy *SanitizePolicy) {
+	lc.sanitizer = NewInsightSanitizer(policy)
+}
+
 // Helper function to extract string from config map
 func getStringFromConfig(config map[string]interface{}, key, defaultValue string) string {
 	if val, ok := config[key].(string); ok {
@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// lifecycleReporter publishes an adapter's lifecycle state changes
+type lifecycleReporter struct {
+	mu    sync.Mutex
+	state types.LifecycleState
+}
+
+// report publishes a state change for the agent
+func (l *lifecycleReporter) report(ctx context.Context, msg *messaging.KafkaMessaging, agent *types.Agent, state types.LifecycleState, reason string) error {
+	if _, err := types.ParseLifecycleState(string(state)); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	previous := l.state
+	l.state = state
+	l.mu.Unlock()
+
+	event := &types.LifecycleEvent{
+		AgentID:   agent.ID,
+		State:     state,
+		Previous:  previous,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	agent.Lifecycle = &types.AgentLifecycle{State: state, Reason: reason, Since: event.Timestamp}
+	if err := msg.PublishLifecycleEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish lifecycle event: %w", err)
+	}
+	return nil
+}
@@ -28,6 +28,8 @@ type OpenAIAdapter struct {
 	config     *MeshConfig
 	logger     *zap.Logger
 	filter     *InsightFilter
+	sanitizer  *InsightSanitizer // Cleans received insights before they reach the assistant
+	lifecycle  lifecycleReporter
 
 	httpClient *http.Client
 	ctx        context.Context
@@ -48,9 +50,9 @@ func NewOpenAIAdapter(
 		Name:         meshConfig.AgentName,
 		Role:         meshConfig.Role,
 		Status:       types.AgentStatusActive,
-		Capabilities: meshConfig.Capabilities,
+		Framework:    types.FrameworkOpenAI,
+		Capabilities: types.ParseCapabilities(meshConfig.Capabilities),
 		Metadata: map[string]string{
-			"framework":    "openai",
 			"assistant_id": assistantID,
 		},
 		CreatedAt:  time.Now(),
@@ -64,6 +66,7 @@ func NewOpenAIAdapter(
 		config:      meshConfig,
 		logger:      logger.With(zap.String("adapter", "openai"), zap.String("agent_id", string(agent.ID))),
 		filter:      DefaultInsightFilter(),
+		sanitizer:   NewInsightSanitizer(nil),
 		httpClient:  &http.Client{Timeout: 30 * time.Second},
 		ctx:         ctx,
 		cancel:      cancel,
@@ -79,6 +82,8 @@ func (oa *OpenAIAdapter) Start(ctx context.Context) error {
 		KafkaBrokers:     oa.config.KafkaBrokers,
 		KafkaTopicPrefix: "agentmesh",
 		RedisAddr:        oa.config.RedisAddr,
+
+		ConsumerHandlerTimeout: oa.config.HandlerTimeout,
 	}
 	oa.messaging = messaging.NewKafkaMessaging(cfg, oa.logger)
 
@@ -97,10 +102,13 @@ func (oa *OpenAIAdapter) Start(ctx context.Context) error {
 	if err := oa.messaging.PublishTopologyEvent(ctx, joinEvent); err != nil {
 		return fmt.Errorf("failed to publish join event: %w", err)
 	}
+	oa.reportLifecycle(ctx, types.LifecycleStarting)
 
-	// Start message consumer
+	// Start message and pushed insight consumers
 	go oa.consumeMessages()
+	go oa.consumePushedInsights()
 
+	oa.reportLifecycle(ctx, types.LifecycleReady)
 	oa.logger.Info("OpenAI adapter started", zap.String("assistant_id", oa.assistantID))
 	return nil
 }
@@ -108,6 +116,13 @@ func (oa *OpenAIAdapter) Start(ctx context.Context) error {
 // Stop disconnects from AgentMesh
 func (oa *OpenAIAdapter) Stop() error {
 	oa.logger.Info("Stopping OpenAI adapter")
+	oa.reportLifecycle(oa.ctx, types.LifecycleDraining)
+	oa.cancel()
+
+	// The adapter's context is done, so announce the departure with a fresh one
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	oa.reportLifecycle(ctx, types.LifecycleStopped)
 
 	// Publish agent left event
 	leaveEvent := types.TopologyEvent{
@@ -115,20 +130,31 @@ func (oa *OpenAIAdapter) Stop() error {
 		AgentID:   oa.agent.ID,
 		Timestamp: time.Now(),
 	}
-	oa.messaging.PublishTopologyEvent(oa.ctx, leaveEvent)
+	oa.messaging.PublishTopologyEvent(ctx, leaveEvent)
 
-	oa.cancel()
 	oa.messaging.Close()
 	return nil
 }
 
+// ReportLifecycle publishes a lifecycle state change, e.g. degraded while the LLM provider is rate limiting
+func (oa *OpenAIAdapter) ReportLifecycle(ctx context.Context, state types.LifecycleState, reason string) error {
+	return oa.lifecycle.report(ctx, oa.messaging, oa.agent, state, reason)
+}
+
+// reportLifecycle reports a state change of Start or Stop, logging failures
+func (oa *OpenAIAdapter) reportLifecycle(ctx context.Context, state types.LifecycleState) {
+	if err := oa.ReportLifecycle(ctx, state, ""); err != nil {
+		oa.logger.Warn("Failed to report lifecycle state", zap.String("state", string(state)), zap.Error(err))
+	}
+}
+
 // GetAgent returns agent metadata
 func (oa *OpenAIAdapter) GetAgent() *types.Agent {
 	return oa.agent
 }
 
 // GetCapabilities returns what this agent can do
-func (oa *OpenAIAdapter) GetCapabilities() []string {
+func (oa *OpenAIAdapter) GetCapabilities() []types.Capability {
 	return oa.agent.Capabilities
 }
 
@@ -167,10 +193,26 @@ func (oa *OpenAIAdapter) ReceiveInsight(ctx context.Context, insight *types.Insi
 		zap.String("topic", insight.Topic),
 	)
 
-	// In a full implementation, you could:
-	// 1. Add insight to OpenAI assistant's knowledge
-	// 2. Update assistant instructions
-	// 3. Store in vector database for retrieval
+	sanitized, ok := oa.sanitizer.Sanitize(insight)
+	if !ok {
+		oa.logger.Warn("Dropped received insight before the assistant",
+			zap.String("insight_id", string(insight.ID)),
+			zap.String("from_agent", string(insight.AgentID)),
+			zap.Strings("flags", sanitized.Flags),
+		)
+		return nil
+	}
+
+	// In a full implementation:
+	// 1. Add sanitized.PromptBlock() to the thread as a message after
+	//    InsightPreamble, or to the assistant's vector store for retrieval
+	// 2. Never add received insights to the assistant instructions, which the
+	//    model treats as trusted
+
+	oa.logger.Debug("Added insight to OpenAI thread (mock)",
+		zap.String("thread_id", oa.threadID),
+		zap.String("provenance", sanitized.Provenance()),
+	)
 
 	return nil
 }
@@ -221,11 +263,11 @@ func (oa *OpenAIAdapter) ReceiveMessage(ctx context.Context, msg *types.Message)
 // consumeMessages listens for messages from the mesh
 func (oa *OpenAIAdapter) consumeMessages() {
 	groupID := fmt.Sprintf("openai-%s", oa.agent.ID)
-	err := oa.messaging.ConsumeMessages(oa.ctx, "messages", groupID, func(msg *types.Message) error {
+	err := oa.messaging.ConsumeMessages(oa.ctx, "messages", groupID, func(ctx context.Context, msg *types.Message) error {
 		if msg.ToAgentID != oa.agent.ID {
 			return nil
 		}
-		return oa.ReceiveMessage(oa.ctx, msg)
+		return oa.ReceiveMessage(ctx, msg)
 	})
 
 	if err != nil && err != context.Canceled {
@@ -233,6 +275,26 @@ func (oa *OpenAIAdapter) consumeMessages() {
 	}
 }
 
+// consumePushedInsights passes insights the knowledge manager pushes to this
+// agent's role to ReceiveInsight
+func (oa *OpenAIAdapter) consumePushedInsights() {
+	groupID := fmt.Sprintf("openai-%s-push", oa.agent.ID)
+	err := oa.messaging.ConsumeMessages(oa.ctx, "insights-push", groupID, func(ctx context.Context, msg *types.Message) error {
+		if msg.FromAgentID == oa.agent.ID || !types.PushedTo(msg, oa.agent.Role) {
+			return nil
+		}
+		insight, err := types.PushedInsight(msg)
+		if err != nil {
+			return err
+		}
+		return oa.ReceiveInsight(ctx, insight)
+	})
+
+	if err != nil && err != context.Canceled {
+		oa.logger.Error("Pushed insight consumption stopped", zap.Error(err))
+	}
+}
+
 // matchesFilter checks if an insight matches the agent's filter
 func (oa *OpenAIAdapter) matchesFilter(insight *types.Insight) bool {
 	// Check confidence
@@ -291,6 +353,11 @@ func (oa *OpenAIAdapter) callOpenAI(endpoint string, payload interface{}) (map[s
 	return map[string]interface{}{"status": "ok"}, nil
 }
 
+// SetSanitizePolicy configures how received insights are cleaned before they reach the LLM
+func (oa *OpenAIAdapter) SetSanitizePolicy(policy *SanitizePolicy) {
+	oa.sanitizer = NewInsightSanitizer(policy)
+}
+
 // SetInsightFilter configures what insights this agent wants to receive
 func (oa *OpenAIAdapter) SetInsightFilter(filter *InsightFilter) {
 	oa.filter = filter
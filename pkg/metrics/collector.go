@@ -22,6 +22,8 @@ type Collector struct {
 	MessageLatency   prometheus.Histogram
 	EdgeReinforcements prometheus.Counter
 	EdgePruned         prometheus.Counter
+	DuplicatesSuppressed *prometheus.CounterVec
+	HandlerCancellations *prometheus.CounterVec
 }
 
 // NewCollector creates a new metrics collector with Prometheus metrics
@@ -99,5 +101,19 @@ func NewCollector() *Collector {
 			Name: "agentmesh_edge_pruned_total",
 			Help: "Total edges pruned",
 		}),
+		DuplicatesSuppressed: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "agentmesh_duplicate_messages_suppressed_total",
+				Help: "Redelivered messages not passed to agent handlers again, by type",
+			},
+			[]string{"type"},
+		),
+		HandlerCancellations: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "agentmesh_handler_cancellations_total",
+				Help: "Agent handlers still running when their context ended, by type and reason",
+			},
+			[]string{"type", "reason"},
+		),
 	}
 }
@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"context"
+	"errors"
+
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
 
@@ -51,6 +54,16 @@ func (r *Reporter) RecordMessageSent(msgType types.MessageType) {
 	r.collector.MessagesSent.WithLabelValues(string(msgType)).Inc()
 }
 
+// RecordMessageReceived records a message received
+func (r *Reporter) RecordMessageReceived(msgType types.MessageType) {
+	r.collector.MessagesReceived.WithLabelValues(string(msgType)).Inc()
+}
+
+// RecordMessageLatency records how long a message took to handle
+func (r *Reporter) RecordMessageLatency(seconds float64) {
+	r.collector.MessageLatency.Observe(seconds)
+}
+
 // RecordEdgeReinforcement records an edge reinforcement
 func (r *Reporter) RecordEdgeReinforcement() {
 	r.collector.EdgeReinforcements.Inc()
@@ -60,3 +73,18 @@ func (r *Reporter) RecordEdgeReinforcement() {
 func (r *Reporter) RecordEdgePruned() {
 	r.collector.EdgePruned.Inc()
 }
+
+// RecordDuplicateSuppressed records a redelivered message an agent did not handle again
+func (r *Reporter) RecordDuplicateSuppressed(msgType types.MessageType) {
+	r.collector.DuplicatesSuppressed.WithLabelValues(string(msgType)).Inc()
+}
+
+// RecordHandlerCancelled records a handler still running when its context
+// ended, by the context's error: a timeout or a shutdown
+func (r *Reporter) RecordHandlerCancelled(msgType types.MessageType, cause error) {
+	reason := "canceled"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		reason = "deadline_exceeded"
+	}
+	r.collector.HandlerCancellations.WithLabelValues(string(msgType), reason).Inc()
+}
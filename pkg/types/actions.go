@@ -0,0 +1,140 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownAction is returned when decoding a task whose action is not registered
+var ErrUnknownAction = errors.New("unknown task action")
+
+// TaskAction is the typed payload of a task; Action names it in the payload's
+// "action" field, which receivers decode it by
+type TaskAction interface {
+	Action() string
+}
+
+// CheckStock asks inventory whether a SKU is in stock
+type CheckStock struct {
+	Product     string `json:"product,omitempty"`
+	SKU         string `json:"sku"`
+	Qty         int    `json:"qty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (CheckStock) Action() string { return "check_stock" }
+
+// VerifyTransaction asks fraud to verify an order's payment
+type VerifyTransaction struct {
+	OrderID     string  `json:"order_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description,omitempty"`
+}
+
+func (VerifyTransaction) Action() string { return "verify_transaction" }
+
+// EscalateTicket hands a support ticket to another team
+type EscalateTicket struct {
+	TicketID    string `json:"ticket_id"`
+	IssueType   string `json:"issue_type"`
+	Description string `json:"description,omitempty"`
+}
+
+func (EscalateTicket) Action() string { return "escalate" }
+
+// StockAlert warns that a product's stock level changed
+type StockAlert struct {
+	Product     string `json:"product"`
+	Level       string `json:"level"` // e.g. "low"
+	Description string `json:"description,omitempty"`
+}
+
+func (StockAlert) Action() string { return "stock_alert" }
+
+// FraudAlert warns about a risky transaction
+type FraudAlert struct {
+	Transaction string `json:"transaction"`
+	RiskLevel   string `json:"risk_level"` // e.g. "medium"
+	Description string `json:"description,omitempty"`
+}
+
+func (FraudAlert) Action() string { return "fraud_alert" }
+
+var (
+	taskActionsMu sync.RWMutex
+	taskActions   = map[string]func() TaskAction{}
+)
+
+func init() {
+	RegisterTaskAction(func() TaskAction { return &CheckStock{} })
+	RegisterTaskAction(func() TaskAction { return &VerifyTransaction{} })
+	RegisterTaskAction(func() TaskAction { return &EscalateTicket{} })
+	RegisterTaskAction(func() TaskAction { return &StockAlert{} })
+	RegisterTaskAction(func() TaskAction { return &FraudAlert{} })
+}
+
+// RegisterTaskAction registers a task action so DecodeTaskAction can decode it.
+// newAction returns a pointer to a new, empty payload; registering an action
+// name again replaces the earlier registration.
+func RegisterTaskAction(newAction func() TaskAction) {
+	taskActionsMu.Lock()
+	defer taskActionsMu.Unlock()
+	taskActions[newAction().Action()] = newAction
+}
+
+// TaskActions returns the registered action names, sorted
+func TaskActions() []string {
+	taskActionsMu.RLock()
+	defer taskActionsMu.RUnlock()
+	names := make([]string, 0, len(taskActions))
+	for name := range taskActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EncodeTaskAction converts a task action to a message payload
+func EncodeTaskAction(action TaskAction) (map[string]any, error) {
+	data, err := json.Marshal(action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", action.Action(), err)
+	}
+	payload := make(map[string]any)
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", action.Action(), err)
+	}
+	payload["action"] = action.Action()
+	return payload, nil
+}
+
+// ActionName returns the action a task message carries, "" if none
+func (m *Message) ActionName() string {
+	action, _ := m.Payload["action"].(string)
+	return action
+}
+
+// DecodeTaskAction decodes the registered task action a message carries into
+// its typed payload, e.g. a *CheckStock
+func DecodeTaskAction(msg *Message) (TaskAction, error) {
+	name := msg.ActionName()
+	taskActionsMu.RLock()
+	newAction, ok := taskActions[name]
+	taskActionsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAction, name)
+	}
+
+	data, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", name, err)
+	}
+	action := newAction()
+	if err := json.Unmarshal(data, action); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", name, err)
+	}
+	return action, nil
+}
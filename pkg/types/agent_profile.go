@@ -0,0 +1,188 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Well-known agent frameworks
+const (
+	FrameworkNative    = "agentmesh_native"
+	FrameworkOpenAI    = "openai"
+	FrameworkLangChain = "langchain"
+)
+
+// promotedMetadataKeys are metadata keys that have typed fields on Agent
+var promotedMetadataKeys = []string{"framework", "model", "language", "version", "region", "namespace"}
+
+var (
+	// tokenPattern matches framework, language, region, namespace and capability names
+	tokenPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/-]*$`)
+
+	// versionPattern accepts semver-like versions: 1, 1.2, v1.2.3, 1.2.3-beta.1
+	versionPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+){0,2}([-+][0-9A-Za-z.-]+)?$`)
+)
+
+// Capability describes something an agent can do
+type Capability struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	Schema  json.RawMessage `json:"schema,omitempty"` // JSON Schema of the capability's input
+}
+
+// ParseCapability parses the compact "name" or "name@version" form
+func ParseCapability(s string) Capability {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "@"); i > 0 {
+		return Capability{Name: s[:i], Version: s[i+1:]}
+	}
+	return Capability{Name: s}
+}
+
+// ParseCapabilities parses a list of compact capability strings, skipping empty ones
+func ParseCapabilities(values []string) []Capability {
+	capabilities := make([]Capability, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		capabilities = append(capabilities, ParseCapability(v))
+	}
+	return capabilities
+}
+
+// String returns the compact "name" or "name@version" form
+func (c Capability) String() string {
+	if c.Version == "" {
+		return c.Name
+	}
+	return c.Name + "@" + c.Version
+}
+
+// UnmarshalJSON accepts both the object form and the legacy plain-string form
+func (c *Capability) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*c = ParseCapability(s)
+		return nil
+	}
+
+	type capability Capability // Drop methods to avoid recursion
+	var decoded capability
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*c = Capability(decoded)
+	return nil
+}
+
+// Validate checks the capability name, version and schema
+func (c Capability) Validate() error {
+	if !tokenPattern.MatchString(c.Name) {
+		return fmt.Errorf("invalid capability name %q", c.Name)
+	}
+	if c.Version != "" && !versionPattern.MatchString(c.Version) {
+		return fmt.Errorf("capability %s has invalid version %q", c.Name, c.Version)
+	}
+	if len(c.Schema) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(c.Schema, &schema); err != nil {
+			return fmt.Errorf("capability %s schema must be a JSON object: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// HasCapability reports whether the agent declares a capability with the given name
+func (a *Agent) HasCapability(name string) bool {
+	_, ok := a.Capability(name)
+	return ok
+}
+
+// Capability returns the agent's capability with the given name
+func (a *Agent) Capability(name string) (Capability, bool) {
+	for _, c := range a.Capabilities {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capability{}, false
+}
+
+// CapabilityNames returns the compact form of every capability
+func (a *Agent) CapabilityNames() []string {
+	names := make([]string, len(a.Capabilities))
+	for i, c := range a.Capabilities {
+		names[i] = c.String()
+	}
+	return names
+}
+
+// PromoteMetadata moves well-known metadata keys (framework, model, language, version,
+// region, namespace) into their typed fields. Typed fields that are already set win.
+func (a *Agent) PromoteMetadata() {
+	fields := map[string]*string{
+		"framework": &a.Framework,
+		"model":     &a.Model,
+		"language":  &a.Language,
+		"version":   &a.Version,
+		"region":    &a.Region,
+		"namespace": &a.Namespace,
+	}
+	for _, key := range promotedMetadataKeys {
+		value, ok := a.Metadata[key]
+		if !ok {
+			continue
+		}
+		if *fields[key] == "" {
+			*fields[key] = value
+		}
+		delete(a.Metadata, key)
+	}
+}
+
+// Validate checks the agent's identity, typed metadata and capabilities
+func (a *Agent) Validate() error {
+	if a.ID == "" {
+		return fmt.Errorf("agent ID is required")
+	}
+	if a.Framework != "" && !tokenPattern.MatchString(a.Framework) {
+		return fmt.Errorf("invalid framework %q", a.Framework)
+	}
+	if a.Language != "" && !tokenPattern.MatchString(a.Language) {
+		return fmt.Errorf("invalid language %q", a.Language)
+	}
+	if a.Version != "" && !versionPattern.MatchString(a.Version) {
+		return fmt.Errorf("invalid version %q", a.Version)
+	}
+	if a.Region != "" && !tokenPattern.MatchString(a.Region) {
+		return fmt.Errorf("invalid region %q", a.Region)
+	}
+	if a.Namespace != "" && !tokenPattern.MatchString(a.Namespace) {
+		return fmt.Errorf("invalid namespace %q", a.Namespace)
+	}
+	for _, dependency := range a.DependsOn {
+		if !tokenPattern.MatchString(dependency) {
+			return fmt.Errorf("invalid dependency %q", dependency)
+		}
+	}
+
+	seen := make(map[string]bool, len(a.Capabilities))
+	for _, c := range a.Capabilities {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate capability %s", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook events of alerting rules
+const (
+	WebhookEventAlertFiring   = "alert.firing"
+	WebhookEventAlertResolved = "alert.resolved"
+)
+
+// Mesh metrics alerting rules can watch
+const (
+	AlertMetricConsumerLag     = "consumer_lag"     // Messages the furthest-behind consumer group is behind
+	AlertMetricDensity         = "density"          // Topology density (0-1)
+	AlertMetricActiveEdges     = "active_edges"     // Topology edges above 0.1 weight
+	AlertMetricAgents          = "agents"           // Agents in the topology
+	AlertMetricProposalBacklog = "proposal_backlog" // Proposals pending a decision
+)
+
+// AlertMetrics lists the metrics alerting rules can watch
+var AlertMetrics = []string{
+	AlertMetricConsumerLag,
+	AlertMetricDensity,
+	AlertMetricActiveEdges,
+	AlertMetricAgents,
+	AlertMetricProposalBacklog,
+}
+
+// AlertRule fires when a mesh metric, or its relative change over Window, is
+// above or below a limit
+type AlertRule struct {
+	Metric string        `json:"metric"`
+	Window time.Duration `json:"window,omitempty"` // Set for rate-of-change rules
+	Above  bool          `json:"above"`            // Fires above Limit, otherwise below
+	Limit  float64       `json:"limit"`            // Relative change (-0.5 = halved) for rate-of-change rules
+}
+
+// String returns the rule as written in ALERT_RULES, which also names it
+func (r AlertRule) String() string {
+	op := "<"
+	if r.Above {
+		op = ">"
+	}
+	limit := strconv.FormatFloat(r.Limit, 'g', -1, 64)
+	if r.Window > 0 {
+		return fmt.Sprintf("change(%s,%s)%s%s", r.Metric, r.Window, op, limit)
+	}
+	return r.Metric + op + limit
+}
+
+// Breached reports whether a value is past the rule's limit
+func (r AlertRule) Breached(value float64) bool {
+	if r.Above {
+		return value > r.Limit
+	}
+	return value < r.Limit
+}
+
+// ParseAlertRules parses rules given as "consumer_lag>10000,change(density,5m)<-0.5":
+// a threshold on a metric, or on its relative change over a window
+func ParseAlertRules(value string) ([]AlertRule, error) {
+	var rules []AlertRule
+	// Commas also separate the arguments of change(), so split on the ones outside it
+	depth, start := 0, 0
+	var entries []string
+	for i, c := range value {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				entries = append(entries, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	entries = append(entries, value[start:])
+
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		rule, err := parseAlertRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no alert rules given")
+	}
+	return rules, nil
+}
+
+// parseAlertRule parses one rule of ALERT_RULES
+func parseAlertRule(entry string) (AlertRule, error) {
+	var rule AlertRule
+	at := strings.IndexAny(entry, "<>")
+	if at < 0 {
+		return rule, fmt.Errorf("alert rule %q must compare a metric with > or <", entry)
+	}
+	subject := strings.TrimSpace(entry[:at])
+	rule.Above = entry[at] == '>'
+	limit, err := strconv.ParseFloat(strings.TrimSpace(entry[at+1:]), 64)
+	if err != nil {
+		return rule, fmt.Errorf("alert rule %q must have a numeric limit", entry)
+	}
+	rule.Limit = limit
+
+	if args, ok := strings.CutPrefix(subject, "change("); ok {
+		args, ok = strings.CutSuffix(args, ")")
+		metric, window, found := strings.Cut(args, ",")
+		if !ok || !found {
+			return rule, fmt.Errorf("alert rule %q must write a rate of change as change(metric,window)", entry)
+		}
+		rule.Window, err = time.ParseDuration(strings.TrimSpace(window))
+		if err != nil || rule.Window <= 0 {
+			return rule, fmt.Errorf("alert rule %q must have a positive window such as 5m", entry)
+		}
+		subject = metric
+	}
+
+	rule.Metric = strings.ToLower(strings.TrimSpace(subject))
+	for _, metric := range AlertMetrics {
+		if metric == rule.Metric {
+			return rule, nil
+		}
+	}
+	return rule, fmt.Errorf("alert rule %q watches unknown metric %q; use one of %s", entry, rule.Metric, strings.Join(AlertMetrics, ", "))
+}
+
+// Alert is the data of alert.firing and alert.resolved deliveries
+type Alert struct {
+	Rule       string     `json:"rule"` // As written in ALERT_RULES
+	Metric     string     `json:"metric"`
+	Value      float64    `json:"value"` // Metric, or its relative change, when last evaluated
+	Limit      float64    `json:"limit"`
+	FiredAt    time.Time  `json:"fired_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
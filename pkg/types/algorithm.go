@@ -0,0 +1,54 @@
+package types
+
+import (
+	"math"
+	"time"
+)
+
+// AlgorithmCycle records what the slime mold algorithm did in one decay
+// interval, listed by GET /api/topology/algorithm to validate its behavior
+type AlgorithmCycle struct {
+	Cycle     uint64    `json:"cycle"`
+	Timestamp time.Time `json:"timestamp"`
+	Paused    bool      `json:"paused,omitempty"` // Decay paused, e.g. during an incident
+	Frozen    bool      `json:"frozen,omitempty"` // Decay and pruning suspended by the guardrails
+
+	// Reinforcement events per edge since the previous cycle
+	Reinforcements     map[EdgeID]int64 `json:"reinforcements,omitempty"`
+	ReinforcementTotal int64            `json:"reinforcement_total"`
+
+	DecayRate    float64 `json:"decay_rate"`    // Base rate in effect, tuned and adapted to throughput
+	DecayApplied float64 `json:"decay_applied"` // Total weight removed from all edges
+
+	PruneThreshold  float64 `json:"prune_threshold"`
+	PruneCandidates int     `json:"prune_candidates"` // Edges the pruning strategy picked
+	PruneProtected  int     `json:"prune_protected"`  // Candidates kept to leave agents connected
+	PruneDeferred   int     `json:"prune_deferred"`   // Candidates postponed by MAX_PRUNE_PER_CYCLE
+	Pruned          int     `json:"pruned"`
+
+	Edges int `json:"edges"` // Edges left after pruning
+
+	// Shannon entropy of the edge weights over the log of the edge count:
+	// 1 while weight is spread evenly, falling as it concentrates on few edges
+	WeightEntropy float64 `json:"weight_entropy"`
+}
+
+// WeightEntropy returns the normalized Shannon entropy of edge weights, 0 for
+// fewer than two edges or no weight
+func WeightEntropy(weights []float64) float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += math.Max(0, w)
+	}
+	if len(weights) < 2 || total <= 0 {
+		return 0
+	}
+
+	entropy := 0.0
+	for _, w := range weights {
+		if p := w / total; p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	return entropy / math.Log(float64(len(weights)))
+}
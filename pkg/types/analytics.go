@@ -0,0 +1,68 @@
+package types
+
+import "time"
+
+// InsightAnalytics aggregates insights into topic counts and a trend, for
+// dashboards shared across teams. Insights of regulated teams are only
+// counted with differential privacy noise (see AnalyticsPrivacy).
+type InsightAnalytics struct {
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Bucket      string            `json:"bucket"` // Width of each trend point, e.g. "24h0m0s"
+	Total       int               `json:"total"`
+	Topics      []TopicCount      `json:"topics"`
+	Trend       []TrendPoint      `json:"trend"`
+	Privacy     *AnalyticsPrivacy `json:"privacy,omitempty"` // Set when counts include noise
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// TopicCount is the number of insights on a topic
+type TopicCount struct {
+	Topic string `json:"topic"`
+	Count int    `json:"count"`
+}
+
+// TrendPoint is the number of insights created in a bucket starting at Start
+type TrendPoint struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// AnalyticsPrivacy describes the noise added to the insights of regulated teams
+type AnalyticsPrivacy struct {
+	Teams     []string `json:"teams"`     // Teams whose insights were noised ("*" = all)
+	Epsilon   float64  `json:"epsilon"`   // Privacy budget of one release, split between topics and trend
+	Delta     float64  `json:"delta"`     // Chance a topic seen only in regulated insights is revealed
+	Threshold float64  `json:"threshold"` // Noised count a regulated-only topic needs to be listed
+}
+
+// CommunicationMatrix aggregates the topology's traffic by pair of agent roles
+// (e.g. sales and inventory) per time bucket, from the snapshot history
+type CommunicationMatrix struct {
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Bucket      string            `json:"bucket"` // Width of each window, e.g. "1h0m0s"
+	Roles       []string          `json:"roles"`
+	Pairs       []RolePairTraffic `json:"pairs"` // Busiest first
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// RolePairTraffic is the traffic between agents of two roles, in either
+// direction; Roles are sorted, and equal for traffic within one role
+type RolePairTraffic struct {
+	Roles     [2]string             `json:"roles"`
+	Messages  int64                 `json:"messages"`
+	ByType    map[MessageType]int64 `json:"by_type,omitempty"`
+	Edges     int                   `json:"edges"`      // Edges between the roles at the end
+	AvgWeight float64               `json:"avg_weight"` // Of those edges
+	Windows   []RolePairWindow      `json:"windows"`
+}
+
+// RolePairWindow is a role pair's traffic in the bucket starting at Start, and
+// its edges as of the end of the bucket
+type RolePairWindow struct {
+	Start     time.Time `json:"start"`
+	Messages  int64     `json:"messages"`
+	Edges     int       `json:"edges"`
+	AvgWeight float64   `json:"avg_weight"`
+}
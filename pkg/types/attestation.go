@@ -0,0 +1,170 @@
+package types
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attestation is an operator-signed claim about an agent's capabilities and
+// the data it may access. Agents present attestations when they join; the
+// topology manager verifies them against the operator CA keys.
+type Attestation struct {
+	Subject      AgentID   `json:"subject"`                // Agent the claim is about
+	Issuer       string    `json:"issuer"`                 // Operator CA that signed it
+	Capabilities []string  `json:"capabilities,omitempty"` // Attested capability names
+	DataAccess   []string  `json:"data_access,omitempty"`  // Attested data access scopes, e.g. "payments"
+	IssuedAt     time.Time `json:"issued_at"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"` // Zero = no expiry
+	Signature    string    `json:"signature"`            // Base64 ed25519 signature of the claims
+}
+
+// AttestedClaims are the claims of an agent's verified attestations,
+// set by the topology manager only
+type AttestedClaims struct {
+	Capabilities []string  `json:"capabilities,omitempty"`
+	DataAccess   []string  `json:"data_access,omitempty"`
+	Issuers      []string  `json:"issuers"`
+	VerifiedAt   time.Time `json:"verified_at"`
+}
+
+// claims returns the signed bytes of an attestation: its JSON without the signature
+func (a Attestation) claims() []byte {
+	a.Signature = ""
+	data, _ := json.Marshal(a)
+	return data
+}
+
+// Sign signs the attestation with an operator CA key
+func (a *Attestation) Sign(key ed25519.PrivateKey) {
+	a.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, a.claims()))
+}
+
+// Verify checks the attestation's signature against the issuer's key, and that
+// it is about the agent and has not expired
+func (a Attestation) Verify(subject AgentID, keys map[string]ed25519.PublicKey, now time.Time) error {
+	if a.Subject != subject {
+		return fmt.Errorf("attestation from %s is about %s, not %s", a.Issuer, a.Subject, subject)
+	}
+	key, ok := keys[a.Issuer]
+	if !ok {
+		return fmt.Errorf("attestation issuer %q is not a trusted CA", a.Issuer)
+	}
+	signature, err := base64.StdEncoding.DecodeString(a.Signature)
+	if err != nil || !ed25519.Verify(key, a.claims(), signature) {
+		return fmt.Errorf("attestation from %s has an invalid signature", a.Issuer)
+	}
+	if !a.ExpiresAt.IsZero() && now.After(a.ExpiresAt) {
+		return fmt.Errorf("attestation from %s expired at %s", a.Issuer, a.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ParseAttestationKeys parses operator CA keys given as "issuer=<base64 ed25519 public key>,..."
+func ParseAttestationKeys(value string) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		issuer, encoded, ok := strings.Cut(entry, "=")
+		if !ok || issuer == "" {
+			return nil, fmt.Errorf("attestation key %q must be issuer=key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("attestation key for %s is not a base64 ed25519 public key", issuer)
+		}
+		keys[issuer] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+// VerifyAttestations replaces the agent's attested claims with those of its
+// valid attestations and returns why the others were rejected
+func (a *Agent) VerifyAttestations(keys map[string]ed25519.PublicKey, now time.Time) []error {
+	a.Attested = nil
+
+	var errs []error
+	claims := &AttestedClaims{VerifiedAt: now}
+	for _, attestation := range a.Attestations {
+		if err := attestation.Verify(a.ID, keys, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		claims.Capabilities = appendUnique(claims.Capabilities, attestation.Capabilities...)
+		claims.DataAccess = appendUnique(claims.DataAccess, attestation.DataAccess...)
+		claims.Issuers = appendUnique(claims.Issuers, attestation.Issuer)
+	}
+	if len(claims.Issuers) > 0 {
+		a.Attested = claims
+	}
+	return errs
+}
+
+// HasAttestedCapability reports whether a verified attestation covers a
+// capability the agent declares
+func (a *Agent) HasAttestedCapability(name string) bool {
+	return a.HasCapability(name) && a.Attested != nil && containsString(a.Attested.Capabilities, name)
+}
+
+// HasDataAccess reports whether a verified attestation grants a data access scope
+func (a *Agent) HasDataAccess(scope string) bool {
+	return a.Attested != nil && containsString(a.Attested.DataAccess, scope)
+}
+
+// Qualifies reports whether the agent may be routed tasks needing a capability;
+// with attested set, the capability must be attested. An empty capability qualifies any agent.
+func (a *Agent) Qualifies(capability string, attested bool) bool {
+	if capability == "" {
+		return true
+	}
+	if attested {
+		return a.HasAttestedCapability(capability)
+	}
+	return a.HasCapability(capability)
+}
+
+// VisibleTo reports whether an agent may read the insight under its privacy
+// setting and, if set, its required data access scope
+func (i *Insight) VisibleTo(agent *Agent) bool {
+	if agent.ID == i.AgentID {
+		return true
+	}
+	if i.RequiredAccess != "" && !agent.HasDataAccess(i.RequiredAccess) {
+		return false
+	}
+	switch i.Privacy {
+	case InsightPrivacyPrivate:
+		return false
+	case InsightPrivacyRestricted:
+		for _, id := range i.SharedWith {
+			if id == agent.ID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, added ...string) []string {
+	for _, v := range added {
+		if !containsString(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// StartMode selects where a manager starts consuming its Kafka topics
+type StartMode string
+
+const (
+	// StartModeResume continues from the committed offsets and the persisted
+	// state; a new consumer group starts from the earliest offset (default)
+	StartModeResume StartMode = "resume"
+
+	// StartModeEarliest discards the persisted state and rebuilds it by
+	// replaying the topics from the earliest offset still retained
+	StartModeEarliest StartMode = "earliest"
+
+	// StartModeLatest keeps the persisted state and skips the backlog,
+	// consuming only messages published from now on
+	StartModeLatest StartMode = "latest"
+
+	// StartModeReplay rebuilds the topology without a persisted snapshot by
+	// replaying its topics from TOPOLOGY_REPLAY_FROM, in the order and at the
+	// time the records were published (topology manager only)
+	StartModeReplay StartMode = "replay"
+)
+
+// ParseStartMode parses a start mode, empty meaning StartModeResume
+func ParseStartMode(value string) (StartMode, error) {
+	switch mode := StartMode(value); mode {
+	case "":
+		return StartModeResume, nil
+	case StartModeResume, StartModeEarliest, StartModeLatest, StartModeReplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown start mode %q (want resume, earliest, latest or replay)", value)
+}
+
+// ParseReplayFrom parses where a replay starts: an RFC 3339 timestamp, or a
+// duration before now, e.g. "72h"
+func ParseReplayFrom(value string, now time.Time) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil || ago <= 0 {
+		return time.Time{}, fmt.Errorf("replay start %q must be an RFC 3339 timestamp or a positive duration before now", value)
+	}
+	return now.Add(-ago), nil
+}
+
+// BackfillProgress reports a manager rebuilding its state from Kafka
+type BackfillProgress struct {
+	Manager     string     `json:"manager"`
+	Total       int64      `json:"total"` // Messages retained when the rebuild started
+	Processed   int64      `json:"processed"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Percent returns the share of the backlog replayed so far (0-100)
+func (p *BackfillProgress) Percent() float64 {
+	if p.Total <= 0 || p.Processed >= p.Total {
+		return 100
+	}
+	return float64(p.Processed) / float64(p.Total) * 100
+}
+
+// Observe counts a replayed message and reports whether it completed the backlog
+func (p *BackfillProgress) Observe(now time.Time) bool {
+	if p.CompletedAt != nil {
+		return false
+	}
+	p.Processed++
+	p.UpdatedAt = now
+	if p.Processed < p.Total {
+		return false
+	}
+	p.CompletedAt = &now
+	return true
+}
@@ -0,0 +1,52 @@
+package types
+
+// EdgeBootstrap is which edges the topology creates for an agent that joins
+type EdgeBootstrap string
+
+const (
+	// None: an edge forms on the first message sent over it, so the topology
+	// only ever holds the paths traffic used
+	EdgeBootstrapLazy EdgeBootstrap = "lazy"
+	// A self-loop and edges to and from every other agent, which decay prunes
+	// down to the paths traffic uses
+	EdgeBootstrapFullMesh EdgeBootstrap = "full_mesh"
+)
+
+// EdgeMode is whether traffic between two agents is tracked on an edge per
+// direction or on one edge for both
+type EdgeMode string
+
+const (
+	// An edge per direction, each reinforced by the traffic that way only
+	EdgeModeDirected EdgeMode = "directed"
+	// One edge per pair of agents, from the lower agent ID to the higher,
+	// reinforced by traffic either way
+	EdgeModeUndirected EdgeMode = "undirected"
+)
+
+// Endpoints returns the source and target of the edge that carries traffic
+// from sourceID to targetID
+func (m EdgeMode) Endpoints(sourceID, targetID AgentID) (AgentID, AgentID) {
+	if m == EdgeModeUndirected && targetID < sourceID {
+		return targetID, sourceID
+	}
+	return sourceID, targetID
+}
+
+// EdgeID returns the ID of the edge that carries traffic from sourceID to targetID
+func (m EdgeMode) EdgeID(sourceID, targetID AgentID) EdgeID {
+	return NewEdgeID(m.Endpoints(sourceID, targetID))
+}
+
+// PruneStrategy names the built-in rule deciding which edges a decay cycle prunes
+type PruneStrategy string
+
+const (
+	// Edges whose weight fell below PRUNE_THRESHOLD (or its tuned value)
+	PruneStrategyThreshold PruneStrategy = "threshold"
+	// All but each agent's PRUNE_TOP_K strongest edges, in or out, however
+	// strong the rest are
+	PruneStrategyTopK PruneStrategy = "top_k"
+	// Edges no message used for PRUNE_MAX_AGE, however strong they are
+	PruneStrategyAge PruneStrategy = "age"
+)
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BundleID is a unique identifier for a proposal bundle
+type BundleID string
+
+// BundleRule decides how the member proposals of a bundle are accepted
+type BundleRule string
+
+const (
+	// BundleRuleAllOrNothing accepts every member once all reach quorum,
+	// and none if any misses it before the bundle expires
+	BundleRuleAllOrNothing BundleRule = "all_or_nothing"
+
+	// BundleRuleBestOf accepts up to BestOf members that reached quorum, those
+	// with the most support first, once all reach quorum or the bundle expires
+	BundleRuleBestOf BundleRule = "best_of"
+)
+
+// MaxBundleSize bounds the number of proposals voted on as one slate
+const MaxBundleSize = 20
+
+// ProposalBundle is a slate of related proposals voted on together,
+// e.g. coordinated price changes across regions
+type ProposalBundle struct {
+	ID         BundleID       `json:"id"`
+	ProposerID AgentID        `json:"proposer_id"`
+	Title      string         `json:"title,omitempty"`
+	Rule       BundleRule     `json:"rule"`
+	BestOf     int            `json:"best_of,omitempty"` // best_of: most members accepted (default 1)
+	Proposals  []ProposalID   `json:"proposals"`
+	Status     ProposalStatus `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	DecidedAt  time.Time      `json:"decided_at,omitempty"`
+}
+
+// NewBundleID generates a unique bundle ID
+func NewBundleID() BundleID {
+	return BundleID(uuid.New().String())
+}
+
+// ValidateBundle checks a bundle's rule against the number of its members
+func ValidateBundle(rule BundleRule, bestOf, members int) error {
+	if members < 2 {
+		return fmt.Errorf("a bundle needs at least 2 proposals")
+	}
+	if members > MaxBundleSize {
+		return fmt.Errorf("a bundle holds at most %d proposals", MaxBundleSize)
+	}
+	switch rule {
+	case BundleRuleAllOrNothing:
+		if bestOf != 0 {
+			return fmt.Errorf("best_of only applies to best_of bundles")
+		}
+	case BundleRuleBestOf:
+		if bestOf < 0 || bestOf > members {
+			return fmt.Errorf("best_of must be between 1 and %d", members)
+		}
+	default:
+		return fmt.Errorf("invalid bundle rule %q (use all_or_nothing or best_of)", rule)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EdgeConstraint forbids edges between two sides, or caps their weight. A
+// side is a role, or an agent given as "agent:<id>", and edges are
+// constrained in both directions.
+type EdgeConstraint struct {
+	A         string  `json:"a"`
+	B         string  `json:"b"`
+	Deny      bool    `json:"deny,omitempty"`
+	MaxWeight float64 `json:"max_weight,omitempty"` // Cap on edge weight unless denied
+}
+
+// String renders the constraint the way it is configured, e.g. "fraud/external_adapter=deny"
+func (c EdgeConstraint) String() string {
+	if c.Deny {
+		return c.A + "/" + c.B + "=deny"
+	}
+	return c.A + "/" + c.B + "=" + strconv.FormatFloat(c.MaxWeight, 'g', -1, 64)
+}
+
+// constraintSideMatches reports whether a side names an agent or its role
+func constraintSideMatches(side string, agent *Agent) bool {
+	if id, ok := strings.CutPrefix(side, "agent:"); ok {
+		return AgentID(id) == agent.ID
+	}
+	return side == agent.Role
+}
+
+// Applies reports whether the constraint covers edges between two agents
+func (c EdgeConstraint) Applies(source, target *Agent) bool {
+	return (constraintSideMatches(c.A, source) && constraintSideMatches(c.B, target)) ||
+		(constraintSideMatches(c.A, target) && constraintSideMatches(c.B, source))
+}
+
+// EdgeConstraints are the constraints on edges between roles or agents
+type EdgeConstraints []EdgeConstraint
+
+// ParseEdgeConstraints parses constraints given as
+// "fraud/external_adapter=deny,sales/support=0.6,agent:agent-pricing-1/analyst=deny":
+// edges between the two sides are forbidden, or their weight is capped
+func ParseEdgeConstraints(value string) (EdgeConstraints, error) {
+	var constraints EdgeConstraints
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		pair, limit, ok := strings.Cut(entry, "=")
+		a, b, isPair := strings.Cut(pair, "/")
+		a, b, limit = strings.TrimSpace(a), strings.TrimSpace(b), strings.TrimSpace(limit)
+		if !ok || !isPair || a == "" || b == "" || a == "agent:" || b == "agent:" {
+			return nil, fmt.Errorf("edge constraint %q must be side/side=deny or side/side=max weight", entry)
+		}
+
+		constraint := EdgeConstraint{A: a, B: b}
+		if strings.EqualFold(limit, "deny") {
+			constraint.Deny = true
+		} else {
+			weight, err := strconv.ParseFloat(limit, 64)
+			if err != nil || weight <= 0 || weight >= 1 {
+				return nil, fmt.Errorf("edge constraint %q must be deny or a max weight above 0 and below 1", entry)
+			}
+			constraint.MaxWeight = weight
+		}
+		constraints = append(constraints, constraint)
+	}
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf("no edge constraints given")
+	}
+	return constraints, nil
+}
+
+// Limit returns the tightest constraint on edges between two agents: whether
+// they are allowed and the most weight they may have (1 when uncapped).
+// Self-loops track an agent's own activity and are never constrained.
+func (c EdgeConstraints) Limit(source, target *Agent) (allowed bool, maxWeight float64) {
+	allowed, maxWeight = true, 1.0
+	if source.ID == target.ID {
+		return allowed, maxWeight
+	}
+	for _, constraint := range c {
+		if !constraint.Applies(source, target) {
+			continue
+		}
+		if constraint.Deny {
+			return false, 0
+		}
+		maxWeight = min(maxWeight, constraint.MaxWeight)
+	}
+	return allowed, maxWeight
+}
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrSnapshotGap is returned when a snapshot delta does not follow the snapshot
+// it is applied to; the consumer must start again from a full checkpoint
+var ErrSnapshotGap = errors.New("snapshot delta out of sequence")
+
+// SnapshotDelta is what changed in the topology between two consecutive
+// snapshots: publishers write one per interval instead of the full graph, with a
+// full checkpoint every so often that consumers start from
+type SnapshotDelta struct {
+	Sequence      uint64                     `json:"sequence"` // Sequence of the snapshot it produces, one past its base
+	Timestamp     time.Time                  `json:"timestamp"`
+	Agents        map[AgentID]*Agent         `json:"agents,omitempty"` // Added or changed
+	RemovedAgents []AgentID                  `json:"removed_agents,omitempty"`
+	Edges         map[EdgeID]*Edge           `json:"edges,omitempty"` // Added or changed
+	RemovedEdges  []EdgeID                   `json:"removed_edges,omitempty"`
+	Communities   map[AgentID]int            `json:"communities,omitempty"` // All of them, only when any changed
+	Layout        map[AgentID]LayoutPosition `json:"layout,omitempty"`      // All of them, only when any agent moved
+	Stats         GraphStats                 `json:"stats"`
+}
+
+// Empty reports whether the delta changes no agent, edge, community or position
+func (d *SnapshotDelta) Empty() bool {
+	return len(d.Agents) == 0 && len(d.RemovedAgents) == 0 &&
+		len(d.Edges) == 0 && len(d.RemovedEdges) == 0 && d.Communities == nil && d.Layout == nil
+}
+
+// NewSnapshotDelta computes the delta turning previous into next. next's
+// sequence must follow previous's.
+func NewSnapshotDelta(previous, next *GraphSnapshot) *SnapshotDelta {
+	delta := &SnapshotDelta{
+		Sequence:  next.Sequence,
+		Timestamp: next.Timestamp,
+		Agents:    changed(previous.Agents, next.Agents),
+		Edges:     changed(previous.Edges, next.Edges),
+		Stats:     next.Stats,
+	}
+	delta.RemovedAgents = removed(previous.Agents, next.Agents)
+	delta.RemovedEdges = removed(previous.Edges, next.Edges)
+
+	if !sameValues(previous.Communities, next.Communities) {
+		delta.Communities = next.Communities
+	}
+	if !sameValues(previous.Layout, next.Layout) {
+		delta.Layout = next.Layout
+	}
+	return delta
+}
+
+// sameValues reports whether two maps hold the same keys and values
+func sameValues[K, V comparable](previous, next map[K]V) bool {
+	if len(previous) != len(next) {
+		return false
+	}
+	for id, value := range next {
+		if known, ok := previous[id]; !ok || known != value {
+			return false
+		}
+	}
+	return true
+}
+
+// changed returns the values of next that are new or differ from previous
+func changed[K comparable, V any](previous, next map[K]*V) map[K]*V {
+	out := make(map[K]*V)
+	for id, value := range next {
+		known, ok := previous[id]
+		if !ok || !sameJSON(known, value) {
+			out[id] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// removed returns the keys of previous missing from next, sorted
+func removed[K ~string, V any](previous, next map[K]V) []K {
+	var out []K
+	for id := range previous {
+		if _, ok := next[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// sameJSON reports whether two values encode to the same JSON, which is what
+// consumers of a snapshot see of them
+func sameJSON(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// SnapshotReconstructor rebuilds the current topology on the consumer side
+// from a full checkpoint and the deltas published after it. It is not safe for
+// concurrent use.
+type SnapshotReconstructor struct {
+	snapshot *GraphSnapshot
+}
+
+// Checkpoint resets the reconstructor to a full snapshot
+func (r *SnapshotReconstructor) Checkpoint(snapshot *GraphSnapshot) {
+	r.snapshot = &GraphSnapshot{
+		Agents:      make(map[AgentID]*Agent, len(snapshot.Agents)),
+		Edges:       make(map[EdgeID]*Edge, len(snapshot.Edges)),
+		Communities: make(map[AgentID]int, len(snapshot.Communities)),
+		Layout:      make(map[AgentID]LayoutPosition, len(snapshot.Layout)),
+		Sequence:    snapshot.Sequence,
+		Timestamp:   snapshot.Timestamp,
+		Stats:       snapshot.Stats,
+	}
+	for id, agent := range snapshot.Agents {
+		r.snapshot.Agents[id] = agent
+	}
+	for id, edge := range snapshot.Edges {
+		r.snapshot.Edges[id] = edge
+	}
+	for id, community := range snapshot.Communities {
+		r.snapshot.Communities[id] = community
+	}
+	for id, position := range snapshot.Layout {
+		r.snapshot.Layout[id] = position
+	}
+}
+
+// Apply applies the delta following the current snapshot. A delta out of
+// sequence wraps ErrSnapshotGap and leaves the snapshot unchanged; deltas the
+// snapshot already includes are ignored.
+func (r *SnapshotReconstructor) Apply(delta *SnapshotDelta) error {
+	if r.snapshot == nil {
+		return fmt.Errorf("%w: no checkpoint to apply delta %d to", ErrSnapshotGap, delta.Sequence)
+	}
+	if delta.Sequence <= r.snapshot.Sequence {
+		return nil
+	}
+	if delta.Sequence != r.snapshot.Sequence+1 {
+		return fmt.Errorf("%w: expected %d, got %d", ErrSnapshotGap, r.snapshot.Sequence+1, delta.Sequence)
+	}
+
+	for _, id := range delta.RemovedAgents {
+		delete(r.snapshot.Agents, id)
+		delete(r.snapshot.Communities, id)
+		delete(r.snapshot.Layout, id)
+	}
+	for id, agent := range delta.Agents {
+		r.snapshot.Agents[id] = agent
+	}
+	for _, id := range delta.RemovedEdges {
+		delete(r.snapshot.Edges, id)
+	}
+	for id, edge := range delta.Edges {
+		r.snapshot.Edges[id] = edge
+	}
+	if delta.Communities != nil {
+		r.snapshot.Communities = make(map[AgentID]int, len(delta.Communities))
+		for id, community := range delta.Communities {
+			r.snapshot.Communities[id] = community
+		}
+	}
+	if delta.Layout != nil {
+		r.snapshot.Layout = make(map[AgentID]LayoutPosition, len(delta.Layout))
+		for id, position := range delta.Layout {
+			r.snapshot.Layout[id] = position
+		}
+	}
+	r.snapshot.Sequence = delta.Sequence
+	r.snapshot.Timestamp = delta.Timestamp
+	r.snapshot.Stats = delta.Stats
+	return nil
+}
+
+// Snapshot returns the reconstructed topology, nil before the first
+// checkpoint. It is shared with the reconstructor until the next Apply or
+// Checkpoint, so callers must not modify it.
+func (r *SnapshotReconstructor) Snapshot() *GraphSnapshot {
+	return r.snapshot
+}
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxProposalDependencies bounds the prerequisites a proposal may declare
+const MaxProposalDependencies = 10
+
+// DependencyGraph is a set of proposals and the prerequisites between them,
+// e.g. a price change that only stands if the budget for it was accepted
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"` // Oldest first
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// DependencyNode is a proposal of a dependency graph
+type DependencyNode struct {
+	ID            ProposalID     `json:"id"`
+	Type          ProposalType   `json:"type,omitempty"`
+	Status        ProposalStatus `json:"status,omitempty"` // Empty once the proposal is no longer kept
+	CreatedAt     time.Time      `json:"created_at,omitempty"`
+	EscalatedFrom ProposalID     `json:"escalated_from,omitempty"` // Expired proposal this one re-proposes
+	BlockedBy     ProposalID     `json:"blocked_by,omitempty"`     // Prerequisite whose failure expired it
+}
+
+// DependencyEdge is a prerequisite that must be accepted before its dependent can be
+type DependencyEdge struct {
+	From ProposalID `json:"from"` // Prerequisite
+	To   ProposalID `json:"to"`   // Dependent
+}
+
+// NewDependencyGraph returns the graph of the dependencies between proposals,
+// with re-proposals following the proposals they escalate. With a root, only
+// the proposals connected to it are included.
+func NewDependencyGraph(proposals []*Proposal, root ProposalID) *DependencyGraph {
+	byID := make(map[ProposalID]*Proposal, len(proposals))
+	linked := make(map[ProposalID][]ProposalID)
+	link := func(a, b ProposalID) {
+		linked[a] = append(linked[a], b)
+		linked[b] = append(linked[b], a)
+	}
+	graph := &DependencyGraph{Nodes: []DependencyNode{}, Edges: []DependencyEdge{}}
+	for _, p := range proposals {
+		byID[p.ID] = p
+		for _, prerequisite := range p.DependsOn {
+			graph.Edges = append(graph.Edges, DependencyEdge{From: prerequisite, To: p.ID})
+			link(prerequisite, p.ID)
+		}
+		if p.EscalatedFrom != "" {
+			link(p.EscalatedFrom, p.ID)
+		}
+	}
+
+	// Proposals with a dependency, or re-proposing one that has, by a search from each
+	included := make(map[ProposalID]bool)
+	var visit func(id ProposalID)
+	visit = func(id ProposalID) {
+		if included[id] {
+			return
+		}
+		included[id] = true
+		for _, next := range linked[id] {
+			visit(next)
+		}
+	}
+	if root != "" {
+		visit(root)
+	} else {
+		for _, edge := range graph.Edges {
+			visit(edge.From)
+		}
+	}
+
+	for id := range included {
+		node := DependencyNode{ID: id}
+		if p, ok := byID[id]; ok {
+			node.Type, node.Status, node.CreatedAt = p.Type, p.Status, p.CreatedAt
+			node.EscalatedFrom, node.BlockedBy = p.EscalatedFrom, p.BlockedBy
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		a, b := graph.Nodes[i], graph.Nodes[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	edges := graph.Edges[:0]
+	for _, edge := range graph.Edges {
+		if included[edge.To] {
+			edges = append(edges, edge)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	graph.Edges = edges
+	return graph
+}
@@ -0,0 +1,66 @@
+package types
+
+import "time"
+
+// TopologyDiff is how the topology evolved between two snapshots: the agents
+// that joined or left, the edges that formed or were pruned, and the weight
+// changes of the edges in both, with how the stats moved
+type TopologyDiff struct {
+	From          time.Time          `json:"from"` // Timestamp of the earlier snapshot
+	To            time.Time          `json:"to"`   // Timestamp of the later snapshot
+	AddedAgents   []*Agent           `json:"added_agents"`
+	RemovedAgents []*Agent           `json:"removed_agents"`
+	AddedEdges    []*Edge            `json:"added_edges"`
+	PrunedEdges   []*Edge            `json:"pruned_edges"`
+	WeightChanges []EdgeWeightChange `json:"weight_changes"` // Largest change first
+	Strengthened  int                `json:"strengthened"`
+	Weakened      int                `json:"weakened"`
+
+	StatsBefore GraphStats `json:"stats_before"` // Without centrality
+	StatsAfter  GraphStats `json:"stats_after"`
+	StatsDelta  StatsDelta `json:"stats_delta"` // After minus before
+}
+
+// StatsDelta is how the headline topology stats changed between two snapshots
+type StatsDelta struct {
+	Agents           int     `json:"agents"`
+	Edges            int     `json:"edges"`
+	ActiveEdges      int     `json:"active_edges"`
+	AverageWeight    float64 `json:"average_weight"`
+	Density          float64 `json:"density"`
+	ReductionPercent float64 `json:"reduction_percent"`
+	Communities      int     `json:"communities"`
+	Components       int     `json:"components"`
+}
+
+// NewStatsDelta returns the change from one snapshot's stats to another's
+func NewStatsDelta(before, after GraphStats) StatsDelta {
+	return StatsDelta{
+		Agents:           after.TotalAgents - before.TotalAgents,
+		Edges:            after.TotalEdges - before.TotalEdges,
+		ActiveEdges:      after.ActiveEdges - before.ActiveEdges,
+		AverageWeight:    after.AverageWeight - before.AverageWeight,
+		Density:          after.Density - before.Density,
+		ReductionPercent: after.ReductionPercent - before.ReductionPercent,
+		Communities:      after.Communities - before.Communities,
+		Components:       after.Components - before.Components,
+	}
+}
+
+// Top keeps only the n largest weight changes, all of them for n <= 0.
+// Strengthened and Weakened still count every change.
+func (d *TopologyDiff) Top(n int) {
+	if n > 0 && len(d.WeightChanges) > n {
+		d.WeightChanges = d.WeightChanges[:n]
+	}
+}
+
+// EdgeWeightChange is the weight change of an edge present in both snapshots
+type EdgeWeightChange struct {
+	EdgeID   EdgeID  `json:"edge_id"`
+	SourceID AgentID `json:"source_id"`
+	TargetID AgentID `json:"target_id"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+	Delta    float64 `json:"delta"`
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Digest is a periodic report of what the mesh learned and decided
+type Digest struct {
+	ID                string          `json:"id"`
+	PeriodStart       time.Time       `json:"period_start"`
+	PeriodEnd         time.Time       `json:"period_end"`
+	TopInsights       []Insight       `json:"top_insights"` // Ranked, most important first
+	NewPatterns       []Pattern       `json:"new_patterns"`
+	AcceptedProposals []*Proposal     `json:"accepted_proposals"`
+	Topology          TopologyChanges `json:"topology"`
+	InsightCount      int             `json:"insight_count"` // All insights in the period
+	GeneratedAt       time.Time       `json:"generated_at"`
+}
+
+// TopologyChanges compares the first and last topology snapshots of a period
+type TopologyChanges struct {
+	Snapshots    int        `json:"snapshots"` // Snapshots compared, 0 if none were taken
+	AgentsJoined []AgentID  `json:"agents_joined"`
+	AgentsLeft   []AgentID  `json:"agents_left"`
+	EdgesFormed  int        `json:"edges_formed"`
+	EdgesPruned  int        `json:"edges_pruned"`
+	Start        GraphStats `json:"start"`
+	End          GraphStats `json:"end"`
+}
+
+// NewDigestID generates a digest ID from the end of its period
+func NewDigestID(periodEnd time.Time) string {
+	return fmt.Sprintf("digest-%s", periodEnd.UTC().Format("20060102T150405Z"))
+}
+
+// Webhook events
+const (
+	WebhookEventDigest            = "digest.published"
+	WebhookEventProposalEscalated = "proposal.escalated"
+)
+
+// Webhook is an HTTP endpoint subscribed to mesh events
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"` // Event types to deliver, empty = all
+	Secret    string    `json:"secret,omitempty"` // Signs deliveries (X-AgentMesh-Signature)
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wants reports whether the webhook subscribes to an event type
+func (w *Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the JSON body POSTed to webhooks
+type WebhookDelivery struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Data      any       `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"slices"
+	"time"
+)
+
+// EdgeMemory is the weight an edge had when one of its agents left, kept to
+// seed the edge when they meet again (EDGE_PRIOR_HISTORY)
+type EdgeMemory struct {
+	Weight    float64   `json:"weight"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// DependsOnAgent reports whether the agent declared the other agent, by ID or
+// role, among the agents it depends on
+func (a *Agent) DependsOnAgent(other *Agent) bool {
+	return slices.Contains(a.DependsOn, string(other.ID)) || (other.Role != "" && slices.Contains(a.DependsOn, other.Role))
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EdgeID encoding
+//
+// An EdgeID is "source->target". Agent IDs supplied by external adapters may
+// themselves contain "->", so backslash and '>' inside an agent ID are escaped
+// with a backslash. The only unescaped '>' is then the one in the delimiter,
+// and IDs without '\' or '>' (e.g. UUIDs) encode exactly as before.
+
+const edgeDelimiter = "->"
+
+var edgeIDEscaper = strings.NewReplacer(`\`, `\\`, `>`, `\>`)
+
+// NewEdgeID generates the edge ID for a directed source -> target edge
+func NewEdgeID(sourceID, targetID AgentID) EdgeID {
+	return EdgeID(edgeIDEscaper.Replace(string(sourceID)) + edgeDelimiter + edgeIDEscaper.Replace(string(targetID)))
+}
+
+// ParseEdgeID splits an edge ID created by NewEdgeID back into its endpoints
+func ParseEdgeID(edgeID EdgeID) (AgentID, AgentID, error) {
+	s := string(edgeID)
+
+	var source strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", fmt.Errorf("invalid edge ID %q: trailing escape", edgeID)
+			}
+			i++
+			source.WriteByte(s[i])
+		case '>':
+			// Unescaped '>' terminates the delimiter; its '-' was already written to source
+			src := source.String()
+			if !strings.HasSuffix(src, "-") {
+				return "", "", fmt.Errorf("invalid edge ID %q: expected %q", edgeID, edgeDelimiter)
+			}
+			target, err := unescapeEdgeEndpoint(s[i+1:])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid edge ID %q: %w", edgeID, err)
+			}
+			sourceID, targetID := AgentID(strings.TrimSuffix(src, "-")), AgentID(target)
+			if NewEdgeID(sourceID, targetID) != edgeID {
+				// Not produced by NewEdgeID, e.g. an escaped '-' before the delimiter
+				return "", "", fmt.Errorf("invalid edge ID %q: non-canonical encoding", edgeID)
+			}
+			return sourceID, targetID, nil
+		default:
+			source.WriteByte(s[i])
+		}
+	}
+
+	return "", "", fmt.Errorf("invalid edge ID %q: missing %q", edgeID, edgeDelimiter)
+}
+
+// unescapeEdgeEndpoint reverses the escaping of the target half of an edge ID
+func unescapeEdgeEndpoint(s string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 == len(s) {
+				return "", fmt.Errorf("trailing escape")
+			}
+			i++
+			out.WriteByte(s[i])
+		case '>':
+			return "", fmt.Errorf("unescaped '>' in target")
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// EmbeddingText is the text of an insight that is embedded
+func (i *Insight) EmbeddingText() string {
+	return i.Topic + "\n" + i.Content
+}
+
+// ContentHash identifies a text in the embedding cache
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// EmbeddingStatus is the model stored insights are embedded with and the
+// progress of re-embedding them after the provider or model changed
+type EmbeddingStatus struct {
+	Model     string              `json:"model"` // "" until the first migration finished
+	Migration *EmbeddingMigration `json:"migration,omitempty"`
+}
+
+// EmbeddingMigration re-embeds the stored insights with a new model
+type EmbeddingMigration struct {
+	From       string     `json:"from,omitempty"`
+	To         string     `json:"to"`
+	Total      int        `json:"total"`
+	Done       int        `json:"done"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"` // Why it stopped; it resumes on the next start
+}
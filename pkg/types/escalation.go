@@ -0,0 +1,130 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EscalationAction is what an escalation step does with an expired proposal
+type EscalationAction string
+
+const (
+	EscalationRepropose EscalationAction = "repropose" // Propose again to a wider electorate
+	EscalationNotify    EscalationAction = "notify"    // Notify humans through the proposal.escalated webhook
+	EscalationDefault   EscalationAction = "default"   // Apply a default decision
+)
+
+// EscalationStep is one step of an escalation chain
+type EscalationStep struct {
+	Action     EscalationAction `json:"action"`
+	Electorate []string         `json:"electorate,omitempty"` // repropose: roles added to the electorate, empty = every agent
+	Timeout    string           `json:"timeout,omitempty"`    // repropose: voting window, default PROPOSAL_TIMEOUT
+	Decision   ProposalStatus   `json:"decision,omitempty"`   // default: accepted or rejected
+}
+
+// EscalationPolicies maps proposal types to the escalation chain applied when
+// their proposals expire without quorum. Each expiry runs the next step; notify
+// steps run together with the step that follows them.
+type EscalationPolicies map[ProposalType][]EscalationStep
+
+// EscalationRecord is an entry in a proposal's escalation history
+type EscalationRecord struct {
+	Step       int              `json:"step"` // Index into the chain
+	Action     EscalationAction `json:"action"`
+	ProposalID ProposalID       `json:"proposal_id"`          // Proposal that expired
+	NewID      ProposalID       `json:"new_id,omitempty"`     // repropose: the follow-up proposal
+	Electorate []string         `json:"electorate,omitempty"` // repropose: electorate of the follow-up
+	Decision   ProposalStatus   `json:"decision,omitempty"`   // default: decision applied
+	Notified   int              `json:"notified,omitempty"`   // notify: webhooks notified
+	At         time.Time        `json:"at"`
+}
+
+// ParseEscalationPolicies decodes and validates policies given as JSON, e.g.
+// {"decision": [{"action": "repropose", "timeout": "2m"}, {"action": "notify"}, {"action": "default", "decision": "rejected"}]}
+func ParseEscalationPolicies(data string) (EscalationPolicies, error) {
+	var policies EscalationPolicies
+	if err := json.Unmarshal([]byte(data), &policies); err != nil {
+		return nil, fmt.Errorf("invalid escalation policies: %w", err)
+	}
+	if err := policies.Validate(); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Validate checks every step of every chain
+func (p EscalationPolicies) Validate() error {
+	for proposalType, steps := range p {
+		for i, step := range steps {
+			switch step.Action {
+			case EscalationRepropose:
+				if step.Timeout != "" {
+					if timeout, err := time.ParseDuration(step.Timeout); err != nil || timeout <= 0 {
+						return fmt.Errorf("%s escalation step %d: invalid timeout %q", proposalType, i, step.Timeout)
+					}
+				}
+			case EscalationNotify:
+			case EscalationDefault:
+				if step.Decision != ProposalStatusAccepted && step.Decision != ProposalStatusRejected {
+					return fmt.Errorf("%s escalation step %d: decision must be accepted or rejected", proposalType, i)
+				}
+			default:
+				return fmt.Errorf("%s escalation step %d: unknown action %q", proposalType, i, step.Action)
+			}
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the step's voting window, or fallback if unset
+func (s EscalationStep) TimeoutDuration(fallback time.Duration) time.Duration {
+	if timeout, err := time.ParseDuration(s.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return fallback
+}
+
+// WidenElectorate returns the union of two electorates; an empty electorate
+// means every agent, so it absorbs any other
+func WidenElectorate(current, added []string) []string {
+	if len(current) == 0 || len(added) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var widened []string
+	for _, role := range append(append([]string{}, current...), added...) {
+		if !seen[role] {
+			seen[role] = true
+			widened = append(widened, role)
+		}
+	}
+	return widened
+}
+
+// Eligible reports whether an agent with a role may vote on the proposal
+func (p *Proposal) Eligible(role string) bool {
+	if len(p.Electorate) == 0 {
+		return true
+	}
+	for _, r := range p.Electorate {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordEscalation appends to the proposal's escalation history (thread-safe)
+func (p *Proposal) RecordEscalation(record EscalationRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Escalations = append(p.Escalations, record)
+}
+
+// EscalationHistory returns a copy of the escalation history (thread-safe)
+func (p *Proposal) EscalationHistory() []EscalationRecord {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]EscalationRecord(nil), p.Escalations...)
+}
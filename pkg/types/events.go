@@ -0,0 +1,99 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EncodeTopologyEvent encodes a topology event for the topology topic. The
+// TopologyEvent JSON is the one envelope of the topic: every producer writes
+// it and every consumer reads it through DecodeTopologyEvent.
+func EncodeTopologyEvent(event TopologyEvent) ([]byte, error) {
+	StampProtocol(&event.ProtocolVersion, &event.MinProtocolVersion)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// legacyTopologyEvent is a topology event as early producers wrote it: a
+// Message whose payload holds the event, or its fields, often as JSON strings
+type legacyTopologyEvent struct {
+	TopologyEvent
+	FromAgentID AgentID        `json:"from_agent_id"`
+	Payload     map[string]any `json:"payload"`
+}
+
+// DecodeTopologyEvent decodes a record of the topology topic, accepting the
+// Message-wrapped events of early producers as well
+func DecodeTopologyEvent(data []byte) (TopologyEvent, error) {
+	var record legacyTopologyEvent
+	if err := json.Unmarshal(data, &record); err != nil {
+		return TopologyEvent{}, fmt.Errorf("failed to unmarshal topology event: %w", err)
+	}
+	event := record.TopologyEvent
+	if record.Payload != nil {
+		var err error
+		if event, err = record.unwrap(); err != nil {
+			return TopologyEvent{}, err
+		}
+	}
+	if event.Type == "" {
+		return TopologyEvent{}, fmt.Errorf("topology event has no type")
+	}
+	return event, nil
+}
+
+// unwrap extracts the event from a Message-wrapped record
+func (r *legacyTopologyEvent) unwrap() (TopologyEvent, error) {
+	if nested, ok := r.Payload["event"]; ok {
+		var event TopologyEvent
+		if err := decodeLegacyField(nested, &event); err != nil {
+			return TopologyEvent{}, fmt.Errorf("invalid wrapped topology event: %w", err)
+		}
+		if event.Timestamp.IsZero() {
+			event.Timestamp = r.Timestamp
+		}
+		return event, nil
+	}
+
+	event := TopologyEvent{
+		Type:               r.Type,
+		AgentID:            r.FromAgentID,
+		Timestamp:          r.Timestamp,
+		ProtocolVersion:    r.ProtocolVersion,
+		MinProtocolVersion: r.MinProtocolVersion,
+	}
+	if eventType, ok := r.Payload["type"].(string); ok {
+		event.Type = TopologyEventType(eventType)
+	}
+	if agentID, ok := r.Payload["agent_id"].(string); ok {
+		event.AgentID = AgentID(agentID)
+	}
+	if edgeID, ok := r.Payload["edge_id"].(string); ok {
+		event.EdgeID = EdgeID(edgeID)
+	}
+	if agent, ok := r.Payload["agent"]; ok {
+		event.Agent = &Agent{}
+		if err := decodeLegacyField(agent, event.Agent); err != nil {
+			return TopologyEvent{}, fmt.Errorf("invalid agent in topology event: %w", err)
+		}
+		if event.AgentID == "" {
+			event.AgentID = event.Agent.ID
+		}
+	}
+	return event, nil
+}
+
+// decodeLegacyField decodes a payload field holding an object or its JSON string
+func decodeLegacyField(value any, into any) error {
+	if s, ok := value.(string); ok {
+		return json.Unmarshal([]byte(s), into)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
@@ -0,0 +1,272 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GoalID is a unique identifier for a mesh objective
+type GoalID string
+
+// InsightTypeGoalProgress is an insight carrying a measurement towards a goal.
+// Its Data holds a GoalProgress (see NewGoalProgressInsight).
+const InsightTypeGoalProgress InsightType = "goal_progress"
+
+// Goal is an operator-defined objective the mesh works towards,
+// e.g. "resolve support tickets < 1h" or "keep stockouts < 2%"
+type Goal struct {
+	ID          GoalID    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Metric      string    `json:"metric"`      // e.g. "ticket_resolution_minutes"
+	Comparator  string    `json:"comparator"`  // "<", "<=", ">" or ">="
+	Target      float64   `json:"target"`      // Value the aggregate is compared against
+	Aggregation string    `json:"aggregation"` // "avg" (default), "min", "max", "sum" or "last"
+	Window      string    `json:"window"`      // Evaluation window, e.g. "1h" (default "24h")
+	Owner       string    `json:"owner,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GoalProgress is a single measurement an agent reports towards a goal
+type GoalProgress struct {
+	GoalID     GoalID    `json:"goal_id"`
+	AgentID    AgentID   `json:"agent_id"`
+	AgentRole  string    `json:"agent_role,omitempty"`
+	Value      float64   `json:"value"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// GoalState summarizes whether a goal is being met
+type GoalState string
+
+const (
+	GoalStateOnTrack  GoalState = "on_track"  // Target met with margin
+	GoalStateAtRisk   GoalState = "at_risk"   // Target met, but within 10% of it
+	GoalStateOffTrack GoalState = "off_track" // Target missed
+	GoalStateNoData   GoalState = "no_data"   // Nothing reported in the window
+)
+
+// goalRiskMargin is how close to the target (relative) an aggregate may be before the goal is at risk
+const goalRiskMargin = 0.1
+
+// GoalContributor summarizes one agent's reports within the window
+type GoalContributor struct {
+	AgentID      AgentID   `json:"agent_id"`
+	AgentRole    string    `json:"agent_role,omitempty"`
+	Samples      int       `json:"samples"`
+	Average      float64   `json:"average"`
+	LastValue    float64   `json:"last_value"`
+	LastReported time.Time `json:"last_reported"`
+}
+
+// GoalStatus is a goal together with its evaluation
+type GoalStatus struct {
+	Goal         Goal              `json:"goal"`
+	State        GoalState         `json:"state"`
+	Current      *float64          `json:"current,omitempty"` // Aggregate over the window
+	Samples      int               `json:"samples"`
+	Contributors []GoalContributor `json:"contributors"`
+	EvaluatedAt  time.Time         `json:"evaluated_at"`
+}
+
+// NewGoalID generates a goal ID from a name
+func NewGoalID(name string) GoalID {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, name)
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "goal"
+	}
+	return GoalID(fmt.Sprintf("%s-%d", slug, time.Now().UnixNano()%1e6))
+}
+
+// Validate checks the goal definition and fills in defaults
+func (g *Goal) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("goal name is required")
+	}
+	if !tokenPattern.MatchString(g.Metric) {
+		return fmt.Errorf("invalid metric %q", g.Metric)
+	}
+	switch g.Comparator {
+	case "<", "<=", ">", ">=":
+	default:
+		return fmt.Errorf("invalid comparator %q (use <, <=, > or >=)", g.Comparator)
+	}
+	if g.Aggregation == "" {
+		g.Aggregation = "avg"
+	}
+	switch g.Aggregation {
+	case "avg", "min", "max", "sum", "last":
+	default:
+		return fmt.Errorf("invalid aggregation %q", g.Aggregation)
+	}
+	if g.Window == "" {
+		g.Window = "24h"
+	}
+	if window, err := time.ParseDuration(g.Window); err != nil || window <= 0 {
+		return fmt.Errorf("invalid window %q", g.Window)
+	}
+	return nil
+}
+
+// WindowDuration returns the evaluation window, defaulting to 24h
+func (g *Goal) WindowDuration() time.Duration {
+	window, err := time.ParseDuration(g.Window)
+	if err != nil || window <= 0 {
+		return 24 * time.Hour
+	}
+	return window
+}
+
+// Evaluate aggregates the progress reported within the goal's window
+func (g *Goal) Evaluate(progress []GoalProgress, now time.Time) GoalStatus {
+	status := GoalStatus{
+		Goal:         *g,
+		State:        GoalStateNoData,
+		Contributors: []GoalContributor{},
+		EvaluatedAt:  now,
+	}
+
+	since := now.Add(-g.WindowDuration())
+	samples := make([]GoalProgress, 0, len(progress))
+	for _, p := range progress {
+		if p.GoalID == g.ID && !p.ReportedAt.Before(since) && !p.ReportedAt.After(now) {
+			samples = append(samples, p)
+		}
+	}
+	if len(samples) == 0 {
+		return status
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].ReportedAt.Before(samples[j].ReportedAt) })
+
+	current := aggregate(g.Aggregation, samples)
+	status.Current = &current
+	status.Samples = len(samples)
+	status.State = g.classify(current)
+	status.Contributors = contributors(samples)
+	return status
+}
+
+// classify compares an aggregate against the target
+func (g *Goal) classify(value float64) GoalState {
+	var met bool
+	switch g.Comparator {
+	case "<":
+		met = value < g.Target
+	case "<=":
+		met = value <= g.Target
+	case ">":
+		met = value > g.Target
+	case ">=":
+		met = value >= g.Target
+	}
+	if !met {
+		return GoalStateOffTrack
+	}
+
+	margin := math.Abs(g.Target) * goalRiskMargin
+	if math.Abs(g.Target-value) <= margin {
+		return GoalStateAtRisk
+	}
+	return GoalStateOnTrack
+}
+
+// aggregate reduces samples (sorted by time) to a single value
+func aggregate(method string, samples []GoalProgress) float64 {
+	switch method {
+	case "last":
+		return samples[len(samples)-1].Value
+	case "min", "max":
+		result := samples[0].Value
+		for _, s := range samples[1:] {
+			if (method == "min" && s.Value < result) || (method == "max" && s.Value > result) {
+				result = s.Value
+			}
+		}
+		return result
+	}
+
+	sum := 0.0
+	for _, s := range samples {
+		sum += s.Value
+	}
+	if method == "sum" {
+		return sum
+	}
+	return sum / float64(len(samples))
+}
+
+// contributors groups samples (sorted by time) by reporting agent, most active first
+func contributors(samples []GoalProgress) []GoalContributor {
+	byAgent := make(map[AgentID]*GoalContributor)
+	totals := make(map[AgentID]float64)
+	for _, s := range samples {
+		c, ok := byAgent[s.AgentID]
+		if !ok {
+			c = &GoalContributor{AgentID: s.AgentID, AgentRole: s.AgentRole}
+			byAgent[s.AgentID] = c
+		}
+		c.Samples++
+		c.LastValue = s.Value
+		c.LastReported = s.ReportedAt
+		totals[s.AgentID] += s.Value
+	}
+
+	result := make([]GoalContributor, 0, len(byAgent))
+	for id, c := range byAgent {
+		c.Average = totals[id] / float64(c.Samples)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Samples != result[j].Samples {
+			return result[i].Samples > result[j].Samples
+		}
+		return result[i].AgentID < result[j].AgentID
+	})
+	return result
+}
+
+// NewGoalProgressInsight creates a typed insight reporting progress towards a goal
+func NewGoalProgressInsight(agentID AgentID, agentRole string, goalID GoalID, value float64) *Insight {
+	insight := NewInsight(agentID, agentRole, InsightTypeGoalProgress, "goal:"+string(goalID),
+		fmt.Sprintf("Progress towards %s: %g", goalID, value), 1.0)
+	insight.Data["goal_id"] = string(goalID)
+	insight.Data["value"] = value
+	return insight
+}
+
+// GoalProgress extracts the goal measurement from a goal_progress insight
+func (i *Insight) GoalProgress() (GoalProgress, error) {
+	if i.Type != InsightTypeGoalProgress {
+		return GoalProgress{}, fmt.Errorf("insight %s is not a goal progress report", i.ID)
+	}
+
+	goalID, _ := i.Data["goal_id"].(string)
+	if goalID == "" {
+		return GoalProgress{}, fmt.Errorf("insight %s has no goal_id", i.ID)
+	}
+	value, ok := i.Data["value"].(float64)
+	if !ok || math.IsNaN(value) || math.IsInf(value, 0) {
+		return GoalProgress{}, fmt.Errorf("insight %s has no numeric value", i.ID)
+	}
+
+	return GoalProgress{
+		GoalID:     GoalID(goalID),
+		AgentID:    i.AgentID,
+		AgentRole:  i.AgentRole,
+		Value:      value,
+		ReportedAt: i.CreatedAt,
+	}, nil
+}
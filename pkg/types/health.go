@@ -0,0 +1,149 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HealthStatus is the state of a health check, or of the whole system
+type HealthStatus string
+
+const (
+	HealthOK       HealthStatus = "ok"
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+// worse returns the more severe of two statuses
+func (s HealthStatus) worse(other HealthStatus) HealthStatus {
+	rank := map[HealthStatus]int{HealthOK: 0, HealthDegraded: 1, HealthDown: 2}
+	if rank[other] > rank[s] {
+		return other
+	}
+	return s
+}
+
+// ManagerServices are the services of which exactly one instance must be active
+var ManagerServices = []string{"topology-manager", "knowledge-manager", "consensus-manager"}
+
+// ServiceHealth is the health a service instance reports to Redis periodically.
+// Reports expire, so instances that stopped reporting drop out of the rollup.
+type ServiceHealth struct {
+	Service     string           `json:"service"`                // e.g. "topology-manager"
+	Instance    string           `json:"instance"`               // host:pid
+	Active      bool             `json:"active"`                 // Doing the service's work; false once its state was handed off
+	KafkaError  string           `json:"kafka_error,omitempty"`  // Why the brokers were unreachable, empty when reachable
+	ConsumerLag map[string]int64 `json:"consumer_lag,omitempty"` // Messages behind per consumer group
+	ClockOffset time.Duration    `json:"clock_offset"`           // How far the instance's clock is ahead of Redis's
+	StartedAt   time.Time        `json:"started_at"`
+	ReportedAt  time.Time        `json:"reported_at"`
+
+	// Per topic, handlers still running when their context ended, since the start
+	HandlerCancellations map[string]int64 `json:"handler_cancellations,omitempty"`
+}
+
+// HealthCheck is one check of the system health rollup
+type HealthCheck struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+}
+
+// SystemHealth rolls the health of every service up into one status, the worst of its checks
+type SystemHealth struct {
+	Status    HealthStatus    `json:"status"`
+	Checks    []HealthCheck   `json:"checks"`
+	Services  []ServiceHealth `json:"services"`
+	Incident  *Incident       `json:"incident,omitempty"` // Active incident mode, if any
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// HealthProbes are the checks the aggregating API server makes itself
+type HealthProbes struct {
+	RedisError     error      // Redis unreachable
+	KafkaError     error      // Kafka unreachable
+	LastSnapshotAt *time.Time // Latest topology snapshot, nil if none
+}
+
+// RollupHealth builds the system health from the probes and the service reports
+func RollupHealth(config *Config, probes HealthProbes, services []ServiceHealth, now time.Time) SystemHealth {
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Service != services[j].Service {
+			return services[i].Service < services[j].Service
+		}
+		return services[i].Instance < services[j].Instance
+	})
+	health := SystemHealth{Status: HealthOK, Checks: []HealthCheck{}, Services: services, CheckedAt: now}
+	add := func(name string, status HealthStatus, detail string) {
+		health.Checks = append(health.Checks, HealthCheck{Name: name, Status: status, Detail: detail})
+		health.Status = health.Status.worse(status)
+	}
+
+	if probes.RedisError != nil {
+		add("redis", HealthDown, probes.RedisError.Error())
+	} else {
+		add("redis", HealthOK, "")
+	}
+
+	// Kafka as seen from the API server and from every service
+	var unreachable []string
+	for _, s := range services {
+		if s.KafkaError != "" {
+			unreachable = append(unreachable, s.Service+"@"+s.Instance)
+		}
+	}
+	switch {
+	case probes.KafkaError != nil:
+		add("kafka", HealthDown, probes.KafkaError.Error())
+	case len(unreachable) > 0:
+		add("kafka", HealthDegraded, "unreachable from "+strings.Join(unreachable, ", "))
+	default:
+		add("kafka", HealthOK, "")
+	}
+
+	// Exactly one active instance per manager; two during a handoff is transient
+	for _, service := range ManagerServices {
+		active := 0
+		for _, s := range services {
+			if s.Service == service && s.Active {
+				active++
+			}
+		}
+		switch {
+		case active == 0:
+			add(service, HealthDown, "no active instance reporting")
+		case active > 1:
+			add(service, HealthDegraded, fmt.Sprintf("%d active instances", active))
+		default:
+			add(service, HealthOK, "")
+		}
+	}
+
+	var lagging []string
+	for _, s := range services {
+		for group, lag := range s.ConsumerLag {
+			if config.HealthMaxConsumerLag > 0 && lag > config.HealthMaxConsumerLag {
+				lagging = append(lagging, fmt.Sprintf("%s is %d messages behind", group, lag))
+			}
+		}
+	}
+	sort.Strings(lagging)
+	if len(lagging) > 0 {
+		add("consumer_lag", HealthDegraded, strings.Join(lagging, "; "))
+	} else {
+		add("consumer_lag", HealthOK, "")
+	}
+
+	switch {
+	case probes.LastSnapshotAt == nil:
+		add("topology_snapshot", HealthDegraded, "no snapshot")
+	case config.HealthMaxSnapshotAge > 0 && now.Sub(*probes.LastSnapshotAt) > config.HealthMaxSnapshotAge:
+		add("topology_snapshot", HealthDegraded, fmt.Sprintf("last snapshot %s old", now.Sub(*probes.LastSnapshotAt).Round(time.Second)))
+	default:
+		add("topology_snapshot", HealthOK, "")
+	}
+
+	return health
+}
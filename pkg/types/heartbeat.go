@@ -0,0 +1,18 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewHeartbeat creates the message an agent publishes on the heartbeats topic
+// to show it is alive
+func NewHeartbeat(agentID AgentID, at time.Time) *Message {
+	return &Message{
+		ID:          fmt.Sprintf("heartbeat-%s-%d", agentID, at.UnixNano()),
+		FromAgentID: agentID,
+		Type:        MessageTypeHeartbeat,
+		Payload:     map[string]any{},
+		Timestamp:   at,
+	}
+}
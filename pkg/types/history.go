@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// TopologyHistoryPoint is a timestamped topology snapshot listed by GET
+// /api/topology/history, with its stats but without per-agent centrality
+type TopologyHistoryPoint struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Sequence  uint64     `json:"sequence,omitempty"`
+	Stats     GraphStats `json:"stats"`
+}
+
+// HistoryPoint summarizes a snapshot as a point of the topology's history
+func (s *GraphSnapshot) HistoryPoint() TopologyHistoryPoint {
+	stats := s.Stats
+	stats.Centrality = nil
+	return TopologyHistoryPoint{Timestamp: s.Timestamp, Sequence: s.Sequence, Stats: stats}
+}
@@ -0,0 +1,343 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// InsightTypeDecisionOutcome is an insight measuring the effect of an
+	// accepted proposal. Its Data holds the proposal_id, metric and value.
+	InsightTypeDecisionOutcome InsightType = "decision_outcome"
+
+	// InsightTypeDecisionImpact reports whether a decision achieved its intent once
+	// its evaluation window closed. Its Data holds the proposal_id, proposer_id and verdict.
+	InsightTypeDecisionImpact InsightType = "decision_impact"
+)
+
+// DecisionIntent is the measurable effect an accepted proposal is meant to
+// have, stated in its content under "intent", e.g.
+// {"metric": "stockout_rate", "comparator": "<", "target": 0.02, "window": "24h"}
+type DecisionIntent struct {
+	Metric      string  `json:"metric"`
+	Comparator  string  `json:"comparator"`            // "<", "<=", ">" or ">="
+	Target      float64 `json:"target"`                // Value the aggregate is compared against
+	Aggregation string  `json:"aggregation,omitempty"` // "avg" (default), "min", "max", "sum" or "last"
+	Window      string  `json:"window,omitempty"`      // Time after the decision to measure, default "24h"
+}
+
+// DecisionOutcome is one measurable result linked to an accepted proposal:
+// an insight tagged with the proposal ID or a task sent with it
+type DecisionOutcome struct {
+	ProposalID ProposalID `json:"proposal_id"`
+	Source     string     `json:"source"` // "insight" or "task"
+	InsightID  InsightID  `json:"insight_id,omitempty"`
+	TaskID     string     `json:"task_id,omitempty"`
+	AgentID    AgentID    `json:"agent_id"`
+	AgentRole  string     `json:"agent_role,omitempty"`
+	Metric     string     `json:"metric,omitempty"`
+	Value      *float64   `json:"value,omitempty"`   // Measurement of Metric
+	Success    *bool      `json:"success,omitempty"` // Task result
+	ReportedAt time.Time  `json:"reported_at"`
+}
+
+// Decision outcome sources
+const (
+	DecisionOutcomeInsight = "insight"
+	DecisionOutcomeTask    = "task"
+)
+
+// DecisionVerdict says whether a decision achieved its intent
+type DecisionVerdict string
+
+const (
+	DecisionVerdictAchieved    DecisionVerdict = "achieved"     // Intent met when the window closed
+	DecisionVerdictNotAchieved DecisionVerdict = "not_achieved" // Intent missed when the window closed
+	DecisionVerdictPending     DecisionVerdict = "pending"      // Window still open
+	DecisionVerdictNoData      DecisionVerdict = "no_data"      // Window closed without measurements
+	DecisionVerdictNoIntent    DecisionVerdict = "no_intent"    // Proposal states no measurable intent
+)
+
+// Final reports whether the verdict can no longer change
+func (v DecisionVerdict) Final() bool {
+	return v == DecisionVerdictAchieved || v == DecisionVerdictNotAchieved || v == DecisionVerdictNoData
+}
+
+// DecisionImpact reports the measured effect of an accepted proposal
+type DecisionImpact struct {
+	ProposalID     ProposalID        `json:"proposal_id"`
+	ProposerID     AgentID           `json:"proposer_id"`
+	Type           ProposalType      `json:"type"`
+	Description    string            `json:"description,omitempty"`
+	DecidedAt      time.Time         `json:"decided_at"`
+	Intent         *DecisionIntent   `json:"intent,omitempty"`
+	Deadline       *time.Time        `json:"deadline,omitempty"` // End of the evaluation window
+	Verdict        DecisionVerdict   `json:"verdict"`
+	Current        *float64          `json:"current,omitempty"` // Aggregate of the measurements
+	Samples        int               `json:"samples"`
+	Tasks          int               `json:"tasks"`
+	TasksSucceeded int               `json:"tasks_succeeded"`
+	Outcomes       []DecisionOutcome `json:"outcomes"`
+	Stakes         []StakeSettlement `json:"stakes,omitempty"` // How the stakes on it settle by the verdict, with VOTE_STAKES
+	EvaluatedAt    time.Time         `json:"evaluated_at"`
+}
+
+// DecisionRecord is a proposer's track record of accepted proposals
+type DecisionRecord struct {
+	ProposerID  AgentID `json:"proposer_id"`
+	Decisions   int     `json:"decisions"`
+	Achieved    int     `json:"achieved"`
+	NotAchieved int     `json:"not_achieved"`
+	Pending     int     `json:"pending"`
+	SuccessRate float64 `json:"success_rate"` // Achieved share of decisions with a final verdict
+}
+
+// Intent returns the proposal's stated intent, or nil if it has none
+func (p *Proposal) Intent() (*DecisionIntent, error) {
+	raw, ok := p.Content["intent"].(map[string]any)
+	if !ok {
+		if _, present := p.Content["intent"]; present {
+			return nil, fmt.Errorf("proposal %s intent must be an object", p.ID)
+		}
+		return nil, nil
+	}
+
+	intent := &DecisionIntent{}
+	intent.Metric, _ = raw["metric"].(string)
+	intent.Comparator, _ = raw["comparator"].(string)
+	intent.Aggregation, _ = raw["aggregation"].(string)
+	intent.Window, _ = raw["window"].(string)
+	target, ok := raw["target"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("proposal %s intent has no numeric target", p.ID)
+	}
+	intent.Target = target
+
+	if err := intent.goal(p.ID).Validate(); err != nil {
+		return nil, fmt.Errorf("proposal %s has an invalid intent: %w", p.ID, err)
+	}
+	return intent, nil
+}
+
+// goal expresses the intent as a goal so it is evaluated the same way
+func (i *DecisionIntent) goal(proposalID ProposalID) *Goal {
+	return &Goal{
+		ID:          GoalID(proposalID),
+		Name:        string(proposalID),
+		Metric:      i.Metric,
+		Comparator:  i.Comparator,
+		Target:      i.Target,
+		Aggregation: i.Aggregation,
+		Window:      i.Window,
+	}
+}
+
+// EvaluateDecision measures an accepted proposal against its intent using the
+// outcomes linked to it. Measurements of the intent's metric reported between
+// the decision and the end of the window count towards the verdict.
+func EvaluateDecision(p *Proposal, outcomes []DecisionOutcome, now time.Time) DecisionImpact {
+	decidedAt := p.DecidedAt
+	if decidedAt.IsZero() {
+		decidedAt = p.CreatedAt
+	}
+	description, _ := p.Content["description"].(string)
+
+	impact := DecisionImpact{
+		ProposalID:  p.ID,
+		ProposerID:  p.ProposerID,
+		Type:        p.Type,
+		Description: description,
+		DecidedAt:   decidedAt,
+		Verdict:     DecisionVerdictNoIntent,
+		Outcomes:    append([]DecisionOutcome{}, outcomes...),
+		EvaluatedAt: now,
+	}
+	sort.Slice(impact.Outcomes, func(i, j int) bool {
+		return impact.Outcomes[i].ReportedAt.Before(impact.Outcomes[j].ReportedAt)
+	})
+	for _, o := range impact.Outcomes {
+		if o.Success != nil {
+			impact.Tasks++
+			if *o.Success {
+				impact.TasksSucceeded++
+			}
+		}
+	}
+
+	intent, err := p.Intent()
+	if err != nil || intent == nil {
+		return impact
+	}
+	impact.Intent = intent
+
+	goal := intent.goal(p.ID)
+	goal.Validate() // Fills in defaults; Intent already validated it
+	deadline := decidedAt.Add(goal.WindowDuration())
+	impact.Deadline = &deadline
+	closed := !now.Before(deadline)
+
+	var samples []GoalProgress
+	for _, o := range impact.Outcomes {
+		if o.Metric != intent.Metric || o.Value == nil || o.ReportedAt.Before(decidedAt) || o.ReportedAt.After(deadline) {
+			continue
+		}
+		samples = append(samples, GoalProgress{GoalID: goal.ID, AgentID: o.AgentID, Value: *o.Value, ReportedAt: o.ReportedAt})
+	}
+
+	evaluateAt := now
+	if closed {
+		evaluateAt = deadline
+	}
+	status := goal.Evaluate(samples, evaluateAt)
+	impact.Current = status.Current
+	impact.Samples = status.Samples
+
+	switch {
+	case !closed:
+		impact.Verdict = DecisionVerdictPending
+	case status.State == GoalStateNoData:
+		impact.Verdict = DecisionVerdictNoData
+	case status.State == GoalStateOffTrack:
+		impact.Verdict = DecisionVerdictNotAchieved
+	default:
+		impact.Verdict = DecisionVerdictAchieved
+	}
+	return impact
+}
+
+// SummarizeDecisions builds each proposer's track record, best first
+func SummarizeDecisions(impacts []DecisionImpact) []DecisionRecord {
+	byProposer := make(map[AgentID]*DecisionRecord)
+	for _, impact := range impacts {
+		record, ok := byProposer[impact.ProposerID]
+		if !ok {
+			record = &DecisionRecord{ProposerID: impact.ProposerID}
+			byProposer[impact.ProposerID] = record
+		}
+		record.Decisions++
+		switch impact.Verdict {
+		case DecisionVerdictAchieved:
+			record.Achieved++
+		case DecisionVerdictNotAchieved, DecisionVerdictNoData:
+			record.NotAchieved++
+		case DecisionVerdictPending:
+			record.Pending++
+		}
+	}
+
+	records := make([]DecisionRecord, 0, len(byProposer))
+	for _, record := range byProposer {
+		if final := record.Achieved + record.NotAchieved; final > 0 {
+			record.SuccessRate = float64(record.Achieved) / float64(final)
+		}
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].SuccessRate != records[j].SuccessRate {
+			return records[i].SuccessRate > records[j].SuccessRate
+		}
+		return records[i].ProposerID < records[j].ProposerID
+	})
+	return records
+}
+
+// proposalTag is the insight tag linking an insight to a proposal
+func proposalTag(proposalID ProposalID) string {
+	return "proposal:" + string(proposalID)
+}
+
+// LinkedProposal returns the proposal an insight is linked to through its
+// proposal_id data or a "proposal:<id>" tag, or "" if none
+func (i *Insight) LinkedProposal() ProposalID {
+	if id, ok := i.Data["proposal_id"].(string); ok && id != "" {
+		return ProposalID(id)
+	}
+	for _, tag := range i.Tags {
+		if id, ok := strings.CutPrefix(tag, proposalTag("")); ok && id != "" {
+			return ProposalID(id)
+		}
+	}
+	return ""
+}
+
+// DecisionOutcome extracts the outcome carried by an insight linked to a
+// proposal. Insights without a numeric metric value link as evidence only.
+func (i *Insight) DecisionOutcome() (DecisionOutcome, error) {
+	proposalID := i.LinkedProposal()
+	if proposalID == "" || i.Type == InsightTypeDecisionImpact {
+		return DecisionOutcome{}, fmt.Errorf("insight %s is not linked to a proposal", i.ID)
+	}
+
+	outcome := DecisionOutcome{
+		ProposalID: proposalID,
+		Source:     DecisionOutcomeInsight,
+		InsightID:  i.ID,
+		AgentID:    i.AgentID,
+		AgentRole:  i.AgentRole,
+		ReportedAt: i.CreatedAt,
+	}
+	metric, _ := i.Data["metric"].(string)
+	value, ok := i.Data["value"].(float64)
+	if metric != "" && ok && !math.IsNaN(value) && !math.IsInf(value, 0) {
+		outcome.Metric = metric
+		outcome.Value = &value
+	}
+	return outcome, nil
+}
+
+// DecisionOutcome links a task outcome to the proposal the task carried out
+func (o TaskOutcome) DecisionOutcome() (DecisionOutcome, bool) {
+	if o.ProposalID == "" {
+		return DecisionOutcome{}, false
+	}
+	success := o.Success
+	return DecisionOutcome{
+		ProposalID: o.ProposalID,
+		Source:     DecisionOutcomeTask,
+		TaskID:     o.TaskID,
+		AgentID:    o.To,
+		Success:    &success,
+		ReportedAt: o.CompletedAt,
+	}, true
+}
+
+// NewDecisionOutcomeInsight creates a typed insight measuring the effect of a proposal
+func NewDecisionOutcomeInsight(agentID AgentID, agentRole string, proposalID ProposalID, metric string, value float64) *Insight {
+	insight := NewInsight(agentID, agentRole, InsightTypeDecisionOutcome, "decision:"+metric,
+		fmt.Sprintf("Outcome of %s: %s = %g", proposalID, metric, value), 1.0)
+	insight.Data["proposal_id"] = string(proposalID)
+	insight.Data["metric"] = metric
+	insight.Data["value"] = value
+	insight.Tags = append(insight.Tags, proposalTag(proposalID))
+	return insight
+}
+
+// NewDecisionImpactInsight creates the insight announcing a decision's final verdict
+func NewDecisionImpactInsight(agentID AgentID, impact DecisionImpact) *Insight {
+	insight := NewInsight(agentID, "", InsightTypeDecisionImpact, "decision_impact",
+		fmt.Sprintf("Decision %s by %s: %s", impact.ProposalID, impact.ProposerID, impact.Verdict), 1.0)
+	insight.Data["proposal_id"] = string(impact.ProposalID)
+	insight.Data["proposer_id"] = string(impact.ProposerID)
+	insight.Data["verdict"] = string(impact.Verdict)
+	if impact.Current != nil {
+		insight.Data["current"] = *impact.Current
+	}
+	insight.Tags = append(insight.Tags, proposalTag(impact.ProposalID))
+	return insight
+}
+
+// DecisionImpact extracts the proposer and verdict of a decision_impact insight
+func (i *Insight) DecisionImpact() (AgentID, DecisionVerdict, bool) {
+	if i.Type != InsightTypeDecisionImpact {
+		return "", "", false
+	}
+	proposer, _ := i.Data["proposer_id"].(string)
+	verdict, _ := i.Data["verdict"].(string)
+	if proposer == "" || !DecisionVerdict(verdict).Final() {
+		return "", "", false
+	}
+	return AgentID(proposer), DecisionVerdict(verdict), true
+}
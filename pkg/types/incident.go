@@ -0,0 +1,77 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Incident is mesh-wide incident mode. While it lasts, non-critical periodic
+// work (scheduled digests, simulated agents) runs only every
+// INCIDENT_SLOWDOWN-th time, topology decay is paused so edges are not pruned
+// while traffic is abnormal, and consensus traffic is never slowed.
+type Incident struct {
+	Reason      string    `json:"reason,omitempty"`
+	DeclaredBy  string    `json:"declared_by,omitempty"`
+	Automatic   bool      `json:"automatic"` // Declared on a health drop; cleared once health recovers
+	DeclaredAt  time.Time `json:"declared_at"`
+	TriggeredBy string    `json:"triggered_by,omitempty"` // Failing health checks of an automatic incident
+}
+
+// ParseIncidentAutoStatus validates the health status that declares an
+// incident automatically, empty for none
+func ParseIncidentAutoStatus(value string) (HealthStatus, error) {
+	switch status := HealthStatus(value); status {
+	case "", HealthDegraded, HealthDown:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown incident trigger %q (use degraded, down or leave it empty)", value)
+}
+
+// AutoIncident decides automatic incident mode from the system health, given
+// the current incident (nil if none) and INCIDENT_AUTO_STATUS. It returns the
+// incident to declare when health is at or below the trigger, and clear=true
+// once an automatic incident's health recovers. Manual incidents are left to
+// whoever declared them.
+func AutoIncident(current *Incident, health SystemHealth, trigger HealthStatus, now time.Time) (declare *Incident, clear bool) {
+	if trigger == "" {
+		return nil, false
+	}
+	failing := health.Status.worse(trigger) == health.Status
+	switch {
+	case current == nil && failing:
+		incident := &Incident{
+			Reason:     fmt.Sprintf("system health %s", health.Status),
+			DeclaredBy: "health",
+			Automatic:  true,
+			DeclaredAt: now,
+		}
+		for _, check := range health.Checks {
+			if check.Status != HealthOK {
+				if incident.TriggeredBy != "" {
+					incident.TriggeredBy += ", "
+				}
+				incident.TriggeredBy += check.Name
+			}
+		}
+		return incident, false
+	case current != nil && current.Automatic && !failing:
+		return nil, true
+	}
+	return nil, false
+}
+
+// IncidentThrottle slows periodic non-critical work during an incident: of
+// its ticks, only every INCIDENT_SLOWDOWN-th runs
+type IncidentThrottle struct {
+	skipped int
+}
+
+// Allow reports whether the current tick runs
+func (t *IncidentThrottle) Allow(incident bool, slowdown int) bool {
+	if !incident || slowdown <= 1 || t.skipped+1 >= slowdown {
+		t.skipped = 0
+		return true
+	}
+	t.skipped++
+	return false
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// maxInsightClockSkew is how far in the future an ingested insight may be dated
+const maxInsightClockSkew = 5 * time.Minute
+
+// Ingestion outcomes of a batch entry
+const (
+	IngestAccepted  = "accepted"  // Published to the insights topic
+	IngestDuplicate = "duplicate" // Already known, or earlier in the batch
+	IngestRejected  = "rejected"  // Invalid
+	IngestFailed    = "failed"    // Valid, but could not be published
+)
+
+// InsightBatch is the body of POST /api/insights/batch
+type InsightBatch struct {
+	Insights []Insight `json:"insights"`
+}
+
+// IngestionResult is the outcome of one entry of a batch
+type IngestionResult struct {
+	Index  int       `json:"index"` // Position in the batch
+	ID     InsightID `json:"id,omitempty"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// IngestionReport sums up a batch ingestion; Results lists every entry not accepted
+type IngestionReport struct {
+	Received   int               `json:"received"`
+	Accepted   int               `json:"accepted"`
+	Duplicates int               `json:"duplicates"`
+	Rejected   int               `json:"rejected"`
+	Failed     int               `json:"failed"`
+	Results    []IngestionResult `json:"results"`
+}
+
+// Record counts the outcome of an entry
+func (r *IngestionReport) Record(result IngestionResult) {
+	switch result.Status {
+	case IngestAccepted:
+		r.Accepted++
+		return
+	case IngestDuplicate:
+		r.Duplicates++
+	case IngestRejected:
+		r.Rejected++
+	case IngestFailed:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// Validate checks an insight published from outside an agent, e.g. backfilled
+// from another system
+func (i *Insight) Validate(now time.Time) error {
+	switch {
+	case i.AgentID == "":
+		return fmt.Errorf("agent_id is required")
+	case i.Type == "":
+		return fmt.Errorf("type is required")
+	case i.Topic == "":
+		return fmt.Errorf("topic is required")
+	case i.Content == "":
+		return fmt.Errorf("content is required")
+	case i.Confidence < 0 || i.Confidence > 1:
+		return fmt.Errorf("confidence must be between 0 and 1, got %g", i.Confidence)
+	case i.CreatedAt.After(now.Add(maxInsightClockSkew)):
+		return fmt.Errorf("created_at %s is in the future", i.CreatedAt.Format(time.RFC3339))
+	}
+	switch i.Privacy {
+	case "", InsightPrivacyPublic, InsightPrivacyPrivate:
+	case InsightPrivacyRestricted:
+		if len(i.SharedWith) == 0 {
+			return fmt.Errorf("restricted insights need shared_with")
+		}
+	default:
+		return fmt.Errorf("privacy must be public, restricted or private, got %q", i.Privacy)
+	}
+	return nil
+}
+
+// FingerprintID derives an insight ID from its author, topic, content and
+// creation time, so ingesting the same insight twice is detected as a duplicate
+func (i *Insight) FingerprintID() InsightID {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%d", i.AgentID, i.Type, i.Topic, i.Content, i.CreatedAt.UnixNano())
+	return InsightID("insight-" + hex.EncodeToString(hash.Sum(nil))[:32])
+}
+
+// PrepareInsightBatch validates a batch and fills in defaults, returning the
+// insights to publish, in order, with the rejected entries and duplicates
+// within the batch already recorded in the report
+func PrepareInsightBatch(batch []Insight, now time.Time) ([]*Insight, *IngestionReport) {
+	report := &IngestionReport{Received: len(batch), Results: []IngestionResult{}}
+	seen := make(map[InsightID]bool, len(batch))
+	var valid []*Insight
+
+	for index := range batch {
+		insight := &batch[index]
+		if err := insight.Validate(now); err != nil {
+			report.Record(IngestionResult{Index: index, ID: insight.ID, Status: IngestRejected, Error: err.Error()})
+			continue
+		}
+		if insight.CreatedAt.IsZero() {
+			insight.CreatedAt = now
+		}
+		if insight.Privacy == "" {
+			insight.Privacy = InsightPrivacyPublic
+		}
+		if insight.ID == "" {
+			insight.ID = insight.FingerprintID()
+		}
+		// Ranks, verification and origin are set by the mesh, not the publisher
+		insight.Rank, insight.Verification, insight.Region = nil, nil, ""
+
+		if seen[insight.ID] {
+			report.Record(IngestionResult{Index: index, ID: insight.ID, Status: IngestDuplicate, Error: "repeated in the batch"})
+			continue
+		}
+		seen[insight.ID] = true
+		valid = append(valid, insight)
+	}
+	return valid, report
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// EdgeLatencySamples is how many of an edge's latest message latencies its
+// percentiles are computed over
+const EdgeLatencySamples = 100
+
+// ObserveLatency records how long a message took from being published to
+// being consumed over the edge and updates the edge's rolling p50 and p99.
+// Negative latencies, from clocks out of step, count as 0.
+func (e *Edge) ObserveLatency(latency time.Duration) {
+	ms := max(0, float64(latency)/float64(time.Millisecond))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.latencies) < EdgeLatencySamples {
+		e.latencies = append(e.latencies, ms)
+	} else {
+		e.latencies[e.latencyAt] = ms
+		e.latencyAt = (e.latencyAt + 1) % EdgeLatencySamples
+	}
+
+	sorted := append([]float64(nil), e.latencies...)
+	sort.Float64s(sorted)
+	e.LatencyP50Ms = Percentile(sorted, 0.5)
+	e.LatencyP99Ms = Percentile(sorted, 0.99)
+}
+
+// LatencyFactor is the share of its strength an edge keeps for routing once
+// its median latency is weighed against the target: 1 at no latency, 0.5 at
+// the target, less beyond. Edges without samples, or a zero target, keep it all.
+func (e *Edge) LatencyFactor(target time.Duration) float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return LatencyFactor(e.LatencyP50Ms, target)
+}
+
+// LatencyFactor weighs a median latency in ms against the target, see Edge.LatencyFactor
+func LatencyFactor(latencyMs float64, target time.Duration) float64 {
+	targetMs := float64(target) / float64(time.Millisecond)
+	if targetMs <= 0 || latencyMs <= 0 {
+		return 1
+	}
+	return targetMs / (targetMs + latencyMs)
+}
+
+// Percentile returns the nearest-rank percentile of sorted values: the
+// smallest value at least a share p of them are less than or equal to
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
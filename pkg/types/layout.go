@@ -0,0 +1,8 @@
+package types
+
+// LayoutPosition is where dashboards draw an agent: coordinates in the unit
+// square, scaled to the drawing area by the client
+type LayoutPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LifecycleState is where an agent is between starting and stopping, finer
+// grained than joined/left: a joined agent may still be warming up, running
+// without a dependency, or finishing its work before it leaves
+type LifecycleState string
+
+const (
+	LifecycleStarting LifecycleState = "starting" // Joining; not taking work yet
+	LifecycleReady    LifecycleState = "ready"    // Taking work
+	LifecycleDegraded LifecycleState = "degraded" // Taking work with reduced capacity, see the reason
+	LifecycleDraining LifecycleState = "draining" // Finishing in-flight work; not taking new work
+	LifecycleStopped  LifecycleState = "stopped"
+)
+
+// LifecycleEvent reports an agent's lifecycle state change on the lifecycle topic
+type LifecycleEvent struct {
+	AgentID   AgentID        `json:"agent_id"`
+	State     LifecycleState `json:"state"`
+	Previous  LifecycleState `json:"previous,omitempty"`
+	Reason    string         `json:"reason,omitempty"` // Why, e.g. "broker unreachable, spooling"
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// AgentLifecycle is the last lifecycle state an agent reported
+type AgentLifecycle struct {
+	State  LifecycleState `json:"state"`
+	Reason string         `json:"reason,omitempty"`
+	Since  time.Time      `json:"since"`
+}
+
+// ParseLifecycleState validates a lifecycle state
+func ParseLifecycleState(value string) (LifecycleState, error) {
+	switch state := LifecycleState(value); state {
+	case LifecycleStarting, LifecycleReady, LifecycleDegraded, LifecycleDraining, LifecycleStopped:
+		return state, nil
+	}
+	return "", fmt.Errorf("unknown lifecycle state %q (use starting, ready, degraded, draining or stopped)", value)
+}
+
+// Routable reports whether new work may be routed to the agent. Agents that
+// never reported a lifecycle state are routable, as before lifecycle events,
+// unless they missed their heartbeats.
+func (a *Agent) Routable() bool {
+	if a.Status == AgentStatusOffline {
+		return false
+	}
+	if a.Lifecycle == nil {
+		return true
+	}
+	return a.Lifecycle.State == LifecycleReady || a.Lifecycle.State == LifecycleDegraded
+}
+
+// Message converts the event to the message published on the lifecycle topic
+func (e *LifecycleEvent) Message() *Message {
+	return &Message{
+		ID:          fmt.Sprintf("lifecycle-%s-%d", e.AgentID, e.Timestamp.UnixNano()),
+		FromAgentID: e.AgentID,
+		Type:        MessageTypeLifecycle,
+		Payload:     map[string]any{"event": e},
+		Timestamp:   e.Timestamp,
+	}
+}
+
+// LifecycleEventFrom decodes the lifecycle event carried by a message
+func LifecycleEventFrom(msg *Message) (*LifecycleEvent, error) {
+	data, err := json.Marshal(msg.Payload["event"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lifecycle event: %w", err)
+	}
+	var event LifecycleEvent
+	if err := json.Unmarshal(data, &event); err != nil || event.AgentID == "" {
+		return nil, fmt.Errorf("message carries no lifecycle event")
+	}
+	if _, err := ParseLifecycleState(string(event.State)); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
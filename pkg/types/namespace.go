@@ -0,0 +1,28 @@
+package types
+
+// DefaultNamespace is the namespace of agents that declare none
+const DefaultNamespace = "default"
+
+// NamespaceStats are the statistics of one namespace's sub-mesh. Edges never
+// cross namespaces, so its density is against a full mesh of its own agents.
+type NamespaceStats struct {
+	Agents           int     `json:"agents"`
+	Edges            int     `json:"edges"`
+	Density          float64 `json:"density"`
+	ReductionPercent float64 `json:"reduction_percent"`
+}
+
+// MeshNamespace returns the namespace of the agent's sub-mesh, DefaultNamespace
+// if it declares none. Teams sharing one deployment each use their own
+// namespace, and agents only form edges with agents of theirs.
+func (a *Agent) MeshNamespace() string {
+	if a.Namespace == "" {
+		return DefaultNamespace
+	}
+	return a.Namespace
+}
+
+// SameNamespace reports whether two agents belong to the same sub-mesh
+func (a *Agent) SameNamespace(other *Agent) bool {
+	return a.MeshNamespace() == other.MeshNamespace()
+}
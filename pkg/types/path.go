@@ -0,0 +1,29 @@
+package types
+
+// PathFallback is what path routing does when no pheromone path connects two agents
+type PathFallback string
+
+const (
+	PathFallbackDirect PathFallback = "direct" // Route over the direct edge, which forms on first use
+	PathFallbackNone   PathFallback = "none"   // Report that there is no path
+)
+
+// Path is a route between two agents through the topology
+type Path struct {
+	Agents   []AgentID `json:"agents"`             // Source first, target last
+	Hops     int       `json:"hops"`               // Edges along the path
+	Strength float64   `json:"strength"`           // Product of the edge weights, discounted by latency; 0 for a fallback path
+	Fallback bool      `json:"fallback,omitempty"` // No pheromone path; the direct edge forms on first use
+}
+
+// Neighborhood is the part of the topology within K hops of an agent: the
+// agents reachable over edges at or above MinWeight, in either direction, and
+// the edges among them at or above MinWeight
+type Neighborhood struct {
+	AgentID   AgentID            `json:"agent_id"`
+	K         int                `json:"k"`
+	MinWeight float64            `json:"min_weight"`
+	Hops      map[AgentID]int    `json:"hops"` // Fewest hops from the agent, 0 for itself
+	Agents    map[AgentID]*Agent `json:"agents"`
+	Edges     map[EdgeID]*Edge   `json:"edges"`
+}
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// TopologyPriors summarizes the edge strengths a topology learned, saved when
+// the topology manager shuts down to seed the edges of the next session
+// (LEARNED_PRIORS)
+type TopologyPriors struct {
+	Roles   map[string]RolePrior `json:"roles"` // By RoleEdgeKey of the agents' roles
+	Edges   map[EdgeID]float64   `json:"edges"` // Weight of each edge between the same two agents
+	SavedAt time.Time            `json:"saved_at"`
+}
+
+// RolePrior is the mean weight of the edges from agents of one role to agents
+// of another
+type RolePrior struct {
+	Weight float64 `json:"weight"`
+	Edges  int     `json:"edges"` // Edges averaged
+}
+
+// RoleEdgeKey names the edges from agents of one role to agents of another,
+// e.g. "sales->inventory"
+func RoleEdgeKey(sourceRole, targetRole string) string {
+	return sourceRole + "->" + targetRole
+}
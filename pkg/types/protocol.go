@@ -0,0 +1,97 @@
+package types
+
+// Wire protocol versions.
+//
+// Every message envelope and topology event carries the protocol version of its
+// sender plus the minimum version a receiver needs to understand it. Records
+// without a version predate negotiation and are treated as version 1.
+const (
+	// ProtocolVersion is the protocol spoken by this build
+	ProtocolVersion = 2
+
+	// MinProtocolVersion is the oldest protocol this build still accepts
+	MinProtocolVersion = 1
+
+	// legacyProtocolVersion is assumed for envelopes without a version
+	legacyProtocolVersion = 1
+)
+
+// ProtocolRelease documents what a protocol version introduced
+type ProtocolRelease struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+// ProtocolReleases lists every protocol version known to this build, oldest first
+var ProtocolReleases = []ProtocolRelease{
+	{Version: 1, Description: "Unversioned envelopes, string capabilities, framework in metadata"},
+	{Version: 2, Description: "Versioned envelopes, typed agent metadata and structured capabilities"},
+}
+
+// Compatibility describes how this build handles a peer's protocol version
+type Compatibility string
+
+const (
+	CompatibilityFull        Compatibility = "compatible"  // Fully understood
+	CompatibilityDegraded    Compatibility = "degraded"    // Newer peer; fields unknown to us are ignored
+	CompatibilityUnsupported Compatibility = "unsupported" // Rejected
+)
+
+// CheckProtocol decides whether a peer speaking version, whose messages require
+// at least minVersion to decode, can be understood by this build
+func CheckProtocol(version, minVersion int) Compatibility {
+	if version == 0 {
+		version = legacyProtocolVersion
+	}
+	if minVersion == 0 {
+		minVersion = legacyProtocolVersion
+	}
+
+	switch {
+	case version < MinProtocolVersion:
+		return CompatibilityUnsupported
+	case minVersion > ProtocolVersion:
+		// Sender uses features we cannot interpret safely
+		return CompatibilityUnsupported
+	case version > ProtocolVersion:
+		return CompatibilityDegraded
+	default:
+		return CompatibilityFull
+	}
+}
+
+// StampProtocol sets the sender protocol fields on an envelope that has none
+func StampProtocol(version, minVersion *int) {
+	if *version == 0 {
+		*version = ProtocolVersion
+	}
+	if *minVersion == 0 {
+		*minVersion = MinProtocolVersion
+	}
+}
+
+// Protocol returns the sender's protocol version, defaulting legacy messages to 1
+func (m *Message) Protocol() int {
+	if m.ProtocolVersion == 0 {
+		return legacyProtocolVersion
+	}
+	return m.ProtocolVersion
+}
+
+// Compatibility reports how this build handles the message's protocol
+func (m *Message) Compatibility() Compatibility {
+	return CheckProtocol(m.ProtocolVersion, m.MinProtocolVersion)
+}
+
+// Protocol returns the sender's protocol version, defaulting legacy events to 1
+func (e *TopologyEvent) Protocol() int {
+	if e.ProtocolVersion == 0 {
+		return legacyProtocolVersion
+	}
+	return e.ProtocolVersion
+}
+
+// Compatibility reports how this build handles the event's protocol
+func (e *TopologyEvent) Compatibility() Compatibility {
+	return CheckProtocol(e.ProtocolVersion, e.MinProtocolVersion)
+}
@@ -0,0 +1,31 @@
+package types
+
+// Matches reports whether an insight passes the query's topic, role, type,
+// confidence and time filters. Access, sharing and verification checks are
+// left to the caller.
+func (q *KnowledgeQuery) Matches(insight *Insight) bool {
+	if insight.Confidence < q.MinConfidence {
+		return false
+	}
+	if q.TimeFrom != nil && insight.CreatedAt.Before(*q.TimeFrom) {
+		return false
+	}
+	if q.TimeTo != nil && insight.CreatedAt.After(*q.TimeTo) {
+		return false
+	}
+	if len(q.Topics) > 0 && !containsString(q.Topics, insight.Topic) {
+		return false
+	}
+	if len(q.AgentTypes) > 0 && !containsString(q.AgentTypes, insight.AgentRole) {
+		return false
+	}
+	if len(q.InsightTypes) > 0 {
+		for _, t := range q.InsightTypes {
+			if t == insight.Type {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
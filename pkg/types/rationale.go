@@ -0,0 +1,104 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rationale limits keep votes small enough to persist with their proposal
+const (
+	MaxRationaleLength = 2000
+	MaxVoteFactors     = 20
+)
+
+// VoteFactor is one consideration behind a vote, e.g.
+// {"name": "margin_impact", "weight": -0.4, "detail": "discount erodes margin below 12%"}
+type VoteFactor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`           // Influence on the vote, -1 (against) to 1 (for)
+	Value  any     `json:"value,omitempty"`  // Observation the factor is based on
+	Detail string  `json:"detail,omitempty"` // Free-text explanation
+}
+
+// FactorSummary aggregates one factor across a proposal's votes
+type FactorSummary struct {
+	Name       string  `json:"name"`
+	Mentions   int     `json:"mentions"`
+	Supporting int     `json:"supporting"` // Mentions in votes for the proposal
+	Opposing   int     `json:"opposing"`   // Mentions in votes against it
+	AvgWeight  float64 `json:"avg_weight"`
+}
+
+// ValidateRationale checks a vote's rationale and factors
+func ValidateRationale(rationale string, factors []VoteFactor) error {
+	if len(rationale) > MaxRationaleLength {
+		return fmt.Errorf("vote rationale exceeds %d characters", MaxRationaleLength)
+	}
+	if len(factors) > MaxVoteFactors {
+		return fmt.Errorf("vote has more than %d factors", MaxVoteFactors)
+	}
+	for i, f := range factors {
+		if !tokenPattern.MatchString(f.Name) {
+			return fmt.Errorf("vote factor %d has an invalid name %q", i, f.Name)
+		}
+		if f.Weight < -1 || f.Weight > 1 {
+			return fmt.Errorf("vote factor %s weight %g out of range [-1, 1]", f.Name, f.Weight)
+		}
+	}
+	return nil
+}
+
+// VoteList returns the proposal's votes in the order they were cast (thread-safe)
+func (p *Proposal) VoteList() []Vote {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	votes := make([]Vote, 0, len(p.Votes))
+	for _, vote := range p.Votes {
+		votes = append(votes, vote)
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		if !votes[i].Timestamp.Equal(votes[j].Timestamp) {
+			return votes[i].Timestamp.Before(votes[j].Timestamp)
+		}
+		return votes[i].VoterID < votes[j].VoterID
+	})
+	return votes
+}
+
+// SummarizeFactors aggregates the factors cited in votes, most mentioned first
+func SummarizeFactors(votes []Vote) []FactorSummary {
+	byName := make(map[string]*FactorSummary)
+	totals := make(map[string]float64)
+	for _, vote := range votes {
+		for _, f := range vote.Factors {
+			summary, ok := byName[f.Name]
+			if !ok {
+				summary = &FactorSummary{Name: f.Name}
+				byName[f.Name] = summary
+			}
+			summary.Mentions++
+			switch {
+			case vote.EffectiveChoice() == VoteChoiceAbstain:
+			case vote.Support && (vote.ConditionMet == nil || *vote.ConditionMet):
+				summary.Supporting++
+			default:
+				summary.Opposing++
+			}
+			totals[f.Name] += f.Weight
+		}
+	}
+
+	summaries := make([]FactorSummary, 0, len(byName))
+	for name, summary := range byName {
+		summary.AvgWeight = totals[name] / float64(summary.Mentions)
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Mentions != summaries[j].Mentions {
+			return summaries[i].Mentions > summaries[j].Mentions
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
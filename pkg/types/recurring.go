@@ -0,0 +1,205 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// SchedulerAgentID is the agent ID recurring proposals are proposed under
+const SchedulerAgentID AgentID = "scheduler"
+
+// defaultCarryOver is how many previous rounds a recurring proposal carries
+const defaultCarryOver = 3
+
+// RecurringProposal is a proposal the consensus manager makes again every
+// interval, e.g. a weekly budget reallocation. Each round's content carries the
+// outcome of the previous rounds under "previous_rounds", and its number under
+// "round".
+type RecurringProposal struct {
+	Name       string         `json:"name"`
+	Every      string         `json:"every"` // Interval between rounds, e.g. "168h"
+	Type       ProposalType   `json:"type,omitempty"`
+	Content    map[string]any `json:"content,omitempty"`
+	Template   string         `json:"template,omitempty"` // Instead of type and content
+	Params     map[string]any `json:"params,omitempty"`
+	Electorate []string       `json:"electorate,omitempty"`
+	Policy     string         `json:"policy,omitempty"`     // Standing policy each accepted round replaces
+	CarryOver  int            `json:"carry_over,omitempty"` // Previous rounds carried, default 3
+}
+
+// ParseRecurringProposals decodes and validates recurring proposals given as
+// JSON, e.g. [{"name": "budget", "every": "168h", "type": "decision", "content": {"action": "reallocate_budget"}}]
+func ParseRecurringProposals(data string) ([]RecurringProposal, error) {
+	var recurring []RecurringProposal
+	if err := json.Unmarshal([]byte(data), &recurring); err != nil {
+		return nil, fmt.Errorf("invalid recurring proposals: %w", err)
+	}
+	names := make(map[string]bool, len(recurring))
+	for i := range recurring {
+		r := &recurring[i]
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("recurring proposal %s is defined twice", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return recurring, nil
+}
+
+// Validate checks the recurring proposal's name, interval and content
+func (r *RecurringProposal) Validate() error {
+	if !tokenPattern.MatchString(r.Name) {
+		return fmt.Errorf("invalid recurring proposal name %q", r.Name)
+	}
+	if every, err := time.ParseDuration(r.Every); err != nil || every < time.Minute {
+		return fmt.Errorf("recurring proposal %s: every must be a duration of 1m or more, got %q", r.Name, r.Every)
+	}
+	if r.Template == "" {
+		switch r.Type {
+		case ProposalTypeDecision, ProposalTypeAction, ProposalTypeTopology:
+		default:
+			return fmt.Errorf("recurring proposal %s: invalid proposal type %q", r.Name, r.Type)
+		}
+		if len(r.Content) == 0 {
+			return fmt.Errorf("recurring proposal %s: content or template is required", r.Name)
+		}
+	} else if r.Type != "" || r.Content != nil {
+		return fmt.Errorf("recurring proposal %s: set either a template or type and content", r.Name)
+	}
+	if r.Policy != "" && !ValidPolicyName(r.Policy) {
+		return fmt.Errorf("recurring proposal %s: invalid policy name %q", r.Name, r.Policy)
+	}
+	if r.CarryOver < 0 {
+		return fmt.Errorf("recurring proposal %s: carry_over must be 0 or more", r.Name)
+	}
+	return nil
+}
+
+// Interval returns the time between rounds
+func (r *RecurringProposal) Interval() time.Duration {
+	every, _ := time.ParseDuration(r.Every)
+	return every
+}
+
+// RoundsCarried returns how many previous rounds a round carries
+func (r *RecurringProposal) RoundsCarried() int {
+	if r.CarryOver == 0 {
+		return defaultCarryOver
+	}
+	return r.CarryOver
+}
+
+// RecurrenceState is the progress of a recurring proposal
+type RecurrenceState struct {
+	Name      string           `json:"name"`
+	Round     int              `json:"round"` // Last round proposed
+	LastRunAt time.Time        `json:"last_run_at"`
+	Rounds    []RecurringRound `json:"rounds"` // Latest rounds, oldest first
+}
+
+// RecurringRound is a round of a recurring proposal and its outcome, carried
+// into the rounds after it
+type RecurringRound struct {
+	Round      int            `json:"round"`
+	ProposalID ProposalID     `json:"proposal_id"` // Latest, if escalation re-proposed it
+	Status     ProposalStatus `json:"status"`
+	DecidedAt  time.Time      `json:"decided_at,omitempty"`
+	Content    map[string]any `json:"content"` // Without the rounds it carried
+}
+
+// Due reports whether the next round is due at now; the first round is due
+// right away
+func (s *RecurrenceState) Due(every time.Duration, now time.Time) bool {
+	return s.LastRunAt.IsZero() || !now.Before(s.LastRunAt.Add(every))
+}
+
+// Propose records the next round proposed at now, keeping the latest rounds
+func (s *RecurrenceState) Propose(proposal *Proposal, now time.Time, keep int) {
+	s.Round = proposal.Round
+	s.LastRunAt = now
+	content := maps.Clone(proposal.Content)
+	delete(content, "previous_rounds")
+	s.Rounds = append(s.Rounds, RecurringRound{
+		Round:      proposal.Round,
+		ProposalID: proposal.ID,
+		Status:     proposal.Status,
+		Content:    content,
+	})
+	if len(s.Rounds) > keep {
+		s.Rounds = s.Rounds[len(s.Rounds)-keep:]
+	}
+}
+
+// Decide records the outcome of a round's proposal, reporting whether the
+// round is still kept
+func (s *RecurrenceState) Decide(proposal *Proposal) bool {
+	for i := range s.Rounds {
+		if s.Rounds[i].Round == proposal.Round {
+			s.Rounds[i].ProposalID = proposal.ID
+			s.Rounds[i].Status = proposal.Status
+			s.Rounds[i].DecidedAt = proposal.DecidedAt
+			return true
+		}
+	}
+	return false
+}
+
+// RoundContent returns a copy of a recurring proposal's content for a round,
+// with the round number and the previous rounds, oldest first
+func RoundContent(content map[string]any, round int, previous []RecurringRound) map[string]any {
+	out := maps.Clone(content)
+	if out == nil {
+		out = map[string]any{}
+	}
+	rounds := make([]any, 0, len(previous))
+	for _, r := range previous {
+		summary := map[string]any{
+			"round":       r.Round,
+			"proposal_id": string(r.ProposalID),
+			"status":      string(r.Status),
+			"content":     r.Content,
+		}
+		if !r.DecidedAt.IsZero() {
+			summary["decided_at"] = r.DecidedAt.Format(time.RFC3339)
+		}
+		rounds = append(rounds, summary)
+	}
+	out["round"] = round
+	out["previous_rounds"] = rounds
+	return out
+}
+
+// ValidPolicyName reports whether a standing policy name is valid
+func ValidPolicyName(name string) bool {
+	return tokenPattern.MatchString(name)
+}
+
+// StandingPolicy is a decision that remains in force until a proposal for the
+// same policy passes and supersedes it
+type StandingPolicy struct {
+	Name         string         `json:"name"`
+	ProposalID   ProposalID     `json:"proposal_id"` // Accepted proposal in force
+	Type         ProposalType   `json:"type"`
+	Content      map[string]any `json:"content"`
+	InForceSince time.Time      `json:"in_force_since"`
+	Supersedes   ProposalID     `json:"supersedes,omitempty"` // Proposal previously in force
+}
+
+// NewStandingPolicy makes an accepted proposal the policy in force, superseding current (nil if none)
+func NewStandingPolicy(proposal *Proposal, current *StandingPolicy, now time.Time) *StandingPolicy {
+	policy := &StandingPolicy{
+		Name:         proposal.Policy,
+		ProposalID:   proposal.ID,
+		Type:         proposal.Type,
+		Content:      proposal.Content,
+		InForceSince: now,
+	}
+	if current != nil && current.ProposalID != proposal.ID {
+		policy.Supersedes = current.ProposalID
+	}
+	return policy
+}
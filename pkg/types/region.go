@@ -0,0 +1,54 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagRegion records whether an edge stays within a region or crosses regions
+func (e *Edge) TagRegion(source, target *Agent) {
+	e.Region, e.CrossRegion = "", false
+	if source.Region == target.Region {
+		e.Region = source.Region
+	} else {
+		e.CrossRegion = true
+	}
+}
+
+// ParseFederationPeers parses the Kafka brokers of the other regions given as
+// "region=broker1,broker2;region=broker3"
+func ParseFederationPeers(value string) (map[string][]string, error) {
+	peers := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		region, brokers, ok := strings.Cut(entry, "=")
+		region = strings.TrimSpace(region)
+		if !ok || region == "" {
+			return nil, fmt.Errorf("federation peer %q must be region=brokers", entry)
+		}
+		for _, broker := range strings.Split(brokers, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				peers[region] = append(peers[region], broker)
+			}
+		}
+		if len(peers[region]) == 0 {
+			return nil, fmt.Errorf("federation peer %s has no brokers", region)
+		}
+	}
+	return peers, nil
+}
+
+// Replicable reports whether a pushed insight should be replicated to the other
+// regions: it was published in this region, ranks at least FederationMinScore,
+// and is readable by every team and agent
+func Replicable(msg *Message, insight *Insight, config *Config) bool {
+	if insight.Region != config.Region || insight.Rank == nil || insight.Rank.Score < config.FederationMinScore {
+		return false
+	}
+	if _, restricted := msg.Payload["audience"]; restricted {
+		return false // Shared only through agreements of this region
+	}
+	return insight.Privacy == InsightPrivacyPublic && insight.RequiredAccess == "" && !insight.Unverified
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RolePolicy overrides how fast edges between agents of two roles reinforce
+// and decay; zero keeps the global value
+type RolePolicy struct {
+	ReinforcementAmount float64 `json:"reinforcement_amount,omitempty"`
+	DecayRate           float64 `json:"decay_rate,omitempty"`
+}
+
+// RolePolicies are the role policies by role pair (see RolePairKey)
+type RolePolicies map[string]RolePolicy
+
+// RolePairKey names the pair of two roles, in either order, e.g. "inventory/sales"
+func RolePairKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "/" + b
+}
+
+// ParseRolePolicies parses policies given as
+// "sales/inventory:REINFORCEMENT_AMOUNT=0.2,DECAY_RATE=0.01;fraud/analyst:REINFORCEMENT_AMOUNT=0.05",
+// each applying to edges between the two roles in both directions
+func ParseRolePolicies(value string) (RolePolicies, error) {
+	policies := make(RolePolicies)
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		pair, spec, ok := strings.Cut(entry, ":")
+		a, b, isPair := strings.Cut(pair, "/")
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		if !ok || !isPair || a == "" || b == "" {
+			return nil, fmt.Errorf("role policy %q must be role/role:REINFORCEMENT_AMOUNT=value,DECAY_RATE=value", entry)
+		}
+
+		params, err := ParseTopologyParams(spec)
+		if err != nil {
+			return nil, fmt.Errorf("role policy %s/%s: %w", a, b, err)
+		}
+		if params.InitialEdgeWeight > 0 || params.PruneThreshold > 0 {
+			return nil, fmt.Errorf("role policy %s/%s may only set REINFORCEMENT_AMOUNT and DECAY_RATE", a, b)
+		}
+		key := RolePairKey(a, b)
+		if _, duplicate := policies[key]; duplicate {
+			return nil, fmt.Errorf("role policy for %s/%s is given twice", a, b)
+		}
+		policies[key] = RolePolicy{ReinforcementAmount: params.ReinforcementAmount, DecayRate: params.DecayRate}
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("no role policies given")
+	}
+	return policies, nil
+}
+
+// For returns the policy of edges between two roles, in either direction
+func (p RolePolicies) For(a, b string) (RolePolicy, bool) {
+	policy, ok := p[RolePairKey(a, b)]
+	return policy, ok
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewResponse creates a response to a task message. The success flag and the
+// task's ID let the mesh score the route the task took.
+func NewResponse(task *Message, success bool, payload map[string]any) *Message {
+	if payload == nil {
+		payload = make(map[string]any)
+	}
+	payload["success"] = success
+
+	return &Message{
+		ID:          fmt.Sprintf("%s-%d", task.ToAgentID, time.Now().UnixNano()),
+		FromAgentID: task.ToAgentID,
+		ToAgentID:   task.FromAgentID,
+		Type:        MessageTypeResponse,
+		Payload:     payload,
+		Metadata:    make(map[string]string),
+		Timestamp:   time.Now(),
+		EdgeID:      NewEdgeID(task.ToAgentID, task.FromAgentID),
+		InReplyTo:   task.ID,
+		ReplayOf:    task.ReplayOf,
+	}
+}
+
+// Succeeded reports whether a response says its task succeeded.
+// Responses without a success flag count as successful.
+func (m *Message) Succeeded() bool {
+	success, ok := m.Payload["success"].(bool)
+	return !ok || success
+}
+
+// TaskOutcome is the result of one task sent along a route
+type TaskOutcome struct {
+	TaskID      string        `json:"task_id"`
+	From        AgentID       `json:"from"` // Agent that routed the task
+	To          AgentID       `json:"to"`   // Agent the task was routed to
+	Success     bool          `json:"success"`
+	TimedOut    bool          `json:"timed_out,omitempty"`
+	Latency     time.Duration `json:"latency"`
+	CompletedAt time.Time     `json:"completed_at"`
+	ProposalID  ProposalID    `json:"proposal_id,omitempty"` // Decision the task carried out
+}
+
+// RouteStats aggregates task outcomes for one directed route
+type RouteStats struct {
+	From         AgentID   `json:"from"`
+	To           AgentID   `json:"to"`
+	Tasks        int64     `json:"tasks"`
+	Successes    int64     `json:"successes"`
+	Failures     int64     `json:"failures"` // Includes timeouts
+	Timeouts     int64     `json:"timeouts"`
+	AvgLatencyMs float64   `json:"avg_latency_ms"` // Moving average over successful tasks
+	Value        float64   `json:"value"`          // Learned route value (0-1)
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SuccessRate returns the share of tasks that succeeded, or 0 without tasks
+func (rs RouteStats) SuccessRate() float64 {
+	if rs.Tasks == 0 {
+		return 0
+	}
+	return float64(rs.Successes) / float64(rs.Tasks)
+}
+
+// RouteCandidate is an agent a task may be routed to, with its routing score
+type RouteCandidate struct {
+	AgentID AgentID  `json:"agent_id"`
+	Role    string   `json:"role"`
+	Region  string   `json:"region,omitempty"`
+	Weight  float64  `json:"weight"`          // Pheromone weight of the edge, 0 without one
+	Value   *float64 `json:"value,omitempty"` // Learned route value, nil without outcomes
+	Load    int      `json:"load,omitempty"`  // Tasks in flight to the agent
+	Score   float64  `json:"score"`
+
+	LatencyMs float64 `json:"latency_ms,omitempty"` // Median latency of the edge, 0 without samples
+}
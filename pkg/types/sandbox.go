@@ -0,0 +1,70 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// Sandbox holds a newly joined agent without a verified attestation on
+// probation: its messages are rate-limited, its insights are marked unverified
+// and its votes are non-binding until the probation ends or an operator approves it
+type Sandbox struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"` // End of probation
+}
+
+// EnterSandbox puts a joining agent on probation if it has no verified
+// attestation; a zero probation disables the sandbox. Call after VerifyAttestations.
+func (a *Agent) EnterSandbox(probation time.Duration, now time.Time) {
+	a.Sandbox = nil
+	if probation <= 0 || a.Attested != nil {
+		return
+	}
+	a.Sandbox = &Sandbox{Since: now, Until: now.Add(probation)}
+}
+
+// Sandboxed reports whether the agent is still on probation
+func (a *Agent) Sandboxed(now time.Time) bool {
+	return a.Sandbox != nil && now.Before(a.Sandbox.Until)
+}
+
+// MessageLimiter caps the messages an agent may send per minute
+type MessageLimiter struct {
+	rate    int
+	windows map[AgentID]*messageWindow
+	mu      sync.Mutex
+}
+
+type messageWindow struct {
+	start time.Time
+	count int
+}
+
+// NewMessageLimiter creates a limiter allowing rate messages per agent per minute
+func NewMessageLimiter(rate int) *MessageLimiter {
+	return &MessageLimiter{
+		rate:    rate,
+		windows: make(map[AgentID]*messageWindow),
+	}
+}
+
+// Allow counts a message from an agent and reports whether it is within the limit
+func (l *MessageLimiter) Allow(agentID AgentID, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.windows[agentID]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		window = &messageWindow{start: now}
+		l.windows[agentID] = window
+	}
+	window.count++
+	return window.count <= l.rate
+}
+
+// Forget drops an agent's message count
+func (l *MessageLimiter) Forget(agentID AgentID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.windows, agentID)
+}
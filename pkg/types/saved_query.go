@@ -0,0 +1,49 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// WebhookEventQueryMatch is delivered to the callback of a saved query for each new matching insight
+const WebhookEventQueryMatch = "query.matched"
+
+// SavedQuery is a knowledge query a client registered with a callback URL.
+// The knowledge manager evaluates new insights against it and POSTs each
+// match to the callback, so integrations don't poll /api/insights.
+type SavedQuery struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name,omitempty"`
+	Query       KnowledgeQuery `json:"query"`            // Limit and Question are ignored
+	CallbackURL string         `json:"callback_url"`     // Receives query.matched webhook deliveries
+	Secret      string         `json:"secret,omitempty"` // Signs deliveries (X-AgentMesh-Signature)
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// QueryMatch is the data of a query.matched delivery
+type QueryMatch struct {
+	QueryID   string  `json:"query_id"`
+	QueryName string  `json:"query_name,omitempty"`
+	Insight   Insight `json:"insight"`
+}
+
+// Validate checks the saved query's callback
+func (q *SavedQuery) Validate() error {
+	u, err := url.Parse(q.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("callback_url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// Callback returns the webhook matches are delivered to
+func (q *SavedQuery) Callback() *Webhook {
+	return &Webhook{
+		ID:        q.ID,
+		URL:       q.CallbackURL,
+		Events:    []string{WebhookEventQueryMatch},
+		Secret:    q.Secret,
+		CreatedAt: q.CreatedAt,
+	}
+}
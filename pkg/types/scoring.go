@@ -0,0 +1,52 @@
+package types
+
+// EdgeScorer turns the traffic over an edge into its weight: how much a
+// message raises it and how much a decay cycle lowers it. Both are given the
+// edge's current weight, and the changes are applied within [0, 1] after
+// EDGE_CONSTRAINTS and the guardrails have capped them.
+type EdgeScorer interface {
+	// Gain returns how much a message worth amount, e.g. REINFORCEMENT_AMOUNT,
+	// raises an edge of the given weight
+	Gain(weight, amount float64) float64
+	// Loss returns how much a decay cycle at rate, e.g. DECAY_RATE, lowers an
+	// edge of the given weight
+	Loss(weight, rate float64) float64
+}
+
+// EdgeScoring names a built-in edge scorer
+type EdgeScoring string
+
+const (
+	// Each message adds the reinforcement amount and each cycle takes the
+	// decay rate away, so a burst saturates an edge at 1 however long ago it was
+	EdgeScoringAdditive EdgeScoring = "additive"
+	// The weight is an exponentially weighted moving average of traffic: each
+	// message moves it toward 1 by the reinforcement amount, each cycle toward
+	// 0 by the decay rate. Frequent recent traffic keeps an edge strong, and a
+	// burst of old traffic fades at the same pace as any other.
+	EdgeScoringEWMA EdgeScoring = "ewma"
+)
+
+// Scorer returns the built-in scorer of the name, additive if empty, or nil
+// for an unknown one
+func (s EdgeScoring) Scorer() EdgeScorer {
+	switch s {
+	case "", EdgeScoringAdditive:
+		return AdditiveScorer{}
+	case EdgeScoringEWMA:
+		return EWMAScorer{}
+	}
+	return nil
+}
+
+// AdditiveScorer is EdgeScoringAdditive, the scorer of edges without one
+type AdditiveScorer struct{}
+
+func (AdditiveScorer) Gain(weight, amount float64) float64 { return amount }
+func (AdditiveScorer) Loss(weight, rate float64) float64   { return rate }
+
+// EWMAScorer is EdgeScoringEWMA
+type EWMAScorer struct{}
+
+func (EWMAScorer) Gain(weight, amount float64) float64 { return amount * (1 - weight) }
+func (EWMAScorer) Loss(weight, rate float64) float64   { return rate * weight }
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TopologyParams are the slime-mold parameters a shadow topology runs with
+// instead of the live ones; zero keeps the live value
+type TopologyParams struct {
+	InitialEdgeWeight   float64 `json:"initial_edge_weight,omitempty"`
+	ReinforcementAmount float64 `json:"reinforcement_amount,omitempty"`
+	DecayRate           float64 `json:"decay_rate,omitempty"`
+	PruneThreshold      float64 `json:"prune_threshold,omitempty"`
+}
+
+// ParseTopologyParams parses parameters given as "DECAY_RATE=0.05,PRUNE_THRESHOLD=0.2",
+// named like the settings they override
+func ParseTopologyParams(value string) (*TopologyParams, error) {
+	params := &TopologyParams{}
+	fields := map[string]*float64{
+		"INITIAL_EDGE_WEIGHT":  &params.InitialEdgeWeight,
+		"REINFORCEMENT_AMOUNT": &params.ReinforcementAmount,
+		"DECAY_RATE":           &params.DecayRate,
+		"PRUNE_THRESHOLD":      &params.PruneThreshold,
+	}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(entry, "=")
+		field, known := fields[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok || !known {
+			return nil, fmt.Errorf("topology parameter %q must be one of INITIAL_EDGE_WEIGHT, REINFORCEMENT_AMOUNT, DECAY_RATE or PRUNE_THRESHOLD=value", entry)
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || number <= 0 || number > 1 {
+			return nil, fmt.Errorf("topology parameter %s must be above 0 and at most 1, got %q", name, raw)
+		}
+		*field = number
+	}
+	if *params == (TopologyParams{}) {
+		return nil, fmt.Errorf("no topology parameters given")
+	}
+	return params, nil
+}
+
+// TopologyParamsOf returns the slime-mold parameters of a configuration
+func TopologyParamsOf(cfg *Config) TopologyParams {
+	return TopologyParams{
+		InitialEdgeWeight:   cfg.InitialEdgeWeight,
+		ReinforcementAmount: cfg.ReinforcementAmount,
+		DecayRate:           cfg.DecayRate,
+		PruneThreshold:      cfg.PruneThreshold,
+	}
+}
+
+// Apply returns a copy of cfg with the parameters set in p
+func (p TopologyParams) Apply(cfg *Config) *Config {
+	out := *cfg
+	if p.InitialEdgeWeight > 0 {
+		out.InitialEdgeWeight = p.InitialEdgeWeight
+	}
+	if p.ReinforcementAmount > 0 {
+		out.ReinforcementAmount = p.ReinforcementAmount
+	}
+	if p.DecayRate > 0 {
+		out.DecayRate = p.DecayRate
+	}
+	if p.PruneThreshold > 0 {
+		out.PruneThreshold = p.PruneThreshold
+	}
+	return &out
+}
+
+// TopologyVariant is one side of a TopologyComparison
+type TopologyVariant struct {
+	Params TopologyParams `json:"params"`
+	Stats  GraphStats     `json:"stats"` // Without centrality
+}
+
+// TopologyComparison puts the live topology side by side with a shadow one
+// fed the same agents and messages under other parameters
+type TopologyComparison struct {
+	Live      TopologyVariant `json:"live"`
+	Shadow    TopologyVariant `json:"shadow"`
+	Since     time.Time       `json:"since"` // When the shadow started observing
+	Timestamp time.Time       `json:"timestamp"`
+}
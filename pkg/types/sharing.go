@@ -0,0 +1,137 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SharingAgreement lets one team (agent role) share insights with another,
+// e.g. sales shares pricing insights with support. Once a team provides any
+// agreement, its insights reach other teams only through agreements covering them.
+type SharingAgreement struct {
+	ID           string        `json:"id"`
+	Provider     string        `json:"provider"`                // Team whose insights are shared
+	Consumer     string        `json:"consumer"`                // Team receiving them
+	InsightTypes []InsightType `json:"insight_types,omitempty"` // Empty = all types
+	Topics       []string      `json:"topics,omitempty"`        // Empty = all topics
+	CreatedBy    string        `json:"created_by,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ExpiresAt    time.Time     `json:"expires_at,omitempty"` // Zero = no expiry
+}
+
+// SharingAuditAction is a change recorded in the sharing audit trail
+type SharingAuditAction string
+
+const (
+	SharingAuditCreated SharingAuditAction = "created"
+	SharingAuditRevoked SharingAuditAction = "revoked"
+)
+
+// SharingAuditEntry records a change to the sharing agreements
+type SharingAuditEntry struct {
+	Action    SharingAuditAction `json:"action"`
+	Agreement SharingAgreement   `json:"agreement"`
+	Actor     string             `json:"actor,omitempty"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// NewSharingAgreementID generates a new unique agreement ID
+func NewSharingAgreementID() string {
+	return fmt.Sprintf("agreement-%d", time.Now().UnixNano())
+}
+
+// Validate checks the agreement's teams
+func (a *SharingAgreement) Validate() error {
+	if a.Provider == "" || a.Consumer == "" {
+		return fmt.Errorf("provider and consumer are required")
+	}
+	if a.Provider == a.Consumer {
+		return fmt.Errorf("a team always shares with itself")
+	}
+	return nil
+}
+
+// Covers reports whether the agreement shares an insight with a team
+func (a *SharingAgreement) Covers(insight *Insight, team string, now time.Time) bool {
+	if a.Provider != insight.AgentRole || a.Consumer != team {
+		return false
+	}
+	if !a.ExpiresAt.IsZero() && now.After(a.ExpiresAt) {
+		return false
+	}
+	if len(a.InsightTypes) > 0 {
+		found := false
+		for _, t := range a.InsightTypes {
+			if t == insight.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(a.Topics) == 0 || containsString(a.Topics, insight.Topic)
+}
+
+// SharingPolicy evaluates the sharing agreements in force
+type SharingPolicy []*SharingAgreement
+
+// Governs reports whether a team's insights are shared only through agreements.
+// Expired agreements still count, so expiry never opens a team up.
+func (p SharingPolicy) Governs(team string) bool {
+	for _, a := range p {
+		if a.Provider == team {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether a team may receive an insight: its own team's insights,
+// those of teams without agreements, and those an agreement covers
+func (p SharingPolicy) Allows(insight *Insight, team string, now time.Time) bool {
+	if insight.AgentRole == team || !p.Governs(insight.AgentRole) {
+		return true
+	}
+	for _, a := range p {
+		if a.Covers(insight, team, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Audience returns the teams an insight may be pushed to, or nil if its team
+// is not governed and it may reach every team
+func (p SharingPolicy) Audience(insight *Insight, now time.Time) []string {
+	if !p.Governs(insight.AgentRole) {
+		return nil
+	}
+	audience := []string{insight.AgentRole}
+	for _, a := range p {
+		if a.Covers(insight, a.Consumer, now) {
+			audience = appendUnique(audience, a.Consumer)
+		}
+	}
+	sort.Strings(audience)
+	return audience
+}
+
+// PushedTo reports whether an insight_push message is meant for a team:
+// messages without an audience reach every team
+func PushedTo(msg *Message, team string) bool {
+	switch audience := msg.Payload["audience"].(type) {
+	case []string:
+		return containsString(audience, team)
+	case []any:
+		for _, t := range audience {
+			if t == team {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// SimulationReport is how a topology evolved when a message trace was replayed
+// offline against a set of parameters, to tune them before applying them
+type SimulationReport struct {
+	Params TopologyParams `json:"params"`
+	From   time.Time      `json:"from"`  // Time of the first replayed record
+	Until  time.Time      `json:"until"` // Time of the last replayed record
+
+	Agents   int `json:"agents"`   // Joined, announced or first seen in a message
+	Messages int `json:"messages"` // Messages that reinforced an edge
+	Cycles   int `json:"cycles"`   // Decay cycles run
+	Pruned   int `json:"pruned"`   // Edges pruned over all cycles
+
+	Stats    GraphStats             `json:"stats"`              // At the end, without centrality
+	Timeline []TopologyHistoryPoint `json:"timeline,omitempty"` // Sampled along the trace
+}
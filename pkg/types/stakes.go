@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// StakeAccount is an agent's balance of stake points, the budget units or
+// reputation points it commits to its votes when VOTE_STAKES is enabled
+type StakeAccount struct {
+	AgentID AgentID `json:"agent_id"`
+	Balance float64 `json:"balance"` // STAKE_INITIAL_BALANCE plus rewards, minus slashes
+	Locked  float64 `json:"locked"`  // Staked on votes not settled yet
+}
+
+// Available returns the points the agent can still stake
+func (a StakeAccount) Available() float64 {
+	return max(0, a.Balance-a.Locked)
+}
+
+// StakeSettlement is the outcome of a stake once the decision it was cast on
+// got its verdict: a reward if the vote was right, a slash if it was wrong
+type StakeSettlement struct {
+	AgentID AgentID `json:"agent_id"`
+	Stake   float64 `json:"stake"`
+	Correct bool    `json:"correct"`
+	Change  float64 `json:"change"` // Added to the balance, negative when slashed
+}
+
+// ValidateStake checks a vote's stake: a finite, non-negative amount that
+// abstentions cannot commit
+func ValidateStake(stake float64, choice VoteChoice) error {
+	if stake < 0 || math.IsNaN(stake) || math.IsInf(stake, 0) {
+		return fmt.Errorf("vote stake must be a non-negative number")
+	}
+	if stake > 0 && choice == VoteChoiceAbstain {
+		return fmt.Errorf("abstentions cannot stake")
+	}
+	return nil
+}
+
+// Stakes returns the stake of each vote on the proposal, for votes with one (thread-safe)
+func (p *Proposal) Stakes() map[AgentID]float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stakes := make(map[AgentID]float64)
+	for id, vote := range p.Votes {
+		if vote.Stake > 0 {
+			stakes[id] = vote.Stake
+		}
+	}
+	return stakes
+}
+
+// SettleStakes rewards the stakes on an accepted proposal that turned out
+// right and slashes the wrong ones, by a share of the stake: supporting a
+// decision that achieved its intent is right, and so is opposing one that did
+// not. Other verdicts, and stakes of non-binding votes, settle without change.
+// Settlements are ordered by agent ID.
+func SettleStakes(p *Proposal, verdict DecisionVerdict, rewardRate, slashRate float64) []StakeSettlement {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	decided := p.Status == ProposalStatusAccepted &&
+		(verdict == DecisionVerdictAchieved || verdict == DecisionVerdictNotAchieved)
+
+	settlements := []StakeSettlement{}
+	for id, vote := range p.Votes {
+		if vote.Stake <= 0 {
+			continue
+		}
+		settlement := StakeSettlement{AgentID: id, Stake: vote.Stake}
+		if decided && !vote.NonBinding {
+			settlement.Correct = p.inFavor(vote) == (verdict == DecisionVerdictAchieved)
+			if settlement.Correct {
+				settlement.Change = vote.Stake * rewardRate
+			} else {
+				settlement.Change = -vote.Stake * slashRate
+			}
+		}
+		settlements = append(settlements, settlement)
+	}
+	sort.Slice(settlements, func(i, j int) bool { return settlements[i].AgentID < settlements[j].AgentID })
+	return settlements
+}
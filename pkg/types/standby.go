@@ -0,0 +1,12 @@
+package types
+
+// IsCriticalRole reports whether agents of a role may have a warm standby,
+// see CriticalRoles
+func (c *Config) IsCriticalRole(role string) bool {
+	for _, r := range c.CriticalRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// placeholderPattern matches {{param}} placeholders in template content
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Template parameter types
+const (
+	TemplateParamString  = "string"
+	TemplateParamNumber  = "number"
+	TemplateParamBoolean = "boolean"
+)
+
+// TemplateParam is a parameter substituted into a proposal template
+type TemplateParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // "string" (default), "number" or "boolean"
+	Required    bool   `json:"required,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProposalTemplate is a reusable proposal with {{param}} placeholders in its content.
+// A string that is exactly one placeholder is replaced by the typed parameter value;
+// placeholders inside longer strings are replaced by the value's text.
+type ProposalTemplate struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Type        ProposalType    `json:"type"`
+	Content     map[string]any  `json:"content"`
+	Params      []TemplateParam `json:"params"`
+	Waggle      *WaggleDance    `json:"waggle,omitempty"` // Replaces the waggle derived from content
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Validate checks the template's type, parameters and placeholders
+func (t *ProposalTemplate) Validate() error {
+	if !tokenPattern.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q", t.Name)
+	}
+	switch t.Type {
+	case ProposalTypeDecision, ProposalTypeAction, ProposalTypeTopology:
+	default:
+		return fmt.Errorf("invalid proposal type %q", t.Type)
+	}
+	if len(t.Content) == 0 {
+		return fmt.Errorf("template content is required")
+	}
+
+	declared := make(map[string]bool)
+	for i := range t.Params {
+		p := &t.Params[i]
+		if p.Name == "" || declared[p.Name] {
+			return fmt.Errorf("template parameter names must be unique and non-empty")
+		}
+		declared[p.Name] = true
+		switch p.Type {
+		case "":
+			p.Type = TemplateParamString
+		case TemplateParamString, TemplateParamNumber, TemplateParamBoolean:
+		default:
+			return fmt.Errorf("parameter %s has invalid type %q", p.Name, p.Type)
+		}
+		if p.Default != nil {
+			value, err := p.coerce(p.Default)
+			if err != nil {
+				return fmt.Errorf("default of %s: %w", p.Name, err)
+			}
+			p.Default = value
+		}
+	}
+
+	for _, name := range placeholders(t.Content) {
+		if !declared[name] {
+			return fmt.Errorf("content uses undeclared parameter %q", name)
+		}
+	}
+
+	if w := t.Waggle; w != nil {
+		if w.Intensity < 0 || w.Intensity > 1 {
+			return fmt.Errorf("waggle intensity %g out of range [0, 1]", w.Intensity)
+		}
+		if w.Angle < 0 || w.Angle >= 360 {
+			return fmt.Errorf("waggle angle %g out of range [0, 360)", w.Angle)
+		}
+		if w.Duration < 0 || w.Repetitions < 0 {
+			return fmt.Errorf("waggle duration and repetitions must not be negative")
+		}
+	}
+	return nil
+}
+
+// Render substitutes params into a copy of the template content
+func (t *ProposalTemplate) Render(params map[string]any) (map[string]any, error) {
+	values := make(map[string]any, len(t.Params))
+	for _, p := range t.Params {
+		raw, ok := params[p.Name]
+		if !ok || raw == nil {
+			if p.Required {
+				return nil, fmt.Errorf("parameter %s is required", p.Name)
+			}
+			values[p.Name] = p.Default
+			continue
+		}
+		value, err := p.coerce(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		values[p.Name] = value
+	}
+
+	var unknown []string
+	for name := range params {
+		if _, ok := values[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameters: %s", strings.Join(unknown, ", "))
+	}
+
+	return substitute(t.Content, values).(map[string]any), nil
+}
+
+// coerce checks a parameter value against the parameter type
+func (p *TemplateParam) coerce(value any) (any, error) {
+	switch p.Type {
+	case TemplateParamString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("want a string, got %T", value)
+	case TemplateParamNumber:
+		switch n := value.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		}
+		return nil, fmt.Errorf("want a number, got %T", value)
+	case TemplateParamBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("want a boolean, got %T", value)
+	}
+	return nil, fmt.Errorf("invalid type %q", p.Type)
+}
+
+// substitute replaces placeholders in strings, maps and slices
+func substitute(node any, values map[string]any) any {
+	switch v := node.(type) {
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			return values[m[1]]
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			if values[name] == nil {
+				return ""
+			}
+			return fmt.Sprint(values[name])
+		})
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			out[key] = substitute(child, values)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = substitute(child, values)
+		}
+		return out
+	}
+	return node
+}
+
+// placeholders returns the parameter names used in content
+func placeholders(node any) []string {
+	var names []string
+	switch v := node.(type) {
+	case string:
+		for _, m := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+			names = append(names, m[1])
+		}
+	case map[string]any:
+		for _, child := range v {
+			names = append(names, placeholders(child)...)
+		}
+	case []any:
+		for _, child := range v {
+			names = append(names, placeholders(child)...)
+		}
+	}
+	return names
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InsightTier is how insights below a topic's confidence threshold are stored
+type InsightTier string
+
+const (
+	InsightTierFull   InsightTier = "full"   // Stored individually like any other
+	InsightTierSample InsightTier = "sample" // A share stored individually, all counted
+	InsightTierCount  InsightTier = "count"  // Only counted
+)
+
+// DefaultTierThreshold is the confidence below which a tiering policy applies
+// unless it sets its own
+const DefaultTierThreshold = 0.3
+
+// TieringPolicy decides how a topic's low-confidence insights are stored
+type TieringPolicy struct {
+	Tier       InsightTier `json:"tier"`
+	SampleRate float64     `json:"sample_rate,omitempty"` // Share stored individually under the sample tier
+	Threshold  float64     `json:"threshold"`             // Insights below this confidence are tiered
+}
+
+// TieringPolicies are the tiering policies by topic; "*" applies to topics
+// without their own
+type TieringPolicies map[string]TieringPolicy
+
+// ParseTieringPolicies parses policies given as "*=sample:0.1,pricing=count<0.5,fraud=full":
+// the tier of each topic's low-confidence insights, with the sample rate after
+// a colon and a confidence threshold other than 0.3 after "<"
+func ParseTieringPolicies(value string) (TieringPolicies, error) {
+	policies := make(TieringPolicies)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		topic, spec, ok := strings.Cut(entry, "=")
+		topic = strings.TrimSpace(topic)
+		if !ok || topic == "" {
+			return nil, fmt.Errorf("tiering policy %q must be topic=full, topic=count or topic=sample:rate", entry)
+		}
+
+		policy := TieringPolicy{Threshold: DefaultTierThreshold}
+		spec, threshold, hasThreshold := strings.Cut(spec, "<")
+		if hasThreshold {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(threshold), 64)
+			if err != nil || parsed <= 0 || parsed > 1 {
+				return nil, fmt.Errorf("tiering policy %q must have a confidence threshold above 0 and at most 1", entry)
+			}
+			policy.Threshold = parsed
+		}
+		tier, rate, hasRate := strings.Cut(strings.TrimSpace(spec), ":")
+		policy.Tier = InsightTier(strings.ToLower(strings.TrimSpace(tier)))
+		switch {
+		case policy.Tier == InsightTierSample && hasRate:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				return nil, fmt.Errorf("tiering policy %q must sample a share between 0 and 1", entry)
+			}
+			policy.SampleRate = parsed
+		case policy.Tier == InsightTierSample:
+			return nil, fmt.Errorf("tiering policy %q must give the share to sample, e.g. sample:0.1", entry)
+		case hasRate || (policy.Tier != InsightTierFull && policy.Tier != InsightTierCount):
+			return nil, fmt.Errorf("tiering policy %q must be topic=full, topic=count or topic=sample:rate", entry)
+		}
+		policies[topic] = policy
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("no tiering policies given")
+	}
+	return policies, nil
+}
+
+// For returns the policy of a topic, falling back to "*"
+func (p TieringPolicies) For(topic string) (TieringPolicy, bool) {
+	if policy, ok := p[topic]; ok {
+		return policy, true
+	}
+	policy, ok := p["*"]
+	return policy, ok
+}
+
+// Tier decides whether an insight is stored individually and whether it is
+// counted in its topic's summary instead. Sampling hashes the insight ID, so
+// a replayed insight gets the same decision.
+func (p TieringPolicies) Tier(insight *Insight) (store, summarize bool) {
+	policy, ok := p.For(insight.Topic)
+	if !ok || policy.Tier == InsightTierFull || insight.Confidence >= policy.Threshold {
+		return true, false
+	}
+	if policy.Tier == InsightTierCount {
+		return false, true
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(insight.ID))
+	return float64(hash.Sum64()%10000)/10000 < policy.SampleRate, true
+}
+
+// InsightTierSummary counts a topic's low-confidence insights that tiering
+// stored only in part or not at all
+type InsightTierSummary struct {
+	Topic          string                `json:"topic"`
+	Count          int64                 `json:"count"`   // All low-confidence insights
+	Sampled        int64                 `json:"sampled"` // Of which stored individually
+	MeanConfidence float64               `json:"mean_confidence"`
+	ByType         map[InsightType]int64 `json:"by_type"`
+	ByRole         map[string]int64      `json:"by_role"`
+	FirstSeen      time.Time             `json:"first_seen"`
+	LastSeen       time.Time             `json:"last_seen"`
+}
+
+// Add counts an insight in the summary
+func (s *InsightTierSummary) Add(insight *Insight, stored bool) {
+	if s.ByType == nil {
+		s.ByType = make(map[InsightType]int64)
+	}
+	if s.ByRole == nil {
+		s.ByRole = make(map[string]int64)
+	}
+	s.Count++
+	if stored {
+		s.Sampled++
+	}
+	s.MeanConfidence += (insight.Confidence - s.MeanConfidence) / float64(s.Count)
+	s.ByType[insight.Type]++
+	s.ByRole[insight.AgentRole]++
+	if s.FirstSeen.IsZero() || insight.CreatedAt.Before(s.FirstSeen) {
+		s.FirstSeen = insight.CreatedAt
+	}
+	if insight.CreatedAt.After(s.LastSeen) {
+		s.LastSeen = insight.CreatedAt
+	}
+}
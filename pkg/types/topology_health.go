@@ -0,0 +1,63 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// InsightTypeTopologyHealth is an insight reporting a pathological topology
+// state with a suggested remediation. Its Data holds the issue's fields.
+const InsightTypeTopologyHealth InsightType = "topology_health"
+
+// TopologyIssueKind names a pathological topology state
+type TopologyIssueKind string
+
+const (
+	// An edge pruned and recreated by traffic over and over
+	TopologyIssueOscillatingEdge TopologyIssueKind = "oscillating_edge"
+	// No agent of a role has a strong edge into it, so little work reaches the role
+	TopologyIssueStarvedRole TopologyIssueKind = "starved_role"
+	// One agent receives an outsized share of all edge weight
+	TopologyIssueHubOverload TopologyIssueKind = "hub_overload"
+)
+
+// TopologyIssue is a pathological topology state detected after a decay
+// cycle, with what to do about it
+type TopologyIssue struct {
+	Kind       TopologyIssueKind `json:"kind"`
+	Subject    string            `json:"subject"` // Edge ID, role or agent ID the issue is about
+	Detail     string            `json:"detail"`
+	Suggestion string            `json:"suggestion"`
+	Agents     []AgentID         `json:"agents,omitempty"` // Agents involved
+	Value      float64           `json:"value"`            // Prunes in the window, strongest in-edge or share of weight
+	DetectedAt time.Time         `json:"detected_at"`
+}
+
+// Key identifies the issue across decay cycles
+func (i TopologyIssue) Key() string {
+	return string(i.Kind) + ":" + i.Subject
+}
+
+// TopologyHealth is the topology's current health issues, oldest detected
+// first, as reported by the topology manager
+type TopologyHealth struct {
+	Issues    []TopologyIssue `json:"issues"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// NewTopologyHealthInsight creates the insight announcing a topology health issue
+func NewTopologyHealthInsight(agentID AgentID, issue TopologyIssue) *Insight {
+	insight := NewInsight(agentID, "", InsightTypeTopologyHealth, "topology_health:"+string(issue.Kind),
+		fmt.Sprintf("%s. %s", issue.Detail, issue.Suggestion), 1.0)
+	insight.Data["kind"] = string(issue.Kind)
+	insight.Data["subject"] = issue.Subject
+	insight.Data["value"] = issue.Value
+	insight.Data["suggestion"] = issue.Suggestion
+	agents := make([]string, len(issue.Agents))
+	for i, id := range issue.Agents {
+		agents[i] = string(id)
+	}
+	insight.Data["agents"] = agents
+	insight.Tags = append(insight.Tags, "topology_health", string(issue.Kind))
+	return insight
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"sync"
 	"time"
@@ -23,8 +24,21 @@ type Agent struct {
 	Name         string            `json:"name"`
 	Role         string            `json:"role"` // e.g., "sales", "support", "inventory"
 	Status       AgentStatus       `json:"status"`
-	Metadata     map[string]string `json:"metadata"`
-	Capabilities []string          `json:"capabilities"`
+	Framework    string            `json:"framework,omitempty"` // e.g., "openai", "langchain"
+	Model        string            `json:"model,omitempty"`     // e.g., "gpt-4"
+	Language     string            `json:"language,omitempty"`  // Implementation language
+	Version      string            `json:"version,omitempty"`   // Agent software version
+	Protocol     int               `json:"protocol,omitempty"`  // Wire protocol version announced at join
+	Region       string            `json:"region,omitempty"`    // Deployment region, e.g. "eu-west"
+	Namespace    string            `json:"namespace,omitempty"` // Sub-mesh of the agent's team, see MeshNamespace
+	Metadata     map[string]string `json:"metadata"`            // Free-form extra metadata
+	Capabilities []Capability      `json:"capabilities"`
+	DependsOn    []string          `json:"depends_on,omitempty"`   // Roles or agent IDs it sends work to, seeding their edges
+	Attestations []Attestation     `json:"attestations,omitempty"` // Operator-signed claims presented at join
+	Attested     *AttestedClaims   `json:"attested,omitempty"`     // Claims of the attestations that verified
+	Sandbox      *Sandbox          `json:"sandbox,omitempty"`      // Probation of a new, unattested agent
+	Lifecycle    *AgentLifecycle   `json:"lifecycle,omitempty"`    // Last reported lifecycle state
+	StandbyFor   AgentID           `json:"standby_for,omitempty"`  // Primary this agent takes over for, see CriticalRoles
 	CreatedAt    time.Time         `json:"created_at"`
 	LastSeenAt   time.Time         `json:"last_seen_at"`
 }
@@ -49,23 +63,132 @@ type Edge struct {
 	LastUsed  time.Time `json:"last_used"`
 	CreatedAt time.Time `json:"created_at"`
 
+	UsageByType map[MessageType]int64 `json:"usage_by_type,omitempty"` // Usage per kind of traffic
+	Metadata    map[string]string     `json:"metadata,omitempty"`      // Free-form annotations, see Graph.SetEdgeMetadata
+
+	// Region of both agents, or CrossRegion if they are in different regions
+	Region      string `json:"region,omitempty"`
+	CrossRegion bool   `json:"cross_region,omitempty"`
+
+	// Message latency over the last EdgeLatencySamples messages, see ObserveLatency
+	LatencyP50Ms float64 `json:"latency_p50_ms,omitempty"`
+	LatencyP99Ms float64 `json:"latency_p99_ms,omitempty"`
+
+	scorer    EdgeScorer // AdditiveScorer when nil, see SetScorer
+	latencies []float64  // Ring of the latest latencies in ms
+	latencyAt int        // Next slot of latencies to overwrite once full
+
 	mu sync.RWMutex `json:"-"`
 }
 
 // Reinforce increases the edge weight (SlimeMold reinforcement)
-func (e *Edge) Reinforce(amount float64) {
+func (e *Edge) Reinforce(amount float64, msgType MessageType) {
+	e.ReinforceAt(amount, msgType, time.Now())
+}
+
+// ReinforceAt reinforces the edge for a message sent at the given time
+func (e *Edge) ReinforceAt(amount float64, msgType MessageType, at time.Time) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.Weight = min(1.0, e.Weight+amount)
 	e.Usage++
-	e.LastUsed = time.Now()
+	e.LastUsed = at
+	if msgType != "" {
+		if e.UsageByType == nil {
+			e.UsageByType = make(map[MessageType]int64)
+		}
+		e.UsageByType[msgType]++
+	}
+}
+
+// SetMetadata sets a metadata key of the edge, or removes it for an empty value
+func (e *Edge) SetMetadata(key, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if value == "" {
+		delete(e.Metadata, key)
+		return
+	}
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+	e.Metadata[key] = value
+}
+
+// Clone returns a copy of the edge that shares no state with it
+func (e *Edge) Clone() *Edge {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	clone := &Edge{
+		ID:          e.ID,
+		SourceID:    e.SourceID,
+		TargetID:    e.TargetID,
+		Weight:      e.Weight,
+		Usage:       e.Usage,
+		LastUsed:    e.LastUsed,
+		CreatedAt:   e.CreatedAt,
+		Region:      e.Region,
+		CrossRegion: e.CrossRegion,
+
+		LatencyP50Ms: e.LatencyP50Ms,
+		LatencyP99Ms: e.LatencyP99Ms,
+
+		scorer:    e.scorer,
+		latencies: append([]float64(nil), e.latencies...),
+		latencyAt: e.latencyAt,
+	}
+	if e.UsageByType != nil {
+		clone.UsageByType = make(map[MessageType]int64, len(e.UsageByType))
+		for msgType, usage := range e.UsageByType {
+			clone.UsageByType[msgType] = usage
+		}
+	}
+	if e.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(e.Metadata))
+		for key, value := range e.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+	return clone
 }
 
-// Decay decreases the edge weight over time (SlimeMold evaporation)
-func (e *Edge) Decay(rate float64) {
+// SetScorer sets how the edge's traffic turns into its weight; nil restores AdditiveScorer
+func (e *Edge) SetScorer(scorer EdgeScorer) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	e.scorer = scorer
+}
+
+// Gain returns how much a message worth amount raises the edge's weight now,
+// as decided by its scorer
+func (e *Edge) Gain(amount float64) float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.scorer == nil {
+		return amount
+	}
+	return e.scorer.Gain(e.Weight, amount)
+}
+
+// Loss returns how much a decay cycle at rate lowers the edge's weight now,
+// as decided by its scorer
+func (e *Edge) Loss(rate float64) float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.scorer == nil {
+		return rate
+	}
+	return e.scorer.Loss(e.Weight, rate)
+}
+
+// Decay decreases the edge weight over time (SlimeMold evaporation),
+// returning the weight removed
+func (e *Edge) Decay(rate float64) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	before := e.Weight
 	e.Weight = max(0.0, e.Weight-rate)
+	return before - e.Weight
 }
 
 // GetWeight safely retrieves the edge weight
@@ -75,6 +198,17 @@ func (e *Edge) GetWeight() float64 {
 	return e.Weight
 }
 
+// GetLastUsed safely retrieves when a message last used the edge, or when it
+// was created if none has
+func (e *Edge) GetLastUsed() time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.LastUsed.IsZero() {
+		return e.CreatedAt
+	}
+	return e.LastUsed
+}
+
 // Message represents a communication between agents
 type Message struct {
 	ID          string            `json:"id"`
@@ -85,18 +219,44 @@ type Message struct {
 	Metadata    map[string]string `json:"metadata"`
 	Timestamp   time.Time         `json:"timestamp"`
 	EdgeID      EdgeID            `json:"edge_id,omitempty"`
+	InReplyTo   string            `json:"in_reply_to,omitempty"` // Task message ID a response answers
+	ReplayOf    string            `json:"replay_of,omitempty"`   // Stored message a debug replay republishes
+
+	ConversationID string `json:"conversation_id,omitempty"` // Receivers handle a conversation's messages in order
+
+	ProtocolVersion    int `json:"protocol_version,omitempty"`     // Sender's protocol version
+	MinProtocolVersion int `json:"min_protocol_version,omitempty"` // Oldest protocol able to decode this message
+}
+
+// Replayed reports whether the message is a debug replay, or a response to
+// one; replays do not shape the topology, train routing or enter the history
+func (m *Message) Replayed() bool {
+	return m.ReplayOf != ""
+}
+
+// OrderingKey groups the messages that must be handled in the order sent:
+// those of a conversation, or outside one those of a sender
+func (m *Message) OrderingKey() string {
+	if m.ConversationID != "" {
+		return "conversation/" + m.ConversationID
+	}
+	return "sender/" + string(m.FromAgentID)
 }
 
 // MessageType defines the kind of message
 type MessageType string
 
 const (
-	MessageTypeTask      MessageType = "task"
-	MessageTypeResponse  MessageType = "response"
-	MessageTypeWaggle    MessageType = "waggle" // Bee consensus broadcast
-	MessageTypeVote      MessageType = "vote"   // Bee consensus vote
-	MessageTypeHeartbeat MessageType = "heartbeat"
-	MessageTypeTopology  MessageType = "topology" // Topology update
+	MessageTypeTask        MessageType = "task"
+	MessageTypeResponse    MessageType = "response"
+	MessageTypeWaggle      MessageType = "waggle" // Bee consensus broadcast
+	MessageTypeVote        MessageType = "vote"   // Bee consensus vote
+	MessageTypeHeartbeat   MessageType = "heartbeat"
+	MessageTypeTopology    MessageType = "topology"     // Topology update
+	MessageTypeInsight     MessageType = "insight"      // Insight shared to the knowledge mesh
+	MessageTypeInsightPush MessageType = "insight_push" // High-importance insight pushed to agents
+	MessageTypeDigest      MessageType = "digest"       // Periodic activity report
+	MessageTypeLifecycle   MessageType = "lifecycle"    // Agent lifecycle state change
 )
 
 // Proposal represents a consensus proposal in the Bee algorithm
@@ -105,11 +265,31 @@ type Proposal struct {
 	ProposerID AgentID          `json:"proposer_id"`
 	Type       ProposalType     `json:"type"`
 	Content    map[string]any   `json:"content"`
-	Waggle     WaggleDance      `json:"waggle"` // Bee waggle dance
+	Waggle     WaggleDance      `json:"waggle"`             // Bee waggle dance
+	Template   string           `json:"template,omitempty"` // Template the proposal was created from
+	Bundle     BundleID         `json:"bundle,omitempty"`   // Bundle the proposal is voted on with
 	Votes      map[AgentID]Vote `json:"votes"`
 	Status     ProposalStatus   `json:"status"`
 	CreatedAt  time.Time        `json:"created_at"`
 	ExpiresAt  time.Time        `json:"expires_at"`
+	DecidedAt  time.Time        `json:"decided_at,omitempty"`  // When the proposal left pending
+	Window     *ProposalWindow  `json:"window,omitempty"`      // Time window the proposal is valid in
+	ExecutedAt time.Time        `json:"executed_at,omitempty"` // When an accepted proposal's window opened
+
+	// Escalation
+	Electorate    []string           `json:"electorate,omitempty"`     // Roles that may vote, empty = every agent
+	EscalatedFrom ProposalID         `json:"escalated_from,omitempty"` // Expired proposal this one re-proposes
+	Escalations   []EscalationRecord `json:"escalations,omitempty"`    // Escalation history of the chain so far
+
+	// Recurrence and standing policies
+	Recurrence   string     `json:"recurrence,omitempty"`    // Recurring proposal this is a round of
+	Round        int        `json:"round,omitempty"`         // Round of the recurring proposal, from 1
+	Policy       string     `json:"policy,omitempty"`        // Standing policy it puts in force once accepted
+	SupersededBy ProposalID `json:"superseded_by,omitempty"` // Proposal that replaced it as the policy in force
+
+	// Dependencies
+	DependsOn []ProposalID `json:"depends_on,omitempty"` // Prerequisites that must be accepted before it can be
+	BlockedBy ProposalID   `json:"blocked_by,omitempty"` // Prerequisite whose failure expired it
 
 	mu sync.RWMutex `json:"-"`
 }
@@ -147,12 +327,31 @@ type Vote struct {
 	Support   bool      `json:"support"`   // true = accept, false = reject
 	Intensity float64   `json:"intensity"` // How strongly they support (0.0-1.0)
 	Timestamp time.Time `json:"timestamp"`
+
+	// Abstentions and conditional votes; votes without a choice follow Support
+	Choice       VoteChoice     `json:"choice,omitempty"`
+	Condition    *VoteCondition `json:"condition,omitempty"`     // Conditional votes support only if it holds
+	ConditionMet *bool          `json:"condition_met,omitempty"` // Set when the proposal is finalized
+
+	// Why the agent voted this way, for post-hoc analysis (optional)
+	Rationale string       `json:"rationale,omitempty"`
+	Factors   []VoteFactor `json:"factors,omitempty"`
+
+	// Cast by a sandboxed agent: recorded but not counted towards quorum
+	NonBinding bool `json:"non_binding,omitempty"`
+
+	// Points committed to the vote, rewarded or slashed by the decision's impact (see VOTE_STAKES)
+	Stake float64 `json:"stake,omitempty"`
 }
 
 // AddVote adds a vote to the proposal (thread-safe)
 func (p *Proposal) AddVote(vote Vote) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if p.Votes == nil {
+		// Proposals decoded from JSON with "votes": null have no map
+		p.Votes = make(map[AgentID]Vote)
+	}
 	p.Votes[vote.VoterID] = vote
 }
 
@@ -167,7 +366,7 @@ func (p *Proposal) GetQuorum(totalAgents int) float64 {
 
 	supportCount := 0
 	for _, vote := range p.Votes {
-		if vote.Support {
+		if p.inFavor(vote) {
 			supportCount++
 		}
 	}
@@ -183,6 +382,12 @@ type TopologyEvent struct {
 	Agent     *Agent            `json:"agent,omitempty"`
 	Edge      *Edge             `json:"edge,omitempty"`
 	Timestamp time.Time         `json:"timestamp"`
+
+	Components [][]AgentID    `json:"components,omitempty"` // Islands of a partition_detected event, largest first
+	Issue      *TopologyIssue `json:"issue,omitempty"`      // Issue of a health_issue event
+
+	ProtocolVersion    int `json:"protocol_version,omitempty"`     // Sender's protocol version
+	MinProtocolVersion int `json:"min_protocol_version,omitempty"` // Oldest protocol able to decode this event
 }
 
 // TopologyEventType defines topology change types
@@ -194,14 +399,25 @@ const (
 	TopologyEventEdgeStrength TopologyEventType = "edge_strength_changed"
 	TopologyEventAgentJoined  TopologyEventType = "agent_joined"
 	TopologyEventAgentLeft    TopologyEventType = "agent_left"
+	TopologyEventFrozen       TopologyEventType = "topology_frozen"
+	TopologyEventUnfrozen     TopologyEventType = "topology_unfrozen"
+
+	// TopologyEventPartitionDetected reports the mesh split into more isolated islands after pruning
+	TopologyEventPartitionDetected TopologyEventType = "partition_detected"
+
+	// TopologyEventHealthIssue reports a topology health issue as it is first detected
+	TopologyEventHealthIssue TopologyEventType = "health_issue"
 )
 
 // GraphSnapshot represents the state of the network at a point in time
 type GraphSnapshot struct {
-	Agents    map[AgentID]*Agent `json:"agents"`
-	Edges     map[EdgeID]*Edge   `json:"edges"`
-	Timestamp time.Time          `json:"timestamp"`
-	Stats     GraphStats         `json:"stats"`
+	Agents      map[AgentID]*Agent         `json:"agents"`
+	Edges       map[EdgeID]*Edge           `json:"edges"`
+	Communities map[AgentID]int            `json:"communities,omitempty"` // Cluster of each agent, numbered from 0
+	Layout      map[AgentID]LayoutPosition `json:"layout,omitempty"`      // Position of each agent, computed by the topology manager
+	Sequence    uint64                     `json:"sequence,omitempty"`    // Position among the published snapshots, see SnapshotDelta
+	Timestamp   time.Time                  `json:"timestamp"`
+	Stats       GraphStats                 `json:"stats"`
 }
 
 // GraphStats contains metrics about the network topology
@@ -212,8 +428,43 @@ type GraphStats struct {
 	AverageWeight    float64 `json:"average_weight"`
 	MaxWeight        float64 `json:"max_weight"`
 	MinWeight        float64 `json:"min_weight"`
-	Density          float64 `json:"density"`           // Actual edges / possible edges
-	ReductionPercent float64 `json:"reduction_percent"` // % reduction from full mesh
+	Density          float64 `json:"density"`              // Actual edges / possible edges
+	ReductionPercent float64 `json:"reduction_percent"`    // % reduction from full mesh
+	Undirected       bool    `json:"undirected,omitempty"` // Each edge stands for both directions, see EdgeModeUndirected
+
+	Centrality  map[AgentID]AgentCentrality `json:"centrality,omitempty"` // Per agent, to spot emerging hubs
+	Communities int                         `json:"communities"`          // Clusters of agents in the snapshot
+	Components  int                         `json:"components"`           // Connected islands, more than one once the mesh split
+
+	Namespaces map[string]NamespaceStats `json:"namespaces,omitempty"` // Per sub-mesh, once agents declare namespaces
+
+	Tuning        *TuningStatus        `json:"tuning,omitempty"`         // Nil without a reduction or density target
+	AdaptiveDecay *AdaptiveDecayStatus `json:"adaptive_decay,omitempty"` // Nil without a reference throughput
+}
+
+// AdaptiveDecayStatus reports how message throughput scales the decay rate
+type AdaptiveDecayStatus struct {
+	ReferenceThroughput float64 `json:"reference_throughput"` // Messages per second the base rate is meant for
+	Throughput          float64 `json:"throughput"`           // Smoothed messages per second
+	Factor              float64 `json:"factor"`               // Applied to the base decay rate
+	DecayRate           float64 `json:"decay_rate"`           // In effect
+}
+
+// TuningStatus reports how the topology converges to its reduction or density target
+type TuningStatus struct {
+	TargetReduction float64 `json:"target_reduction,omitempty"` // % reduction from full mesh
+	TargetDensity   float64 `json:"target_density,omitempty"`
+	Current         float64 `json:"current"` // Reduction or density, like the target
+	DecayRate       float64 `json:"decay_rate"`
+	PruneThreshold  float64 `json:"prune_threshold"`
+	Converged       bool    `json:"converged"` // Within tolerance of the target
+}
+
+// AgentCentrality scores how central an agent is in the topology (0-1 each)
+type AgentCentrality struct {
+	Degree      float64 `json:"degree"`      // Share of other agents it has an edge to or from
+	Betweenness float64 `json:"betweenness"` // Share of strongest paths between others it relays; 0 beyond 500 agents
+	Eigenvector float64 `json:"eigenvector"` // Connected to central agents; the most central scores 1
 }
 
 // ============================================================================
@@ -240,6 +491,29 @@ type Insight struct {
 	// Privacy controls
 	Privacy    InsightPrivacy    `json:"privacy"`
 	SharedWith []AgentID         `json:"shared_with,omitempty"` // If privacy is "restricted"
+	RequiredAccess string        `json:"required_access,omitempty"` // Attested data access scope needed to read it
+
+	// Importance, set on query results and pushed insights only
+	Rank *InsightRank `json:"rank,omitempty"`
+
+	// Consensus verification, set for types in VERIFIED_INSIGHT_TYPES
+	Verification *InsightVerification `json:"verification,omitempty"`
+
+	// Published by an agent in its sandbox; excluded from pattern detection
+	Unverified bool `json:"unverified,omitempty"`
+
+	// Region the insight was published in, set by its knowledge manager
+	Region string `json:"region,omitempty"`
+}
+
+// InsightRank is an insight's importance score and the signals behind it (all 0-1)
+type InsightRank struct {
+	Score         float64 `json:"score"`
+	Novelty       float64 `json:"novelty"`       // Fewer earlier repeats of the same topic and type
+	Reputation    float64 `json:"reputation"`    // Share of the author's insights others corroborated
+	Corroboration float64 `json:"corroboration"` // Other agents reporting the same topic nearby in time
+	Recency       float64 `json:"recency"`       // Halves every InsightHalfLife
+	Corroborators int     `json:"corroborators"`
 }
 
 // InsightType categorizes the kind of insight
@@ -276,6 +550,8 @@ type KnowledgeQuery struct {
 	TimeFrom      *time.Time     `json:"time_from"`      // Start time filter
 	TimeTo        *time.Time     `json:"time_to"`        // End time filter
 	Limit         int            `json:"limit"`          // Max results
+	Actionable    bool           `json:"actionable"`     // Only insights agents may act on (verified where required)
+	AgentID       AgentID        `json:"agent_id,omitempty"` // Requesting agent; only insights visible to it
 }
 
 // KnowledgeQueryResult represents the response to a knowledge query
@@ -284,6 +560,8 @@ type KnowledgeQueryResult struct {
 	Insights  []Insight      `json:"insights"`
 	Count     int            `json:"count"`
 	Patterns  []Pattern      `json:"patterns,omitempty"` // Detected patterns across insights
+
+	LowConfidence []InsightTierSummary `json:"low_confidence,omitempty"` // Counts of insights tiering did not store individually
 	Timestamp time.Time      `json:"timestamp"`
 }
 
@@ -329,11 +607,152 @@ type Config struct {
 	DecayRate           float64       `json:"decay_rate"`
 	DecayInterval       time.Duration `json:"decay_interval"`
 	PruneThreshold      float64       `json:"prune_threshold"`
+	PruneMinDegree      int           `json:"prune_min_degree"` // Edges pruning leaves each agent at least, its strongest (0 = none)
+	PathFallback        PathFallback  `json:"path_fallback"`    // Path routing without a pheromone path
+	EdgeScoring         EdgeScoring   `json:"edge_scoring"`     // How traffic turns into edge weight
+	EdgeBootstrap       EdgeBootstrap `json:"edge_bootstrap"`   // Edges created when an agent joins
+	EdgeMode            EdgeMode      `json:"edge_mode"`        // One edge per direction or per pair of agents
+
+	// Weights of new edges seeded from what is known about their agents instead
+	// of INITIAL_EDGE_WEIGHT (0 = unused), the first that applies in this order
+	EdgePriorHistory    time.Duration `json:"edge_prior_history"`    // How long the weight of an edge whose agent left seeds it on return
+	EdgePriorDependency float64       `json:"edge_prior_dependency"` // To an agent the source depends on
+	EdgePriorSameRole   float64       `json:"edge_prior_same_role"`  // Between agents of the same role
+
+	// Seed new edges with the weights learned before the topology manager last
+	// shut down, if no older than the max age
+	LearnedPriors       bool          `json:"learned_priors"`
+	LearnedPriorsMaxAge time.Duration `json:"learned_priors_max_age"`
+
+	// Which edges decay cycles prune, and the top_k and age strategies' settings
+	PruneStrategy PruneStrategy `json:"prune_strategy"`
+	PruneTopK     int           `json:"prune_top_k"`   // Strongest edges each agent keeps
+	PruneMaxAge   time.Duration `json:"prune_max_age"` // Unused edges older than this are pruned
+
+	// Reduction (% of full mesh) or density the decay rate and prune threshold
+	// are tuned toward, within the maximums (0 = no target)
+	TargetReduction         float64 `json:"target_reduction,omitempty"`
+	TargetDensity           float64 `json:"target_density,omitempty"`
+	TuningMaxDecayRate      float64 `json:"tuning_max_decay_rate"`
+	TuningMaxPruneThreshold float64 `json:"tuning_max_prune_threshold"`
+
+	// Messages per second DecayRate is meant for; decay scales with throughput
+	// against it (0 = fixed rate)
+	DecayReferenceThroughput float64 `json:"decay_reference_throughput,omitempty"`
+
+	// Decay cycles whose reinforcement, decay and pruning are kept for
+	// GET /api/topology/algorithm (0 = none)
+	AlgorithmHistory int `json:"algorithm_history"`
+
+	// Topology health issues looked for after each decay cycle, see TopologyIssue
+	TopologyHealthWindow   time.Duration `json:"topology_health_window"`   // Prunes counted, and grace period of new agents
+	OscillationPrunes      int           `json:"oscillation_prunes"`       // Prunes of an edge in the window that make it oscillating (0 = off)
+	OscillationFreezeEdges int           `json:"oscillation_freeze_edges"` // Oscillating edges at once that freeze the topology (0 = never)
+	StarvedEdgeWeight      float64       `json:"starved_edge_weight"`      // Strongest edge into a role below which it is starved (0 = off)
+	HubOverloadShare       float64       `json:"hub_overload_share"`       // Share of all edge weight into one agent that overloads it (0 = off)
+
+	// Reinforcement and decay of edges between particular roles (nil = global values)
+	RolePolicies RolePolicies `json:"role_policies,omitempty"`
+
+	// Edges forbidden or capped between particular roles or agents (nil = none)
+	EdgeConstraints EdgeConstraints `json:"edge_constraints,omitempty"`
+
+	// Observer-only second topology under other parameters to compare against (nil = none)
+	ShadowTopology *TopologyParams `json:"shadow_topology,omitempty"`
 
 	// Consensus settings
-	QuorumThreshold    float64       `json:"quorum_threshold"` // 0.6 = 60%
-	ProposalTimeout    time.Duration `json:"proposal_timeout"`
-	WaggleIntensityMin float64       `json:"waggle_intensity_min"`
+	QuorumThreshold    float64             `json:"quorum_threshold"` // 0.6 = 60%
+	ProposalTimeout    time.Duration       `json:"proposal_timeout"`
+	WaggleIntensityMin float64             `json:"waggle_intensity_min"`
+	EscalationPolicies EscalationPolicies  `json:"escalation_policies,omitempty"` // Chains for proposals expiring without quorum
+	RecurringProposals []RecurringProposal `json:"recurring_proposals,omitempty"` // Proposals made again every interval
+
+	// Stakes on votes, settled by the decision's impact (opt-in)
+	VoteStakes          bool    `json:"vote_stakes"`
+	StakeInitialBalance float64 `json:"stake_initial_balance"` // Points each agent starts with
+	StakeRewardRate     float64 `json:"stake_reward_rate"`     // Share of a right stake added to the balance
+	StakeSlashRate      float64 `json:"stake_slash_rate"`      // Share of a wrong stake taken from the balance
+
+	// Insight types agents may act on only after a verification proposal is accepted
+	VerifiedInsightTypes []InsightType `json:"verified_insight_types,omitempty"`
+
+	// Agent attestation
+	AttestationKeys    map[string]ed25519.PublicKey `json:"-"`                    // Operator CA keys by issuer
+	RequireAttestation bool                         `json:"require_attestation"` // Routing by capability needs attested capabilities
+
+	// Sandbox for new agents without a verified attestation (0 probation disables it)
+	SandboxProbation   time.Duration `json:"sandbox_probation"`
+	SandboxMessageRate int           `json:"sandbox_message_rate"` // Messages per minute a sandboxed agent may send
+
+	// Agent liveness from heartbeats
+	HeartbeatInterval    time.Duration `json:"heartbeat_interval"`     // How often agents publish a heartbeat
+	AgentOfflineTimeout  time.Duration `json:"agent_offline_timeout"`  // Silence after which an agent is marked offline
+	AgentEvictionTimeout time.Duration `json:"agent_eviction_timeout"` // Silence after which an agent is removed (0 = never)
+
+	// Multi-region deployments
+	Region             string              `json:"region,omitempty"`           // Region of this mesh deployment
+	CrossRegionPenalty float64             `json:"cross_region_penalty"`       // Share of the routing score cross-region candidates lose (0-1)
+	FederationPeers    map[string][]string `json:"federation_peers,omitempty"` // Kafka brokers of the other regions
+	FederationMinScore float64             `json:"federation_min_score"`       // Rank score pushed insights need to be replicated
+
+	// Store-and-forward spool for agents with intermittent connectivity (empty dir disables it)
+	SpoolDir           string        `json:"spool_dir,omitempty"`
+	SpoolMaxMessages   int           `json:"spool_max_messages"` // Oldest entries are dropped beyond the limits (0 = unlimited)
+	SpoolMaxBytes      int           `json:"spool_max_bytes"`
+	SpoolFlushInterval time.Duration `json:"spool_flush_interval"`
+
+	// Insights an agent's local knowledge cache holds at most (0 = unlimited)
+	KnowledgeCacheSize int `json:"knowledge_cache_size"`
+
+	// Publish the current insights to a log-compacted topic keyed by insight ID
+	InsightCompaction bool `json:"insight_compaction"`
+
+	// How each topic's low-confidence insights are stored: all, a sample or
+	// only counted (nil = all stored)
+	InsightTiering TieringPolicies `json:"insight_tiering,omitempty"`
+
+	// Most insights accepted by one POST /api/insights/batch request
+	InsightBatchLimit int `json:"insight_batch_limit"`
+
+	// Insight embeddings for semantic search, from an OpenAI-compatible endpoint ("" = none)
+	EmbeddingURL           string        `json:"embedding_url,omitempty"`
+	EmbeddingModel         string        `json:"embedding_model"`
+	EmbeddingAPIKey        string        `json:"-"`
+	EmbeddingBatchSize     int           `json:"embedding_batch_size"`     // Most texts per provider request
+	EmbeddingBatchInterval time.Duration `json:"embedding_batch_interval"` // How long new insights wait to fill a batch
+
+	// External knowledge connectors
+	ConnectorInterval time.Duration `json:"connector_interval"` // How often a connector pulls its source
+	ConnectorLookback time.Duration `json:"connector_lookback"` // How far back a connector's first pull reaches
+
+	// Every how many topology snapshots a full checkpoint is saved, with only the
+	// changes saved in between (0 or 1 = every snapshot is full)
+	SnapshotCheckpointEvery int `json:"snapshot_checkpoint_every"`
+
+	// Force-directed layout iterations per topology snapshot (0 = no layout)
+	LayoutIterations int `json:"layout_iterations"`
+
+	// Where the managers start consuming Kafka: resume, earliest (rebuild) or latest
+	TopologyStartMode  StartMode `json:"topology_start_mode,omitempty"`
+	KnowledgeStartMode StartMode `json:"knowledge_start_mode,omitempty"`
+
+	// Where the topology manager's replay start mode begins replaying
+	TopologyReplayFrom time.Time `json:"topology_replay_from"`
+
+	// System health rollup thresholds beyond which the mesh is degraded (0 disables a check)
+	HealthMaxConsumerLag int64         `json:"health_max_consumer_lag"`
+	HealthMaxSnapshotAge time.Duration `json:"health_max_snapshot_age"`
+
+	// Incident mode: the health status that declares an incident automatically
+	// ("" = only through the API), and how many times slower non-critical
+	// periodic work runs during one
+	IncidentAutoStatus HealthStatus `json:"incident_auto_status,omitempty"`
+	IncidentSlowdown   int          `json:"incident_slowdown"`
+
+	// Differential privacy for insight analytics shared across teams
+	AnalyticsPrivateTeams []string `json:"analytics_private_teams,omitempty"` // Regulated teams whose insights are only counted with noise ("*" = all)
+	AnalyticsEpsilon      float64  `json:"analytics_epsilon"`                 // Privacy budget per release; lower is more private and noisier
+	AnalyticsDelta        float64  `json:"analytics_delta"`                   // Chance a regulated-only topic is revealed
 
 	// Infrastructure
 	KafkaBrokers     []string `json:"kafka_brokers"`
@@ -341,9 +760,88 @@ type Config struct {
 	RedisAddr        string   `json:"redis_addr"`
 	RedisDB          int      `json:"redis_db"`
 
+	// Concurrent handling in Kafka consumers (1 worker = one message at a time)
+	ConsumerWorkers        int           `json:"consumer_workers"`
+	ConsumerMaxInFlight    int           `json:"consumer_max_in_flight"`   // Messages read and not handled yet per consumer
+	ConsumerHandlerTimeout time.Duration `json:"consumer_handler_timeout"` // Deadline of each handler's context, after which the next message is taken (0 = none)
+
+	// Topology guardrails (0 disables a limit)
+	MaxPrunePerCycle         int           `json:"max_prune_per_cycle"`
+	MaxWeightChangePerMinute float64       `json:"max_weight_change_per_minute"` // Per edge, both directions
+	ChurnFreezeThreshold     int           `json:"churn_freeze_threshold"`       // Edges created+pruned per window
+	ChurnWindow              time.Duration `json:"churn_window"`
+
+	// Insight ranking
+	InsightHalfLife      time.Duration `json:"insight_half_life"`      // Age at which recency halves
+	CorroborationWindow  time.Duration `json:"corroboration_window"`   // How close in time insights corroborate
+	InsightPushThreshold float64       `json:"insight_push_threshold"` // Minimum score to push an insight to agents, 0 = never push
+
+	// Digests
+	DigestInterval    time.Duration `json:"digest_interval"`     // How often to compile a digest, 0 = disabled
+	DigestTopInsights int           `json:"digest_top_insights"` // Insights included in a digest
+
+	// Alerting on mesh metrics, delivered to webhooks
+	AlertRules    []AlertRule   `json:"alert_rules,omitempty"` // Nil = no alerting
+	AlertInterval time.Duration `json:"alert_interval"`        // How often rules are evaluated
+
+	// Routing feedback: task outcomes are always recorded; learning is opt-in
+	RoutingLearning     bool          `json:"routing_learning"`      // Blend learned route values into routing
+	RoutingLearningRate float64       `json:"routing_learning_rate"` // Step size of the route value update
+	RoutingLearnWeight  float64       `json:"routing_learn_weight"`  // Share of the score from the learned value (0-1)
+	RoutingExploration  float64       `json:"routing_exploration"`   // Probability of routing to a random candidate
+	RoutingLoadBalance  bool          `json:"routing_load_balance"`  // Spread tasks over candidates by score and load
+	TaskTimeout         time.Duration `json:"task_timeout"`          // Tasks without a response count as failed
+
+	// Median edge latency at which routing halves an edge's strength (0 = latency ignored)
+	RoutingLatencyTarget time.Duration `json:"routing_latency_target"`
+
 	// Server
 	HTTPPort      int `json:"http_port"`
 	WebSocketPort int `json:"websocket_port"`
+
+	// Manager state handoff for rolling upgrades
+	HandoffAddr string `json:"handoff_addr"` // Listen address for successors, empty = disabled
+	HandoffFrom string `json:"handoff_from"` // Predecessor to take state from at startup
+
+	// Agent debug console (see agent.ServeDebug), empty address = disabled
+	AgentDebugAddr  string `json:"agent_debug_addr,omitempty"`
+	AgentDebugToken string `json:"-"` // Bearer token required from non-loopback clients
+
+	// Shadow mode: agents consume the mesh but only log what they would publish
+	AgentDryRun bool `json:"agent_dry_run,omitempty"`
+
+	// How many handled message IDs an agent remembers to drop redeliveries (0 = none)
+	AgentDedupWindow int `json:"agent_dedup_window"`
+
+	// Roles whose agents may have a warm standby, taking over their messages when they miss their heartbeats
+	CriticalRoles []string `json:"critical_roles,omitempty"`
+
+	// Conversations an agent keeps the recent messages of, least recently active dropped first (0 = unlimited)
+	ConversationStoreSize int `json:"conversation_store_size"`
+}
+
+// TopologyFreeze is a manual freeze or unfreeze requested through the API
+type TopologyFreeze struct {
+	Frozen      bool      `json:"frozen"` // false asks to lift any freeze, including the breaker's
+	Reason      string    `json:"reason,omitempty"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// GuardrailStatus reports the topology guardrails and circuit breaker state
+type GuardrailStatus struct {
+	Frozen        bool       `json:"frozen"`
+	Manual        bool       `json:"manual"` // Frozen through the API rather than by the breaker
+	Reason        string     `json:"reason,omitempty"`
+	FrozenAt      *time.Time `json:"frozen_at,omitempty"`
+	Churn         int        `json:"churn"` // Edges created+pruned in the current window
+	ChurnLimit    int        `json:"churn_limit"`
+	ChurnWindow   string     `json:"churn_window"`
+	MaxPrune      int        `json:"max_prune_per_cycle"`
+	MaxChange     float64    `json:"max_weight_change_per_minute"`
+	PruneDeferred int64      `json:"prune_deferred"` // Prunes postponed by the per-cycle cap
+	ChangeClamped int64      `json:"change_clamped"` // Weight changes reduced by the per-edge limit
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 // Helper functions
@@ -366,11 +864,6 @@ func NewAgentID() AgentID {
 	return AgentID(uuid.New().String())
 }
 
-// NewEdgeID generates a new unique edge ID
-func NewEdgeID(sourceID, targetID AgentID) EdgeID {
-	return EdgeID(string(sourceID) + "->" + string(targetID))
-}
-
 // NewProposalID generates a new unique proposal ID
 func NewProposalID() ProposalID {
 	return ProposalID(uuid.New().String())
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InsightVerificationTemplate is the built-in proposal template the knowledge
+// manager uses to put an insight to a vote
+const InsightVerificationTemplate = "insight_verification"
+
+// InsightVerificationStatus is where an insight stands in consensus verification
+type InsightVerificationStatus string
+
+const (
+	InsightVerificationPending  InsightVerificationStatus = "pending"  // Waiting for the verification vote
+	InsightVerificationVerified InsightVerificationStatus = "verified" // Verification proposal accepted
+	InsightVerificationRejected InsightVerificationStatus = "rejected" // Verification proposal rejected or expired
+)
+
+// InsightVerification records the consensus vote an insight went through
+// before agents may act on it
+type InsightVerification struct {
+	Status     InsightVerificationStatus `json:"status"`
+	ProposalID ProposalID                `json:"proposal_id,omitempty"` // Set once the proposal is found
+	DecidedAt  time.Time                 `json:"decided_at,omitempty"`
+}
+
+// ParseInsightTypes parses a comma-separated list of insight types
+func ParseInsightTypes(value string) []InsightType {
+	var insightTypes []InsightType
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			insightTypes = append(insightTypes, InsightType(name))
+		}
+	}
+	return insightTypes
+}
+
+// RequiresVerification reports whether insights of a type must be verified by
+// consensus in this mesh before agents act on them
+func (c *Config) RequiresVerification(insightType InsightType) bool {
+	for _, t := range c.VerifiedInsightTypes {
+		if t == insightType {
+			return true
+		}
+	}
+	return false
+}
+
+// Actionable reports whether agents may act on the insight: either its type
+// needs no verification, or its verification proposal was accepted
+func (i *Insight) Actionable(config *Config) bool {
+	if !config.RequiresVerification(i.Type) {
+		return true
+	}
+	return i.Verification != nil && i.Verification.Status == InsightVerificationVerified
+}
+
+// PushedInsight decodes the insight carried by an insight_push message
+func PushedInsight(msg *Message) (*Insight, error) {
+	data, err := json.Marshal(msg.Payload["insight"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pushed insight: %w", err)
+	}
+	var insight Insight
+	if err := json.Unmarshal(data, &insight); err != nil || insight.ID == "" {
+		return nil, fmt.Errorf("message carries no insight")
+	}
+	return &insight, nil
+}
+
+// VerificationOutcome returns the verification status of an insight given the
+// proposals created to verify it, including escalated re-proposals. Any accepted
+// proposal verifies it; it stays pending while any proposal is still open.
+func VerificationOutcome(proposals []*Proposal) (InsightVerificationStatus, *Proposal) {
+	var open, last *Proposal
+	for _, proposal := range proposals {
+		switch proposal.Status {
+		case ProposalStatusAccepted:
+			return InsightVerificationVerified, proposal
+		case ProposalStatusPending:
+			open = proposal
+		default:
+			last = proposal
+		}
+	}
+	if open != nil || last == nil {
+		return InsightVerificationPending, open
+	}
+	return InsightVerificationRejected, last
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// ViewAggregate is how a materialized view aggregates the insights of a group
+type ViewAggregate string
+
+const (
+	ViewAggregateCount ViewAggregate = "count"
+	ViewAggregateSum   ViewAggregate = "sum"
+	ViewAggregateAvg   ViewAggregate = "avg"
+	ViewAggregateMin   ViewAggregate = "min"
+	ViewAggregateMax   ViewAggregate = "max"
+)
+
+// InsightView is an operator-defined materialized view over insights, e.g.
+// "open fraud alerts by region". The knowledge manager keeps its result up to
+// date as insights arrive, so reading it does not filter every insight.
+type InsightView struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Query       string        `json:"query"`              // AMQL insight query selecting the insights, e.g. "SELECT insights WHERE type = 'fraud_pattern'"
+	GroupBy     string        `json:"group_by,omitempty"` // Field the insights are grouped by, e.g. "region"; none = one group
+	Aggregate   ViewAggregate `json:"aggregate"`          // "count" (default), "sum", "avg", "min" or "max"
+	Field       string        `json:"field,omitempty"`    // Numeric field aggregated, e.g. "confidence"; not used by count
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// ViewRow is the aggregate of one group of a view
+type ViewRow struct {
+	Group string  `json:"group"`
+	Value float64 `json:"value"`
+	Count int     `json:"count"` // Insights in the group
+}
+
+// ViewResult is the materialized content of a view, largest value first
+type ViewResult struct {
+	View        string    `json:"view"`
+	Rows        []ViewRow `json:"rows"`
+	Matched     int       `json:"matched"` // Insights matching the query
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// Validate checks the view definition, except its query, and fills in defaults
+func (v *InsightView) Validate() error {
+	if !tokenPattern.MatchString(v.Name) {
+		return fmt.Errorf("invalid view name %q", v.Name)
+	}
+	if v.Query == "" {
+		return fmt.Errorf("view query is required")
+	}
+	if v.Aggregate == "" {
+		v.Aggregate = ViewAggregateCount
+	}
+	switch v.Aggregate {
+	case ViewAggregateCount:
+	case ViewAggregateSum, ViewAggregateAvg, ViewAggregateMin, ViewAggregateMax:
+		if v.Field == "" {
+			return fmt.Errorf("aggregate %s requires a field", v.Aggregate)
+		}
+	default:
+		return fmt.Errorf("invalid aggregate %q (use count, sum, avg, min or max)", v.Aggregate)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VoteChoice is how an agent votes on a proposal
+type VoteChoice string
+
+const (
+	VoteChoiceSupport     VoteChoice = "support"
+	VoteChoiceReject      VoteChoice = "reject"
+	VoteChoiceAbstain     VoteChoice = "abstain"     // Participates without taking a side
+	VoteChoiceConditional VoteChoice = "conditional" // Supports only if its condition holds
+)
+
+// VoteCondition is the condition of a conditional vote on a field of the
+// proposal's content, e.g. {"field": "amount", "operator": "<", "value": 10000}.
+// Nested fields are addressed with dots, e.g. "order.amount".
+type VoteCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"` // "<", "<=", ">", ">=", "==" or "!="
+	Value    any    `json:"value"`
+}
+
+// VoteTally counts a proposal's votes by choice
+type VoteTally struct {
+	Support       int `json:"support"`
+	Reject        int `json:"reject"`
+	Abstain       int `json:"abstain"`
+	Conditional   int `json:"conditional"`
+	ConditionsMet int `json:"conditions_met"` // Conditional votes counting as support
+	NonBinding    int `json:"non_binding"`    // Votes of sandboxed agents, not counted above
+	Total         int `json:"total"`
+
+	// Points staked on the votes counting towards quorum and on the others
+	StakedFor     float64 `json:"staked_for,omitempty"`
+	StakedAgainst float64 `json:"staked_against,omitempty"`
+}
+
+// Participation returns the share of the electorate that voted, abstentions included
+func (t VoteTally) Participation(electorate int) float64 {
+	if electorate == 0 {
+		return 0
+	}
+	return float64(t.Total) / float64(electorate)
+}
+
+// Validate checks the condition's field and operator
+func (c *VoteCondition) Validate() error {
+	if c.Field == "" || !tokenPattern.MatchString(c.Field) {
+		return fmt.Errorf("invalid condition field %q", c.Field)
+	}
+	switch c.Operator {
+	case "<", "<=", ">", ">=":
+		if _, ok := c.Value.(float64); !ok {
+			return fmt.Errorf("condition operator %s needs a numeric value", c.Operator)
+		}
+	case "==", "!=":
+	default:
+		return fmt.Errorf("invalid condition operator %q", c.Operator)
+	}
+	return nil
+}
+
+// Evaluate reports whether the condition holds for a proposal's content.
+// A missing or mistyped field does not satisfy it.
+func (c *VoteCondition) Evaluate(content map[string]any) bool {
+	var value any = content
+	for _, key := range strings.Split(c.Field, ".") {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		if value, ok = fields[key]; !ok {
+			return false
+		}
+	}
+
+	switch c.Operator {
+	case "==":
+		return fmt.Sprint(value) == fmt.Sprint(c.Value)
+	case "!=":
+		return fmt.Sprint(value) != fmt.Sprint(c.Value)
+	}
+
+	actual, ok := value.(float64)
+	if !ok {
+		if n, isInt := value.(int); isInt {
+			actual, ok = float64(n), true
+		}
+	}
+	limit, isNumber := c.Value.(float64)
+	if !ok || !isNumber {
+		return false
+	}
+	switch c.Operator {
+	case "<":
+		return actual < limit
+	case "<=":
+		return actual <= limit
+	case ">":
+		return actual > limit
+	case ">=":
+		return actual >= limit
+	}
+	return false
+}
+
+// ValidateChoice checks a vote's choice and condition
+func ValidateChoice(choice VoteChoice, condition *VoteCondition) error {
+	switch choice {
+	case "", VoteChoiceSupport, VoteChoiceReject, VoteChoiceAbstain:
+		if condition != nil {
+			return fmt.Errorf("only conditional votes take a condition")
+		}
+	case VoteChoiceConditional:
+		if condition == nil {
+			return fmt.Errorf("conditional vote has no condition")
+		}
+		return condition.Validate()
+	default:
+		return fmt.Errorf("invalid vote choice %q", choice)
+	}
+	return nil
+}
+
+// EffectiveChoice returns the vote's choice, deriving it from Support for
+// votes cast before choices existed
+func (v Vote) EffectiveChoice() VoteChoice {
+	if v.Choice != "" {
+		return v.Choice
+	}
+	if v.Support {
+		return VoteChoiceSupport
+	}
+	return VoteChoiceReject
+}
+
+// inFavor reports whether a vote counts towards quorum (must be called with p.mu held).
+// Conditional votes are evaluated against the content until the proposal is
+// finalized and their outcome is fixed. Non-binding votes never count.
+func (p *Proposal) inFavor(v Vote) bool {
+	if v.NonBinding {
+		return false
+	}
+	switch v.EffectiveChoice() {
+	case VoteChoiceSupport:
+		return true
+	case VoteChoiceConditional:
+		if v.ConditionMet != nil {
+			return *v.ConditionMet
+		}
+		return v.Condition != nil && v.Condition.Evaluate(p.Content)
+	}
+	return false
+}
+
+// InFavor reports whether a vote counts towards the proposal's quorum (thread-safe)
+func (p *Proposal) InFavor(v Vote) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.inFavor(v)
+}
+
+// Tally counts the proposal's votes by choice (thread-safe)
+func (p *Proposal) Tally() VoteTally {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var tally VoteTally
+	for _, vote := range p.Votes {
+		if vote.NonBinding {
+			tally.NonBinding++
+			continue
+		}
+		tally.Total++
+		if p.inFavor(vote) {
+			tally.StakedFor += vote.Stake
+		} else {
+			tally.StakedAgainst += vote.Stake
+		}
+		switch vote.EffectiveChoice() {
+		case VoteChoiceSupport:
+			tally.Support++
+		case VoteChoiceAbstain:
+			tally.Abstain++
+		case VoteChoiceConditional:
+			tally.Conditional++
+			if p.inFavor(vote) {
+				tally.ConditionsMet++
+			}
+		default:
+			tally.Reject++
+		}
+	}
+	return tally
+}
+
+// ResolveConditions evaluates every conditional vote against the content and
+// records the outcome, so it no longer changes (thread-safe)
+func (p *Proposal) ResolveConditions() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, vote := range p.Votes {
+		if vote.EffectiveChoice() != VoteChoiceConditional || vote.ConditionMet != nil {
+			continue
+		}
+		met := vote.Condition != nil && vote.Condition.Evaluate(p.Content)
+		vote.ConditionMet = &met
+		p.Votes[id] = vote
+	}
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebhookEventProposalExecuted is delivered when an accepted proposal with a
+// window executes at the window's start
+const WebhookEventProposalExecuted = "proposal.executed"
+
+// ProposalWindow is the time window in which a proposal is valid, e.g. a
+// discount during a flash sale from 2pm to 4pm. Voting ends at the latest when
+// the window closes; an accepted proposal executes once the window opens.
+type ProposalWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ParseProposalWindow validates a window given as RFC 3339 times, rejecting
+// windows that closed before now
+func ParseProposalWindow(start, end string, now time.Time) (*ProposalWindow, error) {
+	from, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return nil, fmt.Errorf("window start must be an RFC 3339 time: %w", err)
+	}
+	to, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return nil, fmt.Errorf("window end must be an RFC 3339 time: %w", err)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("window ends at %s, before it starts", end)
+	}
+	if !to.After(now) {
+		return nil, fmt.Errorf("window closed at %s", end)
+	}
+	return &ProposalWindow{Start: from, End: to}, nil
+}
+
+// Open reports whether now is within the window
+func (w *ProposalWindow) Open(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// Closed reports whether the window ended by now
+func (w *ProposalWindow) Closed(now time.Time) bool {
+	return !now.Before(w.End)
+}
+
+// Execute marks an accepted proposal with a window executed at now, if its
+// window is open and it did not execute yet, and reports whether it did
+func (p *Proposal) Execute(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Window == nil || p.Status != ProposalStatusAccepted || !p.ExecutedAt.IsZero() || !p.Window.Open(now) {
+		return false
+	}
+	p.ExecutedAt = now
+	return true
+}
@@ -78,7 +78,7 @@ cd "$PROJECT_ROOT"
 # Build web server binary if not exists
 if [ ! -f "bin/web-server" ]; then
     echo "[BUILD] Building web server..."
-    go build -o bin/web-server web/server.go
+    go build -o bin/web-server ./web
 fi
 
 ./bin/web-server > logs/web-ui.log 2>&1 &
@@ -50,7 +50,7 @@ echo "  Building api-server..."
 go build -o bin/api-server cmd/api-server/main.go || { echo "❌ Failed to build api-server"; exit 1; }
 
 echo "  Building web-server..."
-go build -o bin/web-server web/server.go || { echo "❌ Failed to build web-server"; exit 1; }
+go build -o bin/web-server ./web || { echo "❌ Failed to build web-server"; exit 1; }
 
 echo "✓ All binaries built"
 echo ""
@@ -21,7 +21,7 @@ cd "$PROJECT_ROOT"
 
 if [ ! -f "bin/web-server" ]; then
     echo "[BUILD] Building web server..."
-    go build -o bin/web-server web/server.go
+    go build -o bin/web-server ./web
     echo "✅ Build complete"
     echo ""
 fi
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TestAgentValidate checks typed metadata and capabilities are validated
+func TestAgentValidate(t *testing.T) {
+	valid := func() *types.Agent {
+		return &types.Agent{
+			ID:           "agent-1",
+			Framework:    types.FrameworkLangChain,
+			Language:     "python",
+			Version:      "v1.2.3-beta.1",
+			Region:       "eu-west-1",
+			Namespace:    "team/research",
+			DependsOn:    []string{"agent-2"},
+			Capabilities: types.ParseCapabilities([]string{"search@1.2", "summarize"}),
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Expected a valid agent, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *types.Agent)
+		errMsg string
+	}{
+		{"missing ID", func(a *types.Agent) { a.ID = "" }, "ID is required"},
+		{"framework with spaces", func(a *types.Agent) { a.Framework = "lang chain" }, "invalid framework"},
+		{"language", func(a *types.Agent) { a.Language = "-python" }, "invalid language"},
+		{"version", func(a *types.Agent) { a.Version = "latest" }, "invalid version"},
+		{"region", func(a *types.Agent) { a.Region = "eu west" }, "invalid region"},
+		{"namespace", func(a *types.Agent) { a.Namespace = "team research" }, "invalid namespace"},
+		{"dependency", func(a *types.Agent) { a.DependsOn = []string{""} }, "invalid dependency"},
+		{"capability name", func(a *types.Agent) { a.Capabilities[0].Name = "web search" }, "invalid capability name"},
+		{"capability version", func(a *types.Agent) { a.Capabilities[0].Version = "one" }, "invalid version"},
+		{"capability schema", func(a *types.Agent) { a.Capabilities[0].Schema = json.RawMessage(`[1]`) }, "must be a JSON object"},
+		{"duplicate capability", func(a *types.Agent) { a.Capabilities[1].Name = "search" }, "duplicate capability"},
+	}
+	for _, tt := range tests {
+		agent := valid()
+		tt.modify(agent)
+		err := agent.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+// TestAgentPromoteMetadata checks well-known metadata moves to typed fields
+func TestAgentPromoteMetadata(t *testing.T) {
+	agent := &types.Agent{
+		ID:    "agent-1",
+		Model: "gpt-4",
+		Metadata: map[string]string{
+			"framework": "openai",
+			"model":     "gpt-3.5",
+			"language":  "python",
+			"version":   "2.0",
+			"region":    "us-east-1",
+			"namespace": "sales",
+			"team":      "growth",
+		},
+	}
+	agent.PromoteMetadata()
+
+	if agent.Framework != "openai" || agent.Language != "python" || agent.Version != "2.0" ||
+		agent.Region != "us-east-1" || agent.Namespace != "sales" {
+		t.Errorf("Expected metadata promoted, got %+v", agent)
+	}
+	if agent.Model != "gpt-4" {
+		t.Errorf("Expected the typed model to win over metadata, got %q", agent.Model)
+	}
+	if len(agent.Metadata) != 1 || agent.Metadata["team"] != "growth" {
+		t.Errorf("Expected only unknown metadata left, got %v", agent.Metadata)
+	}
+
+	// Agents without metadata are left alone
+	bare := &types.Agent{ID: "agent-2"}
+	bare.PromoteMetadata()
+	if bare.Framework != "" || bare.Metadata != nil {
+		t.Errorf("Expected an agent without metadata unchanged, got %+v", bare)
+	}
+}
+
+// TestCapabilityUnmarshalLegacy checks capabilities decode from both JSON forms
+func TestCapabilityUnmarshalLegacy(t *testing.T) {
+	var agent types.Agent
+	data := `{"id":"agent-1","capabilities":["search@1.2","summarize",{"name":"translate","version":"3","schema":{"type":"object"}}]}`
+	if err := json.Unmarshal([]byte(data), &agent); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if len(agent.Capabilities) != 3 {
+		t.Fatalf("Expected 3 capabilities, got %d", len(agent.Capabilities))
+	}
+	if c := agent.Capabilities[0]; c.Name != "search" || c.Version != "1.2" {
+		t.Errorf("Expected search@1.2 from the legacy string, got %+v", c)
+	}
+	if c := agent.Capabilities[1]; c.Name != "summarize" || c.Version != "" {
+		t.Errorf("Expected summarize without version, got %+v", c)
+	}
+	if c := agent.Capabilities[2]; c.Name != "translate" || c.Version != "3" || string(c.Schema) != `{"type":"object"}` {
+		t.Errorf("Expected the object form decoded, got %+v", c)
+	}
+
+	// Re-encoding always uses the object form
+	encoded, _ := json.Marshal(agent.Capabilities[0])
+	if string(encoded) != `{"name":"search","version":"1.2"}` {
+		t.Errorf("Expected the object form encoded, got %s", encoded)
+	}
+
+	var invalid types.Capability
+	if err := json.Unmarshal([]byte(`42`), &invalid); err == nil {
+		t.Error("Expected a number to be rejected")
+	}
+}
+
+// TestAgentProfileMigration checks agents stored with string capabilities and
+// profile metadata are upgraded on read
+func TestAgentProfileMigration(t *testing.T) {
+	legacy := `{"schema_version":1,"id":"agent-1","framework":"openai",` +
+		`"capabilities":["search@1.2","summarize"],` +
+		`"metadata":{"framework":"langchain","model":"gpt-4","language":"python","version":"1.0","team":"growth"}}`
+
+	var agent types.Agent
+	if err := state.DecodeRecord("agent:agent-1", []byte(legacy), &agent); err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if agent.Framework != "openai" {
+		t.Errorf("Expected the typed framework to win, got %q", agent.Framework)
+	}
+	if agent.Model != "gpt-4" || agent.Language != "python" || agent.Version != "1.0" {
+		t.Errorf("Expected profile metadata promoted, got %+v", agent)
+	}
+	if len(agent.Metadata) != 1 || agent.Metadata["team"] != "growth" {
+		t.Errorf("Expected only unknown metadata left, got %v", agent.Metadata)
+	}
+	if len(agent.Capabilities) != 2 || agent.Capabilities[0].String() != "search@1.2" {
+		t.Errorf("Expected structured capabilities, got %+v", agent.Capabilities)
+	}
+
+	// Version 0 records go through both steps
+	var old types.Agent
+	if err := state.DecodeRecord("agent:agent-2", []byte(`{"id":"agent-2","metadata":{"model":"claude"}}`), &old); err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if old.Model != "claude" || old.Capabilities == nil || old.Metadata == nil {
+		t.Errorf("Expected a version 0 agent defaulted and promoted, got %+v", old)
+	}
+}
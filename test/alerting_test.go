@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/alerting"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+type staticMetrics struct {
+	health    []types.ServiceHealth
+	snapshot  *types.GraphSnapshot
+	proposals []*types.Proposal
+}
+
+func (s staticMetrics) ListServiceHealth(ctx context.Context) ([]types.ServiceHealth, error) {
+	return s.health, nil
+}
+
+func (s staticMetrics) LoadGraphSnapshot(ctx context.Context) (*types.GraphSnapshot, error) {
+	if s.snapshot == nil {
+		return nil, errors.New("no snapshot")
+	}
+	return s.snapshot, nil
+}
+
+func (s staticMetrics) ListProposals(ctx context.Context) ([]*types.Proposal, error) {
+	return s.proposals, nil
+}
+
+func TestParseAlertRules(t *testing.T) {
+	rules, err := types.ParseAlertRules("consumer_lag>10000, change(density,5m)<-0.5,proposal_backlog>100")
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	if len(rules) != 3 || rules[1].Metric != types.AlertMetricDensity || rules[1].Window != 5*time.Minute || rules[1].Above || rules[1].Limit != -0.5 {
+		t.Fatalf("Unexpected rules: %+v", rules)
+	}
+	if rules[1].String() != "change(density,5m0s)<-0.5" || rules[0].String() != "consumer_lag>10000" {
+		t.Errorf("Unexpected rule names %q and %q", rules[0], rules[1])
+	}
+
+	for _, invalid := range []string{"", "consumer_lag", "queue_depth>5", "density<low", "change(density)<-0.5", "change(density,-5m)<-0.5"} {
+		if _, err := types.ParseAlertRules(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestAlertingEngine(t *testing.T) {
+	rules, _ := types.ParseAlertRules("proposal_backlog>2,change(density,1m)<-0.5")
+	engine := alerting.NewEngine(rules)
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	source := staticMetrics{
+		health: []types.ServiceHealth{
+			{Service: "topology-manager", ConsumerLag: map[string]int64{"topology-manager": 40, "topology-lifecycle": 7}},
+			{Service: "knowledge-manager", ConsumerLag: map[string]int64{"knowledge-manager": 12}},
+		},
+		snapshot: &types.GraphSnapshot{Stats: types.GraphStats{Density: 0.6, TotalAgents: 5}},
+		proposals: []*types.Proposal{
+			{Status: types.ProposalStatusPending},
+			{Status: types.ProposalStatusAccepted},
+		},
+	}
+	sample, err := alerting.Collect(context.Background(), source, start)
+	if err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	if sample.Metrics[types.AlertMetricConsumerLag] != 40 || sample.Metrics[types.AlertMetricProposalBacklog] != 1 || sample.Metrics[types.AlertMetricDensity] != 0.6 {
+		t.Fatalf("Unexpected sample: %+v", sample.Metrics)
+	}
+	if fired, _ := engine.Evaluate(sample); len(fired) != 0 {
+		t.Fatalf("Expected nothing to fire on a healthy mesh, got %+v", fired)
+	}
+
+	// The backlog grows and the topology collapses within a minute
+	source.proposals = append(source.proposals, &types.Proposal{Status: types.ProposalStatusPending}, &types.Proposal{Status: types.ProposalStatusPending})
+	source.snapshot = &types.GraphSnapshot{Stats: types.GraphStats{Density: 0.2}}
+	sample, _ = alerting.Collect(context.Background(), source, start.Add(30*time.Second))
+	fired, _ := engine.Evaluate(sample)
+	if len(fired) != 1 || fired[0].Rule != "proposal_backlog>2" || fired[0].Value != 3 {
+		t.Fatalf("Expected only the backlog to fire before a minute of history, got %+v", fired)
+	}
+	sample.At = start.Add(time.Minute)
+	fired, _ = engine.Evaluate(sample)
+	if len(fired) != 1 || fired[0].Metric != types.AlertMetricDensity || fired[0].Value > -0.66 || fired[0].Value < -0.67 {
+		t.Fatalf("Expected the density collapse to fire once, got %+v", fired)
+	}
+	if fired, _ = engine.Evaluate(sample); len(fired) != 0 || len(engine.Firing()) != 2 {
+		t.Errorf("Expected firing alerts not to fire again, got %+v", fired)
+	}
+
+	// Draining the backlog resolves its alert; the density stays low but no longer falls
+	source.proposals = nil
+	sample, _ = alerting.Collect(context.Background(), source, start.Add(3*time.Minute))
+	fired, resolved := engine.Evaluate(sample)
+	if len(fired) != 0 || len(resolved) != 2 || resolved[0].ResolvedAt == nil || !resolved[0].ResolvedAt.Equal(sample.At) {
+		t.Errorf("Expected both alerts to resolve, got %+v", resolved)
+	}
+
+	// Without a snapshot the topology metrics are unmeasured, not zero
+	source.snapshot = nil
+	sample, _ = alerting.Collect(context.Background(), source, start.Add(4*time.Minute))
+	if _, ok := sample.Metrics[types.AlertMetricDensity]; ok {
+		t.Error("Expected no density without a snapshot")
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/amql"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestAMQLQuery(t *testing.T) {
+	query, err := amql.Parse("select insights WHERE topic = 'pricing' AND confidence > 0.8 AND tags IN ('churn', 'renewal') ORDER BY created_at DESC LIMIT 2")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+	if query.Source != amql.SourceInsights || len(query.Conditions) != 3 || !query.Descending || query.Limit != 2 {
+		t.Fatalf("Unexpected parsed query: %+v", query)
+	}
+
+	now := time.Now()
+	var insights []*types.Insight
+	for i, confidence := range []float64{0.9, 0.95, 0.7, 0.85} {
+		insight := types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Price objection", confidence)
+		insight.Tags = []string{"churn"}
+		insight.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		insights = append(insights, insight)
+	}
+	insights[1].Topic = "delivery"
+
+	records, err := amql.Records(insights)
+	if err != nil {
+		t.Fatalf("Failed to convert insights: %v", err)
+	}
+	results := query.Run(records)
+	if len(results) != 2 || results[0]["id"] != string(insights[3].ID) || results[1]["id"] != string(insights[0].ID) {
+		t.Errorf("Expected the two confident pricing insights, newest first, got %v", results)
+	}
+
+	// Timestamps compare as times, nested fields are reached with dots
+	query, err = amql.Parse("SELECT insights WHERE created_at >= '" + now.Add(2*time.Minute).Format(time.RFC3339Nano) + "' AND verification.status != 'rejected'")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+	if results := query.Run(records); len(results) != 2 {
+		t.Errorf("Expected the two newest insights, got %d", len(results))
+	}
+}
+
+func TestAMQLBoolOrder(t *testing.T) {
+	records := []map[string]any{
+		{"id": "a", "verified": true},
+		{"id": "b", "verified": false},
+		{"id": "c", "verified": true},
+	}
+	ids := func(results []map[string]any) (joined string) {
+		for _, record := range results {
+			joined += record["id"].(string)
+		}
+		return joined
+	}
+
+	// false sorts before true, so opposite conditions agree
+	for input, want := range map[string]string{
+		"SELECT insights WHERE verified > false":  "ac",
+		"SELECT insights WHERE verified < true":   "b",
+		"SELECT insights WHERE verified >= false": "abc",
+		"SELECT insights WHERE verified <= false": "b",
+		"SELECT insights ORDER BY verified":       "bac",
+		"SELECT insights ORDER BY verified DESC":  "acb",
+	} {
+		query, err := amql.Parse(input)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", input, err)
+		}
+		if got := ids(query.Run(records)); got != want {
+			t.Errorf("%s: expected %s, got %s", input, want, got)
+		}
+	}
+}
+
+func TestAMQLParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"SELECT proposals",
+		"SELECT insights WHERE confidence >",
+		"SELECT insights WHERE topic = 'pricing",
+		"SELECT insights WHERE topic IN 'pricing'",
+		"SELECT insights LIMIT 0",
+		"SELECT insights LIMIT 5000",
+		"SELECT insights ORDER created_at",
+		"SELECT insights WHERE topic = 'pricing' OR topic = 'delivery'",
+	} {
+		if _, err := amql.Parse(input); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
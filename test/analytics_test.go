@@ -0,0 +1,175 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/analytics"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestInsightAnalyticsPrivacy(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	start, end := analytics.Window(now, 3*24*time.Hour, 24*time.Hour)
+
+	var insights []*types.Insight
+	add := func(role, topic string, n int, at time.Time) {
+		for i := 0; i < n; i++ {
+			insight := types.NewInsight("agent", role, types.InsightTypeBehaviorPattern, topic, "content", 0.8)
+			insight.CreatedAt = at
+			insights = append(insights, insight)
+		}
+	}
+	add("sales", "pricing", 40, start.Add(time.Hour))
+	add("support", "refunds", 500, start.Add(25*time.Hour))
+	add("support", "patient_x_complaint", 1, start.Add(49*time.Hour))
+	add("sales", "pricing", 5, now) // In the open bucket, not reported
+
+	opts := analytics.Options{Start: start, End: end, Bucket: 24 * time.Hour, Epsilon: 1, Delta: 1e-6}
+	exact := analytics.Compute(insights, opts, now)
+	if exact.Privacy != nil || exact.Total != 541 || len(exact.Trend) != 3 || exact.Trend[1].Count != 500 {
+		t.Fatalf("Unexpected exact analytics: %+v", exact)
+	}
+
+	// Support is regulated: its counts are noised, sales' stay exact
+	opts.PrivateTeams = []string{"support"}
+	revealed := 0
+	for seed := 0; seed < 200; seed++ {
+		opts.Secret = []byte(fmt.Sprint(seed))
+		noised := analytics.Compute(insights, opts, now)
+		if noised.Privacy == nil {
+			t.Fatal("Expected privacy parameters on a noised release")
+		}
+		for _, topic := range noised.Topics {
+			switch topic.Topic {
+			case "pricing":
+				if topic.Count < 40 {
+					t.Fatalf("Exact counts must not lose insights: %d", topic.Count)
+				}
+			case "refunds":
+				if topic.Count < 470 || topic.Count > 530 {
+					t.Fatalf("Noise on a large count should be small: %d", topic.Count)
+				}
+			case "patient_x_complaint":
+				revealed++
+			}
+		}
+		if noised.Trend[0].Count < 40 {
+			t.Fatalf("Trend lost exact counts: %+v", noised.Trend)
+		}
+	}
+	if revealed > 0 {
+		t.Errorf("A topic seen in a single regulated insight was revealed %d times", revealed)
+	}
+}
+
+func TestInsightAnalyticsNoiseSharedAcrossSpans(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	var insights []*types.Insight
+	for day := 1; day <= 7; day++ {
+		for i := 0; i < 20; i++ {
+			insight := types.NewInsight("agent", "support", types.InsightTypeBehaviorPattern, "refunds", "content", 0.8)
+			insight.CreatedAt = now.Truncate(24 * time.Hour).Add(-time.Duration(day)*24*time.Hour + time.Hour)
+			insights = append(insights, insight)
+		}
+	}
+
+	release := func(span time.Duration, secret string) map[time.Time]int {
+		opts := analytics.Options{Bucket: analytics.Grid[span], PrivateTeams: []string{"*"}, Epsilon: 1, Delta: 1e-6, Secret: []byte(secret)}
+		opts.Start, opts.End = analytics.Window(now, span, opts.Bucket)
+		counts := make(map[time.Time]int)
+		for _, point := range analytics.Compute(insights, opts, now).Trend {
+			counts[point.Start] = point.Count
+		}
+		return counts
+	}
+
+	// Both spans count the last week's days; each day must carry the same noise
+	week, month := release(7*24*time.Hour, "secret"), release(30*24*time.Hour, "secret")
+	noised := 0
+	for start, count := range week {
+		if month[start] != count {
+			t.Errorf("Expected the day starting %s counted the same by both spans, got %d and %d", start, count, month[start])
+		}
+		if count != 20 {
+			noised++
+		}
+	}
+	if noised == 0 {
+		t.Error("Expected noise on the regulated counts")
+	}
+
+	// Another secret draws other noise
+	other := release(7*24*time.Hour, "other")
+	differs := false
+	for start, count := range week {
+		differs = differs || other[start] != count
+	}
+	if !differs {
+		t.Error("Expected the noise derived from the secret")
+	}
+
+	// Spans off the grid, or with another bucket, are not served
+	for _, span := range []time.Duration{25 * time.Hour, 8 * 24 * time.Hour} {
+		if _, ok := analytics.Grid[span]; ok {
+			t.Errorf("Expected a %s span off the grid", span)
+		}
+	}
+}
+
+func TestCommunicationMatrix(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	start, end := analytics.Window(now, 2*time.Hour, time.Hour)
+
+	agents := map[types.AgentID]*types.Agent{
+		"sales-1":     {ID: "sales-1", Role: "sales"},
+		"inventory-1": {ID: "inventory-1", Role: "inventory"},
+		"support-1":   {ID: "support-1", Role: "support"},
+	}
+	snapshot := func(at time.Time, salesUsage, supportUsage int64) *types.GraphSnapshot {
+		return &types.GraphSnapshot{
+			Agents: agents,
+			Edges: map[types.EdgeID]*types.Edge{
+				"edge-sales-1-inventory-1": {SourceID: "sales-1", TargetID: "inventory-1", Weight: 0.8, Usage: salesUsage,
+					UsageByType: map[types.MessageType]int64{types.MessageTypeTask: salesUsage}},
+				"edge-inventory-1-sales-1": {SourceID: "inventory-1", TargetID: "sales-1", Weight: 0.6, Usage: 1},
+				"edge-support-1-sales-1":   {SourceID: "support-1", TargetID: "sales-1", Weight: 0.4, Usage: supportUsage},
+			},
+			Timestamp: at,
+		}
+	}
+	snapshots := []*types.GraphSnapshot{
+		snapshot(start.Add(-10*time.Minute), 100, 5), // Baseline
+		snapshot(start.Add(30*time.Minute), 130, 5),
+		snapshot(start.Add(90*time.Minute), 150, 9),
+		snapshot(end.Add(10*time.Minute), 500, 50), // In the open bucket, not reported
+	}
+
+	matrix := analytics.CommunicationMatrix(snapshots, analytics.Options{Start: start, End: end, Bucket: time.Hour}, now)
+	if len(matrix.Pairs) != 2 || len(matrix.Roles) != 3 {
+		t.Fatalf("Expected 2 role pairs of 3 roles, got %+v", matrix)
+	}
+
+	sales := matrix.Pairs[0]
+	if sales.Roles != [2]string{"inventory", "sales"} || sales.Messages != 50 || sales.ByType[types.MessageTypeTask] != 50 {
+		t.Errorf("Expected 50 task messages between inventory and sales, got %+v", sales)
+	}
+	if sales.Edges != 2 || sales.AvgWeight < 0.69 || sales.AvgWeight > 0.71 {
+		t.Errorf("Expected both directions averaged to 0.7, got %d edges at %f", sales.Edges, sales.AvgWeight)
+	}
+	if len(sales.Windows) != 2 || sales.Windows[0].Messages != 30 || sales.Windows[1].Messages != 20 || !sales.Windows[1].Start.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected 30 then 20 messages per hour, got %+v", sales.Windows)
+	}
+
+	support := matrix.Pairs[1]
+	if support.Roles != [2]string{"sales", "support"} || support.Messages != 4 || support.Windows[0].Messages != 0 {
+		t.Errorf("Expected 4 messages between sales and support in the second hour, got %+v", support)
+	}
+
+	// Without a baseline the first snapshot only sets where counting starts
+	matrix = analytics.CommunicationMatrix(snapshots[1:], analytics.Options{Start: start, End: end, Bucket: time.Hour}, now)
+	if matrix.Pairs[0].Messages != 20 {
+		t.Errorf("Expected traffic counted from the first snapshot, got %+v", matrix.Pairs[0])
+	}
+}
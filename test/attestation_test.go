@@ -0,0 +1,87 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestAgentAttestation(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keys, err := types.ParseAttestationKeys("ops=" + base64.StdEncoding.EncodeToString(public))
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+
+	now := time.Now()
+	valid := types.Attestation{
+		Subject:      "agent-fraud-1",
+		Issuer:       "ops",
+		Capabilities: []string{"fraud_detection"},
+		DataAccess:   []string{"payments"},
+		IssuedAt:     now.UTC(),
+		ExpiresAt:    now.Add(time.Hour).UTC(),
+	}
+	valid.Sign(private)
+
+	// Attestations survive the JSON round trip of an agent_joined event
+	data, _ := json.Marshal(valid)
+	var decoded types.Attestation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode attestation: %v", err)
+	}
+
+	forged := decoded
+	forged.DataAccess = []string{"payments", "hr"}
+	expired := valid
+	expired.ExpiresAt = now.Add(-time.Minute).UTC()
+	expired.Sign(private)
+	untrusted := valid
+	untrusted.Issuer = "someone"
+
+	agent := &types.Agent{
+		ID:           "agent-fraud-1",
+		Capabilities: types.ParseCapabilities([]string{"fraud_detection", "risk_scoring"}),
+		Attestations: []types.Attestation{decoded, forged, expired, untrusted},
+		Attested:     &types.AttestedClaims{Capabilities: []string{"risk_scoring"}}, // Self-claimed
+	}
+	if errs := agent.VerifyAttestations(keys, now); len(errs) != 3 {
+		t.Fatalf("Expected the forged, expired and untrusted attestations to be rejected, got %v", errs)
+	}
+	if !agent.HasAttestedCapability("fraud_detection") || agent.HasAttestedCapability("risk_scoring") {
+		t.Errorf("Expected only fraud_detection to be attested, got %+v", agent.Attested)
+	}
+	if !agent.HasDataAccess("payments") || agent.HasDataAccess("hr") {
+		t.Errorf("Expected only payments access, got %+v", agent.Attested)
+	}
+	if !agent.Qualifies("risk_scoring", false) || agent.Qualifies("risk_scoring", true) {
+		t.Error("Expected risk_scoring to qualify only without attestation")
+	}
+
+	other := &types.Agent{ID: "agent-fraud-2", Attestations: []types.Attestation{decoded}}
+	if errs := other.VerifyAttestations(keys, now); len(errs) != 1 || other.Attested != nil {
+		t.Errorf("Expected another agent's attestation to be rejected, got %v", errs)
+	}
+
+	insight := types.NewInsight("agent-fraud-3", "fraud", types.InsightTypeFraudPattern, "payments", "Card testing", 0.9)
+	insight.RequiredAccess = "payments"
+	if !insight.VisibleTo(agent) || insight.VisibleTo(other) {
+		t.Error("Expected the insight to be visible only with attested payments access")
+	}
+	insight.Privacy = types.InsightPrivacyRestricted
+	if insight.VisibleTo(agent) {
+		t.Error("Expected restricted privacy to still apply to attested agents")
+	}
+
+	if _, err := types.ParseAttestationKeys("ops=not-a-key"); err == nil {
+		t.Error("Expected an invalid key to be rejected")
+	}
+}
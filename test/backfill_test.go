@@ -0,0 +1,53 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestBackfillProgress(t *testing.T) {
+	if mode, err := types.ParseStartMode(""); err != nil || mode != types.StartModeResume {
+		t.Errorf("Expected an empty start mode to resume, got %q (%v)", mode, err)
+	}
+	if _, err := types.ParseStartMode("beginning"); err == nil {
+		t.Error("Expected an unknown start mode to be rejected")
+	}
+
+	now := time.Now()
+	progress := &types.BackfillProgress{Manager: "knowledge", Total: 4, StartedAt: now}
+	for i := 0; i < 3; i++ {
+		if progress.Observe(now) {
+			t.Fatalf("Expected the backfill to complete on the 4th message, completed on message %d", i+1)
+		}
+	}
+	if progress.Percent() != 75 {
+		t.Errorf("Expected 75%% progress, got %.1f", progress.Percent())
+	}
+	if !progress.Observe(now) || progress.CompletedAt == nil || progress.Percent() != 100 {
+		t.Errorf("Expected the backfill to complete, got %+v", progress)
+	}
+	if progress.Observe(now) || progress.Processed != 4 {
+		t.Errorf("Expected messages after completion not to count, got %d processed", progress.Processed)
+	}
+}
+
+func TestParseReplayFrom(t *testing.T) {
+	if mode, err := types.ParseStartMode("replay"); err != nil || mode != types.StartModeReplay {
+		t.Errorf("Expected the replay start mode, got %q (%v)", mode, err)
+	}
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	if from, err := types.ParseReplayFrom("2025-03-07T08:30:00Z", now); err != nil || !from.Equal(time.Date(2025, 3, 7, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected an RFC 3339 timestamp, got %s (%v)", from, err)
+	}
+	if from, err := types.ParseReplayFrom("72h", now); err != nil || !from.Equal(now.Add(-72*time.Hour)) {
+		t.Errorf("Expected 72h before now, got %s (%v)", from, err)
+	}
+	for _, value := range []string{"", "yesterday", "-1h", "0s"} {
+		if _, err := types.ParseReplayFrom(value, now); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
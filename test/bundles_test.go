@@ -0,0 +1,147 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func regionalPrices(regions ...string) []*consensus.ProposalRequest {
+	requests := make([]*consensus.ProposalRequest, len(regions))
+	for i, region := range regions {
+		requests[i] = &consensus.ProposalRequest{
+			ProposerID: "agent-pricing-1",
+			Type:       types.ProposalTypeAction,
+			Content:    map[string]any{"action": "price_change", "region": region, "new_price": 11.0},
+		}
+	}
+	return requests
+}
+
+func bundleConsensus(agents int) (*consensus.BeeConsensus, []types.AgentID) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	ids := make([]types.AgentID, agents)
+	for i := range ids {
+		ids[i] = types.NewAgentID()
+		bc.RegisterAgent(ids[i])
+	}
+	return bc, ids
+}
+
+func TestBundleAllOrNothing(t *testing.T) {
+	bc, agents := bundleConsensus(5)
+	bundle, err := bc.ProposeBundle(&consensus.BundleRequest{
+		ProposerID: "agent-pricing-1",
+		Rule:       types.BundleRuleAllOrNothing,
+		Proposals:  regionalPrices("eu", "us", "apac"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose bundle: %v", err)
+	}
+	if len(bundle.Proposals) != 3 {
+		t.Fatalf("Expected 3 member proposals, got %d", len(bundle.Proposals))
+	}
+
+	// A single-member vote does not decide the slate, even at quorum
+	eu, _ := bc.GetProposal(bundle.Proposals[0])
+	for _, voter := range agents[:3] {
+		if err := bc.Vote(eu.ID, voter, true, 0.8); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if eu.Status != types.ProposalStatusPending || eu.Bundle != bundle.ID {
+		t.Fatalf("Expected a pending bundled member, got %s in %q", eu.Status, eu.Bundle)
+	}
+
+	// One vote per agent covers the slate
+	for _, voter := range agents[:3] {
+		if err := bc.VoteBundle(bundle.ID, &consensus.VoteRequest{VoterID: voter, Support: true, Intensity: 0.8}); err != nil {
+			t.Fatalf("Failed to vote on bundle: %v", err)
+		}
+	}
+	if bundle.Status != types.ProposalStatusAccepted {
+		t.Fatalf("Expected the bundle to be accepted, got %s", bundle.Status)
+	}
+	for _, id := range bundle.Proposals {
+		if proposal, _ := bc.GetProposal(id); proposal.Status != types.ProposalStatusAccepted {
+			t.Errorf("Expected member %s to be accepted, got %s", id, proposal.Status)
+		}
+	}
+
+	if err := bc.VoteBundle(bundle.ID, &consensus.VoteRequest{VoterID: agents[4], Support: true, Intensity: 0.8}); err == nil {
+		t.Error("Expected a vote on a decided bundle to be rejected")
+	}
+}
+
+func TestBundleBestOf(t *testing.T) {
+	bc, agents := bundleConsensus(5)
+	bundle, err := bc.ProposeBundle(&consensus.BundleRequest{
+		ProposerID: "agent-pricing-1",
+		Rule:       types.BundleRuleBestOf,
+		Proposals:  regionalPrices("eu", "us"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose bundle: %v", err)
+	}
+	if bundle.BestOf != 1 {
+		t.Errorf("Expected best_of to default to 1, got %d", bundle.BestOf)
+	}
+
+	// eu reaches 4/5 support, us only 3/5
+	for i, id := range bundle.Proposals {
+		for _, voter := range agents[:4-i] {
+			if err := bc.Vote(id, voter, true, 0.8); err != nil {
+				t.Fatalf("Failed to vote: %v", err)
+			}
+		}
+	}
+
+	eu, _ := bc.GetProposal(bundle.Proposals[0])
+	us, _ := bc.GetProposal(bundle.Proposals[1])
+	if eu.Status != types.ProposalStatusAccepted || us.Status != types.ProposalStatusRejected {
+		t.Errorf("Expected eu accepted and us rejected, got %s and %s", eu.Status, us.Status)
+	}
+	if bundle.Status != types.ProposalStatusAccepted {
+		t.Errorf("Expected the bundle to be accepted, got %s", bundle.Status)
+	}
+}
+
+func TestBundleRequestValidation(t *testing.T) {
+	bc, _ := bundleConsensus(3)
+	invalid := []*consensus.BundleRequest{
+		{Rule: types.BundleRuleAllOrNothing, Proposals: regionalPrices("eu")},
+		{Rule: "majority", Proposals: regionalPrices("eu", "us")},
+		{Rule: types.BundleRuleBestOf, BestOf: 3, Proposals: regionalPrices("eu", "us")},
+		{Rule: types.BundleRuleAllOrNothing, BestOf: 1, Proposals: regionalPrices("eu", "us")},
+	}
+	for _, request := range invalid {
+		if _, err := bc.ProposeBundle(request); err == nil {
+			t.Errorf("Expected bundle %+v to be rejected", request)
+		}
+	}
+
+	request, err := consensus.ParseBundleRequest(map[string]any{"bundle": map[string]any{
+		"proposer_id": "agent-pricing-1",
+		"rule":        "best_of",
+		"best_of":     1.0,
+		"electorate":  []any{"pricing"},
+		"proposals": []any{
+			map[string]any{"template": "price_change", "params": map[string]any{"product": "SKU-1"}},
+			map[string]any{"type": "action", "content": map[string]any{"region": "us"}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse bundle: %v", err)
+	}
+	if len(request.Proposals) != 2 || request.Proposals[0].Template != "price_change" || request.Proposals[1].Electorate[0] != "pricing" {
+		t.Errorf("Expected members to inherit the proposer and electorate, got %+v", request.Proposals)
+	}
+	if _, err := consensus.ParseBundleRequest(map[string]any{"bundle": map[string]any{
+		"proposer_id": "agent-pricing-1", "rule": "best_of", "best_of": 1.5, "proposals": []any{},
+	}}); err == nil {
+		t.Error("Expected a fractional best_of to be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestKnowledgeCache(t *testing.T) {
+	cfg := config.Default()
+	cfg.KnowledgeCacheSize = 2
+	cfg.VerifiedInsightTypes = []types.InsightType{types.InsightTypeFraudPattern}
+	viewer := &types.Agent{ID: "agent-support-1", Role: "support"}
+	cache := agent.NewKnowledgeCache(types.KnowledgeQuery{Topics: []string{"pricing", "payments"}}, viewer, cfg)
+
+	now := time.Now()
+	older := types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.8)
+	older.CreatedAt = now.Add(-time.Hour)
+	fraud := types.NewInsight("agent-fraud-1", "fraud", types.InsightTypeFraudPattern, "payments", "Card testing", 0.9)
+	private := types.NewInsight("agent-sales-2", "sales", types.InsightTypePricingIssue, "pricing", "Internal margin", 0.9)
+	private.Privacy = types.InsightPrivacyPrivate
+	delivery := types.NewInsight("agent-ops-1", "ops", types.InsightTypeCustomerFeedback, "delivery", "Late parcels", 0.7)
+
+	for _, insight := range []*types.Insight{older, fraud, private, delivery} {
+		cache.Put(insight, false)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("Expected only the visible pricing and payments insights to be cached, got %d", cache.Len())
+	}
+
+	if result := cache.Query(types.KnowledgeQuery{Actionable: true}); len(result) != 1 || result[0].ID != older.ID {
+		t.Errorf("Expected the unverified fraud pattern to be filtered out, got %v", result)
+	}
+
+	// Raw replays never overwrite the knowledge manager's verified version
+	verified := *fraud
+	verified.Verification = &types.InsightVerification{Status: types.InsightVerificationVerified}
+	cache.Put(&verified, true)
+	cache.Put(fraud, false)
+	if result := cache.Query(types.KnowledgeQuery{Actionable: true}); len(result) != 2 || result[0].ID != fraud.ID {
+		t.Errorf("Expected the verified fraud pattern first, got %v", result)
+	}
+
+	// Beyond the size limit the oldest insight is evicted
+	newer := types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Competitor undercut", 0.8)
+	cache.Put(newer, false)
+	if _, ok := cache.Get(older.ID); ok || cache.Len() != 2 {
+		t.Errorf("Expected the oldest insight to be evicted, got %d cached", cache.Len())
+	}
+}
+
+func TestAgentDebugState(t *testing.T) {
+	cfg := config.Default()
+	a := &types.Agent{ID: "agent-support-1", Role: "support"}
+	runtime := agent.NewAgentRuntime(a, nil, nil, nil, cfg, zap.NewNop())
+	runtime.RegisterHandler(types.MessageTypeTask, func(context.Context, *types.Message) error { return nil })
+	runtime.RegisterHandler(types.MessageTypeInsightPush, func(context.Context, *types.Message) error { return nil })
+	cache := runtime.EnableKnowledgeCache(types.KnowledgeQuery{Topics: []string{"pricing"}})
+	cache.Put(types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.8), false)
+
+	state := runtime.DebugState(10)
+	if len(state.Handlers) != 2 || state.Handlers[0] != types.MessageTypeInsightPush || state.Handlers[1] != types.MessageTypeTask {
+		t.Errorf("Expected sorted handler registrations, got %v", state.Handlers)
+	}
+	if state.CacheFilter == nil || state.CacheFilter.Topics[0] != "pricing" || state.CacheSize != 1 || len(state.Cache) != 1 {
+		t.Errorf("Expected the cache filter and contents, got %+v", state)
+	}
+	if len(state.Recent) != 0 || state.Stats.Processed != 0 {
+		t.Errorf("Expected no processed messages yet, got %+v", state.Recent)
+	}
+	if runtime.DebugState(0).Cache != nil {
+		t.Error("Expected no cached insights when none are asked for")
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+)
+
+func TestConfigCheck(t *testing.T) {
+	cfg := config.Default()
+	if problems := config.Problems(cfg); len(problems) != 0 {
+		t.Fatalf("Expected the default config to be valid, got %v", problems)
+	}
+	if warnings := config.Warnings(cfg); len(warnings) != 0 {
+		t.Fatalf("Expected no warnings for the default config, got %v", warnings)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatalf("Expected the default config to validate: %v", err)
+	}
+
+	cfg.PruneThreshold = cfg.InitialEdgeWeight
+	cfg.QuorumThreshold = 1.5
+	cfg.TopologyStartMode = "rewind"
+	problems := config.Problems(cfg)
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %d: %v", len(problems), problems)
+	}
+	for i, setting := range []string{"PRUNE_THRESHOLD", "QUORUM_THRESHOLD", "TOPOLOGY_START_MODE"} {
+		if !strings.HasPrefix(problems[i], setting) {
+			t.Errorf("Expected problem %d to name %s, got %q", i, setting, problems[i])
+		}
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	cfg := config.Default()
+	cfg.ReinforcementAmount = 1.5
+	if err := config.Validate(cfg); err == nil || !strings.Contains(err.Error(), "REINFORCEMENT_AMOUNT") {
+		t.Fatalf("Expected reinforcement above 1 to be invalid, got %v", err)
+	}
+
+	// Edges decaying from 0.5 to 0.1 at 0.2 per 5s are gone after 10s without messages
+	cfg = config.Default()
+	cfg.DecayRate = 0.2
+	cfg.ReinforcementAmount = 0.2
+	if err := config.Validate(cfg); err != nil {
+		t.Fatalf("Expected fast decay to be valid: %v", err)
+	}
+	warnings := config.Warnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "pruned after 10s") {
+		t.Errorf("Expected a fast decay warning, got %v", warnings)
+	}
+}
+
+func TestConfigFileProfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"agentmesh.yaml": `
+decay_rate: 0.03
+kafka_brokers: [a:9092, b:9092]
+profiles:
+  prod:
+    decay_rate: 0.01
+    quorum_threshold: 0.75
+`,
+		"agentmesh.toml": `
+decay_rate = 0.03 # Top level
+kafka_brokers = ["a:9092", "b:9092"]
+
+[profiles.prod]
+decay_rate = 0.01
+quorum_threshold = 0.75
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(config.FileEnv, path)
+			t.Setenv(config.ProfileEnv, "prod")
+			t.Setenv("QUORUM_THRESHOLD", "0.8")
+
+			effective, err := config.LoadEffective()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			cfg := effective.Config
+			if cfg.DecayRate != 0.01 || cfg.QuorumThreshold != 0.8 || strings.Join(cfg.KafkaBrokers, ",") != "a:9092,b:9092" {
+				t.Errorf("Unexpected config: decay %g, quorum %g, brokers %v", cfg.DecayRate, cfg.QuorumThreshold, cfg.KafkaBrokers)
+			}
+
+			sources := map[string]string{}
+			for _, setting := range effective.Settings {
+				sources[setting.Key] = setting.Source
+			}
+			want := map[string]string{
+				"DECAY_RATE":       "profile:prod",
+				"QUORUM_THRESHOLD": config.SourceEnv,
+				"KAFKA_BROKERS":    config.SourceFile,
+				"PROPOSAL_TIMEOUT": config.SourceDefault,
+			}
+			for key, source := range want {
+				if sources[key] != source {
+					t.Errorf("Expected %s from %s, got %q", key, source, sources[key])
+				}
+			}
+		})
+	}
+
+	// Typos in the file fail instead of being ignored
+	path := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(path, []byte("decay_rat: 0.03\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.FileEnv, path)
+	t.Setenv(config.ProfileEnv, "")
+	if _, err := config.LoadEffective(); err == nil || !strings.Contains(err.Error(), "DECAY_RAT") {
+		t.Errorf("Expected an unknown setting error, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/connectors"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+type recordingPublisher struct {
+	insights []*types.Insight
+}
+
+func (p *recordingPublisher) PublishInsight(ctx context.Context, insight *types.Insight) error {
+	p.insights = append(p.insights, insight)
+	return nil
+}
+
+func (p *recordingPublisher) PublishTopologyEvent(ctx context.Context, event types.TopologyEvent) error {
+	return nil
+}
+
+func (p *recordingPublisher) PublishHeartbeat(ctx context.Context, agentID types.AgentID) error {
+	return nil
+}
+
+func TestZendeskConnector(t *testing.T) {
+	updated := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ops@acme.test/token" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v2/incremental/tickets.json" || r.URL.Query().Get("start_time") == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"end_of_stream": true,
+			"tickets": []map[string]any{
+				{"id": 42, "type": "incident", "subject": "Checkout fails", "description": "Card payments time out", "priority": "urgent", "tags": []string{"payments", "checkout"}, "updated_at": updated},
+				{"id": 43, "subject": "Love the new plan", "tags": []string{}, "updated_at": updated.Add(-time.Minute)},
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.Default()
+	agent := &types.Agent{ID: "connector-1", Name: "zendesk-connector", Role: "connector"}
+	publisher := &recordingPublisher{}
+	connector := connectors.NewConnector(agent, connectors.NewZendeskSource(server.URL, "ops@acme.test/token:secret"), publisher, cfg, zap.NewNop())
+
+	published, err := connector.Pull(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("Expected 2 tickets published, got %d: %v", published, err)
+	}
+	// Oldest first, so the cursor only moves forward
+	feedback, incident := publisher.insights[0], publisher.insights[1]
+	if feedback.Type != types.InsightTypeCustomerFeedback || feedback.Topic != "customer_support" || feedback.Confidence != 0.6 {
+		t.Errorf("Unexpected feedback insight: %+v", feedback)
+	}
+	if incident.Type != types.InsightTypeProductIssue || incident.Topic != "payments" || incident.Confidence != 0.8 || incident.Content != "Checkout fails: Card payments time out" {
+		t.Errorf("Unexpected incident insight: %+v", incident)
+	}
+	if incident.AgentID != "connector-1" || !incident.CreatedAt.Equal(updated) {
+		t.Errorf("Expected the insight published by the connector at the ticket's update, got %s at %s", incident.AgentID, incident.CreatedAt)
+	}
+	want := map[string]string{
+		connectors.MetaSource:          "zendesk",
+		connectors.MetaSourceID:        "42",
+		connectors.MetaSourceURL:       server.URL + "/agent/tickets/42",
+		connectors.MetaSourceUpdatedAt: updated.Format(time.RFC3339),
+		connectors.MetaConnector:       "zendesk-connector",
+	}
+	for key, value := range want {
+		if incident.Metadata[key] != value {
+			t.Errorf("Expected provenance %s=%q, got %q", key, value, incident.Metadata[key])
+		}
+	}
+
+	// The source returns the newest ticket again; it was already published
+	if published, err := connector.Pull(context.Background()); err != nil || published != 0 {
+		t.Errorf("Expected nothing new on the next pull, got %d: %v", published, err)
+	}
+
+	unauthorized := connectors.NewConnector(agent, connectors.NewZendeskSource(server.URL, "wrong:token"), publisher, cfg, zap.NewNop())
+	if _, err := unauthorized.Pull(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the rejected request reported, got %v", err)
+	}
+}
+
+func TestJiraConnector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if jql := r.URL.Query().Get("jql"); !strings.HasPrefix(jql, "(project = OPS) AND updated >= \"-") {
+			t.Errorf("Unexpected JQL %q", jql)
+		}
+		w.Write([]byte(`{"total": 1, "issues": [{"key": "OPS-7", "fields": {
+			"summary": "Refund approvals take days", "updated": "` + time.Now().Add(-time.Minute).Format("2006-01-02T15:04:05.000-0700") + `",
+			"labels": ["refunds"], "issuetype": {"name": "Bug"}, "components": [{"name": "billing"}]}}]}`))
+	}))
+	defer server.Close()
+
+	publisher := &recordingPublisher{}
+	agent := &types.Agent{ID: "connector-2", Name: "jira-connector", Role: "connector"}
+	connector := connectors.NewConnector(agent, connectors.NewJiraSource(server.URL, "project = OPS", "pat"), publisher, config.Default(), zap.NewNop())
+	if published, err := connector.Pull(context.Background()); err != nil || published != 1 {
+		t.Fatalf("Expected 1 issue published, got %d: %v", published, err)
+	}
+	issue := publisher.insights[0]
+	if issue.Type != types.InsightTypeProductIssue || issue.Topic != "billing" || issue.Metadata[connectors.MetaSourceURL] != server.URL+"/browse/OPS-7" {
+		t.Errorf("Unexpected issue insight: %+v", issue)
+	}
+}
+
+func TestCRMExportConnector(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().AddDate(0, -1, 0).UTC().Format(time.RFC3339)
+	path := filepath.Join(t.TempDir(), "export.csv")
+	export := "id,updated_at,account,subject,notes,topic,confidence\n" +
+		"opp-1," + recent + ",Acme,Lost to competitor,Price was 20% higher,pricing,0.9\n" +
+		"opp-2," + old + ",Globex,Renewal,Renewed for two years,,\n"
+	if err := os.WriteFile(path, []byte(export), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := connectors.NewCRMExportSource(path).Fetch(context.Background(), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "opp-1" || records[0].Topic != "pricing" || records[0].Confidence != 0.9 || records[0].Data["account"] != "Acme" {
+		t.Fatalf("Expected only the recent row, got %+v", records)
+	}
+	if records, _ := connectors.NewCRMExportSource(path).Fetch(context.Background(), time.Time{}); len(records) != 2 || records[1].Topic != "sales" || records[1].Confidence != 0.7 {
+		t.Errorf("Expected defaults for rows without topic or confidence, got %+v", records)
+	}
+
+	if err := os.WriteFile(path, []byte("id,subject\nopp-1,Lost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := connectors.NewCRMExportSource(path).Fetch(context.Background(), time.Time{}); err == nil {
+		t.Error("Expected an export without updated_at rejected")
+	}
+}
@@ -0,0 +1,248 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func TestProposalAcceptedAtQuorum(t *testing.T) {
+	config := &types.Config{
+		QuorumThreshold: 0.6,
+		ProposalTimeout: time.Minute,
+	}
+	bc := consensus.NewBeeConsensus(config, zap.NewNop())
+	if err := bc.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start consensus: %v", err)
+	}
+	defer bc.Stop()
+
+	agents := make([]types.AgentID, 5)
+	for i := range agents {
+		agents[i] = types.NewAgentID()
+		bc.RegisterAgent(agents[i])
+	}
+
+	proposal, err := bc.CreateProposal(agents[0], types.ProposalTypeDecision, map[string]any{"task": "review"})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+
+	// 2/5 support is below the 60% threshold
+	for _, voter := range agents[:2] {
+		if err := bc.Vote(proposal.ID, voter, true, 0.8); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if proposal.Status != types.ProposalStatusPending {
+		t.Fatalf("Expected proposal to be pending at 40%% support, got %s", proposal.Status)
+	}
+
+	// Third supporting vote reaches quorum
+	if err := bc.Vote(proposal.ID, agents[2], true, 0.8); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	if proposal.Status != types.ProposalStatusAccepted {
+		t.Errorf("Expected proposal to be accepted at 60%% support, got %s", proposal.Status)
+	}
+
+	// Votes on a finalized proposal are rejected
+	if err := bc.Vote(proposal.ID, agents[3], true, 0.8); err == nil {
+		t.Error("Expected error when voting on an accepted proposal")
+	}
+}
+
+func TestOpposingVotesDoNotReachQuorum(t *testing.T) {
+	config := &types.Config{
+		QuorumThreshold: 0.6,
+		ProposalTimeout: time.Minute,
+	}
+	bc := consensus.NewBeeConsensus(config, zap.NewNop())
+
+	agents := make([]types.AgentID, 3)
+	for i := range agents {
+		agents[i] = types.NewAgentID()
+		bc.RegisterAgent(agents[i])
+	}
+
+	proposal, _ := bc.CreateProposal(agents[0], types.ProposalTypeDecision, map[string]any{"task": "deploy"})
+	for _, voter := range agents {
+		bc.Vote(proposal.ID, voter, false, 1.0)
+	}
+
+	if proposal.Status != types.ProposalStatusPending {
+		t.Errorf("Expected proposal to stay pending without support, got %s", proposal.Status)
+	}
+	if quorum := proposal.GetQuorum(bc.GetAgentCount()); quorum != 0 {
+		t.Errorf("Expected quorum 0, got %f", quorum)
+	}
+}
+
+func TestVoteRationale(t *testing.T) {
+	request, err := consensus.ParseVoteRequest(map[string]any{
+		"vote": map[string]any{
+			"proposal_id": "p",
+			"voter_id":    "agent-finance-1",
+			"support":     false,
+			"intensity":   0.6,
+			"rationale":   "Discount erodes margin below target",
+			"factors": []any{
+				map[string]any{"name": "margin_impact", "weight": -0.7, "value": 0.09},
+				map[string]any{"name": "customer_value", "weight": 0.2, "detail": "repeat customer"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse vote: %v", err)
+	}
+	if request.Rationale == "" || len(request.Factors) != 2 || request.Factors[0].Value != 0.09 {
+		t.Fatalf("Expected the rationale and both factors, got %+v", request)
+	}
+
+	invalid := []map[string]any{
+		{"rationale": 42},
+		{"factors": []any{map[string]any{"name": "risk", "weight": 3.0}}},
+		{"factors": []any{map[string]any{"name": "", "weight": 0.5}}},
+		{"factors": "risk"},
+	}
+	for _, extra := range invalid {
+		vote := map[string]any{"proposal_id": "p", "voter_id": "a", "support": true, "intensity": 0.5}
+		for k, v := range extra {
+			vote[k] = v
+		}
+		if _, err := consensus.ParseVoteRequest(map[string]any{"vote": vote}); err == nil {
+			t.Errorf("Expected vote with %v to be rejected", extra)
+		}
+	}
+
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	for _, id := range []types.AgentID{"agent-finance-1", "agent-sales-1", "agent-sales-2"} {
+		bc.RegisterAgent(id)
+	}
+	proposal, err := bc.CreateProposal("agent-sales-1", types.ProposalTypeDecision, map[string]any{"action": "discount"})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+	request.ProposalID = proposal.ID
+	if err := bc.CastVote(request); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	if err := bc.CastVote(&consensus.VoteRequest{
+		ProposalID: proposal.ID,
+		VoterID:    "agent-sales-2",
+		Support:    true,
+		Intensity:  0.9,
+		Factors:    []types.VoteFactor{{Name: "customer_value", Weight: 0.8}},
+	}); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+
+	votes := proposal.VoteList()
+	if len(votes) != 2 || votes[0].Rationale != request.Rationale {
+		t.Fatalf("Expected the rationale to be kept with the vote, got %+v", votes)
+	}
+	factors := types.SummarizeFactors(votes)
+	if len(factors) != 2 || factors[0].Name != "customer_value" || factors[0].Mentions != 2 ||
+		factors[0].Supporting != 1 || factors[0].Opposing != 1 || factors[0].AvgWeight != 0.5 {
+		t.Errorf("Unexpected factor summary %+v", factors)
+	}
+}
+
+func TestAbstainAndConditionalVotes(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	agents := []types.AgentID{"agent-1", "agent-2", "agent-3", "agent-4", "agent-5"}
+	for _, id := range agents {
+		bc.RegisterAgent(id)
+	}
+	proposal, err := bc.CreateProposal(agents[0], types.ProposalTypeDecision, map[string]any{
+		"action": "approve_order",
+		"order":  map[string]any{"amount": 8000.0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+
+	cheap, err := consensus.ParseVoteRequest(map[string]any{"vote": map[string]any{
+		"proposal_id": string(proposal.ID),
+		"voter_id":    "agent-1",
+		"choice":      "conditional",
+		"intensity":   0.8,
+		"condition":   map[string]any{"field": "order.amount", "operator": "<", "value": 10000.0},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse conditional vote: %v", err)
+	}
+	abstain, err := consensus.ParseVoteRequest(map[string]any{"vote": map[string]any{
+		"proposal_id": string(proposal.ID),
+		"voter_id":    "agent-2",
+		"choice":      "abstain",
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse abstention: %v", err)
+	}
+	for _, vote := range []*consensus.VoteRequest{cheap, abstain, {
+		ProposalID: proposal.ID,
+		VoterID:    "agent-3",
+		Intensity:  0.5,
+		Choice:     types.VoteChoiceConditional,
+		Condition:  &types.VoteCondition{Field: "order.amount", Operator: "<", Value: 5000.0},
+	}} {
+		if err := bc.CastVote(vote); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+
+	// One condition holds; the abstention and the failed condition do not count
+	tally := proposal.Tally()
+	if tally.Conditional != 2 || tally.ConditionsMet != 1 || tally.Abstain != 1 || tally.Total != 3 {
+		t.Fatalf("Unexpected tally %+v", tally)
+	}
+	if p := tally.Participation(len(agents)); p != 0.6 {
+		t.Errorf("Expected abstentions to count towards participation, got %v", p)
+	}
+	if q := proposal.GetQuorum(len(agents)); q != 0.2 {
+		t.Errorf("Expected 1/5 in favor, got %v", q)
+	}
+	status := consensus.NewQuorumSensor(0.6).GetQuorumStatus(proposal, len(agents))
+	if status.SupportCount != 1 || status.RejectCount != 1 || status.AbstainCount != 1 {
+		t.Errorf("Unexpected quorum status %+v", status)
+	}
+
+	for _, id := range agents[3:] {
+		if err := bc.Vote(proposal.ID, id, true, 0.9); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if proposal.Status != types.ProposalStatusAccepted {
+		t.Fatalf("Expected acceptance at 3/5 in favor, got %s", proposal.Status)
+	}
+	for _, vote := range proposal.VoteList() {
+		if vote.Choice == types.VoteChoiceConditional && vote.ConditionMet == nil {
+			t.Errorf("Expected %s's condition to be resolved at finalization", vote.VoterID)
+		}
+		if vote.VoterID == "agent-3" && *vote.ConditionMet {
+			t.Error("Expected agent-3's condition to be recorded as unmet")
+		}
+	}
+
+	invalid := []map[string]any{
+		{"choice": "maybe", "intensity": 0.5},
+		{"choice": "conditional", "intensity": 0.5},
+		{"choice": "support", "intensity": 0.5, "condition": map[string]any{"field": "amount", "operator": "<", "value": 1.0}},
+		{"choice": "conditional", "intensity": 0.5, "condition": map[string]any{"field": "amount", "operator": "<", "value": "lots"}},
+		{"choice": "conditional", "intensity": 0.5, "condition": map[string]any{"field": "amount", "operator": "~", "value": 1.0}},
+	}
+	for _, extra := range invalid {
+		vote := map[string]any{"proposal_id": "p", "voter_id": "a"}
+		for k, v := range extra {
+			vote[k] = v
+		}
+		if _, err := consensus.ParseVoteRequest(map[string]any{"vote": vote}); err == nil {
+			t.Errorf("Expected vote with %v to be rejected", extra)
+		}
+	}
+}
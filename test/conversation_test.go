@@ -0,0 +1,107 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestConversationQueuesKeepConversationOrder(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		handled  = map[string][]int{}
+		running  = map[string]bool{}
+		inFlight atomic.Int32
+		peak     atomic.Int32
+	)
+	queues := agent.NewConversationQueues(4, func(msg *types.Message) {
+		mu.Lock()
+		if running[msg.ConversationID] {
+			t.Errorf("Conversation %s handled concurrently", msg.ConversationID)
+		}
+		running[msg.ConversationID] = true
+		mu.Unlock()
+
+		current := inFlight.Add(1)
+		for {
+			if p := peak.Load(); current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+
+		mu.Lock()
+		running[msg.ConversationID] = false
+		handled[msg.ConversationID] = append(handled[msg.ConversationID], msg.Payload["seq"].(int))
+		mu.Unlock()
+	})
+
+	for seq := 0; seq < 20; seq++ {
+		for c := 0; c < 4; c++ {
+			queues.Submit(&types.Message{
+				ID:             fmt.Sprintf("msg-%d-%d", c, seq),
+				FromAgentID:    "agent-sender",
+				ConversationID: fmt.Sprintf("conv-%d", c),
+				Payload:        map[string]any{"seq": seq},
+			})
+		}
+	}
+	queues.Wait()
+
+	for c := 0; c < 4; c++ {
+		got := handled[fmt.Sprintf("conv-%d", c)]
+		if len(got) != 20 {
+			t.Fatalf("Expected 20 messages of conv-%d handled, got %d", c, len(got))
+		}
+		for i, seq := range got {
+			if seq != i {
+				t.Fatalf("Expected conv-%d handled in order, got %v", c, got)
+			}
+		}
+	}
+	if peak.Load() < 2 {
+		t.Errorf("Expected different conversations handled concurrently, at most %d ran at once", peak.Load())
+	}
+}
+
+func TestConversationQueuesOrderSendersWithoutConversation(t *testing.T) {
+	var handled []string
+	queues := agent.NewConversationQueues(1, func(msg *types.Message) {
+		handled = append(handled, msg.ID)
+	})
+	for i := 0; i < 5; i++ {
+		queues.Submit(&types.Message{ID: fmt.Sprintf("msg-%d", i), FromAgentID: "agent-sender"})
+	}
+	queues.Wait()
+
+	if fmt.Sprint(handled) != "[msg-0 msg-1 msg-2 msg-3 msg-4]" {
+		t.Errorf("Expected a sender's messages handled in order, got %v", handled)
+	}
+}
+
+func TestConversationStoreKeepsRecentConversations(t *testing.T) {
+	store := agent.NewConversationStore(2)
+	for c := 0; c < 3; c++ {
+		for seq := 0; seq < 3; seq++ {
+			store.Record(&types.Message{ID: fmt.Sprintf("msg-%d-%d", c, seq), ConversationID: fmt.Sprintf("conv-%d", c)})
+		}
+	}
+	store.Record(&types.Message{ID: "outside"})
+
+	if got := store.Conversations(); fmt.Sprint(got) != "[conv-2 conv-1]" {
+		t.Errorf("Expected the two most recently active conversations kept, got %v", got)
+	}
+	if history := store.History("conv-0"); history != nil {
+		t.Errorf("Expected the least recently active conversation dropped, got %d messages", len(history))
+	}
+	history := store.History("conv-1")
+	if len(history) != 3 || history[0].ID != "msg-1-0" || history[2].ID != "msg-1-2" {
+		t.Errorf("Expected conv-1's messages oldest first, got %v", history)
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/digest"
+	"github.com/avinashshinde/agentmesh-cortex/internal/webhook"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestDigestCompile(t *testing.T) {
+	cfg := config.Default()
+	cfg.DigestTopInsights = 1
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+
+	inPeriod := rankedInsight("recent", "agent-a", "pricing", end.Add(-time.Hour))
+	corroborating := rankedInsight("echo", "agent-b", "pricing", end.Add(-2*time.Hour))
+	tooOld := rankedInsight("old", "agent-a", "pricing", start.Add(-time.Hour))
+	progress := types.NewGoalProgressInsight("agent-a", "support", "tickets", 30)
+
+	accepted := &types.Proposal{ID: "p-1", Type: types.ProposalTypeAction, Status: types.ProposalStatusAccepted, CreatedAt: end.Add(-time.Hour)}
+	pending := &types.Proposal{ID: "p-2", Status: types.ProposalStatusPending, CreatedAt: end.Add(-time.Hour)}
+
+	first := &types.GraphSnapshot{
+		Agents: map[types.AgentID]*types.Agent{"agent-a": {}, "agent-b": {}},
+		Edges:  map[types.EdgeID]*types.Edge{types.NewEdgeID("agent-a", "agent-b"): {}},
+	}
+	last := &types.GraphSnapshot{
+		Agents: map[types.AgentID]*types.Agent{"agent-a": {}, "agent-c": {}},
+		Edges:  map[types.EdgeID]*types.Edge{types.NewEdgeID("agent-a", "agent-c"): {}, types.NewEdgeID("agent-c", "agent-a"): {}},
+	}
+
+	d := digest.Compile(cfg, start, end, digest.Inputs{
+		Insights:  []*types.Insight{inPeriod, corroborating, tooOld, progress},
+		Patterns:  []types.Pattern{{ID: "new", DetectedAt: end.Add(-time.Minute)}, {ID: "stale", DetectedAt: start.Add(-time.Minute)}},
+		Proposals: []*types.Proposal{accepted, pending},
+		Snapshots: []*types.GraphSnapshot{first, last},
+	})
+
+	if d.InsightCount != 2 || len(d.TopInsights) != 1 || d.TopInsights[0].Rank == nil {
+		t.Errorf("expected 2 insights in period with the top 1 ranked, got %d/%d", d.InsightCount, len(d.TopInsights))
+	}
+	if len(d.NewPatterns) != 1 || d.NewPatterns[0].ID != "new" {
+		t.Errorf("expected only the new pattern, got %+v", d.NewPatterns)
+	}
+	if len(d.AcceptedProposals) != 1 || d.AcceptedProposals[0].ID != "p-1" {
+		t.Errorf("expected only the accepted proposal, got %d", len(d.AcceptedProposals))
+	}
+	topo := d.Topology
+	if len(topo.AgentsJoined) != 1 || topo.AgentsJoined[0] != "agent-c" || len(topo.AgentsLeft) != 1 ||
+		topo.EdgesFormed != 2 || topo.EdgesPruned != 1 {
+		t.Errorf("unexpected topology changes %+v", topo)
+	}
+
+	md := digest.Markdown(d)
+	for _, section := range []string{"## Top Insights", "## New Patterns", "## Accepted Proposals", "## Topology", "`p-1`"} {
+		if !strings.Contains(md, section) {
+			t.Errorf("markdown missing %q", section)
+		}
+	}
+}
+
+type staticWebhooks []*types.Webhook
+
+func (s staticWebhooks) ListWebhooks(ctx context.Context) ([]*types.Webhook, error) {
+	return s, nil
+}
+
+func TestWebhookDeliverySignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var signatureOK atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signatureOK.Store(r.Header.Get("X-AgentMesh-Signature") == webhook.Sign("s3cret", body))
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hooks := staticWebhooks{
+		{ID: "digests", URL: server.URL, Events: []string{types.WebhookEventDigest}, Secret: "s3cret"},
+		{ID: "other", URL: server.URL, Events: []string{"proposal.escalated"}},
+	}
+	dispatcher := webhook.NewDispatcher(hooks, zap.NewNop())
+	dispatcher.SetBackoff(time.Millisecond)
+
+	count, err := dispatcher.Publish(context.Background(), types.WebhookEventDigest, map[string]any{"id": "d-1"})
+	if err != nil || count != 1 {
+		t.Fatalf("expected delivery to 1 webhook, got %d (%v)", count, err)
+	}
+	dispatcher.Wait()
+
+	if attempts.Load() != 2 {
+		t.Errorf("expected a retry after the failure, got %d attempts", attempts.Load())
+	}
+	if !signatureOK.Load() {
+		t.Error("delivery signature did not match")
+	}
+}
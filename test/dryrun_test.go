@@ -0,0 +1,50 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestAgentDryRun(t *testing.T) {
+	t.Setenv("AGENT_DRY_RUN", "true")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !cfg.AgentDryRun {
+		t.Fatal("Expected AGENT_DRY_RUN to enable dry run")
+	}
+	cfg.KafkaBrokers = []string{"127.0.0.1:1"} // Nothing listens: any write would fail
+
+	km := messaging.NewKafkaMessaging(cfg, zap.NewNop())
+	defer km.Close()
+	runtime := agent.NewAgentRuntime(&types.Agent{ID: "agent-inventory-2", Role: "inventory"}, nil, nil, km, cfg, zap.NewNop())
+	if !km.DryRun() || !runtime.DebugState(0).DryRun {
+		t.Fatal("Expected the runtime to put its messaging in dry run")
+	}
+
+	// Would-be records are logged, never written to the broker
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	insight := types.NewInsight("agent-inventory-2", "inventory", types.InsightTypeInventoryTrend, "stock", "SKU-42 running low", 0.9)
+	if err := km.PublishInsight(ctx, insight); err != nil {
+		t.Errorf("Expected the insight to be logged only, got %v", err)
+	}
+	if err := km.PublishProposal(ctx, &types.Proposal{ProposerID: "agent-inventory-2", Type: types.ProposalTypeDecision}, messaging.ProposalOptions{}); err != nil {
+		t.Errorf("Expected the proposal to be logged only, got %v", err)
+	}
+	if err := km.PublishTopologyEvent(ctx, types.TopologyEvent{Type: types.TopologyEventEdgeStrength, Timestamp: time.Now()}); err != nil {
+		t.Errorf("Expected the topology event to be logged only, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("Expected dry-run publishing not to wait on the broker")
+	}
+}
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/embeddings"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// countingProvider embeds a text as its length, recording the batches it gets
+type countingProvider struct {
+	model   string
+	batches [][]string
+}
+
+func (p *countingProvider) Model() string { return p.model }
+
+func (p *countingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	p.batches = append(p.batches, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+// memoryEmbeddingCache keeps vectors by model and hash
+type memoryEmbeddingCache map[string][]float32
+
+func (c memoryEmbeddingCache) GetEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error) {
+	found := make(map[string][]float32)
+	for _, hash := range hashes {
+		if vector, ok := c[model+":"+hash]; ok {
+			found[hash] = vector
+		}
+	}
+	return found, nil
+}
+
+func (c memoryEmbeddingCache) SaveEmbeddings(ctx context.Context, model string, vectors map[string][]float32) error {
+	for hash, vector := range vectors {
+		c[model+":"+hash] = vector
+	}
+	return nil
+}
+
+func TestEmbedderCachesAndBatches(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProvider{model: "test/small"}
+	cache := memoryEmbeddingCache{}
+	embedder := embeddings.NewEmbedder(provider, cache, 2)
+
+	vectors, err := embedder.Embed(ctx, []string{"a", "bb", "a", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 4 || vectors[0][0] != 1 || vectors[1][0] != 2 || vectors[2][0] != 1 || vectors[3][0] != 3 {
+		t.Fatalf("Expected vectors in text order, got %v", vectors)
+	}
+	// The repeated "a" is embedded once, in batches of at most 2
+	if len(provider.batches) != 2 || len(provider.batches[0]) != 2 || len(provider.batches[1]) != 1 {
+		t.Fatalf("Expected batches of 2 and 1 texts, got %v", provider.batches)
+	}
+
+	if _, err := embedder.Embed(ctx, []string{"bb", "dddd"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(provider.batches) != 3 || len(provider.batches[2]) != 1 || provider.batches[2][0] != "dddd" {
+		t.Fatalf("Expected only the uncached text to be embedded, got %v", provider.batches)
+	}
+	stats := embedder.Stats()
+	if stats.CacheHits != 1 || stats.Embedded != 4 || stats.Requests != 3 {
+		t.Errorf("Expected 1 cache hit, 4 embedded and 3 requests, got %+v", stats)
+	}
+
+	// Another model misses the cache
+	other := &countingProvider{model: "test/large"}
+	if _, err := embeddings.NewEmbedder(other, cache, 10).Embed(ctx, []string{"a"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(other.batches) != 1 {
+		t.Errorf("Expected a new model to embed cached texts again, got %v", other.batches)
+	}
+}
+
+func TestEmbedderReembed(t *testing.T) {
+	ctx := context.Background()
+	var insights []*types.Insight
+	for _, topic := range []string{"pricing", "refunds", "fraud", "churn", "pricing"} {
+		insights = append(insights, types.NewInsight("agent-1", "sales", types.InsightTypeCustomerFeedback, topic, "Customers asked for annual billing", 0.8))
+	}
+
+	provider := &countingProvider{model: "test/large"}
+	cache := memoryEmbeddingCache{}
+	embedder := embeddings.NewEmbedder(provider, cache, 2)
+
+	var progress []int
+	if err := embedder.Reembed(ctx, insights, func(done int) { progress = append(progress, done) }); err != nil {
+		t.Fatalf("Reembed failed: %v", err)
+	}
+	if len(progress) != 3 || progress[0] != 2 || progress[1] != 4 || progress[2] != 5 {
+		t.Errorf("Expected progress 2, 4, 5, got %v", progress)
+	}
+	if len(cache) != 4 {
+		t.Errorf("Expected 4 distinct texts cached, got %d", len(cache))
+	}
+
+	// Resuming re-embeds nothing already cached
+	requests := len(provider.batches)
+	if err := embedder.Reembed(ctx, insights, nil); err != nil {
+		t.Fatalf("Reembed failed: %v", err)
+	}
+	if len(provider.batches) != requests {
+		t.Errorf("Expected a resumed migration to use the cache, got %d more requests", len(provider.batches)-requests)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "text-embedding-3-small" {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		// Answer out of order; the provider sorts by index
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := []item{}
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float32{float32(len(req.Input[i]))}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	provider := embeddings.NewOpenAIProvider(server.URL+"/", "text-embedding-3-small", "secret")
+	vectors, err := provider.Embed(context.Background(), []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][0] != 3 {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+
+	if _, err := embeddings.NewOpenAIProvider(server.URL, "other-model", "secret").Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected an error status to fail the batch")
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func TestEscalationPolicies(t *testing.T) {
+	policies, err := types.ParseEscalationPolicies(`{"decision": [
+		{"action": "repropose", "electorate": ["analyst"], "timeout": "2m"},
+		{"action": "notify"},
+		{"action": "default", "decision": "rejected"}
+	]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse policies: %v", err)
+	}
+
+	proposal := &types.Proposal{ID: "p1", Type: types.ProposalTypeDecision}
+	start, steps := consensus.NextEscalation(policies, proposal)
+	if start != 0 || len(steps) != 1 || steps[0].Action != types.EscalationRepropose {
+		t.Fatalf("Expected the first round to re-propose, got %d %+v", start, steps)
+	}
+	if timeout := steps[0].TimeoutDuration(time.Minute); timeout != 2*time.Minute {
+		t.Errorf("Expected a 2m voting window, got %v", timeout)
+	}
+
+	proposal.RecordEscalation(types.EscalationRecord{Step: 0, Action: types.EscalationRepropose})
+	start, steps = consensus.NextEscalation(policies, proposal)
+	if start != 1 || len(steps) != 2 || steps[0].Action != types.EscalationNotify || steps[1].Action != types.EscalationDefault {
+		t.Fatalf("Expected notify to run with the default decision, got %d %+v", start, steps)
+	}
+
+	proposal.RecordEscalation(types.EscalationRecord{Step: 2, Action: types.EscalationDefault})
+	if _, steps = consensus.NextEscalation(policies, proposal); len(steps) != 0 {
+		t.Errorf("Expected an exhausted chain, got %+v", steps)
+	}
+	if _, steps = consensus.NextEscalation(policies, &types.Proposal{Type: types.ProposalTypeAction}); len(steps) != 0 {
+		t.Errorf("Expected no escalation without a policy, got %+v", steps)
+	}
+
+	invalid := []string{
+		`{"decision": [{"action": "shout"}]}`,
+		`{"decision": [{"action": "default", "decision": "pending"}]}`,
+		`{"decision": [{"action": "repropose", "timeout": "soon"}]}`,
+	}
+	for _, data := range invalid {
+		if _, err := types.ParseEscalationPolicies(data); err == nil {
+			t.Errorf("Expected policies %s to be rejected", data)
+		}
+	}
+
+	if widened := types.WidenElectorate([]string{"sales"}, []string{"analyst", "sales"}); len(widened) != 2 {
+		t.Errorf("Expected sales and analyst, got %v", widened)
+	}
+	if widened := types.WidenElectorate([]string{"sales"}, nil); widened != nil {
+		t.Errorf("Expected an empty step electorate to open the vote to everyone, got %v", widened)
+	}
+}
+
+func TestEscalationRepropose(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	bc.RegisterAgentRole("agent-sales-1", "sales")
+	bc.RegisterAgentRole("agent-sales-2", "sales")
+	bc.RegisterAgentRole("agent-analyst-1", "analyst")
+	bc.RegisterAgentRole("agent-analyst-2", "analyst")
+
+	proposal, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeDecision,
+		Content:    map[string]any{"action": "approve_order", "priority": "high"},
+		Electorate: []string{"sales"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	if err := bc.Vote(proposal.ID, "agent-analyst-1", true, 1.0); err == nil {
+		t.Error("Expected a vote from outside the electorate to be rejected")
+	}
+
+	if _, err := bc.Repropose(proposal, nil, time.Minute); err == nil {
+		t.Error("Expected a pending proposal not to be re-proposed")
+	}
+	proposal.Status = types.ProposalStatusExpired
+	proposal.RecordEscalation(types.EscalationRecord{Step: 0, Action: types.EscalationNotify, ProposalID: proposal.ID})
+
+	next, err := bc.Repropose(proposal, types.WidenElectorate(proposal.Electorate, []string{"analyst"}), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to re-propose: %v", err)
+	}
+	if next.EscalatedFrom != proposal.ID || next.Status != types.ProposalStatusPending {
+		t.Errorf("Expected a pending proposal escalated from %s, got %s from %s", proposal.ID, next.Status, next.EscalatedFrom)
+	}
+	if len(next.EscalationHistory()) != 1 {
+		t.Errorf("Expected the escalation history to be carried forward, got %+v", next.EscalationHistory())
+	}
+
+	// Three of the four sales and analyst agents reach the 0.6 quorum
+	for _, voter := range []types.AgentID{"agent-analyst-1", "agent-analyst-2"} {
+		if err := bc.Vote(next.ID, voter, true, 1.0); err != nil {
+			t.Fatalf("Expected %s to vote on the widened proposal: %v", voter, err)
+		}
+	}
+	if next.Status != types.ProposalStatusPending {
+		t.Fatalf("Expected no quorum with half the electorate, got %s", next.Status)
+	}
+	if err := bc.Vote(next.ID, "agent-sales-2", true, 1.0); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	if next.Status != types.ProposalStatusAccepted {
+		t.Errorf("Expected the widened proposal to be accepted, got %s", next.Status)
+	}
+}
+
+func TestEscalationDefaultDecision(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	proposal, err := bc.CreateProposal("agent-sales-1", types.ProposalTypeDecision, map[string]any{"priority": "low"})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+
+	proposal.Status = types.ProposalStatusExpired
+	if err := bc.Resolve(proposal, types.ProposalStatusPending); err == nil {
+		t.Error("Expected a pending default decision to be rejected")
+	}
+	if err := bc.Resolve(proposal, types.ProposalStatusRejected); err != nil {
+		t.Fatalf("Failed to apply the default decision: %v", err)
+	}
+	if proposal.Status != types.ProposalStatusRejected {
+		t.Errorf("Expected rejected, got %s", proposal.Status)
+	}
+	if err := bc.Resolve(proposal, types.ProposalStatusAccepted); err == nil {
+		t.Error("Expected a decided proposal not to be resolved again")
+	}
+}
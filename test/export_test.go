@@ -0,0 +1,277 @@
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// exportAgentID needs escaping in every format
+const exportAgentID types.AgentID = `agent "<1>" & co`
+
+// exportSnapshot builds a snapshot of three agents, two of them in communities
+func exportSnapshot() *types.GraphSnapshot {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	agents := map[types.AgentID]*types.Agent{
+		exportAgentID: {ID: exportAgentID, Name: `Agent <One> & "Co"`, Role: "research", Status: types.AgentStatusActive,
+			Metadata: map[string]string{"note": `says "hi" <b> & bye`}},
+		"agent-2": {ID: "agent-2", Name: "Agent Two", Role: "analyst", Status: types.AgentStatusActive},
+		"agent-3": {ID: "agent-3", Name: "Agent Three", Role: "writer", Status: types.AgentStatusIdle},
+	}
+	edges := map[types.EdgeID]*types.Edge{}
+	for _, e := range []struct {
+		from, to types.AgentID
+		weight   float64
+		usage    int64
+	}{
+		{exportAgentID, "agent-2", 0.875, 12},
+		{"agent-2", "agent-3", 0.25, 3},
+	} {
+		id := types.NewEdgeID(e.from, e.to)
+		edges[id] = &types.Edge{ID: id, SourceID: e.from, TargetID: e.to, Weight: e.weight, Usage: e.usage, LastUsed: now}
+	}
+	return &types.GraphSnapshot{
+		Agents:      agents,
+		Edges:       edges,
+		Communities: map[types.AgentID]int{exportAgentID: 0, "agent-2": 1},
+		Timestamp:   now,
+	}
+}
+
+// exportData is a GraphML data element or GEXF attvalue
+type exportData struct {
+	Key   string `xml:"key,attr"`
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+// exportValue returns the value of a data element by key, and whether it was present
+func exportValue(data []exportData, key string) (string, bool) {
+	for _, d := range data {
+		if d.Key == key {
+			return d.Text, true
+		}
+		if d.For == key {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+// TestExportGraphML parses the GraphML export back
+func TestExportGraphML(t *testing.T) {
+	snapshot := exportSnapshot()
+	var buf bytes.Buffer
+	if err := topology.ExportGraphML(&buf, snapshot); err != nil {
+		t.Fatalf("ExportGraphML failed: %v", err)
+	}
+
+	var doc struct {
+		Keys []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"key"`
+		Graph struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []struct {
+				ID   string       `xml:"id,attr"`
+				Data []exportData `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string       `xml:"source,attr"`
+				Target string       `xml:"target,attr"`
+				Data   []exportData `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GraphML export is not valid XML: %v\n%s", err, buf.String())
+	}
+
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("Expected 3 nodes and 2 edges, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if doc.Graph.EdgeDefault != "directed" {
+		t.Errorf("Expected directed edges, got %s", doc.Graph.EdgeDefault)
+	}
+
+	keys := map[string]bool{}
+	for _, key := range doc.Keys {
+		keys[key.ID] = true
+	}
+	if !keys["community"] || !keys["meta_note"] {
+		t.Errorf("Expected community and meta_note keys declared, got %v", keys)
+	}
+
+	communities := map[string]string{}
+	for _, node := range doc.Graph.Nodes {
+		if community, ok := exportValue(node.Data, "community"); ok {
+			communities[node.ID] = community
+		}
+		if node.ID != string(exportAgentID) {
+			continue
+		}
+		if name, _ := exportValue(node.Data, "name"); name != snapshot.Agents[exportAgentID].Name {
+			t.Errorf("Expected name %q, got %q", snapshot.Agents[exportAgentID].Name, name)
+		}
+		if note, _ := exportValue(node.Data, "meta_note"); note != `says "hi" <b> & bye` {
+			t.Errorf("Expected metadata to round-trip, got %q", note)
+		}
+	}
+	if len(communities) != 2 || communities[string(exportAgentID)] != "0" || communities["agent-2"] != "1" {
+		t.Errorf("Expected communities 0 and 1 on the first two agents only, got %v", communities)
+	}
+
+	weights := map[string]string{}
+	for _, edge := range doc.Graph.Edges {
+		weight, _ := exportValue(edge.Data, "weight")
+		weights[edge.Source+"->"+edge.Target] = weight
+	}
+	if weights[string(exportAgentID)+"->agent-2"] != "0.875" || weights["agent-2->agent-3"] != "0.25" {
+		t.Errorf("Unexpected edge weights %v", weights)
+	}
+}
+
+// TestExportGEXF parses the GEXF export back
+func TestExportGEXF(t *testing.T) {
+	snapshot := exportSnapshot()
+	snapshot.Stats.Undirected = true
+	var buf bytes.Buffer
+	if err := topology.ExportGEXF(&buf, snapshot); err != nil {
+		t.Fatalf("ExportGEXF failed: %v", err)
+	}
+
+	var doc struct {
+		Graph struct {
+			DefaultEdgeType string `xml:"defaultedgetype,attr"`
+			Attributes      []struct {
+				Class     string `xml:"class,attr"`
+				Attribute []struct {
+					ID   string `xml:"id,attr"`
+					Type string `xml:"type,attr"`
+				} `xml:"attribute"`
+			} `xml:"attributes"`
+			Nodes []struct {
+				ID        string       `xml:"id,attr"`
+				Label     string       `xml:"label,attr"`
+				AttValues []exportData `xml:"attvalues>attvalue"`
+			} `xml:"nodes>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Weight string `xml:"weight,attr"`
+			} `xml:"edges>edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GEXF export is not valid XML: %v\n%s", err, buf.String())
+	}
+
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("Expected 3 nodes and 2 edges, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if doc.Graph.DefaultEdgeType != "undirected" {
+		t.Errorf("Expected undirected edges, got %s", doc.Graph.DefaultEdgeType)
+	}
+
+	declared := false
+	for _, attributes := range doc.Graph.Attributes {
+		for _, attribute := range attributes.Attribute {
+			if attributes.Class == "node" && attribute.ID == "community" && attribute.Type == "integer" {
+				declared = true
+			}
+		}
+	}
+	if !declared {
+		t.Error("Expected an integer community node attribute declared")
+	}
+
+	communities := map[string]string{}
+	for _, node := range doc.Graph.Nodes {
+		if community, ok := exportValue(node.AttValues, "community"); ok {
+			communities[node.ID] = community
+		}
+		if node.ID != string(exportAgentID) {
+			continue
+		}
+		if node.Label != snapshot.Agents[exportAgentID].Name {
+			t.Errorf("Expected label %q, got %q", snapshot.Agents[exportAgentID].Name, node.Label)
+		}
+		if note, _ := exportValue(node.AttValues, "meta_note"); note != `says "hi" <b> & bye` {
+			t.Errorf("Expected metadata to round-trip, got %q", note)
+		}
+	}
+	if len(communities) != 2 || communities[string(exportAgentID)] != "0" || communities["agent-2"] != "1" {
+		t.Errorf("Expected communities 0 and 1 on the first two agents only, got %v", communities)
+	}
+
+	weights := map[string]string{}
+	for _, edge := range doc.Graph.Edges {
+		weights[edge.Source+"->"+edge.Target] = edge.Weight
+	}
+	if weights[string(exportAgentID)+"->agent-2"] != "0.875" || weights["agent-2->agent-3"] != "0.25" {
+		t.Errorf("Unexpected edge weights %v", weights)
+	}
+}
+
+// TestExportDOT checks the DOT export's statements and quoting
+func TestExportDOT(t *testing.T) {
+	snapshot := exportSnapshot()
+	var buf bytes.Buffer
+	if err := topology.ExportDOT(&buf, snapshot); err != nil {
+		t.Fatalf("ExportDOT failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "digraph agentmesh {\n") || !strings.HasSuffix(out, "}\n") {
+		t.Fatalf("Expected a digraph, got:\n%s", out)
+	}
+
+	var nodes, edges []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.Contains(line, " -> "):
+			edges = append(edges, line)
+		case strings.HasPrefix(line, `"`):
+			nodes = append(nodes, line)
+		}
+	}
+	if len(nodes) != 3 || len(edges) != 2 {
+		t.Fatalf("Expected 3 node and 2 edge statements, got %d and %d:\n%s", len(nodes), len(edges), out)
+	}
+
+	// Quotes are escaped, while < and & need no escaping in a quoted ID
+	quoted := `"agent \"<1>\" & co"`
+	if !strings.HasPrefix(nodes[0], quoted+" [") {
+		t.Errorf("Expected the first node quoted as %s, got %s", quoted, nodes[0])
+	}
+	if !strings.Contains(nodes[0], `"meta_note"="says \"hi\" <b> & bye"`) {
+		t.Errorf("Expected escaped metadata, got %s", nodes[0])
+	}
+	if !strings.Contains(nodes[0], "community=0") || !strings.Contains(nodes[1], "community=1") || strings.Contains(nodes[2], "community=") {
+		t.Errorf("Expected communities 0 and 1 on the first two agents only:\n%s", strings.Join(nodes, "\n"))
+	}
+	if !strings.HasPrefix(edges[0], quoted+` -> "agent-2" [weight=0.875, usage=12,`) {
+		t.Errorf("Unexpected first edge %s", edges[0])
+	}
+	if !strings.HasPrefix(edges[1], `"agent-2" -> "agent-3" [weight=0.25, usage=3,`) {
+		t.Errorf("Unexpected second edge %s", edges[1])
+	}
+
+	snapshot.Stats.Undirected = true
+	buf.Reset()
+	topology.ExportDOT(&buf, snapshot)
+	if !strings.HasPrefix(buf.String(), "graph agentmesh {\n") || strings.Count(buf.String(), " -- ") != 2 {
+		t.Errorf("Expected an undirected graph with 2 edges, got:\n%s", buf.String())
+	}
+
+	if err := topology.Export(&buf, snapshot, "svg"); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
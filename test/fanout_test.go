@@ -0,0 +1,25 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+)
+
+// TestFanoutWithoutRedis checks frames are delivered locally when there is no store
+func TestFanoutWithoutRedis(t *testing.T) {
+	var delivered []any
+	fanout := state.NewFanout(nil, "web:broadcast", func(frame any) { delivered = append(delivered, frame) }, zap.NewNop())
+
+	// Relay has nothing to subscribe to and returns at once
+	fanout.Relay(context.Background())
+
+	frame := map[string]any{"type": "agent_joined"}
+	fanout.Publish(context.Background(), frame)
+	if len(delivered) != 1 || delivered[0].(map[string]any)["type"] != "agent_joined" {
+		t.Errorf("Expected the frame delivered locally as is, got %v", delivered)
+	}
+}
@@ -0,0 +1,196 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// Fuzz targets for everything consumers decode from Kafka. Run one with e.g.
+// go test ./test/ -run '^$' -fuzz FuzzMessageDecode -fuzztime 30s
+
+func FuzzMessageDecode(f *testing.F) {
+	f.Add([]byte(`{"id":"m1","from_agent_id":"a","to_agent_id":"b","type":"task","payload":{"k":"v"},"timestamp":"2024-01-01T00:00:00Z"}`))
+	f.Add([]byte(`{"payload":null}`))
+	f.Add([]byte(`{"payload":{"proposal":{"proposer_id":1}}}`))
+	f.Add([]byte(`[]`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg types.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return
+		}
+
+		// Whatever decoded must survive a round trip
+		encoded, err := json.Marshal(&msg)
+		if err != nil {
+			t.Fatalf("Failed to re-encode decoded message: %v", err)
+		}
+		var again types.Message
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatalf("Failed to decode re-encoded message: %v", err)
+		}
+		if again.ID != msg.ID || again.FromAgentID != msg.FromAgentID || again.ToAgentID != msg.ToAgentID {
+			t.Fatalf("Round trip changed message identity: %+v -> %+v", msg, again)
+		}
+
+		// Consensus consumers must reject, not panic on, arbitrary payloads
+		consensus.ParseProposalRequest(msg.Payload)
+		consensus.ParseVoteRequest(msg.Payload)
+	})
+}
+
+func FuzzTopologyEventDecode(f *testing.F) {
+	f.Add([]byte(`{"type":"agent_joined","agent_id":"a","agent":{"id":"a","name":"A","role":"r"},"timestamp":"2024-01-01T00:00:00Z"}`))
+	f.Add([]byte(`{"type":"agent_left","agent_id":"a"}`))
+	f.Add([]byte(`{"type":"agent_joined","agent":null}`))
+	f.Add([]byte(`{"id":"m1","from_agent_id":"a","type":"agent_joined","payload":{"agent":"{\"id\":\"a\",\"name\":\"A\",\"role\":\"r\"}"}}`))
+	f.Add([]byte(`{"id":"m2","type":"topology","payload":{"event":{"type":"agent_left","agent_id":"a"}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		event, err := types.DecodeTopologyEvent(data)
+		if err != nil {
+			return
+		}
+
+		// Apply the event the way the topology manager does
+		graph := topology.NewGraph(&types.Config{InitialEdgeWeight: 0.5, ReinforcementAmount: 0.1})
+		graph.AddAgent(&types.Agent{ID: "existing"})
+
+		switch event.Type {
+		case types.TopologyEventAgentJoined:
+			if event.Agent != nil {
+				graph.AddAgent(event.Agent)
+			}
+		case types.TopologyEventAgentLeft:
+			graph.RemoveAgent(event.AgentID)
+		}
+		graph.GetSnapshot()
+	})
+}
+
+func FuzzProposalDecode(f *testing.F) {
+	f.Add([]byte(`{"id":"p1","proposer_id":"a","type":"decision","content":{"x":1},"votes":{"a":{"voter_id":"a","support":true,"intensity":0.5}},"status":"pending"}`))
+	f.Add([]byte(`{"votes":null}`))
+	f.Add([]byte(`{"votes":{"":{"support":true}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var proposal types.Proposal
+		if err := json.Unmarshal(data, &proposal); err != nil {
+			return
+		}
+
+		for _, agents := range []int{0, 1, 5} {
+			quorum := proposal.GetQuorum(agents)
+			if quorum < 0 {
+				t.Fatalf("Negative quorum %f", quorum)
+			}
+		}
+		proposal.AddVote(types.Vote{VoterID: "fuzz", Support: true, Intensity: 1, Timestamp: time.Now()})
+		if _, ok := proposal.Votes["fuzz"]; !ok {
+			t.Fatal("Vote was not recorded")
+		}
+	})
+}
+
+func FuzzVoteRequest(f *testing.F) {
+	f.Add([]byte(`{"vote":{"proposal_id":"p","voter_id":"a","support":true,"intensity":0.7}}`))
+	f.Add([]byte(`{"vote":{"proposal_id":"p","voter_id":"a","support":"yes","intensity":0.7}}`))
+	f.Add([]byte(`{"vote":{"proposal_id":"p","voter_id":"a","support":true,"intensity":7}}`))
+	f.Add([]byte(`{"vote":{"proposal_id":"p","voter_id":"a","support":false,"intensity":0.4,"rationale":"too risky","factors":[{"name":"risk","weight":-0.8}]}}`))
+	f.Add([]byte(`{"vote":{"proposal_id":"p","voter_id":"a","choice":"conditional","intensity":0.5,"condition":{"field":"order.amount","operator":"<","value":100}}}`))
+	f.Add([]byte(`{"vote":{"bundle_id":"b","voter_id":"a","support":true,"intensity":0.6}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var payload map[string]any
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return
+		}
+
+		vote, err := consensus.ParseVoteRequest(payload)
+		if err != nil {
+			return
+		}
+		if (vote.ProposalID == "") == (vote.BundleID == "") || vote.VoterID == "" {
+			t.Fatalf("Accepted vote without IDs: %+v", vote)
+		}
+		if vote.Intensity < 0 || vote.Intensity > 1 {
+			t.Fatalf("Accepted out-of-range intensity %f", vote.Intensity)
+		}
+		if err := types.ValidateRationale(vote.Rationale, vote.Factors); err != nil {
+			t.Fatalf("Accepted invalid rationale: %v", err)
+		}
+		if err := types.ValidateChoice(vote.Choice, vote.Condition); err != nil {
+			t.Fatalf("Accepted invalid choice: %v", err)
+		}
+	})
+}
+
+func FuzzEdgeIDRoundTrip(f *testing.F) {
+	f.Add("agent-a", "agent-b")
+	f.Add("a->b", "c")
+	f.Add("a-", ">b")
+	f.Add(`\`, `\>`)
+
+	f.Fuzz(func(t *testing.T, source, target string) {
+		edgeID := types.NewEdgeID(types.AgentID(source), types.AgentID(target))
+		gotSource, gotTarget, err := types.ParseEdgeID(edgeID)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", edgeID, err)
+		}
+		if gotSource != types.AgentID(source) || gotTarget != types.AgentID(target) {
+			t.Fatalf("%q parsed as %q -> %q, want %q -> %q", edgeID, gotSource, gotTarget, source, target)
+		}
+	})
+}
+
+func FuzzParseEdgeID(f *testing.F) {
+	f.Add("a->b")
+	f.Add(`a\->b`)
+	f.Add(`a->b>c`)
+	f.Add(`\`)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		source, target, err := types.ParseEdgeID(types.EdgeID(s))
+		if err != nil {
+			return
+		}
+		// Anything accepted must be exactly what NewEdgeID would produce
+		if types.NewEdgeID(source, target) != types.EdgeID(s) {
+			t.Fatalf("%q parsed as %q -> %q, which re-encodes differently", s, source, target)
+		}
+	})
+}
+
+func FuzzReinforceEdge(f *testing.F) {
+	f.Add("agent-a", "agent-b")
+	f.Add("a", "a")
+	f.Add("", "b")
+	f.Add("a->b", "c")
+	f.Add("a", "b->c")
+	f.Add(`a\`, `>b`)
+
+	f.Fuzz(func(t *testing.T, source, target string) {
+		config := &types.Config{InitialEdgeWeight: 0.5, ReinforcementAmount: 0.1}
+		graph := topology.NewGraph(config)
+		graph.AddAgent(&types.Agent{ID: types.AgentID(source)})
+		graph.AddAgent(&types.Agent{ID: types.AgentID(target)})
+
+		edgeID := types.NewEdgeID(types.AgentID(source), types.AgentID(target))
+		if err := graph.ReinforceEdge(edgeID, types.MessageTypeTask); err != nil {
+			t.Fatalf("Failed to reinforce edge between existing agents: %v", err)
+		}
+
+		edge, err := graph.GetEdge(edgeID)
+		if err != nil {
+			t.Fatalf("Reinforced edge missing: %v", err)
+		}
+		if edge.SourceID != types.AgentID(source) || edge.TargetID != types.AgentID(target) {
+			t.Fatalf("Edge %q has endpoints %q -> %q", edgeID, edge.SourceID, edge.TargetID)
+		}
+	})
+}
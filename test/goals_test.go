@@ -0,0 +1,93 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestGoalValidateDefaults(t *testing.T) {
+	goal := &types.Goal{Name: "Stockouts", Metric: "stockout_rate", Comparator: "<", Target: 2}
+	if err := goal.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if goal.Aggregation != "avg" || goal.Window != "24h" {
+		t.Errorf("expected defaults avg/24h, got %s/%s", goal.Aggregation, goal.Window)
+	}
+
+	invalid := []*types.Goal{
+		{Metric: "stockout_rate", Comparator: "<"},
+		{Name: "x", Metric: "stockout_rate", Comparator: "=="},
+		{Name: "x", Metric: "stockout_rate", Comparator: "<", Aggregation: "median"},
+		{Name: "x", Metric: "stockout_rate", Comparator: "<", Window: "-1h"},
+	}
+	for i, g := range invalid {
+		if err := g.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestGoalEvaluate(t *testing.T) {
+	now := time.Now()
+	goal := &types.Goal{ID: "tickets", Name: "Tickets", Metric: "ticket_resolution_minutes", Comparator: "<", Target: 60, Window: "1h"}
+	if err := goal.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if status := goal.Evaluate(nil, now); status.State != types.GoalStateNoData || status.Current != nil {
+		t.Fatalf("expected no_data, got %s", status.State)
+	}
+
+	report := func(agent types.AgentID, value float64, ago time.Duration) types.GoalProgress {
+		return types.GoalProgress{GoalID: goal.ID, AgentID: agent, AgentRole: "support", Value: value, ReportedAt: now.Add(-ago)}
+	}
+
+	cases := []struct {
+		name     string
+		progress []types.GoalProgress
+		want     types.GoalState
+	}{
+		{"on track", []types.GoalProgress{report("a", 20, time.Minute), report("b", 40, 2*time.Minute)}, types.GoalStateOnTrack},
+		{"at risk", []types.GoalProgress{report("a", 56, time.Minute)}, types.GoalStateAtRisk},
+		{"off track", []types.GoalProgress{report("a", 90, time.Minute)}, types.GoalStateOffTrack},
+		{"outside window", []types.GoalProgress{report("a", 10, 2*time.Hour)}, types.GoalStateNoData},
+	}
+	for _, tc := range cases {
+		if status := goal.Evaluate(tc.progress, now); status.State != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, status.State)
+		}
+	}
+
+	status := goal.Evaluate([]types.GoalProgress{
+		report("a", 30, 3*time.Minute),
+		report("b", 50, 2*time.Minute),
+		report("a", 10, time.Minute),
+	}, now)
+	if status.Samples != 3 || *status.Current != 30 {
+		t.Fatalf("expected 3 samples averaging 30, got %d/%v", status.Samples, *status.Current)
+	}
+	if len(status.Contributors) != 2 || status.Contributors[0].AgentID != "a" {
+		t.Fatalf("expected agent a to lead 2 contributors, got %+v", status.Contributors)
+	}
+	if c := status.Contributors[0]; c.Samples != 2 || c.Average != 20 || c.LastValue != 10 {
+		t.Errorf("unexpected contributor summary %+v", c)
+	}
+}
+
+func TestGoalProgressInsight(t *testing.T) {
+	insight := types.NewGoalProgressInsight("agent-1", "inventory", "stockouts", 1.5)
+	progress, err := insight.GoalProgress()
+	if err != nil {
+		t.Fatalf("GoalProgress failed: %v", err)
+	}
+	if progress.GoalID != "stockouts" || progress.AgentID != "agent-1" || progress.Value != 1.5 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+
+	other := types.NewInsight("agent-1", "inventory", types.InsightTypeInventoryTrend, "stock", "low", 0.9)
+	if _, err := other.GoalProgress(); err == nil {
+		t.Error("expected error for non-progress insight")
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func newGuardedGraph(t *testing.T, config *types.Config, agents int) (*topology.Graph, []types.AgentID) {
+	t.Helper()
+	config.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	graph := topology.NewGraph(config)
+	ids := make([]types.AgentID, agents)
+	for i := range ids {
+		ids[i] = types.NewAgentID()
+		if err := graph.AddAgent(&types.Agent{ID: ids[i], Status: types.AgentStatusActive, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("failed to add agent: %v", err)
+		}
+	}
+	return graph, ids
+}
+
+func TestPruneCapPerCycle(t *testing.T) {
+	config := &types.Config{InitialEdgeWeight: 0.05, PruneThreshold: 0.1, MaxPrunePerCycle: 3}
+	graph, _ := newGuardedGraph(t, config, 3) // 9 edges, all below the threshold
+
+	if pruned := graph.PruneWeakEdges(); len(pruned) != 3 {
+		t.Fatalf("expected 3 prunes in first cycle, got %d", len(pruned))
+	}
+	if graph.GetEdgeCount() != 6 {
+		t.Errorf("expected 6 edges left, got %d", graph.GetEdgeCount())
+	}
+	if deferred := graph.Guardrails().Status().PruneDeferred; deferred != 6 {
+		t.Errorf("expected 6 deferred prunes, got %d", deferred)
+	}
+}
+
+func TestWeightChangeLimit(t *testing.T) {
+	config := &types.Config{InitialEdgeWeight: 0.2, ReinforcementAmount: 0.1, MaxWeightChangePerMinute: 0.25}
+	graph, ids := newGuardedGraph(t, config, 2)
+
+	for i := 0; i < 10; i++ {
+		graph.ReinforceEdge(types.NewEdgeID(ids[0], ids[1]), types.MessageTypeTask)
+	}
+
+	edge, _ := graph.GetEdgeBetween(ids[0], ids[1])
+	if w := edge.GetWeight(); w > 0.2+0.25+1e-9 {
+		t.Errorf("weight moved past the per-minute limit: %.3f", w)
+	}
+	if edge.Usage != 10 {
+		t.Errorf("usage should still be counted, got %d", edge.Usage)
+	}
+}
+
+func TestChurnBreakerFreezesTopology(t *testing.T) {
+	config := &types.Config{InitialEdgeWeight: 0.05, PruneThreshold: 0.1, DecayRate: 0.01, ChurnFreezeThreshold: 4, ChurnWindow: time.Minute}
+	graph, ids := newGuardedGraph(t, config, 3)
+
+	graph.PruneWeakEdges()
+	if !graph.Guardrails().Frozen() {
+		t.Fatal("expected breaker to freeze after 9 prunes with a limit of 4")
+	}
+
+	// No new paths and no decay while frozen
+	if err := graph.ReinforceEdge(types.NewEdgeID(ids[0], ids[1]), types.MessageTypeTask); err != topology.ErrTopologyFrozen {
+		t.Errorf("expected ErrTopologyFrozen, got %v", err)
+	}
+
+	graph.Guardrails().Unfreeze()
+	if graph.Guardrails().Frozen() {
+		t.Fatal("expected Unfreeze to lift the breaker")
+	}
+	if err := graph.ReinforceEdge(types.NewEdgeID(ids[0], ids[1]), types.MessageTypeTask); err != nil {
+		t.Errorf("expected reinforcement after unfreeze, got %v", err)
+	}
+}
+
+func TestManualFreezeSurvivesRecover(t *testing.T) {
+	guardrails := topology.NewGuardrails(&types.Config{ChurnFreezeThreshold: 10})
+
+	guardrails.Freeze("maintenance", true)
+	if guardrails.Recover() || !guardrails.Frozen() {
+		t.Fatal("manual freeze must not be lifted automatically")
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/handoff"
+)
+
+type fakeManager struct {
+	state    json.RawMessage
+	exported bool
+	resumed  chan struct{}
+	imported json.RawMessage
+}
+
+func (f *fakeManager) ExportState(ctx context.Context) (json.RawMessage, error) {
+	f.exported = true
+	return f.state, nil
+}
+
+func (f *fakeManager) ResumeAfterHandoff() error {
+	close(f.resumed)
+	return nil
+}
+
+func (f *fakeManager) ImportState(state json.RawMessage) error {
+	f.imported = state
+	return nil
+}
+
+// startHandoffServer serves old on a free local port and returns its address
+func startHandoffServer(t *testing.T, old *fakeManager) (*handoff.Server, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to pick a port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server := handoff.NewServer("topology", old, zap.NewNop())
+	go server.Serve(addr)
+	t.Cleanup(server.Stop)
+
+	// Wait for the listener
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return server, addr
+}
+
+func TestHandoffTransfersState(t *testing.T) {
+	old := &fakeManager{state: json.RawMessage(`{"agents":{},"edges":{}}`), resumed: make(chan struct{})}
+	server, addr := startHandoffServer(t, old)
+
+	successor := &fakeManager{}
+	started := false
+	err := handoff.Takeover(context.Background(), addr, "topology", successor, func() error {
+		started = true
+		return nil
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Takeover failed: %v", err)
+	}
+
+	if !old.exported || !started {
+		t.Fatalf("expected export and start, got exported=%v started=%v", old.exported, started)
+	}
+	if string(successor.imported) != string(old.state) {
+		t.Errorf("imported %s, want %s", successor.imported, old.state)
+	}
+
+	select {
+	case <-server.Done():
+	case <-time.After(time.Second):
+		t.Fatal("old instance was not released")
+	}
+}
+
+func TestHandoffResumesWhenSuccessorFails(t *testing.T) {
+	old := &fakeManager{state: json.RawMessage(`[]`), resumed: make(chan struct{})}
+	server, addr := startHandoffServer(t, old)
+
+	err := handoff.Takeover(context.Background(), addr, "topology", &fakeManager{}, func() error {
+		return errors.New("boom")
+	}, zap.NewNop())
+	if err == nil {
+		t.Fatal("expected Takeover to fail")
+	}
+
+	select {
+	case <-old.resumed:
+	case <-time.After(time.Second):
+		t.Fatal("old instance did not resume")
+	}
+	select {
+	case <-server.Done():
+		t.Fatal("old instance released after aborted handoff")
+	default:
+	}
+}
+
+func TestHandoffWrongManager(t *testing.T) {
+	old := &fakeManager{resumed: make(chan struct{})}
+	_, addr := startHandoffServer(t, old)
+
+	err := handoff.Takeover(context.Background(), addr, "knowledge", &fakeManager{}, func() error { return nil }, zap.NewNop())
+	if !errors.Is(err, handoff.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if old.exported {
+		t.Error("state exported to the wrong manager")
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestSystemHealthRollup(t *testing.T) {
+	cfg := config.Default()
+	now := time.Now()
+	recent := now.Add(-10 * time.Second)
+
+	services := []types.ServiceHealth{
+		{Service: "topology-manager", Instance: "a:1", Active: true},
+		{Service: "knowledge-manager", Instance: "b:1", Active: true},
+		{Service: "consensus-manager", Instance: "c:1", Active: true},
+	}
+	health := types.RollupHealth(cfg, types.HealthProbes{LastSnapshotAt: &recent}, services, now)
+	if health.Status != types.HealthOK {
+		t.Fatalf("Expected ok, got %s: %+v", health.Status, health.Checks)
+	}
+
+	// A lagging consumer and a second active topology manager degrade the mesh
+	services[1].ConsumerLag = map[string]int64{"knowledge-manager": cfg.HealthMaxConsumerLag + 1}
+	services = append(services, types.ServiceHealth{Service: "topology-manager", Instance: "a:2", Active: true})
+	health = types.RollupHealth(cfg, types.HealthProbes{LastSnapshotAt: &recent}, services, now)
+	if health.Status != types.HealthDegraded {
+		t.Fatalf("Expected degraded, got %s: %+v", health.Status, health.Checks)
+	}
+	degraded := map[string]bool{}
+	for _, check := range health.Checks {
+		if check.Status == types.HealthDegraded {
+			degraded[check.Name] = true
+		}
+	}
+	if !degraded["consumer_lag"] || !degraded["topology-manager"] || len(degraded) != 2 {
+		t.Errorf("Expected consumer_lag and topology-manager degraded, got %+v", health.Checks)
+	}
+
+	// A handed-off knowledge manager leaves no active instance, and Kafka is unreachable
+	services[1].Active = false
+	stale := now.Add(-2 * cfg.HealthMaxSnapshotAge)
+	health = types.RollupHealth(cfg, types.HealthProbes{KafkaError: errors.New("connection refused"), LastSnapshotAt: &stale}, services, now)
+	if health.Status != types.HealthDown {
+		t.Fatalf("Expected down, got %s: %+v", health.Status, health.Checks)
+	}
+	statuses := map[string]types.HealthStatus{}
+	for _, check := range health.Checks {
+		statuses[check.Name] = check.Status
+	}
+	if statuses["kafka"] != types.HealthDown || statuses["knowledge-manager"] != types.HealthDown || statuses["topology_snapshot"] != types.HealthDegraded {
+		t.Errorf("Unexpected checks: %+v", health.Checks)
+	}
+}
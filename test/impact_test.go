@@ -0,0 +1,137 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/ranking"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func decision(proposer types.AgentID, decidedAt time.Time) *types.Proposal {
+	return &types.Proposal{
+		ID:         types.NewProposalID(),
+		ProposerID: proposer,
+		Type:       types.ProposalTypeAction,
+		Content: map[string]any{
+			"action": "restock",
+			"intent": map[string]any{"metric": "stockout_percent", "comparator": "<", "target": 2.0, "window": "1h"},
+		},
+		Status:    types.ProposalStatusAccepted,
+		CreatedAt: decidedAt.Add(-time.Minute),
+		DecidedAt: decidedAt,
+	}
+}
+
+func measured(proposal *types.Proposal, value float64, at time.Time) types.DecisionOutcome {
+	insight := types.NewDecisionOutcomeInsight("agent-inventory-1", "inventory", proposal.ID, "stockout_percent", value)
+	insight.CreatedAt = at
+	outcome, err := insight.DecisionOutcome()
+	if err != nil {
+		panic(err)
+	}
+	return outcome
+}
+
+func TestEvaluateDecision(t *testing.T) {
+	decidedAt := time.Now().Add(-2 * time.Hour)
+	proposal := decision("agent-inventory-1", decidedAt)
+
+	outcomes := []types.DecisionOutcome{
+		measured(proposal, 9, decidedAt.Add(-time.Minute)), // Before the decision
+		measured(proposal, 1.5, decidedAt.Add(20*time.Minute)),
+		measured(proposal, 1.0, decidedAt.Add(40*time.Minute)),
+		measured(proposal, 9, decidedAt.Add(90*time.Minute)), // After the window
+	}
+
+	if impact := types.EvaluateDecision(proposal, outcomes, decidedAt.Add(30*time.Minute)); impact.Verdict != types.DecisionVerdictPending {
+		t.Errorf("expected pending while the window is open, got %s", impact.Verdict)
+	}
+
+	impact := types.EvaluateDecision(proposal, outcomes, time.Now())
+	if impact.Verdict != types.DecisionVerdictAchieved || impact.Samples != 2 || *impact.Current != 1.25 {
+		t.Fatalf("expected achieved with 2 samples averaging 1.25, got %s %d %v", impact.Verdict, impact.Samples, impact.Current)
+	}
+	if len(impact.Outcomes) != 4 {
+		t.Errorf("expected every linked outcome to be listed, got %d", len(impact.Outcomes))
+	}
+
+	missed := types.EvaluateDecision(proposal, outcomes[3:], time.Now())
+	if missed.Verdict != types.DecisionVerdictNoData {
+		t.Errorf("expected no_data without measurements in the window, got %s", missed.Verdict)
+	}
+	offTarget := []types.DecisionOutcome{measured(proposal, 4, decidedAt.Add(10*time.Minute))}
+	if v := types.EvaluateDecision(proposal, offTarget, time.Now()).Verdict; v != types.DecisionVerdictNotAchieved {
+		t.Errorf("expected not_achieved, got %s", v)
+	}
+
+	delete(proposal.Content, "intent")
+	if v := types.EvaluateDecision(proposal, outcomes, time.Now()).Verdict; v != types.DecisionVerdictNoIntent {
+		t.Errorf("expected no_intent, got %s", v)
+	}
+	proposal.Content["intent"] = map[string]any{"metric": "stockout_percent", "comparator": "~", "target": 2.0}
+	if _, err := proposal.Intent(); err == nil {
+		t.Error("expected an invalid comparator to be rejected")
+	}
+}
+
+func TestDecisionOutcomeLinking(t *testing.T) {
+	tagged := types.NewInsight("agent-support-1", "support", types.InsightTypeCustomerFeedback, "restock", "Fewer complaints", 0.8)
+	tagged.Tags = append(tagged.Tags, "proposal:p-42")
+	outcome, err := tagged.DecisionOutcome()
+	if err != nil || outcome.ProposalID != "p-42" || outcome.Value != nil {
+		t.Errorf("expected a tagged insight to link as evidence, got %+v (%v)", outcome, err)
+	}
+	if _, err := types.NewInsight("a", "support", types.InsightTypeAnomaly, "x", "y", 0.5).DecisionOutcome(); err == nil {
+		t.Error("expected an untagged insight not to link")
+	}
+
+	learner := routing.NewLearner(config.Default())
+	now := time.Now()
+	task := &types.Message{
+		ID:          "task-1",
+		FromAgentID: "agent-inventory-1",
+		ToAgentID:   "agent-warehouse-1",
+		Type:        types.MessageTypeTask,
+		Payload:     map[string]any{"action": "restock", "proposal_id": "p-42"},
+		Timestamp:   now,
+	}
+	learner.Observe(task)
+	result := learner.Observe(types.NewResponse(task, false, nil))
+	linked, ok := result.DecisionOutcome()
+	if !ok || linked.ProposalID != "p-42" || linked.Source != types.DecisionOutcomeTask || *linked.Success {
+		t.Errorf("expected a failed task outcome for p-42, got %+v", linked)
+	}
+}
+
+func TestDecisionImpactFeedsReputation(t *testing.T) {
+	decidedAt := time.Now().Add(-2 * time.Hour)
+	good := decision("agent-good", decidedAt)
+	bad := decision("agent-bad", decidedAt)
+
+	var impacts []types.DecisionImpact
+	var corpus []*types.Insight
+	for _, d := range []struct {
+		proposal *types.Proposal
+		value    float64
+	}{{good, 1}, {bad, 5}} {
+		impact := types.EvaluateDecision(d.proposal, []types.DecisionOutcome{measured(d.proposal, d.value, decidedAt.Add(time.Minute))}, time.Now())
+		impacts = append(impacts, impact)
+		corpus = append(corpus, types.NewDecisionImpactInsight("knowledge-manager", impact))
+	}
+
+	records := types.SummarizeDecisions(impacts)
+	if len(records) != 2 || records[0].ProposerID != "agent-good" || records[0].SuccessRate != 1 || records[1].NotAchieved != 1 {
+		t.Fatalf("unexpected track records %+v", records)
+	}
+
+	ranker := ranking.NewRanker(config.Default(), corpus)
+	if ranker.Reputation("agent-good") <= ranker.Reputation("agent-bad") {
+		t.Error("expected an achieved decision to raise the proposer's reputation")
+	}
+	if ranker.Reputation("knowledge-manager") != 0.5 {
+		t.Error("decision impact insights should not build the publisher's reputation")
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestAutoIncident(t *testing.T) {
+	now := time.Now()
+	degraded := types.SystemHealth{
+		Status: types.HealthDegraded,
+		Checks: []types.HealthCheck{
+			{Name: "redis", Status: types.HealthOK},
+			{Name: "consumer_lag", Status: types.HealthDegraded},
+		},
+	}
+	healthy := types.SystemHealth{Status: types.HealthOK}
+
+	// Without a trigger nothing is declared
+	if declare, clear := types.AutoIncident(nil, degraded, "", now); declare != nil || clear {
+		t.Fatalf("Expected no automatic incidents without a trigger, got %+v, %v", declare, clear)
+	}
+
+	// Degraded health declares with a degraded trigger, not with a down one
+	declare, _ := types.AutoIncident(nil, degraded, types.HealthDegraded, now)
+	if declare == nil || !declare.Automatic || declare.TriggeredBy != "consumer_lag" || !declare.DeclaredAt.Equal(now) {
+		t.Fatalf("Expected an automatic incident triggered by consumer_lag, got %+v", declare)
+	}
+	if declare, _ := types.AutoIncident(nil, degraded, types.HealthDown, now); declare != nil {
+		t.Errorf("Expected degraded health below a down trigger, got %+v", declare)
+	}
+
+	// An automatic incident clears once health recovers, not before
+	if again, clear := types.AutoIncident(declare, degraded, types.HealthDegraded, now); again != nil || clear {
+		t.Errorf("Expected the incident kept while degraded, got %+v, %v", again, clear)
+	}
+	if _, clear := types.AutoIncident(declare, healthy, types.HealthDegraded, now); !clear {
+		t.Error("Expected the incident cleared once healthy")
+	}
+
+	// A manual incident is left to whoever declared it
+	manual := &types.Incident{Reason: "payment outage", DeclaredBy: "oncall"}
+	if again, clear := types.AutoIncident(manual, healthy, types.HealthDegraded, now); again != nil || clear {
+		t.Errorf("Expected a manual incident left alone, got %+v, %v", again, clear)
+	}
+
+	if _, err := types.ParseIncidentAutoStatus("ok"); err == nil {
+		t.Error("Expected ok rejected as an incident trigger")
+	}
+}
+
+func TestIncidentThrottle(t *testing.T) {
+	var throttle types.IncidentThrottle
+	for i := 0; i < 3; i++ {
+		if !throttle.Allow(false, 4) {
+			t.Fatal("Expected every tick to run without an incident")
+		}
+	}
+
+	// During an incident only every 4th tick runs
+	ran := 0
+	for i := 0; i < 12; i++ {
+		if throttle.Allow(true, 4) {
+			ran++
+		}
+	}
+	if ran != 3 {
+		t.Errorf("Expected 3 of 12 ticks run, got %d", ran)
+	}
+	if !throttle.Allow(true, 1) {
+		t.Error("Expected a slowdown of 1 to run every tick")
+	}
+}
+
+func TestIncidentPausesDecay(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	for _, id := range []types.AgentID{"sales-1", "support-1"} {
+		slimeMold.AddAgent(&types.Agent{ID: id, Role: "sales", Status: types.AgentStatusActive})
+	}
+	edgeID := types.NewEdgeID("sales-1", "support-1")
+	weight := slimeMold.GetSnapshot().Edges[edgeID].Weight
+
+	if !slimeMold.PauseDecay(true) || slimeMold.PauseDecay(true) {
+		t.Fatal("Expected only the first pause to change anything")
+	}
+	for i := 0; i < 50; i++ {
+		slimeMold.DecayCycle()
+	}
+	edge := slimeMold.GetSnapshot().Edges[edgeID]
+	if edge == nil || edge.Weight != weight {
+		t.Fatalf("Expected the edge untouched while paused, got %+v", edge)
+	}
+
+	if !slimeMold.PauseDecay(false) {
+		t.Fatal("Expected resuming to change the state")
+	}
+	slimeMold.DecayCycle()
+	if edge := slimeMold.GetSnapshot().Edges[edgeID]; edge != nil && edge.Weight >= weight {
+		t.Errorf("Expected the edge to decay once resumed, got %.3f", edge.Weight)
+	}
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestPrepareInsightBatch(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	historical := now.AddDate(-1, 0, 0)
+	insight := func(topic string, confidence float64) types.Insight {
+		return types.Insight{
+			AgentID:    "crm-import",
+			AgentRole:  "sales",
+			Type:       types.InsightTypeCustomerFeedback,
+			Topic:      topic,
+			Content:    "Customers asked for annual billing",
+			Confidence: confidence,
+			CreatedAt:  historical,
+		}
+	}
+
+	restricted := insight("pricing", 0.5)
+	restricted.Privacy = types.InsightPrivacyRestricted
+	future := insight("pricing", 0.5)
+	future.CreatedAt = now.Add(time.Hour)
+	undated := insight("refunds", 0.5)
+	undated.CreatedAt = time.Time{}
+	named := insight("fraud", 0.9)
+	named.ID = "insight-crm-42"
+
+	batch := []types.Insight{
+		insight("pricing", 0.8),
+		insight("", 0.8),
+		insight("pricing", 0.8), // Same as the first
+		insight("pricing", 1.5),
+		restricted,
+		future,
+		undated,
+		named,
+		named,
+	}
+	valid, report := types.PrepareInsightBatch(batch, now)
+
+	if len(valid) != 3 || report.Received != 9 || report.Rejected != 4 || report.Duplicates != 2 || len(report.Results) != 6 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Results[0].Index != 1 || report.Results[0].Status != types.IngestRejected || report.Results[0].Error != "topic is required" {
+		t.Errorf("Expected the insight without a topic rejected, got %+v", report.Results[0])
+	}
+	if report.Results[1].Index != 2 || report.Results[1].Status != types.IngestDuplicate || report.Results[1].ID != valid[0].ID {
+		t.Errorf("Expected the repeated insight reported as a duplicate, got %+v", report.Results[1])
+	}
+
+	// Derived IDs are stable, so resending a batch is detected as duplicate
+	again := insight("pricing", 0.8)
+	if valid[0].ID == "" || valid[0].ID != again.FingerprintID() || valid[0].ID == valid[1].ID {
+		t.Errorf("Expected stable, distinct derived IDs, got %q and %q", valid[0].ID, valid[1].ID)
+	}
+	if !valid[0].CreatedAt.Equal(historical) || !valid[1].CreatedAt.Equal(now) || valid[1].Privacy != types.InsightPrivacyPublic {
+		t.Errorf("Expected historical dates kept and defaults filled in, got %+v", valid[1])
+	}
+	if valid[2].ID != "insight-crm-42" {
+		t.Errorf("Expected given IDs kept, got %q", valid[2].ID)
+	}
+
+	report.Record(types.IngestionResult{Index: 0, ID: valid[0].ID, Status: types.IngestAccepted})
+	if report.Accepted != 1 || len(report.Results) != 6 {
+		t.Errorf("Expected accepted insights counted but not listed, got %+v", report)
+	}
+}
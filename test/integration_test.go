@@ -0,0 +1,1319 @@
+//go:build integration
+
+package test
+
+// End-to-end tests against real Kafka and Redis started with testcontainers.
+// The managers run in-process, wired exactly as their cmd binaries wire them.
+//
+// Run with: go test -tags integration ./test/ (requires Docker)
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/manager"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// meshTopics are created up front so readers never race topic auto-creation
+var meshTopics = []string{"topology", "messages", "insights", "proposals", "votes"}
+
+// Shared infrastructure, started once in TestMain
+var (
+	kafkaBrokers []string
+	redisAddr    string
+)
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	kafkaContainer, err := tckafka.Run(ctx, "confluentinc/confluent-local:7.5.0", tckafka.WithClusterID("agentmesh-test"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start Kafka: %v\n", err)
+		os.Exit(1)
+	}
+
+	redisContainer, err := tcredis.Run(ctx, "redis:7-alpine")
+	if err != nil {
+		testcontainers.TerminateContainer(kafkaContainer)
+		fmt.Fprintf(os.Stderr, "Failed to start Redis: %v\n", err)
+		os.Exit(1)
+	}
+
+	kafkaBrokers, err = kafkaContainer.Brokers(ctx)
+	if err == nil {
+		redisAddr, err = redisContainer.Endpoint(ctx, "")
+	}
+
+	code := 1
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve container endpoints: %v\n", err)
+	} else {
+		code = m.Run()
+	}
+
+	testcontainers.TerminateContainer(redisContainer)
+	testcontainers.TerminateContainer(kafkaContainer)
+	os.Exit(code)
+}
+
+// meshHarness is an isolated mesh: its own topic prefix and Redis database
+type meshHarness struct {
+	cfg       *types.Config
+	logger    *zap.Logger
+	store     *state.RedisStore
+	messaging *messaging.KafkaMessaging
+}
+
+var nextRedisDB = 0
+
+// newMesh creates topics and clients for one test. Timers that would make
+// results time-dependent (decay, proposal expiry) are pushed far out.
+func newMesh(t *testing.T) *meshHarness {
+	t.Helper()
+
+	nextRedisDB++
+	cfg := config.Default()
+	cfg.KafkaBrokers = kafkaBrokers
+	cfg.KafkaTopicPrefix = "it-" + uuid.New().String()[:8]
+	cfg.RedisAddr = redisAddr
+	cfg.RedisDB = nextRedisDB
+	cfg.DecayInterval = time.Hour
+	cfg.ProposalTimeout = time.Hour
+
+	createTopics(t, cfg)
+
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel))
+
+	store, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	km := messaging.NewKafkaMessaging(cfg, logger)
+
+	t.Cleanup(func() {
+		km.Close()
+		store.Close()
+	})
+
+	return &meshHarness{cfg: cfg, logger: logger, store: store, messaging: km}
+}
+
+// createTopics creates the mesh topics for cfg's prefix on the controller
+func createTopics(t *testing.T, cfg *types.Config) {
+	t.Helper()
+
+	conn, err := kafka.Dial("tcp", cfg.KafkaBrokers[0])
+	if err != nil {
+		t.Fatalf("Failed to dial Kafka: %v", err)
+	}
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	if err != nil {
+		t.Fatalf("Failed to find Kafka controller: %v", err)
+	}
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		t.Fatalf("Failed to dial Kafka controller: %v", err)
+	}
+	defer controllerConn.Close()
+
+	configs := make([]kafka.TopicConfig, len(meshTopics))
+	for i, topic := range meshTopics {
+		configs[i] = kafka.TopicConfig{
+			Topic:             cfg.KafkaTopicPrefix + "." + topic,
+			NumPartitions:     1,
+			ReplicationFactor: 1,
+		}
+	}
+	if err := controllerConn.CreateTopics(configs...); err != nil {
+		t.Fatalf("Failed to create topics: %v", err)
+	}
+}
+
+// eventually polls cond until it succeeds or the timeout elapses
+func eventually(t *testing.T, timeout time.Duration, what string, cond func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := cond()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s: %v", what, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// joinAgents publishes agent-joined events and returns the agents
+func (h *meshHarness) joinAgents(t *testing.T, ctx context.Context, count int) []*types.Agent {
+	t.Helper()
+
+	agents := make([]*types.Agent, count)
+	for i := range agents {
+		agents[i] = &types.Agent{
+			ID:        types.NewAgentID(),
+			Name:      fmt.Sprintf("agent-%d", i),
+			Role:      "test",
+			Status:    types.AgentStatusActive,
+			CreatedAt: time.Now(),
+		}
+		event := types.TopologyEvent{
+			Type:      types.TopologyEventAgentJoined,
+			AgentID:   agents[i].ID,
+			Agent:     agents[i],
+			Timestamp: time.Now(),
+		}
+		if err := h.messaging.PublishTopologyEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to publish join event: %v", err)
+		}
+	}
+	return agents
+}
+
+// redisClient connects to the harness's Redis database, for keys the store
+// has no accessors for
+func (h *meshHarness) redisClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: h.cfg.RedisAddr, DB: h.cfg.RedisDB})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestIntegrationJoinMessageReinforcementSnapshot(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tm := manager.NewTopologyManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := tm.Start(ctx); err != nil {
+		t.Fatalf("Failed to start topology manager: %v", err)
+	}
+	defer tm.Stop()
+
+	agents := h.joinAgents(t, ctx, 3)
+	eventually(t, 30*time.Second, "agents to join", func() error {
+		if n := tm.SlimeMold().GetGraph().GetAgentCount(); n != len(agents) {
+			return fmt.Errorf("have %d agents", n)
+		}
+		return nil
+	})
+
+	const messageCount = 3
+	for i := 0; i < messageCount; i++ {
+		msg := &types.Message{
+			ID:          uuid.New().String(),
+			FromAgentID: agents[0].ID,
+			ToAgentID:   agents[1].ID,
+			Type:        types.MessageTypeTask,
+			Payload:     map[string]any{"seq": i},
+			Timestamp:   time.Now(),
+		}
+		if err := h.messaging.PublishMessage(ctx, "messages", msg); err != nil {
+			t.Fatalf("Failed to publish message: %v", err)
+		}
+	}
+
+	edgeID := types.NewEdgeID(agents[0].ID, agents[1].ID)
+	expected := h.cfg.InitialEdgeWeight + messageCount*h.cfg.ReinforcementAmount
+
+	// Snapshots are written on a 5s ticker; wait for one that reflects every message
+	eventually(t, 30*time.Second, "reinforced snapshot", func() error {
+		snapshot, err := h.store.LoadGraphSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		if len(snapshot.Agents) != len(agents) {
+			return fmt.Errorf("snapshot has %d agents", len(snapshot.Agents))
+		}
+		edge, ok := snapshot.Edges[edgeID]
+		if !ok {
+			return fmt.Errorf("snapshot has no edge %s", edgeID)
+		}
+		if edge.Weight < expected-1e-9 {
+			return fmt.Errorf("edge weight %f, want %f", edge.Weight, expected)
+		}
+		return nil
+	})
+
+	// Untouched edges keep their initial weight
+	snapshot, _ := h.store.LoadGraphSnapshot(ctx)
+	reverse := snapshot.Edges[types.NewEdgeID(agents[1].ID, agents[0].ID)]
+	if reverse == nil || reverse.Weight != h.cfg.InitialEdgeWeight {
+		t.Errorf("Expected reverse edge at initial weight %f, got %+v", h.cfg.InitialEdgeWeight, reverse)
+	}
+
+	messages, err := h.store.ListMessages(ctx, time.Now().Add(-time.Hour), time.Now(), 0)
+	if err != nil {
+		t.Fatalf("Failed to list message history: %v", err)
+	}
+	if len(messages) != messageCount {
+		t.Errorf("Expected %d messages in history, got %d", messageCount, len(messages))
+	}
+}
+
+func TestIntegrationTopologyWarmRestart(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tm := manager.NewTopologyManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := tm.Start(ctx); err != nil {
+		t.Fatalf("Failed to start topology manager: %v", err)
+	}
+
+	agents := h.joinAgents(t, ctx, 2)
+	msg := &types.Message{
+		ID:          uuid.New().String(),
+		FromAgentID: agents[0].ID,
+		ToAgentID:   agents[1].ID,
+		Type:        types.MessageTypeTask,
+		Timestamp:   time.Now(),
+	}
+	if err := h.messaging.PublishMessage(ctx, "messages", msg); err != nil {
+		t.Fatalf("Failed to publish message: %v", err)
+	}
+
+	edgeID := types.NewEdgeID(agents[0].ID, agents[1].ID)
+	eventually(t, 30*time.Second, "reinforced snapshot", func() error {
+		snapshot, err := h.store.LoadGraphSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		if edge, ok := snapshot.Edges[edgeID]; !ok || edge.Usage == 0 {
+			return fmt.Errorf("snapshot has no reinforced edge %s", edgeID)
+		}
+		return nil
+	})
+	tm.Stop()
+
+	saved, err := h.store.LoadGraphSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load snapshot: %v", err)
+	}
+
+	// A new instance starts from the learned graph instead of an empty one
+	restarted := manager.NewTopologyManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := restarted.Start(ctx); err != nil {
+		t.Fatalf("Failed to restart topology manager: %v", err)
+	}
+	defer restarted.Stop()
+
+	graph := restarted.SlimeMold().GetGraph()
+	if n := graph.GetAgentCount(); n != len(agents) {
+		t.Errorf("Expected %d agents restored, got %d", len(agents), n)
+	}
+	edge, err := graph.GetEdge(edgeID)
+	if err != nil {
+		t.Fatalf("Expected edge %s restored: %v", edgeID, err)
+	}
+	if edge.Usage != saved.Edges[edgeID].Usage || edge.GetWeight() > saved.Edges[edgeID].Weight+1e-9 {
+		t.Errorf("Expected the saved edge back, got usage %d weight %f, saved %+v", edge.Usage, edge.GetWeight(), saved.Edges[edgeID])
+	}
+}
+
+func TestIntegrationProposalVotesAcceptance(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	cm := manager.NewConsensusManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := cm.Start(ctx); err != nil {
+		t.Fatalf("Failed to start consensus manager: %v", err)
+	}
+	defer cm.Stop()
+
+	agents := h.joinAgents(t, ctx, 5)
+	eventually(t, 30*time.Second, "agents to register", func() error {
+		if n := cm.Consensus().GetAgentCount(); n != len(agents) {
+			return fmt.Errorf("have %d agents", n)
+		}
+		return nil
+	})
+
+	proposalMsg := &types.Message{
+		ID:          uuid.New().String(),
+		FromAgentID: agents[0].ID,
+		Type:        types.MessageTypeWaggle,
+		Payload: map[string]any{
+			"proposal": map[string]any{
+				"proposer_id": string(agents[0].ID),
+				"type":        string(types.ProposalTypeDecision),
+				"content":     map[string]any{"action": "scale_up"},
+			},
+		},
+		Timestamp: time.Now(),
+	}
+	if err := h.messaging.PublishMessage(ctx, "proposals", proposalMsg); err != nil {
+		t.Fatalf("Failed to publish proposal: %v", err)
+	}
+
+	var proposalID types.ProposalID
+	eventually(t, 30*time.Second, "proposal to be created", func() error {
+		pending := cm.Consensus().GetPendingProposals()
+		if len(pending) != 1 {
+			return fmt.Errorf("have %d pending proposals", len(pending))
+		}
+		proposalID = pending[0].ID
+		return nil
+	})
+
+	// 3 of 5 supporting votes meets the 0.6 quorum threshold
+	for _, voter := range agents[:3] {
+		voteMsg := &types.Message{
+			ID:          uuid.New().String(),
+			FromAgentID: voter.ID,
+			Type:        types.MessageTypeVote,
+			Payload: map[string]any{
+				"vote": map[string]any{
+					"proposal_id": string(proposalID),
+					"voter_id":    string(voter.ID),
+					"support":     true,
+					"intensity":   0.9,
+				},
+			},
+			Timestamp: time.Now(),
+		}
+		if err := h.messaging.PublishMessage(ctx, "votes", voteMsg); err != nil {
+			t.Fatalf("Failed to publish vote: %v", err)
+		}
+	}
+
+	eventually(t, 30*time.Second, "proposal acceptance in Redis", func() error {
+		proposal, err := h.store.LoadProposal(ctx, proposalID)
+		if err != nil {
+			return err
+		}
+		if proposal.Status != types.ProposalStatusAccepted {
+			return fmt.Errorf("status %s", proposal.Status)
+		}
+		if len(proposal.Votes) != 3 {
+			return fmt.Errorf("%d votes recorded", len(proposal.Votes))
+		}
+		return nil
+	})
+}
+
+func TestIntegrationInsightCollection(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	km := manager.NewKnowledgeManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := km.Start(ctx); err != nil {
+		t.Fatalf("Failed to start knowledge manager: %v", err)
+	}
+
+	insight := &types.Insight{
+		ID:         types.InsightID(uuid.New().String()),
+		AgentID:    types.NewAgentID(),
+		AgentRole:  "test",
+		Type:       types.InsightTypeCorrelation,
+		Topic:      "integration",
+		Content:    "Kafka round trip works",
+		Confidence: 0.9,
+		CreatedAt:  time.Now(),
+	}
+	if err := h.messaging.PublishInsight(ctx, insight); err != nil {
+		t.Fatalf("Failed to publish insight: %v", err)
+	}
+
+	eventually(t, 30*time.Second, "insight to be indexed", func() error {
+		result := km.QueryInsights(types.KnowledgeQuery{Topics: []string{"integration"}})
+		if result.Count != 1 {
+			return fmt.Errorf("query returned %d insights", result.Count)
+		}
+		return nil
+	})
+
+	// Stop flushes the knowledge base to Redis
+	km.Stop()
+
+	var stored types.Insight
+	if err := h.store.Get(ctx, "insight:"+string(insight.ID), &stored); err != nil {
+		t.Fatalf("Failed to load persisted insight: %v", err)
+	}
+	if stored.Content != insight.Content {
+		t.Errorf("Expected persisted content %q, got %q", insight.Content, stored.Content)
+	}
+}
+
+func TestIntegrationInsightCompaction(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	km := manager.NewKnowledgeManager(h.messaging, h.store, h.cfg, h.logger)
+	if err := km.Start(ctx); err != nil {
+		t.Fatalf("Failed to start knowledge manager: %v", err)
+	}
+
+	insight := types.NewInsight(types.NewAgentID(), "test", types.InsightTypeCorrelation, "compaction", "Survives a restart", 0.9)
+	if err := h.messaging.PublishInsight(ctx, insight); err != nil {
+		t.Fatalf("Failed to publish insight: %v", err)
+	}
+	eventually(t, 30*time.Second, "insight to be indexed", func() error {
+		if result := km.QueryInsights(types.KnowledgeQuery{Topics: []string{"compaction"}}); result.Count != 1 {
+			return fmt.Errorf("query returned %d insights", result.Count)
+		}
+		return nil
+	})
+
+	// Stop publishes the knowledge base to the compacted topic
+	km.Stop()
+
+	// A replica skipping the insight backlog bootstraps from the compacted topic
+	replicaConfig := *h.cfg
+	replicaConfig.KnowledgeStartMode = types.StartModeLatest
+	replica := manager.NewKnowledgeManager(h.messaging, h.store, &replicaConfig, h.logger)
+	if err := replica.Start(ctx); err != nil {
+		t.Fatalf("Failed to start replica: %v", err)
+	}
+	defer replica.Stop()
+
+	if result := replica.QueryInsights(types.KnowledgeQuery{Topics: []string{"compaction"}}); result.Count != 1 {
+		t.Errorf("Expected the replica to bootstrap 1 insight, got %d", result.Count)
+	}
+}
+
+func TestIntegrationTopologyHistory(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		snapshot := &types.GraphSnapshot{
+			Timestamp: base.Add(time.Duration(i) * 10 * time.Minute),
+			Sequence:  uint64(i + 1),
+			Agents:    map[types.AgentID]*types.Agent{},
+			Edges:     map[types.EdgeID]*types.Edge{},
+			Stats:     types.GraphStats{TotalAgents: i + 1},
+		}
+		if err := h.store.SaveGraphSnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("Failed to save snapshot: %v", err)
+		}
+	}
+
+	snapshots, err := h.store.ListSnapshots(ctx, base.Add(5*time.Minute), base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Sequence != 2 || snapshots[1].Sequence != 3 {
+		t.Fatalf("Expected snapshots 2 and 3 in range, got %d", len(snapshots))
+	}
+
+	at, err := h.store.SnapshotAt(ctx, base.Add(15*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to load snapshot: %v", err)
+	}
+	if at.Sequence != 2 {
+		t.Errorf("Expected snapshot 2 as of 15 minutes in, got %d", at.Sequence)
+	}
+
+	if _, err := h.store.SnapshotAt(ctx, base.Add(-time.Minute)); !errors.Is(err, state.ErrNoSnapshot) {
+		t.Errorf("Expected ErrNoSnapshot before the first snapshot, got %v", err)
+	}
+}
+
+// TestIntegrationTopologyHistoryDeltas checks history rebuilds the topology
+// between checkpoints from the deltas saved since
+func TestIntegrationTopologyHistoryDeltas(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	topology := func(sequence uint64, minutes int, ids ...types.AgentID) *types.GraphSnapshot {
+		agents := map[types.AgentID]*types.Agent{}
+		for _, id := range ids {
+			agents[id] = &types.Agent{ID: id}
+		}
+		return &types.GraphSnapshot{
+			Timestamp: base.Add(time.Duration(minutes) * time.Minute),
+			Sequence:  sequence,
+			Agents:    agents,
+			Edges:     map[types.EdgeID]*types.Edge{},
+			Stats:     types.GraphStats{TotalAgents: len(ids)},
+		}
+	}
+	first := topology(1, 0, "agent-a")
+	second := topology(2, 1, "agent-a", "agent-b")
+	third := topology(3, 2, "agent-b", "agent-c")
+	fourth := topology(4, 3, "agent-c")
+	afterGap := topology(6, 4, "agent-d")
+
+	if err := h.store.SaveGraphSnapshot(ctx, first); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	for _, delta := range []*types.SnapshotDelta{types.NewSnapshotDelta(first, second), types.NewSnapshotDelta(second, third)} {
+		if err := h.store.SaveSnapshotDelta(ctx, delta); err != nil {
+			t.Fatalf("Failed to save delta: %v", err)
+		}
+	}
+	if err := h.store.SaveGraphSnapshot(ctx, fourth); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if err := h.store.SaveSnapshotDelta(ctx, types.NewSnapshotDelta(topology(5, 4, "agent-c"), afterGap)); err != nil {
+		t.Fatalf("Failed to save delta: %v", err)
+	}
+
+	agentIDs := func(snapshot *types.GraphSnapshot) []types.AgentID {
+		ids := []types.AgentID{}
+		for id := range snapshot.Agents {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+
+	for _, tt := range []struct {
+		minutes  float64
+		sequence uint64
+		agents   []types.AgentID
+	}{
+		{0.5, 1, []types.AgentID{"agent-a"}},
+		{1.5, 2, []types.AgentID{"agent-a", "agent-b"}},
+		{2.5, 3, []types.AgentID{"agent-b", "agent-c"}},
+		{5, 4, []types.AgentID{"agent-c"}}, // The delta after the gap does not apply
+	} {
+		at, err := h.store.SnapshotAt(ctx, base.Add(time.Duration(tt.minutes*float64(time.Minute))))
+		if err != nil {
+			t.Fatalf("Failed to load snapshot: %v", err)
+		}
+		if at.Sequence != tt.sequence || !reflect.DeepEqual(agentIDs(at), tt.agents) {
+			t.Errorf("Expected snapshot %d with %v after %v minutes, got %d with %v",
+				tt.sequence, tt.agents, tt.minutes, at.Sequence, agentIDs(at))
+		}
+	}
+
+	// Starting after the checkpoint, so the first delta in range applies to it
+	snapshots, err := h.store.ListSnapshots(ctx, base.Add(30*time.Second), base.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	sequences := []uint64{}
+	for _, snapshot := range snapshots {
+		sequences = append(sequences, snapshot.Sequence)
+	}
+	if !reflect.DeepEqual(sequences, []uint64{2, 3, 4}) {
+		t.Fatalf("Expected snapshots 2 to 4, got %v", sequences)
+	}
+	if got := agentIDs(snapshots[0]); !reflect.DeepEqual(got, []types.AgentID{"agent-a", "agent-b"}) {
+		t.Errorf("Expected snapshot 2 kept as it was, got %v", got)
+	}
+	if got := agentIDs(snapshots[1]); !reflect.DeepEqual(got, []types.AgentID{"agent-b", "agent-c"}) {
+		t.Errorf("Expected snapshot 3 rebuilt, got %v", got)
+	}
+
+	// Replays check the size of a window before loading it
+	for _, minutes := range []int{1, 2, 6} {
+		if err := h.store.SaveMessage(ctx, &types.Message{ID: uuid.New().String(), Timestamp: base.Add(time.Duration(minutes) * time.Minute)}); err != nil {
+			t.Fatalf("Failed to save message: %v", err)
+		}
+	}
+	counted, messages, err := h.store.CountHistory(ctx, base.Add(30*time.Second), base.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to count history: %v", err)
+	}
+	if counted != 4 || messages != 2 {
+		t.Errorf("Expected 1 checkpoint and 3 deltas, and 2 messages, got %d snapshots and %d messages", counted, messages)
+	}
+}
+
+func TestIntegrationConcurrentConsumerKeepsConversationOrder(t *testing.T) {
+	h := newMesh(t)
+	h.cfg.ConsumerWorkers = 4
+	h.cfg.ConsumerHandlerTimeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	const conversations, perConversation = 4, 10
+	for seq := 0; seq < perConversation; seq++ {
+		for c := 0; c < conversations; c++ {
+			msg := &types.Message{
+				ID:             uuid.New().String(),
+				FromAgentID:    "agent-sender",
+				ConversationID: fmt.Sprintf("conv-%d", c),
+				Type:           types.MessageTypeTask,
+				Payload:        map[string]any{"seq": seq},
+				Timestamp:      time.Now(),
+			}
+			if err := h.messaging.PublishMessage(ctx, "messages", msg); err != nil {
+				t.Fatalf("Failed to publish message: %v", err)
+			}
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		handled = map[string][]int{}
+		total   int
+	)
+	consumeCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.messaging.ConsumeMessages(consumeCtx, "messages", "it-concurrent", func(ctx context.Context, msg *types.Message) error {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			handled[msg.ConversationID] = append(handled[msg.ConversationID], int(msg.Payload["seq"].(float64)))
+			total++
+			return nil
+		})
+	}()
+
+	eventually(t, 30*time.Second, "messages to be handled", func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if total != conversations*perConversation {
+			return fmt.Errorf("handled %d messages", total)
+		}
+		return nil
+	})
+	stop()
+	<-done
+
+	for conversation, seqs := range handled {
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("Expected %s handled in order, got %v", conversation, seqs)
+			}
+		}
+	}
+}
+
+func TestIntegrationHandlerContextDeadline(t *testing.T) {
+	h := newMesh(t)
+	h.cfg.ConsumerHandlerTimeout = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	msg := &types.Message{ID: uuid.New().String(), FromAgentID: "agent-sender", Type: types.MessageTypeTask, Timestamp: time.Now()}
+	if err := h.messaging.PublishMessage(ctx, "messages", msg); err != nil {
+		t.Fatalf("Failed to publish message: %v", err)
+	}
+
+	consumeCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	ended := make(chan error, 1)
+	go h.messaging.ConsumeMessages(consumeCtx, "messages", "it-deadline", func(ctx context.Context, msg *types.Message) error {
+		<-ctx.Done()
+		ended <- ctx.Err()
+		return ctx.Err()
+	})
+
+	select {
+	case err := <-ended:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the handler context to time out, got %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("Timed out waiting for the handler context to end")
+	}
+	eventually(t, 5*time.Second, "cancellation to be counted", func() error {
+		if n := h.messaging.HandlerCancellations()["messages"]; n != 1 {
+			return fmt.Errorf("counted %d cancellations", n)
+		}
+		return nil
+	})
+}
+
+func TestIntegrationBackupRoundTrip(t *testing.T) {
+	h := newMesh(t)
+	rdb := h.redisClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, "backup:string", "original", 10*time.Minute)
+	pipe.Set(ctx, "backup:persistent", "forever", 0)
+	pipe.SAdd(ctx, "backup:set", "a", "b")
+	pipe.RPush(ctx, "backup:list", "x", "y", "z")
+	pipe.PExpire(ctx, "backup:list", 5*time.Minute)
+	pipe.HSet(ctx, "backup:hash", "field", "value")
+	pipe.ZAdd(ctx, "backup:zset", redis.Z{Member: "low", Score: 1}, redis.Z{Member: "high", Score: 2})
+	pipe.Set(ctx, "other:key", "excluded", 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("Failed to seed keys: %v", err)
+	}
+
+	dumps, err := h.store.DumpKeys(ctx, "backup:*")
+	if err != nil {
+		t.Fatalf("DumpKeys failed: %v", err)
+	}
+	if len(dumps) != 6 {
+		t.Fatalf("Expected 6 keys dumped, got %d", len(dumps))
+	}
+	for _, dump := range dumps {
+		switch dump.Key {
+		case "backup:string":
+			if dump.TTLMs <= 0 || dump.TTLMs > (10*time.Minute).Milliseconds() {
+				t.Errorf("Expected a TTL of up to 10 minutes dumped, got %dms", dump.TTLMs)
+			}
+		case "backup:persistent":
+			if dump.TTLMs != 0 {
+				t.Errorf("Expected no TTL dumped for a persistent key, got %dms", dump.TTLMs)
+			}
+		}
+	}
+
+	// Restore into a database where only the string key exists, changed
+	if err := rdb.FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	rdb.Set(ctx, "backup:string", "changed", 0)
+
+	restored, skipped, err := h.store.RestoreKeys(ctx, dumps, false)
+	if err != nil {
+		t.Fatalf("RestoreKeys failed: %v", err)
+	}
+	if restored != 5 || skipped != 1 {
+		t.Errorf("Expected 5 keys restored and 1 skipped, got %d and %d", restored, skipped)
+	}
+	if value := rdb.Get(ctx, "backup:string").Val(); value != "changed" {
+		t.Errorf("Expected the existing key kept without overwrite, got %q", value)
+	}
+	if list := rdb.LRange(ctx, "backup:list", 0, -1).Val(); len(list) != 3 || list[0] != "x" || list[2] != "z" {
+		t.Errorf("Expected list x, y, z restored in order, got %v", list)
+	}
+	if ttl := rdb.PTTL(ctx, "backup:list").Val(); ttl <= 0 || ttl > 5*time.Minute {
+		t.Errorf("Expected the list's TTL restored, got %v", ttl)
+	}
+	if ttl := rdb.PTTL(ctx, "backup:persistent").Val(); ttl != -1 {
+		t.Errorf("Expected the persistent key restored without TTL, got %v", ttl)
+	}
+	if members := rdb.SMembers(ctx, "backup:set").Val(); len(members) != 2 {
+		t.Errorf("Expected 2 set members restored, got %v", members)
+	}
+	if value := rdb.HGet(ctx, "backup:hash", "field").Val(); value != "value" {
+		t.Errorf("Expected the hash field restored, got %q", value)
+	}
+	if score := rdb.ZScore(ctx, "backup:zset", "high").Val(); score != 2 {
+		t.Errorf("Expected the sorted set score restored, got %v", score)
+	}
+	if rdb.Exists(ctx, "other:key").Val() != 0 {
+		t.Error("Expected keys outside the pattern left out of the backup")
+	}
+
+	restored, skipped, err = h.store.RestoreKeys(ctx, dumps, true)
+	if err != nil {
+		t.Fatalf("RestoreKeys with overwrite failed: %v", err)
+	}
+	if restored != 6 || skipped != 0 {
+		t.Errorf("Expected all 6 keys restored with overwrite, got %d and %d skipped", restored, skipped)
+	}
+	if value := rdb.Get(ctx, "backup:string").Val(); value != "original" {
+		t.Errorf("Expected the existing key overwritten, got %q", value)
+	}
+	if ttl := rdb.PTTL(ctx, "backup:string").Val(); ttl <= 0 || ttl > 10*time.Minute {
+		t.Errorf("Expected the overwritten key's TTL restored, got %v", ttl)
+	}
+}
+
+func TestIntegrationMigrateAllDryRun(t *testing.T) {
+	h := newMesh(t)
+	rdb := h.redisClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	legacyAgent := `{"id":"agent-old","name":"Old","metadata":{"framework":"langchain"}}`
+	current, err := state.EncodeRecord("proposal:current", &types.Proposal{ID: "current"})
+	if err != nil {
+		t.Fatalf("EncodeRecord failed: %v", err)
+	}
+	newer := fmt.Sprintf(`{"schema_version":%d,"id":"insight-new"}`, state.CurrentVersion(state.SchemaInsight)+1)
+
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, "agent:agent-old", legacyAgent, time.Hour)
+	pipe.HSet(ctx, "agent:agent-old:state", "status", "active") // Not a record
+	pipe.Set(ctx, "insight:insight-old", `{"id":"insight-old","content":"Legacy"}`, 0)
+	pipe.Set(ctx, "insight:insight-new", newer, 0)
+	pipe.Set(ctx, "proposal:current", current, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("Failed to seed records: %v", err)
+	}
+
+	reportsBySchema := func(dryRun bool) map[state.Schema]state.MigrationReport {
+		t.Helper()
+		reports, err := h.store.MigrateAll(ctx, dryRun)
+		if err != nil {
+			t.Fatalf("MigrateAll failed: %v", err)
+		}
+		bySchema := map[state.Schema]state.MigrationReport{}
+		for _, r := range reports {
+			bySchema[r.Schema] = r
+		}
+		return bySchema
+	}
+
+	reports := reportsBySchema(true)
+	if r := reports[state.SchemaAgent]; r.Scanned != 1 || r.Migrated != 1 || r.Failed != 0 {
+		t.Errorf("Expected 1 stale agent, got %+v", r)
+	}
+	if r := reports[state.SchemaInsight]; r.Scanned != 2 || r.Migrated != 1 || r.Failed != 1 {
+		t.Errorf("Expected 1 stale and 1 newer insight, got %+v", r)
+	}
+	if r := reports[state.SchemaProposal]; r.Scanned != 1 || r.Migrated != 0 {
+		t.Errorf("Expected the current proposal left alone, got %+v", r)
+	}
+	if value := rdb.Get(ctx, "agent:agent-old").Val(); value != legacyAgent {
+		t.Errorf("Expected a dry run to leave records unchanged, got %s", value)
+	}
+
+	reports = reportsBySchema(false)
+	if r := reports[state.SchemaAgent]; r.Migrated != 1 {
+		t.Errorf("Expected the agent migrated, got %+v", r)
+	}
+	var migrated map[string]any
+	json.Unmarshal([]byte(rdb.Get(ctx, "agent:agent-old").Val()), &migrated)
+	if migrated["schema_version"] != float64(state.CurrentVersion(state.SchemaAgent)) || migrated["framework"] != "langchain" {
+		t.Errorf("Expected the agent rewritten at the current version, got %v", migrated)
+	}
+	if ttl := rdb.TTL(ctx, "agent:agent-old").Val(); ttl <= 0 {
+		t.Errorf("Expected the TTL kept, got %v", ttl)
+	}
+	if value := rdb.Get(ctx, "insight:insight-new").Val(); value != newer {
+		t.Errorf("Expected the newer insight left alone, got %s", value)
+	}
+
+	for schema, r := range reportsBySchema(true) {
+		if r.Migrated != 0 {
+			t.Errorf("Expected no stale %s records after migrating, got %d", schema, r.Migrated)
+		}
+	}
+}
+
+// TestIntegrationListInsights checks insights are read back across SCAN
+// batches, skipping unreadable records
+func TestIntegrationListInsights(t *testing.T) {
+	h := newMesh(t)
+	rdb := h.redisClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const count = 1200 // More than two batches
+	for i := 0; i < count; i++ {
+		insight := types.NewInsight("agent-1", "research", types.InsightTypeCorrelation, "pricing", "Batch", 0.5)
+		insight.ID = types.InsightID(fmt.Sprintf("insight-%04d", i))
+		if err := h.store.Set(ctx, "insight:"+string(insight.ID), insight, time.Hour); err != nil {
+			t.Fatalf("Failed to save insight: %v", err)
+		}
+	}
+	rdb.Set(ctx, "insight:broken", "not json", time.Hour)
+
+	insights, err := h.store.ListInsights(ctx)
+	if err != nil {
+		t.Fatalf("ListInsights failed: %v", err)
+	}
+	if len(insights) != count {
+		t.Fatalf("Expected %d insights, got %d", count, len(insights))
+	}
+	seen := map[types.InsightID]bool{}
+	for _, insight := range insights {
+		seen[insight.ID] = true
+	}
+	if len(seen) != count {
+		t.Errorf("Expected %d distinct insights, got %d", count, len(seen))
+	}
+}
+
+// TestIntegrationQueryStoredInsights checks the API server's insight query
+// filters, ranks and limits what the knowledge manager persisted
+func TestIntegrationQueryStoredInsights(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	save := func(id, topic string, confidence float64, privacy types.InsightPrivacy) {
+		t.Helper()
+		insight := types.NewInsight("agent-1", "research", types.InsightTypeCorrelation, topic, id, confidence)
+		insight.ID = types.InsightID(id)
+		insight.Privacy = privacy
+		if err := h.store.Set(ctx, "insight:"+id, insight, time.Hour); err != nil {
+			t.Fatalf("Failed to save insight: %v", err)
+		}
+	}
+	save("strong", "pricing", 0.9, types.InsightPrivacyPublic)
+	save("weak", "pricing", 0.2, types.InsightPrivacyPublic)
+	save("other", "payments", 0.8, types.InsightPrivacyPublic)
+	save("secret", "pricing", 0.95, types.InsightPrivacyPrivate)
+
+	ids := func(insights []types.Insight) []string {
+		out := []string{}
+		for _, insight := range insights {
+			out = append(out, string(insight.ID))
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	query := types.KnowledgeQuery{Topics: []string{"pricing"}, MinConfidence: 0.5}
+	anonymous, err := manager.QueryStoredInsights(ctx, h.store, h.cfg, query, nil)
+	if err != nil {
+		t.Fatalf("QueryStoredInsights failed: %v", err)
+	}
+	if got := ids(anonymous); !reflect.DeepEqual(got, []string{"secret", "strong"}) {
+		t.Errorf("Expected the confident pricing insights, got %v", got)
+	}
+
+	reader := &types.Agent{ID: "agent-2", Role: "research"}
+	visible, err := manager.QueryStoredInsights(ctx, h.store, h.cfg, query, reader)
+	if err != nil {
+		t.Fatalf("QueryStoredInsights failed: %v", err)
+	}
+	if got := ids(visible); !reflect.DeepEqual(got, []string{"strong"}) {
+		t.Errorf("Expected the private insight hidden from another agent, got %v", got)
+	}
+
+	limited, err := manager.QueryStoredInsights(ctx, h.store, h.cfg, types.KnowledgeQuery{Limit: 2}, nil)
+	if err != nil {
+		t.Fatalf("QueryStoredInsights failed: %v", err)
+	}
+	if len(limited) != 2 {
+		t.Errorf("Expected the limit applied, got %d insights", len(limited))
+	}
+}
+
+// TestIntegrationSnapshotAgents checks the API server's agent list comes from
+// the latest snapshot, by namespace and in ID order
+func TestIntegrationSnapshotAgents(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := h.store.SnapshotAgents(ctx, ""); !errors.Is(err, state.ErrNoSnapshot) {
+		t.Fatalf("Expected ErrNoSnapshot before any snapshot, got %v", err)
+	}
+
+	snapshot := &types.GraphSnapshot{
+		Agents: map[types.AgentID]*types.Agent{
+			"agent-c": {ID: "agent-c"},
+			"agent-a": {ID: "agent-a", Namespace: "sales"},
+			"agent-b": {ID: "agent-b"},
+		},
+		Edges:     map[types.EdgeID]*types.Edge{},
+		Timestamp: time.Now(),
+	}
+	if err := h.store.SaveGraphSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("SaveGraphSnapshot failed: %v", err)
+	}
+
+	agentIDs := func(namespace string) []types.AgentID {
+		t.Helper()
+		agents, err := h.store.SnapshotAgents(ctx, namespace)
+		if err != nil {
+			t.Fatalf("SnapshotAgents failed: %v", err)
+		}
+		out := []types.AgentID{}
+		for _, agent := range agents {
+			out = append(out, agent.ID)
+		}
+		return out
+	}
+	if got := agentIDs(""); !reflect.DeepEqual(got, []types.AgentID{"agent-a", "agent-b", "agent-c"}) {
+		t.Errorf("Expected all agents in ID order, got %v", got)
+	}
+	if got := agentIDs(types.DefaultNamespace); !reflect.DeepEqual(got, []types.AgentID{"agent-b", "agent-c"}) {
+		t.Errorf("Expected the default namespace's agents, got %v", got)
+	}
+	if got := agentIDs("sales"); !reflect.DeepEqual(got, []types.AgentID{"agent-a"}) {
+		t.Errorf("Expected the sales agent, got %v", got)
+	}
+}
+
+// TestIntegrationFanout checks a frame published by one web server replica
+// reaches the clients of every replica over the shared channel
+func TestIntegrationFanout(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	replica := func() (*state.Fanout, chan any) {
+		frames := make(chan any, 100)
+		fanout := state.NewFanout(h.store, "web:broadcast", func(frame any) { frames <- frame }, h.logger)
+		go fanout.Relay(ctx)
+		return fanout, frames
+	}
+	first, firstFrames := replica()
+	_, secondFrames := replica()
+
+	// receive waits for a frame of the given type, skipping earlier ones
+	receive := func(frames chan any, frameType string) (map[string]any, error) {
+		for {
+			select {
+			case frame := <-frames:
+				raw, ok := frame.(json.RawMessage)
+				if !ok {
+					return nil, fmt.Errorf("expected a relayed frame, got %T", frame)
+				}
+				var decoded map[string]any
+				if err := json.Unmarshal(raw, &decoded); err != nil {
+					return nil, err
+				}
+				if decoded["type"] == frameType {
+					return decoded, nil
+				}
+			case <-time.After(500 * time.Millisecond):
+				return nil, fmt.Errorf("no %s frame", frameType)
+			}
+		}
+	}
+
+	// Subscriptions start asynchronously, so ping until both replicas relay
+	eventually(t, 10*time.Second, "both replicas subscribed", func() error {
+		first.Publish(ctx, map[string]any{"type": "ping"})
+		if _, err := receive(firstFrames, "ping"); err != nil {
+			return err
+		}
+		_, err := receive(secondFrames, "ping")
+		return err
+	})
+
+	first.Publish(ctx, map[string]any{"type": "agent_joined", "agent_id": "agent-1"})
+	for name, frames := range map[string]chan any{"publishing": firstFrames, "other": secondFrames} {
+		frame, err := receive(frames, "agent_joined")
+		if err != nil {
+			t.Errorf("The %s replica: %v", name, err)
+		} else if frame["agent_id"] != "agent-1" {
+			t.Errorf("The %s replica got %v", name, frame)
+		}
+	}
+}
+
+// redisProxy forwards connections to Redis and can cut them, to simulate an outage
+type redisProxy struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	down  bool
+	conns []net.Conn
+}
+
+// newRedisProxy starts a proxy to the test Redis, closed with the test
+func newRedisProxy(t *testing.T) *redisProxy {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	p := &redisProxy{listener: listener}
+	go func() {
+		for {
+			client, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			p.mu.Lock()
+			server, err := net.Dial("tcp", redisAddr)
+			if p.down || err != nil {
+				client.Close()
+				p.mu.Unlock()
+				continue
+			}
+			p.conns = append(p.conns, client, server)
+			p.mu.Unlock()
+
+			go func() { io.Copy(server, client); server.Close() }()
+			go func() { io.Copy(client, server); client.Close() }()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		p.setDown(true)
+	})
+	return p
+}
+
+// setDown cuts the open connections and refuses new ones, or accepts them again
+func (p *redisProxy) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.down = down
+	if down {
+		for _, conn := range p.conns {
+			conn.Close()
+		}
+		p.conns = nil
+	}
+}
+
+// TestIntegrationFanoutResubscribes checks a replica starting while Redis is
+// unreachable delivers its frames locally, then relays them once Redis is back
+func TestIntegrationFanoutResubscribes(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	proxy := newRedisProxy(t)
+	cfg := *h.cfg
+	cfg.RedisAddr = proxy.listener.Addr().String()
+	store, err := state.NewRedisStore(&cfg, h.logger)
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis through the proxy: %v", err)
+	}
+	defer store.Close()
+
+	proxy.setDown(true)
+	frames := make(chan any, 100)
+	fanout := state.NewFanout(store, "web:broadcast", func(frame any) { frames <- frame }, h.logger)
+	go fanout.Relay(ctx)
+
+	// next returns the next frame and whether it came through Redis
+	next := func(wait time.Duration) (any, bool, error) {
+		select {
+		case frame := <-frames:
+			_, relayed := frame.(json.RawMessage)
+			return frame, relayed, nil
+		case <-time.After(wait):
+			return nil, false, errors.New("no frame")
+		}
+	}
+
+	fanout.Publish(ctx, map[string]any{"type": "while_down"})
+	if frame, relayed, err := next(10 * time.Second); err != nil || relayed {
+		t.Fatalf("Expected the frame delivered locally while Redis is down, got %v (%v)", frame, err)
+	}
+
+	proxy.setDown(false)
+	eventually(t, 30*time.Second, "frames relayed through Redis again", func() error {
+		fanout.Publish(ctx, map[string]any{"type": "ping"})
+		if _, relayed, err := next(500 * time.Millisecond); err != nil {
+			return err
+		} else if !relayed {
+			return errors.New("frame delivered locally")
+		}
+		return nil
+	})
+
+	// Subscribed again, a frame arrives once, through Redis
+	for len(frames) > 0 {
+		<-frames
+	}
+	fanout.Publish(ctx, map[string]any{"type": "agent_joined"})
+	if frame, relayed, err := next(5 * time.Second); err != nil || !relayed {
+		t.Fatalf("Expected the frame relayed, got %v (%v)", frame, err)
+	}
+	if frame, _, err := next(300 * time.Millisecond); err == nil {
+		t.Errorf("Expected the frame delivered once, also got %v", frame)
+	}
+}
+
+// TestIntegrationMessageOrderingKey checks messages are keyed by conversation,
+// or outside one by sender, so each key stays on one partition
+func TestIntegrationMessageOrderingKey(t *testing.T) {
+	h := newMesh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	topic := h.cfg.KafkaTopicPrefix + ".partitioned"
+	conn, err := kafka.Dial("tcp", h.cfg.KafkaBrokers[0])
+	if err != nil {
+		t.Fatalf("Failed to dial Kafka: %v", err)
+	}
+	defer conn.Close()
+	controller, err := conn.Controller()
+	if err != nil {
+		t.Fatalf("Failed to find Kafka controller: %v", err)
+	}
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		t.Fatalf("Failed to dial Kafka controller: %v", err)
+	}
+	defer controllerConn.Close()
+	if err := controllerConn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 4, ReplicationFactor: 1}); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+
+	const perKey = 10
+	sent := 0
+	for i := 0; i < perKey; i++ {
+		for _, msg := range []*types.Message{
+			{ID: fmt.Sprintf("a-%d", i), FromAgentID: "agent-1", ConversationID: "order-1"},
+			{ID: fmt.Sprintf("b-%d", i), FromAgentID: "agent-2", ConversationID: "order-2"},
+			{ID: fmt.Sprintf("c-%d", i), FromAgentID: "agent-3"},
+		} {
+			msg.Type = types.MessageTypeTask
+			msg.Timestamp = time.Now()
+			if err := h.messaging.PublishMessage(ctx, "partitioned", msg); err != nil {
+				t.Fatalf("Failed to publish message: %v", err)
+			}
+			sent++
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     h.cfg.KafkaBrokers,
+		Topic:       topic,
+		GroupID:     "ordering-" + uuid.New().String()[:8],
+		StartOffset: kafka.FirstOffset,
+	})
+	defer reader.Close()
+
+	partitions := map[string]map[int]bool{}
+	order := map[string][]string{}
+	for i := 0; i < sent; i++ {
+		record, err := reader.ReadMessage(ctx)
+		if err != nil {
+			t.Fatalf("Failed to read message %d: %v", i, err)
+		}
+		var msg types.Message
+		if err := json.Unmarshal(record.Value, &msg); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		key := string(record.Key)
+		if key != msg.OrderingKey() {
+			t.Errorf("Expected key %q for message %s, got %q", msg.OrderingKey(), msg.ID, key)
+		}
+		if partitions[key] == nil {
+			partitions[key] = map[int]bool{}
+		}
+		partitions[key][record.Partition] = true
+		order[key] = append(order[key], msg.ID)
+	}
+
+	for key, seen := range partitions {
+		if len(seen) != 1 {
+			t.Errorf("Expected %s on one partition, got %v", key, seen)
+		}
+	}
+	for key, ids := range order {
+		for i, id := range ids {
+			if want := fmt.Sprintf("%s-%d", ids[0][:1], i); id != want {
+				t.Errorf("Expected %s in the order sent, got %v", key, ids)
+				break
+			}
+		}
+	}
+}
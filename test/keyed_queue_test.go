@@ -0,0 +1,146 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+)
+
+func TestKeyedQueueSerialisesKey(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		ran     = map[string][]int{}
+		running = map[string]bool{}
+		active  atomic.Int32
+		peak    atomic.Int32
+	)
+	queue := messaging.NewKeyedQueue(3, 100, 0)
+
+	for seq := 0; seq < 10; seq++ {
+		for k := 0; k < 3; k++ {
+			key, seq := fmt.Sprintf("key-%d", k), seq
+			queue.Submit(key, func() {
+				mu.Lock()
+				if running[key] {
+					t.Errorf("Tasks of %s ran concurrently", key)
+				}
+				running[key] = true
+				mu.Unlock()
+
+				current := active.Add(1)
+				for {
+					if p := peak.Load(); current <= p || peak.CompareAndSwap(p, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+
+				mu.Lock()
+				running[key] = false
+				ran[key] = append(ran[key], seq)
+				mu.Unlock()
+			}, nil)
+		}
+	}
+	queue.Wait()
+
+	for k := 0; k < 3; k++ {
+		if got := ran[fmt.Sprintf("key-%d", k)]; fmt.Sprint(got) != "[0 1 2 3 4 5 6 7 8 9]" {
+			t.Errorf("Expected key-%d's tasks in order, got %v", k, got)
+		}
+	}
+	if peak.Load() < 2 {
+		t.Errorf("Expected different keys to run concurrently, at most %d ran at once", peak.Load())
+	}
+}
+
+func TestKeyedQueueLimitsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	queue := messaging.NewKeyedQueue(2, 3, 0)
+	queue.Submit("key-a", func() { <-release }, nil)
+	queue.Submit("key-a", func() {}, nil) // Waits for key-a, but counts as in flight
+	queue.Submit("key-b", func() { <-release }, nil)
+
+	submitted := make(chan struct{})
+	go func() {
+		queue.Submit("key-c", func() {}, nil)
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("Expected Submit to block while 3 tasks are in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release <- struct{}{} // One task done frees a slot
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Submit to return once a task was done")
+	}
+
+	close(release)
+	queue.Wait()
+}
+
+func TestKeyedQueueTimeoutHoldsKey(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		order = append(order, event)
+		mu.Unlock()
+	}
+
+	release := make(chan struct{})
+	overran := make(chan string, 3)
+	overrun := func(task string) func() {
+		return func() { overran <- task }
+	}
+	queue := messaging.NewKeyedQueue(2, 10, 20*time.Millisecond)
+
+	queue.Submit("slow", func() {
+		<-release
+		record("first returned")
+	}, overrun("first"))
+	queue.Submit("slow", func() { record("second started") }, overrun("second"))
+	other := make(chan struct{})
+	queue.Submit("other", func() { close(other) }, overrun("other"))
+
+	select {
+	case task := <-overran:
+		if task != "first" {
+			t.Fatalf("Expected the blocked task to overrun, got %s", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the overrun callback after the timeout")
+	}
+	select {
+	case <-other:
+	case <-time.After(time.Second):
+		t.Fatal("Expected other keys to keep running")
+	}
+
+	mu.Lock()
+	started := len(order) > 0
+	mu.Unlock()
+	if started {
+		t.Fatalf("Expected the key held while its task runs past the timeout, got %v", order)
+	}
+
+	close(release)
+	queue.Wait()
+	if fmt.Sprint(order) != "[first returned second started]" {
+		t.Errorf("Expected the next task of the key after the overrunning one returned, got %v", order)
+	}
+	if len(overran) != 0 {
+		t.Errorf("Expected only the overrunning task reported, got %s too", <-overran)
+	}
+}
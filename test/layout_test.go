@@ -0,0 +1,128 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TestTopologyLayout checks the layout is deterministic, follows the edges
+// and stays put while the topology does
+func TestTopologyLayout(t *testing.T) {
+	cfg := config.Default()
+	graph := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	for _, id := range []types.AgentID{"sales-1", "sales-2", "billing-1", "billing-2", "fraud-1"} {
+		if err := graph.AddAgent(&types.Agent{ID: id, Status: types.AgentStatusActive}); err != nil {
+			t.Fatalf("AddAgent failed: %v", err)
+		}
+	}
+	for _, pair := range [][2]types.AgentID{{"sales-1", "sales-2"}, {"billing-1", "billing-2"}} {
+		for i := 0; i < 5; i++ {
+			graph.ReinforceEdge(pair[0], pair[1], types.MessageTypeTask)
+		}
+	}
+	snapshot := graph.GetSnapshot()
+
+	distance := func(layout map[types.AgentID]types.LayoutPosition, a, b types.AgentID) float64 {
+		return math.Hypot(layout[a].X-layout[b].X, layout[a].Y-layout[b].Y)
+	}
+
+	layout := topology.NewLayout(cfg.LayoutIterations)
+	first := layout.Update(snapshot)
+	if len(first) != 5 {
+		t.Fatalf("Expected a position per agent, got %d", len(first))
+	}
+	for id, position := range first {
+		if position.X < 0 || position.X > 1 || position.Y < 0 || position.Y > 1 {
+			t.Errorf("Expected %s inside the unit square, got %+v", id, position)
+		}
+	}
+	if distance(first, "sales-1", "sales-2") >= distance(first, "sales-1", "billing-1") {
+		t.Errorf("Expected linked agents closer together: %+v", first)
+	}
+
+	// Every client and every instance computes the same layout
+	again := topology.NewLayout(cfg.LayoutIterations).Update(snapshot)
+	for id, position := range first {
+		if again[id] != position {
+			t.Errorf("Expected the same position for %s, got %+v and %+v", id, position, again[id])
+		}
+	}
+
+	// An unchanged topology barely moves, and settles
+	moved := 0.0
+	for i := 0; i < 20; i++ {
+		next := layout.Update(snapshot)
+		moved = 0
+		for id := range next {
+			moved = math.Max(moved, distance(map[types.AgentID]types.LayoutPosition{"a": first[id], "b": next[id]}, "a", "b"))
+		}
+		first = next
+	}
+	if moved > 0.002 {
+		t.Errorf("Expected the layout to settle, agents still moved %.3f", moved)
+	}
+
+	// A new agent starts by its neighbors and leaves the others near where they were
+	graph.AddAgent(&types.Agent{ID: "sales-3", Status: types.AgentStatusActive})
+	for i := 0; i < 5; i++ {
+		graph.ReinforceEdge("sales-3", "sales-1", types.MessageTypeTask)
+	}
+	graph.RemoveAgent("fraud-1")
+	next := layout.Update(graph.GetSnapshot())
+	if _, ok := next["fraud-1"]; ok || len(next) != 5 {
+		t.Errorf("Expected the removed agent dropped, got %+v", next)
+	}
+	if distance(next, "sales-3", "sales-1") >= distance(next, "sales-3", "billing-1") {
+		t.Errorf("Expected the new agent placed by its neighbor: %+v", next)
+	}
+	if d := distance(map[types.AgentID]types.LayoutPosition{"a": first["billing-1"], "b": next["billing-1"]}, "a", "b"); d > 0.25 {
+		t.Errorf("Expected billing-1 to stay near its place, moved %.3f", d)
+	}
+
+	// Restoring the positions continues the layout elsewhere
+	restored := topology.NewLayout(cfg.LayoutIterations)
+	restored.Restore(next)
+	if d := distance(map[types.AgentID]types.LayoutPosition{"a": next["sales-1"], "b": restored.Update(graph.GetSnapshot())["sales-1"]}, "a", "b"); d > 0.02 {
+		t.Errorf("Expected a restored layout to continue, sales-1 moved %.3f", d)
+	}
+	if topology.NewLayout(0).Update(snapshot) != nil {
+		t.Error("Expected no layout without iterations")
+	}
+}
+
+// TestSnapshotDeltaLayout checks deltas carry the layout only when an agent moved
+func TestSnapshotDeltaLayout(t *testing.T) {
+	base := &types.GraphSnapshot{
+		Agents:   map[types.AgentID]*types.Agent{"a": {ID: "a"}, "b": {ID: "b"}},
+		Edges:    map[types.EdgeID]*types.Edge{},
+		Layout:   map[types.AgentID]types.LayoutPosition{"a": {X: 0.2, Y: 0.3}, "b": {X: 0.7, Y: 0.6}},
+		Sequence: 1,
+	}
+	same := *base
+	same.Sequence = 2
+	if delta := types.NewSnapshotDelta(base, &same); delta.Layout != nil || !delta.Empty() {
+		t.Errorf("Expected no layout in the delta of an unmoved topology, got %+v", delta.Layout)
+	}
+
+	moved := same
+	moved.Layout = map[types.AgentID]types.LayoutPosition{"a": {X: 0.25, Y: 0.3}, "b": {X: 0.7, Y: 0.6}}
+	delta := types.NewSnapshotDelta(base, &moved)
+	if len(delta.Layout) != 2 || delta.Empty() {
+		t.Fatalf("Expected every position in the delta once an agent moved, got %+v", delta.Layout)
+	}
+
+	var reconstructor types.SnapshotReconstructor
+	reconstructor.Checkpoint(base)
+	if err := reconstructor.Apply(delta); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := reconstructor.Snapshot().Layout["a"]; got.X != 0.25 {
+		t.Errorf("Expected the moved position, got %+v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	event := &types.LifecycleEvent{
+		AgentID:   "agent-support-1",
+		State:     types.LifecycleDegraded,
+		Previous:  types.LifecycleReady,
+		Reason:    "CRM API timing out",
+		Timestamp: time.Now(),
+	}
+
+	// Events travel as JSON messages on the lifecycle topic
+	data, err := json.Marshal(event.Message())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg types.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := types.LifecycleEventFrom(&msg)
+	if err != nil || decoded.State != types.LifecycleDegraded || decoded.Previous != types.LifecycleReady || decoded.Reason != event.Reason {
+		t.Fatalf("Expected the event back, got %+v (%v)", decoded, err)
+	}
+
+	msg.Payload["event"].(map[string]any)["state"] = "sleeping"
+	if _, err := types.LifecycleEventFrom(&msg); err == nil {
+		t.Error("Expected an unknown state to be rejected")
+	}
+
+	// The directory records the state; only ready and degraded agents take new work
+	slimeMold := topology.NewSlimeMoldTopology(config.Default(), zap.NewNop())
+	agent := &types.Agent{ID: "agent-support-1", Name: "Support", Role: "support"}
+	if err := slimeMold.AddAgent(agent); err != nil {
+		t.Fatal(err)
+	}
+	if !agent.Routable() {
+		t.Error("Agents that never reported a lifecycle state should stay routable")
+	}
+	graph := slimeMold.GetGraph()
+	if !graph.SetLifecycle(agent.ID, &types.AgentLifecycle{State: decoded.State, Reason: decoded.Reason, Since: decoded.Timestamp}) {
+		t.Fatal("Expected the agent to be in the directory")
+	}
+	if stored, _ := graph.GetAgent(agent.ID); !stored.Routable() || stored.Lifecycle.Reason != event.Reason {
+		t.Errorf("Expected a routable degraded agent, got %+v", stored.Lifecycle)
+	}
+	graph.SetLifecycle(agent.ID, &types.AgentLifecycle{State: types.LifecycleDraining, Since: time.Now()})
+	if stored, _ := graph.GetAgent(agent.ID); stored.Routable() {
+		t.Error("Expected a draining agent to take no new work")
+	}
+	if graph.SetLifecycle("agent-unknown", &types.AgentLifecycle{State: types.LifecycleReady}) {
+		t.Error("Expected unknown agents to be ignored")
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func TestHandlerMiddleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) agent.Middleware {
+		return func(next agent.MessageHandler) agent.MessageHandler {
+			return func(ctx context.Context, msg *types.Message) error {
+				calls = append(calls, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+	ctx := context.Background()
+	handled := 0
+	handler := agent.Chain(func(ctx context.Context, msg *types.Message) error {
+		handled++
+		if msg.Payload["panic"] == true {
+			panic("nil map")
+		}
+		return nil
+	},
+		agent.Recover(zap.NewNop()),
+		trace("outer"),
+		agent.Authorize(agent.AllowSenders("agent-sales-1")),
+		agent.Dedup(2),
+		trace("inner"),
+	)
+
+	msg := &types.Message{ID: "msg-1", FromAgentID: "agent-sales-1", Type: types.MessageTypeTask}
+	if err := handler(ctx, msg); err != nil || handled != 1 {
+		t.Fatalf("Expected the message to be handled, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("Expected the first middleware outermost, got %v", calls)
+	}
+
+	if err := handler(ctx, msg); !errors.Is(err, agent.ErrDuplicate) || handled != 1 {
+		t.Errorf("Expected the redelivery to be dropped, got %v", err)
+	}
+	stranger := &types.Message{ID: "msg-2", FromAgentID: "agent-unknown", Type: types.MessageTypeTask}
+	if err := handler(ctx, stranger); !errors.Is(err, agent.ErrUnauthorized) || handled != 1 {
+		t.Errorf("Expected an unknown sender to be rejected, got %v", err)
+	}
+
+	// A panicking handler fails its message and is not remembered as handled
+	panicking := &types.Message{ID: "msg-3", FromAgentID: "agent-sales-1", Payload: map[string]any{"panic": true}}
+	for i := 0; i < 2; i++ {
+		if err := handler(ctx, panicking); err == nil || errors.Is(err, agent.ErrDuplicate) {
+			t.Errorf("Expected the panic as an error, got %v", err)
+		}
+	}
+	if handled != 3 {
+		t.Errorf("Expected the failed message to be retried, got %d calls", handled)
+	}
+}
+
+func TestDedupKeepsRecentlySeenIDs(t *testing.T) {
+	handled := map[string]int{}
+	handler := agent.Chain(func(ctx context.Context, msg *types.Message) error {
+		handled[msg.ID]++
+		return nil
+	}, agent.Dedup(2))
+
+	deliver := func(id string) error {
+		return handler(context.Background(), &types.Message{ID: id, Type: types.MessageTypeTask})
+	}
+	deliver("msg-a")
+	deliver("msg-b")
+
+	// A redelivery of msg-a keeps it in the window, so msg-c evicts msg-b instead
+	if err := deliver("msg-a"); !errors.Is(err, agent.ErrDuplicate) {
+		t.Fatalf("Expected msg-a to be suppressed, got %v", err)
+	}
+	deliver("msg-c")
+	if err := deliver("msg-a"); !errors.Is(err, agent.ErrDuplicate) {
+		t.Errorf("Expected the recently redelivered msg-a to stay suppressed, got %v", err)
+	}
+	if err := deliver("msg-b"); err != nil || handled["msg-b"] != 2 {
+		t.Errorf("Expected the least recently seen msg-b to be handled again, got %v", err)
+	}
+	if handled["msg-a"] != 1 || handled["msg-c"] != 1 {
+		t.Errorf("Expected msg-a and msg-c handled once, got %v", handled)
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestNamespaceTopology(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	graph := topology.NewGraph(cfg)
+	for _, agent := range []*types.Agent{
+		{ID: "sales-a", Role: "sales", Namespace: "team-a"},
+		{ID: "inventory-a", Role: "inventory", Namespace: "team-a"},
+		{ID: "sales-b", Role: "sales", Namespace: "team-b"},
+		{ID: "inventory-b", Role: "inventory", Namespace: "team-b"},
+		{ID: "support-1", Role: "support"},
+	} {
+		if err := graph.AddAgent(agent); err != nil {
+			t.Fatalf("Failed to add agent: %v", err)
+		}
+	}
+
+	if _, err := graph.GetEdgeBetween("sales-a", "inventory-a"); err != nil {
+		t.Errorf("Expected a full mesh edge within team-a: %v", err)
+	}
+	if _, err := graph.GetEdgeBetween("sales-a", "inventory-b"); err == nil {
+		t.Error("Expected no edge between namespaces")
+	}
+	if err := graph.ReinforceEdge(types.NewEdgeID("sales-b", "support-1"), types.MessageTypeTask); !errors.Is(err, topology.ErrEdgeForbidden) {
+		t.Errorf("Expected traffic between namespaces not to form an edge, got %v", err)
+	}
+
+	// Each namespace is a full mesh of its own, measured against its own agents
+	snapshot := graph.GetSnapshot()
+	stats := snapshot.Stats
+	if stats.TotalEdges != 9 || len(stats.Namespaces) != 3 {
+		t.Fatalf("Expected 9 edges in 3 namespaces, got %+v", stats)
+	}
+	if team := stats.Namespaces["team-a"]; team.Agents != 2 || team.Edges != 4 || team != stats.Namespaces["team-b"] {
+		t.Errorf("Expected team-a's 2 agents and 4 edges, self-loops included, like team-b, got %+v", stats.Namespaces)
+	}
+	if _, ok := stats.Namespaces[types.DefaultNamespace]; !ok {
+		t.Errorf("Expected agents without a namespace in %q, got %v", types.DefaultNamespace, stats.Namespaces)
+	}
+
+	view := topology.NamespaceView(cfg, snapshot, "team-b")
+	if len(view.Agents) != 2 || view.Stats.TotalEdges != len(view.Edges) || view.Stats.Namespaces["team-b"].Agents != 2 {
+		t.Fatalf("Expected the 2 team-b agents with their own stats, got %d agents, %+v", len(view.Agents), view.Stats)
+	}
+	for _, edge := range view.Edges {
+		if source := view.Agents[edge.SourceID]; source == nil || source.Namespace != "team-b" {
+			t.Errorf("Expected only team-b edges in its view, got %s", edge.ID)
+		}
+	}
+}
+
+func TestAgentNamespace(t *testing.T) {
+	agent := &types.Agent{ID: "sales-1", Metadata: map[string]string{"namespace": "team-a"}}
+	agent.PromoteMetadata()
+	if agent.Namespace != "team-a" || agent.MeshNamespace() != "team-a" {
+		t.Errorf("Expected the namespace promoted from metadata, got %q", agent.Namespace)
+	}
+	if other := (&types.Agent{ID: "sales-2"}); other.MeshNamespace() != types.DefaultNamespace || agent.SameNamespace(other) {
+		t.Errorf("Expected an agent without a namespace in the default one, got %q", other.MeshNamespace())
+	}
+
+	agent.Namespace = "team a"
+	if err := agent.Validate(); err == nil {
+		t.Error("Expected a namespace with a space to be rejected")
+	}
+}
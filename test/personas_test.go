@@ -0,0 +1,164 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/personas"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TestPersonasLibrary checks every role has a persona and that copies are independent
+func TestPersonasLibrary(t *testing.T) {
+	want := []string{"analyst", "coordinator", "fraud", "inventory", "research", "sales", "support"}
+	roles := personas.Roles()
+	if len(roles) != len(want) {
+		t.Fatalf("Expected roles %v, got %v", want, roles)
+	}
+	for i, role := range want {
+		if roles[i] != role {
+			t.Fatalf("Expected roles %v, got %v", want, roles)
+		}
+		persona, ok := personas.Get(role)
+		if !ok || persona.Role != role || len(persona.Behaviors) == 0 {
+			t.Errorf("Persona %s is missing or sends nothing", role)
+		}
+	}
+
+	tuned, _ := personas.Get("sales")
+	tuned.Params.Targets = []string{"support"}
+	fresh, _ := personas.Get("sales")
+	if len(fresh.Params.Targets) != 0 {
+		t.Error("Tuning a persona should not affect later copies")
+	}
+
+	if _, ok := personas.Get("unknown"); ok {
+		t.Error("Expected no persona for an unknown role")
+	}
+}
+
+// TestPersonaTick checks schedules, target rotation and goal reports
+func TestPersonaTick(t *testing.T) {
+	sales, _ := personas.Get("sales")
+
+	actions, _ := sales.Tick(1)
+	if len(actions) != 0 {
+		t.Errorf("Expected no sales messages on tick 1, got %d", len(actions))
+	}
+	actions, _ = sales.Tick(6)
+	if len(actions) != 2 || actions[0].TargetRole != "inventory" || actions[1].TargetRole != "fraud" {
+		t.Fatalf("Expected stock check and fraud verification on tick 6, got %+v", actions)
+	}
+	if actions[0].Payload["action"] != "check_stock" || actions[1].Payload["action"] != "verify_transaction" {
+		t.Errorf("Unexpected actions %v and %v", actions[0].Payload["action"], actions[1].Payload["action"])
+	}
+
+	support, _ := personas.Get("support")
+	actions, reports := support.Tick(6)
+	if len(actions) != 1 || actions[0].TargetRole != "sales" {
+		t.Fatalf("Expected support to message sales on tick 6, got %+v", actions)
+	}
+	if len(reports) != 1 || reports[0].GoalID != personas.GoalTicketResolution {
+		t.Errorf("Expected a ticket resolution report on tick 6, got %+v", reports)
+	}
+	actions, _ = support.Tick(4)
+	if actions[0].TargetRole != "inventory" || actions[0].Payload["action"] != "check_delivery" {
+		t.Errorf("Expected a delivery check with inventory on tick 4, got %+v", actions[0])
+	}
+
+	// Doubling activity halves the intervals; target overrides apply to every behavior
+	support.Params.Activity = 2
+	support.Params.Targets = []string{"fraud"}
+	actions, _ = support.Tick(1)
+	if len(actions) != 1 || actions[0].TargetRole != "fraud" {
+		t.Errorf("Expected a tuned support message to fraud on tick 1, got %+v", actions)
+	}
+}
+
+// TestPersonaParamsAndLearning checks parameter overrides, failure spreading and insight rules
+func TestPersonaParamsAndLearning(t *testing.T) {
+	params := personas.DefaultParams()
+	if err := personas.ParseParams(&params, "activity=2, targets=sales|fraud, interval=5s, failure_rate=0.25"); err != nil {
+		t.Fatalf("ParseParams failed: %v", err)
+	}
+	if params.Activity != 2 || len(params.Targets) != 2 || params.Interval.Seconds() != 5 || params.FailureRate != 0.25 {
+		t.Errorf("Unexpected params %+v", params)
+	}
+	for _, spec := range []string{"activity=0", "failure_rate=2", "speed=1", "activity"} {
+		if err := personas.ParseParams(&params, spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+
+	inventory, _ := personas.Get("inventory")
+	inventory.Params.FailureRate = 0.25
+	failures := 0
+	for n := 0; n < 100; n++ {
+		if inventory.Fails(n) {
+			failures++
+		}
+	}
+	if failures != 25 {
+		t.Errorf("Expected 25 failures in 100 tasks, got %d", failures)
+	}
+
+	agent := &types.Agent{ID: "inventory-1", Role: "inventory"}
+	insight := inventory.Learn(agent, &types.Message{
+		Type:    types.MessageTypeTask,
+		Payload: map[string]any{"action": "check_stock", "sku": "SKU-7"},
+	})
+	if insight == nil || insight.Type != types.InsightTypeInventoryTrend || insight.AgentID != agent.ID {
+		t.Fatalf("Expected an inventory trend insight, got %+v", insight)
+	}
+	if inventory.Learn(agent, &types.Message{Type: types.MessageTypeTask, Payload: map[string]any{"action": "escalate"}}) != nil {
+		t.Error("Expected no insight for an unrelated action")
+	}
+}
+
+func TestTypedTaskActions(t *testing.T) {
+	sales, _ := personas.Get("sales")
+	actions, _ := sales.Tick(6)
+
+	// Payloads travel as JSON and decode back into their typed structs
+	data, _ := json.Marshal(&types.Message{Type: types.MessageTypeTask, Payload: actions[0].Payload})
+	var msg types.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	action, err := types.DecodeTaskAction(&msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check, ok := action.(*types.CheckStock)
+	if !ok || check.SKU != "SKU-6" || check.Qty != 6 || check.Product != "Product-6" {
+		t.Errorf("Expected the stock check, got %#v", action)
+	}
+
+	// A malformed payload is an error, not a panic
+	msg.Payload["qty"] = "six"
+	if _, err := types.DecodeTaskAction(&msg); err == nil {
+		t.Error("Expected a malformed payload to be rejected")
+	}
+	msg.Payload["action"] = "launch_rocket"
+	if _, err := types.DecodeTaskAction(&msg); !errors.Is(err, types.ErrUnknownAction) {
+		t.Errorf("Expected an unknown action, got %v", err)
+	}
+
+	// Teams register their own actions
+	types.RegisterTaskAction(func() types.TaskAction { return &reserveItems{} })
+	payload, err := types.EncodeTaskAction(&reserveItems{SKU: "SKU-1", Qty: 2})
+	if err != nil || payload["action"] != "reserve_items" {
+		t.Fatalf("Expected an encoded reservation, got %v (%v)", payload, err)
+	}
+	if decoded, err := types.DecodeTaskAction(&types.Message{Payload: payload}); err != nil || decoded.(*reserveItems).Qty != 2 {
+		t.Errorf("Expected the reservation back, got %v (%v)", decoded, err)
+	}
+}
+
+type reserveItems struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+func (reserveItems) Action() string { return "reserve_items" }
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// voteThrough has every registered agent support a proposal
+func voteThrough(t *testing.T, bc *consensus.BeeConsensus, proposal *types.Proposal, voters ...types.AgentID) {
+	t.Helper()
+	for _, voter := range voters {
+		if err := bc.Vote(proposal.ID, voter, true, 1.0); err != nil {
+			t.Fatalf("Failed to vote on %s: %v", proposal.ID, err)
+		}
+	}
+}
+
+func TestProposalDependencyParsing(t *testing.T) {
+	request, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+		"proposer_id": "agent-sales-1",
+		"type":        "action",
+		"content":     map[string]any{"action": "lower_price"},
+		"depends_on":  []any{"budget-1", "budget-1", "forecast-2"},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse proposal: %v", err)
+	}
+	if len(request.DependsOn) != 2 || request.DependsOn[0] != "budget-1" || request.DependsOn[1] != "forecast-2" {
+		t.Errorf("Expected two distinct prerequisites, got %v", request.DependsOn)
+	}
+
+	for _, dependsOn := range []any{"budget-1", []any{"budget-1", 7.0}, []any{""}} {
+		if _, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+			"proposer_id": "agent-sales-1", "type": "action", "content": map[string]any{}, "depends_on": dependsOn,
+		}}); err == nil {
+			t.Errorf("Expected depends_on %v to be rejected", dependsOn)
+		}
+	}
+}
+
+func TestProposalDependencies(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Hour}, zap.NewNop())
+	voters := []types.AgentID{"agent-finance-1", "agent-sales-1"}
+	for _, id := range voters {
+		bc.RegisterAgent(id)
+	}
+	propose := func(dependsOn ...types.ProposalID) *types.Proposal {
+		t.Helper()
+		proposal, err := bc.Propose(&consensus.ProposalRequest{
+			ProposerID: "agent-sales-1",
+			Type:       types.ProposalTypeDecision,
+			Content:    map[string]any{"action": "lower_price"},
+			DependsOn:  dependsOn,
+		})
+		if err != nil {
+			t.Fatalf("Failed to propose: %v", err)
+		}
+		return proposal
+	}
+
+	if _, err := bc.Propose(&consensus.ProposalRequest{ProposerID: "agent-sales-1", Type: types.ProposalTypeDecision, Content: map[string]any{}, DependsOn: []types.ProposalID{"unknown"}}); err == nil {
+		t.Error("Expected an unknown prerequisite to be rejected")
+	}
+
+	// A dependent reaching quorum first waits for its prerequisite
+	budget := propose()
+	price := propose(budget.ID)
+	voteThrough(t, bc, price, voters...)
+	if price.Status != types.ProposalStatusPending {
+		t.Fatalf("Expected the price change pending until the budget is accepted, got %s", price.Status)
+	}
+	voteThrough(t, bc, budget, voters...)
+	if budget.Status != types.ProposalStatusAccepted || price.Status != types.ProposalStatusAccepted {
+		t.Fatalf("Expected both accepted in order, got budget %s and price %s", budget.Status, price.Status)
+	}
+
+	// A prerequisite expiring with no escalation left expires its dependents, and theirs
+	now := time.Now()
+	failed, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-finance-1",
+		Type:       types.ProposalTypeDecision,
+		Content:    map[string]any{"action": "raise_budget"},
+		Window:     &types.ProposalWindow{Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	dependent := propose(failed.ID)
+	transitive := propose(dependent.ID)
+	failed.Window.End = now.Add(-time.Second)
+	voteThrough(t, bc, failed, voters...)
+	if failed.Status != types.ProposalStatusExpired {
+		t.Fatalf("Expected the prerequisite expired with its window closed, got %s", failed.Status)
+	}
+	if dependent.Status != types.ProposalStatusExpired || dependent.BlockedBy != failed.ID {
+		t.Errorf("Expected the dependent expired by its failed prerequisite, got %s blocked by %q", dependent.Status, dependent.BlockedBy)
+	}
+	if transitive.Status != types.ProposalStatusExpired || transitive.BlockedBy != dependent.ID {
+		t.Errorf("Expected the dependent's dependent expired too, got %s blocked by %q", transitive.Status, transitive.BlockedBy)
+	}
+	if _, err := bc.Propose(&consensus.ProposalRequest{ProposerID: "agent-sales-1", Type: types.ProposalTypeDecision, Content: map[string]any{}, DependsOn: []types.ProposalID{failed.ID}}); err == nil {
+		t.Error("Expected a failed prerequisite to be rejected")
+	}
+
+	graph := types.NewDependencyGraph([]*types.Proposal{budget, price, failed, dependent, transitive, propose()}, "")
+	if len(graph.Nodes) != 5 || len(graph.Edges) != 3 {
+		t.Errorf("Expected 5 proposals linked by 3 dependencies, got %+v", graph)
+	}
+	if part := types.NewDependencyGraph([]*types.Proposal{budget, price, failed, dependent, transitive}, price.ID); len(part.Nodes) != 2 || part.Edges[0] != (types.DependencyEdge{From: budget.ID, To: price.ID}) {
+		t.Errorf("Expected only the budget and price, got %+v", part)
+	}
+}
+
+func TestProposalDependencyEscalation(t *testing.T) {
+	cfg := &types.Config{
+		QuorumThreshold: 0.6,
+		ProposalTimeout: time.Hour,
+		EscalationPolicies: types.EscalationPolicies{
+			types.ProposalTypeAction: {{Action: types.EscalationDefault, Decision: types.ProposalStatusAccepted}},
+		},
+	}
+	bc := consensus.NewBeeConsensus(cfg, zap.NewNop())
+	voters := []types.AgentID{"agent-finance-1", "agent-sales-1"}
+	for _, id := range voters {
+		bc.RegisterAgent(id)
+	}
+	now := time.Now()
+
+	// The prerequisite expires, but its escalation may still decide it
+	budget, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-finance-1",
+		Type:       types.ProposalTypeAction,
+		Content:    map[string]any{"action": "raise_budget"},
+		Window:     &types.ProposalWindow{Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	price, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeDecision,
+		Content:    map[string]any{"action": "lower_price"},
+		DependsOn:  []types.ProposalID{budget.ID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	budget.Window.End = now.Add(-time.Second)
+	voteThrough(t, bc, budget, voters...)
+	voteThrough(t, bc, price, voters...)
+	if budget.Status != types.ProposalStatusExpired || price.Status != types.ProposalStatusPending {
+		t.Fatalf("Expected the price change waiting on the expired budget's escalation, got budget %s and price %s", budget.Status, price.Status)
+	}
+
+	if err := bc.Resolve(budget, types.ProposalStatusAccepted); err != nil {
+		t.Fatalf("Failed to apply default decision: %v", err)
+	}
+	if price.Status != types.ProposalStatusAccepted {
+		t.Errorf("Expected the price change accepted with the budget's default decision, got %s", price.Status)
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestProposalWindowParsing(t *testing.T) {
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	request, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+		"proposer_id": "agent-sales-1",
+		"type":        "action",
+		"content":     map[string]any{"action": "apply_discount", "percent": 10.0},
+		"window":      map[string]any{"start": start.Format(time.RFC3339), "end": end.Format(time.RFC3339)},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse proposal: %v", err)
+	}
+	if request.Window == nil || !request.Window.Start.Equal(start) || !request.Window.End.Equal(end) {
+		t.Fatalf("Expected the window %s to %s, got %+v", start, end, request.Window)
+	}
+
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	invalid := []any{
+		"2pm-4pm",
+		map[string]any{"start": start.Format(time.RFC3339)},
+		map[string]any{"start": end.Format(time.RFC3339), "end": start.Format(time.RFC3339)},
+		map[string]any{"start": past, "end": past},
+	}
+	for _, window := range invalid {
+		_, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+			"proposer_id": "agent-sales-1",
+			"type":        "action",
+			"content":     map[string]any{},
+			"window":      window,
+		}})
+		if err == nil {
+			t.Errorf("Expected window %v to be rejected", window)
+		}
+	}
+}
+
+func TestProposalWindowExecution(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: 3 * time.Hour}, zap.NewNop())
+	for _, id := range []types.AgentID{"agent-sales-1", "agent-sales-2"} {
+		bc.RegisterAgent(id)
+	}
+	now := time.Now()
+
+	// A proposal for a later window is accepted now and executes once it opens
+	window := &types.ProposalWindow{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+	proposal, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeAction,
+		Content:    map[string]any{"action": "apply_discount"},
+		Window:     window,
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	if !proposal.ExpiresAt.Equal(window.End) {
+		t.Errorf("Expected voting to end with the window at %s, got %s", window.End, proposal.ExpiresAt)
+	}
+	for _, voter := range []types.AgentID{"agent-sales-1", "agent-sales-2"} {
+		if err := bc.Vote(proposal.ID, voter, true, 1.0); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if proposal.Status != types.ProposalStatusAccepted || !proposal.ExecutedAt.IsZero() {
+		t.Fatalf("Expected accepted and waiting for its window, got %s executed at %s", proposal.Status, proposal.ExecutedAt)
+	}
+	if proposal.Execute(now) {
+		t.Error("Expected no execution before the window opens")
+	}
+	if !proposal.Execute(window.Start) || proposal.Execute(window.Start.Add(time.Minute)) {
+		t.Error("Expected exactly one execution once the window opens")
+	}
+
+	// Accepted within its window, a proposal executes right away
+	open, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeAction,
+		Content:    map[string]any{"action": "apply_discount"},
+		Window:     &types.ProposalWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	for _, voter := range []types.AgentID{"agent-sales-1", "agent-sales-2"} {
+		bc.Vote(open.ID, voter, true, 1.0)
+	}
+	if open.ExecutedAt.IsZero() {
+		t.Error("Expected a proposal accepted within its window to execute")
+	}
+	executed := false
+	for len(bc.EventChannel()) > 0 {
+		if event := <-bc.EventChannel(); event.Type == consensus.ConsensusEventProposalExecuted && event.ProposalID == open.ID {
+			executed = true
+		}
+	}
+	if !executed {
+		t.Error("Expected a proposal_executed event")
+	}
+
+	// Quorum reached after the window closed expires the proposal instead
+	late, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeAction,
+		Content:    map[string]any{"action": "apply_discount"},
+		Window:     &types.ProposalWindow{Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	late.Window.End = now.Add(-time.Second)
+	for _, voter := range []types.AgentID{"agent-sales-1", "agent-sales-2"} {
+		bc.Vote(late.ID, voter, true, 1.0)
+	}
+	if late.Status != types.ProposalStatusExpired {
+		t.Errorf("Expected a proposal accepted after its window to expire, got %s", late.Status)
+	}
+	if _, err := bc.Repropose(late, nil, time.Minute); err == nil {
+		t.Error("Expected a proposal whose window closed not to be re-proposed")
+	}
+}
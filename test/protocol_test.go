@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestCheckProtocol(t *testing.T) {
+	cases := []struct {
+		name       string
+		version    int
+		minVersion int
+		want       types.Compatibility
+	}{
+		{"legacy unversioned", 0, 0, types.CompatibilityFull},
+		{"current", types.ProtocolVersion, types.MinProtocolVersion, types.CompatibilityFull},
+		{"newer but readable", types.ProtocolVersion + 1, types.ProtocolVersion, types.CompatibilityDegraded},
+		{"newer and unreadable", types.ProtocolVersion + 1, types.ProtocolVersion + 1, types.CompatibilityUnsupported},
+	}
+
+	for _, tc := range cases {
+		if got := types.CheckProtocol(tc.version, tc.minVersion); got != tc.want {
+			t.Errorf("%s: CheckProtocol(%d, %d) = %s, want %s", tc.name, tc.version, tc.minVersion, got, tc.want)
+		}
+	}
+}
+
+func TestTopologyEventEnvelope(t *testing.T) {
+	agent := &types.Agent{ID: "agent-sales-1", Name: "Sales", Role: "sales"}
+	data, err := types.EncodeTopologyEvent(types.TopologyEvent{Type: types.TopologyEventAgentJoined, AgentID: agent.ID, Agent: agent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := types.DecodeTopologyEvent(data)
+	if err != nil || event.Agent == nil || event.Agent.Role != "sales" || event.ProtocolVersion != types.ProtocolVersion {
+		t.Fatalf("Expected the stamped join event back, got %+v (%v)", event, err)
+	}
+
+	// Early producers wrapped events in messages, with fields as JSON strings
+	legacy := []struct {
+		record string
+		want   types.TopologyEvent
+	}{
+		{
+			`{"id":"m1","from_agent_id":"agent-sales-1","type":"agent_joined","payload":{"agent":"{\"id\":\"agent-sales-1\",\"role\":\"sales\"}"}}`,
+			types.TopologyEvent{Type: types.TopologyEventAgentJoined, AgentID: "agent-sales-1"},
+		},
+		{
+			`{"id":"m2","type":"topology","payload":{"type":"agent_left","agent_id":"agent-sales-1"}}`,
+			types.TopologyEvent{Type: types.TopologyEventAgentLeft, AgentID: "agent-sales-1"},
+		},
+		{
+			`{"id":"m3","type":"topology","payload":{"event":"{\"type\":\"agent_left\",\"agent_id\":\"agent-sales-1\"}"}}`,
+			types.TopologyEvent{Type: types.TopologyEventAgentLeft, AgentID: "agent-sales-1"},
+		},
+	}
+	for _, tc := range legacy {
+		event, err := types.DecodeTopologyEvent([]byte(tc.record))
+		if err != nil || event.Type != tc.want.Type || event.AgentID != tc.want.AgentID {
+			t.Errorf("Decoding %s: got %+v (%v)", tc.record, event, err)
+		}
+	}
+	if event, _ := types.DecodeTopologyEvent([]byte(legacy[0].record)); event.Agent == nil || event.Agent.Role != "sales" {
+		t.Errorf("Expected the agent of a legacy join, got %+v", event.Agent)
+	}
+
+	if _, err := types.DecodeTopologyEvent([]byte(`{"agent_id":"agent-sales-1"}`)); err == nil {
+		t.Error("Expected an event without a type to be rejected")
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/ranking"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func rankedInsight(id string, agent types.AgentID, topic string, createdAt time.Time) *types.Insight {
+	insight := types.NewInsight(agent, "support", types.InsightTypeProductIssue, topic, "issue", 0.8)
+	insight.ID = types.InsightID(id)
+	insight.CreatedAt = createdAt
+	return insight
+}
+
+func TestRankerPrefersCorroboratedInsights(t *testing.T) {
+	cfg := config.Default()
+	now := time.Now()
+
+	corpus := []*types.Insight{
+		rankedInsight("lone", "agent-a", "billing", now.Add(-time.Minute)),
+		rankedInsight("shared", "agent-a", "mobile_app", now.Add(-time.Minute)),
+		rankedInsight("echo-1", "agent-b", "mobile_app", now.Add(-2*time.Minute)),
+		rankedInsight("echo-2", "agent-c", "mobile_app", now.Add(-3*time.Minute)),
+		rankedInsight("stale", "agent-d", "pricing", now.Add(-2*cfg.CorroborationWindow)),
+		rankedInsight("repeat", "agent-d", "pricing", now.Add(-time.Minute)),
+	}
+	ranker := ranking.NewRanker(cfg, corpus)
+
+	lone := ranker.Score(corpus[0], now)
+	shared := ranker.Score(corpus[1], now)
+	if shared.Corroborators != 2 || lone.Corroborators != 0 {
+		t.Fatalf("expected 2 and 0 corroborators, got %d and %d", shared.Corroborators, lone.Corroborators)
+	}
+	if lone.Novelty != 1 || shared.Novelty != 1 {
+		t.Errorf("recent reports from other agents should not reduce novelty, got %v and %v", lone.Novelty, shared.Novelty)
+	}
+	if repeat := ranker.Score(corpus[5], now); repeat.Novelty != 0.5 {
+		t.Errorf("expected an old report to halve novelty, got %v", repeat.Novelty)
+	}
+	if shared.Score <= lone.Score {
+		t.Errorf("corroborated insight should outrank, got %v <= %v", shared.Score, lone.Score)
+	}
+
+	// agent-b's only insight was corroborated, an unknown agent has the neutral prior
+	if ranker.Reputation("agent-b") <= ranker.Reputation("agent-unknown") {
+		t.Errorf("corroborated agent should have a higher reputation")
+	}
+}
+
+func TestRankerSortAndRecency(t *testing.T) {
+	cfg := config.Default()
+	now := time.Now()
+
+	old := rankedInsight("old", "agent-a", "pricing", now.Add(-2*cfg.InsightHalfLife))
+	fresh := rankedInsight("fresh", "agent-b", "shipping", now)
+	ranker := ranking.NewRanker(cfg, []*types.Insight{old, fresh})
+
+	if r := ranker.Score(old, now).Recency; r < 0.24 || r > 0.26 {
+		t.Errorf("expected recency 0.25 after two half-lives, got %v", r)
+	}
+
+	insights := []types.Insight{*old, *fresh}
+	ranker.Sort(insights, now)
+	if insights[0].ID != "fresh" || insights[0].Rank == nil {
+		t.Errorf("expected the fresh insight first with a rank, got %s", insights[0].ID)
+	}
+
+	cfg.InsightPushThreshold = 0
+	if ranker.ShouldPush(types.InsightRank{Score: 1}) {
+		t.Error("a zero threshold should disable pushing")
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestRecurringProposalsConfig(t *testing.T) {
+	t.Setenv("RECURRING_PROPOSALS", `[
+		{"name": "weekly-budget", "every": "168h", "type": "decision", "content": {"action": "reallocate_budget"}, "policy": "budget"},
+		{"name": "price-review", "every": "24h", "template": "price_change", "params": {"product": "widget"}, "carry_over": 5}
+	]`)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfg.RecurringProposals) != 2 {
+		t.Fatalf("Expected 2 recurring proposals, got %+v", cfg.RecurringProposals)
+	}
+	budget := cfg.RecurringProposals[0]
+	if budget.Interval() != 168*time.Hour || budget.RoundsCarried() != 3 || budget.Policy != "budget" {
+		t.Errorf("Expected a weekly round carrying 3 rounds into the budget policy, got %+v", budget)
+	}
+
+	invalid := []string{
+		`[{"name": "fast", "every": "10s", "type": "decision", "content": {"a": 1}}]`,
+		`[{"name": "both", "every": "1h", "template": "price_change", "type": "decision", "content": {"a": 1}}]`,
+		`[{"name": "empty", "every": "1h", "type": "decision"}]`,
+		`[{"name": "twice", "every": "1h", "type": "decision", "content": {"a": 1}}, {"name": "twice", "every": "2h", "type": "decision", "content": {"a": 1}}]`,
+	}
+	for _, data := range invalid {
+		if _, err := types.ParseRecurringProposals(data); err == nil {
+			t.Errorf("Expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestRecurringRoundsCarryOver(t *testing.T) {
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	every := 168 * time.Hour
+	progress := &types.RecurrenceState{Name: "weekly-budget"}
+	if !progress.Due(every, start) {
+		t.Fatal("Expected the first round due right away")
+	}
+
+	content := map[string]any{"action": "reallocate_budget"}
+	for round := 1; round <= 4; round++ {
+		now := start.Add(time.Duration(round-1) * every)
+		roundContent := types.RoundContent(content, round, progress.Rounds)
+		if roundContent["round"] != round {
+			t.Fatalf("Expected round %d, got %v", round, roundContent["round"])
+		}
+		if carried := roundContent["previous_rounds"].([]any); len(carried) != min(round-1, 2) {
+			t.Fatalf("Expected round %d to carry %d rounds, got %d", round, min(round-1, 2), len(carried))
+		}
+
+		proposal := &types.Proposal{ID: types.NewProposalID(), Round: round, Content: roundContent, Status: types.ProposalStatusPending}
+		progress.Propose(proposal, now, 2)
+		if progress.Due(every, now.Add(time.Hour)) {
+			t.Fatalf("Expected round %d not due again within the week", round)
+		}
+
+		// Every other round is rejected
+		proposal.Status, proposal.DecidedAt = types.ProposalStatusAccepted, now.Add(time.Minute)
+		if round%2 == 0 {
+			proposal.Status = types.ProposalStatusRejected
+		}
+		if !progress.Decide(proposal) {
+			t.Fatalf("Expected round %d to be kept", round)
+		}
+	}
+	if _, ok := content["round"]; ok {
+		t.Error("Expected the recurring proposal's content left untouched")
+	}
+
+	carried := types.RoundContent(content, 5, progress.Rounds)["previous_rounds"].([]any)
+	last := carried[len(carried)-1].(map[string]any)
+	if last["round"] != 4 || last["status"] != "rejected" || last["decided_at"] == nil {
+		t.Errorf("Expected round 4's rejection carried into round 5, got %v", last)
+	}
+	if _, nested := last["content"].(map[string]any)["previous_rounds"]; nested {
+		t.Error("Expected carried rounds without the rounds they carried")
+	}
+	if progress.Decide(&types.Proposal{Round: 1}) {
+		t.Error("Expected round 1 no longer kept")
+	}
+}
+
+func TestStandingPolicies(t *testing.T) {
+	request, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+		"proposer_id": "agent-finance-1",
+		"type":        "decision",
+		"content":     map[string]any{"max_discount": 0.15},
+		"policy":      "discount-cap",
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse proposal: %v", err)
+	}
+	if _, err := consensus.ParseProposalRequest(map[string]any{"proposal": map[string]any{
+		"proposer_id": "agent-finance-1", "type": "decision", "content": map[string]any{}, "policy": "no spaces",
+	}}); err == nil {
+		t.Error("Expected an invalid policy name to be rejected")
+	}
+
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	first, err := bc.Propose(request)
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	if first.Policy != "discount-cap" {
+		t.Fatalf("Expected the proposal for the discount-cap policy, got %q", first.Policy)
+	}
+
+	now := time.Now()
+	policy := types.NewStandingPolicy(first, nil, now)
+	if policy.Name != "discount-cap" || policy.ProposalID != first.ID || policy.Supersedes != "" {
+		t.Errorf("Expected the first proposal in force, got %+v", policy)
+	}
+
+	second, _ := bc.Propose(request)
+	next := types.NewStandingPolicy(second, policy, now.Add(time.Hour))
+	if next.ProposalID != second.ID || next.Supersedes != first.ID {
+		t.Errorf("Expected the second proposal to supersede the first, got %+v", next)
+	}
+	if again := types.NewStandingPolicy(second, next, now); again.Supersedes != "" {
+		t.Errorf("Expected a proposal not to supersede itself, got %+v", again)
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestRegionTopology(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	graph := topology.NewGraph(cfg)
+	for _, agent := range []*types.Agent{
+		{ID: "sales-eu", Role: "sales", Region: "eu-west"},
+		{ID: "inventory-eu", Role: "inventory", Region: "eu-west"},
+		{ID: "inventory-us", Role: "inventory", Region: "us-east"},
+	} {
+		if err := graph.AddAgent(agent); err != nil {
+			t.Fatalf("Failed to add agent: %v", err)
+		}
+	}
+
+	local, _ := graph.GetEdgeBetween("sales-eu", "inventory-eu")
+	remote, _ := graph.GetEdgeBetween("inventory-us", "sales-eu")
+	if local.Region != "eu-west" || local.CrossRegion || !remote.CrossRegion || remote.Region != "" {
+		t.Errorf("Expected a local eu-west edge and a cross-region edge, got %+v / %+v", local, remote)
+	}
+
+	view := topology.RegionView(cfg, graph.GetSnapshot(), "eu-west")
+	if len(view.Agents) != 2 || view.Stats.TotalAgents != 2 || view.Stats.TotalEdges != len(view.Edges) {
+		t.Fatalf("Expected the 2 eu-west agents with their own stats, got %d agents, %+v", len(view.Agents), view.Stats)
+	}
+	for _, edge := range view.Edges {
+		if edge.CrossRegion {
+			t.Errorf("Expected no cross-region edge in a region view, got %s", edge.ID)
+		}
+	}
+}
+
+func TestRegionRouting(t *testing.T) {
+	cfg := config.Default()
+	candidates := []types.RouteCandidate{
+		{AgentID: "inventory-us", Region: "us-east", Weight: 0.8},
+		{AgentID: "inventory-eu", Region: "eu-west", Weight: 0.6},
+	}
+	ranked := routing.PreferRegion(cfg, "eu-west", routing.Rank(cfg, "sales-eu", candidates, nil))
+	if ranked[0].AgentID != "inventory-eu" || ranked[1].Score != 0.4 {
+		t.Errorf("Expected the local candidate first and the remote one halved, got %+v", ranked)
+	}
+
+	peers, err := types.ParseFederationPeers("us-east=kafka-us-1:9092, kafka-us-2:9092; ap-south=kafka-ap:9092")
+	if err != nil || len(peers["us-east"]) != 2 || len(peers["ap-south"]) != 1 {
+		t.Errorf("Expected 2 peers, got %v (%v)", peers, err)
+	}
+	if _, err := types.ParseFederationPeers("us-east="); err == nil {
+		t.Error("Expected a peer without brokers to be rejected")
+	}
+
+	cfg.Region = "eu-west"
+	insight := types.NewInsight("sales-eu", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.9)
+	insight.Region = "eu-west"
+	insight.Rank = &types.InsightRank{Score: 0.85}
+	push := &types.Message{Payload: map[string]any{}}
+	if !types.Replicable(push, insight, cfg) {
+		t.Error("Expected a high-value local insight to be replicated")
+	}
+	insight.Region = "us-east"
+	if types.Replicable(push, insight, cfg) {
+		t.Error("Expected a replicated insight not to be replicated again")
+	}
+	insight.Region = "eu-west"
+	if types.Replicable(&types.Message{Payload: map[string]any{"audience": []string{"sales"}}}, insight, cfg) {
+		t.Error("Expected pushes limited by sharing agreements to stay in their region")
+	}
+}
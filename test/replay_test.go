@@ -0,0 +1,225 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/replay"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TestReplayRoundTrip records traffic and plays it back in order with compressed gaps
+func TestReplayRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := replay.NewRecorder(&buf, "agentmesh", replay.DefaultTopics)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	start := time.Now()
+	message, _ := json.Marshal(&types.Message{ID: "msg-1", Type: types.MessageTypeTask, Timestamp: start})
+	event, _ := json.Marshal(types.TopologyEvent{Type: types.TopologyEventAgentJoined, AgentID: "agent-1", Timestamp: start})
+	if err := recorder.Record("topology", []byte("agent-1"), event, start); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := recorder.Record("messages", []byte("msg-1"), message, start.Add(200*time.Millisecond)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := recorder.Record("messages", nil, []byte("not json"), start); err == nil {
+		t.Error("Expected non-JSON records to be rejected")
+	}
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if recorder.Count() != 2 {
+		t.Errorf("Expected 2 records, got %d", recorder.Count())
+	}
+
+	reader, err := replay.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if reader.Header.TopicPrefix != "agentmesh" || reader.Header.Version != replay.FormatVersion {
+		t.Errorf("Unexpected header %+v", reader.Header)
+	}
+
+	var played []replay.Entry
+	began := time.Now()
+	n, err := replay.Play(context.Background(), reader, 4, func(entry replay.Entry) error {
+		played = append(played, entry)
+		return nil
+	})
+	elapsed := time.Since(began)
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 entries played, got %d (%v)", n, err)
+	}
+	if played[0].Topic != "topology" || played[1].Topic != "messages" || played[1].Key != "msg-1" {
+		t.Errorf("Entries replayed out of order: %+v", played)
+	}
+	// The 200ms gap at 4x takes about 50ms
+	if elapsed < 40*time.Millisecond || elapsed > 150*time.Millisecond {
+		t.Errorf("Expected about 50ms of playback at 4x, took %v", elapsed)
+	}
+
+	var replayed types.Message
+	if err := json.Unmarshal(played[1].Value, &replayed); err != nil || replayed.ID != "msg-1" {
+		t.Errorf("Message did not round-trip: %+v (%v)", replayed, err)
+	}
+}
+
+// TestReplayRetime checks replayed records get fresh timestamps
+func TestReplayRetime(t *testing.T) {
+	old := time.Now().Add(-24 * time.Hour)
+	value, _ := json.Marshal(&types.Message{ID: "msg-1", Timestamp: old})
+
+	now := time.Now()
+	retimed, err := replay.Retime(value, now)
+	if err != nil {
+		t.Fatalf("Retime failed: %v", err)
+	}
+	var message types.Message
+	if err := json.Unmarshal(retimed, &message); err != nil {
+		t.Fatalf("Retimed record is not a message: %v", err)
+	}
+	if !message.Timestamp.Equal(now) || message.ID != "msg-1" {
+		t.Errorf("Expected timestamp %v and ID msg-1, got %v and %s", now, message.Timestamp, message.ID)
+	}
+
+	proposal := json.RawMessage(`{"id":"prop-1","created_at":"2024-01-01T00:00:00Z"}`)
+	if unchanged, _ := replay.Retime(proposal, now); !bytes.Equal(unchanged, proposal) {
+		t.Errorf("Expected records without a timestamp to be unchanged, got %s", unchanged)
+	}
+}
+
+// TestReplayParseTime checks replay windows accept RFC3339 and Unix seconds
+func TestReplayParseTime(t *testing.T) {
+	at, err := replay.ParseTime("2024-03-01T12:30:00Z")
+	if err != nil || !at.Equal(time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected RFC3339 time parsed, got %v (%v)", at, err)
+	}
+	at, err = replay.ParseTime("1709296200")
+	if err != nil || !at.Equal(time.Unix(1709296200, 0)) {
+		t.Errorf("Expected Unix seconds parsed, got %v (%v)", at, err)
+	}
+
+	for _, value := range []string{"", "yesterday", "2024-03-01", "1.5"} {
+		if _, err := replay.ParseTime(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+// TestReplayTimeline checks snapshot and message history merge in time order
+func TestReplayTimeline(t *testing.T) {
+	base := time.Now()
+	snapshots := []*types.GraphSnapshot{
+		{Timestamp: base, Sequence: 1},
+		{Timestamp: base.Add(2 * time.Second), Sequence: 2},
+	}
+	messages := []*types.Message{
+		{ID: "msg-1", Timestamp: base.Add(-time.Second)},
+		{ID: "msg-2", Timestamp: base.Add(time.Second)},
+		{ID: "msg-3", Timestamp: base.Add(2 * time.Second)},
+		{ID: "msg-4", Timestamp: base.Add(2 * time.Second)},
+	}
+
+	frames := replay.Timeline(snapshots, messages)
+
+	// A snapshot comes before the messages taken with it, which keep their order
+	expected := []string{"msg-1", "snapshot-1", "msg-2", "snapshot-2", "msg-3", "msg-4"}
+	if len(frames) != len(expected) {
+		t.Fatalf("Expected %d frames, got %d", len(expected), len(frames))
+	}
+	for i, frame := range frames {
+		name := ""
+		switch {
+		case frame.Snapshot != nil && frame.Message == nil:
+			name = fmt.Sprintf("snapshot-%d", frame.Snapshot.Sequence)
+		case frame.Message != nil && frame.Snapshot == nil:
+			name = frame.Message.ID
+		}
+		if name != expected[i] {
+			t.Errorf("Frame %d: expected %s, got %q", i, expected[i], name)
+		}
+		if i > 0 && frame.At.Before(frames[i-1].At) {
+			t.Errorf("Frame %d at %v is before the previous one at %v", i, frame.At, frames[i-1].At)
+		}
+	}
+
+	if frames := replay.Timeline(nil, nil); len(frames) != 0 {
+		t.Errorf("Expected no frames without history, got %d", len(frames))
+	}
+}
+
+// TestSimulator replays a recorded trace offline against two decay rates
+func TestSimulator(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := replay.NewRecorder(&buf, "agentmesh", replay.DefaultTopics)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	start := time.Now()
+	for _, id := range []types.AgentID{"sim-a", "sim-b", "sim-c", "sim-d"} {
+		agent := &types.Agent{ID: id, Role: "worker", Status: types.AgentStatusActive, CreatedAt: start}
+		event, _ := json.Marshal(types.TopologyEvent{Type: types.TopologyEventAgentJoined, AgentID: id, Agent: agent, Timestamp: start})
+		recorder.Record("topology", []byte(id), event, start)
+	}
+	// The other pairs talk once, sim-a and sim-b every 5s for 10 minutes
+	for i, pair := range [][2]types.AgentID{{"sim-a", "sim-c"}, {"sim-c", "sim-d"}, {"sim-d", "sim-b"}} {
+		message, _ := json.Marshal(&types.Message{ID: fmt.Sprintf("sim-once-%d", i), FromAgentID: pair[0], ToAgentID: pair[1], Type: types.MessageTypeTask, Timestamp: start})
+		recorder.Record("messages", nil, message, start)
+	}
+	for i := 1; i <= 120; i++ {
+		at := start.Add(time.Duration(i) * 5 * time.Second)
+		message, _ := json.Marshal(&types.Message{ID: fmt.Sprintf("sim-msg-%d", i), FromAgentID: "sim-a", ToAgentID: "sim-b", Type: types.MessageTypeTask, Timestamp: at})
+		recorder.Record("messages", nil, message, at)
+	}
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	recording := buf.Bytes()
+
+	simulate := func(params string) types.SimulationReport {
+		p, err := types.ParseTopologyParams(params)
+		if err != nil {
+			t.Fatalf("ParseTopologyParams failed: %v", err)
+		}
+		reader, err := replay.NewReader(bytes.NewReader(recording))
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+		simulator := topology.NewSimulator(config.Default(), *p, time.Minute)
+		if err := simulator.Replay(reader); err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		return simulator.Report()
+	}
+
+	slow := simulate("DECAY_RATE=0.001")
+	fast := simulate("DECAY_RATE=0.5")
+
+	if slow.Agents != 4 || slow.Messages != 123 {
+		t.Errorf("Expected 4 agents and 123 messages, got %d and %d", slow.Agents, slow.Messages)
+	}
+	// 10 minutes of trace at the default 5s decay interval
+	if slow.Cycles != 120 || slow.Until.Sub(slow.From) != 10*time.Minute {
+		t.Errorf("Expected 120 cycles up to the last message, got %d over %v", slow.Cycles, slow.Until.Sub(slow.From))
+	}
+	if len(slow.Timeline) != 11 || !slow.Timeline[0].Timestamp.Equal(slow.From) {
+		t.Errorf("Expected a sample per minute of trace time, got %d", len(slow.Timeline))
+	}
+	if slow.Params.DecayRate != 0.001 || fast.Params.DecayRate != 0.5 {
+		t.Errorf("Parameters not applied: %+v and %+v", slow.Params, fast.Params)
+	}
+	if fast.Pruned <= slow.Pruned || fast.Stats.TotalEdges >= slow.Stats.TotalEdges {
+		t.Errorf("Expected faster decay to prune more: %d pruned leaving %d edges vs %d leaving %d",
+			fast.Pruned, fast.Stats.TotalEdges, slow.Pruned, slow.Stats.TotalEdges)
+	}
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/routing"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func sendTask(l *routing.Learner, from, to types.AgentID, sentAt time.Time) *types.Message {
+	task := &types.Message{
+		ID:          string(from) + "-" + sentAt.Format(time.RFC3339Nano),
+		FromAgentID: from,
+		ToAgentID:   to,
+		Type:        types.MessageTypeTask,
+		Payload:     map[string]any{"action": "check_stock"},
+		Timestamp:   sentAt,
+	}
+	l.Observe(task)
+	return task
+}
+
+func TestRoutingLearnerRecordsOutcomes(t *testing.T) {
+	cfg := config.Default()
+	learner := routing.NewLearner(cfg)
+	now := time.Now()
+
+	task := sendTask(learner, "sales", "inventory-1", now)
+	response := types.NewResponse(task, true, nil)
+	response.Timestamp = now.Add(200 * time.Millisecond)
+
+	outcome := learner.Observe(response)
+	if outcome == nil || !outcome.Success || outcome.Latency != 200*time.Millisecond {
+		t.Fatalf("unexpected outcome %+v", outcome)
+	}
+	if learner.Observe(response) != nil {
+		t.Error("duplicate response recorded twice")
+	}
+
+	failed := types.NewResponse(sendTask(learner, "sales", "inventory-1", now.Add(time.Second)), false, nil)
+	learner.Observe(failed)
+
+	stats := learner.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 route, got %d", len(stats))
+	}
+	if s := stats[0]; s.Tasks != 2 || s.Successes != 1 || s.Failures != 1 || s.AvgLatencyMs != 200 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRoutingLearnerTimesOutTasks(t *testing.T) {
+	cfg := config.Default()
+	learner := routing.NewLearner(cfg)
+	now := time.Now()
+
+	sendTask(learner, "sales", "fraud-1", now.Add(-2*cfg.TaskTimeout))
+	sendTask(learner, "sales", "fraud-1", now)
+
+	expired := learner.ExpirePending(now)
+	if len(expired) != 1 || !expired[0].TimedOut {
+		t.Fatalf("expected 1 timed out task, got %+v", expired)
+	}
+	if s := learner.Stats()[0]; s.Timeouts != 1 || s.Value >= 0.5 {
+		t.Errorf("timeout should count as a failure and lower the value, got %+v", s)
+	}
+}
+
+func TestRoutingRankPrefersReliableRoutes(t *testing.T) {
+	cfg := config.Default()
+	learner := routing.NewLearner(cfg)
+	now := time.Now()
+
+	// inventory-1 has the stronger edge but fails; inventory-2 succeeds quickly
+	for i := 0; i < 10; i++ {
+		at := now.Add(time.Duration(i) * time.Second)
+		learner.Observe(types.NewResponse(sendTask(learner, "sales", "inventory-1", at), false, nil))
+		learner.Observe(types.NewResponse(sendTask(learner, "sales", "inventory-2", at), true, nil))
+	}
+
+	candidates := []types.RouteCandidate{
+		{AgentID: "inventory-1", Weight: 0.9},
+		{AgentID: "inventory-2", Weight: 0.6},
+	}
+
+	ranked := routing.Rank(cfg, "sales", candidates, learner.Stats())
+	if ranked[0].AgentID != "inventory-1" {
+		t.Errorf("without learning, expected the strongest edge first, got %s", ranked[0].AgentID)
+	}
+
+	cfg.RoutingLearning = true
+	cfg.RoutingExploration = 0
+	ranked = routing.Rank(cfg, "sales", candidates, learner.Stats())
+	if ranked[0].AgentID != "inventory-2" || ranked[0].Value == nil {
+		t.Errorf("with learning, expected the reliable route first, got %+v", ranked[0])
+	}
+	if selected, ok := routing.Select(cfg, ranked); !ok || selected.AgentID != "inventory-2" {
+		t.Errorf("expected inventory-2 selected, got %s", selected.AgentID)
+	}
+}
+
+func TestRoutingLoadBalance(t *testing.T) {
+	cfg := config.Default()
+	learner := routing.NewLearner(cfg)
+	now := time.Now()
+
+	// inventory-2 has three tasks in flight, inventory-1 answered its one
+	learner.Observe(types.NewResponse(sendTask(learner, "sales", "inventory-1", now), true, nil))
+	for i := 0; i < 3; i++ {
+		sendTask(learner, "sales", "inventory-2", now.Add(time.Duration(i)*time.Second))
+	}
+	load := learner.Load()
+	if load["inventory-1"] != 0 || load["inventory-2"] != 3 {
+		t.Fatalf("expected 3 tasks in flight to inventory-2 only, got %v", load)
+	}
+
+	candidates := []types.RouteCandidate{
+		{AgentID: "inventory-1", Weight: 0.5},
+		{AgentID: "inventory-2", Weight: 0.5, Load: load["inventory-2"]},
+		{AgentID: "inventory-3", Weight: 0.5},
+	}
+	ranked := routing.Rank(cfg, "sales", candidates, learner.Stats())
+	if selected, _ := routing.Select(cfg, ranked); selected.AgentID != "inventory-1" {
+		t.Errorf("without load balancing, expected the first ranked candidate, got %s", selected.AgentID)
+	}
+
+	// Equally strong agents share the tasks, the busy one a quarter as often
+	cfg.RoutingLoadBalance = true
+	picks := make(map[types.AgentID]int)
+	for i := 0; i < 9000; i++ {
+		selected, ok := routing.Select(cfg, ranked)
+		if !ok {
+			t.Fatal("expected a candidate selected")
+		}
+		picks[selected.AgentID]++
+	}
+	for _, id := range []types.AgentID{"inventory-1", "inventory-3"} {
+		if picks[id] < 3500 || picks[id] > 4500 {
+			t.Errorf("expected %s picked about 4000 times, got %d", id, picks[id])
+		}
+	}
+	if picks["inventory-2"] < 700 || picks["inventory-2"] > 1300 {
+		t.Errorf("expected the busy inventory-2 picked about 1000 times, got %d", picks["inventory-2"])
+	}
+
+	// Agents without an edge yet still get some tasks
+	picks = make(map[types.AgentID]int)
+	for i := 0; i < 1000; i++ {
+		selected, _ := routing.Balance([]types.RouteCandidate{{AgentID: "strong", Score: 0.9}, {AgentID: "new"}})
+		picks[selected.AgentID]++
+	}
+	if picks["new"] == 0 || picks["new"] > picks["strong"]/5 {
+		t.Errorf("expected the agent without an edge picked rarely but not never, got %v", picks)
+	}
+	if _, ok := routing.Balance(nil); ok {
+		t.Error("expected no candidate selected from none")
+	}
+}
+
+func TestRoutingPrefersLowLatency(t *testing.T) {
+	cfg := config.Default()
+	candidates := func() []types.RouteCandidate {
+		return []types.RouteCandidate{
+			{AgentID: "inventory-1", Score: 0.9, LatencyMs: 900},
+			{AgentID: "inventory-2", Score: 0.6, LatencyMs: 20},
+			{AgentID: "inventory-3", Score: 0.5},
+		}
+	}
+
+	if ranked := routing.PreferLowLatency(cfg, candidates()); ranked[0].AgentID != "inventory-1" || ranked[0].Score != 0.9 {
+		t.Errorf("without a latency target, expected the ranking unchanged, got %+v", ranked)
+	}
+
+	cfg.RoutingLatencyTarget = 100 * time.Millisecond
+	ranked := routing.PreferLowLatency(cfg, candidates())
+	if ranked[0].AgentID != "inventory-2" || ranked[1].AgentID != "inventory-3" || ranked[2].AgentID != "inventory-1" {
+		t.Errorf("expected the fast agent first and the slow one last, got %+v", ranked)
+	}
+	if ranked[2].Score < 0.089 || ranked[2].Score > 0.091 {
+		t.Errorf("expected the slow agent's score cut to a tenth, got %g", ranked[2].Score)
+	}
+}
+
+func TestReplayedTaskResponsesAreReplays(t *testing.T) {
+	task := &types.Message{ID: "replay-1", FromAgentID: "sales", ToAgentID: "inventory-1", Type: types.MessageTypeTask, ReplayOf: "sales-1"}
+	response := types.NewResponse(task, true, nil)
+	if !response.Replayed() || response.ReplayOf != "sales-1" {
+		t.Errorf("response to a replayed task should be a replay, got %+v", response)
+	}
+
+	task.ReplayOf = ""
+	if types.NewResponse(task, true, nil).Replayed() {
+		t.Error("response to a live task marked as a replay")
+	}
+}
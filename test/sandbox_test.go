@@ -0,0 +1,82 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestAgentSandbox(t *testing.T) {
+	now := time.Now()
+
+	agent := &types.Agent{ID: "agent-new-1"}
+	agent.EnterSandbox(72*time.Hour, now)
+	if !agent.Sandboxed(now.Add(time.Hour)) || agent.Sandboxed(now.Add(73*time.Hour)) {
+		t.Errorf("Expected a 72h probation, got %+v", agent.Sandbox)
+	}
+
+	attested := &types.Agent{ID: "agent-fraud-1", Attested: &types.AttestedClaims{Issuers: []string{"ops"}}}
+	attested.EnterSandbox(72*time.Hour, now)
+	if attested.Sandboxed(now) {
+		t.Error("Expected attested agents to skip the sandbox")
+	}
+	agent.EnterSandbox(0, now)
+	if agent.Sandboxed(now) {
+		t.Error("Expected a zero probation to disable the sandbox")
+	}
+
+	limiter := types.NewMessageLimiter(2)
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("agent-new-1", now) {
+			t.Fatalf("Expected message %d within the rate", i+1)
+		}
+	}
+	if limiter.Allow("agent-new-1", now.Add(time.Second)) {
+		t.Error("Expected the third message in a minute to be limited")
+	}
+	if !limiter.Allow("agent-new-1", now.Add(time.Minute)) {
+		t.Error("Expected the limit to reset after a minute")
+	}
+}
+
+func TestSandboxedVotesNonBinding(t *testing.T) {
+	bc, agents := bundleConsensus(3)
+	newcomer := types.NewAgentID()
+	bc.RegisterAgent(newcomer)
+	bc.SandboxAgent(newcomer, time.Now().Add(time.Hour))
+
+	proposal, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: agents[0],
+		Type:       types.ProposalTypeDecision,
+		Content:    map[string]any{"decision": "launch"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+
+	// The newcomer's support is recorded but does not count, nor does the newcomer
+	// raise the bar: 2 of the 3 binding voters reach quorum
+	if err := bc.Vote(proposal.ID, newcomer, true, 1.0); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	if err := bc.Vote(proposal.ID, agents[1], true, 0.8); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	tally := proposal.Tally()
+	if proposal.Status != types.ProposalStatusPending || tally.NonBinding != 1 || tally.Support != 1 {
+		t.Fatalf("Expected 1 binding and 1 non-binding vote on a pending proposal, got %s %+v", proposal.Status, tally)
+	}
+	if err := bc.Vote(proposal.ID, agents[2], true, 0.8); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	if proposal.Status != types.ProposalStatusAccepted {
+		t.Errorf("Expected quorum from the binding votes, got %s", proposal.Status)
+	}
+
+	bc.ReleaseSandbox(newcomer)
+	if bc.Sandboxed(newcomer) {
+		t.Error("Expected approval to release the sandbox")
+	}
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/webhook"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestSavedQueryCallback(t *testing.T) {
+	if err := (&types.SavedQuery{CallbackURL: "ftp://example.com"}).Validate(); err == nil {
+		t.Error("Expected a non-http callback to be rejected")
+	}
+
+	var delivery struct {
+		Event string           `json:"event"`
+		Data  types.QueryMatch `json:"data"`
+	}
+	var event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get("X-AgentMesh-Event")
+		json.NewDecoder(r.Body).Decode(&delivery)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	saved := &types.SavedQuery{
+		ID:          "query-1",
+		Name:        "pricing",
+		Query:       types.KnowledgeQuery{Topics: []string{"pricing"}},
+		CallbackURL: server.URL,
+	}
+	if err := saved.Validate(); err != nil {
+		t.Fatalf("Expected a valid saved query, got %v", err)
+	}
+
+	// Callbacks are not registered webhooks, so broadcasts never reach them
+	dispatcher := webhook.NewDispatcher(staticWebhooks{}, zap.NewNop())
+	insight := types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.9)
+	if !saved.Query.Matches(insight) {
+		t.Fatal("Expected the insight to match the saved query")
+	}
+	match := types.QueryMatch{QueryID: saved.ID, QueryName: saved.Name, Insight: *insight}
+	if err := dispatcher.Send(saved.Callback(), types.WebhookEventQueryMatch, match); err != nil {
+		t.Fatalf("Failed to send match: %v", err)
+	}
+	dispatcher.Wait()
+
+	if event != types.WebhookEventQueryMatch || delivery.Data.QueryID != "query-1" || delivery.Data.Insight.ID != insight.ID {
+		t.Errorf("Expected a query.matched delivery of the insight, got %q %+v", event, delivery)
+	}
+}
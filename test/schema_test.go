@@ -0,0 +1,103 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// TestSchemaRoundTrip checks records are stamped with the current version and read back unchanged
+func TestSchemaRoundTrip(t *testing.T) {
+	insight := types.NewInsight("agent-1", "research", types.InsightTypeCorrelation, "pricing", "Prices rise on Mondays", 0.8)
+	data, err := state.EncodeRecord("insight:"+string(insight.ID), insight)
+	if err != nil {
+		t.Fatalf("EncodeRecord failed: %v", err)
+	}
+
+	var stamped map[string]any
+	json.Unmarshal(data, &stamped)
+	if version := stamped["schema_version"]; version != float64(state.CurrentVersion(state.SchemaInsight)) {
+		t.Errorf("Expected schema_version %d, got %v", state.CurrentVersion(state.SchemaInsight), version)
+	}
+
+	var decoded types.Insight
+	if err := state.DecodeRecord("insight:"+string(insight.ID), data, &decoded); err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if decoded.ID != insight.ID || decoded.Content != insight.Content || decoded.Privacy != insight.Privacy {
+		t.Errorf("Insight did not round-trip: %+v", decoded)
+	}
+}
+
+// TestSchemaLazyMigration checks records written before versioning are upgraded on read
+func TestSchemaLazyMigration(t *testing.T) {
+	var insight types.Insight
+	legacy := `{"id":"insight-1","agent_id":"agent-1","topic":"pricing","content":"Legacy"}`
+	if err := state.DecodeRecord("insight:insight-1", []byte(legacy), &insight); err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if insight.Privacy != types.InsightPrivacyPublic {
+		t.Errorf("Expected a version 0 insight to default to public, got %q", insight.Privacy)
+	}
+	if insight.Tags == nil || insight.Metadata == nil || insight.Data == nil {
+		t.Errorf("Expected tags, metadata and data defaulted, got %+v", insight)
+	}
+	if insight.Content != "Legacy" {
+		t.Errorf("Expected fields kept, got content %q", insight.Content)
+	}
+
+	var snapshot types.GraphSnapshot
+	if err := state.DecodeRecord("graph:snapshot:latest", []byte(`{"timestamp":"2024-01-01T00:00:00Z"}`), &snapshot); err != nil {
+		t.Fatalf("DecodeRecord failed: %v", err)
+	}
+	if snapshot.Agents == nil || snapshot.Edges == nil {
+		t.Error("Expected a version 0 snapshot to get empty agent and edge maps")
+	}
+}
+
+// TestSchemaRejectsNewerVersion checks records from a newer binary are not read
+func TestSchemaRejectsNewerVersion(t *testing.T) {
+	newer := fmt.Sprintf(`{"schema_version":%d,"id":"insight-1","content":"From the future"}`, state.CurrentVersion(state.SchemaInsight)+1)
+
+	var insight types.Insight
+	err := state.DecodeRecord("insight:insight-1", []byte(newer), &insight)
+	if !errors.Is(err, state.ErrNewerSchema) {
+		t.Fatalf("Expected ErrNewerSchema, got %v", err)
+	}
+	if insight.Content != "" {
+		t.Errorf("Expected nothing decoded from a rejected record, got %+v", insight)
+	}
+}
+
+// TestSchemaUnknownPrefix checks keys outside the versioned prefixes are plain JSON
+func TestSchemaUnknownPrefix(t *testing.T) {
+	value := map[string]string{"hello": "world"}
+	data, err := state.EncodeRecord("cache:greeting", value)
+	if err != nil {
+		t.Fatalf("EncodeRecord failed: %v", err)
+	}
+	if string(data) != `{"hello":"world"}` {
+		t.Errorf("Expected no schema_version stamped, got %s", data)
+	}
+
+	// Not versioned, so a schema_version field is just data
+	var decoded map[string]any
+	if err := state.DecodeRecord("cache:greeting", []byte(`{"schema_version":99,"hello":"world"}`), &decoded); err != nil {
+		t.Fatalf("Expected unversioned keys decoded as is, got %v", err)
+	}
+	if decoded["hello"] != "world" || decoded["schema_version"] != float64(99) {
+		t.Errorf("Unexpected decoded value %v", decoded)
+	}
+
+	// Values that are not objects only fail under a versioned prefix
+	if _, err := state.EncodeRecord("counter:messages", 42); err != nil {
+		t.Errorf("Expected a plain value encoded under an unversioned key, got %v", err)
+	}
+	if _, err := state.EncodeRecord("agent:agent-1", 42); err == nil {
+		t.Error("Expected a non-object agent record to be rejected")
+	}
+}
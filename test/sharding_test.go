@@ -0,0 +1,116 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+// sparseGraph builds a graph of n agents, each with edges to the next degree
+// agents in a ring, without the full mesh AddAgent would create
+func sparseGraph(config *types.Config, n, degree int) (*topology.Graph, []types.EdgeID) {
+	now := time.Now()
+	snapshot := &types.GraphSnapshot{
+		Agents: make(map[types.AgentID]*types.Agent, n),
+		Edges:  make(map[types.EdgeID]*types.Edge, n*degree),
+	}
+	ids := make([]types.AgentID, n)
+	for i := range ids {
+		ids[i] = types.AgentID(fmt.Sprintf("agent-%05d", i))
+		snapshot.Agents[ids[i]] = &types.Agent{ID: ids[i], Role: "worker", Status: types.AgentStatusActive, CreatedAt: now}
+	}
+
+	edgeIDs := make([]types.EdgeID, 0, n*degree)
+	for i, source := range ids {
+		for d := 1; d <= degree; d++ {
+			target := ids[(i+d)%n]
+			id := types.NewEdgeID(source, target)
+			snapshot.Edges[id] = &types.Edge{ID: id, SourceID: source, TargetID: target, Weight: 0.5, CreatedAt: now, LastUsed: now}
+			edgeIDs = append(edgeIDs, id)
+		}
+	}
+
+	graph := topology.NewGraph(config)
+	graph.Restore(snapshot)
+	return graph, edgeIDs
+}
+
+func TestGraphConcurrentReinforcement(t *testing.T) {
+	config := &types.Config{InitialEdgeWeight: 0.5, ReinforcementAmount: 0.001}
+	graph, edgeIDs := sparseGraph(config, 200, 3)
+
+	// Every worker reinforces every edge, and creates the same new edges
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, id := range edgeIDs {
+				if err := graph.ReinforceEdge(id, types.MessageTypeTask); err != nil {
+					errs <- err
+					return
+				}
+			}
+			for i := 0; i < 50; i++ {
+				id := types.NewEdgeID(types.AgentID(fmt.Sprintf("agent-%05d", i)), types.AgentID(fmt.Sprintf("agent-%05d", 199-i)))
+				if err := graph.ReinforceEdge(id, types.MessageTypeTask); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed to reinforce edge: %v", err)
+	}
+
+	if got, want := graph.GetEdgeCount(), len(edgeIDs)+50; got != want {
+		t.Errorf("Expected %d edges, got %d", want, got)
+	}
+	for _, id := range []types.EdgeID{edgeIDs[0], types.NewEdgeID("agent-00000", "agent-00199")} {
+		edge, err := graph.GetEdge(id)
+		if err != nil {
+			t.Fatalf("Failed to get edge: %v", err)
+		}
+		if usage := edge.Clone().Usage; usage != workers {
+			t.Errorf("Expected edge %s used %d times, got %d", id, workers, usage)
+		}
+	}
+	if neighbors := graph.GetNeighbors("agent-00000", 0); len(neighbors) != 4 {
+		t.Errorf("Expected 4 neighbors of agent-00000, got %v", neighbors)
+	}
+
+	if err := graph.RemoveAgent("agent-00199"); err != nil {
+		t.Fatalf("Failed to remove agent: %v", err)
+	}
+	if _, err := graph.GetEdgeBetween("agent-00000", "agent-00199"); err == nil {
+		t.Error("Expected edges to a removed agent to be removed")
+	}
+}
+
+// BenchmarkReinforceEdge10kAgents measures reinforcement throughput of a graph
+// of 10,000 agents with messages between random pairs from every CPU
+func BenchmarkReinforceEdge10kAgents(b *testing.B) {
+	config := &types.Config{InitialEdgeWeight: 0.5, ReinforcementAmount: 0.001}
+	graph, edgeIDs := sparseGraph(config, 10000, 8)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+		for pb.Next() {
+			if err := graph.ReinforceEdge(edgeIDs[random.Intn(len(edgeIDs))], types.MessageTypeTask); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestSharingPolicy(t *testing.T) {
+	now := time.Now()
+	policy := types.SharingPolicy{
+		{ID: "a1", Provider: "sales", Consumer: "support", InsightTypes: []types.InsightType{types.InsightTypePricingIssue}},
+		{ID: "a2", Provider: "sales", Consumer: "marketing", ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	pricing := types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.8)
+	fraud := types.NewInsight("agent-sales-1", "sales", types.InsightTypeFraudPattern, "payments", "Card testing", 0.9)
+	feedback := types.NewInsight("agent-ops-1", "ops", types.InsightTypeCustomerFeedback, "delivery", "Late parcels", 0.7)
+
+	if !policy.Allows(pricing, "support", now) || policy.Allows(fraud, "support", now) {
+		t.Error("Expected support to receive sales pricing insights but not fraud data")
+	}
+	if policy.Allows(pricing, "marketing", now) {
+		t.Error("Expected an expired agreement to share nothing")
+	}
+	if !policy.Allows(fraud, "sales", now) {
+		t.Error("Expected a team to always see its own insights")
+	}
+	if !policy.Allows(feedback, "marketing", now) {
+		t.Error("Expected teams without agreements to share with everyone")
+	}
+
+	if audience := policy.Audience(pricing, now); len(audience) != 2 || audience[0] != "sales" || audience[1] != "support" {
+		t.Errorf("Expected the pricing push to reach sales and support, got %v", audience)
+	}
+	if audience := policy.Audience(feedback, now); audience != nil {
+		t.Errorf("Expected no audience for an ungoverned team, got %v", audience)
+	}
+
+	if err := (&types.SharingAgreement{Provider: "sales", Consumer: "sales"}).Validate(); err == nil {
+		t.Error("Expected an agreement with itself to be rejected")
+	}
+}
+
+func TestPushAudience(t *testing.T) {
+	// The audience survives the JSON round trip through Kafka
+	data, _ := json.Marshal(&types.Message{Payload: map[string]any{"audience": []string{"sales", "support"}}})
+	var msg types.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if !types.PushedTo(&msg, "support") || types.PushedTo(&msg, "fraud") {
+		t.Error("Expected the push to reach only its audience")
+	}
+	if !types.PushedTo(&types.Message{Payload: map[string]any{}}, "fraud") {
+		t.Error("Expected pushes without an audience to reach every team")
+	}
+}
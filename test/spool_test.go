@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestSpoolFlush(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := agent.OpenSpool(dir, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to open spool: %v", err)
+	}
+	for _, id := range []string{"m1", "m2", "m1"} {
+		if err := spool.Enqueue("messages", &types.Message{ID: id}); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	if stats := spool.Stats(); stats.Queued != 2 || stats.Duplicates != 1 {
+		t.Fatalf("Expected 2 queued messages and 1 duplicate, got %+v", stats)
+	}
+
+	// The broker is still down: nothing is lost
+	down := func(ctx context.Context, topic string, msg *types.Message) error { return errors.New("broker unreachable") }
+	if flushed, err := spool.Flush(context.Background(), down); err == nil || flushed != 0 {
+		t.Fatalf("Expected the flush to fail, got %d flushed", flushed)
+	}
+
+	// A restarted agent picks the spool up and flushes it in order
+	spool, err = agent.OpenSpool(dir, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen spool: %v", err)
+	}
+	var sent []string
+	up := func(ctx context.Context, topic string, msg *types.Message) error {
+		sent = append(sent, msg.ID)
+		return nil
+	}
+	if flushed, err := spool.Flush(context.Background(), up); err != nil || flushed != 2 || sent[0] != "m1" || sent[1] != "m2" {
+		t.Fatalf("Expected m1 and m2 to be flushed in order, got %v (%v)", sent, err)
+	}
+
+	// A retry of a delivered message is not sent twice
+	spool.Enqueue("messages", &types.Message{ID: "m2"})
+	if stats := spool.Stats(); stats.Queued != 0 || stats.Flushed != 2 || stats.Duplicates != 1 {
+		t.Errorf("Expected the retry to be dropped as a duplicate, got %+v", stats)
+	}
+}
+
+func TestSpoolLimits(t *testing.T) {
+	spool, err := agent.OpenSpool(t.TempDir(), 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to open spool: %v", err)
+	}
+	for _, id := range []string{"m1", "m2", "m3"} {
+		spool.Enqueue("messages", &types.Message{ID: id})
+	}
+
+	var sent []string
+	spool.Flush(context.Background(), func(ctx context.Context, topic string, msg *types.Message) error {
+		sent = append(sent, msg.ID)
+		return nil
+	})
+	if len(sent) != 2 || sent[0] != "m2" || spool.Stats().Dropped != 1 {
+		t.Errorf("Expected the oldest message to be dropped, got %v (%+v)", sent, spool.Stats())
+	}
+
+	small, _ := agent.OpenSpool(t.TempDir(), 0, 64)
+	if err := small.Enqueue("messages", &types.Message{ID: "big", Payload: map[string]any{"text": "more than sixty-four bytes of payload"}}); err == nil {
+		t.Error("Expected a message larger than the spool to be rejected")
+	}
+}
+
+func TestSpoolFlushDropsRejected(t *testing.T) {
+	spool, err := agent.OpenSpool(t.TempDir(), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to open spool: %v", err)
+	}
+	for _, id := range []string{"m1", "too-large", "m3"} {
+		spool.Enqueue("messages", &types.Message{ID: id})
+	}
+
+	// A message the broker refuses does not hold back those behind it
+	var sent []string
+	publish := func(ctx context.Context, topic string, msg *types.Message) error {
+		if msg.ID == "too-large" {
+			return fmt.Errorf("failed to write message: %w", kafka.WriteErrors{kafka.MessageSizeTooLarge})
+		}
+		sent = append(sent, msg.ID)
+		return nil
+	}
+	if flushed, err := spool.Flush(context.Background(), publish); err != nil || flushed != 2 || fmt.Sprint(sent) != "[m1 m3]" {
+		t.Fatalf("Expected m1 and m3 flushed past the rejected message, got %v (%v)", sent, err)
+	}
+	if stats := spool.Stats(); stats.Queued != 0 || stats.Rejected != 1 || stats.Flushed != 2 {
+		t.Errorf("Expected the rejected message dropped and counted, got %+v", stats)
+	}
+
+	cases := []struct {
+		err       error
+		permanent bool
+	}{
+		{nil, false},
+		{errors.New("dial tcp: connection refused"), false},
+		{context.DeadlineExceeded, false},
+		{kafka.WriteErrors{kafka.LeaderNotAvailable}, false},
+		{kafka.WriteErrors{kafka.MessageSizeTooLarge}, true},
+		{kafka.MessageTooLargeError{}, true},
+		{fmt.Errorf("failed to marshal message: %w", &json.UnsupportedValueError{Str: "NaN"}), true},
+	}
+	for _, tc := range cases {
+		if got := messaging.Permanent(tc.err); got != tc.permanent {
+			t.Errorf("Expected Permanent(%v) to be %v", tc.err, tc.permanent)
+		}
+	}
+}
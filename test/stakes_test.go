@@ -0,0 +1,109 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestVoteStakeParsing(t *testing.T) {
+	vote, err := consensus.ParseVoteRequest(map[string]any{"vote": map[string]any{
+		"proposal_id": "p-1", "voter_id": "agent-finance-1", "support": true, "intensity": 0.8, "stake": 25.0,
+	}})
+	if err != nil {
+		t.Fatalf("Failed to parse vote: %v", err)
+	}
+	if vote.Stake != 25 {
+		t.Errorf("Expected a stake of 25, got %g", vote.Stake)
+	}
+
+	invalid := []map[string]any{
+		{"proposal_id": "p-1", "voter_id": "agent-finance-1", "support": true, "intensity": 0.8, "stake": -1.0},
+		{"proposal_id": "p-1", "voter_id": "agent-finance-1", "support": true, "intensity": 0.8, "stake": "25"},
+		{"proposal_id": "p-1", "voter_id": "agent-finance-1", "choice": "abstain", "stake": 5.0},
+		{"bundle_id": "b-1", "voter_id": "agent-finance-1", "support": true, "intensity": 0.8, "stake": 5.0},
+	}
+	for _, data := range invalid {
+		if _, err := consensus.ParseVoteRequest(map[string]any{"vote": data}); err == nil {
+			t.Errorf("Expected vote %v to be rejected", data)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.VoteStakes, cfg.StakeSlashRate = true, 1.5
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "STAKE_SLASH_RATE") {
+		t.Errorf("Expected a slash rate above 1 to be flagged, got %v", problems)
+	}
+}
+
+func TestVoteStakeSettlement(t *testing.T) {
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Hour}, zap.NewNop())
+	voters := []types.AgentID{"agent-finance-1", "agent-ops-1", "agent-sales-1"}
+	for _, id := range voters {
+		bc.RegisterAgent(id)
+	}
+	proposal, err := bc.Propose(&consensus.ProposalRequest{
+		ProposerID: "agent-sales-1",
+		Type:       types.ProposalTypeDecision,
+		Content:    map[string]any{"action": "restock", "intent": map[string]any{"metric": "stockout_rate", "comparator": "<", "target": 0.02}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+
+	if err := bc.CastVote(&consensus.VoteRequest{ProposalID: proposal.ID, VoterID: "agent-ops-1", Choice: types.VoteChoiceAbstain, Stake: 5}); err == nil {
+		t.Error("Expected an abstention with a stake to be rejected")
+	}
+
+	votes := []*consensus.VoteRequest{
+		{ProposalID: proposal.ID, VoterID: "agent-finance-1", Support: false, Intensity: 0.7, Stake: 20},
+		{ProposalID: proposal.ID, VoterID: "agent-ops-1", Support: true, Intensity: 0.9, Stake: 10},
+		{ProposalID: proposal.ID, VoterID: "agent-sales-1", Support: true, Intensity: 0.9},
+	}
+	for _, vote := range votes {
+		if err := bc.CastVote(vote); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if proposal.Status != types.ProposalStatusAccepted {
+		t.Fatalf("Expected the proposal accepted, got %s", proposal.Status)
+	}
+	if tally := proposal.Tally(); tally.StakedFor != 10 || tally.StakedAgainst != 20 {
+		t.Errorf("Expected 10 staked for and 20 against, got %+v", tally)
+	}
+
+	// Supporters were right about a decision that achieved its intent
+	settled := types.SettleStakes(proposal, types.DecisionVerdictAchieved, 0.5, 1.0)
+	if len(settled) != 2 {
+		t.Fatalf("Expected the two stakes settled, got %+v", settled)
+	}
+	if finance := settled[0]; finance.AgentID != "agent-finance-1" || finance.Correct || finance.Change != -20 {
+		t.Errorf("Expected the stake against slashed, got %+v", finance)
+	}
+	if ops := settled[1]; !ops.Correct || ops.Change != 5 {
+		t.Errorf("Expected the stake in support rewarded by half, got %+v", ops)
+	}
+	if settled := types.SettleStakes(proposal, types.DecisionVerdictNotAchieved, 0.5, 1.0); settled[0].Change != 10 || settled[1].Change != -10 {
+		t.Errorf("Expected the stakes reversed for a missed intent, got %+v", settled)
+	}
+
+	// Without a final verdict stakes are only returned
+	for _, verdict := range []types.DecisionVerdict{types.DecisionVerdictNoData, ""} {
+		for _, settlement := range types.SettleStakes(proposal, verdict, 0.5, 1.0) {
+			if settlement.Change != 0 {
+				t.Errorf("Expected no change for verdict %q, got %+v", verdict, settlement)
+			}
+		}
+	}
+
+	account := types.StakeAccount{AgentID: "agent-ops-1", Balance: 100, Locked: 30}
+	if account.Available() != 70 {
+		t.Errorf("Expected 70 available, got %g", account.Available())
+	}
+}
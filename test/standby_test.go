@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/agent"
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestStandbyTakesOverAfterMissedHeartbeats(t *testing.T) {
+	start := time.Now()
+	standby := agent.NewStandby("agent-primary", 90*time.Second, start)
+
+	handled := &types.Message{ID: "handled", ToAgentID: "agent-primary", Timestamp: start.Add(10 * time.Second)}
+	missed := &types.Message{ID: "missed", ToAgentID: "agent-primary", Timestamp: start.Add(40 * time.Second)}
+	for _, msg := range []*types.Message{handled, missed} {
+		if standby.Receive(msg) {
+			t.Fatalf("Expected %s held while the primary is alive", msg.ID)
+		}
+	}
+	standby.Heartbeat(start.Add(30 * time.Second))
+
+	if _, tookOver := standby.Check(start.Add(60 * time.Second)); tookOver {
+		t.Fatal("Expected no takeover before the timeout")
+	}
+	held, tookOver := standby.Check(start.Add(2 * time.Minute))
+	if !tookOver || !standby.Active() {
+		t.Fatal("Expected a takeover once the primary was silent for the timeout")
+	}
+	if len(held) != 1 || held[0].ID != "missed" {
+		t.Errorf("Expected only the message since the last heartbeat handed over, got %v", held)
+	}
+	if !standby.Receive(&types.Message{ID: "new", ToAgentID: "agent-primary"}) {
+		t.Error("Expected new messages to the primary handled after the takeover")
+	}
+
+	// A heartbeat from before the takeover, e.g. read late, does not end it
+	if standby.Heartbeat(start.Add(50 * time.Second)) {
+		t.Error("Expected a heartbeat sent before the takeover not to end it")
+	}
+	if !standby.Heartbeat(start.Add(3*time.Minute)) || standby.Active() {
+		t.Error("Expected the standby to step back once the primary is back")
+	}
+}
+
+func TestStandbyOfCriticalAgent(t *testing.T) {
+	cfg := config.Default()
+	cfg.CriticalRoles = []string{"fraud"}
+	if !cfg.IsCriticalRole("fraud") || cfg.IsCriticalRole("sales") {
+		t.Fatalf("Expected only fraud critical, got %v", cfg.CriticalRoles)
+	}
+
+	graph := topology.NewGraph(cfg)
+	primary := &types.Agent{ID: "agent-fraud-1", Role: "fraud", Status: types.AgentStatusActive}
+	standby := &types.Agent{ID: "agent-fraud-2", Role: "fraud", Status: types.AgentStatusActive, StandbyFor: primary.ID}
+	for _, a := range []*types.Agent{primary, standby} {
+		if err := graph.AddAgent(a); err != nil {
+			t.Fatalf("Failed to add agent: %v", err)
+		}
+	}
+
+	if got := graph.StandbyOf(primary.ID); got == nil || got.ID != standby.ID {
+		t.Errorf("Expected %s standing by for %s, got %v", standby.ID, primary.ID, got)
+	}
+	if got := graph.StandbyOf(standby.ID); got != nil {
+		t.Errorf("Expected no standby for %s, got %s", standby.ID, got.ID)
+	}
+
+	standby.Status = types.AgentStatusOffline
+	if got := graph.StandbyOf(primary.ID); got != nil {
+		t.Errorf("Expected an offline standby not to count, got %s", got.ID)
+	}
+}
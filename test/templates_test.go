@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+	"go.uber.org/zap"
+)
+
+func TestProposalTemplateRender(t *testing.T) {
+	for _, template := range consensus.BuiltinTemplates() {
+		if err := template.Validate(); err != nil {
+			t.Errorf("Built-in template %s is invalid: %v", template.Name, err)
+		}
+	}
+
+	template, err := consensus.LookupTemplate(context.Background(), nil, "large_order_approval")
+	if err != nil {
+		t.Fatalf("Failed to look up built-in template: %v", err)
+	}
+
+	content, err := template.Render(map[string]any{"order_id": "ORD-7", "amount": 25000.0})
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if content["amount"] != 25000.0 || content["order_id"] != "ORD-7" {
+		t.Errorf("Expected typed parameter values, got %v and %v", content["amount"], content["order_id"])
+	}
+	if content["priority"] != "high" {
+		t.Errorf("Expected default priority high, got %v", content["priority"])
+	}
+	if content["description"] != "Approve order ORD-7 of $25000" {
+		t.Errorf("Unexpected description %q", content["description"])
+	}
+	if template.Content["order_id"] != "{{order_id}}" {
+		t.Error("Rendering must not modify the template")
+	}
+
+	invalid := []map[string]any{
+		{"amount": 10.0},                                  // Missing required order_id
+		{"order_id": "ORD-7", "amount": "lots"},           // Mistyped amount
+		{"order_id": "ORD-7", "amount": 10.0, "extra": 1}, // Unknown parameter
+	}
+	for _, params := range invalid {
+		if _, err := template.Render(params); err == nil {
+			t.Errorf("Expected params %v to be rejected", params)
+		}
+	}
+
+	undeclared := &types.ProposalTemplate{
+		Name:    "broken",
+		Type:    types.ProposalTypeAction,
+		Content: map[string]any{"sku": "{{sku}}"},
+	}
+	if err := undeclared.Validate(); err == nil {
+		t.Error("Expected a template using an undeclared parameter to be invalid")
+	}
+}
+
+func TestTemplatedProposalKeepsWaggle(t *testing.T) {
+	request, err := consensus.ParseProposalRequest(map[string]any{
+		"proposal": map[string]any{
+			"proposer_id": "agent-sales-1",
+			"template":    "price_change",
+			"params":      map[string]any{"product": "Widget", "old_price": 10.0, "new_price": 12.0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse templated request: %v", err)
+	}
+	if request.Template != "price_change" || request.Content != nil {
+		t.Fatalf("Expected an uninstantiated template request, got %+v", request)
+	}
+
+	bc := consensus.NewBeeConsensus(&types.Config{QuorumThreshold: 0.6, ProposalTimeout: time.Minute}, zap.NewNop())
+	if _, err := bc.Propose(request); err == nil {
+		t.Error("Expected an uninstantiated request to be rejected")
+	}
+
+	template, _ := consensus.LookupTemplate(context.Background(), nil, request.Template)
+	request, err = consensus.Instantiate(template, request.ProposerID, request.Params)
+	if err != nil {
+		t.Fatalf("Failed to instantiate: %v", err)
+	}
+	proposal, err := bc.Propose(request)
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	if proposal.Type != types.ProposalTypeAction || proposal.Template != "price_change" {
+		t.Errorf("Expected an action proposal from price_change, got %s from %q", proposal.Type, proposal.Template)
+	}
+	if proposal.Waggle != *template.Waggle {
+		t.Errorf("Expected the template's waggle %+v, got %+v", *template.Waggle, proposal.Waggle)
+	}
+	if proposal.Content["new_price"] != 12.0 {
+		t.Errorf("Expected new_price 12, got %v", proposal.Content["new_price"])
+	}
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestInsightTiering(t *testing.T) {
+	t.Setenv("INSIGHT_TIERING", "*=sample:0.1, pricing=count<0.5, fraud=full")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	policies := cfg.InsightTiering
+	if len(policies) != 3 || policies["pricing"].Threshold != 0.5 || policies["*"].Threshold != types.DefaultTierThreshold || policies["*"].SampleRate != 0.1 {
+		t.Fatalf("Unexpected policies: %+v", policies)
+	}
+
+	insight := func(topic string, confidence float64) *types.Insight {
+		return types.NewInsight("agent-sales-1", "sales", types.InsightTypeBehaviorPattern, topic, "content", confidence)
+	}
+
+	// High confidence and full-tier topics are always stored
+	for _, in := range []*types.Insight{insight("pricing", 0.6), insight("refunds", 0.9), insight("fraud", 0.1)} {
+		if store, summarize := policies.Tier(in); !store || summarize {
+			t.Errorf("Expected %s at %g to be stored individually", in.Topic, in.Confidence)
+		}
+	}
+	if store, summarize := policies.Tier(insight("pricing", 0.4)); store || !summarize {
+		t.Error("Expected low-confidence pricing insights only counted")
+	}
+
+	// Sampling keeps about the configured share, the same one every time
+	summary := &types.InsightTierSummary{Topic: "refunds"}
+	for i := 0; i < 2000; i++ {
+		in := insight("refunds", 0.2)
+		in.ID = types.InsightID(fmt.Sprintf("insight-%d", i))
+		store, summarize := policies.Tier(in)
+		if again, _ := policies.Tier(in); again != store || !summarize {
+			t.Fatal("Expected the same sampling decision for the same insight")
+		}
+		summary.Add(in, store)
+	}
+	if summary.Count != 2000 || summary.Sampled < 140 || summary.Sampled > 260 {
+		t.Errorf("Expected about 200 of 2000 insights sampled, got %d of %d", summary.Sampled, summary.Count)
+	}
+	if summary.MeanConfidence < 0.199 || summary.MeanConfidence > 0.201 || summary.ByRole["sales"] != 2000 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	for _, invalid := range []string{"pricing", "pricing=sample", "pricing=sample:2", "pricing=count:0.1", "pricing=drop", "pricing=count<0"} {
+		if _, err := types.ParseTieringPolicies(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
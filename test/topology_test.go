@@ -1,9 +1,15 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
 	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 	"go.uber.org/zap"
@@ -43,6 +49,7 @@ func TestGraphAddAgent(t *testing.T) {
 func TestGraphFullMeshCreation(t *testing.T) {
 	config := &types.Config{
 		InitialEdgeWeight: 0.5,
+		EdgeBootstrap:     types.EdgeBootstrapFullMesh,
 	}
 	graph := topology.NewGraph(config)
 
@@ -59,8 +66,8 @@ func TestGraphFullMeshCreation(t *testing.T) {
 		graph.AddAgent(agents[i])
 	}
 
-	// Full mesh for 4 agents = 4 * 3 = 12 bidirectional edges
-	expectedEdges := 4 * 3
+	// Full mesh for 4 agents = 4 self-loops + 4 * 3 directed edges
+	expectedEdges := 4 + 4*3
 	if graph.GetEdgeCount() != expectedEdges {
 		t.Errorf("Expected %d edges (full mesh), got %d", expectedEdges, graph.GetEdgeCount())
 	}
@@ -74,10 +81,110 @@ func TestGraphFullMeshCreation(t *testing.T) {
 	}
 }
 
+func TestLazyEdgeBootstrap(t *testing.T) {
+	config := &types.Config{
+		InitialEdgeWeight:   0.5,
+		ReinforcementAmount: 0.1,
+	}
+	graph := topology.NewGraph(config)
+
+	agent1 := &types.Agent{ID: types.NewAgentID(), Name: "A1", Role: "test", Status: types.AgentStatusActive, CreatedAt: time.Now()}
+	agent2 := &types.Agent{ID: types.NewAgentID(), Name: "A2", Role: "test", Status: types.AgentStatusActive, CreatedAt: time.Now()}
+	graph.AddAgent(agent1)
+	graph.AddAgent(agent2)
+
+	if graph.GetEdgeCount() != 0 {
+		t.Fatalf("Expected no edges before any message, got %d", graph.GetEdgeCount())
+	}
+
+	// The first message creates the edge at the initial weight, reinforced
+	edgeID := types.NewEdgeID(agent1.ID, agent2.ID)
+	if err := graph.ReinforceEdge(edgeID, types.MessageTypeTask); err != nil {
+		t.Fatalf("Failed to reinforce edge: %v", err)
+	}
+	edge, err := graph.GetEdge(edgeID)
+	if err != nil {
+		t.Fatalf("Expected the first message to create the edge: %v", err)
+	}
+	if weight := edge.GetWeight(); weight != 0.5+0.1 {
+		t.Errorf("Expected weight %f, got %f", 0.5+0.1, weight)
+	}
+	if graph.GetEdgeCount() != 1 {
+		t.Errorf("Expected only the used edge, got %d edges", graph.GetEdgeCount())
+	}
+}
+
+func TestUndirectedEdges(t *testing.T) {
+	cfg := &types.Config{
+		InitialEdgeWeight:   0.5,
+		ReinforcementAmount: 0.1,
+		EdgeBootstrap:       types.EdgeBootstrapFullMesh,
+		EdgeMode:            types.EdgeModeUndirected,
+	}
+	graph := topology.NewGraph(cfg)
+	for _, id := range []types.AgentID{"sales", "inventory", "warehouse", "billing"} {
+		graph.AddAgent(&types.Agent{ID: id, Status: types.AgentStatusActive})
+	}
+
+	// Full mesh for 4 agents = 4 self-loops + 4 * 3 / 2 pairs
+	if count := graph.GetEdgeCount(); count != 4+6 {
+		t.Fatalf("Expected 10 edges (one per pair), got %d", count)
+	}
+
+	// Traffic either way reinforces the pair's one edge
+	for _, edgeID := range []types.EdgeID{types.NewEdgeID("warehouse", "sales"), types.NewEdgeID("sales", "warehouse")} {
+		if err := graph.ReinforceEdge(edgeID, types.MessageTypeTask); err != nil {
+			t.Fatalf("Failed to reinforce %s: %v", edgeID, err)
+		}
+	}
+	edge, err := graph.GetEdgeBetween("warehouse", "sales")
+	if err != nil {
+		t.Fatalf("Expected the pair's edge: %v", err)
+	}
+	if edge.ID != types.NewEdgeID("sales", "warehouse") || edge.Usage != 2 || math.Abs(edge.GetWeight()-0.7) > 1e-9 {
+		t.Errorf("Expected sales->warehouse reinforced twice, got %+v", edge)
+	}
+	if count := graph.GetEdgeCount(); count != 10 {
+		t.Errorf("Expected no new edges, got %d", count)
+	}
+
+	// Neighbors and paths follow edges both ways
+	neighbors := graph.GetNeighbors("warehouse", 0.6)
+	if len(neighbors) != 1 || neighbors[0] != "sales" {
+		t.Errorf("Expected sales as warehouse's strong neighbor, got %v", neighbors)
+	}
+	path, err := graph.FindPath("warehouse", "sales")
+	if err != nil || path.Fallback || path.Hops != 1 {
+		t.Errorf("Expected the direct path back over the pair's edge, got %+v (%v)", path, err)
+	}
+
+	// A directed snapshot restores to one edge per pair, the stronger direction
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	for _, id := range []types.AgentID{"sales", "billing"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	for source, weight := range map[types.AgentID]float64{"sales": 0.3, "billing": 0.8} {
+		target := types.AgentID("sales")
+		if source == target {
+			target = "billing"
+		}
+		snapshot.Edges[types.NewEdgeID(source, target)] = &types.Edge{ID: types.NewEdgeID(source, target), SourceID: source, TargetID: target, Weight: weight}
+	}
+	graph.Restore(snapshot)
+	if edge, err := graph.GetEdgeBetween("sales", "billing"); err != nil || graph.GetEdgeCount() != 1 || edge.GetWeight() != 0.8 {
+		t.Errorf("Expected billing->sales kept at 0.8 as the only edge, got %d edges (%v)", graph.GetEdgeCount(), err)
+	}
+	// Measured against the undirected full mesh: 2 self-loops and the one pair
+	if stats := graph.GetSnapshot().Stats; !stats.Undirected || math.Abs(stats.Density-1.0/3) > 1e-9 {
+		t.Errorf("Expected the pair's one edge to be a third of the full mesh, got %+v", stats)
+	}
+}
+
 func TestEdgeReinforcement(t *testing.T) {
 	config := &types.Config{
 		InitialEdgeWeight:   0.5,
 		ReinforcementAmount: 0.1,
+		EdgeBootstrap:       types.EdgeBootstrapFullMesh,
 	}
 	graph := topology.NewGraph(config)
 
@@ -97,7 +204,7 @@ func TestEdgeReinforcement(t *testing.T) {
 	}
 
 	// Reinforce edge
-	graph.ReinforceEdge(edgeID)
+	graph.ReinforceEdge(edgeID, types.MessageTypeTask)
 	edge, _ = graph.GetEdge(edgeID)
 	newWeight := edge.GetWeight()
 
@@ -108,7 +215,7 @@ func TestEdgeReinforcement(t *testing.T) {
 
 	// Test saturation at 1.0
 	for i := 0; i < 10; i++ {
-		graph.ReinforceEdge(edgeID)
+		graph.ReinforceEdge(edgeID, types.MessageTypeTask)
 	}
 	edge, _ = graph.GetEdge(edgeID)
 	if edge.GetWeight() > 1.0 {
@@ -120,6 +227,7 @@ func TestEdgeDecay(t *testing.T) {
 	config := &types.Config{
 		InitialEdgeWeight: 0.5,
 		DecayRate:         0.1,
+		EdgeBootstrap:     types.EdgeBootstrapFullMesh,
 	}
 	graph := topology.NewGraph(config)
 
@@ -159,6 +267,7 @@ func TestEdgePruning(t *testing.T) {
 		InitialEdgeWeight: 0.15,
 		PruneThreshold:    0.1,
 		DecayRate:         0.1,
+		EdgeBootstrap:     types.EdgeBootstrapFullMesh,
 	}
 	graph := topology.NewGraph(config)
 
@@ -186,6 +295,113 @@ func TestEdgePruning(t *testing.T) {
 	}
 }
 
+func TestPruningStrategies(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	snapshot := func() *types.GraphSnapshot {
+		snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+		for _, id := range []types.AgentID{"coordinator", "sales-1", "sales-2", "sales-3"} {
+			snapshot.Agents[id] = &types.Agent{ID: id}
+		}
+		link := func(source, target types.AgentID, weight float64, lastUsed time.Time) {
+			id := types.NewEdgeID(source, target)
+			snapshot.Edges[id] = &types.Edge{ID: id, SourceID: source, TargetID: target, Weight: weight, LastUsed: lastUsed, CreatedAt: now.Add(-3 * time.Hour)}
+		}
+		link("coordinator", "sales-1", 0.9, now.Add(-time.Minute))
+		link("coordinator", "sales-2", 0.8, now.Add(-time.Minute))
+		link("coordinator", "sales-3", 0.7, now.Add(-time.Minute))
+		link("sales-1", "sales-2", 0.5, now.Add(-2*time.Hour))
+		link("sales-2", "sales-3", 0.3, time.Time{}) // Never used
+		return snapshot
+	}
+	prune := func(cfg *types.Config, strategy topology.PruningStrategy) []types.EdgeID {
+		graph := topology.NewGraph(cfg)
+		graph.Restore(snapshot())
+		graph.SetClock(func() time.Time { return now })
+		if strategy != nil {
+			graph.SetPruningStrategy(strategy)
+		}
+		return graph.PruneWeakEdges()
+	}
+
+	cfg := config.Default()
+	cfg.PruneMinDegree = 0
+	if pruned := prune(cfg, nil); len(pruned) != 0 {
+		t.Errorf("Expected no edge below the threshold pruned, got %v", pruned)
+	}
+
+	// Each agent keeps its strongest edge, so only the links between sales agents go
+	cfg.PruneStrategy, cfg.PruneTopK = types.PruneStrategyTopK, 1
+	pruned := prune(cfg, nil)
+	if len(pruned) != 2 || pruned[0] != types.NewEdgeID("sales-2", "sales-3") || pruned[1] != types.NewEdgeID("sales-1", "sales-2") {
+		t.Errorf("Expected the sales links pruned weakest first, got %v", pruned)
+	}
+	cfg.PruneTopK = 2
+	if pruned := prune(cfg, nil); len(pruned) != 0 {
+		t.Errorf("Expected every edge among its agents' two strongest kept, got %v", pruned)
+	}
+
+	// Edges unused for an hour go however strong, the never used one first
+	cfg.PruneStrategy, cfg.PruneMaxAge = types.PruneStrategyAge, time.Hour
+	pruned = prune(cfg, nil)
+	if len(pruned) != 2 || pruned[0] != types.NewEdgeID("sales-2", "sales-3") || pruned[1] != types.NewEdgeID("sales-1", "sales-2") {
+		t.Errorf("Expected the stale edges pruned oldest first, got %v", pruned)
+	}
+
+	// A strategy set on the graph replaces the configured one
+	cfg.PruneStrategy = types.PruneStrategyThreshold
+	if pruned := prune(cfg, topology.AgePruning{MaxAge: 150 * time.Minute}); len(pruned) != 1 || pruned[0] != types.NewEdgeID("sales-2", "sales-3") {
+		t.Errorf("Expected only the edge unused for 3 hours pruned, got %v", pruned)
+	}
+
+	cfg.PruneStrategy, cfg.PruneTopK = types.PruneStrategyTopK, 0
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "PRUNE_TOP_K") {
+		t.Errorf("Expected PRUNE_TOP_K flagged, got %v", problems)
+	}
+	cfg.PruneStrategy = "random"
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "PRUNE_STRATEGY") {
+		t.Errorf("Expected PRUNE_STRATEGY flagged, got %v", problems)
+	}
+}
+
+func TestPruneKeepsAgentsConnected(t *testing.T) {
+	config := &types.Config{
+		InitialEdgeWeight:   0.5,
+		ReinforcementAmount: 0.1,
+		DecayRate:           0.35,
+		PruneThreshold:      0.3,
+		PruneMinDegree:      1,
+	}
+	graph := topology.NewGraph(config)
+	for _, id := range []types.AgentID{"hub", "a", "b", "c"} {
+		graph.AddAgent(&types.Agent{ID: id, Role: "test", Status: types.AgentStatusActive})
+	}
+
+	// Every spoke decays below the threshold; only a→b stays above it
+	for _, id := range []types.AgentID{"a", "b", "c"} {
+		graph.ReinforceEdge(types.NewEdgeID(id, "hub"), types.MessageTypeTask)
+	}
+	graph.ReinforceEdge(types.NewEdgeID("a", "b"), types.MessageTypeTask)
+	graph.ReinforceEdge(types.NewEdgeID("a", "b"), types.MessageTypeTask)
+	graph.DecayAllEdges()
+
+	pruned := graph.PruneWeakEdges()
+	if len(pruned) != 2 {
+		t.Errorf("Expected 2 edges pruned, got %v", pruned)
+	}
+	// c's only edge is kept, a and b still have theirs
+	for _, edgeID := range []types.EdgeID{types.NewEdgeID("c", "hub"), types.NewEdgeID("a", "b")} {
+		if _, err := graph.GetEdge(edgeID); err != nil {
+			t.Errorf("Expected %s kept: %v", edgeID, err)
+		}
+	}
+
+	config.PruneMinDegree = 0
+	graph.PruneWeakEdges()
+	if _, err := graph.GetEdge(types.NewEdgeID("c", "hub")); err == nil {
+		t.Error("Expected the weak edge pruned without protection")
+	}
+}
+
 func TestSlimeMoldTopology(t *testing.T) {
 	config := &types.Config{
 		InitialEdgeWeight:   0.5,
@@ -193,6 +409,7 @@ func TestSlimeMoldTopology(t *testing.T) {
 		DecayRate:           0.05,
 		DecayInterval:       100 * time.Millisecond,
 		PruneThreshold:      0.1,
+		EdgeBootstrap:       types.EdgeBootstrapFullMesh,
 	}
 
 	logger, _ := zap.NewDevelopment()
@@ -205,15 +422,15 @@ func TestSlimeMoldTopology(t *testing.T) {
 	sm.AddAgent(agent1)
 	sm.AddAgent(agent2)
 
-	// Verify full mesh created
+	// Verify full mesh created: 2 self-loops and an edge each way
 	snapshot := sm.GetSnapshot()
-	if snapshot.Stats.TotalEdges != 2 {
-		t.Errorf("Expected 2 edges, got %d", snapshot.Stats.TotalEdges)
+	if snapshot.Stats.TotalEdges != 4 {
+		t.Errorf("Expected 4 edges, got %d", snapshot.Stats.TotalEdges)
 	}
 
 	// Reinforce one edge multiple times
 	for i := 0; i < 5; i++ {
-		sm.ReinforceEdge(agent1.ID, agent2.ID)
+		sm.ReinforceEdge(agent1.ID, agent2.ID, types.MessageTypeTask)
 	}
 
 	// Check reinforcement worked
@@ -229,12 +446,13 @@ func TestTopologyEvolution(t *testing.T) {
 		ReinforcementAmount: 0.2,
 		DecayRate:           0.1,
 		PruneThreshold:      0.15,
+		EdgeBootstrap:       types.EdgeBootstrapFullMesh,
 	}
 
 	logger, _ := zap.NewDevelopment()
 	sm := topology.NewSlimeMoldTopology(config, logger)
 
-	// Create 4 agents (12 edges full mesh)
+	// Create 4 agents (16 edges full mesh, self-loops included)
 	agents := make([]*types.Agent, 4)
 	for i := 0; i < 4; i++ {
 		agents[i] = &types.Agent{
@@ -248,14 +466,14 @@ func TestTopologyEvolution(t *testing.T) {
 	}
 
 	initialSnapshot := sm.GetSnapshot()
-	if initialSnapshot.Stats.TotalEdges != 12 {
-		t.Errorf("Expected 12 initial edges, got %d", initialSnapshot.Stats.TotalEdges)
+	if initialSnapshot.Stats.TotalEdges != 16 {
+		t.Errorf("Expected 16 initial edges, got %d", initialSnapshot.Stats.TotalEdges)
 	}
 
 	// Simulate high-frequency communication between A0 and A1
 	for i := 0; i < 20; i++ {
-		sm.ReinforceEdge(agents[0].ID, agents[1].ID)
-		sm.ReinforceEdge(agents[1].ID, agents[0].ID)
+		sm.ReinforceEdge(agents[0].ID, agents[1].ID, types.MessageTypeTask)
+		sm.ReinforceEdge(agents[1].ID, agents[0].ID, types.MessageTypeTask)
 	}
 
 	// Apply decay to all edges
@@ -286,6 +504,7 @@ func TestTopologyEvolution(t *testing.T) {
 func TestGraphStatistics(t *testing.T) {
 	config := &types.Config{
 		InitialEdgeWeight: 0.5,
+		EdgeBootstrap:     types.EdgeBootstrapFullMesh,
 	}
 	graph := topology.NewGraph(config)
 
@@ -309,8 +528,8 @@ func TestGraphStatistics(t *testing.T) {
 		t.Errorf("Expected 4 agents, got %d", stats.TotalAgents)
 	}
 
-	if stats.TotalEdges != 12 {
-		t.Errorf("Expected 12 edges, got %d", stats.TotalEdges)
+	if stats.TotalEdges != 16 {
+		t.Errorf("Expected 16 edges, self-loops included, got %d", stats.TotalEdges)
 	}
 
 	if stats.Density != 1.0 {
@@ -325,3 +544,1129 @@ func TestGraphStatistics(t *testing.T) {
 		t.Errorf("Expected average weight 0.5, got %f", stats.AverageWeight)
 	}
 }
+
+func TestMultiHopPath(t *testing.T) {
+	cfg := config.Default()
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	for _, id := range []types.AgentID{"sales", "inventory", "warehouse", "billing"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	link := func(source, target types.AgentID, weight float64) {
+		snapshot.Edges[types.NewEdgeID(source, target)] = &types.Edge{SourceID: source, TargetID: target, Weight: weight}
+	}
+	link("sales", "warehouse", 0.05) // Pruned in all but name
+	link("sales", "inventory", 0.9)
+	link("inventory", "warehouse", 0.8)
+	link("sales", "billing", 0.6)
+	link("billing", "warehouse", 0.6)
+
+	slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	slimeMold.GetGraph().Restore(snapshot)
+	path, err := slimeMold.GetOptimalPath("sales", "warehouse")
+	if err != nil || len(path) != 3 || path[1] != "inventory" {
+		t.Fatalf("Expected a relay through inventory, got %v (%v)", path, err)
+	}
+
+	// Draining agents do not relay
+	snapshot.Agents["inventory"].Lifecycle = &types.AgentLifecycle{State: types.LifecycleDraining}
+	relay, err := topology.SnapshotPath(cfg, snapshot, "sales", "warehouse")
+	if err != nil || relay.Hops != 2 || relay.Agents[1] != "billing" || relay.Strength < 0.35 || relay.Strength > 0.37 {
+		t.Errorf("Expected a relay through billing, got %+v (%v)", relay, err)
+	}
+
+	// Without any path the fallback policy decides
+	fallback, err := topology.SnapshotPath(cfg, snapshot, "warehouse", "sales")
+	if err != nil || !fallback.Fallback || len(fallback.Agents) != 2 {
+		t.Errorf("Expected the direct fallback, got %+v (%v)", fallback, err)
+	}
+	cfg.PathFallback = types.PathFallbackNone
+	if _, err := topology.SnapshotPath(cfg, snapshot, "warehouse", "sales"); !errors.Is(err, topology.ErrNoPath) {
+		t.Errorf("Expected no path, got %v", err)
+	}
+}
+
+func TestEdgeLatency(t *testing.T) {
+	edge := &types.Edge{SourceID: "sales", TargetID: "inventory", Weight: 0.5}
+	if edge.LatencyFactor(100*time.Millisecond) != 1 {
+		t.Error("Expected an edge without samples to keep its strength")
+	}
+	for ms := 1; ms <= 100; ms++ {
+		edge.ObserveLatency(time.Duration(ms) * time.Millisecond)
+	}
+	if edge.LatencyP50Ms != 50 || edge.LatencyP99Ms != 99 {
+		t.Errorf("Expected p50 50ms and p99 99ms, got %g and %g", edge.LatencyP50Ms, edge.LatencyP99Ms)
+	}
+	if factor := edge.LatencyFactor(50 * time.Millisecond); factor != 0.5 {
+		t.Errorf("Expected half the strength at the target, got %g", factor)
+	}
+
+	// Only the latest samples count, and clock skew counts as no latency
+	for i := 0; i < types.EdgeLatencySamples; i++ {
+		edge.ObserveLatency(-time.Second)
+	}
+	if edge.LatencyP50Ms != 0 || edge.LatencyP99Ms != 0 {
+		t.Errorf("Expected old samples rolled out, got p50 %g and p99 %g", edge.LatencyP50Ms, edge.LatencyP99Ms)
+	}
+
+	// With a target, a slow direct edge loses to a fast relay
+	cfg := config.Default()
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	for _, id := range []types.AgentID{"sales", "inventory", "warehouse"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	link := func(source, target types.AgentID, weight float64, latency time.Duration) {
+		edge := &types.Edge{SourceID: source, TargetID: target, Weight: weight}
+		edge.ObserveLatency(latency)
+		snapshot.Edges[types.NewEdgeID(source, target)] = edge
+	}
+	link("sales", "warehouse", 0.85, 2*time.Second)
+	link("sales", "inventory", 0.9, 10*time.Millisecond)
+	link("inventory", "warehouse", 0.9, 10*time.Millisecond)
+
+	if path, err := topology.SnapshotPath(cfg, snapshot, "sales", "warehouse"); err != nil || path.Hops != 1 {
+		t.Errorf("Expected the direct edge without a latency target, got %+v (%v)", path, err)
+	}
+	cfg.RoutingLatencyTarget = 100 * time.Millisecond
+	if path, err := topology.SnapshotPath(cfg, snapshot, "sales", "warehouse"); err != nil || path.Hops != 2 || path.Agents[1] != "inventory" {
+		t.Errorf("Expected the fast relay through inventory, got %+v (%v)", path, err)
+	}
+}
+
+func TestPercentileNearestRank(t *testing.T) {
+	samples := func(n int) []float64 {
+		sorted := make([]float64, n)
+		for i := range sorted {
+			sorted[i] = float64(i + 1)
+		}
+		return sorted
+	}
+
+	cases := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"empty", nil, 0.5, 0},
+		{"single", samples(1), 0.99, 1},
+		{"p0 is the minimum", samples(10), 0, 1},
+		{"p50 of even count", samples(10), 0.5, 5},
+		{"p50 of odd count", samples(13), 0.5, 7},
+		{"p95 rounds the rank up", samples(13), 0.95, 13},
+		{"p99 rounds the rank up", samples(13), 0.99, 13},
+		{"p95 of 20", samples(20), 0.95, 19},
+		{"p99 of 100", samples(100), 0.99, 99},
+		{"p100 is the maximum", samples(7), 1, 7},
+	}
+	for _, tc := range cases {
+		if got := types.Percentile(tc.sorted, tc.p); got != tc.want {
+			t.Errorf("%s: expected %g, got %g", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAlgorithmCycles(t *testing.T) {
+	cfg := config.Default()
+	cfg.PruneMinDegree = 0
+	cfg.AlgorithmHistory = 2
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	link := func(source, target types.AgentID, weight float64) {
+		id := types.NewEdgeID(source, target)
+		snapshot.Edges[id] = &types.Edge{ID: id, SourceID: source, TargetID: target, Weight: weight}
+	}
+	for _, id := range []types.AgentID{"sales-1", "inventory-1", "warehouse-1"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	link("sales-1", "inventory-1", 0.6)
+	link("inventory-1", "warehouse-1", 0.6)
+	link("sales-1", "warehouse-1", cfg.PruneThreshold+0.005)
+
+	slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	slimeMold.Restore(snapshot)
+	for i := 0; i < 2; i++ {
+		slimeMold.ReinforceEdge("sales-1", "inventory-1", types.MessageTypeTask)
+		slimeMold.ReinforceEdge("inventory-1", "warehouse-1", types.MessageTypeTask)
+	}
+	slimeMold.DecayCycle()
+
+	cycles := slimeMold.AlgorithmCycles(0)
+	if len(cycles) != 1 {
+		t.Fatalf("Expected one cycle recorded, got %d", len(cycles))
+	}
+	cycle := cycles[0]
+	if cycle.Cycle != 1 || cycle.ReinforcementTotal != 4 || cycle.Reinforcements[types.NewEdgeID("sales-1", "inventory-1")] != 2 {
+		t.Errorf("Expected 4 reinforcements, 2 of sales-1->inventory-1, got %+v", cycle)
+	}
+	if cycle.DecayRate != cfg.DecayRate || math.Abs(cycle.DecayApplied-3*cfg.DecayRate) > 1e-9 {
+		t.Errorf("Expected decay of %g on each of 3 edges, got rate %g applied %g", cfg.DecayRate, cycle.DecayRate, cycle.DecayApplied)
+	}
+	if cycle.PruneCandidates != 1 || cycle.Pruned != 1 || cycle.PruneProtected != 0 || cycle.Edges != 2 {
+		t.Errorf("Expected the weak edge considered and pruned, got %+v", cycle)
+	}
+	if math.Abs(cycle.WeightEntropy-1) > 1e-9 {
+		t.Errorf("Expected two equal edges to have entropy 1, got %g", cycle.WeightEntropy)
+	}
+
+	// Quiet and paused cycles are recorded too, and only the latest are kept
+	slimeMold.DecayCycle()
+	slimeMold.PauseDecay(true)
+	slimeMold.DecayCycle()
+	cycles = slimeMold.AlgorithmCycles(0)
+	if len(cycles) != 2 || cycles[0].Cycle != 2 || cycles[0].Reinforcements != nil || cycles[0].ReinforcementTotal != 0 {
+		t.Fatalf("Expected cycles 2 and 3 kept, the first without reinforcements, got %+v", cycles)
+	}
+	if !cycles[1].Paused || cycles[1].DecayApplied != 0 {
+		t.Errorf("Expected a paused cycle without decay, got %+v", cycles[1])
+	}
+	if len(slimeMold.AlgorithmCycles(3)) != 0 {
+		t.Error("Expected no cycles after the last")
+	}
+
+	if entropy := types.WeightEntropy([]float64{1, 0, 0, 0}); entropy != 0 {
+		t.Errorf("Expected weight on a single edge to have entropy 0, got %g", entropy)
+	}
+	if entropy := types.WeightEntropy([]float64{0.5}); entropy != 0 {
+		t.Errorf("Expected a single edge to have entropy 0, got %g", entropy)
+	}
+}
+
+func TestTopologyHealthIssues(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.PruneMinDegree = 0
+	cfg.InitialEdgeWeight, cfg.ReinforcementAmount = cfg.PruneThreshold+0.01, 0
+	cfg.OscillationFreezeEdges = 1
+
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	for id, role := range map[types.AgentID]string{"coordinator-1": "ops", "sales-1": "sales", "sales-2": "sales", "inventory-1": "inventory"} {
+		snapshot.Agents[id] = &types.Agent{ID: id, Role: role, CreatedAt: now.Add(-time.Hour)}
+	}
+	for _, source := range []types.AgentID{"sales-1", "sales-2", "inventory-1"} {
+		id := types.NewEdgeID(source, "coordinator-1")
+		snapshot.Edges[id] = &types.Edge{ID: id, SourceID: source, TargetID: "coordinator-1", Weight: 0.9}
+	}
+
+	slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	slimeMold.Restore(snapshot)
+	slimeMold.SetClock(func() time.Time { return now })
+
+	// Traffic keeps recreating an edge too weak to survive a decay cycle
+	detected := map[types.TopologyIssueKind][]string{}
+	for i := 0; i < 3; i++ {
+		if err := slimeMold.ReinforceEdge("sales-1", "inventory-1", types.MessageTypeTask); err != nil {
+			t.Fatalf("Failed to recreate the edge: %v", err)
+		}
+		slimeMold.DecayCycle()
+		for len(slimeMold.EventChannel()) > 0 {
+			if event := <-slimeMold.EventChannel(); event.Type == types.TopologyEventHealthIssue {
+				detected[event.Issue.Kind] = append(detected[event.Issue.Kind], event.Issue.Subject)
+			}
+		}
+		now = now.Add(time.Minute)
+	}
+
+	if subjects := detected[types.TopologyIssueHubOverload]; len(subjects) != 1 || subjects[0] != "coordinator-1" {
+		t.Errorf("Expected the coordinator reported as an overloaded hub once, got %v", subjects)
+	}
+	if subjects := detected[types.TopologyIssueStarvedRole]; len(subjects) != 2 {
+		t.Errorf("Expected the sales and inventory roles reported as starved once each, got %v", subjects)
+	}
+	if subjects := detected[types.TopologyIssueOscillatingEdge]; len(subjects) != 1 || subjects[0] != string(types.NewEdgeID("sales-1", "inventory-1")) {
+		t.Errorf("Expected the recreated edge reported as oscillating, got %v", subjects)
+	}
+	if issues := slimeMold.TopologyHealth().Issues; len(issues) != 4 || issues[3].Kind != types.TopologyIssueOscillatingEdge || issues[3].Value != 3 {
+		t.Errorf("Expected 4 current issues, the oscillating edge detected last, got %+v", issues)
+	}
+	if !slimeMold.Guardrails().Frozen() {
+		t.Error("Expected the oscillating edge to freeze the topology")
+	}
+
+	insight := types.NewTopologyHealthInsight("topology-manager", slimeMold.TopologyHealth().Issues[3])
+	if insight.Type != types.InsightTypeTopologyHealth || insight.Data["kind"] != "oscillating_edge" || insight.Data["suggestion"] == "" {
+		t.Errorf("Expected a topology_health insight with the suggestion, got %+v", insight)
+	}
+
+	// Issues clear once the topology recovers
+	slimeMold.Guardrails().Unfreeze()
+	now = now.Add(time.Hour)
+	slimeMold.DecayCycle()
+	for _, issue := range slimeMold.TopologyHealth().Issues {
+		if issue.Kind == types.TopologyIssueOscillatingEdge {
+			t.Errorf("Expected the oscillation forgotten after the window, got %+v", issue)
+		}
+	}
+
+	cfg = config.Default()
+	cfg.OscillationPrunes = 1
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "OSCILLATION_PRUNES") {
+		t.Errorf("Expected a single prune flagged as no oscillation, got %v", problems)
+	}
+}
+
+func TestCentralityFindsHubs(t *testing.T) {
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	link := func(source, target types.AgentID, weight float64) {
+		snapshot.Edges[types.NewEdgeID(source, target)] = &types.Edge{SourceID: source, TargetID: target, Weight: weight}
+	}
+	// Every agent talks to the others through the coordinator
+	for _, id := range []types.AgentID{"coordinator", "sales", "support", "inventory", "fraud"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+		if id != "coordinator" {
+			link(id, "coordinator", 0.9)
+			link("coordinator", id, 0.9)
+		}
+	}
+	link("sales", "inventory", 0.3)
+
+	graph := topology.NewGraph(config.Default())
+	graph.Restore(snapshot)
+	centrality := graph.GetSnapshot().Stats.Centrality
+	hub, sales, fraud := centrality["coordinator"], centrality["sales"], centrality["fraud"]
+
+	if hub.Degree != 1 || fraud.Degree != 0.25 || sales.Degree != 0.5 {
+		t.Errorf("Unexpected degrees: hub %g, sales %g, fraud %g", hub.Degree, sales.Degree, fraud.Degree)
+	}
+	// Even sales reaches inventory more strongly through the hub (0.81) than directly (0.3)
+	if hub.Betweenness != 1 || sales.Betweenness != 0 {
+		t.Errorf("Expected the hub to relay every path, got hub %g, sales %g", hub.Betweenness, sales.Betweenness)
+	}
+	if hub.Eigenvector != 1 || fraud.Eigenvector >= sales.Eigenvector || sales.Eigenvector >= 1 {
+		t.Errorf("Unexpected eigenvector centrality: hub %g, sales %g, fraud %g", hub.Eigenvector, sales.Eigenvector, fraud.Eigenvector)
+	}
+}
+
+func TestCommunityDetection(t *testing.T) {
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	link := func(source, target types.AgentID, weight float64) {
+		snapshot.Edges[types.NewEdgeID(source, target)] = &types.Edge{SourceID: source, TargetID: target, Weight: weight}
+	}
+	for _, id := range []types.AgentID{"fraud-1", "fraud-2", "sales-1", "sales-2", "sales-3", "support-1"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	// Two tightly linked groups joined by a weak edge; support talks to no one
+	link("sales-1", "sales-2", 0.9)
+	link("sales-2", "sales-3", 0.8)
+	link("sales-3", "sales-1", 0.9)
+	link("fraud-1", "fraud-2", 0.9)
+	link("fraud-2", "fraud-1", 0.7)
+	link("sales-1", "fraud-1", 0.2)
+
+	graph := topology.NewGraph(config.Default())
+	graph.Restore(snapshot)
+	result := graph.GetSnapshot()
+	communities := result.Communities
+
+	if result.Stats.Communities != 3 {
+		t.Fatalf("Expected 3 clusters, got %d: %v", result.Stats.Communities, communities)
+	}
+	if communities["fraud-1"] != 0 || communities["fraud-2"] != 0 {
+		t.Errorf("Expected the fraud agents in cluster 0, got %v", communities)
+	}
+	if communities["sales-1"] != 1 || communities["sales-2"] != 1 || communities["sales-3"] != 1 {
+		t.Errorf("Expected the sales agents in cluster 1, got %v", communities)
+	}
+	if communities["support-1"] != 2 {
+		t.Errorf("Expected the isolated agent in its own cluster, got %v", communities)
+	}
+}
+
+func TestPartitionDetection(t *testing.T) {
+	cfg := config.Default()
+	cfg.PruneMinDegree = 0
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	link := func(source, target types.AgentID, weight float64) {
+		id := types.NewEdgeID(source, target)
+		snapshot.Edges[id] = &types.Edge{ID: id, SourceID: source, TargetID: target, Weight: weight}
+	}
+	for _, id := range []types.AgentID{"fraud-1", "fraud-2", "sales-1", "sales-2", "sales-3"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	// Two groups joined by a single edge about to be pruned
+	link("sales-1", "sales-2", 0.9)
+	link("sales-2", "sales-3", 0.9)
+	link("fraud-1", "fraud-2", 0.9)
+	link("sales-1", "fraud-1", cfg.PruneThreshold+0.005)
+
+	slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	slimeMold.Restore(snapshot)
+	if stats := slimeMold.GetSnapshot().Stats; stats.Components != 1 {
+		t.Fatalf("Expected one connected mesh, got %d components", stats.Components)
+	}
+
+	var partition *types.TopologyEvent
+	for i := 0; i < 10 && partition == nil; i++ {
+		slimeMold.DecayCycle()
+		for len(slimeMold.EventChannel()) > 0 {
+			if event := <-slimeMold.EventChannel(); event.Type == types.TopologyEventPartitionDetected {
+				partition = &event
+			}
+		}
+	}
+	if partition == nil {
+		t.Fatal("Expected a partition once the bridge was pruned")
+	}
+	if len(partition.Components) != 2 || len(partition.Components[0]) != 3 || partition.Components[1][0] != "fraud-1" {
+		t.Errorf("Expected the sales and fraud islands, largest first, got %v", partition.Components)
+	}
+	if stats := slimeMold.GetSnapshot().Stats; stats.Components != 2 {
+		t.Errorf("Expected 2 components in the stats, got %d", stats.Components)
+	}
+
+	// The split is reported once, not on every cycle
+	slimeMold.DecayCycle()
+	for len(slimeMold.EventChannel()) > 0 {
+		if event := <-slimeMold.EventChannel(); event.Type == types.TopologyEventPartitionDetected {
+			t.Error("Expected no second partition event for the same split")
+		}
+	}
+}
+
+func TestNeighborhood(t *testing.T) {
+	snapshot := &types.GraphSnapshot{Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+	link := func(source, target types.AgentID, weight float64) {
+		snapshot.Edges[types.NewEdgeID(source, target)] = &types.Edge{SourceID: source, TargetID: target, Weight: weight}
+	}
+	for _, id := range []types.AgentID{"sales", "inventory", "warehouse", "billing", "fraud"} {
+		snapshot.Agents[id] = &types.Agent{ID: id}
+	}
+	// A chain sales → inventory → warehouse, billing messaging sales, fraud weakly linked to billing
+	link("sales", "inventory", 0.9)
+	link("inventory", "warehouse", 0.8)
+	link("billing", "sales", 0.6)
+	link("fraud", "billing", 0.1)
+
+	graph := topology.NewGraph(config.Default())
+	graph.Restore(snapshot)
+
+	neighborhood, err := graph.GetNeighborhood("sales", 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to get neighborhood: %v", err)
+	}
+	if len(neighborhood.Agents) != 3 || neighborhood.Hops["inventory"] != 1 || neighborhood.Hops["billing"] != 1 {
+		t.Errorf("Expected sales with inventory and billing one hop out, got %v", neighborhood.Hops)
+	}
+	if len(neighborhood.Edges) != 2 {
+		t.Errorf("Expected the 2 edges among them, got %d", len(neighborhood.Edges))
+	}
+
+	neighborhood, _ = graph.GetNeighborhood("sales", 2, 0.5)
+	if neighborhood.Hops["warehouse"] != 2 || len(neighborhood.Agents) != 4 {
+		t.Errorf("Expected warehouse two hops out and fraud's weak edge left out, got %v", neighborhood.Hops)
+	}
+	if _, ok := neighborhood.Edges[types.NewEdgeID("fraud", "billing")]; ok {
+		t.Error("Expected edges below min weight left out")
+	}
+
+	// The API computes the same over the stored snapshot
+	fromSnapshot, err := topology.SnapshotNeighborhood(snapshot, "sales", 2, 0.5)
+	if err != nil || len(fromSnapshot.Agents) != len(neighborhood.Agents) || len(fromSnapshot.Edges) != len(neighborhood.Edges) {
+		t.Errorf("Expected the snapshot neighborhood to match, got %v (%v)", fromSnapshot, err)
+	}
+
+	if self, _ := graph.GetNeighborhood("sales", 0, 0); len(self.Agents) != 1 || len(self.Edges) != 0 {
+		t.Errorf("Expected only the agent at k=0, got %v", self.Hops)
+	}
+	if _, err := graph.GetNeighborhood("missing", 1, 0); err == nil {
+		t.Error("Expected an error for an unknown agent")
+	}
+}
+
+func TestTopologyDiff(t *testing.T) {
+	start := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	snapshot := func(at time.Time, agents []types.AgentID, weights map[types.EdgeID]float64) *types.GraphSnapshot {
+		s := &types.GraphSnapshot{Timestamp: at, Agents: map[types.AgentID]*types.Agent{}, Edges: map[types.EdgeID]*types.Edge{}}
+		for _, id := range agents {
+			s.Agents[id] = &types.Agent{ID: id}
+		}
+		for id, weight := range weights {
+			s.Edges[id] = &types.Edge{Weight: weight}
+		}
+		return s
+	}
+	salesInventory := types.NewEdgeID("sales", "inventory")
+	salesBilling := types.NewEdgeID("sales", "billing")
+	inventorySales := types.NewEdgeID("inventory", "sales")
+	fraudBilling := types.NewEdgeID("fraud", "billing")
+
+	before := snapshot(start, []types.AgentID{"sales", "inventory", "billing"}, map[types.EdgeID]float64{
+		salesInventory: 0.5, salesBilling: 0.5, inventorySales: 0.5,
+	})
+	after := snapshot(start.Add(time.Hour), []types.AgentID{"sales", "inventory", "fraud"}, map[types.EdgeID]float64{
+		salesInventory: 0.9, inventorySales: 0.48, fraudBilling: 0.5,
+	})
+
+	diff := topology.Diff(before, after, 0)
+	if !diff.From.Equal(start) || !diff.To.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the snapshot times, got %s to %s", diff.From, diff.To)
+	}
+	if len(diff.AddedAgents) != 1 || diff.AddedAgents[0].ID != "fraud" || len(diff.RemovedAgents) != 1 || diff.RemovedAgents[0].ID != "billing" {
+		t.Errorf("Expected fraud added and billing removed, got %+v and %+v", diff.AddedAgents, diff.RemovedAgents)
+	}
+	if len(diff.AddedEdges) != 1 || len(diff.PrunedEdges) != 1 {
+		t.Errorf("Expected one edge added and one pruned, got %d and %d", len(diff.AddedEdges), len(diff.PrunedEdges))
+	}
+	if len(diff.WeightChanges) != 2 || diff.WeightChanges[0].EdgeID != salesInventory || diff.Strengthened != 1 || diff.Weakened != 1 {
+		t.Fatalf("Expected the sales→inventory change first of 2, got %+v", diff.WeightChanges)
+	}
+	if change := diff.WeightChanges[0]; math.Abs(change.Delta-0.4) > 1e-9 || change.Before != 0.5 || change.After != 0.9 {
+		t.Errorf("Expected 0.5 → 0.9, got %+v", change)
+	}
+
+	// Small changes are left out, added and pruned edges are not
+	diff = topology.Diff(before, after, 0.1)
+	if len(diff.WeightChanges) != 1 || diff.Weakened != 0 || len(diff.AddedEdges) != 1 {
+		t.Errorf("Expected only the large change, got %+v", diff.WeightChanges)
+	}
+	if diff := topology.Diff(after, after, 0); len(diff.AddedAgents)+len(diff.RemovedAgents)+len(diff.AddedEdges)+len(diff.PrunedEdges)+len(diff.WeightChanges) != 0 || diff.StatsDelta != (types.StatsDelta{}) {
+		t.Errorf("Expected no changes between a snapshot and itself, got %+v", diff)
+	}
+
+	// Stats move from one snapshot's to the other's, without centrality
+	before.Stats = types.GraphStats{TotalAgents: 3, TotalEdges: 3, Density: 0.5, ReductionPercent: 50, Communities: 1, Components: 1}
+	after.Stats = types.GraphStats{TotalAgents: 4, TotalEdges: 2, Density: 0.25, ReductionPercent: 75, Communities: 2, Components: 1,
+		Centrality: map[types.AgentID]types.AgentCentrality{"sales": {}}}
+	diff = topology.Diff(before, after, 0)
+	if delta := diff.StatsDelta; delta.Agents != 1 || delta.Edges != -1 || delta.Density != -0.25 || delta.ReductionPercent != 25 || delta.Communities != 1 || delta.Components != 0 {
+		t.Errorf("Unexpected stats delta %+v", delta)
+	}
+	if diff.StatsBefore.TotalEdges != 3 || diff.StatsAfter.TotalAgents != 4 || diff.StatsAfter.Centrality != nil {
+		t.Errorf("Expected both snapshots' stats without centrality, got %+v and %+v", diff.StatsBefore, diff.StatsAfter)
+	}
+
+	// Top keeps the largest weight changes but still counts them all
+	diff.Top(1)
+	if len(diff.WeightChanges) != 1 || diff.WeightChanges[0].EdgeID != salesInventory || diff.Weakened != 1 {
+		t.Errorf("Expected only the largest change kept, got %+v", diff.WeightChanges)
+	}
+	diff.Top(0)
+	if len(diff.WeightChanges) != 1 {
+		t.Errorf("Expected Top(0) to keep every change, got %d", len(diff.WeightChanges))
+	}
+}
+
+func TestSnapshotDeltas(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	graph := topology.NewGraph(cfg)
+	for _, id := range []types.AgentID{"sales-1", "support-1", "fraud-1"} {
+		graph.AddAgent(&types.Agent{ID: id, Role: "test", Status: types.AgentStatusActive})
+	}
+	checkpoint := graph.GetSnapshot()
+	checkpoint.Sequence = 1
+
+	graph.ReinforceEdge(types.NewEdgeID("sales-1", "support-1"), types.MessageTypeTask)
+	graph.RemoveAgent("fraud-1")
+	graph.AddAgent(&types.Agent{ID: "inventory-1", Role: "test", Status: types.AgentStatusActive})
+	next := graph.GetSnapshot()
+	next.Sequence = 2
+
+	delta := types.NewSnapshotDelta(checkpoint, next)
+	if _, ok := delta.Agents["inventory-1"]; !ok || len(delta.RemovedAgents) != 1 || delta.RemovedAgents[0] != "fraud-1" {
+		t.Errorf("Expected inventory-1 added and fraud-1 removed, got %v and %v", delta.Agents, delta.RemovedAgents)
+	}
+	if _, ok := delta.Edges[types.NewEdgeID("sales-1", "support-1")]; !ok || len(delta.Edges) >= len(next.Edges) {
+		t.Errorf("Expected only the changed edges in the delta, got %d of %d", len(delta.Edges), len(next.Edges))
+	}
+
+	// Consumers rebuild the topology from the checkpoint and the delta as published
+	data, err := json.Marshal(delta)
+	if err != nil {
+		t.Fatalf("Failed to encode delta: %v", err)
+	}
+	var published types.SnapshotDelta
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatalf("Failed to decode delta: %v", err)
+	}
+	var reconstructor types.SnapshotReconstructor
+	reconstructor.Checkpoint(checkpoint)
+	if err := reconstructor.Apply(&published); err != nil {
+		t.Fatalf("Failed to apply delta: %v", err)
+	}
+	rebuilt, _ := json.Marshal(reconstructor.Snapshot())
+	expected, _ := json.Marshal(next)
+	if string(rebuilt) != string(expected) {
+		t.Errorf("Expected the rebuilt snapshot to equal the published one:\n%s\n%s", rebuilt, expected)
+	}
+
+	// A missed delta is detected rather than silently skipped
+	if err := reconstructor.Apply(&types.SnapshotDelta{Sequence: 4}); !errors.Is(err, types.ErrSnapshotGap) {
+		t.Errorf("Expected a sequence gap, got %v", err)
+	}
+	if reconstructor.Snapshot().Sequence != 2 {
+		t.Errorf("Expected the snapshot to stay at sequence 2, got %d", reconstructor.Snapshot().Sequence)
+	}
+}
+
+func TestShadowTopologyParams(t *testing.T) {
+	params, err := types.ParseTopologyParams("DECAY_RATE=0.05, prune_threshold=0.2")
+	if err != nil {
+		t.Fatalf("Failed to parse parameters: %v", err)
+	}
+	live := config.Default()
+	live.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	live.PruneMinDegree = 0 // Let the shadow prune every edge
+	shadow := params.Apply(live)
+	if shadow.DecayRate != 0.05 || shadow.PruneThreshold != 0.2 || shadow.ReinforcementAmount != live.ReinforcementAmount {
+		t.Errorf("Expected only decay and prune threshold overridden, got %+v", types.TopologyParamsOf(shadow))
+	}
+	if live.DecayRate == 0.05 {
+		t.Error("Expected the live configuration to be left alone")
+	}
+
+	for _, invalid := range []string{"", "DECAY_INTERVAL=10s", "DECAY_RATE=2", "DECAY_RATE"} {
+		if _, err := types.ParseTopologyParams(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	// The same traffic under a faster decay and a higher threshold prunes what the live topology keeps
+	params, _ = types.ParseTopologyParams("DECAY_RATE=0.05,PRUNE_THRESHOLD=0.45")
+	liveGraph, shadowGraph := topology.NewGraph(live), topology.NewGraph(params.Apply(live))
+	for _, graph := range []*topology.Graph{liveGraph, shadowGraph} {
+		graph.AddAgent(&types.Agent{ID: "sales-1", Status: types.AgentStatusActive})
+		graph.AddAgent(&types.Agent{ID: "support-1", Status: types.AgentStatusActive})
+		for i := 0; i < 3; i++ {
+			graph.DecayAllEdges()
+			graph.PruneWeakEdges()
+		}
+	}
+	if liveGraph.GetEdgeCount() == 0 || shadowGraph.GetEdgeCount() != 0 {
+		t.Errorf("Expected only the shadow to prune, live has %d edges and shadow %d", liveGraph.GetEdgeCount(), shadowGraph.GetEdgeCount())
+	}
+}
+
+func TestReductionTargetTuning(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	cfg.TargetReduction = 50
+	graph := topology.NewGraph(cfg)
+	for _, id := range []types.AgentID{"sales-1", "sales-2", "support-1", "fraud-1"} {
+		graph.AddAgent(&types.Agent{ID: id, Status: types.AgentStatusActive})
+	}
+
+	// A full mesh is far from the target, so decay and pruning speed up
+	graph.Tune()
+	tuning := graph.GetSnapshot().Stats.Tuning
+	if tuning == nil || tuning.TargetReduction != 50 || tuning.Converged {
+		t.Fatalf("Expected an unconverged tuning status, got %+v", tuning)
+	}
+	if tuning.DecayRate <= cfg.DecayRate || tuning.PruneThreshold <= cfg.PruneThreshold {
+		t.Errorf("Expected decay rate and prune threshold to rise, got %+v", tuning)
+	}
+
+	// Parameters stay within bounds however far off the graph is
+	for i := 0; i < 20; i++ {
+		graph.Tune()
+	}
+	tuning = graph.Tuning()
+	if tuning.DecayRate != cfg.TuningMaxDecayRate || tuning.PruneThreshold != cfg.TuningMaxPruneThreshold {
+		t.Errorf("Expected the parameters capped at their maximums, got %+v", tuning)
+	}
+
+	// Once unused edges are pruned past the target, the parameters ease off
+	for i := 0; i < 10; i++ {
+		graph.DecayAllEdges()
+		graph.PruneWeakEdges()
+	}
+	graph.Tune()
+	tuning = graph.Tuning()
+	if tuning.Current <= 50 || tuning.DecayRate >= cfg.TuningMaxDecayRate {
+		t.Errorf("Expected the overshoot to lower the decay rate, got %+v", tuning)
+	}
+
+	if topology.NewGraph(config.Default()).GetSnapshot().Stats.Tuning != nil {
+		t.Error("Expected no tuning status without a target")
+	}
+}
+
+func TestEdgeUsageByMessageType(t *testing.T) {
+	graph := topology.NewGraph(config.Default())
+	for _, id := range []types.AgentID{"sales-1", "support-1"} {
+		graph.AddAgent(&types.Agent{ID: id, Status: types.AgentStatusActive})
+	}
+	edgeID := types.NewEdgeID("sales-1", "support-1")
+
+	graph.ReinforceEdge(edgeID, types.MessageTypeTask)
+	graph.ReinforceEdge(edgeID, types.MessageTypeTask)
+	graph.ReinforceEdge(edgeID, types.MessageTypeInsight)
+	graph.ReinforceEdge(edgeID, "")
+	if err := graph.SetEdgeMetadata(edgeID, "channel", "escalations"); err != nil {
+		t.Fatalf("Failed to set edge metadata: %v", err)
+	}
+	if err := graph.SetEdgeMetadata("edge-missing", "channel", "escalations"); err == nil {
+		t.Error("Expected an error annotating a missing edge")
+	}
+
+	snapshot := graph.GetSnapshot()
+	edge := snapshot.Edges[edgeID]
+	if edge.Usage != 4 {
+		t.Errorf("Expected usage 4, got %d", edge.Usage)
+	}
+	if edge.UsageByType[types.MessageTypeTask] != 2 || edge.UsageByType[types.MessageTypeInsight] != 1 || len(edge.UsageByType) != 2 {
+		t.Errorf("Expected 2 task and 1 insight message, got %v", edge.UsageByType)
+	}
+	if edge.Metadata["channel"] != "escalations" {
+		t.Errorf("Expected the channel metadata, got %v", edge.Metadata)
+	}
+
+	// The snapshot is a copy: later traffic doesn't change it
+	graph.ReinforceEdge(edgeID, types.MessageTypeTask)
+	graph.SetEdgeMetadata(edgeID, "channel", "")
+	if edge.UsageByType[types.MessageTypeTask] != 2 || edge.Metadata["channel"] != "escalations" {
+		t.Error("Expected the snapshot edge not to share state with the graph")
+	}
+	if live, _ := graph.GetEdge(edgeID); live.Metadata["channel"] != "" {
+		t.Error("Expected an empty value to remove the metadata key")
+	}
+
+	encoded, err := json.Marshal(edge)
+	if err != nil {
+		t.Fatalf("Failed to encode edge: %v", err)
+	}
+	var decoded types.Edge
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.UsageByType[types.MessageTypeInsight] != 1 {
+		t.Errorf("Expected per-type usage to round-trip through JSON, got %v (%v)", decoded.UsageByType, err)
+	}
+}
+
+func TestAdaptiveDecay(t *testing.T) {
+	cfg := config.Default()
+	cfg.EdgeBootstrap = types.EdgeBootstrapFullMesh
+	cfg.DecayInterval = 20 * time.Millisecond
+	cfg.DecayReferenceThroughput = 1
+	sm := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+	for _, id := range []types.AgentID{"sales-1", "support-1"} {
+		sm.AddAgent(&types.Agent{ID: id, Status: types.AgentStatusActive})
+	}
+	sm.Start(context.Background())
+	defer sm.Stop()
+
+	waitFor := func(check func(*types.AdaptiveDecayStatus) bool) *types.AdaptiveDecayStatus {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			status := sm.GetSnapshot().Stats.AdaptiveDecay
+			if status != nil && check(status) || time.Now().After(deadline) {
+				return status
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// A silent mesh barely decays
+	status := waitFor(func(s *types.AdaptiveDecayStatus) bool { return s.Factor < 1 })
+	if status == nil || status.Factor != 0.1 || status.DecayRate >= cfg.DecayRate {
+		t.Fatalf("Expected a quiet mesh to decay at a tenth of the rate, got %+v", status)
+	}
+
+	// Far more traffic than the reference speeds decay up, within bounds
+	for i := 0; i < 1000; i++ {
+		sm.ReinforceEdge("sales-1", "support-1", types.MessageTypeTask)
+	}
+	status = waitFor(func(s *types.AdaptiveDecayStatus) bool { return s.Factor > 1 })
+	if status == nil || status.Factor != 10 || status.Throughput <= cfg.DecayReferenceThroughput || status.DecayRate != 10*cfg.DecayRate {
+		t.Errorf("Expected a busy mesh to decay at ten times the rate, got %+v", status)
+	}
+
+	if topology.NewGraph(config.Default()).AdaptiveDecay() != nil {
+		t.Error("Expected no adaptive decay without a reference throughput")
+	}
+}
+
+func TestRolePolicies(t *testing.T) {
+	t.Setenv("EDGE_BOOTSTRAP", "full_mesh")
+	t.Setenv("ROLE_POLICIES", "sales/inventory:REINFORCEMENT_AMOUNT=0.3;fraud/analyst:REINFORCEMENT_AMOUNT=0.05,DECAY_RATE=0.1")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if policy, ok := cfg.RolePolicies.For("inventory", "sales"); !ok || policy.ReinforcementAmount != 0.3 {
+		t.Fatalf("Expected the sales/inventory policy either way round, got %+v", cfg.RolePolicies)
+	}
+
+	graph := topology.NewGraph(cfg)
+	for id, role := range map[types.AgentID]string{"sales-1": "sales", "inventory-1": "inventory", "fraud-1": "fraud", "analyst-1": "analyst"} {
+		graph.AddAgent(&types.Agent{ID: id, Role: role, Status: types.AgentStatusActive})
+	}
+	weight := func(source, target types.AgentID) float64 {
+		edge, err := graph.GetEdgeBetween(source, target)
+		if err != nil {
+			t.Fatalf("Missing edge %s -> %s", source, target)
+		}
+		return edge.GetWeight()
+	}
+
+	graph.ReinforceEdge(types.NewEdgeID("inventory-1", "sales-1"), types.MessageTypeTask)
+	graph.ReinforceEdge(types.NewEdgeID("fraud-1", "analyst-1"), types.MessageTypeTask)
+	graph.ReinforceEdge(types.NewEdgeID("sales-1", "fraud-1"), types.MessageTypeTask)
+	if w := weight("inventory-1", "sales-1"); w < 0.799 || w > 0.801 {
+		t.Errorf("Expected sales/inventory edges to reinforce by 0.3, got %f", w)
+	}
+	if w := weight("fraud-1", "analyst-1"); w < 0.549 || w > 0.551 {
+		t.Errorf("Expected fraud/analyst edges to reinforce by 0.05, got %f", w)
+	}
+	if w := weight("sales-1", "fraud-1"); w < 0.599 || w > 0.601 {
+		t.Errorf("Expected other edges to reinforce by REINFORCEMENT_AMOUNT, got %f", w)
+	}
+
+	graph.DecayAllEdges()
+	if w := weight("analyst-1", "fraud-1"); w < 0.399 || w > 0.401 {
+		t.Errorf("Expected fraud/analyst edges to decay by 0.1, got %f", w)
+	}
+	if w := weight("sales-1", "inventory-1"); w < 0.479 || w > 0.481 {
+		t.Errorf("Expected other edges to decay by DECAY_RATE, got %f", w)
+	}
+
+	for _, invalid := range []string{"sales:REINFORCEMENT_AMOUNT=0.2", "sales/inventory:PRUNE_THRESHOLD=0.2", "sales/inventory:DECAY_RATE=0.1;inventory/sales:DECAY_RATE=0.2"} {
+		if _, err := types.ParseRolePolicies(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestEdgePriors(t *testing.T) {
+	t.Setenv("EDGE_PRIOR_SAME_ROLE", "0.6")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	graph := topology.NewGraph(cfg)
+	graph.SetClock(func() time.Time { return now })
+
+	agents := map[types.AgentID]*types.Agent{
+		"sales-1":     {ID: "sales-1", Role: "sales", DependsOn: []string{"inventory"}},
+		"sales-2":     {ID: "sales-2", Role: "sales"},
+		"inventory-1": {ID: "inventory-1", Role: "inventory"},
+		"support-1":   {ID: "support-1", Role: "support"},
+	}
+	for _, agent := range agents {
+		graph.AddAgent(agent)
+	}
+	weight := func(source, target types.AgentID) float64 {
+		if err := graph.ReinforceEdge(types.NewEdgeID(source, target), types.MessageTypeTask); err != nil {
+			t.Fatalf("Failed to reinforce edge: %v", err)
+		}
+		edge, _ := graph.GetEdgeBetween(source, target)
+		return edge.GetWeight()
+	}
+
+	// New edges start at their prior, then reinforce by REINFORCEMENT_AMOUNT
+	if w := weight("sales-1", "inventory-1"); math.Abs(w-0.8) > 0.001 {
+		t.Errorf("Expected an edge to a declared dependency seeded at 0.7, got %f", w)
+	}
+	if w := weight("sales-1", "sales-2"); math.Abs(w-0.7) > 0.001 {
+		t.Errorf("Expected an edge within a role seeded at 0.6, got %f", w)
+	}
+	if w := weight("support-1", "sales-1"); math.Abs(w-0.6) > 0.001 {
+		t.Errorf("Expected other edges seeded at INITIAL_EDGE_WEIGHT, got %f", w)
+	}
+
+	// An agent that leaves and returns gets its old weights back
+	weight("support-1", "sales-1")
+	graph.RemoveAgent("support-1")
+	if history := graph.EdgeHistory(); math.Abs(history[types.NewEdgeID("support-1", "sales-1")].Weight-0.7) > 0.001 {
+		t.Fatalf("Expected the weight of the removed edge remembered, got %v", history)
+	}
+	graph.AddAgent(agents["support-1"])
+	if w := weight("support-1", "sales-1"); math.Abs(w-0.8) > 0.001 {
+		t.Errorf("Expected the returning agent's edge seeded at its old 0.7, got %f", w)
+	}
+	if history := graph.EdgeHistory(); len(history) != 0 {
+		t.Errorf("Expected the remembered weight used up, got %v", history)
+	}
+
+	// Only within EDGE_PRIOR_HISTORY
+	graph.RemoveAgent("support-1")
+	now = now.Add(cfg.EdgePriorHistory + time.Minute)
+	if history := graph.EdgeHistory(); len(history) != 0 {
+		t.Errorf("Expected the weight forgotten after EDGE_PRIOR_HISTORY, got %v", history)
+	}
+
+	cfg.EdgePriorSameRole = cfg.PruneThreshold
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "EDGE_PRIOR_SAME_ROLE") {
+		t.Errorf("Expected a prior at the prune threshold flagged, got %v", problems)
+	}
+}
+
+func TestLearnedPriors(t *testing.T) {
+	cfg := config.Default()
+	reinforce := func(graph *topology.Graph, source, target types.AgentID, times int) float64 {
+		for i := 0; i < times; i++ {
+			if err := graph.ReinforceEdge(types.NewEdgeID(source, target), types.MessageTypeTask); err != nil {
+				t.Fatalf("Failed to reinforce edge: %v", err)
+			}
+		}
+		edge, _ := graph.GetEdgeBetween(source, target)
+		return edge.GetWeight()
+	}
+
+	// Yesterday's session learns which agents and roles talk
+	yesterday := topology.NewGraph(cfg)
+	for id, role := range map[types.AgentID]string{"sales-1": "sales", "inventory-1": "inventory", "inventory-2": "inventory"} {
+		yesterday.AddAgent(&types.Agent{ID: id, Role: role})
+	}
+	reinforce(yesterday, "sales-1", "inventory-1", 3)
+	reinforce(yesterday, "sales-1", "inventory-2", 1)
+
+	learned := yesterday.LearnedPriors()
+	if w := learned.Edges[types.NewEdgeID("sales-1", "inventory-1")]; w != 0.8 {
+		t.Errorf("Expected the edge's weight rounded to 0.8, got %v", w)
+	}
+	if prior := learned.Roles[types.RoleEdgeKey("sales", "inventory")]; prior.Edges != 2 || prior.Weight != 0.7 {
+		t.Errorf("Expected the mean of 2 sales->inventory edges, 0.7, got %+v", prior)
+	}
+	data, _ := json.Marshal(learned)
+	var priors types.TopologyPriors
+	if err := json.Unmarshal(data, &priors); err != nil {
+		t.Fatalf("Failed to decode priors: %v", err)
+	}
+
+	// Today's mesh starts from them instead of INITIAL_EDGE_WEIGHT
+	today := topology.NewGraph(cfg)
+	today.SetPriors(&priors)
+	for id, role := range map[types.AgentID]string{"sales-1": "sales", "inventory-1": "inventory", "inventory-3": "inventory", "support-1": "support"} {
+		today.AddAgent(&types.Agent{ID: id, Role: role})
+	}
+	if w := reinforce(today, "sales-1", "inventory-1", 1); math.Abs(w-0.9) > 0.001 {
+		t.Errorf("Expected the edge between the same agents seeded at 0.8, got %f", w)
+	}
+	if w := reinforce(today, "sales-1", "inventory-3", 1); math.Abs(w-0.8) > 0.001 {
+		t.Errorf("Expected a new inventory agent seeded at the sales->inventory mean, got %f", w)
+	}
+	if w := reinforce(today, "inventory-3", "sales-1", 1); math.Abs(w-0.6) > 0.001 {
+		t.Errorf("Expected the reverse direction not seeded by the role prior, got %f", w)
+	}
+	if w := reinforce(today, "support-1", "sales-1", 1); math.Abs(w-0.6) > 0.001 {
+		t.Errorf("Expected unknown pairs seeded at INITIAL_EDGE_WEIGHT, got %f", w)
+	}
+
+	cfg.LearnedPriorsMaxAge = 0
+	if problems := config.Problems(cfg); len(problems) != 1 || !strings.Contains(problems[0], "LEARNED_PRIORS_MAX_AGE") {
+		t.Errorf("Expected a zero max age flagged, got %v", problems)
+	}
+}
+
+func TestEdgeConstraints(t *testing.T) {
+	t.Setenv("EDGE_BOOTSTRAP", "full_mesh")
+	t.Setenv("EDGE_CONSTRAINTS", "fraud/external_adapter=deny, sales/support=0.6, agent:sales-2/inventory=deny")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfg.EdgeConstraints) != 3 || cfg.EdgeConstraints[1].String() != "sales/support=0.6" {
+		t.Fatalf("Unexpected constraints: %+v", cfg.EdgeConstraints)
+	}
+
+	graph := topology.NewGraph(cfg)
+	for _, agent := range []*types.Agent{
+		{ID: "fraud-1", Role: "fraud"},
+		{ID: "adapter-1", Role: "external_adapter"},
+		{ID: "sales-1", Role: "sales"},
+		{ID: "sales-2", Role: "sales"},
+		{ID: "support-1", Role: "support"},
+		{ID: "inventory-1", Role: "inventory"},
+	} {
+		agent.Status = types.AgentStatusActive
+		graph.AddAgent(agent)
+	}
+
+	// Full mesh creation leaves out denied edges, in both directions
+	for _, pair := range [][2]types.AgentID{{"fraud-1", "adapter-1"}, {"adapter-1", "fraud-1"}, {"inventory-1", "sales-2"}} {
+		if _, err := graph.GetEdgeBetween(pair[0], pair[1]); err == nil {
+			t.Errorf("Expected no edge %s -> %s", pair[0], pair[1])
+		}
+	}
+	if _, err := graph.GetEdgeBetween("sales-1", "inventory-1"); err != nil {
+		t.Error("Expected agent constraints to leave other agents of the role alone")
+	}
+	if _, err := graph.GetEdgeBetween("fraud-1", "fraud-1"); err != nil {
+		t.Error("Expected self-loops never constrained")
+	}
+
+	// Messages do not recreate denied edges
+	if err := graph.ReinforceEdge(types.NewEdgeID("fraud-1", "adapter-1"), types.MessageTypeTask); !errors.Is(err, topology.ErrEdgeForbidden) {
+		t.Errorf("Expected ErrEdgeForbidden, got %v", err)
+	}
+	if _, err := graph.GetEdgeBetween("fraud-1", "adapter-1"); err == nil {
+		t.Error("Expected the denied edge not to be created")
+	}
+
+	// Capped edges are reinforced up to the cap but still count usage
+	for i := 0; i < 5; i++ {
+		if err := graph.ReinforceEdge(types.NewEdgeID("support-1", "sales-1"), types.MessageTypeTask); err != nil {
+			t.Fatalf("Failed to reinforce capped edge: %v", err)
+		}
+	}
+	edge, _ := graph.GetEdgeBetween("support-1", "sales-1")
+	if w := edge.GetWeight(); w < 0.599 || w > 0.601 || edge.Usage != 5 {
+		t.Errorf("Expected the sales/support edge capped at 0.6 with 5 uses, got %f and %d", w, edge.Usage)
+	}
+
+	for _, invalid := range []string{"fraud/external_adapter", "fraud=deny", "fraud/adapter=1.5", "fraud/adapter=0", "agent:/fraud=deny", "fraud/adapter=block"} {
+		if _, err := types.ParseEdgeConstraints(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestAgentLiveness(t *testing.T) {
+	graph := topology.NewGraph(config.Default())
+	start := time.Now()
+	for _, id := range []types.AgentID{"sales-1", "support-1", "fraud-1"} {
+		graph.AddAgent(&types.Agent{ID: id, Role: "sales", Status: types.AgentStatusActive, LastSeenAt: start})
+	}
+	status := func(id types.AgentID) types.AgentStatus {
+		agent, err := graph.GetAgent(id)
+		if err != nil {
+			t.Fatalf("Missing agent %s", id)
+		}
+		return agent.Status
+	}
+
+	// sales-1 keeps sending heartbeats, support-1 goes quiet, fraud-1 was never heard from
+	graph.Heartbeat("sales-1", start.Add(time.Minute))
+	graph.Heartbeat("support-1", start.Add(30*time.Second))
+
+	// Silence only counts from the floor, e.g. when the manager started
+	if offline, _ := graph.SweepLiveness(start.Add(2*time.Minute), start.Add(time.Minute), 90*time.Second, 10*time.Minute); len(offline) != 0 {
+		t.Fatalf("Expected no agent offline within a timeout of the floor, got %v", offline)
+	}
+	offline, evict := graph.SweepLiveness(start.Add(2*time.Minute), start, 90*time.Second, 10*time.Minute)
+	if len(offline) != 2 || len(evict) != 0 {
+		t.Fatalf("Expected support-1 and fraud-1 offline, got %v and evictions %v", offline, evict)
+	}
+	if status("support-1") != types.AgentStatusOffline || status("sales-1") != types.AgentStatusActive {
+		t.Errorf("Unexpected statuses %s and %s", status("support-1"), status("sales-1"))
+	}
+	agent, _ := graph.GetAgent("support-1")
+	if agent.Routable() {
+		t.Error("Expected offline agents not routable")
+	}
+
+	// Sweeping again reports no agent twice
+	if offline, _ := graph.SweepLiveness(start.Add(2*time.Minute), start, 90*time.Second, 10*time.Minute); len(offline) != 0 {
+		t.Errorf("Expected no agent newly offline, got %v", offline)
+	}
+
+	// A heartbeat brings an agent back; an old one does not move LastSeenAt back
+	if known, revived := graph.Heartbeat("support-1", start.Add(3*time.Minute)); !known || !revived || status("support-1") != types.AgentStatusActive {
+		t.Error("Expected support-1 back online")
+	}
+	graph.Heartbeat("support-1", start)
+	if agent, _ := graph.GetAgent("support-1"); !agent.LastSeenAt.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("Expected LastSeenAt kept at the newest heartbeat, got %s", agent.LastSeenAt)
+	}
+	if known, _ := graph.Heartbeat("unknown-1", start); known {
+		t.Error("Expected heartbeats of unknown agents ignored")
+	}
+
+	// Long silence evicts; never with a zero eviction timeout
+	_, evict = graph.SweepLiveness(start.Add(12*time.Minute), start, 90*time.Second, 10*time.Minute)
+	if len(evict) != 2 {
+		t.Errorf("Expected sales-1 and fraud-1 evicted, got %v", evict)
+	}
+	if _, evict := graph.SweepLiveness(start.Add(time.Hour), start, 90*time.Second, 0); len(evict) != 0 {
+		t.Errorf("Expected no evictions without a timeout, got %v", evict)
+	}
+}
+
+func TestTopologyReplayClock(t *testing.T) {
+	t.Setenv("EDGE_BOOTSTRAP", "full_mesh")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// The same records replayed by the replayed time yield the same graph
+	rebuild := func() *types.GraphSnapshot {
+		slimeMold := topology.NewSlimeMoldTopology(cfg, zap.NewNop())
+		now := start
+		slimeMold.SetClock(func() time.Time { return now })
+		for _, id := range []types.AgentID{"sales-1", "support-1", "billing-1"} {
+			slimeMold.AddAgent(&types.Agent{ID: id, Role: "sales", Status: types.AgentStatusActive})
+		}
+		for i := 0; i < 5; i++ {
+			now = now.Add(cfg.DecayInterval)
+			slimeMold.DecayCycle()
+			slimeMold.ReinforceEdge("sales-1", "support-1", types.MessageTypeTask)
+		}
+		return slimeMold.GetSnapshot()
+	}
+	first, second := rebuild(), rebuild()
+
+	edgeID := types.NewEdgeID("sales-1", "support-1")
+	edge := first.Edges[edgeID]
+	if edge == nil {
+		t.Fatal("Expected the reinforced edge to exist")
+	}
+	if !edge.CreatedAt.Equal(start) || !edge.LastUsed.Equal(start.Add(5*cfg.DecayInterval)) {
+		t.Errorf("Expected edge times from the clock, got created %s, last used %s", edge.CreatedAt, edge.LastUsed)
+	}
+	if len(first.Edges) != len(second.Edges) {
+		t.Fatalf("Expected the same edges, got %d and %d", len(first.Edges), len(second.Edges))
+	}
+	for id, edge := range first.Edges {
+		other := second.Edges[id]
+		if other == nil || other.Weight != edge.Weight || other.Usage != edge.Usage || !other.LastUsed.Equal(edge.LastUsed) {
+			t.Errorf("Expected edge %s rebuilt the same, got %+v and %+v", id, edge, other)
+		}
+	}
+}
+
+// halvingScorer halves the distance to 1 per message and the weight per cycle
+type halvingScorer struct{}
+
+func (halvingScorer) Gain(weight, amount float64) float64 { return (1 - weight) / 2 }
+func (halvingScorer) Loss(weight, rate float64) float64   { return weight / 2 }
+
+func TestEdgeScoring(t *testing.T) {
+	// Weight after a burst of 50 messages, then 10 decay cycles
+	burst := func(scoring types.EdgeScoring, scorer types.EdgeScorer) (peak, after float64) {
+		cfg := &types.Config{InitialEdgeWeight: 0.5, ReinforcementAmount: 0.1, DecayRate: 0.1, EdgeScoring: scoring}
+		graph := topology.NewGraph(cfg)
+		if scorer != nil {
+			graph.SetEdgeScorer(scorer)
+		}
+		for _, id := range []types.AgentID{"sales-1", "support-1"} {
+			if err := graph.AddAgent(&types.Agent{ID: id, Role: "sales", Status: types.AgentStatusActive}); err != nil {
+				t.Fatalf("Failed to add agent: %v", err)
+			}
+		}
+		edgeID := types.NewEdgeID("sales-1", "support-1")
+		for i := 0; i < 50; i++ {
+			if err := graph.ReinforceEdge(edgeID, types.MessageTypeTask); err != nil {
+				t.Fatalf("Failed to reinforce edge: %v", err)
+			}
+		}
+		edge, _ := graph.GetEdge(edgeID)
+		peak = edge.GetWeight()
+		for i := 0; i < 10; i++ {
+			graph.DecayAllEdges()
+		}
+		return peak, edge.GetWeight()
+	}
+
+	const epsilon = 1e-9
+	if peak, after := burst(types.EdgeScoringAdditive, nil); peak != 1 || math.Abs(after) > epsilon {
+		t.Errorf("Expected additive scoring to saturate at 1 and decay to 0, got %g then %g", peak, after)
+	}
+
+	// 1 - 0.5 * 0.9^50 after the burst, then 0.9^10 of it
+	wantPeak := 1 - 0.5*math.Pow(0.9, 50)
+	peak, after := burst(types.EdgeScoringEWMA, nil)
+	if math.Abs(peak-wantPeak) > epsilon || peak >= 1 {
+		t.Errorf("Expected EWMA peak %g below 1, got %g", wantPeak, peak)
+	}
+	if want := wantPeak * math.Pow(0.9, 10); math.Abs(after-want) > epsilon {
+		t.Errorf("Expected EWMA weight %g after decay, got %g", want, after)
+	}
+
+	if peak, after := burst(types.EdgeScoringAdditive, halvingScorer{}); math.Abs(peak-(1-0.5*math.Pow(0.5, 50))) > epsilon || math.Abs(after-peak/1024) > epsilon {
+		t.Errorf("Expected the plugged-in scorer to decide the weight, got %g then %g", peak, after)
+	}
+
+	if types.EdgeScoring("linear").Scorer() != nil {
+		t.Error("Expected no scorer for an unknown EDGE_SCORING")
+	}
+}
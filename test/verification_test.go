@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/config"
+	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestInsightVerificationPolicy(t *testing.T) {
+	cfg := config.Default()
+	cfg.VerifiedInsightTypes = types.ParseInsightTypes(" fraud_pattern, ,anomaly")
+	if len(cfg.VerifiedInsightTypes) != 2 {
+		t.Fatalf("Expected 2 insight types, got %v", cfg.VerifiedInsightTypes)
+	}
+
+	fraud := types.NewInsight("agent-fraud-1", "fraud", types.InsightTypeFraudPattern, "payments", "Card testing", 0.9)
+	if fraud.Actionable(cfg) {
+		t.Error("Expected an unverified fraud insight not to be actionable")
+	}
+	fraud.Verification = &types.InsightVerification{Status: types.InsightVerificationPending}
+	if fraud.Actionable(cfg) {
+		t.Error("Expected a pending fraud insight not to be actionable")
+	}
+	fraud.Verification.Status = types.InsightVerificationVerified
+	if !fraud.Actionable(cfg) {
+		t.Error("Expected a verified fraud insight to be actionable")
+	}
+
+	feedback := types.NewInsight("agent-support-1", "support", types.InsightTypeCustomerFeedback, "pricing", "Too expensive", 0.7)
+	if !feedback.Actionable(cfg) {
+		t.Error("Expected insight types without a policy to be actionable")
+	}
+
+	pushed, err := types.PushedInsight(&types.Message{Payload: map[string]any{"insight": map[string]any{
+		"id": "insight-1", "type": "fraud_pattern", "verification": map[string]any{"status": "verified"},
+	}}})
+	if err != nil || !pushed.Actionable(cfg) {
+		t.Errorf("Expected a verified pushed insight to decode as actionable, got %+v (%v)", pushed, err)
+	}
+	if _, err := types.PushedInsight(&types.Message{Payload: map[string]any{}}); err == nil {
+		t.Error("Expected a push without an insight to be rejected")
+	}
+}
+
+func TestVerificationOutcome(t *testing.T) {
+	expired := &types.Proposal{ID: "p1", Status: types.ProposalStatusExpired}
+	repropose := &types.Proposal{ID: "p2", Status: types.ProposalStatusPending}
+
+	if status, _ := types.VerificationOutcome(nil); status != types.InsightVerificationPending {
+		t.Errorf("Expected pending before the proposal is stored, got %s", status)
+	}
+	if status, p := types.VerificationOutcome([]*types.Proposal{expired, repropose}); status != types.InsightVerificationPending || p != repropose {
+		t.Errorf("Expected pending on the open re-proposal, got %s", status)
+	}
+
+	repropose.Status = types.ProposalStatusAccepted
+	if status, p := types.VerificationOutcome([]*types.Proposal{expired, repropose}); status != types.InsightVerificationVerified || p != repropose {
+		t.Errorf("Expected verified by the accepted re-proposal, got %s", status)
+	}
+
+	repropose.Status = types.ProposalStatusRejected
+	if status, _ := types.VerificationOutcome([]*types.Proposal{expired, repropose}); status != types.InsightVerificationRejected {
+		t.Errorf("Expected rejected once every proposal is decided against, got %s", status)
+	}
+
+	template, err := consensus.LookupTemplate(context.Background(), nil, types.InsightVerificationTemplate)
+	if err != nil {
+		t.Fatalf("Expected a built-in verification template: %v", err)
+	}
+	request, err := consensus.Instantiate(template, "knowledge-manager", map[string]any{
+		"insight_id": "insight-1", "insight_type": "fraud_pattern", "agent_id": "agent-fraud-1", "confidence": 0.9,
+	})
+	if err != nil {
+		t.Fatalf("Failed to instantiate: %v", err)
+	}
+	if request.Content["insight_id"] != "insight-1" || request.Type != types.ProposalTypeDecision {
+		t.Errorf("Expected a decision on insight-1, got %+v", request.Content)
+	}
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/amql"
+	"github.com/avinashshinde/agentmesh-cortex/internal/views"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+func TestInsightViews(t *testing.T) {
+	var insights []*types.Insight
+	for _, region := range []string{"eu-west", "eu-west", "us-east", "us-east"} {
+		insight := types.NewInsight("agent-fraud-1", "fraud", types.InsightTypeFraudPattern, "payments", "Card testing", 0.8)
+		insight.Region = region
+		insights = append(insights, insight)
+	}
+	insights[3].Verification = &types.InsightVerification{Status: types.InsightVerificationRejected}
+	insights = append(insights, types.NewInsight("agent-sales-1", "sales", types.InsightTypePricingIssue, "pricing", "Too expensive", 0.9))
+
+	records, err := amql.Records(insights)
+	if err != nil {
+		t.Fatalf("Failed to convert insights: %v", err)
+	}
+
+	view := &types.InsightView{
+		Name:    "open-fraud-alerts-by-region",
+		Query:   "SELECT insights WHERE type = 'fraud_pattern' AND verification.status != 'rejected'",
+		GroupBy: "region",
+	}
+	if err := views.Validate(view); err != nil || view.Aggregate != types.ViewAggregateCount {
+		t.Fatalf("Expected a valid count view, got %q (%v)", view.Aggregate, err)
+	}
+	result, err := views.Compute(view, records, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to compute view: %v", err)
+	}
+	if result.Matched != 3 || len(result.Rows) != 2 || result.Rows[0].Group != "eu-west" || result.Rows[0].Value != 2 {
+		t.Errorf("Expected 2 open alerts in eu-west and 1 in us-east, got %+v", result)
+	}
+
+	avg := &types.InsightView{Name: "confidence-by-type", Query: "SELECT insights", GroupBy: "type", Aggregate: types.ViewAggregateAvg, Field: "confidence"}
+	if result, err := views.Compute(avg, records, time.Now()); err != nil || len(result.Rows) != 2 || result.Rows[0].Group != "pricing_issue" {
+		t.Errorf("Expected pricing issues to have the highest average confidence, got %+v (%v)", result, err)
+	}
+
+	for _, invalid := range []*types.InsightView{
+		{Name: "no-field", Query: "SELECT insights", Aggregate: types.ViewAggregateSum},
+		{Name: "edges", Query: "SELECT edges"},
+		{Name: "bad query", Query: "SELECT insights"},
+	} {
+		if err := views.Validate(invalid); err == nil {
+			t.Errorf("Expected view %q to be rejected", invalid.Name)
+		}
+	}
+}
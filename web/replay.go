@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/avinashshinde/agentmesh-cortex/internal/replay"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
+	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
+)
+
+const (
+	// maxReplayWindow bounds how much history a single replay may cover
+	maxReplayWindow = 24 * time.Hour
+
+	// maxReplaySpeed bounds the playback speed multiplier
+	maxReplaySpeed = 1000.0
+
+	// maxReplaySnapshots and maxReplayMessages bound the frames a replay loads
+	// into memory; busier windows must be narrowed
+	maxReplaySnapshots = 2000
+	maxReplayMessages  = 50000
+)
+
+// handleReplay streams historical snapshots and messages over a WebSocket.
+//
+// Query parameters:
+//
+//	from, to - window to replay (RFC3339 or Unix seconds)
+//	speed    - playback multiplier (default 1.0, e.g. 60 replays an hour in a minute)
+//
+// Frames use the same "snapshot" and "message" shapes as the live stream, so the
+// dashboard renders them unchanged, bracketed by "replay_start" and "replay_end".
+// A window holding more than maxReplaySnapshots snapshots or maxReplayMessages
+// messages is refused with 413.
+func handleReplay(store *state.RedisStore, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			http.Error(w, "Replay unavailable: history store not connected", http.StatusServiceUnavailable)
+			return
+		}
+
+		from, err := replay.ParseTime(r.URL.Query().Get("from"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := replay.ParseTime(r.URL.Query().Get("to"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !to.After(from) {
+			http.Error(w, "to must be after from", http.StatusBadRequest)
+			return
+		}
+		if to.Sub(from) > maxReplayWindow {
+			http.Error(w, fmt.Sprintf("Replay window exceeds %s", maxReplayWindow), http.StatusBadRequest)
+			return
+		}
+
+		speed := 1.0
+		if s := r.URL.Query().Get("speed"); s != "" {
+			speed, err = strconv.ParseFloat(s, 64)
+			if err != nil || speed <= 0 || speed > maxReplaySpeed {
+				http.Error(w, fmt.Sprintf("speed must be in (0, %g]", maxReplaySpeed), http.StatusBadRequest)
+				return
+			}
+		}
+
+		snapshots, messages, err := store.CountHistory(r.Context(), from, to)
+		if err != nil {
+			logger.Error("Failed to count replay history", zap.Error(err))
+			http.Error(w, "Failed to load history", http.StatusInternalServerError)
+			return
+		}
+		if snapshots > maxReplaySnapshots || messages > maxReplayMessages {
+			http.Error(w, fmt.Sprintf("Replay window holds %d snapshots and %d messages, more than the %d and %d a replay may load; narrow it",
+				snapshots, messages, maxReplaySnapshots, maxReplayMessages), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		frames, err := loadReplayFrames(r, store, from, to)
+		if err != nil {
+			logger.Error("Failed to load replay history", zap.Error(err))
+			http.Error(w, "Failed to load history", http.StatusInternalServerError)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Error("WebSocket upgrade failed", zap.Error(err))
+			return
+		}
+		defer conn.Close()
+
+		// Detect client disconnects so playback stops early
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		logger.Info("Starting replay",
+			zap.Time("from", from),
+			zap.Time("to", to),
+			zap.Float64("speed", speed),
+			zap.Int("frames", len(frames)),
+		)
+
+		if err := conn.WriteJSON(map[string]interface{}{
+			"type":   "replay_start",
+			"from":   from,
+			"to":     to,
+			"speed":  speed,
+			"frames": len(frames),
+		}); err != nil {
+			return
+		}
+
+		agents := map[types.AgentID]*types.Agent{}
+		cursor := from
+		for _, frame := range frames {
+			delay := time.Duration(float64(frame.At.Sub(cursor)) / speed)
+			cursor = frame.At
+			if delay > 0 {
+				select {
+				case <-done:
+					return
+				case <-time.After(delay):
+				}
+			}
+
+			var payload map[string]interface{}
+			if frame.Snapshot != nil {
+				agents = frame.Snapshot.Agents
+				payload = map[string]interface{}{
+					"type":     "snapshot",
+					"snapshot": frame.Snapshot,
+					"replay":   true,
+				}
+			} else {
+				payload = messageFrame(frame.Message, agentName(agents, frame.Message.FromAgentID), agentName(agents, frame.Message.ToAgentID))
+				payload["replay"] = true
+			}
+
+			if err := conn.WriteJSON(payload); err != nil {
+				return
+			}
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"replay_end"}`))
+	}
+}
+
+// loadReplayFrames loads the snapshot and message history of a window as a
+// single timeline, up to maxReplayMessages messages
+func loadReplayFrames(r *http.Request, store *state.RedisStore, from, to time.Time) ([]replay.Frame, error) {
+	snapshots, err := store.ListSnapshots(r.Context(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := store.ListMessages(r.Context(), from, to, maxReplayMessages)
+	if err != nil {
+		return nil, err
+	}
+	return replay.Timeline(snapshots, messages), nil
+}
+
+// agentName resolves an agent's display name, falling back to its ID
+func agentName(agents map[types.AgentID]*types.Agent, agentID types.AgentID) string {
+	if agent, ok := agents[agentID]; ok && agent.Name != "" {
+		return agent.Name
+	}
+	return string(agentID)
+}
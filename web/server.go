@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,7 +18,9 @@ import (
 
 	"github.com/avinashshinde/agentmesh-cortex/internal/config"
 	"github.com/avinashshinde/agentmesh-cortex/internal/consensus"
+	"github.com/avinashshinde/agentmesh-cortex/internal/health"
 	"github.com/avinashshinde/agentmesh-cortex/internal/messaging"
+	"github.com/avinashshinde/agentmesh-cortex/internal/state"
 	"github.com/avinashshinde/agentmesh-cortex/internal/topology"
 	"github.com/avinashshinde/agentmesh-cortex/pkg/types"
 )
@@ -28,21 +31,42 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// broadcastChannel is the Redis Pub/Sub channel web server replicas share
+// live frames on, so behind a load balancer every client sees the same stream
+// whichever replica it is connected to and whichever replica consumed the event
+const broadcastChannel = "web:broadcast"
+
 type WebSocketHub struct {
 	clients    map[*websocket.Conn]bool
 	broadcast  chan interface{}
 	register   chan *websocket.Conn
 	unregister chan *websocket.Conn
 	mu         sync.RWMutex
+
+	fanout *state.Fanout // Fans frames out to all replicas over Redis
 }
 
-func newHub() *WebSocketHub {
-	return &WebSocketHub{
+// newHub creates a hub, streaming to local clients only with a nil store
+func newHub(store *state.RedisStore, logger *zap.Logger) *WebSocketHub {
+	h := &WebSocketHub{
 		clients:    make(map[*websocket.Conn]bool),
 		broadcast:  make(chan interface{}, 100),
 		register:   make(chan *websocket.Conn),
 		unregister: make(chan *websocket.Conn),
 	}
+	h.fanout = state.NewFanout(store, broadcastChannel, func(frame any) { h.broadcast <- frame }, logger)
+	return h
+}
+
+// publish sends a live frame to the clients of every replica through Redis,
+// falling back to this replica's clients without Redis
+func (h *WebSocketHub) publish(ctx context.Context, frame interface{}) {
+	h.fanout.Publish(ctx, frame)
+}
+
+// relay delivers the frames published by any replica to this replica's clients
+func (h *WebSocketHub) relay(ctx context.Context) {
+	h.fanout.Relay(ctx)
 }
 
 func (h *WebSocketHub) run() {
@@ -74,15 +98,78 @@ func (h *WebSocketHub) run() {
 	}
 }
 
+// agentNames caches agent names from the shared topology snapshot, for agents
+// whose join event another replica consumed
+type agentNames struct {
+	mu    sync.RWMutex
+	names map[types.AgentID]string
+}
+
+func (n *agentNames) set(agents map[types.AgentID]*types.Agent) {
+	names := make(map[types.AgentID]string, len(agents))
+	for id, agent := range agents {
+		names[id] = agent.Name
+	}
+	n.mu.Lock()
+	n.names = names
+	n.mu.Unlock()
+}
+
+func (n *agentNames) get(id types.AgentID) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	name, ok := n.names[id]
+	return name, ok
+}
+
+// fetchTopology returns the current topology as JSON: the snapshot in Redis,
+// which every replica shares, or the API server's without Redis
+func fetchTopology(ctx context.Context, store *state.RedisStore) ([]byte, error) {
+	if store != nil {
+		snapshot, err := store.LoadGraphSnapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(snapshot)
+	}
+
+	resp, err := http.Get("http://localhost:8080/api/topology")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// messageFrame builds the WebSocket frame for a mesh message
+func messageFrame(msg *types.Message, fromName, toName string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"message": map[string]interface{}{
+			"from":      msg.FromAgentID,
+			"to":        msg.ToAgentID,
+			"fromName":  fromName,
+			"toName":    toName,
+			"type":      msg.Type,
+			"payload":   msg.Payload,
+			"timestamp": msg.Timestamp,
+		},
+	}
+}
+
 func main() {
 	logger, _ := zap.NewDevelopment()
 	defer logger.Sync()
 
 	logger.Info("Starting AgentMesh Cortex Web Server")
 
-	cfg := config.Load()
-	hub := newHub()
-	go hub.run()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	for _, warning := range config.Warnings(cfg) {
+		logger.Warn("Suspicious configuration", zap.String("warning", warning))
+	}
 
 	// Initialize backend
 	slimeMold := topology.NewSlimeMoldTopology(cfg, logger)
@@ -97,6 +184,23 @@ func main() {
 	kafkaMessaging := messaging.NewKafkaMessaging(cfg, logger)
 	defer kafkaMessaging.Close()
 
+	// Redis-backed history powers dashboard replay, and Redis Pub/Sub fans live
+	// frames out to every replica; a single replica streams without it
+	redisStore, err := state.NewRedisStore(cfg, logger)
+	if err != nil {
+		logger.Warn("Redis unavailable, replay and multi-replica streaming disabled", zap.Error(err))
+	} else {
+		defer redisStore.Close()
+	}
+
+	hub := newHub(redisStore, logger)
+	go hub.run()
+	if redisStore != nil {
+		go hub.relay(ctx)
+		go health.NewReporter("web-server", redisStore, kafkaMessaging, logger, "web-server", "web-message-stream", "web-lifecycle").Run(ctx)
+	}
+	names := &agentNames{}
+
 	// Fetch existing agents from API server to handle race condition
 	go func() {
 		time.Sleep(1 * time.Second) // Wait for API server to be ready
@@ -121,25 +225,25 @@ func main() {
 	// Monitor events and broadcast to WebSocket clients
 	go func() {
 		for event := range slimeMold.EventChannel() {
-			hub.broadcast <- map[string]interface{}{
+			hub.publish(ctx, map[string]interface{}{
 				"type":  "topology",
 				"event": event,
-			}
+			})
 		}
 	}()
 
 	go func() {
 		for event := range beeConsensus.EventChannel() {
-			hub.broadcast <- map[string]interface{}{
+			hub.publish(ctx, map[string]interface{}{
 				"type":  "consensus",
 				"event": event,
-			}
+			})
 		}
 	}()
 
 	// Listen to Kafka for agent join/leave events
 	go func() {
-		err := kafkaMessaging.ConsumeTopologyEvents(ctx, "topology", "web-server", func(event types.TopologyEvent) error {
+		err := kafkaMessaging.ConsumeTopologyEvents(ctx, "topology", "web-server", func(ctx context.Context, event types.TopologyEvent) error {
 			switch event.Type {
 			case types.TopologyEventAgentJoined:
 				if event.Agent != nil {
@@ -165,11 +269,36 @@ func main() {
 		}
 	}()
 
+	// Stream agent lifecycle changes (starting, ready, degraded, draining, stopped)
+	go func() {
+		err := kafkaMessaging.ConsumeMessages(ctx, "lifecycle", "web-lifecycle", func(ctx context.Context, msg *types.Message) error {
+			event, err := types.LifecycleEventFrom(msg)
+			if err != nil {
+				return nil
+			}
+			name := string(event.AgentID)
+			if agent, err := slimeMold.GetGraph().GetAgent(event.AgentID); err == nil {
+				name = agent.Name
+			} else if known, ok := names.get(event.AgentID); ok {
+				name = known
+			}
+			hub.publish(ctx, map[string]interface{}{
+				"type":  "lifecycle",
+				"event": event,
+				"name":  name,
+			})
+			return nil
+		})
+		if err != nil && err != context.Canceled {
+			logger.Error("Lifecycle event listener stopped", zap.Error(err))
+		}
+	}()
+
 	// Listen to Kafka messages and broadcast to WebSocket for live message stream
 	// Note: We don't reinforce edges in the web-server's local topology anymore
 	// because we fetch the real topology from the API server (Redis-backed)
 	go func() {
-		err := kafkaMessaging.ConsumeMessages(ctx, "messages", "web-message-stream", func(msg *types.Message) error {
+		err := kafkaMessaging.ConsumeMessages(ctx, "messages", "web-message-stream", func(ctx context.Context, msg *types.Message) error {
 			// Resolve agent names from IDs
 			fromName := string(msg.FromAgentID)
 			toName := string(msg.ToAgentID)
@@ -177,24 +306,17 @@ func main() {
 			graph := slimeMold.GetGraph()
 			if fromAgent, err := graph.GetAgent(msg.FromAgentID); err == nil {
 				fromName = fromAgent.Name
+			} else if name, ok := names.get(msg.FromAgentID); ok {
+				fromName = name
 			}
 			if toAgent, err := graph.GetAgent(msg.ToAgentID); err == nil {
 				toName = toAgent.Name
+			} else if name, ok := names.get(msg.ToAgentID); ok {
+				toName = name
 			}
 
-			// Broadcast message to all WebSocket clients with agent names
-			hub.broadcast <- map[string]interface{}{
-				"type": "message",
-				"message": map[string]interface{}{
-					"from":      msg.FromAgentID,
-					"to":        msg.ToAgentID,
-					"fromName":  fromName,
-					"toName":    toName,
-					"type":      msg.Type,
-					"payload":   msg.Payload,
-					"timestamp": msg.Timestamp,
-				},
-			}
+			// Broadcast message to the WebSocket clients of every replica with agent names
+			hub.publish(ctx, messageFrame(msg, fromName, toName))
 			return nil
 		})
 		if err != nil && err != context.Canceled {
@@ -202,27 +324,27 @@ func main() {
 		}
 	}()
 
-	// Periodically broadcast topology snapshot from API server (Redis-backed)
+	// Periodically broadcast the topology snapshot (Redis-backed) to this
+	// replica's clients; every replica reads the same snapshot
 	go func() {
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
 		for range ticker.C {
-			// Fetch real topology from API server
-			resp, err := http.Get("http://localhost:8080/api/topology")
+			data, err := fetchTopology(ctx, redisStore)
 			if err != nil {
-				logger.Debug("Failed to fetch topology from API server", zap.Error(err))
+				logger.Debug("Failed to fetch topology", zap.Error(err))
 				continue
 			}
-			defer resp.Body.Close()
 
 			var topology struct {
 				Agents map[types.AgentID]*types.Agent          `json:"agents"`
 				Edges  map[string]map[string]interface{}       `json:"edges"`
 			}
-			if err := json.NewDecoder(resp.Body).Decode(&topology); err != nil {
+			if err := json.Unmarshal(data, &topology); err != nil {
 				logger.Debug("Failed to decode topology", zap.Error(err))
 				continue
 			}
+			names.set(topology.Agents)
 
 			// Calculate stats
 			totalAgents := len(topology.Agents)
@@ -230,7 +352,7 @@ func main() {
 			activeEdges := 0
 			var totalWeight float64
 			for _, edge := range topology.Edges {
-				weight := edge["weight"].(float64)
+				weight, _ := edge["weight"].(float64)
 				if weight > 0.1 {
 					activeEdges++
 				}
@@ -290,8 +412,16 @@ func main() {
 		}
 	})
 
+	http.HandleFunc("/ws/replay", handleReplay(redisStore, logger))
+
 	http.HandleFunc("/api/snapshot", func(w http.ResponseWriter, r *http.Request) {
 		snapshot := slimeMold.GetSnapshot()
+		if redisStore != nil {
+			// The shared snapshot, not the agents this replica happened to see join
+			if shared, err := redisStore.LoadGraphSnapshot(r.Context()); err == nil {
+				snapshot = shared
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(snapshot)
 	})
@@ -27,6 +27,9 @@ wsManager.addListener((data) => {
         case 'consensus':
             handleConsensusEvent(data.event);
             break;
+        case 'lifecycle':
+            handleLifecycleEvent(data.event, data.name);
+            break;
         case 'message':
             // Forward to message stream handler
             if (data.message) {
@@ -58,10 +61,32 @@ function handleTopologyEvent(event) {
         'edge_strength_changed': 'Edge reinforced'
     };
 
+    // Health issues carry their own description and the suggested fix
+    if (event.type === 'health_issue' && event.issue) {
+        wsManager.addEvent(`${event.issue.detail}. ${event.issue.suggestion}`, 'error');
+        return;
+    }
+
     const message = typeMap[event.type] || event.type;
     wsManager.addEvent(message, 'topology');
 }
 
+function handleLifecycleEvent(event, name) {
+    const typeMap = {
+        'starting': 'is starting',
+        'ready': 'is ready',
+        'degraded': 'is degraded',
+        'draining': 'is draining',
+        'stopped': 'stopped'
+    };
+
+    let message = `${name || event.agent_id} ${typeMap[event.state] || event.state}`;
+    if (event.reason) {
+        message += `: ${event.reason}`;
+    }
+    wsManager.addEvent(message, event.state === 'degraded' ? 'error' : 'topology');
+}
+
 function handleConsensusEvent(event) {
     const typeMap = {
         'proposal_created': 'Proposal created',
@@ -111,6 +136,7 @@ fetch('http://localhost:8080/api/topology')
         const snapshot = {
             agents: topology.agents || {},
             edges: topology.edges || {},
+            communities: topology.communities || {},
             stats: {
                 total_agents: totalAgents,
                 total_edges: totalEdges,
@@ -0,0 +1,54 @@
+// Mesh objectives panel - polls goal status from the API server
+const GOALS_URL = 'http://localhost:8080/api/goals';
+const GOALS_REFRESH_MS = 10000;
+
+const GOAL_STATE_LABELS = {
+    'on_track': 'On track',
+    'at_risk': 'At risk',
+    'off_track': 'Off track',
+    'no_data': 'No data'
+};
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function renderGoals(statuses) {
+    const container = document.getElementById('goals');
+    if (!statuses || statuses.length === 0) {
+        container.innerHTML = '<p class="goals-empty">No goals defined</p>';
+        return;
+    }
+
+    container.innerHTML = statuses.map(status => {
+        const goal = status.goal;
+        const current = status.current !== undefined ? status.current.toFixed(2) : '-';
+        const contributors = (status.contributors || [])
+            .slice(0, 3)
+            .map(c => `${escapeHtml(c.agent_role || c.agent_id)} (${c.samples})`)
+            .join(', ');
+
+        return `
+            <div class="goal">
+                <div>
+                    <div class="goal-name">${escapeHtml(goal.name)}</div>
+                    <div class="goal-target">${escapeHtml(goal.metric)} ${escapeHtml(goal.aggregation)} ${escapeHtml(goal.comparator)} ${goal.target} over ${escapeHtml(goal.window)}</div>
+                    <div class="goal-contributors">${contributors ? 'Contributors: ' + contributors : 'No reports yet'}</div>
+                </div>
+                <div class="goal-current">${current} <small>(${status.samples} reports)</small></div>
+                <div class="goal-state ${status.state}">${GOAL_STATE_LABELS[status.state] || status.state}</div>
+            </div>`;
+    }).join('');
+}
+
+function refreshGoals() {
+    fetch(GOALS_URL)
+        .then(res => res.json())
+        .then(data => renderGoals(data.goals))
+        .catch(err => console.error('Failed to load goals:', err));
+}
+
+refreshGoals();
+setInterval(refreshGoals, GOALS_REFRESH_MS);
@@ -1,3 +1,6 @@
+const SLOW_LINK_MS = 250;
+const LAYOUT_MARGIN = 40;
+
 class ForceDirectedGraph {
     constructor(svgId) {
         this.svg = d3.select(`#${svgId}`);
@@ -21,20 +24,37 @@ class ForceDirectedGraph {
     update(snapshot) {
         if (!snapshot || !snapshot.agents || !snapshot.edges) return;
 
-        // Convert agents to nodes
-        const newNodes = Object.values(snapshot.agents).map(agent => ({
-            id: agent.id,
-            name: agent.name,
-            role: agent.role,
-            status: agent.status
-        }));
+        // Convert agents to nodes, pinned where the topology manager laid them out
+        // so every client draws the same layout; without one the simulation places them
+        const communities = snapshot.communities || {};
+        const layout = snapshot.layout || {};
+        const newNodes = Object.values(snapshot.agents).map(agent => {
+            const position = layout[agent.id];
+            const x = position ? LAYOUT_MARGIN + position.x * (this.width - 2 * LAYOUT_MARGIN) : null;
+            const y = position ? LAYOUT_MARGIN + position.y * (this.height - 2 * LAYOUT_MARGIN) : null;
+            return {
+                id: agent.id,
+                name: agent.name,
+                role: agent.role,
+                status: agent.status,
+                community: communities[agent.id],
+                layoutX: x,
+                layoutY: y,
+                x: x ?? undefined,
+                y: y ?? undefined,
+                fx: x,
+                fy: y
+            };
+        });
 
         // Convert edges to links
         const newLinks = Object.values(snapshot.edges).map(edge => ({
             source: edge.source_id,
             target: edge.target_id,
             weight: edge.weight,
-            usage: edge.usage
+            usage: edge.usage,
+            latency: edge.latency_p50_ms || 0,
+            latencyP99: edge.latency_p99_ms || 0
         }));
 
         this.nodes = newNodes;
@@ -55,9 +75,12 @@ class ForceDirectedGraph {
             .data(this.links, d => `${d.source}-${d.target}`)
             .enter()
             .append('line')
-            .attr('class', 'link')
+            // Links whose median latency crosses SLOW_LINK_MS are drawn dashed in orange
+            .attr('class', d => d.latency >= SLOW_LINK_MS ? 'link slow' : 'link')
             .attr('stroke-width', d => Math.max(1, d.weight * 5))
-            .attr('stroke-opacity', d => 0.3 + (d.weight * 0.7));
+            .attr('stroke-opacity', d => 0.3 + (d.weight * 0.7))
+            .append('title')
+            .text(d => d.latency ? `p50 ${d.latency.toFixed(1)}ms, p99 ${d.latencyP99.toFixed(1)}ms` : 'no latency samples');
 
         // Rebuild nodes from scratch
         this.nodeGroup
@@ -67,6 +90,9 @@ class ForceDirectedGraph {
             .append('circle')
             .attr('r', 20)
             .attr('class', d => `node ${d.role}`)
+            // Role fills the node; the ring colors its cluster of closely linked agents
+            .style('stroke', d => d.community === undefined ? null : d3.schemeCategory10[d.community % 10])
+            .style('stroke-width', d => d.community === undefined ? null : '4px')
             .call(this.drag(this.simulation));
 
         // Rebuild labels from scratch
@@ -122,10 +148,11 @@ class ForceDirectedGraph {
             event.subject.fy = event.y;
         }
 
+        // Dropped nodes return to their laid out position, if any
         function dragended(event) {
             if (!event.active) simulation.alphaTarget(0);
-            event.subject.fx = null;
-            event.subject.fy = null;
+            event.subject.fx = event.subject.layoutX;
+            event.subject.fy = event.subject.layoutY;
         }
 
         return d3.drag()
@@ -0,0 +1,57 @@
+// Query panel - runs AMQL queries on the API server
+const AMQL_URL = 'http://localhost:8080/api/amql';
+const QUERY_MAX_COLUMNS = 8;
+
+function formatCell(value) {
+    if (value === null || value === undefined) {
+        return '';
+    }
+    if (typeof value === 'number') {
+        return Number.isInteger(value) ? String(value) : value.toFixed(3);
+    }
+    if (typeof value === 'object') {
+        return JSON.stringify(value);
+    }
+    return String(value);
+}
+
+function renderQueryResults(results) {
+    const container = document.getElementById('query-results');
+    if (!results || results.length === 0) {
+        container.innerHTML = '<p class="query-message">No results</p>';
+        return;
+    }
+
+    // Scalar fields of the first result, in its order, as columns
+    const columns = Object.keys(results[0])
+        .filter(key => typeof results[0][key] !== 'object' || results[0][key] === null)
+        .slice(0, QUERY_MAX_COLUMNS);
+
+    const header = columns.map(c => `<th>${escapeHtml(c)}</th>`).join('');
+    const rows = results.map(record =>
+        '<tr>' + columns.map(c => `<td>${escapeHtml(formatCell(record[c]))}</td>`).join('') + '</tr>'
+    ).join('');
+
+    container.innerHTML = `
+        <p class="query-message">${results.length} result(s)</p>
+        <table class="query-results-table"><thead><tr>${header}</tr></thead><tbody>${rows}</tbody></table>`;
+}
+
+function runQuery(query) {
+    const container = document.getElementById('query-results');
+    fetch(AMQL_URL, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ query })
+    })
+        .then(res => res.ok ? res.json() : res.text().then(text => { throw new Error(text); }))
+        .then(data => renderQueryResults(data.results))
+        .catch(err => {
+            container.innerHTML = `<p class="query-message error">${escapeHtml(err.message)}</p>`;
+        });
+}
+
+document.getElementById('query-form').addEventListener('submit', event => {
+    event.preventDefault();
+    runQuery(document.getElementById('query-input').value);
+});